@@ -1,13 +1,24 @@
 package cmd
 
 import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
 	"edukita-teaching-grading/configs"
+	"edukita-teaching-grading/internal/app/event"
+	"edukita-teaching-grading/internal/app/policy"
 	"edukita-teaching-grading/internal/app/repository"
 	"edukita-teaching-grading/internal/app/server"
 	"edukita-teaching-grading/internal/app/service"
+	"edukita-teaching-grading/internal/app/worker"
 	"edukita-teaching-grading/internal/pkg"
 	"edukita-teaching-grading/pkg/driver"
 	"edukita-teaching-grading/pkg/logger"
+	"edukita-teaching-grading/pkg/mailer"
+	"edukita-teaching-grading/pkg/pubsub"
+	"edukita-teaching-grading/pkg/storage"
 
 	"github.com/sirupsen/logrus"
 	"go.uber.org/zap"
@@ -50,29 +61,216 @@ func Run() {
 		OptionsApplication: options,
 	})
 
+	rbac := policy.Default()
+
+	blobStore, err := storage.NewBlobStore(storage.Option{
+		Driver:    config.Storage.Driver,
+		LocalPath: config.Storage.LocalPath,
+		S3: storage.S3Option{
+			Endpoint:  config.Storage.S3Endpoint,
+			Region:    config.Storage.S3Region,
+			Bucket:    config.Storage.S3Bucket,
+			AccessKey: config.Storage.S3AccessKey,
+			SecretKey: config.Storage.S3SecretKey,
+			UseSSL:    config.Storage.S3UseSSL,
+		},
+	})
+	if err != nil {
+		logger.Fatalf("failed to initialize storage: %v", err.Error(), zap.Error(err))
+		return
+	}
+
+	mailTemplates, err := mailer.NewTemplates(config.Mail.AppName, config.Mail.AppURL)
+	if err != nil {
+		logger.Fatalf("failed to parse email templates: %v", err.Error(), zap.Error(err))
+		return
+	}
+	mailSender, err := mailer.NewSender(mailer.Option{
+		Driver: config.Mail.Driver,
+		From:   config.Mail.From,
+		LogDir: config.Mail.LogDir,
+		SMTP: mailer.SMTPOption{
+			Host:     config.Mail.SMTPHost,
+			Port:     config.Mail.SMTPPort,
+			Username: config.Mail.SMTPUsername,
+			Password: config.Mail.SMTPPassword,
+			StartTLS: config.Mail.SMTPStartTLS,
+			Timeout:  config.Mail.Timeout,
+		},
+		Logger: logger,
+	})
+	if err != nil {
+		logger.Fatalf("failed to initialize mail sender: %v", err.Error(), zap.Error(err))
+		return
+	}
+
+	hub := pubsub.NewHub(config.Realtime.Buffer)
+	broker, err := pubsub.NewBroker(pubsub.Option{
+		Driver:  config.Realtime.Driver,
+		URL:     config.Postgresql.URL,
+		Channel: config.Realtime.Channel,
+		Logger:  logger,
+	}, hub)
+	if err != nil {
+		logger.Fatalf("failed to initialize realtime broker: %v", err.Error(), zap.Error(err))
+		return
+	}
+
+	// job handlers are registered before the queue runs, and before anything enqueues
+	jobQueue := worker.NewJobQueue(worker.JobQueueOption{
+		OptionsApplication: options,
+		Repository:         repo,
+	})
+	worker.NewMailer(worker.MailerOption{
+		OptionsApplication: options,
+		Repository:         repo,
+		Templates:          mailTemplates,
+		Sender:             mailSender,
+	}).Register(jobQueue)
+
+	webhooks := event.NewWebhookRelay(event.WebhookRelayOption{
+		OptionsApplication: options,
+		Repository:         repo,
+		Jobs:               jobQueue,
+	})
+	webhooks.Register(jobQueue)
+
 	svc := serviceConnector(service.ServiceOption{
 		OptionsApplication: options,
 		Repository:         repo,
+		Policy:             rbac,
+		Storage:            blobStore,
+		URLSigner:          storage.NewURLSigner(config.Application.Secret, config.Storage.PublicURL+"/api/v1/lms/attachments", config.Storage.SignedURLExpired),
+		Hub:                hub,
+		Jobs:               jobQueue,
+	})
+
+	// SIGINT and SIGTERM stop the server and the workers, the job queue drains before Run returns
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// subscribers register on the bus before the dispatcher starts delivering
+	bus := event.NewBus()
+	bus.Subscribe("notifications", svc.Notification.NotifyEvent, event.AssignmentPublished, event.SubmissionGraded)
+	bus.Subscribe("webhooks", webhooks.Enqueue)
+
+	realtime := event.NewRealtimeRelay(event.RealtimeRelayOption{
+		OptionsApplication: options,
+		Repository:         repo,
+		Broker:             broker,
+	})
+	bus.Subscribe("realtime", realtime.Push,
+		event.AssignmentPublished,
+		event.SubmissionCreated,
+		event.SubmissionResubmitted,
+		event.SubmissionGraded,
+		event.StudentEnrolled,
+		event.StudentDropped,
+	)
+	go broker.Run(ctx)
+
+	jobs, err := worker.NewScheduler(worker.SchedulerOption{
+		OptionsApplication: options,
+		Repository:         repo,
+	})
+	if err != nil {
+		logger.Fatalf("failed to initialize scheduler: %v", err.Error(), zap.Error(err))
+		return
+	}
+	for _, job := range []struct {
+		name string
+		spec string
+		run  worker.JobFunc
+	}{
+		{pkg.JOB_DUE_REMINDERS, config.Scheduler.DueReminders, svc.Scheduler.SendDueReminders},
+		{pkg.JOB_DEACTIVATE_ENDED_COURSES, config.Scheduler.DeactivateCourses, svc.Scheduler.DeactivateEndedCourses},
+		{pkg.JOB_PURGE_EXPIRED_TOKENS, config.Scheduler.PurgeTokens, svc.Scheduler.PurgeExpiredTokens},
+		{pkg.JOB_PURGE_FINISHED_JOBS, config.Scheduler.PurgeJobs, svc.Scheduler.PurgeSucceededJobs},
+	} {
+		if err = jobs.Register(job.name, job.spec, job.run); err != nil {
+			logger.Fatalf("failed to register scheduled job: %v", err.Error(), zap.Error(err))
+			return
+		}
+	}
+	go jobs.Run(ctx)
+
+	drained := make(chan struct{})
+	go func() {
+		defer close(drained)
+		jobQueue.Run(ctx)
+	}()
+
+	dispatcher := event.NewDispatcher(event.DispatcherOption{
+		OptionsApplication: options,
+		Repository:         repo,
+		Bus:                bus,
 	})
+	go dispatcher.Run(ctx)
+
+	app := server.NewServer(options, svc, repo, rbac)
+	app.ServerRun(ctx)
 
-	app := server.NewServer(options, svc, repo)
-	app.ServerRun()
+	stop()
+	<-drained
+	logger.Info("job queue drained")
 }
 
 func repositoryConnector(opt repository.RepositoryOption) *repository.Repository {
 	userRepo := repository.InitiateUserRepository(opt)
 	lmsRepo := repository.InitiateLearningManagementRepository(opt)
+	authRepo := repository.InitiateAuthRepository(opt)
+	attachmentRepo := repository.InitiateAttachmentRepository(opt)
+	rubricRepo := repository.InitiateRubricRepository(opt)
+	gradebookRepo := repository.InitiateGradebookRepository(opt)
+	auditRepo := repository.InitiateAuditRepository(opt)
+	outboxRepo := repository.InitiateOutboxRepository(opt)
+	webhookRepo := repository.InitiateWebhookRepository(opt)
+	notificationRepo := repository.InitiateNotificationRepository(opt)
+	emailRepo := repository.InitiateEmailRepository(opt)
+	schedulerRepo := repository.InitiateSchedulerRepository(opt)
+	jobRepo := repository.InitiateJobRepository(opt)
+	txManager := repository.NewTxManager(opt)
 	return &repository.Repository{
 		User:               userRepo,
 		LearningManagement: lmsRepo,
+		Auth:               authRepo,
+		Attachment:         attachmentRepo,
+		Rubric:             rubricRepo,
+		Gradebook:          gradebookRepo,
+		Audit:              auditRepo,
+		Outbox:             outboxRepo,
+		Webhook:            webhookRepo,
+		Notification:       notificationRepo,
+		Email:              emailRepo,
+		Scheduler:          schedulerRepo,
+		Job:                jobRepo,
+		Tx:                 txManager,
 	}
 }
 
 func serviceConnector(opt service.ServiceOption) *service.Service {
 	userService := service.InitiateUserService(opt)
 	lmsService := service.InitiateLearningManagementService(opt)
+	attachmentService := service.InitiateAttachmentService(opt)
+	rubricService := service.InitiateRubricService(opt)
+	gradebookService := service.InitiateGradebookService(opt)
+	auditService := service.InitiateAuditService(opt)
+	webhookService := service.InitiateWebhookService(opt)
+	notificationService := service.InitiateNotificationService(opt)
+	realtimeService := service.InitiateRealtimeService(opt)
+	schedulerService := service.InitiateSchedulerService(opt)
+	jobService := service.InitiateJobService(opt)
 	return &service.Service{
 		User:               userService,
 		LearningManagement: lmsService,
+		Attachment:         attachmentService,
+		Rubric:             rubricService,
+		Gradebook:          gradebookService,
+		Audit:              auditService,
+		Webhook:            webhookService,
+		Notification:       notificationService,
+		Realtime:           realtimeService,
+		Scheduler:          schedulerService,
+		Job:                jobService,
 	}
 }
@@ -3,6 +3,7 @@ package configs
 import (
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -14,7 +15,15 @@ type (
 	Config struct {
 		Application Application
 		Cookies     Cookies
+		Auth        Auth
 		Postgresql  Postgresql
+		Storage     Storage
+		Outbox      Outbox
+		Webhook     Webhook
+		Mail        Mail
+		Realtime    Realtime
+		Scheduler   Scheduler
+		Jobs        Jobs
 	}
 	Application struct {
 		Name        string
@@ -24,16 +33,102 @@ type (
 		StaticToken string
 		CostBcrypt  int
 		SwaggerPath string
+		// ShutdownTimeout is how long in-flight requests get to finish on shutdown
+		ShutdownTimeout time.Duration
 	}
 	Cookies struct {
-		AccessToken string
-		Domain      string
-		SSOExpired  time.Duration
+		AccessToken   string
+		RefreshToken  string
+		Domain        string
+		AccessExpired time.Duration
+		SSOExpired    time.Duration
+	}
+	Auth struct {
+		PasswordResetExpired     time.Duration
+		EmailVerificationExpired time.Duration
+		// RequireEmailVerification refuses to log in users that have not verified their email address
+		RequireEmailVerification bool
+		// MFAIssuer names the account in authenticator apps
+		MFAIssuer string
+		// MFARequiredRoles must pass two-factor authentication to log in, other roles may turn it on
+		MFARequiredRoles    []string
+		MFAChallengeExpired time.Duration
+		// MFAMaxAttempts is how many codes may be tried against one login challenge
+		MFAMaxAttempts int
 	}
 	Postgresql struct {
 		Name string
 		URL  string
 	}
+	Storage struct {
+		Driver              string
+		LocalPath           string
+		S3Endpoint          string
+		S3Region            string
+		S3Bucket            string
+		S3AccessKey         string
+		S3SecretKey         string
+		S3UseSSL            bool
+		MaxUploadSize       int64
+		AllowedContentTypes []string
+		PublicURL           string
+		SignedURLExpired    time.Duration
+	}
+	Outbox struct {
+		PollInterval time.Duration
+		BatchSize    int
+		MaxAttempts  int
+		RetryBackoff time.Duration
+		MaxBackoff   time.Duration
+	}
+	// Webhook deliveries are jobs of the job queue and retried with its backoff, like emails
+	Webhook struct {
+		Timeout     time.Duration
+		MaxAttempts int
+	}
+	Mail struct {
+		Driver        string
+		From          string
+		AppName       string
+		AppURL        string
+		LogDir        string
+		SMTPHost      string
+		SMTPPort      int
+		SMTPUsername  string
+		SMTPPassword  string
+		SMTPStartTLS  bool
+		Timeout       time.Duration
+		MaxAttempts   int
+		RatePerMinute int
+		RateBurst     int
+	}
+	Realtime struct {
+		Driver    string
+		Channel   string
+		Buffer    int
+		Heartbeat time.Duration
+	}
+	// Scheduler holds the cron expressions of the scheduled jobs, evaluated in Timezone
+	Scheduler struct {
+		Timezone          string
+		DueReminders      string
+		DeactivateCourses string
+		PurgeTokens       string
+		PurgeJobs         string
+	}
+	Jobs struct {
+		Workers      int
+		PollInterval time.Duration
+		// VisibilityTimeout is how long a claimed job is hidden from other workers, and how long a run may take
+		VisibilityTimeout time.Duration
+		MaxAttempts       int
+		RetryBackoff      time.Duration
+		MaxBackoff        time.Duration
+		// ShutdownTimeout is how long running jobs may still take once the application stops
+		ShutdownTimeout time.Duration
+		// Retention is how long succeeded jobs are kept
+		Retention time.Duration
+	}
 )
 
 func LoadConfigurations(fileName string) (*Config, error) {
@@ -49,20 +144,105 @@ func LoadConfigurations(fileName string) (*Config, error) {
 		StaticToken: GetEnv("APP_STATIC_TOKEN", "supersecretsecret"),
 		CostBcrypt:  getEnvAsInt("APP_COST_BCRYPT", bcrypt.DefaultCost),
 		SwaggerPath: GetEnv("APP_SWAGGER_PATH", ""),
+
+		ShutdownTimeout: time.Second * time.Duration(getEnvAsInt("APP_SHUTDOWN_TIMEOUT", 10)),
 	}
 	cookies := Cookies{
-		AccessToken: GetEnv("COOKIES_ACCESS_TOKEN", "edukita_lms"),
-		Domain:      GetEnv("COOKIES_DOMAIN", "localhost"),
-		SSOExpired:  time.Hour * 24 * time.Duration(getEnvAsInt("COOKIES_SSO_EXPIRED", 7)),
+		AccessToken:   GetEnv("COOKIES_ACCESS_TOKEN", "edukita_lms"),
+		RefreshToken:  GetEnv("COOKIES_REFRESH_TOKEN", "edukita_lms_refresh"),
+		Domain:        GetEnv("COOKIES_DOMAIN", "localhost"),
+		AccessExpired: time.Minute * time.Duration(getEnvAsInt("COOKIES_ACCESS_EXPIRED", 15)),
+		SSOExpired:    time.Hour * 24 * time.Duration(getEnvAsInt("COOKIES_SSO_EXPIRED", 7)),
+	}
+	auth := Auth{
+		PasswordResetExpired:     time.Minute * time.Duration(getEnvAsInt("AUTH_PASSWORD_RESET_EXPIRED", 30)),
+		EmailVerificationExpired: time.Hour * time.Duration(getEnvAsInt("AUTH_EMAIL_VERIFICATION_EXPIRED", 48)),
+		RequireEmailVerification: getEnvAsBool("AUTH_REQUIRE_EMAIL_VERIFICATION", false),
+		MFAIssuer:                GetEnv("AUTH_MFA_ISSUER", "Edukita LMS"),
+		MFARequiredRoles:         getEnvAsSlice("AUTH_MFA_REQUIRED_ROLES", []string{}),
+		MFAChallengeExpired:      time.Minute * time.Duration(getEnvAsInt("AUTH_MFA_CHALLENGE_EXPIRED", 5)),
+		MFAMaxAttempts:           getEnvAsInt("AUTH_MFA_MAX_ATTEMPTS", 5),
 	}
 	psql := Postgresql{
 		Name: GetEnv("POSTGRES_NAME", "edukita-teaching-grading"),
 		URL:  GetEnv("POSTGRES_URL", "localhost:5432"),
 	}
+	storage := Storage{
+		Driver:              GetEnv("STORAGE_DRIVER", "local"),
+		LocalPath:           GetEnv("STORAGE_LOCAL_PATH", "./storage"),
+		S3Endpoint:          GetEnv("STORAGE_S3_ENDPOINT", "localhost:9000"),
+		S3Region:            GetEnv("STORAGE_S3_REGION", "us-east-1"),
+		S3Bucket:            GetEnv("STORAGE_S3_BUCKET", "edukita-lms"),
+		S3AccessKey:         GetEnv("STORAGE_S3_ACCESS_KEY", ""),
+		S3SecretKey:         GetEnv("STORAGE_S3_SECRET_KEY", ""),
+		S3UseSSL:            getEnvAsBool("STORAGE_S3_USE_SSL", false),
+		MaxUploadSize:       int64(getEnvAsInt("STORAGE_MAX_UPLOAD_SIZE", 10)) << 20,
+		AllowedContentTypes: getEnvAsSlice("STORAGE_ALLOWED_CONTENT_TYPES", []string{"application/pdf", "application/zip", "image/png", "image/jpeg", "text/plain"}),
+		PublicURL:           GetEnv("STORAGE_PUBLIC_URL", "http://localhost:8080"),
+		SignedURLExpired:    time.Minute * time.Duration(getEnvAsInt("STORAGE_SIGNED_URL_EXPIRED", 15)),
+	}
+	outbox := Outbox{
+		PollInterval: time.Second * time.Duration(getEnvAsInt("OUTBOX_POLL_INTERVAL", 2)),
+		BatchSize:    getEnvAsInt("OUTBOX_BATCH_SIZE", 50),
+		MaxAttempts:  getEnvAsInt("OUTBOX_MAX_ATTEMPTS", 10),
+		RetryBackoff: time.Second * time.Duration(getEnvAsInt("OUTBOX_RETRY_BACKOFF", 5)),
+		MaxBackoff:   time.Minute * time.Duration(getEnvAsInt("OUTBOX_MAX_BACKOFF", 60)),
+	}
+	webhook := Webhook{
+		Timeout:     time.Second * time.Duration(getEnvAsInt("WEBHOOK_TIMEOUT", 10)),
+		MaxAttempts: getEnvAsInt("WEBHOOK_MAX_ATTEMPTS", 8),
+	}
+	mail := Mail{
+		Driver:        GetEnv("MAIL_DRIVER", "log"),
+		From:          GetEnv("MAIL_FROM", "Edukita LMS <no-reply@edukita.local>"),
+		AppName:       GetEnv("MAIL_APP_NAME", "Edukita LMS"),
+		AppURL:        GetEnv("MAIL_APP_URL", "http://localhost:3000"),
+		LogDir:        GetEnv("MAIL_LOG_DIR", ""),
+		SMTPHost:      GetEnv("MAIL_SMTP_HOST", "localhost"),
+		SMTPPort:      getEnvAsInt("MAIL_SMTP_PORT", 1025),
+		SMTPUsername:  GetEnv("MAIL_SMTP_USERNAME", ""),
+		SMTPPassword:  GetEnv("MAIL_SMTP_PASSWORD", ""),
+		SMTPStartTLS:  getEnvAsBool("MAIL_SMTP_STARTTLS", false),
+		Timeout:       time.Second * time.Duration(getEnvAsInt("MAIL_TIMEOUT", 10)),
+		MaxAttempts:   getEnvAsInt("MAIL_MAX_ATTEMPTS", 5),
+		RatePerMinute: getEnvAsInt("MAIL_RATE_PER_MINUTE", 60),
+		RateBurst:     getEnvAsInt("MAIL_RATE_BURST", 10),
+	}
+	realtime := Realtime{
+		Driver:    GetEnv("REALTIME_DRIVER", "memory"),
+		Channel:   GetEnv("REALTIME_CHANNEL", "edukita_realtime"),
+		Buffer:    getEnvAsInt("REALTIME_BUFFER", 64),
+		Heartbeat: time.Second * time.Duration(getEnvAsInt("REALTIME_HEARTBEAT", 25)),
+	}
+	scheduler := Scheduler{
+		Timezone:          GetEnv("SCHEDULER_TIMEZONE", "UTC"),
+		DueReminders:      GetEnv("SCHEDULER_DUE_REMINDERS", "*/5 * * * *"),
+		DeactivateCourses: GetEnv("SCHEDULER_DEACTIVATE_COURSES", "0 * * * *"),
+		PurgeTokens:       GetEnv("SCHEDULER_PURGE_TOKENS", "30 2 * * *"),
+		PurgeJobs:         GetEnv("SCHEDULER_PURGE_JOBS", "45 2 * * *"),
+	}
+	jobs := Jobs{
+		Workers:           getEnvAsInt("JOBS_WORKERS", 4),
+		PollInterval:      time.Second * time.Duration(getEnvAsInt("JOBS_POLL_INTERVAL", 2)),
+		VisibilityTimeout: time.Second * time.Duration(getEnvAsInt("JOBS_VISIBILITY_TIMEOUT", 300)),
+		MaxAttempts:       getEnvAsInt("JOBS_MAX_ATTEMPTS", 5),
+		RetryBackoff:      time.Second * time.Duration(getEnvAsInt("JOBS_RETRY_BACKOFF", 30)),
+		MaxBackoff:        time.Minute * time.Duration(getEnvAsInt("JOBS_MAX_BACKOFF", 60)),
+		ShutdownTimeout:   time.Second * time.Duration(getEnvAsInt("JOBS_SHUTDOWN_TIMEOUT", 30)),
+		Retention:         24 * time.Hour * time.Duration(getEnvAsInt("JOBS_RETENTION_DAYS", 7)),
+	}
 	cfg := Config{
 		Application: app,
 		Cookies:     cookies,
+		Auth:        auth,
 		Postgresql:  psql,
+		Storage:     storage,
+		Outbox:      outbox,
+		Webhook:     webhook,
+		Mail:        mail,
+		Realtime:    realtime,
+		Scheduler:   scheduler,
+		Jobs:        jobs,
 	}
 	return &cfg, nil
 }
@@ -83,3 +263,27 @@ func getEnvAsInt(name string, defaultVal int) int {
 
 	return defaultVal
 }
+
+func getEnvAsBool(name string, defaultVal bool) bool {
+	valStr := GetEnv(name, "")
+	if value, err := strconv.ParseBool(valStr); err == nil {
+		return value
+	}
+
+	return defaultVal
+}
+
+func getEnvAsSlice(name string, defaultVal []string) []string {
+	valStr := GetEnv(name, "")
+	if valStr == "" {
+		return defaultVal
+	}
+
+	values := []string{}
+	for _, value := range strings.Split(valStr, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
@@ -0,0 +1,62 @@
+package event
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// Handler reacts to a dispatched event. Delivery is at least once, so a handler must tolerate seeing the
+// same event again, for instance by keying what it does on the event ID.
+type Handler func(ctx context.Context, e Event) error
+
+type subscription struct {
+	name    string
+	types   []Type
+	handler Handler
+}
+
+// Bus hands events to the in-process subscribers registered on it
+type Bus struct {
+	mu            sync.RWMutex
+	subscriptions []subscription
+}
+
+func NewBus() *Bus {
+	return &Bus{}
+}
+
+// Subscribe registers the handler under name for the given event types, or for every event when none is given
+func (b *Bus) Subscribe(name string, handler Handler, types ...Type) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subscriptions = append(b.subscriptions, subscription{name: name, types: types, handler: handler})
+}
+
+// Deliver calls the subscribers of the event in the order they subscribed and stops at the first one failing.
+// A panicking subscriber fails the delivery instead of taking the dispatcher down.
+func (b *Bus) Deliver(ctx context.Context, e Event) error {
+	b.mu.RLock()
+	subscriptions := slices.Clone(b.subscriptions)
+	b.mu.RUnlock()
+
+	for _, sub := range subscriptions {
+		if len(sub.types) > 0 && !slices.Contains(sub.types, e.Type) {
+			continue
+		}
+		if err := sub.deliver(ctx, e); err != nil {
+			return fmt.Errorf("subscriber %s: %w", sub.name, err)
+		}
+	}
+	return nil
+}
+
+func (s subscription) deliver(ctx context.Context, e Event) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("panic: %v", p)
+		}
+	}()
+	return s.handler(ctx, e)
+}
@@ -0,0 +1,70 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestBusDeliver(t *testing.T) {
+	bus := NewBus()
+
+	var delivered []string
+	record := func(name string) Handler {
+		return func(ctx context.Context, e Event) error {
+			delivered = append(delivered, name)
+			return nil
+		}
+	}
+	bus.Subscribe("all", record("all"))
+	bus.Subscribe("grades", record("grades"), SubmissionGraded)
+	bus.Subscribe("enrollments", record("enrollments"), StudentEnrolled, StudentDropped)
+
+	if err := bus.Deliver(context.Background(), Event{ID: uuid.New(), Type: SubmissionGraded}); err != nil {
+		t.Fatalf("deliver: %v", err)
+	}
+	if got := strings.Join(delivered, ","); got != "all,grades" {
+		t.Errorf("delivered to %q, want all,grades", got)
+	}
+}
+
+func TestBusDeliverStopsAtFailure(t *testing.T) {
+	bus := NewBus()
+
+	failure := errors.New("receiver down")
+	called := false
+	bus.Subscribe("failing", func(ctx context.Context, e Event) error { return failure })
+	bus.Subscribe("after", func(ctx context.Context, e Event) error {
+		called = true
+		return nil
+	})
+
+	err := bus.Deliver(context.Background(), Event{Type: UserRegistered})
+	if !errors.Is(err, failure) {
+		t.Fatalf("got %v, want %v", err, failure)
+	}
+	if !strings.Contains(err.Error(), "failing") {
+		t.Errorf("error %q does not name the subscriber", err)
+	}
+	if called {
+		t.Error("subscriber after the failing one was called")
+	}
+}
+
+func TestBusDeliverRecoversPanic(t *testing.T) {
+	bus := NewBus()
+	bus.Subscribe("panicking", func(ctx context.Context, e Event) error { panic("boom") })
+
+	if err := bus.Deliver(context.Background(), Event{Type: UserRegistered}); err == nil {
+		t.Fatal("expected the panic to fail the delivery")
+	}
+}
+
+func TestTypeAggregate(t *testing.T) {
+	if got := SubmissionGraded.Aggregate(); got != "submission" {
+		t.Errorf("got %q, want submission", got)
+	}
+}
@@ -0,0 +1,97 @@
+package event
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"edukita-teaching-grading/internal/app/repository"
+	"edukita-teaching-grading/internal/pkg"
+
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+type DispatcherOption struct {
+	pkg.OptionsApplication
+	Repository *repository.Repository
+	Bus        *Bus
+}
+
+// Dispatcher moves committed events from the outbox to the subscribers of the bus
+type Dispatcher struct {
+	DispatcherOption
+}
+
+func NewDispatcher(opt DispatcherOption) *Dispatcher {
+	return &Dispatcher{
+		DispatcherOption: opt,
+	}
+}
+
+// Run polls the outbox until ctx is cancelled, full batches are dispatched back to back
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.Config.Outbox.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		for ctx.Err() == nil {
+			claimed, err := d.DispatchBatch(ctx)
+			if err != nil {
+				d.Logger.Errorf(fmt.Sprintf("failed to dispatch outbox events: %s", err.Error()), zap.Error(err))
+				break
+			}
+			if claimed < d.Config.Outbox.BatchSize {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DispatchBatch delivers one batch of due events and returns how many it claimed. Every event is delivered in
+// a savepoint of the claiming transaction, so database writes of the subscribers commit together with the
+// event being marked dispatched, and are rolled back when a subscriber fails and the event is retried.
+func (d *Dispatcher) DispatchBatch(ctx context.Context) (claimed int, err error) {
+	return claimed, d.Repository.Tx.Run(ctx, repository.TxReadWrite, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+		now := time.Now()
+		rows, err := d.Repository.Outbox.ClaimOutboxEvents(ctx, now, uint(d.Config.Outbox.BatchSize), tx)
+		if err != nil {
+			return
+		}
+		claimed = len(rows)
+
+		for _, row := range rows {
+			deliverErr := d.Repository.Tx.Run(ctx, repository.TxReadWrite, func(ctx context.Context, tx *sqlx.Tx) error {
+				return d.Bus.Deliver(ctx, FromOutbox(row))
+			})
+
+			row.Attempts++
+			switch {
+			case deliverErr == nil:
+				row.DispatchedAt = &now
+				row.LastError = nil
+			case row.Attempts >= d.Config.Outbox.MaxAttempts:
+				lastError := deliverErr.Error()
+				row.LastError = &lastError
+				row.FailedAt = &now
+				d.Logger.Errorf("giving up outbox event %s (%s) after %d attempts: %s", row.ID, row.EventType, row.Attempts, lastError)
+			default:
+				lastError := deliverErr.Error()
+				row.LastError = &lastError
+				row.AvailableAt = now.Add(pkg.Backoff(row.Attempts, d.Config.Outbox.RetryBackoff, d.Config.Outbox.MaxBackoff))
+				d.Logger.Warnf("failed to deliver outbox event %s (%s), retrying at %s: %s", row.ID, row.EventType, row.AvailableAt.Format(time.RFC3339), lastError)
+			}
+
+			if err = d.Repository.Outbox.UpdateOutboxEvent(ctx, row, tx); err != nil {
+				return
+			}
+		}
+		return
+	})
+}
@@ -0,0 +1,132 @@
+package event
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"edukita-teaching-grading/internal/app/model"
+
+	"github.com/google/uuid"
+)
+
+// Type names a domain event as "<aggregate>.<what happened>"
+type Type string
+
+const (
+	UserRegistered Type = "user.registered"
+
+	AssignmentPublished Type = "assignment.published"
+
+	SubmissionCreated     Type = "submission.created"
+	SubmissionResubmitted Type = "submission.resubmitted"
+	SubmissionGraded      Type = "submission.graded"
+
+	StudentEnrolled Type = "enrollment.enrolled"
+	StudentDropped  Type = "enrollment.dropped"
+)
+
+// Types lists every event the services raise
+var Types = []Type{
+	UserRegistered,
+	AssignmentPublished,
+	SubmissionCreated,
+	SubmissionResubmitted,
+	SubmissionGraded,
+	StudentEnrolled,
+	StudentDropped,
+}
+
+// Known reports whether the services raise events of the type
+func (t Type) Known() bool {
+	for _, known := range Types {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Aggregate is the kind of entity the event is about
+func (t Type) Aggregate() string {
+	aggregate, _, _ := strings.Cut(string(t), ".")
+	return aggregate
+}
+
+// Event is a dispatched domain event, Payload is decoded with Decode into the payload type of the event
+type Event struct {
+	ID          uuid.UUID
+	Type        Type
+	AggregateID uuid.UUID
+	ActorID     *uuid.UUID
+	OccurredAt  time.Time
+	// Attempt counts the deliveries of the event including this one, subscribers see it again after a failure
+	Attempt int
+	Payload json.RawMessage
+}
+
+// Decode unmarshals the payload of the event into v
+func (e Event) Decode(v any) error {
+	return json.Unmarshal(e.Payload, v)
+}
+
+// FromOutbox turns an outbox row into the event handed to subscribers
+func FromOutbox(row model.OutboxEvent) Event {
+	return Event{
+		ID:          row.ID,
+		Type:        Type(row.EventType),
+		AggregateID: row.AggregateID,
+		ActorID:     row.ActorID,
+		OccurredAt:  row.OccurredAt,
+		Attempt:     row.Attempts + 1,
+		Payload:     json.RawMessage(row.Payload),
+	}
+}
+
+type UserRegisteredPayload struct {
+	UserID    uuid.UUID `json:"user_id"`
+	Email     string    `json:"email"`
+	FirstName string    `json:"first_name"`
+	LastName  string    `json:"last_name"`
+	Role      string    `json:"role"`
+}
+
+type AssignmentPublishedPayload struct {
+	AssignmentID uuid.UUID `json:"assignment_id"`
+	CourseID     uuid.UUID `json:"course_id"`
+	TeacherID    uuid.UUID `json:"teacher_id"`
+	Title        string    `json:"title"`
+	DueDate      time.Time `json:"due_date"`
+}
+
+// SubmissionPayload is the payload of SubmissionCreated and SubmissionResubmitted
+type SubmissionPayload struct {
+	SubmissionID uuid.UUID `json:"submission_id"`
+	AssignmentID uuid.UUID `json:"assignment_id"`
+	CourseID     uuid.UUID `json:"course_id"`
+	StudentID    uuid.UUID `json:"student_id"`
+	TeacherID    uuid.UUID `json:"teacher_id"`
+	AttemptCount int       `json:"attempt_count"`
+	IsLate       bool      `json:"is_late"`
+	SubmittedAt  time.Time `json:"submitted_at"`
+}
+
+// SubmissionGradedPayload is raised when a teacher grades a submission or leaves feedback on it, Grade is nil
+// for feedback without a grade
+type SubmissionGradedPayload struct {
+	SubmissionID uuid.UUID `json:"submission_id"`
+	AssignmentID uuid.UUID `json:"assignment_id"`
+	CourseID     uuid.UUID `json:"course_id"`
+	StudentID    uuid.UUID `json:"student_id"`
+	GradedBy     uuid.UUID `json:"graded_by"`
+	Grade        *float64  `json:"grade"`
+	RawGrade     *float64  `json:"raw_grade"`
+	Feedback     *string   `json:"feedback"`
+}
+
+// EnrollmentPayload is the payload of StudentEnrolled and StudentDropped
+type EnrollmentPayload struct {
+	EnrollmentID uuid.UUID `json:"enrollment_id"`
+	CourseID     uuid.UUID `json:"course_id"`
+	StudentID    uuid.UUID `json:"student_id"`
+}
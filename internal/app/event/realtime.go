@@ -0,0 +1,99 @@
+package event
+
+import (
+	"context"
+
+	"edukita-teaching-grading/internal/app/repository"
+	"edukita-teaching-grading/internal/pkg"
+	"edukita-teaching-grading/pkg/pubsub"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// StaffTopic receives every message meant for the teachers of a course, admins listen on it
+const StaffTopic = "staff"
+
+// UserTopic receives the messages about one user
+func UserTopic(id uuid.UUID) string {
+	return "user:" + id.String()
+}
+
+// CourseTopic receives the messages for everyone in a course
+func CourseTopic(id uuid.UUID) string {
+	return "course:" + id.String()
+}
+
+// CourseStaffTopic receives the messages for the teachers of a course only, such as new work of its students
+func CourseStaffTopic(id uuid.UUID) string {
+	return "course:" + id.String() + ":staff"
+}
+
+type RealtimeRelayOption struct {
+	pkg.OptionsApplication
+	Repository *repository.Repository
+	Broker     pubsub.Broker
+}
+
+// RealtimeRelay pushes domain events to the connected clients of the users and courses they are about
+type RealtimeRelay struct {
+	RealtimeRelayOption
+}
+
+func NewRealtimeRelay(opt RealtimeRelayOption) *RealtimeRelay {
+	return &RealtimeRelay{RealtimeRelayOption: opt}
+}
+
+// Push is the bus subscriber that publishes the event to its topics. It runs in the savepoint of the
+// dispatcher, so with the postgres broker the message goes out when the dispatch commits.
+func (r *RealtimeRelay) Push(ctx context.Context, e Event) error {
+	msg, ok, err := RealtimeMessage(e)
+	if err != nil || !ok {
+		return err
+	}
+
+	return r.Repository.Tx.Run(ctx, repository.TxReadWrite, func(ctx context.Context, tx *sqlx.Tx) error {
+		return r.Broker.Publish(ctx, tx, msg)
+	})
+}
+
+// RealtimeMessage builds the message of an event with the topics allowed to see it, ok is false for events
+// that are not pushed
+func RealtimeMessage(e Event) (msg pubsub.Message, ok bool, err error) {
+	msg = pubsub.Message{
+		ID:         e.ID.String(),
+		Type:       string(e.Type),
+		OccurredAt: e.OccurredAt,
+		Data:       e.Payload,
+	}
+
+	switch e.Type {
+	case AssignmentPublished:
+		var published AssignmentPublishedPayload
+		if err = e.Decode(&published); err != nil {
+			return
+		}
+		msg.Topics = []string{CourseTopic(published.CourseID), CourseStaffTopic(published.CourseID), StaffTopic}
+	case SubmissionCreated, SubmissionResubmitted:
+		var submission SubmissionPayload
+		if err = e.Decode(&submission); err != nil {
+			return
+		}
+		msg.Topics = []string{UserTopic(submission.StudentID), CourseStaffTopic(submission.CourseID), StaffTopic}
+	case SubmissionGraded:
+		var graded SubmissionGradedPayload
+		if err = e.Decode(&graded); err != nil {
+			return
+		}
+		msg.Topics = []string{UserTopic(graded.StudentID), CourseStaffTopic(graded.CourseID), StaffTopic}
+	case StudentEnrolled, StudentDropped:
+		var enrollment EnrollmentPayload
+		if err = e.Decode(&enrollment); err != nil {
+			return
+		}
+		msg.Topics = []string{UserTopic(enrollment.StudentID), CourseStaffTopic(enrollment.CourseID), StaffTopic}
+	default:
+		return msg, false, nil
+	}
+	return msg, true, nil
+}
@@ -0,0 +1,49 @@
+package event
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestRealtimeMessageTopics(t *testing.T) {
+	courseID, studentID := uuid.New(), uuid.New()
+	tests := []struct {
+		eventType Type
+		payload   any
+		want      []string
+		unwanted  string
+	}{
+		{AssignmentPublished, AssignmentPublishedPayload{CourseID: courseID}, []string{CourseTopic(courseID), CourseStaffTopic(courseID), StaffTopic}, ""},
+		{SubmissionCreated, SubmissionPayload{CourseID: courseID, StudentID: studentID}, []string{UserTopic(studentID), CourseStaffTopic(courseID), StaffTopic}, CourseTopic(courseID)},
+		{SubmissionGraded, SubmissionGradedPayload{CourseID: courseID, StudentID: studentID}, []string{UserTopic(studentID), CourseStaffTopic(courseID), StaffTopic}, CourseTopic(courseID)},
+		{StudentDropped, EnrollmentPayload{CourseID: courseID, StudentID: studentID}, []string{UserTopic(studentID), CourseStaffTopic(courseID), StaffTopic}, CourseTopic(courseID)},
+	}
+	for _, tt := range tests {
+		payload, _ := json.Marshal(tt.payload)
+		e := Event{ID: uuid.New(), Type: tt.eventType, OccurredAt: time.Now(), Payload: payload}
+
+		msg, ok, err := RealtimeMessage(e)
+		if err != nil || !ok {
+			t.Errorf("%s: ok %v, %v", tt.eventType, ok, err)
+			continue
+		}
+		if !slices.Equal(msg.Topics, tt.want) {
+			t.Errorf("%s: topics %v, want %v", tt.eventType, msg.Topics, tt.want)
+		}
+		// other students of the course must not see the work of a student
+		if tt.unwanted != "" && slices.Contains(msg.Topics, tt.unwanted) {
+			t.Errorf("%s: published to %s", tt.eventType, tt.unwanted)
+		}
+		if msg.ID != e.ID.String() || msg.Type != string(tt.eventType) || string(msg.Data) != string(payload) {
+			t.Errorf("%s: message %+v does not carry the event", tt.eventType, msg)
+		}
+	}
+
+	if _, ok, err := RealtimeMessage(Event{Type: UserRegistered, Payload: json.RawMessage(`{}`)}); ok || err != nil {
+		t.Errorf("user.registered pushed: %v, %v", ok, err)
+	}
+}
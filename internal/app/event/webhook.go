@@ -0,0 +1,251 @@
+package event
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"edukita-teaching-grading/internal/app/model"
+	"edukita-teaching-grading/internal/app/repository"
+	"edukita-teaching-grading/internal/app/worker"
+	"edukita-teaching-grading/internal/pkg"
+	"edukita-teaching-grading/pkg/webhook"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+type WebhookRelayOption struct {
+	pkg.OptionsApplication
+	Repository *repository.Repository
+	Jobs       *worker.JobQueue
+}
+
+// WebhookRelay queues domain events for the webhook subscriptions and sends them to the receivers as
+// deliver_webhook jobs
+type WebhookRelay struct {
+	WebhookRelayOption
+	sender *webhook.Sender
+}
+
+func NewWebhookRelay(opt WebhookRelayOption) *WebhookRelay {
+	return &WebhookRelay{
+		WebhookRelayOption: opt,
+		sender:             webhook.NewSender(opt.Config.Webhook.Timeout),
+	}
+}
+
+// WebhookBody is the JSON document posted to receivers, Data is the payload of the event
+type WebhookBody struct {
+	ID          uuid.UUID       `json:"id"`
+	Type        Type            `json:"type"`
+	AggregateID uuid.UUID       `json:"aggregate_id"`
+	OccurredAt  time.Time       `json:"occurred_at"`
+	Data        json.RawMessage `json:"data"`
+}
+
+// Enqueue is the bus subscriber that creates a delivery of the event, and the job sending it, for every active
+// subscription that wants it. It runs in the savepoint of the dispatcher, so the deliveries exist exactly when the
+// event is dispatched.
+func (r *WebhookRelay) Enqueue(ctx context.Context, e Event) error {
+	return r.Repository.Tx.Run(ctx, repository.TxReadWrite, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+		subscriptions, err := r.Repository.Webhook.GetAllActiveWebhookSubscriptions(ctx, tx)
+		if err != nil {
+			return
+		}
+
+		body, err := json.Marshal(WebhookBody{
+			ID:          e.ID,
+			Type:        e.Type,
+			AggregateID: e.AggregateID,
+			OccurredAt:  e.OccurredAt,
+			Data:        e.Payload,
+		})
+		if err != nil {
+			return
+		}
+
+		now := time.Now()
+		for _, subscription := range subscriptions {
+			if !WebhookWants(subscription, e.Type) {
+				continue
+			}
+
+			delivery := model.WebhookDelivery{
+				ID:             uuid.New(),
+				SubscriptionID: subscription.ID,
+				EventID:        e.ID,
+				EventType:      string(e.Type),
+				Payload:        string(body),
+				Status:         pkg.WEBHOOK_DELIVERY_PENDING,
+				NextAttemptAt:  now,
+				CreatedAt:      now,
+				UpdatedAt:      now,
+			}
+
+			var created int64
+			if created, err = r.Repository.Webhook.CreateWebhookDelivery(ctx, delivery, tx); err != nil {
+				return
+			}
+			// a redelivered event already has its delivery and job
+			if created == 0 {
+				continue
+			}
+			if err = EnqueueDelivery(ctx, r.Jobs, delivery); err != nil {
+				return
+			}
+		}
+		return
+	})
+}
+
+// WebhookWants reports whether the subscription receives events of the type, a subscription without event
+// types receives all of them
+func WebhookWants(subscription model.WebhookSubscription, t Type) bool {
+	var types []Type
+	if err := json.Unmarshal([]byte(subscription.EventTypes), &types); err != nil {
+		return false
+	}
+	if len(types) == 0 {
+		return true
+	}
+	for _, wanted := range types {
+		if wanted == t {
+			return true
+		}
+	}
+	return false
+}
+
+// DeliverWebhookPayload is the payload of a deliver_webhook job
+type DeliverWebhookPayload struct {
+	DeliveryID uuid.UUID `json:"delivery_id"`
+}
+
+// Register adds the handler of deliver_webhook jobs to the queue
+func (r *WebhookRelay) Register(q *worker.JobQueue) {
+	worker.Handle(q, pkg.JOB_TYPE_DELIVER_WEBHOOK, r.Deliver)
+	worker.Finish(q, pkg.JOB_TYPE_DELIVER_WEBHOOK, r.Finish)
+}
+
+// EnqueueDelivery queues a deliver_webhook job for the delivery, in the transaction carried by ctx
+func EnqueueDelivery(ctx context.Context, q *worker.JobQueue, delivery model.WebhookDelivery) error {
+	_, err := q.Enqueue(ctx, pkg.JOB_TYPE_DELIVER_WEBHOOK, DeliverWebhookPayload{DeliveryID: delivery.ID}, worker.EnqueueOptions{
+		MaxAttempts: q.Config.Webhook.MaxAttempts,
+	})
+	return err
+}
+
+// Deliver sends the delivery once and saves the log of the attempt. A delivery that is no longer pending was sent
+// by an earlier run, and one to a webhook that was paused or deleted meanwhile is given up.
+func (r *WebhookRelay) Deliver(ctx context.Context, job model.Job, payload DeliverWebhookPayload) error {
+	var (
+		delivery     model.WebhookDelivery
+		subscription model.WebhookSubscription
+	)
+	err := r.Repository.Tx.Run(ctx, repository.TxReadOnly, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+		delivery, err = r.Repository.Webhook.GetWebhookDeliveryByID(ctx, payload.DeliveryID.String(), tx)
+		if err != nil {
+			return
+		}
+		subscription, err = r.Repository.Webhook.GetWebhookSubscriptionByID(ctx, delivery.SubscriptionID.String(), tx)
+		return
+	})
+	if err != nil {
+		if pkg.IsNotFound(err) {
+			return worker.PermanentJobError(err)
+		}
+		return err
+	}
+	if delivery.Status != pkg.WEBHOOK_DELIVERY_PENDING {
+		return nil
+	}
+	if !subscription.IsActive {
+		return worker.PermanentJobError(errors.New("webhook is paused"))
+	}
+
+	attempt, sendErr := r.send(ctx, subscription, delivery, job.Attempts, time.Now())
+	// the attempt is logged even when the shutdown timeout cut it off
+	err = r.Repository.Tx.Run(context.WithoutCancel(ctx), repository.TxReadWrite, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+		if _, err = r.Repository.Webhook.CreateWebhookAttempt(ctx, attempt, tx); err != nil {
+			return
+		}
+		delivery.LastStatusCode = attempt.StatusCode
+		_, err = r.Repository.Webhook.UpdateWebhookDelivery(ctx, delivery, tx)
+		return
+	})
+	if err != nil {
+		r.Logger.Errorf(fmt.Sprintf("failed to save webhook attempt of %s: %s", delivery.ID, err.Error()), zap.Error(err))
+	}
+	return sendErr
+}
+
+// send posts the delivery to the receiver and returns the log of the attempt
+func (r *WebhookRelay) send(ctx context.Context, subscription model.WebhookSubscription, delivery model.WebhookDelivery, attempts int, now time.Time) (model.WebhookAttempt, error) {
+	res, sendErr := r.sender.Send(ctx, webhook.Message{
+		URL:        subscription.URL,
+		Secret:     subscription.Secret,
+		Event:      delivery.EventType,
+		DeliveryID: delivery.ID.String(),
+		Body:       []byte(delivery.Payload),
+	}, now)
+
+	attempt := model.WebhookAttempt{
+		ID:          uuid.New(),
+		DeliveryID:  delivery.ID,
+		Attempt:     attempts,
+		DurationMS:  res.Duration.Milliseconds(),
+		AttemptedAt: now,
+	}
+	if res.StatusCode != 0 {
+		statusCode, body := res.StatusCode, res.Body
+		attempt.StatusCode = &statusCode
+		attempt.ResponseBody = &body
+	}
+	if sendErr != nil {
+		lastError := sendErr.Error()
+		attempt.Error = &lastError
+	}
+	return attempt, sendErr
+}
+
+// Finish saves the outcome of the run on the delivery
+func (r *WebhookRelay) Finish(ctx context.Context, job model.Job, payload DeliverWebhookPayload, tx *sqlx.Tx) error {
+	delivery, err := r.Repository.Webhook.GetWebhookDeliveryByID(ctx, payload.DeliveryID.String(), tx)
+	if pkg.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if delivery.Status != pkg.WEBHOOK_DELIVERY_PENDING {
+		return nil
+	}
+
+	if _, err = r.Repository.Webhook.UpdateWebhookDelivery(ctx, finishedDelivery(delivery, job), tx); err != nil {
+		r.Logger.Errorf(fmt.Sprintf("failed to save webhook delivery %s: %s", delivery.ID, err.Error()), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// finishedDelivery returns the delivery with the state of the job that sends it: succeeded when the job did, dead
+// when it failed for good and pending while it is retried
+func finishedDelivery(delivery model.WebhookDelivery, job model.Job) model.WebhookDelivery {
+	delivery.Attempts = job.Attempts
+	delivery.LastError = job.LastError
+	delivery.NextAttemptAt = job.RunAt
+	switch job.Status {
+	case pkg.JOB_STATUS_SUCCEEDED:
+		delivery.Status = pkg.WEBHOOK_DELIVERY_SUCCEEDED
+		delivery.DeliveredAt = job.FinishedAt
+	case pkg.JOB_STATUS_FAILED:
+		delivery.Status = pkg.WEBHOOK_DELIVERY_DEAD
+	default:
+		delivery.Status = pkg.WEBHOOK_DELIVERY_PENDING
+	}
+	return delivery
+}
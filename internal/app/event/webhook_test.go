@@ -0,0 +1,118 @@
+package event
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"edukita-teaching-grading/configs"
+	"edukita-teaching-grading/internal/app/model"
+	"edukita-teaching-grading/internal/pkg"
+	"edukita-teaching-grading/pkg/webhook"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+func newTestRelay() *WebhookRelay {
+	return NewWebhookRelay(WebhookRelayOption{
+		OptionsApplication: pkg.OptionsApplication{
+			Config: &configs.Config{Webhook: configs.Webhook{Timeout: time.Second}},
+			Logger: zap.NewNop().Sugar(),
+		},
+	})
+}
+
+func newTestDelivery() model.WebhookDelivery {
+	return model.WebhookDelivery{
+		ID:        uuid.New(),
+		EventID:   uuid.New(),
+		EventType: string(SubmissionGraded),
+		Payload:   `{"type":"submission.graded"}`,
+		Status:    pkg.WEBHOOK_DELIVERY_PENDING,
+	}
+}
+
+func TestWebhookSendSucceeded(t *testing.T) {
+	subscription := model.WebhookSubscription{Secret: "whsec_test"}
+	delivery := newTestDelivery()
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if err := webhook.Verify(subscription.Secret, r.Header, body, time.Now(), time.Minute); err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		if r.Header.Get(webhook.HeaderDelivery) != delivery.ID.String() {
+			http.Error(w, "wrong delivery", http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer receiver.Close()
+	subscription.URL = receiver.URL
+
+	now := time.Now()
+	attempt, err := newTestRelay().send(context.Background(), subscription, delivery, 2, now)
+	if err != nil {
+		t.Fatalf("send() unexpected error: %v", err)
+	}
+	if attempt.DeliveryID != delivery.ID || attempt.Attempt != 2 || !attempt.AttemptedAt.Equal(now) || attempt.Error != nil {
+		t.Errorf("unexpected attempt log %+v", attempt)
+	}
+	if attempt.StatusCode == nil || *attempt.StatusCode != http.StatusOK || *attempt.ResponseBody != "ok" {
+		t.Errorf("status code %v, body %v", attempt.StatusCode, attempt.ResponseBody)
+	}
+}
+
+func TestWebhookSendFailed(t *testing.T) {
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+
+	unreachable := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	unreachable.Close()
+
+	tests := []struct {
+		name           string
+		url            string
+		wantStatusCode *int
+	}{
+		{"error status", failing.URL, ref(http.StatusInternalServerError)},
+		{"unreachable", unreachable.URL, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempt, err := newTestRelay().send(context.Background(), model.WebhookSubscription{URL: tt.url}, newTestDelivery(), 1, time.Now())
+			if err == nil || attempt.Error == nil || *attempt.Error != err.Error() {
+				t.Errorf("send() error = %v, logged %v", err, attempt.Error)
+			}
+			if (attempt.StatusCode == nil) != (tt.wantStatusCode == nil) || (attempt.StatusCode != nil && *attempt.StatusCode != *tt.wantStatusCode) {
+				t.Errorf("status code %v, want %v", attempt.StatusCode, tt.wantStatusCode)
+			}
+		})
+	}
+}
+
+func ref[T any](v T) *T { return &v }
+
+func TestWebhookWants(t *testing.T) {
+	tests := []struct {
+		eventTypes string
+		want       bool
+	}{
+		{`[]`, true},
+		{`["submission.graded"]`, true},
+		{`["submission.created","enrollment.enrolled"]`, false},
+		{`not json`, false},
+	}
+	for _, tt := range tests {
+		if got := WebhookWants(model.WebhookSubscription{EventTypes: tt.eventTypes}, SubmissionGraded); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.eventTypes, got, tt.want)
+		}
+	}
+}
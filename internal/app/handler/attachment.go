@@ -0,0 +1,353 @@
+package handler
+
+import (
+	"edukita-teaching-grading/internal/app/model"
+	"edukita-teaching-grading/internal/app/payload"
+	"edukita-teaching-grading/internal/pkg"
+	"errors"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type AttachmentHandler struct {
+	HandlerOptions
+}
+
+func (h *AttachmentHandler) UploadSubmissionAttachment(c *fiber.Ctx) (err error) {
+	var (
+		claim = c.Locals("mw.auth.claims").(model.JWTToken)
+		id    = c.Params("id")
+		e     *pkg.AppError
+	)
+	if id == "" {
+		return c.Status(http.StatusBadRequest).JSON(payload.BaseResponse{
+			Status:  http.StatusBadRequest,
+			Message: "id is required",
+		},
+		)
+	}
+
+	if claim.UUID == "" {
+		return c.Status(http.StatusUnauthorized).JSON(payload.BaseResponse{
+			Status:  http.StatusUnauthorized,
+			Message: "unauthorized",
+		},
+		)
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(payload.BaseResponse{
+			Status:  http.StatusBadRequest,
+			Message: "file is required",
+		},
+		)
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	res, err := h.Service.Attachment.UploadSubmissionAttachment(c.UserContext(), id, uploadAttachmentRequest(fileHeader, file))
+	if err != nil {
+		resError := payload.BaseResponse{
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Error:   err,
+		}
+		if errors.As(err, &e) {
+			resError.Status = e.StatusCode
+			resError.Message = e.Message
+			resError.Error = e.Err
+		} else {
+			resError.Status = http.StatusInternalServerError
+		}
+		return c.Status(resError.Status).JSON(resError)
+	}
+
+	response := payload.BaseResponse{
+		Status:  http.StatusCreated,
+		Message: "success",
+		Data:    res,
+	}
+	return c.Status(http.StatusCreated).JSON(response)
+}
+
+func (h *AttachmentHandler) UploadAssignmentAttachment(c *fiber.Ctx) (err error) {
+	var (
+		claim = c.Locals("mw.auth.claims").(model.JWTToken)
+		id    = c.Params("id")
+		e     *pkg.AppError
+	)
+	if id == "" {
+		return c.Status(http.StatusBadRequest).JSON(payload.BaseResponse{
+			Status:  http.StatusBadRequest,
+			Message: "id is required",
+		},
+		)
+	}
+
+	if claim.UUID == "" {
+		return c.Status(http.StatusUnauthorized).JSON(payload.BaseResponse{
+			Status:  http.StatusUnauthorized,
+			Message: "unauthorized",
+		},
+		)
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(payload.BaseResponse{
+			Status:  http.StatusBadRequest,
+			Message: "file is required",
+		},
+		)
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	res, err := h.Service.Attachment.UploadAssignmentAttachment(c.UserContext(), id, uploadAttachmentRequest(fileHeader, file))
+	if err != nil {
+		resError := payload.BaseResponse{
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Error:   err,
+		}
+		if errors.As(err, &e) {
+			resError.Status = e.StatusCode
+			resError.Message = e.Message
+			resError.Error = e.Err
+		} else {
+			resError.Status = http.StatusInternalServerError
+		}
+		return c.Status(resError.Status).JSON(resError)
+	}
+
+	response := payload.BaseResponse{
+		Status:  http.StatusCreated,
+		Message: "success",
+		Data:    res,
+	}
+	return c.Status(http.StatusCreated).JSON(response)
+}
+
+func (h *AttachmentHandler) GetAttachmentByID(c *fiber.Ctx) (err error) {
+	var (
+		claim = c.Locals("mw.auth.claims").(model.JWTToken)
+		e     *pkg.AppError
+	)
+	query := c.Params("id")
+	if query == "" {
+		return c.Status(http.StatusBadRequest).JSON(payload.BaseResponse{
+			Status:  http.StatusBadRequest,
+			Message: "id is required",
+		},
+		)
+	}
+
+	if claim.UUID == "" {
+		return c.Status(http.StatusUnauthorized).JSON(payload.BaseResponse{
+			Status:  http.StatusUnauthorized,
+			Message: "unauthorized",
+		},
+		)
+	}
+
+	res, err := h.Service.Attachment.GetAttachmentByID(c.UserContext(), query)
+	if err != nil {
+		resError := payload.BaseResponse{
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Error:   err,
+		}
+		if errors.As(err, &e) {
+			resError.Status = e.StatusCode
+			resError.Message = e.Message
+			resError.Error = e.Err
+		} else {
+			resError.Status = http.StatusInternalServerError
+		}
+		return c.Status(resError.Status).JSON(resError)
+	}
+
+	response := payload.BaseResponse{
+		Status:  http.StatusOK,
+		Message: "success",
+		Data:    res,
+	}
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+func (h *AttachmentHandler) GetAllAttachmentsBySubmissionID(c *fiber.Ctx) (err error) {
+	var (
+		claim = c.Locals("mw.auth.claims").(model.JWTToken)
+		e     *pkg.AppError
+	)
+	query := c.Params("id")
+	if query == "" {
+		return c.Status(http.StatusBadRequest).JSON(payload.BaseResponse{
+			Status:  http.StatusBadRequest,
+			Message: "id is required",
+		},
+		)
+	}
+
+	if claim.UUID == "" {
+		return c.Status(http.StatusUnauthorized).JSON(payload.BaseResponse{
+			Status:  http.StatusUnauthorized,
+			Message: "unauthorized",
+		},
+		)
+	}
+
+	listQuery, err := pkg.ParseListQuery(c.Queries())
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(payload.BaseResponse{
+			Status:  http.StatusBadRequest,
+			Message: err.Error(),
+		},
+		)
+	}
+
+	res, meta, err := h.Service.Attachment.GetAllAttachmentsBySubmissionID(c.UserContext(), query, listQuery)
+	if err != nil {
+		resError := payload.BaseResponse{
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Error:   err,
+		}
+		if errors.As(err, &e) {
+			resError.Status = e.StatusCode
+			resError.Message = e.Message
+			resError.Error = e.Err
+		} else {
+			resError.Status = http.StatusInternalServerError
+		}
+		return c.Status(resError.Status).JSON(resError)
+	}
+
+	response := payload.BaseResponseWithMeta{
+		BaseResponse: payload.BaseResponse{
+			Status:  http.StatusOK,
+			Message: "success",
+			Data:    res,
+		},
+		Meta: meta,
+	}
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+func (h *AttachmentHandler) GetAllAttachmentsByAssignmentID(c *fiber.Ctx) (err error) {
+	var (
+		claim = c.Locals("mw.auth.claims").(model.JWTToken)
+		e     *pkg.AppError
+	)
+	query := c.Params("id")
+	if query == "" {
+		return c.Status(http.StatusBadRequest).JSON(payload.BaseResponse{
+			Status:  http.StatusBadRequest,
+			Message: "id is required",
+		},
+		)
+	}
+
+	if claim.UUID == "" {
+		return c.Status(http.StatusUnauthorized).JSON(payload.BaseResponse{
+			Status:  http.StatusUnauthorized,
+			Message: "unauthorized",
+		},
+		)
+	}
+
+	listQuery, err := pkg.ParseListQuery(c.Queries())
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(payload.BaseResponse{
+			Status:  http.StatusBadRequest,
+			Message: err.Error(),
+		},
+		)
+	}
+
+	res, meta, err := h.Service.Attachment.GetAllAttachmentsByAssignmentID(c.UserContext(), query, listQuery)
+	if err != nil {
+		resError := payload.BaseResponse{
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Error:   err,
+		}
+		if errors.As(err, &e) {
+			resError.Status = e.StatusCode
+			resError.Message = e.Message
+			resError.Error = e.Err
+		} else {
+			resError.Status = http.StatusInternalServerError
+		}
+		return c.Status(resError.Status).JSON(resError)
+	}
+
+	response := payload.BaseResponseWithMeta{
+		BaseResponse: payload.BaseResponse{
+			Status:  http.StatusOK,
+			Message: "success",
+			Data:    res,
+		},
+		Meta: meta,
+	}
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+// DownloadAttachment streams the file behind a signed link, it does not require a session
+func (h *AttachmentHandler) DownloadAttachment(c *fiber.Ctx) (err error) {
+	var e *pkg.AppError
+	id := c.Params("id")
+	if id == "" {
+		return c.Status(http.StatusBadRequest).JSON(payload.BaseResponse{
+			Status:  http.StatusBadRequest,
+			Message: "id is required",
+		},
+		)
+	}
+
+	res, err := h.Service.Attachment.DownloadAttachment(c.UserContext(), id, c.Query("expires"), c.Query("signature"))
+	if err != nil {
+		resError := payload.BaseResponse{
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Error:   err,
+		}
+		if errors.As(err, &e) {
+			resError.Status = e.StatusCode
+			resError.Message = e.Message
+			resError.Error = e.Err
+		} else {
+			resError.Status = http.StatusInternalServerError
+		}
+		return c.Status(resError.Status).JSON(resError)
+	}
+
+	c.Set(fiber.HeaderContentType, res.ContentType)
+	c.Set(fiber.HeaderContentDisposition, mime.FormatMediaType("attachment", map[string]string{"filename": res.FileName}))
+	c.Set(fiber.HeaderETag, strconv.Quote(res.Checksum))
+	c.Set(fiber.HeaderCacheControl, "private, no-store")
+	// the stream is closed by fasthttp once the body has been written
+	return c.Status(http.StatusOK).SendStream(res.Content, int(res.SizeBytes))
+}
+
+func uploadAttachmentRequest(fileHeader *multipart.FileHeader, file multipart.File) *payload.UploadAttachmentRequest {
+	return &payload.UploadAttachmentRequest{
+		FileName: filepath.Base(fileHeader.Filename),
+		Size:     fileHeader.Size,
+		Content:  file,
+	}
+}
@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"edukita-teaching-grading/internal/app/model"
+	"edukita-teaching-grading/internal/app/payload"
+	"edukita-teaching-grading/internal/pkg"
+	"errors"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type AuditHandler struct {
+	HandlerOptions
+}
+
+func (h *AuditHandler) GetAllAuditEvents(c *fiber.Ctx) (err error) {
+	var (
+		claim = c.Locals("mw.auth.claims").(model.JWTToken)
+		e     *pkg.AppError
+	)
+	if claim.UUID == "" {
+		return c.Status(http.StatusUnauthorized).JSON(payload.BaseResponse{
+			Status:  http.StatusUnauthorized,
+			Message: "unauthorized",
+		},
+		)
+	}
+
+	listQuery, err := pkg.ParseListQuery(c.Queries())
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(payload.BaseResponse{
+			Status:  http.StatusBadRequest,
+			Message: err.Error(),
+		},
+		)
+	}
+
+	res, meta, err := h.Service.Audit.GetAllAuditEvents(c.UserContext(), listQuery)
+	if err != nil {
+		resError := payload.BaseResponse{
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Error:   err,
+		}
+		if errors.As(err, &e) {
+			resError.Status = e.StatusCode
+			resError.Message = e.Message
+			resError.Error = e.Err
+		} else {
+			resError.Status = http.StatusInternalServerError
+		}
+		return c.Status(resError.Status).JSON(resError)
+	}
+
+	response := payload.BaseResponseWithMeta{
+		BaseResponse: payload.BaseResponse{
+			Status:  http.StatusOK,
+			Message: "success",
+			Data:    res,
+		},
+		Meta: meta,
+	}
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+func (h *AuditHandler) VerifyAuditChain(c *fiber.Ctx) (err error) {
+	var (
+		claim = c.Locals("mw.auth.claims").(model.JWTToken)
+		e     *pkg.AppError
+	)
+	if claim.UUID == "" {
+		return c.Status(http.StatusUnauthorized).JSON(payload.BaseResponse{
+			Status:  http.StatusUnauthorized,
+			Message: "unauthorized",
+		},
+		)
+	}
+
+	res, err := h.Service.Audit.VerifyAuditChain(c.UserContext())
+	if err != nil {
+		resError := payload.BaseResponse{
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Error:   err,
+		}
+		if errors.As(err, &e) {
+			resError.Status = e.StatusCode
+			resError.Message = e.Message
+			resError.Error = e.Err
+		} else {
+			resError.Status = http.StatusInternalServerError
+		}
+		return c.Status(resError.Status).JSON(resError)
+	}
+
+	response := payload.BaseResponse{
+		Status:  http.StatusOK,
+		Message: "success",
+		Data:    res,
+	}
+	return c.Status(http.StatusOK).JSON(response)
+}
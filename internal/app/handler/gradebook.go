@@ -0,0 +1,343 @@
+package handler
+
+import (
+	"edukita-teaching-grading/internal/app/model"
+	"edukita-teaching-grading/internal/app/payload"
+	"edukita-teaching-grading/internal/pkg"
+	"errors"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type GradebookHandler struct {
+	HandlerOptions
+}
+
+func (h *GradebookHandler) CreateCategory(c *fiber.Ctx) (err error) {
+	var (
+		claim = c.Locals("mw.auth.claims").(model.JWTToken)
+		id    = c.Params("id")
+		e     *pkg.AppError
+	)
+	if id == "" {
+		return c.Status(http.StatusBadRequest).JSON(payload.BaseResponse{
+			Status:  http.StatusBadRequest,
+			Message: "id is required",
+		},
+		)
+	}
+
+	req := new(payload.CreateCategoryRequest)
+	if err = c.BodyParser(req); err != nil {
+		return
+	}
+
+	v := NewValidator()
+	if errs := v.Validate(req); len(errs) > 0 {
+		return c.Status(http.StatusBadRequest).JSON(payload.BaseResponse{
+			Status:  http.StatusBadRequest,
+			Message: "invalid request body",
+			Error:   errs,
+		},
+		)
+	}
+
+	if claim.UUID == "" {
+		return c.Status(http.StatusUnauthorized).JSON(payload.BaseResponse{
+			Status:  http.StatusUnauthorized,
+			Message: "unauthorized",
+		},
+		)
+	}
+
+	res, err := h.Service.Gradebook.CreateCategory(c.UserContext(), id, req)
+	if err != nil {
+		resError := payload.BaseResponse{
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Error:   err,
+		}
+		if errors.As(err, &e) {
+			resError.Status = e.StatusCode
+			resError.Message = e.Message
+			resError.Error = e.Err
+		} else {
+			resError.Status = http.StatusInternalServerError
+		}
+		return c.Status(resError.Status).JSON(resError)
+	}
+
+	response := payload.BaseResponse{
+		Status:  http.StatusCreated,
+		Message: "success",
+		Data:    res,
+	}
+	return c.Status(http.StatusCreated).JSON(response)
+}
+
+func (h *GradebookHandler) GetAllCategoriesByCourseID(c *fiber.Ctx) (err error) {
+	var (
+		claim = c.Locals("mw.auth.claims").(model.JWTToken)
+		id    = c.Params("id")
+		e     *pkg.AppError
+	)
+	if id == "" {
+		return c.Status(http.StatusBadRequest).JSON(payload.BaseResponse{
+			Status:  http.StatusBadRequest,
+			Message: "id is required",
+		},
+		)
+	}
+
+	if claim.UUID == "" {
+		return c.Status(http.StatusUnauthorized).JSON(payload.BaseResponse{
+			Status:  http.StatusUnauthorized,
+			Message: "unauthorized",
+		},
+		)
+	}
+
+	listQuery, err := pkg.ParseListQuery(c.Queries())
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(payload.BaseResponse{
+			Status:  http.StatusBadRequest,
+			Message: err.Error(),
+		},
+		)
+	}
+
+	res, meta, err := h.Service.Gradebook.GetAllCategoriesByCourseID(c.UserContext(), id, listQuery)
+	if err != nil {
+		resError := payload.BaseResponse{
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Error:   err,
+		}
+		if errors.As(err, &e) {
+			resError.Status = e.StatusCode
+			resError.Message = e.Message
+			resError.Error = e.Err
+		} else {
+			resError.Status = http.StatusInternalServerError
+		}
+		return c.Status(resError.Status).JSON(resError)
+	}
+
+	response := payload.BaseResponseWithMeta{
+		BaseResponse: payload.BaseResponse{
+			Status:  http.StatusOK,
+			Message: "success",
+			Data:    res,
+		},
+		Meta: meta,
+	}
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+func (h *GradebookHandler) UpdateCategoryByID(c *fiber.Ctx) (err error) {
+	var (
+		claim      = c.Locals("mw.auth.claims").(model.JWTToken)
+		id         = c.Params("id")
+		categoryID = c.Params("category_id")
+		e          *pkg.AppError
+	)
+	if id == "" || categoryID == "" {
+		return c.Status(http.StatusBadRequest).JSON(payload.BaseResponse{
+			Status:  http.StatusBadRequest,
+			Message: "id and category_id are required",
+		},
+		)
+	}
+
+	req := new(payload.UpdateCategoryRequest)
+	if err = c.BodyParser(req); err != nil {
+		return
+	}
+
+	v := NewValidator()
+	if errs := v.Validate(req); len(errs) > 0 {
+		return c.Status(http.StatusBadRequest).JSON(payload.BaseResponse{
+			Status:  http.StatusBadRequest,
+			Message: "invalid request body",
+			Error:   errs,
+		},
+		)
+	}
+
+	if claim.UUID == "" {
+		return c.Status(http.StatusUnauthorized).JSON(payload.BaseResponse{
+			Status:  http.StatusUnauthorized,
+			Message: "unauthorized",
+		},
+		)
+	}
+
+	res, err := h.Service.Gradebook.UpdateCategoryByID(c.UserContext(), id, categoryID, req)
+	if err != nil {
+		resError := payload.BaseResponse{
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Error:   err,
+		}
+		if errors.As(err, &e) {
+			resError.Status = e.StatusCode
+			resError.Message = e.Message
+			resError.Error = e.Err
+		} else {
+			resError.Status = http.StatusInternalServerError
+		}
+		return c.Status(resError.Status).JSON(resError)
+	}
+
+	response := payload.BaseResponse{
+		Status:  http.StatusOK,
+		Message: "success",
+		Data:    res,
+	}
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+func (h *GradebookHandler) DeleteCategoryByID(c *fiber.Ctx) (err error) {
+	var (
+		claim      = c.Locals("mw.auth.claims").(model.JWTToken)
+		id         = c.Params("id")
+		categoryID = c.Params("category_id")
+		e          *pkg.AppError
+	)
+	if id == "" || categoryID == "" {
+		return c.Status(http.StatusBadRequest).JSON(payload.BaseResponse{
+			Status:  http.StatusBadRequest,
+			Message: "id and category_id are required",
+		},
+		)
+	}
+
+	if claim.UUID == "" {
+		return c.Status(http.StatusUnauthorized).JSON(payload.BaseResponse{
+			Status:  http.StatusUnauthorized,
+			Message: "unauthorized",
+		},
+		)
+	}
+
+	res, err := h.Service.Gradebook.DeleteCategoryByID(c.UserContext(), id, categoryID)
+	if err != nil {
+		resError := payload.BaseResponse{
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Error:   err,
+		}
+		if errors.As(err, &e) {
+			resError.Status = e.StatusCode
+			resError.Message = e.Message
+			resError.Error = e.Err
+		} else {
+			resError.Status = http.StatusInternalServerError
+		}
+		return c.Status(resError.Status).JSON(resError)
+	}
+
+	response := payload.BaseResponse{
+		Status:  http.StatusOK,
+		Message: "success",
+		Data:    res,
+	}
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+func (h *GradebookHandler) GetCourseGradebook(c *fiber.Ctx) (err error) {
+	var (
+		claim = c.Locals("mw.auth.claims").(model.JWTToken)
+		id    = c.Params("id")
+		e     *pkg.AppError
+	)
+	if id == "" {
+		return c.Status(http.StatusBadRequest).JSON(payload.BaseResponse{
+			Status:  http.StatusBadRequest,
+			Message: "id is required",
+		},
+		)
+	}
+
+	if claim.UUID == "" {
+		return c.Status(http.StatusUnauthorized).JSON(payload.BaseResponse{
+			Status:  http.StatusUnauthorized,
+			Message: "unauthorized",
+		},
+		)
+	}
+
+	res, err := h.Service.Gradebook.GetCourseGradebook(c.UserContext(), id)
+	if err != nil {
+		resError := payload.BaseResponse{
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Error:   err,
+		}
+		if errors.As(err, &e) {
+			resError.Status = e.StatusCode
+			resError.Message = e.Message
+			resError.Error = e.Err
+		} else {
+			resError.Status = http.StatusInternalServerError
+		}
+		return c.Status(resError.Status).JSON(resError)
+	}
+
+	response := payload.BaseResponse{
+		Status:  http.StatusOK,
+		Message: "success",
+		Data:    res,
+	}
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+// GetStudentGradebook serves both /gradebook/students/:student_id and /gradebook/me, which defaults to the caller
+func (h *GradebookHandler) GetStudentGradebook(c *fiber.Ctx) (err error) {
+	var (
+		claim     = c.Locals("mw.auth.claims").(model.JWTToken)
+		id        = c.Params("id")
+		studentID = c.Params("student_id", claim.UUID)
+		e         *pkg.AppError
+	)
+	if id == "" || studentID == "" {
+		return c.Status(http.StatusBadRequest).JSON(payload.BaseResponse{
+			Status:  http.StatusBadRequest,
+			Message: "id and student_id are required",
+		},
+		)
+	}
+
+	if claim.UUID == "" {
+		return c.Status(http.StatusUnauthorized).JSON(payload.BaseResponse{
+			Status:  http.StatusUnauthorized,
+			Message: "unauthorized",
+		},
+		)
+	}
+
+	res, err := h.Service.Gradebook.GetStudentGradebook(c.UserContext(), id, studentID)
+	if err != nil {
+		resError := payload.BaseResponse{
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Error:   err,
+		}
+		if errors.As(err, &e) {
+			resError.Status = e.StatusCode
+			resError.Message = e.Message
+			resError.Error = e.Err
+		} else {
+			resError.Status = http.StatusInternalServerError
+		}
+		return c.Status(resError.Status).JSON(resError)
+	}
+
+	response := payload.BaseResponse{
+		Status:  http.StatusOK,
+		Message: "success",
+		Data:    res,
+	}
+	return c.Status(http.StatusOK).JSON(response)
+}
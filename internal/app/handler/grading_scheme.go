@@ -0,0 +1,201 @@
+package handler
+
+import (
+	"edukita-teaching-grading/internal/app/model"
+	"edukita-teaching-grading/internal/app/payload"
+	"edukita-teaching-grading/internal/pkg"
+	"errors"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func (h *GradebookHandler) CreateGradingScheme(c *fiber.Ctx) (err error) {
+	var (
+		claim = c.Locals("mw.auth.claims").(model.JWTToken)
+		e     *pkg.AppError
+	)
+	req := new(payload.CreateGradingSchemeRequest)
+	if err = c.BodyParser(req); err != nil {
+		return
+	}
+
+	v := NewValidator()
+	if errs := v.Validate(req); len(errs) > 0 {
+		return c.Status(http.StatusBadRequest).JSON(payload.BaseResponse{
+			Status:  http.StatusBadRequest,
+			Message: "invalid request body",
+			Error:   errs,
+		},
+		)
+	}
+
+	if claim.UUID == "" {
+		return c.Status(http.StatusUnauthorized).JSON(payload.BaseResponse{
+			Status:  http.StatusUnauthorized,
+			Message: "unauthorized",
+		},
+		)
+	}
+
+	res, err := h.Service.Gradebook.CreateGradingScheme(c.UserContext(), req)
+	if err != nil {
+		resError := payload.BaseResponse{
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Error:   err,
+		}
+		if errors.As(err, &e) {
+			resError.Status = e.StatusCode
+			resError.Message = e.Message
+			resError.Error = e.Err
+		} else {
+			resError.Status = http.StatusInternalServerError
+		}
+		return c.Status(resError.Status).JSON(resError)
+	}
+
+	response := payload.BaseResponse{
+		Status:  http.StatusCreated,
+		Message: "success",
+		Data:    res,
+	}
+	return c.Status(http.StatusCreated).JSON(response)
+}
+
+func (h *GradebookHandler) GetGradingSchemeByID(c *fiber.Ctx) (err error) {
+	var (
+		claim = c.Locals("mw.auth.claims").(model.JWTToken)
+		e     *pkg.AppError
+	)
+	query := c.Params("id")
+	if query == "" {
+		return c.Status(http.StatusBadRequest).JSON(payload.BaseResponse{
+			Status:  http.StatusBadRequest,
+			Message: "id is required",
+		},
+		)
+	}
+
+	if claim.UUID == "" {
+		return c.Status(http.StatusUnauthorized).JSON(payload.BaseResponse{
+			Status:  http.StatusUnauthorized,
+			Message: "unauthorized",
+		},
+		)
+	}
+
+	res, err := h.Service.Gradebook.GetGradingSchemeByID(c.UserContext(), query)
+	if err != nil {
+		resError := payload.BaseResponse{
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Error:   err,
+		}
+		if errors.As(err, &e) {
+			resError.Status = e.StatusCode
+			resError.Message = e.Message
+			resError.Error = e.Err
+		} else {
+			resError.Status = http.StatusInternalServerError
+		}
+		return c.Status(resError.Status).JSON(resError)
+	}
+
+	response := payload.BaseResponse{
+		Status:  http.StatusOK,
+		Message: "success",
+		Data:    res,
+	}
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+func (h *GradebookHandler) GetAllGradingSchemes(c *fiber.Ctx) (err error) {
+	var (
+		claim = c.Locals("mw.auth.claims").(model.JWTToken)
+		e     *pkg.AppError
+	)
+	if claim.UUID == "" {
+		return c.Status(http.StatusUnauthorized).JSON(payload.BaseResponse{
+			Status:  http.StatusUnauthorized,
+			Message: "unauthorized",
+		},
+		)
+	}
+
+	listQuery, err := pkg.ParseListQuery(c.Queries())
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(payload.BaseResponse{
+			Status:  http.StatusBadRequest,
+			Message: err.Error(),
+		},
+		)
+	}
+
+	res, meta, err := h.Service.Gradebook.GetAllGradingSchemes(c.UserContext(), listQuery)
+	if err != nil {
+		resError := payload.BaseResponse{
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Error:   err,
+		}
+		if errors.As(err, &e) {
+			resError.Status = e.StatusCode
+			resError.Message = e.Message
+			resError.Error = e.Err
+		} else {
+			resError.Status = http.StatusInternalServerError
+		}
+		return c.Status(resError.Status).JSON(resError)
+	}
+
+	response := payload.BaseResponseWithMeta{
+		BaseResponse: payload.BaseResponse{
+			Status:  http.StatusOK,
+			Message: "success",
+			Data:    res,
+		},
+		Meta: meta,
+	}
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+// GetStudentTranscript serves both /transcripts/students/:student_id and /transcripts/me, which defaults to the caller
+func (h *GradebookHandler) GetStudentTranscript(c *fiber.Ctx) (err error) {
+	var (
+		claim     = c.Locals("mw.auth.claims").(model.JWTToken)
+		studentID = c.Params("student_id", claim.UUID)
+		e         *pkg.AppError
+	)
+	if claim.UUID == "" {
+		return c.Status(http.StatusUnauthorized).JSON(payload.BaseResponse{
+			Status:  http.StatusUnauthorized,
+			Message: "unauthorized",
+		},
+		)
+	}
+
+	res, err := h.Service.Gradebook.GetStudentTranscript(c.UserContext(), studentID)
+	if err != nil {
+		resError := payload.BaseResponse{
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Error:   err,
+		}
+		if errors.As(err, &e) {
+			resError.Status = e.StatusCode
+			resError.Message = e.Message
+			resError.Error = e.Err
+		} else {
+			resError.Status = http.StatusInternalServerError
+		}
+		return c.Status(resError.Status).JSON(resError)
+	}
+
+	response := payload.BaseResponse{
+		Status:  http.StatusOK,
+		Message: "success",
+		Data:    res,
+	}
+	return c.Status(http.StatusOK).JSON(response)
+}
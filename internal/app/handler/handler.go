@@ -6,6 +6,7 @@ import (
 	"reflect"
 	"strings"
 
+	"edukita-teaching-grading/internal/app/policy"
 	"edukita-teaching-grading/internal/app/repository"
 	"edukita-teaching-grading/internal/app/service"
 	"edukita-teaching-grading/internal/pkg"
@@ -17,6 +18,7 @@ type HandlerOptions struct {
 	pkg.OptionsApplication
 	*service.Service
 	*repository.Repository
+	Policy *policy.Policy
 }
 
 // SimpleValidator provides basic validation functionality
@@ -42,7 +42,7 @@ func (h *LMSHandler) CreateCourse(c *fiber.Ctx) (err error) {
 		)
 	}
 
-	res, err := h.Service.LearningManagement.CreateCourse(c.Context(), req)
+	res, err := h.Service.LearningManagement.CreateCourse(c.UserContext(), req)
 	if err != nil {
 		resError := payload.BaseResponse{
 			Status:  http.StatusInternalServerError,
@@ -89,7 +89,7 @@ func (h *LMSHandler) GetCourseByID(c *fiber.Ctx) (err error) {
 		)
 	}
 
-	res, err := h.Service.LearningManagement.GetCourseByID(c.Context(), query)
+	res, err := h.Service.LearningManagement.GetCourseByID(c.UserContext(), query)
 	if err != nil {
 		resError := payload.BaseResponse{
 			Status:  http.StatusInternalServerError,
@@ -136,7 +136,7 @@ func (h *LMSHandler) GetCourseByCode(c *fiber.Ctx) (err error) {
 		)
 	}
 
-	res, err := h.Service.LearningManagement.GetCourseByCode(c.Context(), query)
+	res, err := h.Service.LearningManagement.GetCourseByCode(c.UserContext(), query)
 	if err != nil {
 		resError := payload.BaseResponse{
 			Status:  http.StatusInternalServerError,
@@ -174,7 +174,81 @@ func (h *LMSHandler) GetAllCourses(c *fiber.Ctx) (err error) {
 		)
 	}
 
-	res, err := h.Service.LearningManagement.GetAllCourses(c.Context())
+	listQuery, err := pkg.ParseListQuery(c.Queries())
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(payload.BaseResponse{
+			Status:  http.StatusBadRequest,
+			Message: err.Error(),
+		},
+		)
+	}
+
+	res, meta, err := h.Service.LearningManagement.GetAllCourses(c.UserContext(), listQuery)
+	if err != nil {
+		resError := payload.BaseResponse{
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Error:   err,
+		}
+		if errors.As(err, &e) {
+			resError.Status = e.StatusCode
+			resError.Message = e.Message
+			resError.Error = e.Err
+		} else {
+			resError.Status = http.StatusInternalServerError
+		}
+		return c.Status(resError.Status).JSON(resError)
+	}
+
+	response := payload.BaseResponseWithMeta{
+		BaseResponse: payload.BaseResponse{
+			Status:  http.StatusOK,
+			Message: "success",
+			Data:    res,
+		},
+		Meta: meta,
+	}
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+func (h *LMSHandler) UpdateCourseByID(c *fiber.Ctx) (err error) {
+	var (
+		claim = c.Locals("mw.auth.claims").(model.JWTToken)
+		id    = c.Params("id")
+		e     *pkg.AppError
+	)
+	if id == "" {
+		return c.Status(http.StatusBadRequest).JSON(payload.BaseResponse{
+			Status:  http.StatusBadRequest,
+			Message: "id is required",
+		},
+		)
+	}
+
+	req := new(payload.UpdateCourseRequest)
+	if err = c.BodyParser(req); err != nil {
+		return
+	}
+
+	v := NewValidator()
+	if errs := v.Validate(req); len(errs) > 0 {
+		return c.Status(http.StatusBadRequest).JSON(payload.BaseResponse{
+			Status:  http.StatusBadRequest,
+			Message: "invalid request body",
+			Error:   errs,
+		},
+		)
+	}
+
+	if claim.UUID == "" {
+		return c.Status(http.StatusUnauthorized).JSON(payload.BaseResponse{
+			Status:  http.StatusUnauthorized,
+			Message: "unauthorized",
+		},
+		)
+	}
+
+	res, err := h.Service.LearningManagement.UpdateCourseByID(c.UserContext(), id, req)
 	if err != nil {
 		resError := payload.BaseResponse{
 			Status:  http.StatusInternalServerError,
@@ -199,7 +273,7 @@ func (h *LMSHandler) GetAllCourses(c *fiber.Ctx) (err error) {
 	return c.Status(http.StatusOK).JSON(response)
 }
 
-func (h *LMSHandler) UpdateCourseByID(c *fiber.Ctx) (err error) {
+func (h *LMSHandler) AddCourseTeacher(c *fiber.Ctx) (err error) {
 	var (
 		claim = c.Locals("mw.auth.claims").(model.JWTToken)
 		id    = c.Params("id")
@@ -213,7 +287,7 @@ func (h *LMSHandler) UpdateCourseByID(c *fiber.Ctx) (err error) {
 		)
 	}
 
-	req := new(payload.UpdateCourseRequest)
+	req := new(payload.AddCourseTeacherRequest)
 	if err = c.BodyParser(req); err != nil {
 		return
 	}
@@ -236,7 +310,114 @@ func (h *LMSHandler) UpdateCourseByID(c *fiber.Ctx) (err error) {
 		)
 	}
 
-	res, err := h.Service.LearningManagement.UpdateCourseByID(c.Context(), id, req)
+	res, err := h.Service.LearningManagement.AddCourseTeacher(c.UserContext(), id, req)
+	if err != nil {
+		resError := payload.BaseResponse{
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Error:   err,
+		}
+		if errors.As(err, &e) {
+			resError.Status = e.StatusCode
+			resError.Message = e.Message
+			resError.Error = e.Err
+		} else {
+			resError.Status = http.StatusInternalServerError
+		}
+		return c.Status(resError.Status).JSON(resError)
+	}
+
+	response := payload.BaseResponse{
+		Status:  http.StatusOK,
+		Message: "success",
+		Data:    res,
+	}
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+func (h *LMSHandler) GetAllCourseTeachersByCourseID(c *fiber.Ctx) (err error) {
+	var (
+		claim = c.Locals("mw.auth.claims").(model.JWTToken)
+		e     *pkg.AppError
+	)
+	query := c.Params("id")
+	if query == "" {
+		return c.Status(http.StatusBadRequest).JSON(payload.BaseResponse{
+			Status:  http.StatusBadRequest,
+			Message: "id is required",
+		},
+		)
+	}
+
+	if claim.UUID == "" {
+		return c.Status(http.StatusUnauthorized).JSON(payload.BaseResponse{
+			Status:  http.StatusUnauthorized,
+			Message: "unauthorized",
+		},
+		)
+	}
+
+	listQuery, err := pkg.ParseListQuery(c.Queries())
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(payload.BaseResponse{
+			Status:  http.StatusBadRequest,
+			Message: err.Error(),
+		},
+		)
+	}
+
+	res, meta, err := h.Service.LearningManagement.GetAllCourseTeachersByCourseID(c.UserContext(), query, listQuery)
+	if err != nil {
+		resError := payload.BaseResponse{
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Error:   err,
+		}
+		if errors.As(err, &e) {
+			resError.Status = e.StatusCode
+			resError.Message = e.Message
+			resError.Error = e.Err
+		} else {
+			resError.Status = http.StatusInternalServerError
+		}
+		return c.Status(resError.Status).JSON(resError)
+	}
+
+	response := payload.BaseResponseWithMeta{
+		BaseResponse: payload.BaseResponse{
+			Status:  http.StatusOK,
+			Message: "success",
+			Data:    res,
+		},
+		Meta: meta,
+	}
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+func (h *LMSHandler) RemoveCourseTeacher(c *fiber.Ctx) (err error) {
+	var (
+		claim     = c.Locals("mw.auth.claims").(model.JWTToken)
+		id        = c.Params("id")
+		teacherID = c.Params("teacher_id")
+		e         *pkg.AppError
+	)
+	if id == "" || teacherID == "" {
+		return c.Status(http.StatusBadRequest).JSON(payload.BaseResponse{
+			Status:  http.StatusBadRequest,
+			Message: "id and teacher_id are required",
+		},
+		)
+	}
+
+	if claim.UUID == "" {
+		return c.Status(http.StatusUnauthorized).JSON(payload.BaseResponse{
+			Status:  http.StatusUnauthorized,
+			Message: "unauthorized",
+		},
+		)
+	}
+
+	res, err := h.Service.LearningManagement.RemoveCourseTeacher(c.UserContext(), id, teacherID)
 	if err != nil {
 		resError := payload.BaseResponse{
 			Status:  http.StatusInternalServerError,
@@ -289,7 +470,7 @@ func (h *LMSHandler) CreateAssignment(c *fiber.Ctx) (err error) {
 		)
 	}
 
-	res, err := h.Service.LearningManagement.CreateAssignment(c.Context(), req)
+	res, err := h.Service.LearningManagement.CreateAssignment(c.UserContext(), req)
 	if err != nil {
 		resError := payload.BaseResponse{
 			Status:  http.StatusInternalServerError,
@@ -336,7 +517,7 @@ func (h *LMSHandler) GetAssignmentByID(c *fiber.Ctx) (err error) {
 		)
 	}
 
-	res, err := h.Service.LearningManagement.GetAssignmentByID(c.Context(), query)
+	res, err := h.Service.LearningManagement.GetAssignmentByID(c.UserContext(), query)
 	if err != nil {
 		resError := payload.BaseResponse{
 			Status:  http.StatusInternalServerError,
@@ -398,7 +579,7 @@ func (h *LMSHandler) UpdateAssignmentByID(c *fiber.Ctx) (err error) {
 		)
 	}
 
-	res, err := h.Service.LearningManagement.UpdateAssignmentByID(c.Context(), id, req)
+	res, err := h.Service.LearningManagement.UpdateAssignmentByID(c.UserContext(), id, req)
 	if err != nil {
 		resError := payload.BaseResponse{
 			Status:  http.StatusInternalServerError,
@@ -451,7 +632,7 @@ func (h *LMSHandler) CreateSubmission(c *fiber.Ctx) (err error) {
 		)
 	}
 
-	res, err := h.Service.LearningManagement.CreateSubmission(c.Context(), req.AssignmentID, req)
+	res, err := h.Service.LearningManagement.CreateSubmission(c.UserContext(), req.AssignmentID, req)
 	if err != nil {
 		resError := payload.BaseResponse{
 			Status:  http.StatusInternalServerError,
@@ -498,7 +679,7 @@ func (h *LMSHandler) GetSubmissionByID(c *fiber.Ctx) (err error) {
 		)
 	}
 
-	res, err := h.Service.LearningManagement.GetSubmissionByID(c.Context(), query)
+	res, err := h.Service.LearningManagement.GetSubmissionByID(c.UserContext(), query)
 	if err != nil {
 		resError := payload.BaseResponse{
 			Status:  http.StatusInternalServerError,
@@ -560,7 +741,7 @@ func (h *LMSHandler) UpdateSubmissionByID(c *fiber.Ctx) (err error) {
 		)
 	}
 
-	res, err := h.Service.LearningManagement.UpdateSubmissionByID(c.Context(), id, req)
+	res, err := h.Service.LearningManagement.UpdateSubmissionByID(c.UserContext(), id, req)
 	if err != nil {
 		resError := payload.BaseResponse{
 			Status:  http.StatusInternalServerError,
@@ -607,7 +788,16 @@ func (h *LMSHandler) GetAllSubmissionsByCourseID(c *fiber.Ctx) (err error) {
 		)
 	}
 
-	res, err := h.Service.LearningManagement.GetAllSubmissionsByCourseID(c.Context(), query, claim.UUID)
+	listQuery, err := pkg.ParseListQuery(c.Queries())
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(payload.BaseResponse{
+			Status:  http.StatusBadRequest,
+			Message: err.Error(),
+		},
+		)
+	}
+
+	res, meta, err := h.Service.LearningManagement.GetAllSubmissionsByCourseID(c.UserContext(), query, listQuery)
 	if err != nil {
 		resError := payload.BaseResponse{
 			Status:  http.StatusInternalServerError,
@@ -624,10 +814,13 @@ func (h *LMSHandler) GetAllSubmissionsByCourseID(c *fiber.Ctx) (err error) {
 		return c.Status(resError.Status).JSON(resError)
 	}
 
-	response := payload.BaseResponse{
-		Status:  http.StatusOK,
-		Message: "success",
-		Data:    res,
+	response := payload.BaseResponseWithMeta{
+		BaseResponse: payload.BaseResponse{
+			Status:  http.StatusOK,
+			Message: "success",
+			Data:    res,
+		},
+		Meta: meta,
 	}
 	return c.Status(http.StatusOK).JSON(response)
 }
@@ -654,7 +847,16 @@ func (h *LMSHandler) GetAllSubmissionsByAssignmentID(c *fiber.Ctx) (err error) {
 		)
 	}
 
-	res, err := h.Service.LearningManagement.GetAllSubmissionsByAssignmentID(c.Context(), query, claim.UUID)
+	listQuery, err := pkg.ParseListQuery(c.Queries())
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(payload.BaseResponse{
+			Status:  http.StatusBadRequest,
+			Message: err.Error(),
+		},
+		)
+	}
+
+	res, meta, err := h.Service.LearningManagement.GetAllSubmissionsByAssignmentID(c.UserContext(), query, listQuery)
 	if err != nil {
 		resError := payload.BaseResponse{
 			Status:  http.StatusInternalServerError,
@@ -671,10 +873,13 @@ func (h *LMSHandler) GetAllSubmissionsByAssignmentID(c *fiber.Ctx) (err error) {
 		return c.Status(resError.Status).JSON(resError)
 	}
 
-	response := payload.BaseResponse{
-		Status:  http.StatusOK,
-		Message: "success",
-		Data:    res,
+	response := payload.BaseResponseWithMeta{
+		BaseResponse: payload.BaseResponse{
+			Status:  http.StatusOK,
+			Message: "success",
+			Data:    res,
+		},
+		Meta: meta,
 	}
 	return c.Status(http.StatusOK).JSON(response)
 }
@@ -701,7 +906,717 @@ func (h *LMSHandler) GetAllSubmissionsByUserID(c *fiber.Ctx) (err error) {
 		)
 	}
 
-	res, err := h.Service.LearningManagement.GetAllSubmissionsByUserID(c.Context(), query)
+	listQuery, err := pkg.ParseListQuery(c.Queries())
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(payload.BaseResponse{
+			Status:  http.StatusBadRequest,
+			Message: err.Error(),
+		},
+		)
+	}
+
+	res, meta, err := h.Service.LearningManagement.GetAllSubmissionsByUserID(c.UserContext(), query, listQuery)
+	if err != nil {
+		resError := payload.BaseResponse{
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Error:   err,
+		}
+		if errors.As(err, &e) {
+			resError.Status = e.StatusCode
+			resError.Message = e.Message
+			resError.Error = e.Err
+		} else {
+			resError.Status = http.StatusInternalServerError
+		}
+		return c.Status(resError.Status).JSON(resError)
+	}
+
+	response := payload.BaseResponseWithMeta{
+		BaseResponse: payload.BaseResponse{
+			Status:  http.StatusOK,
+			Message: "success",
+			Data:    res,
+		},
+		Meta: meta,
+	}
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+func (h *LMSHandler) GetAllSubmissionAttempts(c *fiber.Ctx) (err error) {
+	var (
+		claim = c.Locals("mw.auth.claims").(model.JWTToken)
+		e     *pkg.AppError
+	)
+	query := c.Params("id")
+	if query == "" {
+		return c.Status(http.StatusBadRequest).JSON(payload.BaseResponse{
+			Status:  http.StatusBadRequest,
+			Message: "id is required",
+		},
+		)
+	}
+
+	if claim.UUID == "" {
+		return c.Status(http.StatusUnauthorized).JSON(payload.BaseResponse{
+			Status:  http.StatusUnauthorized,
+			Message: "unauthorized",
+		},
+		)
+	}
+
+	listQuery, err := pkg.ParseListQuery(c.Queries())
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(payload.BaseResponse{
+			Status:  http.StatusBadRequest,
+			Message: err.Error(),
+		},
+		)
+	}
+
+	res, meta, err := h.Service.LearningManagement.GetAllSubmissionAttempts(c.UserContext(), query, listQuery)
+	if err != nil {
+		resError := payload.BaseResponse{
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Error:   err,
+		}
+		if errors.As(err, &e) {
+			resError.Status = e.StatusCode
+			resError.Message = e.Message
+			resError.Error = e.Err
+		} else {
+			resError.Status = http.StatusInternalServerError
+		}
+		return c.Status(resError.Status).JSON(resError)
+	}
+
+	response := payload.BaseResponseWithMeta{
+		BaseResponse: payload.BaseResponse{
+			Status:  http.StatusOK,
+			Message: "success",
+			Data:    res,
+		},
+		Meta: meta,
+	}
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+func (h *LMSHandler) DiffSubmissionAttempts(c *fiber.Ctx) (err error) {
+	var (
+		claim = c.Locals("mw.auth.claims").(model.JWTToken)
+		e     *pkg.AppError
+	)
+	query := c.Params("id")
+	if query == "" {
+		return c.Status(http.StatusBadRequest).JSON(payload.BaseResponse{
+			Status:  http.StatusBadRequest,
+			Message: "id is required",
+		},
+		)
+	}
+
+	if claim.UUID == "" {
+		return c.Status(http.StatusUnauthorized).JSON(payload.BaseResponse{
+			Status:  http.StatusUnauthorized,
+			Message: "unauthorized",
+		},
+		)
+	}
+
+	res, err := h.Service.LearningManagement.DiffSubmissionAttempts(c.UserContext(), query, c.QueryInt("from"), c.QueryInt("to"))
+	if err != nil {
+		resError := payload.BaseResponse{
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Error:   err,
+		}
+		if errors.As(err, &e) {
+			resError.Status = e.StatusCode
+			resError.Message = e.Message
+			resError.Error = e.Err
+		} else {
+			resError.Status = http.StatusInternalServerError
+		}
+		return c.Status(resError.Status).JSON(resError)
+	}
+
+	response := payload.BaseResponse{
+		Status:  http.StatusOK,
+		Message: "success",
+		Data:    res,
+	}
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+func (h *LMSHandler) EnrollStudent(c *fiber.Ctx) (err error) {
+	var (
+		claim = c.Locals("mw.auth.claims").(model.JWTToken)
+		id    = c.Params("id")
+		e     *pkg.AppError
+	)
+	if id == "" {
+		return c.Status(http.StatusBadRequest).JSON(payload.BaseResponse{
+			Status:  http.StatusBadRequest,
+			Message: "id is required",
+		},
+		)
+	}
+
+	req := new(payload.EnrollStudentRequest)
+	if err = c.BodyParser(req); err != nil {
+		return
+	}
+
+	v := NewValidator()
+	if errs := v.Validate(req); len(errs) > 0 {
+		return c.Status(http.StatusBadRequest).JSON(payload.BaseResponse{
+			Status:  http.StatusBadRequest,
+			Message: "invalid request body",
+			Error:   errs,
+		},
+		)
+	}
+
+	if claim.UUID == "" {
+		return c.Status(http.StatusUnauthorized).JSON(payload.BaseResponse{
+			Status:  http.StatusUnauthorized,
+			Message: "unauthorized",
+		},
+		)
+	}
+
+	res, err := h.Service.LearningManagement.EnrollStudent(c.UserContext(), id, req)
+	if err != nil {
+		resError := payload.BaseResponse{
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Error:   err,
+		}
+		if errors.As(err, &e) {
+			resError.Status = e.StatusCode
+			resError.Message = e.Message
+			resError.Error = e.Err
+		} else {
+			resError.Status = http.StatusInternalServerError
+		}
+		return c.Status(resError.Status).JSON(resError)
+	}
+
+	response := payload.BaseResponse{
+		Status:  http.StatusOK,
+		Message: "success",
+		Data:    res,
+	}
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+func (h *LMSHandler) DropEnrollment(c *fiber.Ctx) (err error) {
+	var (
+		claim     = c.Locals("mw.auth.claims").(model.JWTToken)
+		id        = c.Params("id")
+		studentID = c.Params("student_id")
+		e         *pkg.AppError
+	)
+	if id == "" || studentID == "" {
+		return c.Status(http.StatusBadRequest).JSON(payload.BaseResponse{
+			Status:  http.StatusBadRequest,
+			Message: "id and student_id are required",
+		},
+		)
+	}
+
+	if claim.UUID == "" {
+		return c.Status(http.StatusUnauthorized).JSON(payload.BaseResponse{
+			Status:  http.StatusUnauthorized,
+			Message: "unauthorized",
+		},
+		)
+	}
+
+	res, err := h.Service.LearningManagement.DropEnrollment(c.UserContext(), id, studentID)
+	if err != nil {
+		resError := payload.BaseResponse{
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Error:   err,
+		}
+		if errors.As(err, &e) {
+			resError.Status = e.StatusCode
+			resError.Message = e.Message
+			resError.Error = e.Err
+		} else {
+			resError.Status = http.StatusInternalServerError
+		}
+		return c.Status(resError.Status).JSON(resError)
+	}
+
+	response := payload.BaseResponse{
+		Status:  http.StatusOK,
+		Message: "success",
+		Data:    res,
+	}
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+func (h *LMSHandler) GetAllEnrollmentsByCourseID(c *fiber.Ctx) (err error) {
+	var (
+		claim = c.Locals("mw.auth.claims").(model.JWTToken)
+		e     *pkg.AppError
+	)
+	query := c.Params("id")
+	if query == "" {
+		return c.Status(http.StatusBadRequest).JSON(payload.BaseResponse{
+			Status:  http.StatusBadRequest,
+			Message: "id is required",
+		},
+		)
+	}
+
+	if claim.UUID == "" {
+		return c.Status(http.StatusUnauthorized).JSON(payload.BaseResponse{
+			Status:  http.StatusUnauthorized,
+			Message: "unauthorized",
+		},
+		)
+	}
+
+	listQuery, err := pkg.ParseListQuery(c.Queries())
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(payload.BaseResponse{
+			Status:  http.StatusBadRequest,
+			Message: err.Error(),
+		},
+		)
+	}
+
+	res, meta, err := h.Service.LearningManagement.GetAllEnrollmentsByCourseID(c.UserContext(), query, listQuery)
+	if err != nil {
+		resError := payload.BaseResponse{
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Error:   err,
+		}
+		if errors.As(err, &e) {
+			resError.Status = e.StatusCode
+			resError.Message = e.Message
+			resError.Error = e.Err
+		} else {
+			resError.Status = http.StatusInternalServerError
+		}
+		return c.Status(resError.Status).JSON(resError)
+	}
+
+	response := payload.BaseResponseWithMeta{
+		BaseResponse: payload.BaseResponse{
+			Status:  http.StatusOK,
+			Message: "success",
+			Data:    res,
+		},
+		Meta: meta,
+	}
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+func (h *LMSHandler) DeleteCourseByID(c *fiber.Ctx) (err error) {
+	var (
+		claim = c.Locals("mw.auth.claims").(model.JWTToken)
+		id    = c.Params("id")
+		e     *pkg.AppError
+	)
+	if id == "" {
+		return c.Status(http.StatusBadRequest).JSON(payload.BaseResponse{
+			Status:  http.StatusBadRequest,
+			Message: "id is required",
+		},
+		)
+	}
+
+	if claim.UUID == "" {
+		return c.Status(http.StatusUnauthorized).JSON(payload.BaseResponse{
+			Status:  http.StatusUnauthorized,
+			Message: "unauthorized",
+		},
+		)
+	}
+
+	res, err := h.Service.LearningManagement.DeleteCourseByID(c.UserContext(), id)
+	if err != nil {
+		resError := payload.BaseResponse{
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Error:   err,
+		}
+		if errors.As(err, &e) {
+			resError.Status = e.StatusCode
+			resError.Message = e.Message
+			resError.Error = e.Err
+		} else {
+			resError.Status = http.StatusInternalServerError
+		}
+		return c.Status(resError.Status).JSON(resError)
+	}
+
+	response := payload.BaseResponse{
+		Status:  http.StatusOK,
+		Message: "success",
+		Data:    res,
+	}
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+func (h *LMSHandler) RestoreCourseByID(c *fiber.Ctx) (err error) {
+	var (
+		claim = c.Locals("mw.auth.claims").(model.JWTToken)
+		id    = c.Params("id")
+		e     *pkg.AppError
+	)
+	if id == "" {
+		return c.Status(http.StatusBadRequest).JSON(payload.BaseResponse{
+			Status:  http.StatusBadRequest,
+			Message: "id is required",
+		},
+		)
+	}
+
+	if claim.UUID == "" {
+		return c.Status(http.StatusUnauthorized).JSON(payload.BaseResponse{
+			Status:  http.StatusUnauthorized,
+			Message: "unauthorized",
+		},
+		)
+	}
+
+	res, err := h.Service.LearningManagement.RestoreCourseByID(c.UserContext(), id)
+	if err != nil {
+		resError := payload.BaseResponse{
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Error:   err,
+		}
+		if errors.As(err, &e) {
+			resError.Status = e.StatusCode
+			resError.Message = e.Message
+			resError.Error = e.Err
+		} else {
+			resError.Status = http.StatusInternalServerError
+		}
+		return c.Status(resError.Status).JSON(resError)
+	}
+
+	response := payload.BaseResponse{
+		Status:  http.StatusOK,
+		Message: "success",
+		Data:    res,
+	}
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+func (h *LMSHandler) PurgeCourseByID(c *fiber.Ctx) (err error) {
+	var (
+		claim = c.Locals("mw.auth.claims").(model.JWTToken)
+		id    = c.Params("id")
+		e     *pkg.AppError
+	)
+	if id == "" {
+		return c.Status(http.StatusBadRequest).JSON(payload.BaseResponse{
+			Status:  http.StatusBadRequest,
+			Message: "id is required",
+		},
+		)
+	}
+
+	if claim.UUID == "" {
+		return c.Status(http.StatusUnauthorized).JSON(payload.BaseResponse{
+			Status:  http.StatusUnauthorized,
+			Message: "unauthorized",
+		},
+		)
+	}
+
+	res, err := h.Service.LearningManagement.PurgeCourseByID(c.UserContext(), id)
+	if err != nil {
+		resError := payload.BaseResponse{
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Error:   err,
+		}
+		if errors.As(err, &e) {
+			resError.Status = e.StatusCode
+			resError.Message = e.Message
+			resError.Error = e.Err
+		} else {
+			resError.Status = http.StatusInternalServerError
+		}
+		return c.Status(resError.Status).JSON(resError)
+	}
+
+	response := payload.BaseResponse{
+		Status:  http.StatusOK,
+		Message: "success",
+		Data:    res,
+	}
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+func (h *LMSHandler) DeleteAssignmentByID(c *fiber.Ctx) (err error) {
+	var (
+		claim = c.Locals("mw.auth.claims").(model.JWTToken)
+		id    = c.Params("id")
+		e     *pkg.AppError
+	)
+	if id == "" {
+		return c.Status(http.StatusBadRequest).JSON(payload.BaseResponse{
+			Status:  http.StatusBadRequest,
+			Message: "id is required",
+		},
+		)
+	}
+
+	if claim.UUID == "" {
+		return c.Status(http.StatusUnauthorized).JSON(payload.BaseResponse{
+			Status:  http.StatusUnauthorized,
+			Message: "unauthorized",
+		},
+		)
+	}
+
+	res, err := h.Service.LearningManagement.DeleteAssignmentByID(c.UserContext(), id)
+	if err != nil {
+		resError := payload.BaseResponse{
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Error:   err,
+		}
+		if errors.As(err, &e) {
+			resError.Status = e.StatusCode
+			resError.Message = e.Message
+			resError.Error = e.Err
+		} else {
+			resError.Status = http.StatusInternalServerError
+		}
+		return c.Status(resError.Status).JSON(resError)
+	}
+
+	response := payload.BaseResponse{
+		Status:  http.StatusOK,
+		Message: "success",
+		Data:    res,
+	}
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+func (h *LMSHandler) RestoreAssignmentByID(c *fiber.Ctx) (err error) {
+	var (
+		claim = c.Locals("mw.auth.claims").(model.JWTToken)
+		id    = c.Params("id")
+		e     *pkg.AppError
+	)
+	if id == "" {
+		return c.Status(http.StatusBadRequest).JSON(payload.BaseResponse{
+			Status:  http.StatusBadRequest,
+			Message: "id is required",
+		},
+		)
+	}
+
+	if claim.UUID == "" {
+		return c.Status(http.StatusUnauthorized).JSON(payload.BaseResponse{
+			Status:  http.StatusUnauthorized,
+			Message: "unauthorized",
+		},
+		)
+	}
+
+	res, err := h.Service.LearningManagement.RestoreAssignmentByID(c.UserContext(), id)
+	if err != nil {
+		resError := payload.BaseResponse{
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Error:   err,
+		}
+		if errors.As(err, &e) {
+			resError.Status = e.StatusCode
+			resError.Message = e.Message
+			resError.Error = e.Err
+		} else {
+			resError.Status = http.StatusInternalServerError
+		}
+		return c.Status(resError.Status).JSON(resError)
+	}
+
+	response := payload.BaseResponse{
+		Status:  http.StatusOK,
+		Message: "success",
+		Data:    res,
+	}
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+func (h *LMSHandler) PurgeAssignmentByID(c *fiber.Ctx) (err error) {
+	var (
+		claim = c.Locals("mw.auth.claims").(model.JWTToken)
+		id    = c.Params("id")
+		e     *pkg.AppError
+	)
+	if id == "" {
+		return c.Status(http.StatusBadRequest).JSON(payload.BaseResponse{
+			Status:  http.StatusBadRequest,
+			Message: "id is required",
+		},
+		)
+	}
+
+	if claim.UUID == "" {
+		return c.Status(http.StatusUnauthorized).JSON(payload.BaseResponse{
+			Status:  http.StatusUnauthorized,
+			Message: "unauthorized",
+		},
+		)
+	}
+
+	res, err := h.Service.LearningManagement.PurgeAssignmentByID(c.UserContext(), id)
+	if err != nil {
+		resError := payload.BaseResponse{
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Error:   err,
+		}
+		if errors.As(err, &e) {
+			resError.Status = e.StatusCode
+			resError.Message = e.Message
+			resError.Error = e.Err
+		} else {
+			resError.Status = http.StatusInternalServerError
+		}
+		return c.Status(resError.Status).JSON(resError)
+	}
+
+	response := payload.BaseResponse{
+		Status:  http.StatusOK,
+		Message: "success",
+		Data:    res,
+	}
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+func (h *LMSHandler) DeleteSubmissionByID(c *fiber.Ctx) (err error) {
+	var (
+		claim = c.Locals("mw.auth.claims").(model.JWTToken)
+		id    = c.Params("id")
+		e     *pkg.AppError
+	)
+	if id == "" {
+		return c.Status(http.StatusBadRequest).JSON(payload.BaseResponse{
+			Status:  http.StatusBadRequest,
+			Message: "id is required",
+		},
+		)
+	}
+
+	if claim.UUID == "" {
+		return c.Status(http.StatusUnauthorized).JSON(payload.BaseResponse{
+			Status:  http.StatusUnauthorized,
+			Message: "unauthorized",
+		},
+		)
+	}
+
+	res, err := h.Service.LearningManagement.DeleteSubmissionByID(c.UserContext(), id)
+	if err != nil {
+		resError := payload.BaseResponse{
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Error:   err,
+		}
+		if errors.As(err, &e) {
+			resError.Status = e.StatusCode
+			resError.Message = e.Message
+			resError.Error = e.Err
+		} else {
+			resError.Status = http.StatusInternalServerError
+		}
+		return c.Status(resError.Status).JSON(resError)
+	}
+
+	response := payload.BaseResponse{
+		Status:  http.StatusOK,
+		Message: "success",
+		Data:    res,
+	}
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+func (h *LMSHandler) RestoreSubmissionByID(c *fiber.Ctx) (err error) {
+	var (
+		claim = c.Locals("mw.auth.claims").(model.JWTToken)
+		id    = c.Params("id")
+		e     *pkg.AppError
+	)
+	if id == "" {
+		return c.Status(http.StatusBadRequest).JSON(payload.BaseResponse{
+			Status:  http.StatusBadRequest,
+			Message: "id is required",
+		},
+		)
+	}
+
+	if claim.UUID == "" {
+		return c.Status(http.StatusUnauthorized).JSON(payload.BaseResponse{
+			Status:  http.StatusUnauthorized,
+			Message: "unauthorized",
+		},
+		)
+	}
+
+	res, err := h.Service.LearningManagement.RestoreSubmissionByID(c.UserContext(), id)
+	if err != nil {
+		resError := payload.BaseResponse{
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Error:   err,
+		}
+		if errors.As(err, &e) {
+			resError.Status = e.StatusCode
+			resError.Message = e.Message
+			resError.Error = e.Err
+		} else {
+			resError.Status = http.StatusInternalServerError
+		}
+		return c.Status(resError.Status).JSON(resError)
+	}
+
+	response := payload.BaseResponse{
+		Status:  http.StatusOK,
+		Message: "success",
+		Data:    res,
+	}
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+func (h *LMSHandler) PurgeSubmissionByID(c *fiber.Ctx) (err error) {
+	var (
+		claim = c.Locals("mw.auth.claims").(model.JWTToken)
+		id    = c.Params("id")
+		e     *pkg.AppError
+	)
+	if id == "" {
+		return c.Status(http.StatusBadRequest).JSON(payload.BaseResponse{
+			Status:  http.StatusBadRequest,
+			Message: "id is required",
+		},
+		)
+	}
+
+	if claim.UUID == "" {
+		return c.Status(http.StatusUnauthorized).JSON(payload.BaseResponse{
+			Status:  http.StatusUnauthorized,
+			Message: "unauthorized",
+		},
+		)
+	}
+
+	res, err := h.Service.LearningManagement.PurgeSubmissionByID(c.UserContext(), id)
 	if err != nil {
 		resError := payload.BaseResponse{
 			Status:  http.StatusInternalServerError,
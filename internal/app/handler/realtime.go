@@ -0,0 +1,211 @@
+package handler
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"edukita-teaching-grading/internal/app/model"
+	"edukita-teaching-grading/internal/app/payload"
+	"edukita-teaching-grading/internal/pkg"
+	"edukita-teaching-grading/pkg/pubsub"
+
+	"github.com/gofiber/contrib/websocket"
+	"github.com/gofiber/fiber/v2"
+)
+
+// realtimeSubscriptionKey carries the subscription from the upgrade request to the WebSocket connection
+const realtimeSubscriptionKey = "realtime.subscription"
+
+type RealtimeHandler struct {
+	HandlerOptions
+}
+
+// Stream pushes the messages of the user as Server-Sent Events until the client leaves, the access token
+// expires or is revoked or the user leaves a course of the stream, when the client reconnects with a fresh token
+func (h *RealtimeHandler) Stream(c *fiber.Ctx) (err error) {
+	session, err := h.subscribe(c)
+	if err != nil {
+		return h.realtimeError(c, err)
+	}
+
+	c.Set(fiber.HeaderContentType, "text/event-stream")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set(fiber.HeaderConnection, "keep-alive")
+	// stop proxies such as nginx from buffering the stream
+	c.Set("X-Accel-Buffering", "no")
+
+	heartbeat := h.Config.Realtime.Heartbeat
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer session.sub.Close()
+		ticker := time.NewTicker(heartbeat)
+		defer ticker.Stop()
+		expired := time.NewTimer(time.Until(session.expiresAt))
+		defer expired.Stop()
+
+		fmt.Fprintf(w, "retry: %d\n\n", (5 * time.Second).Milliseconds())
+		if w.Flush() != nil {
+			return
+		}
+		for {
+			select {
+			case msg, ok := <-session.sub.C:
+				if !ok {
+					return
+				}
+				data, err := json.Marshal(realtimeMessage(msg))
+				if err != nil {
+					continue
+				}
+				fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", msg.ID, msg.Type, data)
+			case <-ticker.C:
+				if h.check(session) != nil {
+					return
+				}
+				// a comment keeps the connection open and tells a client that left apart from a quiet one
+				fmt.Fprint(w, ": ping\n\n")
+			case <-expired.C:
+				return
+			}
+			if w.Flush() != nil {
+				return
+			}
+		}
+	})
+	return nil
+}
+
+// UpgradeWebSocket subscribes the user before the protocol switch, so a refused subscription is still an HTTP
+// error the client can read
+func (h *RealtimeHandler) UpgradeWebSocket(c *fiber.Ctx) (err error) {
+	if !websocket.IsWebSocketUpgrade(c) {
+		return c.Status(http.StatusUpgradeRequired).JSON(payload.BaseResponse{
+			Status:  http.StatusUpgradeRequired,
+			Message: "websocket upgrade required",
+		})
+	}
+
+	session, err := h.subscribe(c)
+	if err != nil {
+		return h.realtimeError(c, err)
+	}
+	c.Locals(realtimeSubscriptionKey, session)
+	return c.Next()
+}
+
+// realtimeSession is what a stream needs past the request: the subscription, when it ends and what to check it
+// against on each heartbeat
+type realtimeSession struct {
+	sub       *pubsub.Subscription
+	expiresAt time.Time
+	ctx       context.Context
+	courseID  string
+}
+
+// WebSocket sends every message as a JSON text frame and pings the client at the heartbeat interval, closing
+// the connection once the user may no longer listen. Clients only listen, anything they send is ignored.
+func (h *RealtimeHandler) WebSocket() fiber.Handler {
+	heartbeat := h.Config.Realtime.Heartbeat
+	return websocket.New(func(conn *websocket.Conn) {
+		session := conn.Locals(realtimeSubscriptionKey).(realtimeSession)
+		defer session.sub.Close()
+
+		// reading is needed to see close frames and answer pings, it ends when the client leaves
+		left := make(chan struct{})
+		go func() {
+			defer close(left)
+			for {
+				if _, _, err := conn.ReadMessage(); err != nil {
+					return
+				}
+			}
+		}()
+
+		ticker := time.NewTicker(heartbeat)
+		defer ticker.Stop()
+		expired := time.NewTimer(time.Until(session.expiresAt))
+		defer expired.Stop()
+
+		for {
+			select {
+			case msg, ok := <-session.sub.C:
+				if !ok {
+					conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseGoingAway, "server shutting down"))
+					return
+				}
+				if err := conn.WriteJSON(realtimeMessage(msg)); err != nil {
+					return
+				}
+			case <-ticker.C:
+				if err := h.check(session); err != nil {
+					conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "access revoked"))
+					return
+				}
+				if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(heartbeat)); err != nil {
+					return
+				}
+			case <-expired.C:
+				conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "token expired"))
+				return
+			case <-left:
+				return
+			}
+		}
+	})
+}
+
+// subscribe subscribes the authenticated user to the course_id of the query, or to all of their courses
+func (h *RealtimeHandler) subscribe(c *fiber.Ctx) (session realtimeSession, err error) {
+	claim := c.Locals("mw.auth.claims").(model.JWTToken)
+	if claim.UUID == "" {
+		return session, pkg.NewUnauthorizedError("unauthorized", nil)
+	}
+
+	// the stream ends with the access token, tokens without an expiry are cut off after a day
+	session.expiresAt = time.Now().Add(24 * time.Hour)
+	if actor, ok := pkg.ActorFromContext(c.UserContext()); ok && !actor.ExpiresAt.IsZero() {
+		session.expiresAt = actor.ExpiresAt
+	}
+
+	// the stream outlives the request, whose buffers fiber reuses
+	session.ctx = c.UserContext()
+	session.courseID = strings.Clone(c.Query("course_id"))
+	session.sub, err = h.Service.Realtime.Subscribe(session.ctx, session.courseID)
+	return
+}
+
+// check tells whether the stream may go on, the token is not revoked and the user is still in its courses
+func (h *RealtimeHandler) check(session realtimeSession) error {
+	ctx, cancel := context.WithTimeout(session.ctx, h.Config.Realtime.Heartbeat)
+	defer cancel()
+	return h.Service.Realtime.Check(ctx, session.courseID, session.sub)
+}
+
+func (h *RealtimeHandler) realtimeError(c *fiber.Ctx, err error) error {
+	var e *pkg.AppError
+	resError := payload.BaseResponse{
+		Status:  http.StatusInternalServerError,
+		Message: err.Error(),
+		Error:   err,
+	}
+	if errors.As(err, &e) {
+		resError.Status = e.StatusCode
+		resError.Message = e.Message
+		resError.Error = e.Err
+	}
+	return c.Status(resError.Status).JSON(resError)
+}
+
+func realtimeMessage(msg pubsub.Message) payload.RealtimeMessageResponse {
+	return payload.RealtimeMessageResponse{
+		ID:         msg.ID,
+		Type:       msg.Type,
+		OccurredAt: msg.OccurredAt.Format(time.RFC3339),
+		Data:       msg.Data,
+	}
+}
@@ -33,7 +33,7 @@ func (h *UserHandler) RegisterUser(c *fiber.Ctx) (err error) {
 		)
 	}
 
-	res, err := h.Service.User.RegisterUser(c.Context(), *req)
+	res, err := h.Service.User.RegisterUser(c.UserContext(), *req)
 	if err != nil {
 		resError := payload.BaseResponse{
 			Status:  http.StatusInternalServerError,
@@ -73,7 +73,7 @@ func (h *UserHandler) LoginUser(c *fiber.Ctx) (err error) {
 		)
 	}
 
-	res, err := h.Service.User.LoginUser(c.Context(), req)
+	res, err := h.Service.User.LoginUser(c.UserContext(), req)
 	if err != nil {
 		resError := payload.BaseResponse{
 			Status:  http.StatusInternalServerError,
@@ -96,17 +96,10 @@ func (h *UserHandler) LoginUser(c *fiber.Ctx) (err error) {
 		Data:    res,
 	}
 
-	setCookie := fiber.Cookie{
-		Name:     h.Config.Cookies.AccessToken,
-		Value:    res.Token,
-		Path:     "/",
-		Domain:   h.Config.Cookies.Domain,
-		Expires:  time.Now().Add(h.Config.Cookies.SSOExpired),
-		HTTPOnly: false,
-		SameSite: fiber.CookieSameSiteLaxMode,
+	// a login answered with an MFA challenge has no session yet
+	if !res.MFARequired {
+		h.setAuthCookies(c, res)
 	}
-
-	c.Cookie(&setCookie)
 	return c.Status(http.StatusOK).JSON(response)
 }
 
@@ -124,7 +117,13 @@ func (h *UserHandler) LogoutUser(c *fiber.Ctx) (err error) {
 		)
 	}
 
-	res, err := h.Service.User.LogoutUser(c.Context(), claim.UUID)
+	req := new(payload.RefreshTokenRequest)
+	_ = c.BodyParser(req)
+	if req.RefreshToken == "" {
+		req.RefreshToken = c.Cookies(h.Config.Cookies.RefreshToken)
+	}
+
+	res, err := h.Service.User.LogoutUser(c.UserContext(), req.RefreshToken)
 	if err != nil {
 		resError := payload.BaseResponse{
 			Status:  http.StatusInternalServerError,
@@ -141,12 +140,7 @@ func (h *UserHandler) LogoutUser(c *fiber.Ctx) (err error) {
 		return c.Status(resError.Status).JSON(resError)
 	}
 
-	setCookie := fiber.Cookie{
-		Name:    h.Config.Cookies.AccessToken,
-		Value:   "",
-		Expires: time.Now().Add(-1 * time.Hour),
-	}
-	c.Cookie(&setCookie)
+	h.clearAuthCookies(c)
 
 	return c.Status(http.StatusOK).JSON(res)
 }
@@ -156,14 +150,247 @@ func (h *UserHandler) GetUserByID(c *fiber.Ctx) (err error) {
 		claim = c.Locals("mw.auth.claims").(model.JWTToken)
 		e     *pkg.AppError
 	)
-	query := c.Params("id")
-	if query == "" {
+	if claim.UUID == "" {
+		return c.Status(http.StatusUnauthorized).JSON(payload.BaseResponse{
+			Status:  http.StatusUnauthorized,
+			Message: "unauthorized",
+		},
+		)
+	}
+
+	// /me has no id param and resolves to the authenticated user
+	query := c.Params("id", claim.UUID)
+
+	res, err := h.Service.User.GetUserByID(c.UserContext(), query)
+	if err != nil {
+		resError := payload.BaseResponse{
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Error:   err,
+		}
+		if errors.As(err, &e) {
+			resError.Status = e.StatusCode
+			resError.Message = e.Message
+			resError.Error = e.Err
+		} else {
+			resError.Status = http.StatusInternalServerError
+		}
+		return c.Status(resError.Status).JSON(resError)
+	}
+
+	response := payload.BaseResponse{
+		Status:  http.StatusOK,
+		Message: "success",
+		Data:    res,
+	}
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+func (h *UserHandler) RefreshToken(c *fiber.Ctx) (err error) {
+	var e *pkg.AppError
+	req := new(payload.RefreshTokenRequest)
+	if len(c.Body()) > 0 {
+		if err = c.BodyParser(req); err != nil {
+			return
+		}
+	}
+	if req.RefreshToken == "" {
+		req.RefreshToken = c.Cookies(h.Config.Cookies.RefreshToken)
+	}
+
+	if req.RefreshToken == "" {
+		return c.Status(http.StatusUnauthorized).JSON(payload.BaseResponse{
+			Status:  http.StatusUnauthorized,
+			Message: "refresh token is required",
+		},
+		)
+	}
+
+	res, err := h.Service.User.RefreshToken(c.UserContext(), req)
+	if err != nil {
+		resError := payload.BaseResponse{
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Error:   err,
+		}
+		if errors.As(err, &e) {
+			resError.Status = e.StatusCode
+			resError.Message = e.Message
+			resError.Error = e.Err
+		} else {
+			resError.Status = http.StatusInternalServerError
+		}
+		if resError.Status == http.StatusUnauthorized {
+			h.clearAuthCookies(c)
+		}
+		return c.Status(resError.Status).JSON(resError)
+	}
+
+	response := payload.BaseResponse{
+		Status:  http.StatusOK,
+		Message: "success",
+		Data:    res,
+	}
+
+	h.setAuthCookies(c, res)
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+func (h *UserHandler) RevokeUserSessions(c *fiber.Ctx) (err error) {
+	var (
+		claim = c.Locals("mw.auth.claims").(model.JWTToken)
+		id    = c.Params("id")
+		e     *pkg.AppError
+	)
+	if id == "" {
+		return c.Status(http.StatusBadRequest).JSON(payload.BaseResponse{
+			Status:  http.StatusBadRequest,
+			Message: "id is required",
+		},
+		)
+	}
+
+	if claim.UUID == "" {
+		return c.Status(http.StatusUnauthorized).JSON(payload.BaseResponse{
+			Status:  http.StatusUnauthorized,
+			Message: "unauthorized",
+		},
+		)
+	}
+
+	res, err := h.Service.User.RevokeUserSessions(c.UserContext(), id)
+	if err != nil {
+		resError := payload.BaseResponse{
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Error:   err,
+		}
+		if errors.As(err, &e) {
+			resError.Status = e.StatusCode
+			resError.Message = e.Message
+			resError.Error = e.Err
+		} else {
+			resError.Status = http.StatusInternalServerError
+		}
+		return c.Status(resError.Status).JSON(resError)
+	}
+
+	response := payload.BaseResponse{
+		Status:  http.StatusOK,
+		Message: "success",
+		Data:    res,
+	}
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+func (h *UserHandler) DeleteUserByID(c *fiber.Ctx) (err error) {
+	var (
+		claim = c.Locals("mw.auth.claims").(model.JWTToken)
+		id    = c.Params("id")
+		e     *pkg.AppError
+	)
+	if id == "" {
+		return c.Status(http.StatusBadRequest).JSON(payload.BaseResponse{
+			Status:  http.StatusBadRequest,
+			Message: "id is required",
+		},
+		)
+	}
+
+	if claim.UUID == "" {
+		return c.Status(http.StatusUnauthorized).JSON(payload.BaseResponse{
+			Status:  http.StatusUnauthorized,
+			Message: "unauthorized",
+		},
+		)
+	}
+
+	res, err := h.Service.User.DeleteUserByID(c.UserContext(), id)
+	if err != nil {
+		resError := payload.BaseResponse{
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Error:   err,
+		}
+		if errors.As(err, &e) {
+			resError.Status = e.StatusCode
+			resError.Message = e.Message
+			resError.Error = e.Err
+		} else {
+			resError.Status = http.StatusInternalServerError
+		}
+		return c.Status(resError.Status).JSON(resError)
+	}
+
+	response := payload.BaseResponse{
+		Status:  http.StatusOK,
+		Message: "success",
+		Data:    res,
+	}
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+func (h *UserHandler) RestoreUserByID(c *fiber.Ctx) (err error) {
+	var (
+		claim = c.Locals("mw.auth.claims").(model.JWTToken)
+		id    = c.Params("id")
+		e     *pkg.AppError
+	)
+	if id == "" {
+		return c.Status(http.StatusBadRequest).JSON(payload.BaseResponse{
+			Status:  http.StatusBadRequest,
+			Message: "id is required",
+		},
+		)
+	}
+
+	if claim.UUID == "" {
+		return c.Status(http.StatusUnauthorized).JSON(payload.BaseResponse{
+			Status:  http.StatusUnauthorized,
+			Message: "unauthorized",
+		},
+		)
+	}
+
+	res, err := h.Service.User.RestoreUserByID(c.UserContext(), id)
+	if err != nil {
+		resError := payload.BaseResponse{
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Error:   err,
+		}
+		if errors.As(err, &e) {
+			resError.Status = e.StatusCode
+			resError.Message = e.Message
+			resError.Error = e.Err
+		} else {
+			resError.Status = http.StatusInternalServerError
+		}
+		return c.Status(resError.Status).JSON(resError)
+	}
+
+	response := payload.BaseResponse{
+		Status:  http.StatusOK,
+		Message: "success",
+		Data:    res,
+	}
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+func (h *UserHandler) PurgeUserByID(c *fiber.Ctx) (err error) {
+	var (
+		claim = c.Locals("mw.auth.claims").(model.JWTToken)
+		id    = c.Params("id")
+		e     *pkg.AppError
+	)
+	if id == "" {
 		return c.Status(http.StatusBadRequest).JSON(payload.BaseResponse{
 			Status:  http.StatusBadRequest,
 			Message: "id is required",
 		},
 		)
 	}
+
 	if claim.UUID == "" {
 		return c.Status(http.StatusUnauthorized).JSON(payload.BaseResponse{
 			Status:  http.StatusUnauthorized,
@@ -172,7 +399,7 @@ func (h *UserHandler) GetUserByID(c *fiber.Ctx) (err error) {
 		)
 	}
 
-	res, err := h.Service.User.GetUserByID(c.Context(), query)
+	res, err := h.Service.User.PurgeUserByID(c.UserContext(), id)
 	if err != nil {
 		resError := payload.BaseResponse{
 			Status:  http.StatusInternalServerError,
@@ -196,3 +423,584 @@ func (h *UserHandler) GetUserByID(c *fiber.Ctx) (err error) {
 	}
 	return c.Status(http.StatusOK).JSON(response)
 }
+
+func (h *UserHandler) ForgotPassword(c *fiber.Ctx) (err error) {
+	var e *pkg.AppError
+	req := new(payload.ForgotPasswordRequest)
+	if err = c.BodyParser(req); err != nil {
+		return
+	}
+
+	v := NewValidator()
+	if errs := v.Validate(req); len(errs) > 0 {
+		return c.Status(http.StatusBadRequest).JSON(payload.BaseResponse{
+			Status:  http.StatusBadRequest,
+			Message: "invalid request body",
+			Error:   errs,
+		},
+		)
+	}
+
+	err = h.Service.User.ForgotPassword(c.UserContext(), *req)
+	if err != nil {
+		resError := payload.BaseResponse{
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Error:   err,
+		}
+		if errors.As(err, &e) {
+			resError.Status = e.StatusCode
+			resError.Message = e.Message
+			resError.Error = e.Err
+		} else {
+			resError.Status = http.StatusInternalServerError
+		}
+		return c.Status(resError.Status).JSON(resError)
+	}
+
+	response := payload.BaseResponse{
+		Status:  http.StatusOK,
+		Message: "if the email belongs to an account, a password reset link has been sent to it",
+	}
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+func (h *UserHandler) ResetPassword(c *fiber.Ctx) (err error) {
+	var e *pkg.AppError
+	req := new(payload.ResetPasswordRequest)
+	if err = c.BodyParser(req); err != nil {
+		return
+	}
+
+	v := NewValidator()
+	if errs := v.Validate(req); len(errs) > 0 {
+		return c.Status(http.StatusBadRequest).JSON(payload.BaseResponse{
+			Status:  http.StatusBadRequest,
+			Message: "invalid request body",
+			Error:   errs,
+		},
+		)
+	}
+
+	res, err := h.Service.User.ResetPassword(c.UserContext(), *req)
+	if err != nil {
+		resError := payload.BaseResponse{
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Error:   err,
+		}
+		if errors.As(err, &e) {
+			resError.Status = e.StatusCode
+			resError.Message = e.Message
+			resError.Error = e.Err
+		} else {
+			resError.Status = http.StatusInternalServerError
+		}
+		return c.Status(resError.Status).JSON(resError)
+	}
+
+	response := payload.BaseResponse{
+		Status:  http.StatusOK,
+		Message: "success",
+		Data:    res,
+	}
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+func (h *UserHandler) ChangePassword(c *fiber.Ctx) (err error) {
+	var (
+		claim = c.Locals("mw.auth.claims").(model.JWTToken)
+		e     *pkg.AppError
+	)
+	if claim.UUID == "" {
+		return c.Status(http.StatusUnauthorized).JSON(payload.BaseResponse{
+			Status:  http.StatusUnauthorized,
+			Message: "unauthorized",
+		},
+		)
+	}
+
+	req := new(payload.ChangePasswordRequest)
+	if err = c.BodyParser(req); err != nil {
+		return
+	}
+
+	v := NewValidator()
+	if errs := v.Validate(req); len(errs) > 0 {
+		return c.Status(http.StatusBadRequest).JSON(payload.BaseResponse{
+			Status:  http.StatusBadRequest,
+			Message: "invalid request body",
+			Error:   errs,
+		},
+		)
+	}
+
+	res, err := h.Service.User.ChangePassword(c.UserContext(), *req)
+	if err != nil {
+		resError := payload.BaseResponse{
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Error:   err,
+		}
+		if errors.As(err, &e) {
+			resError.Status = e.StatusCode
+			resError.Message = e.Message
+			resError.Error = e.Err
+		} else {
+			resError.Status = http.StatusInternalServerError
+		}
+		return c.Status(resError.Status).JSON(resError)
+	}
+
+	response := payload.BaseResponse{
+		Status:  http.StatusOK,
+		Message: "success",
+		Data:    res,
+	}
+
+	h.setAuthCookies(c, res)
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+func (h *UserHandler) VerifyEmail(c *fiber.Ctx) (err error) {
+	var e *pkg.AppError
+	req := new(payload.VerifyEmailRequest)
+	if err = c.BodyParser(req); err != nil {
+		return
+	}
+
+	v := NewValidator()
+	if errs := v.Validate(req); len(errs) > 0 {
+		return c.Status(http.StatusBadRequest).JSON(payload.BaseResponse{
+			Status:  http.StatusBadRequest,
+			Message: "invalid request body",
+			Error:   errs,
+		},
+		)
+	}
+
+	res, err := h.Service.User.VerifyEmail(c.UserContext(), *req)
+	if err != nil {
+		resError := payload.BaseResponse{
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Error:   err,
+		}
+		if errors.As(err, &e) {
+			resError.Status = e.StatusCode
+			resError.Message = e.Message
+			resError.Error = e.Err
+		} else {
+			resError.Status = http.StatusInternalServerError
+		}
+		return c.Status(resError.Status).JSON(resError)
+	}
+
+	response := payload.BaseResponse{
+		Status:  http.StatusOK,
+		Message: "success",
+		Data:    res,
+	}
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+func (h *UserHandler) ResendVerification(c *fiber.Ctx) (err error) {
+	var e *pkg.AppError
+	req := new(payload.ResendVerificationRequest)
+	if err = c.BodyParser(req); err != nil {
+		return
+	}
+
+	v := NewValidator()
+	if errs := v.Validate(req); len(errs) > 0 {
+		return c.Status(http.StatusBadRequest).JSON(payload.BaseResponse{
+			Status:  http.StatusBadRequest,
+			Message: "invalid request body",
+			Error:   errs,
+		},
+		)
+	}
+
+	err = h.Service.User.ResendVerification(c.UserContext(), *req)
+	if err != nil {
+		resError := payload.BaseResponse{
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Error:   err,
+		}
+		if errors.As(err, &e) {
+			resError.Status = e.StatusCode
+			resError.Message = e.Message
+			resError.Error = e.Err
+		} else {
+			resError.Status = http.StatusInternalServerError
+		}
+		return c.Status(resError.Status).JSON(resError)
+	}
+
+	response := payload.BaseResponse{
+		Status:  http.StatusOK,
+		Message: "if the email belongs to an unverified account, a verification link has been sent to it",
+	}
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+func (h *UserHandler) LoginMFA(c *fiber.Ctx) (err error) {
+	var e *pkg.AppError
+	req := new(payload.LoginMFARequest)
+	if err = c.BodyParser(req); err != nil {
+		return
+	}
+
+	v := NewValidator()
+	if errs := v.Validate(req); len(errs) > 0 {
+		return c.Status(http.StatusBadRequest).JSON(payload.BaseResponse{
+			Status:  http.StatusBadRequest,
+			Message: "invalid request body",
+			Error:   errs,
+		},
+		)
+	}
+
+	res, err := h.Service.User.LoginMFA(c.UserContext(), *req)
+	if err != nil {
+		resError := payload.BaseResponse{
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Error:   err,
+		}
+		if errors.As(err, &e) {
+			resError.Status = e.StatusCode
+			resError.Message = e.Message
+			resError.Error = e.Err
+		} else {
+			resError.Status = http.StatusInternalServerError
+		}
+		return c.Status(resError.Status).JSON(resError)
+	}
+
+	response := payload.BaseResponse{
+		Status:  http.StatusOK,
+		Message: "success",
+		Data:    res,
+	}
+
+	h.setAuthCookies(c, res)
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+func (h *UserHandler) EnrollMFAChallenge(c *fiber.Ctx) (err error) {
+	var e *pkg.AppError
+	req := new(payload.MFAChallengeRequest)
+	if err = c.BodyParser(req); err != nil {
+		return
+	}
+
+	v := NewValidator()
+	if errs := v.Validate(req); len(errs) > 0 {
+		return c.Status(http.StatusBadRequest).JSON(payload.BaseResponse{
+			Status:  http.StatusBadRequest,
+			Message: "invalid request body",
+			Error:   errs,
+		},
+		)
+	}
+
+	res, err := h.Service.User.EnrollMFAChallenge(c.UserContext(), *req)
+	if err != nil {
+		resError := payload.BaseResponse{
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Error:   err,
+		}
+		if errors.As(err, &e) {
+			resError.Status = e.StatusCode
+			resError.Message = e.Message
+			resError.Error = e.Err
+		} else {
+			resError.Status = http.StatusInternalServerError
+		}
+		return c.Status(resError.Status).JSON(resError)
+	}
+
+	response := payload.BaseResponse{
+		Status:  http.StatusOK,
+		Message: "success",
+		Data:    res,
+	}
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+func (h *UserHandler) GetMFAStatus(c *fiber.Ctx) (err error) {
+	var (
+		claim = c.Locals("mw.auth.claims").(model.JWTToken)
+		e     *pkg.AppError
+	)
+	if claim.UUID == "" {
+		return c.Status(http.StatusUnauthorized).JSON(payload.BaseResponse{
+			Status:  http.StatusUnauthorized,
+			Message: "unauthorized",
+		},
+		)
+	}
+
+	res, err := h.Service.User.GetMFAStatus(c.UserContext())
+	if err != nil {
+		resError := payload.BaseResponse{
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Error:   err,
+		}
+		if errors.As(err, &e) {
+			resError.Status = e.StatusCode
+			resError.Message = e.Message
+			resError.Error = e.Err
+		} else {
+			resError.Status = http.StatusInternalServerError
+		}
+		return c.Status(resError.Status).JSON(resError)
+	}
+
+	response := payload.BaseResponse{
+		Status:  http.StatusOK,
+		Message: "success",
+		Data:    res,
+	}
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+func (h *UserHandler) EnrollMFA(c *fiber.Ctx) (err error) {
+	var (
+		claim = c.Locals("mw.auth.claims").(model.JWTToken)
+		e     *pkg.AppError
+	)
+	if claim.UUID == "" {
+		return c.Status(http.StatusUnauthorized).JSON(payload.BaseResponse{
+			Status:  http.StatusUnauthorized,
+			Message: "unauthorized",
+		},
+		)
+	}
+
+	res, err := h.Service.User.EnrollMFA(c.UserContext())
+	if err != nil {
+		resError := payload.BaseResponse{
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Error:   err,
+		}
+		if errors.As(err, &e) {
+			resError.Status = e.StatusCode
+			resError.Message = e.Message
+			resError.Error = e.Err
+		} else {
+			resError.Status = http.StatusInternalServerError
+		}
+		return c.Status(resError.Status).JSON(resError)
+	}
+
+	response := payload.BaseResponse{
+		Status:  http.StatusOK,
+		Message: "success",
+		Data:    res,
+	}
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+func (h *UserHandler) VerifyMFA(c *fiber.Ctx) (err error) {
+	var (
+		claim = c.Locals("mw.auth.claims").(model.JWTToken)
+		e     *pkg.AppError
+	)
+	if claim.UUID == "" {
+		return c.Status(http.StatusUnauthorized).JSON(payload.BaseResponse{
+			Status:  http.StatusUnauthorized,
+			Message: "unauthorized",
+		},
+		)
+	}
+
+	req := new(payload.MFACodeRequest)
+	if err = c.BodyParser(req); err != nil {
+		return
+	}
+
+	v := NewValidator()
+	if errs := v.Validate(req); len(errs) > 0 {
+		return c.Status(http.StatusBadRequest).JSON(payload.BaseResponse{
+			Status:  http.StatusBadRequest,
+			Message: "invalid request body",
+			Error:   errs,
+		},
+		)
+	}
+
+	res, err := h.Service.User.VerifyMFA(c.UserContext(), *req)
+	if err != nil {
+		resError := payload.BaseResponse{
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Error:   err,
+		}
+		if errors.As(err, &e) {
+			resError.Status = e.StatusCode
+			resError.Message = e.Message
+			resError.Error = e.Err
+		} else {
+			resError.Status = http.StatusInternalServerError
+		}
+		return c.Status(resError.Status).JSON(resError)
+	}
+
+	response := payload.BaseResponse{
+		Status:  http.StatusOK,
+		Message: "success",
+		Data:    res,
+	}
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+func (h *UserHandler) RegenerateRecoveryCodes(c *fiber.Ctx) (err error) {
+	var (
+		claim = c.Locals("mw.auth.claims").(model.JWTToken)
+		e     *pkg.AppError
+	)
+	if claim.UUID == "" {
+		return c.Status(http.StatusUnauthorized).JSON(payload.BaseResponse{
+			Status:  http.StatusUnauthorized,
+			Message: "unauthorized",
+		},
+		)
+	}
+
+	req := new(payload.MFACodeRequest)
+	if err = c.BodyParser(req); err != nil {
+		return
+	}
+
+	v := NewValidator()
+	if errs := v.Validate(req); len(errs) > 0 {
+		return c.Status(http.StatusBadRequest).JSON(payload.BaseResponse{
+			Status:  http.StatusBadRequest,
+			Message: "invalid request body",
+			Error:   errs,
+		},
+		)
+	}
+
+	res, err := h.Service.User.RegenerateRecoveryCodes(c.UserContext(), *req)
+	if err != nil {
+		resError := payload.BaseResponse{
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Error:   err,
+		}
+		if errors.As(err, &e) {
+			resError.Status = e.StatusCode
+			resError.Message = e.Message
+			resError.Error = e.Err
+		} else {
+			resError.Status = http.StatusInternalServerError
+		}
+		return c.Status(resError.Status).JSON(resError)
+	}
+
+	response := payload.BaseResponse{
+		Status:  http.StatusOK,
+		Message: "success",
+		Data:    res,
+	}
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+func (h *UserHandler) DisableMFA(c *fiber.Ctx) (err error) {
+	var (
+		claim = c.Locals("mw.auth.claims").(model.JWTToken)
+		e     *pkg.AppError
+	)
+	if claim.UUID == "" {
+		return c.Status(http.StatusUnauthorized).JSON(payload.BaseResponse{
+			Status:  http.StatusUnauthorized,
+			Message: "unauthorized",
+		},
+		)
+	}
+
+	req := new(payload.DisableMFARequest)
+	if err = c.BodyParser(req); err != nil {
+		return
+	}
+
+	v := NewValidator()
+	if errs := v.Validate(req); len(errs) > 0 {
+		return c.Status(http.StatusBadRequest).JSON(payload.BaseResponse{
+			Status:  http.StatusBadRequest,
+			Message: "invalid request body",
+			Error:   errs,
+		},
+		)
+	}
+
+	err = h.Service.User.DisableMFA(c.UserContext(), *req)
+	if err != nil {
+		resError := payload.BaseResponse{
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Error:   err,
+		}
+		if errors.As(err, &e) {
+			resError.Status = e.StatusCode
+			resError.Message = e.Message
+			resError.Error = e.Err
+		} else {
+			resError.Status = http.StatusInternalServerError
+		}
+		return c.Status(resError.Status).JSON(resError)
+	}
+
+	response := payload.BaseResponse{
+		Status:  http.StatusOK,
+		Message: "success",
+	}
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+// setAuthCookies stores the access token for the browser and keeps the refresh token out of reach of scripts
+func (h *UserHandler) setAuthCookies(c *fiber.Ctx, res payload.LoginUserResponse) {
+	c.Cookie(&fiber.Cookie{
+		Name:     h.Config.Cookies.AccessToken,
+		Value:    res.Token,
+		Path:     "/",
+		Domain:   h.Config.Cookies.Domain,
+		Expires:  time.Now().Add(h.Config.Cookies.AccessExpired),
+		HTTPOnly: false,
+		SameSite: fiber.CookieSameSiteLaxMode,
+	})
+	c.Cookie(&fiber.Cookie{
+		Name:     h.Config.Cookies.RefreshToken,
+		Value:    res.RefreshToken,
+		Path:     "/api/v1/user",
+		Domain:   h.Config.Cookies.Domain,
+		Expires:  time.Now().Add(h.Config.Cookies.SSOExpired),
+		HTTPOnly: true,
+		SameSite: fiber.CookieSameSiteStrictMode,
+	})
+}
+
+func (h *UserHandler) clearAuthCookies(c *fiber.Ctx) {
+	c.Cookie(&fiber.Cookie{
+		Name:    h.Config.Cookies.AccessToken,
+		Value:   "",
+		Path:    "/",
+		Domain:  h.Config.Cookies.Domain,
+		Expires: time.Now().Add(-1 * time.Hour),
+	})
+	c.Cookie(&fiber.Cookie{
+		Name:     h.Config.Cookies.RefreshToken,
+		Value:    "",
+		Path:     "/api/v1/user",
+		Domain:   h.Config.Cookies.Domain,
+		Expires:  time.Now().Add(-1 * time.Hour),
+		HTTPOnly: true,
+	})
+}
@@ -0,0 +1,33 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AuditEvent records one change made to an entity. Before and After hold the JSON state of the entity around
+// the change, Before is nil for creations and After for purges.
+type AuditEvent struct {
+	Seq        int64      `db:"seq"`
+	ID         uuid.UUID  `db:"id"`
+	ActorID    *uuid.UUID `db:"actor_id"`
+	ActorRole  *string    `db:"actor_role"`
+	Action     string     `db:"action"`
+	EntityType string     `db:"entity_type"`
+	EntityID   uuid.UUID  `db:"entity_id"`
+	CourseID   *uuid.UUID `db:"course_id"`
+	Before     *string    `db:"before"`
+	After      *string    `db:"after"`
+	IP         *string    `db:"ip"`
+	RequestID  *string    `db:"request_id"`
+	CreatedAt  time.Time  `db:"created_at"`
+	PrevHash   string     `db:"prev_hash"`
+	Hash       string     `db:"hash"`
+}
+
+// AuditChainHead is the last link of the audit chain
+type AuditChainHead struct {
+	Seq  int64  `db:"seq"`
+	Hash string `db:"hash"`
+}
@@ -0,0 +1,74 @@
+package model
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RefreshToken is a rotating, long-lived credential used to obtain new access tokens.
+// Only the SHA-256 hash of the token is stored.
+type RefreshToken struct {
+	ID            uuid.UUID  `db:"id" json:"id"`
+	UserID        uuid.UUID  `db:"user_id" json:"user_id"`
+	TokenHash     string     `db:"token_hash" json:"-"`
+	AccessTokenID string     `db:"access_token_id" json:"access_token_id"`
+	ExpiresAt     time.Time  `db:"expires_at" json:"expires_at"`
+	RevokedAt     *time.Time `db:"revoked_at" json:"revoked_at"`
+	ReplacedBy    *uuid.UUID `db:"replaced_by" json:"replaced_by"`
+	CreatedAt     time.Time  `db:"created_at" json:"created_at"`
+}
+
+// RevokedToken is an entry of the access token deny list keyed on the JWT jti
+type RevokedToken struct {
+	TokenID   string     `db:"token_id" json:"token_id"`
+	UserID    uuid.UUID  `db:"user_id" json:"user_id"`
+	Reason    string     `db:"reason" json:"reason"`
+	ExpiresAt time.Time  `db:"expires_at" json:"expires_at"`
+	RevokedBy *uuid.UUID `db:"revoked_by" json:"revoked_by"`
+	RevokedAt time.Time  `db:"revoked_at" json:"revoked_at"`
+}
+
+// UserToken is a single-use token mailed to a user to reset their password or verify their email address, or
+// handed out at login as the MFA challenge. Only the SHA-256 hash of the token is stored, Attempts counts the codes
+// tried against a challenge.
+type UserToken struct {
+	ID        uuid.UUID  `db:"id" json:"id"`
+	UserID    uuid.UUID  `db:"user_id" json:"user_id"`
+	Purpose   string     `db:"purpose" json:"purpose"`
+	TokenHash string     `db:"token_hash" json:"-"`
+	ExpiresAt time.Time  `db:"expires_at" json:"expires_at"`
+	UsedAt    *time.Time `db:"used_at" json:"used_at"`
+	Attempts  int        `db:"attempts" json:"attempts"`
+	CreatedAt time.Time  `db:"created_at" json:"created_at"`
+}
+
+// Mailed returns the token of a mailed link, derived from the id with the application secret. It is worked out
+// again when the email is sent, so neither the token nor the email with its link is ever stored.
+func (t UserToken) Mailed(secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("user-token:" + t.ID.String()))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// UserMFA is the TOTP enrollment of a user, it is only enforced once EnabledAt is set. Secret is sealed.
+type UserMFA struct {
+	UserID       uuid.UUID  `db:"user_id" json:"user_id"`
+	Secret       string     `db:"secret" json:"-"`
+	EnabledAt    *time.Time `db:"enabled_at" json:"enabled_at"`
+	LastUsedStep int64      `db:"last_used_step" json:"-"`
+	CreatedAt    time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt    time.Time  `db:"updated_at" json:"updated_at"`
+}
+
+// RecoveryCode is a one-time code that stands in for a TOTP code, only its SHA-256 hash is stored
+type RecoveryCode struct {
+	ID        uuid.UUID  `db:"id" json:"id"`
+	UserID    uuid.UUID  `db:"user_id" json:"user_id"`
+	CodeHash  string     `db:"code_hash" json:"-"`
+	UsedAt    *time.Time `db:"used_at" json:"used_at"`
+	CreatedAt time.Time  `db:"created_at" json:"created_at"`
+}
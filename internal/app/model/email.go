@@ -0,0 +1,27 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EmailMessage is an email sent by a send_email job. Data holds the JSON data of its template, it is rendered when
+// it is sent. The link of a reset or verification email carries the token UserTokenID points to, see
+// UserToken.Mailed.
+type EmailMessage struct {
+	ID            uuid.UUID  `db:"id"`
+	UserID        *uuid.UUID `db:"user_id"`
+	ToAddress     string     `db:"to_address"`
+	Template      string     `db:"template"`
+	Data          string     `db:"data"`
+	UserTokenID   *uuid.UUID `db:"user_token_id"`
+	EventID       *uuid.UUID `db:"event_id"`
+	Status        string     `db:"status"`
+	Attempts      int        `db:"attempts"`
+	NextAttemptAt time.Time  `db:"next_attempt_at"`
+	LastError     *string    `db:"last_error"`
+	SentAt        *time.Time `db:"sent_at"`
+	CreatedAt     time.Time  `db:"created_at"`
+	UpdatedAt     time.Time  `db:"updated_at"`
+}
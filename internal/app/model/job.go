@@ -0,0 +1,33 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Job is a unit of background work run by the handler registered for its type, Payload is its JSON input
+type Job struct {
+	ID          uuid.UUID  `db:"id"`
+	Type        string     `db:"type"`
+	Payload     string     `db:"payload"`
+	Priority    int        `db:"priority"`
+	Status      string     `db:"status"`
+	Attempts    int        `db:"attempts"`
+	MaxAttempts int        `db:"max_attempts"`
+	RunAt       time.Time  `db:"run_at"`
+	LockedUntil *time.Time `db:"locked_until"`
+	LockedBy    *string    `db:"locked_by"`
+	LastError   *string    `db:"last_error"`
+	StartedAt   *time.Time `db:"started_at"`
+	FinishedAt  *time.Time `db:"finished_at"`
+	CreatedAt   time.Time  `db:"created_at"`
+	UpdatedAt   time.Time  `db:"updated_at"`
+}
+
+// JobCount is the number of jobs of a type in a status
+type JobCount struct {
+	Type   string `db:"type"`
+	Status string `db:"status"`
+	Count  int64  `db:"count"`
+}
@@ -14,6 +14,27 @@ type Course struct {
 	StartDate   *time.Time `db:"start_date" json:"start_date"`
 	EndDate     *time.Time `db:"end_date" json:"end_date"`
 	IsActive    bool       `db:"is_active" json:"is_active"`
+	// GradingSchemeID turns the percentages of the course into letter grades
+	GradingSchemeID *uuid.UUID `db:"grading_scheme_id" json:"grading_scheme_id"`
+}
+
+// AssignmentCategory is a weighted group of assignments of a course, such as homework or exams
+type AssignmentCategory struct {
+	BaseModel
+	CourseID uuid.UUID `db:"course_id" json:"course_id"`
+	Name     string    `db:"name" json:"name"`
+	// Weight is the share of the course grade in percent
+	Weight float64 `db:"weight" json:"weight"`
+	// DropLowest is the number of lowest scores of the category left out of the grade
+	DropLowest int `db:"drop_lowest" json:"drop_lowest"`
+}
+
+// CourseTeacher grants a teacher co-ownership of a course
+type CourseTeacher struct {
+	CourseID  uuid.UUID `db:"course_id" json:"course_id"`
+	TeacherID uuid.UUID `db:"teacher_id" json:"teacher_id"`
+	CreatedBy uuid.UUID `db:"created_by" json:"created_by"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
 }
 
 // Assignment represents work assigned to students
@@ -27,6 +48,17 @@ type Assignment struct {
 	TeacherID   uuid.UUID `db:"teacher_id" json:"teacher_id"`
 	TotalPoints float64   `db:"total_points" json:"total_points"`
 	IsPublished bool      `db:"is_published" json:"is_published"`
+
+	AvailableFrom      *time.Time `db:"available_from" json:"available_from"`
+	AvailableUntil     *time.Time `db:"available_until" json:"available_until"`
+	LatePolicy         string     `db:"late_policy" json:"late_policy"`
+	LatePenaltyPercent float64    `db:"late_penalty_percent" json:"late_penalty_percent"`
+	// MaxAttempts limits how often a student may hand in work, 0 means unlimited
+	MaxAttempts int `db:"max_attempts" json:"max_attempts"`
+	// RubricID is the rubric submissions are graded with, grades are entered directly when it is nil
+	RubricID *uuid.UUID `db:"rubric_id" json:"rubric_id"`
+	// CategoryID groups the assignment into a weighted category of the course gradebook
+	CategoryID *uuid.UUID `db:"category_id" json:"category_id"`
 }
 
 // Submission represents a student's submitted work for an assignment
@@ -42,4 +74,125 @@ type Submission struct {
 	Feedback     *string    `db:"feedback" json:"feedback"`
 	GradedAt     *time.Time `db:"graded_at" json:"graded_at"`
 	GradedBy     *string    `db:"graded_by" json:"graded_by"`
+
+	IsLate             bool     `db:"is_late" json:"is_late"`
+	LatePenaltyPercent float64  `db:"late_penalty_percent" json:"late_penalty_percent"`
+	RawGrade           *float64 `db:"raw_grade" json:"raw_grade"`
+
+	AttemptCount    int        `db:"attempt_count" json:"attempt_count"`
+	GradedAttemptID *uuid.UUID `db:"graded_attempt_id" json:"graded_attempt_id"`
+}
+
+// SubmissionAttempt is an immutable snapshot of the work handed in for a submission
+type SubmissionAttempt struct {
+	ID                 uuid.UUID `db:"id" json:"id"`
+	SubmissionID       uuid.UUID `db:"submission_id" json:"submission_id"`
+	AttemptNumber      int       `db:"attempt_number" json:"attempt_number"`
+	Content            string    `db:"content" json:"content"`
+	FileURL            *string   `db:"file_url" json:"file_url"`
+	SubmittedAt        time.Time `db:"submitted_at" json:"submitted_at"`
+	IsLate             bool      `db:"is_late" json:"is_late"`
+	LatePenaltyPercent float64   `db:"late_penalty_percent" json:"late_penalty_percent"`
+	CreatedBy          uuid.UUID `db:"created_by" json:"created_by"`
+	CreatedAt          time.Time `db:"created_at" json:"created_at"`
+}
+
+// Enrollment links a student to the roster of a course
+type Enrollment struct {
+	BaseModel
+	CourseID   uuid.UUID  `db:"course_id" json:"course_id"`
+	StudentID  uuid.UUID  `db:"student_id" json:"student_id"`
+	Status     string     `db:"status" json:"status"`
+	EnrolledAt time.Time  `db:"enrolled_at" json:"enrolled_at"`
+	DroppedAt  *time.Time `db:"dropped_at" json:"dropped_at"`
+}
+
+// EnrollmentRoster is an enrollment joined with the student's profile
+type EnrollmentRoster struct {
+	ID            uuid.UUID  `db:"id" json:"id"`
+	CourseID      uuid.UUID  `db:"course_id" json:"course_id"`
+	StudentID     uuid.UUID  `db:"student_id" json:"student_id"`
+	StudentNumber string     `db:"student_number" json:"student_number"`
+	FirstName     string     `db:"first_name" json:"first_name"`
+	LastName      string     `db:"last_name" json:"last_name"`
+	Email         string     `db:"email" json:"email"`
+	Status        string     `db:"status" json:"status"`
+	EnrolledAt    time.Time  `db:"enrolled_at" json:"enrolled_at"`
+	DroppedAt     *time.Time `db:"dropped_at" json:"dropped_at"`
+}
+
+// Attachment is an uploaded file linked to either a submission or an assignment
+type Attachment struct {
+	BaseModel
+	SubmissionID *uuid.UUID `db:"submission_id" json:"submission_id"`
+	AssignmentID *uuid.UUID `db:"assignment_id" json:"assignment_id"`
+	FileName     string     `db:"file_name" json:"file_name"`
+	ContentType  string     `db:"content_type" json:"content_type"`
+	SizeBytes    int64      `db:"size_bytes" json:"size_bytes"`
+	Checksum     string     `db:"checksum_sha256" json:"checksum_sha256"`
+	StorageKey   string     `db:"storage_key" json:"-"`
+}
+
+// Rubric is a reusable grading guide made of criteria, each scored by picking one of its levels
+type Rubric struct {
+	BaseModel
+	Title       string `db:"title" json:"title"`
+	Description string `db:"description" json:"description"`
+}
+
+// RubricCriterion is one aspect of the work a rubric grades
+type RubricCriterion struct {
+	ID          uuid.UUID `db:"id" json:"id"`
+	RubricID    uuid.UUID `db:"rubric_id" json:"rubric_id"`
+	Title       string    `db:"title" json:"title"`
+	Description string    `db:"description" json:"description"`
+	Position    int       `db:"position" json:"position"`
+}
+
+// RubricLevel is a performance level of a criterion and the points it is worth
+type RubricLevel struct {
+	ID          uuid.UUID `db:"id" json:"id"`
+	CriterionID uuid.UUID `db:"criterion_id" json:"criterion_id"`
+	Title       string    `db:"title" json:"title"`
+	Description string    `db:"description" json:"description"`
+	Points      float64   `db:"points" json:"points"`
+	Position    int       `db:"position" json:"position"`
+}
+
+// CriterionScore is the level a teacher picked for one criterion of a submission
+type CriterionScore struct {
+	SubmissionID uuid.UUID `db:"submission_id" json:"submission_id"`
+	CriterionID  uuid.UUID `db:"criterion_id" json:"criterion_id"`
+	LevelID      uuid.UUID `db:"level_id" json:"level_id"`
+	Points       float64   `db:"points" json:"points"`
+	Feedback     *string   `db:"feedback" json:"feedback"`
+	GradedBy     uuid.UUID `db:"graded_by" json:"graded_by"`
+	GradedAt     time.Time `db:"graded_at" json:"graded_at"`
+}
+
+// CriterionScoreDetail is a criterion score joined with the titles of its criterion and level
+type CriterionScoreDetail struct {
+	CriterionScore
+	CriterionTitle string `db:"criterion_title" json:"criterion_title"`
+	LevelTitle     string `db:"level_title" json:"level_title"`
+}
+
+// GradingScheme maps percentages to letter grades, pass/fail or other bands
+type GradingScheme struct {
+	BaseModel
+	Name        string `db:"name" json:"name"`
+	Type        string `db:"type" json:"type"`
+	Description string `db:"description" json:"description"`
+}
+
+// GradingSchemeBand is the grade given from a minimum percentage upwards
+type GradingSchemeBand struct {
+	ID            uuid.UUID `db:"id" json:"id"`
+	SchemeID      uuid.UUID `db:"scheme_id" json:"scheme_id"`
+	Label         string    `db:"label" json:"label"`
+	MinPercentage float64   `db:"min_percentage" json:"min_percentage"`
+	// GradePoints is nil for bands that do not count towards the GPA
+	GradePoints *float64 `db:"grade_points" json:"grade_points"`
+	IsPassing   bool     `db:"is_passing" json:"is_passing"`
+	Position    int      `db:"position" json:"position"`
 }
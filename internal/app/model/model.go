@@ -9,13 +9,13 @@ import (
 
 // Base contains common fields for all models
 type BaseModel struct {
-	ID        uuid.UUID  `db:"id"`
-	CreatedBy uuid.UUID  `db:"created_by"`
-	CreatedAt time.Time  `db:"created_at"`
-	UpdatedBy *uuid.UUID `db:"updated_by"`
-	UpdatedAt *time.Time `db:"updated_at"`
-	DeletedBy *uuid.UUID `db:"deleted_by"`
-	DeletedAt *time.Time `db:"deleted_at"`
+	ID        uuid.UUID  `db:"id" json:"id"`
+	CreatedBy uuid.UUID  `db:"created_by" json:"created_by"`
+	CreatedAt time.Time  `db:"created_at" json:"created_at"`
+	UpdatedBy *uuid.UUID `db:"updated_by" json:"updated_by"`
+	UpdatedAt *time.Time `db:"updated_at" json:"updated_at"`
+	DeletedBy *uuid.UUID `db:"deleted_by" json:"deleted_by"`
+	DeletedAt *time.Time `db:"deleted_at" json:"deleted_at"`
 }
 
 type JWTToken struct {
@@ -0,0 +1,29 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Notification is a message shown to a user in the LMS, Data holds the JSON ids of the records it links to
+type Notification struct {
+	ID        uuid.UUID  `db:"id"`
+	UserID    uuid.UUID  `db:"user_id"`
+	Type      string     `db:"type"`
+	Title     string     `db:"title"`
+	Body      string     `db:"body"`
+	Data      string     `db:"data"`
+	EventID   *uuid.UUID `db:"event_id"`
+	ReadAt    *time.Time `db:"read_at"`
+	CreatedAt time.Time  `db:"created_at"`
+}
+
+// NotificationPreference turns one type of notification on or off for a user, in the LMS and by email
+type NotificationPreference struct {
+	UserID    uuid.UUID `db:"user_id"`
+	Type      string    `db:"type"`
+	InApp     bool      `db:"in_app"`
+	Email     bool      `db:"email"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
@@ -0,0 +1,23 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OutboxEvent is a domain event waiting in the outbox to be dispatched, Payload holds its JSON body
+type OutboxEvent struct {
+	ID            uuid.UUID  `db:"id"`
+	EventType     string     `db:"event_type"`
+	AggregateType string     `db:"aggregate_type"`
+	AggregateID   uuid.UUID  `db:"aggregate_id"`
+	Payload       string     `db:"payload"`
+	ActorID       *uuid.UUID `db:"actor_id"`
+	OccurredAt    time.Time  `db:"occurred_at"`
+	AvailableAt   time.Time  `db:"available_at"`
+	Attempts      int        `db:"attempts"`
+	LastError     *string    `db:"last_error"`
+	DispatchedAt  *time.Time `db:"dispatched_at"`
+	FailedAt      *time.Time `db:"failed_at"`
+}
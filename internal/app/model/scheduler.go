@@ -0,0 +1,32 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ScheduledJobRun is one finished run of a scheduled job, ScheduledAt is the time its schedule fired at
+type ScheduledJobRun struct {
+	ID          uuid.UUID `db:"id"`
+	JobName     string    `db:"job_name"`
+	ScheduledAt time.Time `db:"scheduled_at"`
+	Status      string    `db:"status"`
+	Summary     string    `db:"summary"`
+	Error       *string   `db:"error"`
+	Instance    string    `db:"instance"`
+	StartedAt   time.Time `db:"started_at"`
+	FinishedAt  time.Time `db:"finished_at"`
+}
+
+// DueReminder is an active student of a course with a published assignment they have not submitted
+type DueReminder struct {
+	AssignmentID    uuid.UUID `db:"assignment_id"`
+	AssignmentTitle string    `db:"assignment_title"`
+	DueDate         time.Time `db:"due_date"`
+	CourseID        uuid.UUID `db:"course_id"`
+	CourseName      string    `db:"course_name"`
+	StudentID       uuid.UUID `db:"student_id"`
+	FirstName       string    `db:"first_name"`
+	Email           string    `db:"email"`
+}
@@ -20,6 +20,8 @@ type User struct {
 	Role         string     `db:"role" json:"role"`
 	LastLogin    *time.Time `db:"last_login" json:"last_login"`
 	IsActive     bool       `db:"is_active" json:"is_active"`
+	// EmailVerifiedAt is set once the user followed the verification link mailed to their address
+	EmailVerifiedAt *time.Time `db:"email_verified_at" json:"email_verified_at"`
 }
 
 // SetPassword hashes and sets the user's password
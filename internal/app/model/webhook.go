@@ -0,0 +1,47 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WebhookSubscription is an endpoint outside the LMS that receives domain events
+type WebhookSubscription struct {
+	BaseModel
+	URL         string `db:"url" json:"url"`
+	Description string `db:"description" json:"description"`
+	Secret      string `db:"secret" json:"-"`
+	// EventTypes is the JSON array of event types sent to the endpoint, an empty array subscribes to all of them
+	EventTypes string `db:"event_types" json:"event_types"`
+	IsActive   bool   `db:"is_active" json:"is_active"`
+}
+
+// WebhookDelivery is an event on its way to one subscription, Payload holds the JSON body that is sent
+type WebhookDelivery struct {
+	ID             uuid.UUID  `db:"id"`
+	SubscriptionID uuid.UUID  `db:"subscription_id"`
+	EventID        uuid.UUID  `db:"event_id"`
+	EventType      string     `db:"event_type"`
+	Payload        string     `db:"payload"`
+	Status         string     `db:"status"`
+	Attempts       int        `db:"attempts"`
+	NextAttemptAt  time.Time  `db:"next_attempt_at"`
+	LastStatusCode *int       `db:"last_status_code"`
+	LastError      *string    `db:"last_error"`
+	DeliveredAt    *time.Time `db:"delivered_at"`
+	CreatedAt      time.Time  `db:"created_at"`
+	UpdatedAt      time.Time  `db:"updated_at"`
+}
+
+// WebhookAttempt is one request made for a delivery
+type WebhookAttempt struct {
+	ID           uuid.UUID `db:"id"`
+	DeliveryID   uuid.UUID `db:"delivery_id"`
+	Attempt      int       `db:"attempt"`
+	StatusCode   *int      `db:"status_code"`
+	ResponseBody *string   `db:"response_body"`
+	Error        *string   `db:"error"`
+	DurationMS   int64     `db:"duration_ms"`
+	AttemptedAt  time.Time `db:"attempted_at"`
+}
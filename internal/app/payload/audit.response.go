@@ -0,0 +1,36 @@
+package payload
+
+import "encoding/json"
+
+type AuditEventResponse struct {
+	Seq        int64           `json:"seq"`
+	ID         string          `json:"id"`
+	ActorID    *string         `json:"actor_id"`
+	ActorRole  *string         `json:"actor_role"`
+	Action     string          `json:"action"`
+	EntityType string          `json:"entity_type"`
+	EntityID   string          `json:"entity_id"`
+	CourseID   *string         `json:"course_id"`
+	Before     json.RawMessage `json:"before"`
+	After      json.RawMessage `json:"after"`
+	IP         *string         `json:"ip"`
+	RequestID  *string         `json:"request_id"`
+	CreatedAt  string          `json:"created_at"`
+	PrevHash   string          `json:"prev_hash"`
+	Hash       string          `json:"hash"`
+}
+
+type GetAllAuditEventsResponse struct {
+	Events []AuditEventResponse `json:"events"`
+}
+
+// AuditVerificationResponse is the result of walking the audit chain from its first event
+type AuditVerificationResponse struct {
+	Valid bool `json:"valid"`
+	// Checked is the number of events verified before the walk stopped
+	Checked int64 `json:"checked"`
+	HeadSeq int64 `json:"head_seq"`
+	// BrokenAtSeq is the first event that does not link to the one before it, nil for an intact chain
+	BrokenAtSeq *int64 `json:"broken_at_seq"`
+	Reason      string `json:"reason,omitempty"`
+}
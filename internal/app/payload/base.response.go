@@ -14,6 +14,19 @@ type BaseResponseWithMeta struct {
 
 type MetaResponse struct {
 	TotalCount int `json:"total_count"`
-	Page       int `json:"page"`
-	PerPage    int `json:"per_page"`
+	// Page is 0 when the page was reached through a cursor
+	Page    int `json:"page"`
+	PerPage int `json:"per_page"`
+	// NextCursor fetches the page after this one and is empty on the last page
+	NextCursor string `json:"next_cursor"`
+}
+
+// DeletionResponse describes a record after it was soft-deleted, restored or purged
+type DeletionResponse struct {
+	ID string `json:"id"`
+	// DeletedAt and DeletedBy are null once a record is restored
+	DeletedAt *string `json:"deleted_at"`
+	DeletedBy *string `json:"deleted_by"`
+	// Purged is true when the record was removed for good
+	Purged bool `json:"purged"`
 }
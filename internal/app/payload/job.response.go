@@ -0,0 +1,37 @@
+package payload
+
+import "encoding/json"
+
+type JobResponse struct {
+	ID          string          `json:"id"`
+	Type        string          `json:"type"`
+	Payload     json.RawMessage `json:"payload"`
+	Priority    int             `json:"priority"`
+	Status      string          `json:"status"`
+	Attempts    int             `json:"attempts"`
+	MaxAttempts int             `json:"max_attempts"`
+	RunAt       string          `json:"run_at"`
+	LockedUntil *string         `json:"locked_until"`
+	LockedBy    *string         `json:"locked_by"`
+	LastError   *string         `json:"last_error"`
+	StartedAt   *string         `json:"started_at"`
+	FinishedAt  *string         `json:"finished_at"`
+	CreatedAt   string          `json:"created_at"`
+}
+
+type GetAllJobsResponse struct {
+	Jobs []JobResponse `json:"jobs"`
+}
+
+// JobStatsResponse counts the jobs of every type by status
+type JobStatsResponse struct {
+	Types []JobTypeStatsResponse `json:"types"`
+}
+
+type JobTypeStatsResponse struct {
+	Type      string `json:"type"`
+	Pending   int64  `json:"pending"`
+	Running   int64  `json:"running"`
+	Succeeded int64  `json:"succeeded"`
+	Failed    int64  `json:"failed"`
+}
@@ -1,42 +1,60 @@
 package payload
 
+import "io"
+
 type CreateCourseRequest struct {
-	CreatedBy   string `json:"created_by" validate:"required"`
-	Code        string `json:"code" validate:"required"`
-	Name        string `json:"name" validate:"required"`
-	Description string `json:"description"`
-	StartDate   string `json:"start_date" validate:"required"`
-	EndDate     string `json:"end_date" validate:"required"`
+	Code            string `json:"code" validate:"required"`
+	Name            string `json:"name" validate:"required"`
+	Description     string `json:"description"`
+	StartDate       string `json:"start_date" validate:"required"`
+	EndDate         string `json:"end_date" validate:"required"`
+	GradingSchemeID string `json:"grading_scheme_id"`
 }
 
 type UpdateCourseRequest struct {
-	UserID      string `json:"user_id" validate:"required"`
-	Code        string `json:"code" validate:"required"`
-	Name        string `json:"name" validate:"required"`
-	Description string `json:"description"`
-	StartDate   string `json:"start_date" validate:"required"`
-	EndDate     string `json:"end_date" validate:"required"`
+	Code            string `json:"code" validate:"required"`
+	Name            string `json:"name" validate:"required"`
+	Description     string `json:"description"`
+	StartDate       string `json:"start_date" validate:"required"`
+	EndDate         string `json:"end_date" validate:"required"`
+	GradingSchemeID string `json:"grading_scheme_id"`
 }
 
 type CreateAssignmentRequest struct {
-	CourseID    string  `json:"course_id" validate:"required"`
-	CreatedBy   string  `json:"created_by" validate:"required"`
-	Title       string  `json:"title" validate:"required"`
-	Description string  `json:"description" validate:"required"`
-	Content     string  `json:"content" validate:"required"`
-	TotalPoints float64 `json:"total_points" validate:"required"`
+	CourseID           string  `json:"course_id" validate:"required"`
+	Title              string  `json:"title" validate:"required"`
+	Description        string  `json:"description" validate:"required"`
+	Content            string  `json:"content" validate:"required"`
+	TotalPoints        float64 `json:"total_points" validate:"required"`
+	DueDate            string  `json:"due_date" validate:"required"`
+	AvailableFrom      string  `json:"available_from"`
+	AvailableUntil     string  `json:"available_until"`
+	LatePolicy         string  `json:"late_policy" validate:"omitempty,oneof=reject accept penalty"`
+	LatePenaltyPercent float64 `json:"late_penalty_percent" validate:"gte=0,lte=100"`
+	MaxAttempts        int     `json:"max_attempts" validate:"gte=0"`
+	RubricID           string  `json:"rubric_id"`
+	CategoryID         string  `json:"category_id"`
 }
 
+// UpdateAssignmentRequest keeps the schedule, rubric and category the assignment has for every field left out. An
+// empty available_from or available_until removes that end of the availability window, an empty rubric_id detaches
+// the rubric and an empty category_id takes the assignment out of its category.
 type UpdateAssignmentRequest struct {
-	UserID      string  `json:"user_id" validate:"required"`
-	Title       string  `json:"title" validate:"required"`
-	Description string  `json:"description" validate:"required"`
-	TotalPoints float64 `json:"total_points" validate:"required"`
-	IsPublished bool    `json:"is_published" validate:"required"`
+	Title              string   `json:"title" validate:"required"`
+	Description        string   `json:"description" validate:"required"`
+	TotalPoints        float64  `json:"total_points" validate:"required"`
+	IsPublished        bool     `json:"is_published" validate:"required"`
+	DueDate            string   `json:"due_date"`
+	AvailableFrom      *string  `json:"available_from"`
+	AvailableUntil     *string  `json:"available_until"`
+	LatePolicy         string   `json:"late_policy" validate:"omitempty,oneof=reject accept penalty"`
+	LatePenaltyPercent *float64 `json:"late_penalty_percent" validate:"omitempty,gte=0,lte=100"`
+	MaxAttempts        int      `json:"max_attempts" validate:"gte=0"`
+	RubricID           *string  `json:"rubric_id"`
+	CategoryID         *string  `json:"category_id"`
 }
 
 type CreateSubmissionRequest struct {
-	CreatedBy    string   `json:"created_by" validate:"required"`
 	AssignmentID string   `json:"assignment_id" validate:"required"`
 	Content      string   `json:"content"`
 	FileURL      string   `json:"file_url"`
@@ -45,10 +63,79 @@ type CreateSubmissionRequest struct {
 }
 
 type UpdateSubmissionRequest struct {
-	UserID       string  `json:"user_id" validate:"required"`
-	AssignmentID string  `json:"assignment_id" validate:"required"`
-	Content      string  `json:"content"`
-	FileURL      string  `json:"file_url"`
-	Grade        float64 `json:"grade"`
-	Feedback     string  `json:"feedback"`
+	AssignmentID string `json:"assignment_id" validate:"required"`
+	Content      string `json:"content"`
+	FileURL      string `json:"file_url"`
+	// Grade is nil when the request only carries feedback, 0 is a grade
+	Grade    *float64 `json:"grade" validate:"omitempty,gte=0"`
+	Feedback string   `json:"feedback"`
+	// AttemptID picks the attempt being graded, the latest one is graded when it is empty
+	AttemptID string `json:"attempt_id"`
+	// Scores grades every criterion of the assignment rubric, the grade is then computed from them
+	Scores []CriterionScoreRequest `json:"scores" validate:"dive"`
+}
+
+type CriterionScoreRequest struct {
+	CriterionID string `json:"criterion_id" validate:"required"`
+	LevelID     string `json:"level_id" validate:"required"`
+	Feedback    string `json:"feedback"`
+}
+
+type EnrollStudentRequest struct {
+	StudentID string `json:"student_id" validate:"required"`
+}
+
+type AddCourseTeacherRequest struct {
+	TeacherID string `json:"teacher_id" validate:"required"`
+}
+
+// UploadAttachmentRequest is filled from a multipart form, the content is streamed to the blob store
+type UploadAttachmentRequest struct {
+	FileName string    `json:"-"`
+	Size     int64     `json:"-"`
+	Content  io.Reader `json:"-"`
+}
+
+type CreateRubricRequest struct {
+	Title       string                         `json:"title" validate:"required,notblank"`
+	Description string                         `json:"description"`
+	Criteria    []CreateRubricCriterionRequest `json:"criteria" validate:"required,min=1,dive"`
+}
+
+type CreateRubricCriterionRequest struct {
+	Title       string                     `json:"title" validate:"required,notblank"`
+	Description string                     `json:"description"`
+	Levels      []CreateRubricLevelRequest `json:"levels" validate:"required,min=1,dive"`
+}
+
+type CreateRubricLevelRequest struct {
+	Title       string  `json:"title" validate:"required,notblank"`
+	Description string  `json:"description"`
+	Points      float64 `json:"points" validate:"gte=0"`
+}
+
+type CreateCategoryRequest struct {
+	Name       string  `json:"name" validate:"required,notblank"`
+	Weight     float64 `json:"weight" validate:"required,gt=0,lte=100"`
+	DropLowest int     `json:"drop_lowest" validate:"gte=0"`
+}
+
+type UpdateCategoryRequest struct {
+	Name       string  `json:"name" validate:"required,notblank"`
+	Weight     float64 `json:"weight" validate:"required,gt=0,lte=100"`
+	DropLowest int     `json:"drop_lowest" validate:"gte=0"`
+}
+
+type CreateGradingSchemeRequest struct {
+	Name        string                           `json:"name" validate:"required,notblank"`
+	Type        string                           `json:"type" validate:"required,oneof=letter pass_fail"`
+	Description string                           `json:"description"`
+	Bands       []CreateGradingSchemeBandRequest `json:"bands" validate:"required,min=2,dive"`
+}
+
+type CreateGradingSchemeBandRequest struct {
+	Label         string   `json:"label" validate:"required,notblank"`
+	MinPercentage float64  `json:"min_percentage" validate:"gte=0,lte=100"`
+	GradePoints   *float64 `json:"grade_points" validate:"omitempty,gte=0,lte=9.99"`
+	IsPassing     bool     `json:"is_passing"`
 }
@@ -1,27 +1,31 @@
 package payload
 
+import "io"
+
 type CreateCourseResponse struct {
 	ID string `json:"id"`
 }
 
 type UpdateCourseResponse struct {
-	ID          string `json:"id"`
-	Code        string `json:"code"`
-	Name        string `json:"name"`
-	Description string `json:"description"`
-	StartDate   string `json:"start_date"`
-	EndDate     string `json:"end_date"`
-	IsActive    bool   `json:"is_active"`
+	ID              string  `json:"id"`
+	Code            string  `json:"code"`
+	Name            string  `json:"name"`
+	Description     string  `json:"description"`
+	StartDate       string  `json:"start_date"`
+	EndDate         string  `json:"end_date"`
+	IsActive        bool    `json:"is_active"`
+	GradingSchemeID *string `json:"grading_scheme_id"`
 }
 
 type GetCourseResponse struct {
-	ID          string `json:"id"`
-	Code        string `json:"code"`
-	Name        string `json:"name"`
-	Description string `json:"description"`
-	StartDate   string `json:"start_date"`
-	EndDate     string `json:"end_date"`
-	IsActive    bool   `json:"is_active"`
+	ID              string  `json:"id"`
+	Code            string  `json:"code"`
+	Name            string  `json:"name"`
+	Description     string  `json:"description"`
+	StartDate       string  `json:"start_date"`
+	EndDate         string  `json:"end_date"`
+	IsActive        bool    `json:"is_active"`
+	GradingSchemeID *string `json:"grading_scheme_id"`
 }
 
 type GetAllCoursesResponse struct {
@@ -33,21 +37,35 @@ type CreateAssignmentResponse struct {
 }
 
 type UpdateAssignmentResponse struct {
-	ID          string  `json:"id"`
-	Title       string  `json:"title"`
-	Description string  `json:"description"`
-	DueDate     string  `json:"due_date"`
-	TotalPoints float64 `json:"total_points"`
-	IsPublished bool    `json:"is_published"`
+	ID                 string  `json:"id"`
+	Title              string  `json:"title"`
+	Description        string  `json:"description"`
+	DueDate            string  `json:"due_date"`
+	TotalPoints        float64 `json:"total_points"`
+	IsPublished        bool    `json:"is_published"`
+	AvailableFrom      *string `json:"available_from"`
+	AvailableUntil     *string `json:"available_until"`
+	LatePolicy         string  `json:"late_policy"`
+	LatePenaltyPercent float64 `json:"late_penalty_percent"`
+	MaxAttempts        int     `json:"max_attempts"`
+	RubricID           *string `json:"rubric_id"`
+	CategoryID         *string `json:"category_id"`
 }
 
 type GetAssignmentResponse struct {
-	ID          string  `json:"id"`
-	Title       string  `json:"title"`
-	Description string  `json:"description"`
-	DueDate     string  `json:"due_date"`
-	TotalPoints float64 `json:"total_points"`
-	IsPublished bool    `json:"is_published"`
+	ID                 string  `json:"id"`
+	Title              string  `json:"title"`
+	Description        string  `json:"description"`
+	DueDate            string  `json:"due_date"`
+	TotalPoints        float64 `json:"total_points"`
+	IsPublished        bool    `json:"is_published"`
+	AvailableFrom      *string `json:"available_from"`
+	AvailableUntil     *string `json:"available_until"`
+	LatePolicy         string  `json:"late_policy"`
+	LatePenaltyPercent float64 `json:"late_penalty_percent"`
+	MaxAttempts        int     `json:"max_attempts"`
+	RubricID           *string `json:"rubric_id"`
+	CategoryID         *string `json:"category_id"`
 }
 
 type CreateSubmissionResponse struct {
@@ -67,6 +85,17 @@ type GetSubmissionResponse struct {
 	GradedBy     *string  `json:"graded_by"`
 	CreatedAt    string   `json:"created_at"`
 	CreatedBy    string   `json:"created_by"`
+
+	IsLate             bool     `json:"is_late"`
+	LatePenaltyPercent float64  `json:"late_penalty_percent"`
+	RawGrade           *float64 `json:"raw_grade"`
+
+	AttemptCount    int     `json:"attempt_count"`
+	GradedAttemptID *string `json:"graded_attempt_id"`
+
+	CriterionScores []CriterionScoreResponse `json:"criterion_scores,omitempty"`
+	// LetterGrade renders the grade with the grading scheme of the course
+	LetterGrade *string `json:"letter_grade"`
 }
 
 type UpdateSubmissionResponse struct {
@@ -80,6 +109,17 @@ type UpdateSubmissionResponse struct {
 	Feedback     *string  `json:"feedback"`
 	GradedAt     *string  `json:"graded_at"`
 	GradedBy     *string  `json:"graded_by"`
+
+	IsLate             bool     `json:"is_late"`
+	LatePenaltyPercent float64  `json:"late_penalty_percent"`
+	RawGrade           *float64 `json:"raw_grade"`
+
+	AttemptCount    int     `json:"attempt_count"`
+	GradedAttemptID *string `json:"graded_attempt_id"`
+
+	CriterionScores []CriterionScoreResponse `json:"criterion_scores,omitempty"`
+	// LetterGrade renders the grade with the grading scheme of the course
+	LetterGrade *string `json:"letter_grade"`
 }
 
 type GetAllSubmissionsResponse struct {
@@ -108,3 +148,249 @@ type (
 		Submissions  []GetSubmissionResponse `json:"submissions"`
 	}
 )
+
+type CourseTeacherResponse struct {
+	CourseID  string `json:"course_id"`
+	TeacherID string `json:"teacher_id"`
+	CreatedBy string `json:"created_by"`
+	CreatedAt string `json:"created_at"`
+}
+
+type GetAllCourseTeachersResponse struct {
+	CourseID string                  `json:"course_id"`
+	Teachers []CourseTeacherResponse `json:"teachers"`
+}
+
+type EnrollmentResponse struct {
+	ID         string  `json:"id"`
+	CourseID   string  `json:"course_id"`
+	StudentID  string  `json:"student_id"`
+	Status     string  `json:"status"`
+	EnrolledAt string  `json:"enrolled_at"`
+	DroppedAt  *string `json:"dropped_at"`
+}
+
+type (
+	GetAllEnrollmentsResponse struct {
+		CourseID    string                     `json:"course_id"`
+		Enrollments []EnrollmentRosterResponse `json:"enrollments"`
+	}
+	EnrollmentRosterResponse struct {
+		ID            string  `json:"id"`
+		StudentID     string  `json:"student_id"`
+		StudentNumber string  `json:"student_number"`
+		FirstName     string  `json:"first_name"`
+		LastName      string  `json:"last_name"`
+		Email         string  `json:"email"`
+		Status        string  `json:"status"`
+		EnrolledAt    string  `json:"enrolled_at"`
+		DroppedAt     *string `json:"dropped_at"`
+	}
+)
+
+type AttachmentResponse struct {
+	ID                string  `json:"id"`
+	SubmissionID      *string `json:"submission_id"`
+	AssignmentID      *string `json:"assignment_id"`
+	FileName          string  `json:"file_name"`
+	ContentType       string  `json:"content_type"`
+	SizeBytes         int64   `json:"size_bytes"`
+	Checksum          string  `json:"checksum_sha256"`
+	DownloadURL       string  `json:"download_url"`
+	DownloadExpiresAt string  `json:"download_expires_at"`
+	CreatedAt         string  `json:"created_at"`
+	CreatedBy         string  `json:"created_by"`
+}
+
+type GetAllAttachmentsResponse struct {
+	Attachments []AttachmentResponse `json:"attachments"`
+}
+
+// DownloadAttachmentResponse carries the stored object, the caller must close the content
+type DownloadAttachmentResponse struct {
+	FileName    string
+	ContentType string
+	SizeBytes   int64
+	Checksum    string
+	Content     io.ReadCloser
+}
+
+type SubmissionAttemptResponse struct {
+	ID                 string  `json:"id"`
+	AttemptNumber      int     `json:"attempt_number"`
+	Content            string  `json:"content"`
+	FileURL            *string `json:"file_url"`
+	SubmittedAt        string  `json:"submitted_at"`
+	IsLate             bool    `json:"is_late"`
+	LatePenaltyPercent float64 `json:"late_penalty_percent"`
+	IsGraded           bool    `json:"is_graded"`
+}
+
+type GetAllSubmissionAttemptsResponse struct {
+	SubmissionID    string                      `json:"submission_id"`
+	MaxAttempts     int                         `json:"max_attempts"`
+	GradedAttemptID *string                     `json:"graded_attempt_id"`
+	Attempts        []SubmissionAttemptResponse `json:"attempts"`
+}
+
+type SubmissionAttemptDiffResponse struct {
+	SubmissionID string             `json:"submission_id"`
+	From         int                `json:"from"`
+	To           int                `json:"to"`
+	Lines        []DiffLineResponse `json:"lines"`
+}
+
+type DiffLineResponse struct {
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
+
+type RubricResponse struct {
+	ID          string                    `json:"id"`
+	Title       string                    `json:"title"`
+	Description string                    `json:"description"`
+	MaxPoints   float64                   `json:"max_points"`
+	Criteria    []RubricCriterionResponse `json:"criteria"`
+	CreatedAt   string                    `json:"created_at"`
+	CreatedBy   string                    `json:"created_by"`
+}
+
+type RubricCriterionResponse struct {
+	ID          string                `json:"id"`
+	Title       string                `json:"title"`
+	Description string                `json:"description"`
+	MaxPoints   float64               `json:"max_points"`
+	Levels      []RubricLevelResponse `json:"levels"`
+}
+
+type RubricLevelResponse struct {
+	ID          string  `json:"id"`
+	Title       string  `json:"title"`
+	Description string  `json:"description"`
+	Points      float64 `json:"points"`
+}
+
+type RubricSummaryResponse struct {
+	ID          string `json:"id"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	CreatedAt   string `json:"created_at"`
+	CreatedBy   string `json:"created_by"`
+}
+
+type GetAllRubricsResponse struct {
+	Rubrics []RubricSummaryResponse `json:"rubrics"`
+}
+
+type CriterionScoreResponse struct {
+	CriterionID    string  `json:"criterion_id"`
+	CriterionTitle string  `json:"criterion_title"`
+	LevelID        string  `json:"level_id"`
+	LevelTitle     string  `json:"level_title"`
+	Points         float64 `json:"points"`
+	Feedback       *string `json:"feedback"`
+	GradedAt       string  `json:"graded_at"`
+	GradedBy       string  `json:"graded_by"`
+}
+
+type CategoryResponse struct {
+	ID         string  `json:"id"`
+	CourseID   string  `json:"course_id"`
+	Name       string  `json:"name"`
+	Weight     float64 `json:"weight"`
+	DropLowest int     `json:"drop_lowest"`
+	CreatedAt  string  `json:"created_at"`
+	CreatedBy  string  `json:"created_by"`
+}
+
+type GetAllCategoriesResponse struct {
+	Categories []CategoryResponse `json:"categories"`
+}
+
+type GradebookResponse struct {
+	CourseID        string                 `json:"course_id"`
+	GradingSchemeID *string                `json:"grading_scheme_id"`
+	Categories      []CategoryResponse     `json:"categories"`
+	Students        []StudentGradeResponse `json:"students"`
+}
+
+type StudentGradeResponse struct {
+	StudentID        string `json:"student_id"`
+	StudentNumber    string `json:"student_number"`
+	FirstName        string `json:"first_name"`
+	LastName         string `json:"last_name"`
+	EnrollmentStatus string `json:"enrollment_status"`
+	// RunningPercentage only counts graded work, FinalPercentage counts missing work as zero
+	RunningPercentage *float64 `json:"running_percentage"`
+	FinalPercentage   *float64 `json:"final_percentage"`
+	// the letter grades, grade points and pass mark come from the grading scheme of the course
+	RunningLetterGrade *string                   `json:"running_letter_grade"`
+	LetterGrade        *string                   `json:"letter_grade"`
+	GradePoints        *float64                  `json:"grade_points"`
+	Passed             *bool                     `json:"passed"`
+	Categories         []CategoryGradeResponse   `json:"categories"`
+	Assignments        []AssignmentGradeResponse `json:"assignments"`
+}
+
+type CategoryGradeResponse struct {
+	CategoryID        *string  `json:"category_id"`
+	Name              string   `json:"name"`
+	Weight            float64  `json:"weight"`
+	DropLowest        int      `json:"drop_lowest"`
+	RunningPercentage *float64 `json:"running_percentage"`
+	FinalPercentage   *float64 `json:"final_percentage"`
+}
+
+type AssignmentGradeResponse struct {
+	AssignmentID string   `json:"assignment_id"`
+	Title        string   `json:"title"`
+	CategoryID   *string  `json:"category_id"`
+	DueDate      string   `json:"due_date"`
+	TotalPoints  float64  `json:"total_points"`
+	Grade        *float64 `json:"grade"`
+	Percentage   *float64 `json:"percentage"`
+	LetterGrade  *string  `json:"letter_grade"`
+	Counted      bool     `json:"counted"`
+	Dropped      bool     `json:"dropped"`
+}
+
+type GradingSchemeResponse struct {
+	ID          string                      `json:"id"`
+	Name        string                      `json:"name"`
+	Type        string                      `json:"type"`
+	Description string                      `json:"description"`
+	Bands       []GradingSchemeBandResponse `json:"bands"`
+	CreatedAt   string                      `json:"created_at"`
+}
+
+type GradingSchemeBandResponse struct {
+	Label         string   `json:"label"`
+	MinPercentage float64  `json:"min_percentage"`
+	GradePoints   *float64 `json:"grade_points"`
+	IsPassing     bool     `json:"is_passing"`
+}
+
+type GetAllGradingSchemesResponse struct {
+	GradingSchemes []GradingSchemeResponse `json:"grading_schemes"`
+}
+
+type TranscriptResponse struct {
+	StudentID string `json:"student_id"`
+	// GPA averages the grade points of every course graded with points, nil when there is none yet
+	GPA            *float64                   `json:"gpa"`
+	CoursesCounted int                        `json:"courses_counted"`
+	Courses        []TranscriptCourseResponse `json:"courses"`
+}
+
+type TranscriptCourseResponse struct {
+	CourseID         string   `json:"course_id"`
+	Code             string   `json:"code"`
+	Name             string   `json:"name"`
+	EnrollmentStatus string   `json:"enrollment_status"`
+	GradingScheme    *string  `json:"grading_scheme"`
+	FinalPercentage  *float64 `json:"final_percentage"`
+	LetterGrade      *string  `json:"letter_grade"`
+	GradePoints      *float64 `json:"grade_points"`
+	Passed           *bool    `json:"passed"`
+	CountsTowardsGPA bool     `json:"counts_towards_gpa"`
+}
@@ -0,0 +1,12 @@
+package payload
+
+type UpdateNotificationPreferencesRequest struct {
+	Preferences []NotificationPreferenceRequest `json:"preferences" validate:"required,dive"`
+}
+
+// NotificationPreferenceRequest changes the channels it names, a channel left out keeps its setting
+type NotificationPreferenceRequest struct {
+	Type  string `json:"type" validate:"required,oneof=assignment_published grade_posted feedback_posted assignment_due_soon"`
+	InApp *bool  `json:"in_app" validate:"required_without=Email"`
+	Email *bool  `json:"email" validate:"required_without=InApp"`
+}
@@ -0,0 +1,35 @@
+package payload
+
+import "encoding/json"
+
+type NotificationResponse struct {
+	ID    string `json:"id"`
+	Type  string `json:"type"`
+	Title string `json:"title"`
+	Body  string `json:"body"`
+	// Data holds the ids of the records the notification links to
+	Data      json.RawMessage `json:"data"`
+	IsRead    bool            `json:"is_read"`
+	ReadAt    *string         `json:"read_at"`
+	CreatedAt string          `json:"created_at"`
+}
+
+type GetAllNotificationsResponse struct {
+	Notifications []NotificationResponse `json:"notifications"`
+	UnreadCount   int64                  `json:"unread_count"`
+}
+
+type MarkAllNotificationsReadResponse struct {
+	Updated int64 `json:"updated"`
+}
+
+type NotificationPreferenceResponse struct {
+	Type  string `json:"type"`
+	InApp bool   `json:"in_app"`
+	Email bool   `json:"email"`
+}
+
+// NotificationPreferencesResponse lists every notification type, types the user never changed are on
+type NotificationPreferencesResponse struct {
+	Preferences []NotificationPreferenceResponse `json:"preferences"`
+}
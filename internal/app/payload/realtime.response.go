@@ -0,0 +1,12 @@
+package payload
+
+import "encoding/json"
+
+// RealtimeMessageResponse is one pushed event, Data is the payload of the domain event and ID its id, which
+// stays the same when the event is pushed again
+type RealtimeMessageResponse struct {
+	ID         string          `json:"id"`
+	Type       string          `json:"type"`
+	OccurredAt string          `json:"occurred_at"`
+	Data       json.RawMessage `json:"data"`
+}
@@ -0,0 +1,18 @@
+package payload
+
+type ScheduledJobRunResponse struct {
+	ID          string  `json:"id"`
+	JobName     string  `json:"job_name"`
+	ScheduledAt string  `json:"scheduled_at"`
+	Status      string  `json:"status"`
+	Summary     string  `json:"summary"`
+	Error       *string `json:"error"`
+	Instance    string  `json:"instance"`
+	StartedAt   string  `json:"started_at"`
+	FinishedAt  string  `json:"finished_at"`
+	DurationMS  int64   `json:"duration_ms"`
+}
+
+type GetAllScheduledJobRunsResponse struct {
+	Runs []ScheduledJobRunResponse `json:"runs"`
+}
@@ -8,20 +8,33 @@ type RegisterUserResponse struct {
 	Role      string `json:"role"`
 }
 
+// LoginUserResponse holds the tokens of the session, or the MFA challenge when the login needs a second factor
 type LoginUserResponse struct {
-	Token string `json:"token"`
+	Token            string `json:"token,omitempty"`
+	ExpiresAt        string `json:"expires_at,omitempty"`
+	RefreshToken     string `json:"refresh_token,omitempty"`
+	RefreshExpiresAt string `json:"refresh_expires_at,omitempty"`
+
+	MFARequired bool `json:"mfa_required,omitempty"`
+	// MFAEnrollmentRequired asks a user of a role that must use two-factor authentication to enroll first
+	MFAEnrollmentRequired bool   `json:"mfa_enrollment_required,omitempty"`
+	MFAToken              string `json:"mfa_token,omitempty"`
+	MFAExpiresAt          string `json:"mfa_expires_at,omitempty"`
+	// RecoveryCodes are shown once, when a login completes the enrollment
+	RecoveryCodes []string `json:"recovery_codes,omitempty"`
 }
 
 type GetUserResponse struct {
-	ID        string           `json:"id"`
-	FirstName string           `json:"first_name"`
-	LastName  string           `json:"last_name"`
-	Email     string           `json:"email"`
-	UserRole  RoleUserResponse `json:"user_role"`
-	IsActive  bool             `json:"is_active"`
-	LastLogin string           `json:"last_login"`
-	CreatedAt string           `json:"created_at"`
-	UpdatedAt string           `json:"updated_at"`
+	ID              string           `json:"id"`
+	FirstName       string           `json:"first_name"`
+	LastName        string           `json:"last_name"`
+	Email           string           `json:"email"`
+	UserRole        RoleUserResponse `json:"user_role"`
+	IsActive        bool             `json:"is_active"`
+	EmailVerifiedAt string           `json:"email_verified_at"`
+	LastLogin       string           `json:"last_login"`
+	CreatedAt       string           `json:"created_at"`
+	UpdatedAt       string           `json:"updated_at"`
 }
 
 type RoleUserResponse struct {
@@ -36,3 +49,35 @@ type RoleUserResponse struct {
 type LogoutUserResponse struct {
 	ID string `json:"id"`
 }
+
+type RevokeUserSessionsResponse struct {
+	UserID          string `json:"user_id"`
+	RevokedSessions int    `json:"revoked_sessions"`
+}
+
+type ResetPasswordResponse struct {
+	ID              string `json:"id"`
+	RevokedSessions int    `json:"revoked_sessions"`
+}
+
+type VerifyEmailResponse struct {
+	ID              string `json:"id"`
+	Email           string `json:"email"`
+	EmailVerifiedAt string `json:"email_verified_at"`
+}
+
+type MFAEnrollmentResponse struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+type MFARecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type MFAStatusResponse struct {
+	Enabled           bool   `json:"enabled"`
+	EnabledAt         string `json:"enabled_at,omitempty"`
+	Required          bool   `json:"required"`
+	RecoveryCodesLeft int    `json:"recovery_codes_left"`
+}
@@ -0,0 +1,15 @@
+package payload
+
+type CreateWebhookRequest struct {
+	URL         string `json:"url" validate:"required,url"`
+	Description string `json:"description"`
+	// EventTypes are the events sent to the webhook, every event is sent when it is empty
+	EventTypes []string `json:"event_types"`
+}
+
+type UpdateWebhookRequest struct {
+	URL         string   `json:"url" validate:"required,url"`
+	Description string   `json:"description"`
+	EventTypes  []string `json:"event_types"`
+	IsActive    *bool    `json:"is_active" validate:"required"`
+}
@@ -0,0 +1,50 @@
+package payload
+
+import "encoding/json"
+
+type WebhookResponse struct {
+	ID          string   `json:"id"`
+	URL         string   `json:"url"`
+	Description string   `json:"description"`
+	EventTypes  []string `json:"event_types"`
+	IsActive    bool     `json:"is_active"`
+	// Secret signs the requests of the webhook, it is only returned when the webhook is created or the secret rotated
+	Secret    string  `json:"secret,omitempty"`
+	CreatedAt string  `json:"created_at"`
+	CreatedBy string  `json:"created_by"`
+	UpdatedAt *string `json:"updated_at"`
+}
+
+type GetAllWebhooksResponse struct {
+	Webhooks []WebhookResponse `json:"webhooks"`
+}
+
+type WebhookDeliveryResponse struct {
+	ID             string          `json:"id"`
+	WebhookID      string          `json:"webhook_id"`
+	EventID        string          `json:"event_id"`
+	EventType      string          `json:"event_type"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  string          `json:"next_attempt_at"`
+	LastStatusCode *int            `json:"last_status_code"`
+	LastError      *string         `json:"last_error"`
+	DeliveredAt    *string         `json:"delivered_at"`
+	CreatedAt      string          `json:"created_at"`
+	// History lists every request made for the delivery, it is only returned for a single delivery
+	History []WebhookAttemptResponse `json:"history,omitempty"`
+}
+
+type WebhookAttemptResponse struct {
+	Attempt      int     `json:"attempt"`
+	StatusCode   *int    `json:"status_code"`
+	ResponseBody *string `json:"response_body"`
+	Error        *string `json:"error"`
+	DurationMS   int64   `json:"duration_ms"`
+	AttemptedAt  string  `json:"attempted_at"`
+}
+
+type GetAllWebhookDeliveriesResponse struct {
+	Deliveries []WebhookDeliveryResponse `json:"deliveries"`
+}
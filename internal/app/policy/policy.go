@@ -0,0 +1,222 @@
+package policy
+
+import (
+	"fmt"
+
+	"edukita-teaching-grading/internal/pkg"
+
+	"github.com/google/uuid"
+)
+
+// Action is a permission checked against the role and ownership of the acting user
+type Action string
+
+const (
+	CourseCreate Action = "course:create"
+	CourseRead   Action = "course:read"
+	CourseUpdate Action = "course:update"
+	CourseDelete Action = "course:delete"
+
+	CourseTeacherManage Action = "course_teacher:manage"
+
+	EnrollmentManage Action = "enrollment:manage"
+	EnrollmentRead   Action = "enrollment:read"
+
+	AssignmentCreate Action = "assignment:create"
+	AssignmentRead   Action = "assignment:read"
+	AssignmentUpdate Action = "assignment:update"
+	AssignmentDelete Action = "assignment:delete"
+
+	SubmissionCreate Action = "submission:create"
+	SubmissionRead   Action = "submission:read"
+	SubmissionUpdate Action = "submission:update"
+	SubmissionGrade  Action = "submission:grade"
+	SubmissionReview Action = "submission:review"
+	SubmissionDelete Action = "submission:delete"
+
+	RubricCreate Action = "rubric:create"
+	RubricRead   Action = "rubric:read"
+
+	GradebookRead Action = "gradebook:read"
+
+	GradingSchemeCreate Action = "grading_scheme:create"
+	GradingSchemeRead   Action = "grading_scheme:read"
+
+	TranscriptRead Action = "transcript:read"
+
+	SessionRevoke Action = "session:revoke"
+
+	UserDelete Action = "user:delete"
+
+	RecordRestore Action = "record:restore"
+	RecordPurge   Action = "record:purge"
+
+	AuditRead   Action = "audit:read"
+	AuditVerify Action = "audit:verify"
+
+	WebhookManage Action = "webhook:manage"
+
+	SchedulerRead Action = "scheduler:read"
+
+	JobManage Action = "job:manage"
+)
+
+// Scope describes which resources a role may act on for a given action
+type Scope int
+
+const (
+	// ScopeNone denies the action
+	ScopeNone Scope = iota
+	// ScopeOwn allows the action only on resources the subject owns
+	ScopeOwn
+	// ScopeAll allows the action on every resource
+	ScopeAll
+)
+
+// Rules maps every action to the scope granted to each role, roles that are not listed get ScopeNone
+type Rules map[Action]map[string]Scope
+
+// DefaultRules is the permission matrix of the LMS
+var DefaultRules = Rules{
+	CourseCreate: {pkg.ROLE_ADMIN: ScopeAll, pkg.ROLE_TEACHER: ScopeAll},
+	CourseRead:   {pkg.ROLE_ADMIN: ScopeAll, pkg.ROLE_TEACHER: ScopeAll, pkg.ROLE_STUDENT: ScopeAll},
+	CourseUpdate: {pkg.ROLE_ADMIN: ScopeAll, pkg.ROLE_TEACHER: ScopeOwn},
+	// deleting takes the whole course down, so among teachers only its creator may do it
+	CourseDelete: {pkg.ROLE_ADMIN: ScopeAll, pkg.ROLE_TEACHER: ScopeOwn},
+
+	// only the course creator picks co-teachers, co-teachers cannot add further ones
+	CourseTeacherManage: {pkg.ROLE_ADMIN: ScopeAll, pkg.ROLE_TEACHER: ScopeOwn},
+
+	EnrollmentManage: {pkg.ROLE_ADMIN: ScopeAll, pkg.ROLE_TEACHER: ScopeOwn},
+	EnrollmentRead:   {pkg.ROLE_ADMIN: ScopeAll, pkg.ROLE_TEACHER: ScopeOwn},
+
+	AssignmentCreate: {pkg.ROLE_ADMIN: ScopeAll, pkg.ROLE_TEACHER: ScopeOwn},
+	AssignmentRead:   {pkg.ROLE_ADMIN: ScopeAll, pkg.ROLE_TEACHER: ScopeAll, pkg.ROLE_STUDENT: ScopeAll},
+	AssignmentUpdate: {pkg.ROLE_ADMIN: ScopeAll, pkg.ROLE_TEACHER: ScopeOwn},
+	AssignmentDelete: {pkg.ROLE_ADMIN: ScopeAll, pkg.ROLE_TEACHER: ScopeOwn},
+
+	SubmissionCreate: {pkg.ROLE_STUDENT: ScopeAll},
+	SubmissionRead:   {pkg.ROLE_ADMIN: ScopeAll, pkg.ROLE_TEACHER: ScopeOwn, pkg.ROLE_STUDENT: ScopeOwn},
+	SubmissionUpdate: {pkg.ROLE_STUDENT: ScopeOwn},
+	SubmissionGrade:  {pkg.ROLE_ADMIN: ScopeAll, pkg.ROLE_TEACHER: ScopeOwn},
+	SubmissionReview: {pkg.ROLE_ADMIN: ScopeAll, pkg.ROLE_TEACHER: ScopeOwn},
+	// students withdraw their own work until it is graded
+	SubmissionDelete: {pkg.ROLE_ADMIN: ScopeAll, pkg.ROLE_STUDENT: ScopeOwn},
+
+	// rubrics are shared between teachers and visible to students so they know how work is graded
+	RubricCreate: {pkg.ROLE_ADMIN: ScopeAll, pkg.ROLE_TEACHER: ScopeAll},
+	RubricRead:   {pkg.ROLE_ADMIN: ScopeAll, pkg.ROLE_TEACHER: ScopeAll, pkg.ROLE_STUDENT: ScopeAll},
+
+	// teachers read the gradebook of their courses, students only their own row
+	GradebookRead: {pkg.ROLE_ADMIN: ScopeAll, pkg.ROLE_TEACHER: ScopeOwn, pkg.ROLE_STUDENT: ScopeOwn},
+
+	// grading schemes are shared like rubrics
+	GradingSchemeCreate: {pkg.ROLE_ADMIN: ScopeAll, pkg.ROLE_TEACHER: ScopeAll},
+	GradingSchemeRead:   {pkg.ROLE_ADMIN: ScopeAll, pkg.ROLE_TEACHER: ScopeAll, pkg.ROLE_STUDENT: ScopeAll},
+
+	// a transcript spans every course of the student, so only admins read other students' transcripts
+	TranscriptRead: {pkg.ROLE_ADMIN: ScopeAll, pkg.ROLE_STUDENT: ScopeOwn},
+
+	SessionRevoke: {pkg.ROLE_ADMIN: ScopeAll},
+
+	UserDelete: {pkg.ROLE_ADMIN: ScopeAll},
+
+	// soft-deleted records of any kind are only brought back or removed for good by admins
+	RecordRestore: {pkg.ROLE_ADMIN: ScopeAll},
+	RecordPurge:   {pkg.ROLE_ADMIN: ScopeAll},
+
+	// teachers read the history of their own courses, the whole log and its integrity are for admins
+	AuditRead:   {pkg.ROLE_ADMIN: ScopeAll, pkg.ROLE_TEACHER: ScopeOwn},
+	AuditVerify: {pkg.ROLE_ADMIN: ScopeAll},
+
+	// webhooks send events of every course outside the LMS
+	WebhookManage: {pkg.ROLE_ADMIN: ScopeAll},
+
+	// scheduled jobs run across every course
+	SchedulerRead: {pkg.ROLE_ADMIN: ScopeAll},
+
+	// job payloads can carry data of any course or user
+	JobManage: {pkg.ROLE_ADMIN: ScopeAll},
+}
+
+// Subject is the user performing an action
+type Subject struct {
+	ID   uuid.UUID
+	Role string
+}
+
+// Resource describes the users that own the target of an action
+type Resource struct {
+	Owners []uuid.UUID
+}
+
+// OwnedBy builds a resource owned by the given users
+func OwnedBy(owners ...uuid.UUID) Resource {
+	return Resource{Owners: owners}
+}
+
+// IsOwner reports whether the user is one of the owners of the resource
+func (r Resource) IsOwner(id uuid.UUID) bool {
+	if id == uuid.Nil {
+		return false
+	}
+	for _, owner := range r.Owners {
+		if owner == id {
+			return true
+		}
+	}
+	return false
+}
+
+type Policy struct {
+	rules Rules
+}
+
+func New(rules Rules) *Policy {
+	return &Policy{rules: rules}
+}
+
+// Default returns the policy enforcing DefaultRules
+func Default() *Policy {
+	return New(DefaultRules)
+}
+
+// Actions returns every action known by the policy
+func (p *Policy) Actions() []Action {
+	actions := make([]Action, 0, len(p.rules))
+	for action := range p.rules {
+		actions = append(actions, action)
+	}
+	return actions
+}
+
+// Scope returns the scope granted to the role for the action
+func (p *Policy) Scope(role string, action Action) Scope {
+	return p.rules[action][role]
+}
+
+// AllowsRole reports whether the role may perform the action on at least some resources.
+// It is used where the resource is not known yet, such as in the HTTP middleware.
+func (p *Policy) AllowsRole(role string, action Action) bool {
+	return p.Scope(role, action) != ScopeNone
+}
+
+// Can reports whether the subject may perform the action on the resource
+func (p *Policy) Can(subject Subject, action Action, resource Resource) bool {
+	switch p.Scope(subject.Role, action) {
+	case ScopeAll:
+		return true
+	case ScopeOwn:
+		return resource.IsOwner(subject.ID)
+	default:
+		return false
+	}
+}
+
+// Authorize is Can returning a forbidden AppError when the action is denied
+func (p *Policy) Authorize(subject Subject, action Action, resource Resource) error {
+	if p.Can(subject, action, resource) {
+		return nil
+	}
+	return pkg.NewForbiddenError(fmt.Sprintf("%s is not allowed to %s", subject.Role, action), nil)
+}
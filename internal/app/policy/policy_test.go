@@ -0,0 +1,130 @@
+package policy
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"edukita-teaching-grading/internal/pkg"
+
+	"github.com/google/uuid"
+)
+
+type testCase struct {
+	name     string
+	role     string
+	action   Action
+	resource Resource
+	want     bool
+}
+
+func TestPolicyCan(t *testing.T) {
+	p := Default()
+
+	self := uuid.New()
+	other := uuid.New()
+
+	roles := []string{pkg.ROLE_ADMIN, pkg.ROLE_TEACHER, pkg.ROLE_STUDENT, "guest"}
+
+	// expected scope per action for admin, teacher, student and an unknown role
+	matrix := map[Action][4]Scope{
+		CourseCreate: {ScopeAll, ScopeAll, ScopeNone, ScopeNone},
+		CourseRead:   {ScopeAll, ScopeAll, ScopeAll, ScopeNone},
+		CourseUpdate: {ScopeAll, ScopeOwn, ScopeNone, ScopeNone},
+		CourseDelete: {ScopeAll, ScopeOwn, ScopeNone, ScopeNone},
+
+		CourseTeacherManage: {ScopeAll, ScopeOwn, ScopeNone, ScopeNone},
+
+		EnrollmentManage: {ScopeAll, ScopeOwn, ScopeNone, ScopeNone},
+		EnrollmentRead:   {ScopeAll, ScopeOwn, ScopeNone, ScopeNone},
+
+		AssignmentCreate: {ScopeAll, ScopeOwn, ScopeNone, ScopeNone},
+		AssignmentRead:   {ScopeAll, ScopeAll, ScopeAll, ScopeNone},
+		AssignmentUpdate: {ScopeAll, ScopeOwn, ScopeNone, ScopeNone},
+		AssignmentDelete: {ScopeAll, ScopeOwn, ScopeNone, ScopeNone},
+
+		SubmissionCreate: {ScopeNone, ScopeNone, ScopeAll, ScopeNone},
+		SubmissionRead:   {ScopeAll, ScopeOwn, ScopeOwn, ScopeNone},
+		SubmissionUpdate: {ScopeNone, ScopeNone, ScopeOwn, ScopeNone},
+		SubmissionGrade:  {ScopeAll, ScopeOwn, ScopeNone, ScopeNone},
+		SubmissionReview: {ScopeAll, ScopeOwn, ScopeNone, ScopeNone},
+		SubmissionDelete: {ScopeAll, ScopeNone, ScopeOwn, ScopeNone},
+
+		RubricCreate: {ScopeAll, ScopeAll, ScopeNone, ScopeNone},
+		RubricRead:   {ScopeAll, ScopeAll, ScopeAll, ScopeNone},
+
+		GradebookRead: {ScopeAll, ScopeOwn, ScopeOwn, ScopeNone},
+
+		GradingSchemeCreate: {ScopeAll, ScopeAll, ScopeNone, ScopeNone},
+		GradingSchemeRead:   {ScopeAll, ScopeAll, ScopeAll, ScopeNone},
+
+		TranscriptRead: {ScopeAll, ScopeNone, ScopeOwn, ScopeNone},
+
+		SessionRevoke: {ScopeAll, ScopeNone, ScopeNone, ScopeNone},
+
+		UserDelete: {ScopeAll, ScopeNone, ScopeNone, ScopeNone},
+
+		RecordRestore: {ScopeAll, ScopeNone, ScopeNone, ScopeNone},
+		RecordPurge:   {ScopeAll, ScopeNone, ScopeNone, ScopeNone},
+
+		AuditRead:   {ScopeAll, ScopeOwn, ScopeNone, ScopeNone},
+		AuditVerify: {ScopeAll, ScopeNone, ScopeNone, ScopeNone},
+
+		WebhookManage: {ScopeAll, ScopeNone, ScopeNone, ScopeNone},
+
+		SchedulerRead: {ScopeAll, ScopeNone, ScopeNone, ScopeNone},
+
+		JobManage: {ScopeAll, ScopeNone, ScopeNone, ScopeNone},
+	}
+
+	for _, action := range p.Actions() {
+		if _, ok := matrix[action]; !ok {
+			t.Errorf("action %s is missing from the test matrix", action)
+		}
+	}
+
+	tests := []testCase{}
+	for action, scopes := range matrix {
+		for i, role := range roles {
+			scope := scopes[i]
+			tests = append(tests,
+				testCase{role + " " + string(action) + " owned", role, action, OwnedBy(self), scope != ScopeNone},
+				testCase{role + " " + string(action) + " not owned", role, action, OwnedBy(other), scope == ScopeAll},
+				testCase{role + " " + string(action) + " co-owned", role, action, OwnedBy(other, self), scope != ScopeNone},
+			)
+		}
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := p.Can(Subject{ID: self, Role: tt.role}, tt.action, tt.resource)
+			if got != tt.want {
+				t.Errorf("Can() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPolicyAuthorize(t *testing.T) {
+	p := Default()
+	teacher := Subject{ID: uuid.New(), Role: pkg.ROLE_TEACHER}
+
+	if err := p.Authorize(teacher, CourseUpdate, OwnedBy(teacher.ID)); err != nil {
+		t.Fatalf("Authorize() unexpected error: %v", err)
+	}
+
+	err := p.Authorize(teacher, CourseUpdate, OwnedBy(uuid.New()))
+	var e *pkg.AppError
+	if !errors.As(err, &e) {
+		t.Fatalf("Authorize() error = %v, want *pkg.AppError", err)
+	}
+	if e.StatusCode != http.StatusForbidden {
+		t.Errorf("Authorize() code = %d, want %d", e.StatusCode, http.StatusForbidden)
+	}
+}
+
+func TestResourceIsOwnerNil(t *testing.T) {
+	if OwnedBy(uuid.Nil).IsOwner(uuid.Nil) {
+		t.Error("IsOwner() should never match the nil uuid")
+	}
+}
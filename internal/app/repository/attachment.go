@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+
+	"edukita-teaching-grading/internal/app/model"
+	"edukita-teaching-grading/internal/pkg"
+
+	"github.com/doug-martin/goqu/v9"
+)
+
+type (
+	IAttachmentRepository interface {
+		CreateAttachment(ctx context.Context, attachment model.Attachment, tx DBTX) (doc model.Attachment, err error)
+		GetAttachmentByID(ctx context.Context, id string, tx DBTX) (doc model.Attachment, err error)
+		ListAttachmentsBySubmissionID(ctx context.Context, submissionID string, q pkg.ListQuery, tx DBTX) (docs []model.Attachment, page pkg.ListPage, err error)
+		ListAttachmentsByAssignmentID(ctx context.Context, assignmentID string, q pkg.ListQuery, tx DBTX) (docs []model.Attachment, page pkg.ListPage, err error)
+	}
+	AttachmentRepository struct {
+		RepositoryOption
+	}
+)
+
+func InitiateAttachmentRepository(opt RepositoryOption) IAttachmentRepository {
+	return &AttachmentRepository{
+		RepositoryOption: opt,
+	}
+}
+
+func (r *AttachmentRepository) CreateAttachment(ctx context.Context, attachment model.Attachment, tx DBTX) (doc model.Attachment, err error) {
+	query, _, err := goqu.Insert(fmt.Sprintf("%s.%s", pkg.SCHEMA_NAME, pkg.TABLE_ATTACHMENTS)).
+		Rows(attachment).
+		Returning("*").
+		ToSQL()
+	if err != nil {
+		return
+	}
+
+	if err = tx.QueryRowxContext(ctx, query).StructScan(&doc); err != nil {
+		err = pkg.NewDatabaseError(err)
+		return
+	}
+	return
+}
+
+func (r *AttachmentRepository) GetAttachmentByID(ctx context.Context, id string, tx DBTX) (doc model.Attachment, err error) {
+	query, _, err := goqu.Select("*").
+		From(fmt.Sprintf("%s.%s", pkg.SCHEMA_NAME, pkg.TABLE_ATTACHMENTS)).
+		Where(
+			goqu.Ex{"id": id},
+			goqu.Ex{"deleted_at": nil},
+		).
+		ToSQL()
+	if err != nil {
+		return
+	}
+
+	if err = tx.GetContext(ctx, &doc, query); err != nil {
+		if err == sql.ErrNoRows {
+			err = &pkg.AppError{
+				Code:       "ATTACHMENT_NOT_FOUND",
+				Message:    "attachment not found",
+				StatusCode: http.StatusNotFound,
+				Err:        fmt.Errorf("attachment not found"),
+			}
+		} else {
+			err = pkg.NewDatabaseError(err)
+			return
+		}
+		return
+	}
+	return
+}
+
+var attachmentListSpec = listSpec{
+	sorts: map[string]string{
+		"file_name":  "file_name",
+		"size_bytes": "size_bytes",
+		"created_at": "created_at",
+	},
+	filters: map[string]listFilter{
+		"content_type": {column: "content_type", kind: filterString},
+	},
+	defaultSort: []string{"created_at"},
+	key:         "id",
+}
+
+func (r *AttachmentRepository) ListAttachmentsBySubmissionID(ctx context.Context, submissionID string, q pkg.ListQuery, tx DBTX) (docs []model.Attachment, page pkg.ListPage, err error) {
+	ds := goqu.From(goqu.T(pkg.TABLE_ATTACHMENTS).Schema(pkg.SCHEMA_NAME)).
+		Where(
+			goqu.Ex{"submission_id": submissionID},
+			goqu.Ex{"deleted_at": nil},
+		)
+	return selectPage[model.Attachment](ctx, tx, ds, q, attachmentListSpec)
+}
+
+func (r *AttachmentRepository) ListAttachmentsByAssignmentID(ctx context.Context, assignmentID string, q pkg.ListQuery, tx DBTX) (docs []model.Attachment, page pkg.ListPage, err error) {
+	ds := goqu.From(goqu.T(pkg.TABLE_ATTACHMENTS).Schema(pkg.SCHEMA_NAME)).
+		Where(
+			goqu.Ex{"assignment_id": assignmentID},
+			goqu.Ex{"deleted_at": nil},
+		)
+	return selectPage[model.Attachment](ctx, tx, ds, q, attachmentListSpec)
+}
@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"edukita-teaching-grading/internal/app/model"
+	"edukita-teaching-grading/internal/pkg"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+)
+
+type (
+	IAuditRepository interface {
+		GetAuditChainHead(ctx context.Context, tx DBTX) (doc model.AuditChainHead, err error)
+		LockAuditChainHead(ctx context.Context, tx DBTX) (doc model.AuditChainHead, err error)
+		UpdateAuditChainHead(ctx context.Context, head model.AuditChainHead, tx DBTX) (err error)
+		CreateAuditEvent(ctx context.Context, event model.AuditEvent, tx DBTX) (doc model.AuditEvent, err error)
+		ListAuditEvents(ctx context.Context, q pkg.ListQuery, tx DBTX) (docs []model.AuditEvent, page pkg.ListPage, err error)
+		GetAuditEventsAfterSeq(ctx context.Context, seq int64, limit uint, tx DBTX) (docs []model.AuditEvent, err error)
+	}
+	AuditRepository struct {
+		RepositoryOption
+	}
+)
+
+func InitiateAuditRepository(opt RepositoryOption) IAuditRepository {
+	return &AuditRepository{
+		RepositoryOption: opt,
+	}
+}
+
+func (r *AuditRepository) GetAuditChainHead(ctx context.Context, tx DBTX) (doc model.AuditChainHead, err error) {
+	return r.getAuditChainHead(ctx, auditChainHeadQuery(), tx)
+}
+
+// LockAuditChainHead returns the tip of the audit chain and locks it until the transaction ends, so events
+// are appended one transaction at a time
+func (r *AuditRepository) LockAuditChainHead(ctx context.Context, tx DBTX) (doc model.AuditChainHead, err error) {
+	return r.getAuditChainHead(ctx, auditChainHeadQuery().ForUpdate(exp.Wait), tx)
+}
+
+func auditChainHeadQuery() *goqu.SelectDataset {
+	return goqu.Select("seq", "hash").
+		From(goqu.T(pkg.TABLE_AUDIT_HEAD).Schema(pkg.SCHEMA_NAME))
+}
+
+func (r *AuditRepository) getAuditChainHead(ctx context.Context, ds *goqu.SelectDataset, tx DBTX) (doc model.AuditChainHead, err error) {
+	query, _, err := ds.ToSQL()
+	if err != nil {
+		return
+	}
+
+	if err = tx.GetContext(ctx, &doc, query); err != nil {
+		if err == sql.ErrNoRows {
+			err = notFoundError("AUDIT_CHAIN_NOT_FOUND", "audit chain head not found")
+			return
+		}
+		err = pkg.NewDatabaseError(err)
+		return
+	}
+	return
+}
+
+func (r *AuditRepository) UpdateAuditChainHead(ctx context.Context, head model.AuditChainHead, tx DBTX) (err error) {
+	query, _, err := goqu.Update(goqu.T(pkg.TABLE_AUDIT_HEAD).Schema(pkg.SCHEMA_NAME)).
+		Set(goqu.Record{"seq": head.Seq, "hash": head.Hash}).
+		ToSQL()
+	if err != nil {
+		return
+	}
+
+	if _, err = tx.ExecContext(ctx, query); err != nil {
+		err = pkg.NewDatabaseError(err)
+		return
+	}
+	return
+}
+
+func (r *AuditRepository) CreateAuditEvent(ctx context.Context, event model.AuditEvent, tx DBTX) (doc model.AuditEvent, err error) {
+	query, _, err := goqu.Insert(goqu.T(pkg.TABLE_AUDIT_EVENTS).Schema(pkg.SCHEMA_NAME)).
+		Rows(event).
+		Returning("*").
+		ToSQL()
+	if err != nil {
+		return
+	}
+
+	if err = tx.QueryRowxContext(ctx, query).StructScan(&doc); err != nil {
+		err = pkg.NewDatabaseError(err)
+		return
+	}
+	return
+}
+
+var auditEventListSpec = listSpec{
+	sorts: map[string]string{
+		"seq":        "seq",
+		"created_at": "created_at",
+	},
+	filters: map[string]listFilter{
+		"actor_id":    {column: "actor_id", kind: filterUUID},
+		"entity_type": {column: "entity_type", kind: filterString},
+		"entity_id":   {column: "entity_id", kind: filterUUID},
+		"course_id":   {column: "course_id", kind: filterUUID},
+		"action":      {column: "action", kind: filterString},
+	},
+	defaultSort: []string{"-seq"},
+	key:         "seq",
+}
+
+func (r *AuditRepository) ListAuditEvents(ctx context.Context, q pkg.ListQuery, tx DBTX) (docs []model.AuditEvent, page pkg.ListPage, err error) {
+	ds := goqu.From(goqu.T(pkg.TABLE_AUDIT_EVENTS).Schema(pkg.SCHEMA_NAME))
+	return selectPage[model.AuditEvent](ctx, tx, ds, q, auditEventListSpec)
+}
+
+// GetAuditEventsAfterSeq returns up to limit events following seq in chain order
+func (r *AuditRepository) GetAuditEventsAfterSeq(ctx context.Context, seq int64, limit uint, tx DBTX) (docs []model.AuditEvent, err error) {
+	query, _, err := goqu.From(goqu.T(pkg.TABLE_AUDIT_EVENTS).Schema(pkg.SCHEMA_NAME)).
+		Where(goqu.I("seq").Gt(seq)).
+		Order(goqu.I("seq").Asc()).
+		Limit(limit).
+		ToSQL()
+	if err != nil {
+		return
+	}
+
+	if err = tx.SelectContext(ctx, &docs, query); err != nil {
+		err = pkg.NewDatabaseError(err)
+		return
+	}
+	return
+}
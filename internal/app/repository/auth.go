@@ -0,0 +1,436 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"time"
+
+	"edukita-teaching-grading/internal/app/model"
+	"edukita-teaching-grading/internal/pkg"
+
+	"github.com/doug-martin/goqu/v9"
+)
+
+type (
+	IAuthRepository interface {
+		// Refresh Token
+		CreateRefreshToken(ctx context.Context, token model.RefreshToken, tx DBTX) (doc model.RefreshToken, err error)
+		GetRefreshTokenByHash(ctx context.Context, hash string, tx DBTX) (doc model.RefreshToken, err error)
+		UpdateRefreshTokenByID(ctx context.Context, token model.RefreshToken, tx DBTX) (doc model.RefreshToken, err error)
+		RevokeRefreshTokensByUserID(ctx context.Context, userID string, revokedAt time.Time, tx DBTX) (docs []model.RefreshToken, err error)
+		DeleteExpiredRefreshTokens(ctx context.Context, expiredBefore time.Time, tx DBTX) (deleted int64, err error)
+
+		// Revoked Access Token
+		CreateRevokedToken(ctx context.Context, token model.RevokedToken, tx DBTX) (err error)
+		IsTokenRevoked(ctx context.Context, tokenID string, tx DBTX) (revoked bool, err error)
+		DeleteExpiredRevokedTokens(ctx context.Context, expiredBefore time.Time, tx DBTX) (deleted int64, err error)
+
+		// User Token
+		CreateUserToken(ctx context.Context, token model.UserToken, tx DBTX) (doc model.UserToken, err error)
+		GetUserTokenByHash(ctx context.Context, hash string, purpose string, tx DBTX) (doc model.UserToken, err error)
+		GetUserTokenByID(ctx context.Context, id string, tx DBTX) (doc model.UserToken, err error)
+		UseUserTokensByUserID(ctx context.Context, userID string, purpose string, usedAt time.Time, tx DBTX) (used int64, err error)
+		UpdateUserTokenByID(ctx context.Context, token model.UserToken, tx DBTX) (doc model.UserToken, err error)
+		DeleteExpiredUserTokens(ctx context.Context, expiredBefore time.Time, tx DBTX) (deleted int64, err error)
+
+		// MFA
+		GetUserMFAByUserID(ctx context.Context, userID string, tx DBTX) (doc model.UserMFA, err error)
+		GetUserMFAByUserIDForUpdate(ctx context.Context, userID string, tx DBTX) (doc model.UserMFA, err error)
+		SaveUserMFA(ctx context.Context, mfa model.UserMFA, tx DBTX) (doc model.UserMFA, err error)
+		DeleteUserMFA(ctx context.Context, userID string, tx DBTX) (err error)
+		ReplaceRecoveryCodes(ctx context.Context, userID string, codes []model.RecoveryCode, tx DBTX) (err error)
+		UseRecoveryCode(ctx context.Context, userID string, hash string, usedAt time.Time, tx DBTX) (used bool, err error)
+		CountUnusedRecoveryCodes(ctx context.Context, userID string, tx DBTX) (count int, err error)
+	}
+	AuthRepository struct {
+		RepositoryOption
+	}
+)
+
+func InitiateAuthRepository(opt RepositoryOption) IAuthRepository {
+	return &AuthRepository{
+		RepositoryOption: opt,
+	}
+}
+
+func (r *AuthRepository) CreateRefreshToken(ctx context.Context, token model.RefreshToken, tx DBTX) (doc model.RefreshToken, err error) {
+	query, _, err := goqu.Insert(fmt.Sprintf("%s.%s", pkg.SCHEMA_NAME, pkg.TABLE_REFRESH_TOKENS)).
+		Rows(token).
+		Returning("*").
+		ToSQL()
+	if err != nil {
+		return
+	}
+
+	if err = tx.QueryRowxContext(ctx, query).StructScan(&doc); err != nil {
+		err = pkg.NewDatabaseError(err)
+		return
+	}
+	return
+}
+
+func (r *AuthRepository) GetRefreshTokenByHash(ctx context.Context, hash string, tx DBTX) (doc model.RefreshToken, err error) {
+	query, _, err := goqu.Select("*").
+		From(fmt.Sprintf("%s.%s", pkg.SCHEMA_NAME, pkg.TABLE_REFRESH_TOKENS)).
+		Where(
+			goqu.Ex{"token_hash": hash},
+		).
+		ForUpdate(goqu.Wait).
+		ToSQL()
+	if err != nil {
+		return
+	}
+
+	if err = tx.GetContext(ctx, &doc, query); err != nil {
+		if err == sql.ErrNoRows {
+			err = &pkg.AppError{
+				Code:       "REFRESH_TOKEN_NOT_FOUND",
+				Message:    "invalid refresh token",
+				StatusCode: http.StatusUnauthorized,
+				Err:        fmt.Errorf("refresh token not found"),
+			}
+		} else {
+			err = pkg.NewDatabaseError(err)
+			return
+		}
+		return
+	}
+	return
+}
+
+func (r *AuthRepository) UpdateRefreshTokenByID(ctx context.Context, token model.RefreshToken, tx DBTX) (doc model.RefreshToken, err error) {
+	query, _, err := goqu.From(fmt.Sprintf("%s.%s", pkg.SCHEMA_NAME, pkg.TABLE_REFRESH_TOKENS)).
+		Update().
+		Set(token).
+		Where(goqu.Ex{"id": token.ID}).
+		Returning("*").
+		ToSQL()
+	if err != nil {
+		return
+	}
+
+	if err = tx.QueryRowxContext(ctx, query).StructScan(&doc); err != nil {
+		err = pkg.NewDatabaseError(err)
+		return
+	}
+	return
+}
+
+func (r *AuthRepository) RevokeRefreshTokensByUserID(ctx context.Context, userID string, revokedAt time.Time, tx DBTX) (docs []model.RefreshToken, err error) {
+	query, _, err := goqu.From(fmt.Sprintf("%s.%s", pkg.SCHEMA_NAME, pkg.TABLE_REFRESH_TOKENS)).
+		Update().
+		Set(goqu.Record{"revoked_at": revokedAt}).
+		Where(
+			goqu.Ex{"user_id": userID},
+			goqu.Ex{"revoked_at": nil},
+		).
+		Returning("*").
+		ToSQL()
+	if err != nil {
+		return
+	}
+
+	if err = tx.SelectContext(ctx, &docs, query); err != nil {
+		err = pkg.NewDatabaseError(err)
+		return
+	}
+	return
+}
+
+func (r *AuthRepository) CreateRevokedToken(ctx context.Context, token model.RevokedToken, tx DBTX) (err error) {
+	query, _, err := goqu.Insert(fmt.Sprintf("%s.%s", pkg.SCHEMA_NAME, pkg.TABLE_REVOKED_TOKENS)).
+		Rows(token).
+		OnConflict(goqu.DoNothing()).
+		ToSQL()
+	if err != nil {
+		return
+	}
+
+	if _, err = tx.ExecContext(ctx, query); err != nil {
+		err = pkg.NewDatabaseError(err)
+		return
+	}
+	return
+}
+
+func (r *AuthRepository) IsTokenRevoked(ctx context.Context, tokenID string, tx DBTX) (revoked bool, err error) {
+	query, _, err := goqu.Select(goqu.COUNT("*")).
+		From(fmt.Sprintf("%s.%s", pkg.SCHEMA_NAME, pkg.TABLE_REVOKED_TOKENS)).
+		Where(
+			goqu.Ex{"token_id": tokenID},
+		).
+		ToSQL()
+	if err != nil {
+		return
+	}
+
+	var count int
+	if err = tx.GetContext(ctx, &count, query); err != nil {
+		err = pkg.NewDatabaseError(err)
+		return
+	}
+	return count > 0, nil
+}
+
+// DeleteExpiredRefreshTokens removes refresh tokens that can no longer be used, revoked or not
+func (r *AuthRepository) DeleteExpiredRefreshTokens(ctx context.Context, expiredBefore time.Time, tx DBTX) (deleted int64, err error) {
+	return r.deleteExpired(ctx, pkg.TABLE_REFRESH_TOKENS, expiredBefore, tx)
+}
+
+// DeleteExpiredRevokedTokens shortens the deny list, an access token past its expiry is refused anyway
+func (r *AuthRepository) DeleteExpiredRevokedTokens(ctx context.Context, expiredBefore time.Time, tx DBTX) (deleted int64, err error) {
+	return r.deleteExpired(ctx, pkg.TABLE_REVOKED_TOKENS, expiredBefore, tx)
+}
+
+func (r *AuthRepository) CreateUserToken(ctx context.Context, token model.UserToken, tx DBTX) (doc model.UserToken, err error) {
+	query, _, err := goqu.Insert(goqu.T(pkg.TABLE_USER_TOKENS).Schema(pkg.SCHEMA_NAME)).
+		Rows(token).
+		Returning("*").
+		ToSQL()
+	if err != nil {
+		return
+	}
+
+	if err = tx.QueryRowxContext(ctx, query).StructScan(&doc); err != nil {
+		err = pkg.NewDatabaseError(err)
+		return
+	}
+	return
+}
+
+// GetUserTokenByHash locks the token with the purpose, used and expired tokens are returned as well
+func (r *AuthRepository) GetUserTokenByHash(ctx context.Context, hash string, purpose string, tx DBTX) (doc model.UserToken, err error) {
+	query, _, err := goqu.Select("*").
+		From(goqu.T(pkg.TABLE_USER_TOKENS).Schema(pkg.SCHEMA_NAME)).
+		Where(
+			goqu.Ex{"token_hash": hash},
+			goqu.Ex{"purpose": purpose},
+		).
+		ForUpdate(goqu.Wait).
+		ToSQL()
+	if err != nil {
+		return
+	}
+
+	if err = tx.GetContext(ctx, &doc, query); err != nil {
+		if err == sql.ErrNoRows {
+			err = &pkg.AppError{
+				Code:       "USER_TOKEN_NOT_FOUND",
+				Message:    "invalid or expired token",
+				StatusCode: http.StatusBadRequest,
+				Err:        fmt.Errorf("user token not found"),
+			}
+		} else {
+			err = pkg.NewDatabaseError(err)
+		}
+		return
+	}
+	return
+}
+
+// GetUserTokenByID returns the token an email links to, used and expired tokens are returned as well
+func (r *AuthRepository) GetUserTokenByID(ctx context.Context, id string, tx DBTX) (doc model.UserToken, err error) {
+	query, _, err := goqu.Select("*").
+		From(goqu.T(pkg.TABLE_USER_TOKENS).Schema(pkg.SCHEMA_NAME)).
+		Where(goqu.Ex{"id": id}).
+		ToSQL()
+	if err != nil {
+		return
+	}
+	return returningOne[model.UserToken](ctx, tx, query, notFoundError("USER_TOKEN_NOT_FOUND", "user token not found"))
+}
+
+// UseUserTokensByUserID marks the unused tokens of the user with the purpose as used, so only the latest link
+// mailed works and a token works once
+func (r *AuthRepository) UseUserTokensByUserID(ctx context.Context, userID string, purpose string, usedAt time.Time, tx DBTX) (used int64, err error) {
+	query, _, err := goqu.From(goqu.T(pkg.TABLE_USER_TOKENS).Schema(pkg.SCHEMA_NAME)).
+		Update().
+		Set(goqu.Record{"used_at": usedAt}).
+		Where(
+			goqu.Ex{"user_id": userID},
+			goqu.Ex{"purpose": purpose},
+			goqu.Ex{"used_at": nil},
+		).
+		ToSQL()
+	if err != nil {
+		return
+	}
+
+	result, err := tx.ExecContext(ctx, query)
+	if err != nil {
+		err = pkg.NewDatabaseError(err)
+		return
+	}
+	return result.RowsAffected()
+}
+
+func (r *AuthRepository) UpdateUserTokenByID(ctx context.Context, token model.UserToken, tx DBTX) (doc model.UserToken, err error) {
+	query, _, err := goqu.From(goqu.T(pkg.TABLE_USER_TOKENS).Schema(pkg.SCHEMA_NAME)).
+		Update().
+		Set(token).
+		Where(goqu.Ex{"id": token.ID}).
+		Returning("*").
+		ToSQL()
+	if err != nil {
+		return
+	}
+
+	if err = tx.QueryRowxContext(ctx, query).StructScan(&doc); err != nil {
+		err = pkg.NewDatabaseError(err)
+		return
+	}
+	return
+}
+
+// DeleteExpiredUserTokens removes reset, verification and challenge tokens that can no longer be used, used or not
+func (r *AuthRepository) DeleteExpiredUserTokens(ctx context.Context, expiredBefore time.Time, tx DBTX) (deleted int64, err error) {
+	return r.deleteExpired(ctx, pkg.TABLE_USER_TOKENS, expiredBefore, tx)
+}
+
+// GetUserMFAByUserID reads the TOTP enrollment of the user, enabled or not
+func (r *AuthRepository) GetUserMFAByUserID(ctx context.Context, userID string, tx DBTX) (doc model.UserMFA, err error) {
+	return r.getUserMFA(ctx, userMFAByUserIDQuery(userID), tx)
+}
+
+// GetUserMFAByUserIDForUpdate locks the TOTP enrollment of the user until the transaction ends
+func (r *AuthRepository) GetUserMFAByUserIDForUpdate(ctx context.Context, userID string, tx DBTX) (doc model.UserMFA, err error) {
+	return r.getUserMFA(ctx, userMFAByUserIDQuery(userID).ForUpdate(goqu.Wait), tx)
+}
+
+func userMFAByUserIDQuery(userID string) *goqu.SelectDataset {
+	return goqu.Select("*").
+		From(goqu.T(pkg.TABLE_USER_MFA).Schema(pkg.SCHEMA_NAME)).
+		Where(goqu.Ex{"user_id": userID})
+}
+
+func (r *AuthRepository) getUserMFA(ctx context.Context, ds *goqu.SelectDataset, tx DBTX) (doc model.UserMFA, err error) {
+	query, _, err := ds.ToSQL()
+	if err != nil {
+		return
+	}
+	return returningOne[model.UserMFA](ctx, tx, query, notFoundError("MFA_NOT_FOUND", "two-factor authentication is not set up"))
+}
+
+// SaveUserMFA creates the enrollment of the user or replaces it, such as when enrolling again before confirming
+func (r *AuthRepository) SaveUserMFA(ctx context.Context, mfa model.UserMFA, tx DBTX) (doc model.UserMFA, err error) {
+	query, _, err := goqu.Insert(goqu.T(pkg.TABLE_USER_MFA).Schema(pkg.SCHEMA_NAME)).
+		Rows(mfa).
+		OnConflict(goqu.DoUpdate("user_id", goqu.Record{
+			"secret":         goqu.I("excluded.secret"),
+			"enabled_at":     goqu.I("excluded.enabled_at"),
+			"last_used_step": goqu.I("excluded.last_used_step"),
+		})).
+		Returning("*").
+		ToSQL()
+	if err != nil {
+		return
+	}
+
+	if err = tx.QueryRowxContext(ctx, query).StructScan(&doc); err != nil {
+		err = pkg.NewDatabaseError(err)
+		return
+	}
+	return
+}
+
+// DeleteUserMFA removes the enrollment of the user together with their recovery codes
+func (r *AuthRepository) DeleteUserMFA(ctx context.Context, userID string, tx DBTX) (err error) {
+	for _, table := range []string{pkg.TABLE_RECOVERY_CODES, pkg.TABLE_USER_MFA} {
+		query, _, err := goqu.Delete(goqu.T(table).Schema(pkg.SCHEMA_NAME)).
+			Where(goqu.Ex{"user_id": userID}).
+			ToSQL()
+		if err != nil {
+			return err
+		}
+
+		if _, err = tx.ExecContext(ctx, query); err != nil {
+			return pkg.NewDatabaseError(err)
+		}
+	}
+	return
+}
+
+// ReplaceRecoveryCodes swaps every recovery code of the user, used or not, for the new codes
+func (r *AuthRepository) ReplaceRecoveryCodes(ctx context.Context, userID string, codes []model.RecoveryCode, tx DBTX) (err error) {
+	query, _, err := goqu.Delete(goqu.T(pkg.TABLE_RECOVERY_CODES).Schema(pkg.SCHEMA_NAME)).
+		Where(goqu.Ex{"user_id": userID}).
+		ToSQL()
+	if err != nil {
+		return
+	}
+
+	if _, err = tx.ExecContext(ctx, query); err != nil {
+		return pkg.NewDatabaseError(err)
+	}
+	if len(codes) == 0 {
+		return
+	}
+
+	query, _, err = goqu.Insert(goqu.T(pkg.TABLE_RECOVERY_CODES).Schema(pkg.SCHEMA_NAME)).
+		Rows(codes).
+		ToSQL()
+	if err != nil {
+		return
+	}
+
+	if _, err = tx.ExecContext(ctx, query); err != nil {
+		return pkg.NewDatabaseError(err)
+	}
+	return
+}
+
+// UseRecoveryCode spends the unused recovery code of the user with the hash, used is false when there is none
+func (r *AuthRepository) UseRecoveryCode(ctx context.Context, userID string, hash string, usedAt time.Time, tx DBTX) (used bool, err error) {
+	query, _, err := goqu.From(goqu.T(pkg.TABLE_RECOVERY_CODES).Schema(pkg.SCHEMA_NAME)).
+		Update().
+		Set(goqu.Record{"used_at": usedAt}).
+		Where(
+			goqu.Ex{"user_id": userID},
+			goqu.Ex{"code_hash": hash},
+			goqu.Ex{"used_at": nil},
+		).
+		ToSQL()
+	if err != nil {
+		return
+	}
+
+	updated, err := execCount(ctx, tx, query)
+	return updated > 0, err
+}
+
+func (r *AuthRepository) CountUnusedRecoveryCodes(ctx context.Context, userID string, tx DBTX) (count int, err error) {
+	query, _, err := goqu.Select(goqu.COUNT("*")).
+		From(goqu.T(pkg.TABLE_RECOVERY_CODES).Schema(pkg.SCHEMA_NAME)).
+		Where(
+			goqu.Ex{"user_id": userID},
+			goqu.Ex{"used_at": nil},
+		).
+		ToSQL()
+	if err != nil {
+		return
+	}
+
+	if err = tx.GetContext(ctx, &count, query); err != nil {
+		err = pkg.NewDatabaseError(err)
+		return
+	}
+	return
+}
+
+func (r *AuthRepository) deleteExpired(ctx context.Context, table string, expiredBefore time.Time, tx DBTX) (deleted int64, err error) {
+	query, _, err := goqu.Delete(goqu.T(table).Schema(pkg.SCHEMA_NAME)).
+		Where(goqu.I("expires_at").Lt(expiredBefore)).
+		ToSQL()
+	if err != nil {
+		return
+	}
+
+	result, err := tx.ExecContext(ctx, query)
+	if err != nil {
+		err = pkg.NewDatabaseError(err)
+		return
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"edukita-teaching-grading/internal/app/model"
+	"edukita-teaching-grading/internal/pkg"
+
+	"github.com/doug-martin/goqu/v9"
+)
+
+type (
+	IEmailRepository interface {
+		CreateEmailMessages(ctx context.Context, emails []model.EmailMessage, tx DBTX) (docs []model.EmailMessage, err error)
+		GetEmailMessageByID(ctx context.Context, id string, tx DBTX) (doc model.EmailMessage, err error)
+		UpdateEmailMessage(ctx context.Context, email model.EmailMessage, tx DBTX) (doc model.EmailMessage, err error)
+	}
+	EmailRepository struct {
+		RepositoryOption
+	}
+)
+
+func InitiateEmailRepository(opt RepositoryOption) IEmailRepository {
+	return &EmailRepository{
+		RepositoryOption: opt,
+	}
+}
+
+// CreateEmailMessages saves the emails in one statement and returns the ones created, an address already emailed
+// about the same event is skipped
+func (r *EmailRepository) CreateEmailMessages(ctx context.Context, emails []model.EmailMessage, tx DBTX) (docs []model.EmailMessage, err error) {
+	if len(emails) == 0 {
+		return
+	}
+
+	query, _, err := goqu.Insert(goqu.T(pkg.TABLE_EMAIL_MESSAGES).Schema(pkg.SCHEMA_NAME)).
+		Rows(emails).
+		OnConflict(goqu.DoNothing()).
+		Returning("*").
+		ToSQL()
+	if err != nil {
+		return
+	}
+
+	if err = tx.SelectContext(ctx, &docs, query); err != nil {
+		err = pkg.NewDatabaseError(err)
+		return
+	}
+	return
+}
+
+func (r *EmailRepository) GetEmailMessageByID(ctx context.Context, id string, tx DBTX) (doc model.EmailMessage, err error) {
+	query, _, err := goqu.From(goqu.T(pkg.TABLE_EMAIL_MESSAGES).Schema(pkg.SCHEMA_NAME)).
+		Where(goqu.Ex{"id": id}).
+		ToSQL()
+	if err != nil {
+		return
+	}
+
+	if err = tx.GetContext(ctx, &doc, query); err != nil {
+		if err == sql.ErrNoRows {
+			err = notFoundError("EMAIL_NOT_FOUND", "email not found")
+			return
+		}
+		err = pkg.NewDatabaseError(err)
+		return
+	}
+	return
+}
+
+// UpdateEmailMessage saves the outcome of a run of its job
+func (r *EmailRepository) UpdateEmailMessage(ctx context.Context, email model.EmailMessage, tx DBTX) (doc model.EmailMessage, err error) {
+	query, _, err := goqu.Update(goqu.T(pkg.TABLE_EMAIL_MESSAGES).Schema(pkg.SCHEMA_NAME)).
+		Set(goqu.Record{
+			"status":          email.Status,
+			"attempts":        email.Attempts,
+			"next_attempt_at": email.NextAttemptAt,
+			"last_error":      email.LastError,
+			"sent_at":         email.SentAt,
+		}).
+		Where(goqu.Ex{"id": email.ID}).
+		Returning("*").
+		ToSQL()
+	if err != nil {
+		return
+	}
+
+	if err = tx.QueryRowxContext(ctx, query).StructScan(&doc); err != nil {
+		err = pkg.NewDatabaseError(err)
+		return
+	}
+	return
+}
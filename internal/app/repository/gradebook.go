@@ -0,0 +1,253 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+
+	"edukita-teaching-grading/internal/app/model"
+	"edukita-teaching-grading/internal/pkg"
+
+	"github.com/doug-martin/goqu/v9"
+)
+
+type (
+	IGradebookRepository interface {
+		CreateCategory(ctx context.Context, category model.AssignmentCategory, tx DBTX) (doc model.AssignmentCategory, err error)
+		GetCategoryByID(ctx context.Context, id string, tx DBTX) (doc model.AssignmentCategory, err error)
+		GetAllCategoriesByCourseID(ctx context.Context, courseID string, tx DBTX) (docs []model.AssignmentCategory, err error)
+		ListCategoriesByCourseID(ctx context.Context, courseID string, q pkg.ListQuery, tx DBTX) (docs []model.AssignmentCategory, page pkg.ListPage, err error)
+		UpdateCategoryByID(ctx context.Context, category model.AssignmentCategory, tx DBTX) (doc model.AssignmentCategory, err error)
+		DeleteCategoryByID(ctx context.Context, id string, tx DBTX) (doc model.AssignmentCategory, err error)
+
+		CreateGradingScheme(ctx context.Context, scheme model.GradingScheme, tx DBTX) (doc model.GradingScheme, err error)
+		GetGradingSchemeByID(ctx context.Context, id string, tx DBTX) (doc model.GradingScheme, err error)
+		ListGradingSchemes(ctx context.Context, q pkg.ListQuery, tx DBTX) (docs []model.GradingScheme, page pkg.ListPage, err error)
+		CreateGradingSchemeBand(ctx context.Context, band model.GradingSchemeBand, tx DBTX) (doc model.GradingSchemeBand, err error)
+		GetAllGradingSchemeBandsBySchemeID(ctx context.Context, schemeID string, tx DBTX) (docs []model.GradingSchemeBand, err error)
+	}
+	GradebookRepository struct {
+		RepositoryOption
+	}
+)
+
+func InitiateGradebookRepository(opt RepositoryOption) IGradebookRepository {
+	return &GradebookRepository{
+		RepositoryOption: opt,
+	}
+}
+
+func (r *GradebookRepository) CreateCategory(ctx context.Context, category model.AssignmentCategory, tx DBTX) (doc model.AssignmentCategory, err error) {
+	query, _, err := goqu.Insert(fmt.Sprintf("%s.%s", pkg.SCHEMA_NAME, pkg.TABLE_CATEGORIES)).
+		Rows(category).
+		Returning("*").
+		ToSQL()
+	if err != nil {
+		return
+	}
+
+	if err = tx.QueryRowxContext(ctx, query).StructScan(&doc); err != nil {
+		err = pkg.NewDatabaseError(err)
+		return
+	}
+	return
+}
+
+func (r *GradebookRepository) GetCategoryByID(ctx context.Context, id string, tx DBTX) (doc model.AssignmentCategory, err error) {
+	query, _, err := goqu.Select("*").
+		From(fmt.Sprintf("%s.%s", pkg.SCHEMA_NAME, pkg.TABLE_CATEGORIES)).
+		Where(
+			goqu.Ex{"id": id},
+			goqu.Ex{"deleted_at": nil},
+		).
+		ToSQL()
+	if err != nil {
+		return
+	}
+
+	if err = tx.GetContext(ctx, &doc, query); err != nil {
+		if err == sql.ErrNoRows {
+			err = &pkg.AppError{
+				Code:       "CATEGORY_NOT_FOUND",
+				Message:    "category not found",
+				StatusCode: http.StatusNotFound,
+				Err:        fmt.Errorf("category not found"),
+			}
+		} else {
+			err = pkg.NewDatabaseError(err)
+			return
+		}
+		return
+	}
+	return
+}
+
+func (r *GradebookRepository) GetAllCategoriesByCourseID(ctx context.Context, courseID string, tx DBTX) (docs []model.AssignmentCategory, err error) {
+	query, _, err := goqu.Select("*").
+		From(fmt.Sprintf("%s.%s", pkg.SCHEMA_NAME, pkg.TABLE_CATEGORIES)).
+		Where(
+			goqu.Ex{"course_id": courseID},
+			goqu.Ex{"deleted_at": nil},
+		).
+		Order(goqu.I("name").Asc()).
+		ToSQL()
+	if err != nil {
+		return
+	}
+
+	if err = tx.SelectContext(ctx, &docs, query); err != nil {
+		err = pkg.NewDatabaseError(err)
+		return
+	}
+	return
+}
+
+var categoryListSpec = listSpec{
+	sorts: map[string]string{
+		"name":       "name",
+		"weight":     "weight",
+		"created_at": "created_at",
+	},
+	defaultSort: []string{"name"},
+	key:         "id",
+}
+
+func (r *GradebookRepository) ListCategoriesByCourseID(ctx context.Context, courseID string, q pkg.ListQuery, tx DBTX) (docs []model.AssignmentCategory, page pkg.ListPage, err error) {
+	ds := goqu.From(goqu.T(pkg.TABLE_CATEGORIES).Schema(pkg.SCHEMA_NAME)).
+		Where(
+			goqu.Ex{"course_id": courseID},
+			goqu.Ex{"deleted_at": nil},
+		)
+	return selectPage[model.AssignmentCategory](ctx, tx, ds, q, categoryListSpec)
+}
+
+func (r *GradebookRepository) UpdateCategoryByID(ctx context.Context, category model.AssignmentCategory, tx DBTX) (doc model.AssignmentCategory, err error) {
+	query, _, err := goqu.From(fmt.Sprintf("%s.%s", pkg.SCHEMA_NAME, pkg.TABLE_CATEGORIES)).
+		Update().
+		Set(category).
+		Where(goqu.Ex{"id": category.ID}).
+		Returning("*").
+		ToSQL()
+	if err != nil {
+		return
+	}
+
+	if err = tx.QueryRowxContext(ctx, query).StructScan(&doc); err != nil {
+		err = pkg.NewDatabaseError(err)
+		return
+	}
+	return
+}
+
+func (r *GradebookRepository) DeleteCategoryByID(ctx context.Context, id string, tx DBTX) (doc model.AssignmentCategory, err error) {
+	query, _, err := goqu.Delete(fmt.Sprintf("%s.%s", pkg.SCHEMA_NAME, pkg.TABLE_CATEGORIES)).
+		Where(goqu.Ex{"id": id}).
+		Returning("*").
+		ToSQL()
+	if err != nil {
+		return
+	}
+
+	if err = tx.QueryRowxContext(ctx, query).StructScan(&doc); err != nil {
+		err = pkg.NewDatabaseError(err)
+		return
+	}
+	return
+}
+
+func (r *GradebookRepository) CreateGradingScheme(ctx context.Context, scheme model.GradingScheme, tx DBTX) (doc model.GradingScheme, err error) {
+	query, _, err := goqu.Insert(fmt.Sprintf("%s.%s", pkg.SCHEMA_NAME, pkg.TABLE_SCHEMES)).
+		Rows(scheme).
+		Returning("*").
+		ToSQL()
+	if err != nil {
+		return
+	}
+
+	if err = tx.QueryRowxContext(ctx, query).StructScan(&doc); err != nil {
+		err = pkg.NewDatabaseError(err)
+		return
+	}
+	return
+}
+
+func (r *GradebookRepository) GetGradingSchemeByID(ctx context.Context, id string, tx DBTX) (doc model.GradingScheme, err error) {
+	query, _, err := goqu.Select("*").
+		From(fmt.Sprintf("%s.%s", pkg.SCHEMA_NAME, pkg.TABLE_SCHEMES)).
+		Where(
+			goqu.Ex{"id": id},
+			goqu.Ex{"deleted_at": nil},
+		).
+		ToSQL()
+	if err != nil {
+		return
+	}
+
+	if err = tx.GetContext(ctx, &doc, query); err != nil {
+		if err == sql.ErrNoRows {
+			err = &pkg.AppError{
+				Code:       "GRADING_SCHEME_NOT_FOUND",
+				Message:    "grading scheme not found",
+				StatusCode: http.StatusNotFound,
+				Err:        fmt.Errorf("grading scheme not found"),
+			}
+		} else {
+			err = pkg.NewDatabaseError(err)
+			return
+		}
+		return
+	}
+	return
+}
+
+var gradingSchemeListSpec = listSpec{
+	sorts: map[string]string{
+		"name":       "name",
+		"created_at": "created_at",
+	},
+	filters: map[string]listFilter{
+		"type": {column: "type", kind: filterString},
+	},
+	defaultSort: []string{"name"},
+	key:         "id",
+}
+
+func (r *GradebookRepository) ListGradingSchemes(ctx context.Context, q pkg.ListQuery, tx DBTX) (docs []model.GradingScheme, page pkg.ListPage, err error) {
+	ds := goqu.From(goqu.T(pkg.TABLE_SCHEMES).Schema(pkg.SCHEMA_NAME)).
+		Where(goqu.Ex{"deleted_at": nil})
+	return selectPage[model.GradingScheme](ctx, tx, ds, q, gradingSchemeListSpec)
+}
+
+func (r *GradebookRepository) CreateGradingSchemeBand(ctx context.Context, band model.GradingSchemeBand, tx DBTX) (doc model.GradingSchemeBand, err error) {
+	query, _, err := goqu.Insert(fmt.Sprintf("%s.%s", pkg.SCHEMA_NAME, pkg.TABLE_BANDS)).
+		Rows(band).
+		Returning("*").
+		ToSQL()
+	if err != nil {
+		return
+	}
+
+	if err = tx.QueryRowxContext(ctx, query).StructScan(&doc); err != nil {
+		err = pkg.NewDatabaseError(err)
+		return
+	}
+	return
+}
+
+func (r *GradebookRepository) GetAllGradingSchemeBandsBySchemeID(ctx context.Context, schemeID string, tx DBTX) (docs []model.GradingSchemeBand, err error) {
+	query, _, err := goqu.Select("*").
+		From(fmt.Sprintf("%s.%s", pkg.SCHEMA_NAME, pkg.TABLE_BANDS)).
+		Where(goqu.Ex{"scheme_id": schemeID}).
+		Order(goqu.I("min_percentage").Desc()).
+		ToSQL()
+	if err != nil {
+		return
+	}
+
+	if err = tx.SelectContext(ctx, &docs, query); err != nil {
+		err = pkg.NewDatabaseError(err)
+		return
+	}
+	return
+}
@@ -0,0 +1,202 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"edukita-teaching-grading/internal/app/model"
+	"edukita-teaching-grading/internal/pkg"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+)
+
+type (
+	IJobRepository interface {
+		CreateJob(ctx context.Context, job model.Job, tx DBTX) (doc model.Job, err error)
+		GetJobByID(ctx context.Context, id string, tx DBTX) (doc model.Job, err error)
+		ListJobs(ctx context.Context, q pkg.ListQuery, tx DBTX) (docs []model.Job, page pkg.ListPage, err error)
+		CountJobs(ctx context.Context, tx DBTX) (docs []model.JobCount, err error)
+		ClaimJobs(ctx context.Context, now time.Time, leaseUntil time.Time, workerID string, limit uint, tx DBTX) (docs []model.Job, err error)
+		FinishJob(ctx context.Context, job model.Job, workerID string, tx DBTX) (doc model.Job, err error)
+		UpdateJob(ctx context.Context, job model.Job, tx DBTX) (doc model.Job, err error)
+		DeleteSucceededJobs(ctx context.Context, finishedBefore time.Time, tx DBTX) (deleted int64, err error)
+	}
+	JobRepository struct {
+		RepositoryOption
+	}
+)
+
+func InitiateJobRepository(opt RepositoryOption) IJobRepository {
+	return &JobRepository{
+		RepositoryOption: opt,
+	}
+}
+
+func (r *JobRepository) CreateJob(ctx context.Context, job model.Job, tx DBTX) (doc model.Job, err error) {
+	query, _, err := goqu.Insert(goqu.T(pkg.TABLE_JOBS).Schema(pkg.SCHEMA_NAME)).
+		Rows(job).
+		Returning("*").
+		ToSQL()
+	if err != nil {
+		return
+	}
+
+	if err = tx.QueryRowxContext(ctx, query).StructScan(&doc); err != nil {
+		err = pkg.NewDatabaseError(err)
+		return
+	}
+	return
+}
+
+func (r *JobRepository) GetJobByID(ctx context.Context, id string, tx DBTX) (doc model.Job, err error) {
+	query, _, err := goqu.From(goqu.T(pkg.TABLE_JOBS).Schema(pkg.SCHEMA_NAME)).
+		Where(goqu.Ex{"id": id}).
+		ToSQL()
+	if err != nil {
+		return
+	}
+
+	if err = tx.GetContext(ctx, &doc, query); err != nil {
+		if err == sql.ErrNoRows {
+			err = notFoundError("JOB_NOT_FOUND", "job not found")
+			return
+		}
+		err = pkg.NewDatabaseError(err)
+		return
+	}
+	return
+}
+
+var jobListSpec = listSpec{
+	sorts: map[string]string{
+		"created_at": "created_at",
+		"run_at":     "run_at",
+		"priority":   "priority",
+	},
+	filters: map[string]listFilter{
+		"type":   {column: "type", kind: filterString},
+		"status": {column: "status", kind: filterString},
+	},
+	defaultSort: []string{"-created_at"},
+	key:         "id",
+}
+
+func (r *JobRepository) ListJobs(ctx context.Context, q pkg.ListQuery, tx DBTX) (docs []model.Job, page pkg.ListPage, err error) {
+	ds := goqu.From(goqu.T(pkg.TABLE_JOBS).Schema(pkg.SCHEMA_NAME))
+	return selectPage[model.Job](ctx, tx, ds, q, jobListSpec)
+}
+
+// CountJobs counts the jobs of every type by status
+func (r *JobRepository) CountJobs(ctx context.Context, tx DBTX) (docs []model.JobCount, err error) {
+	query, _, err := goqu.From(goqu.T(pkg.TABLE_JOBS).Schema(pkg.SCHEMA_NAME)).
+		Select("type", "status", goqu.COUNT("*").As("count")).
+		GroupBy("type", "status").
+		Order(goqu.I("type").Asc(), goqu.I("status").Asc()).
+		ToSQL()
+	if err != nil {
+		return
+	}
+
+	if err = tx.SelectContext(ctx, &docs, query); err != nil {
+		err = pkg.NewDatabaseError(err)
+		return
+	}
+	return
+}
+
+// ClaimJobs marks up to limit runnable jobs running for the worker until leaseUntil and counts the attempt,
+// the highest priority first. Runnable jobs are pending jobs that are due and running jobs whose worker let the
+// visibility timeout pass, because it crashed or is stuck.
+func (r *JobRepository) ClaimJobs(ctx context.Context, now time.Time, leaseUntil time.Time, workerID string, limit uint, tx DBTX) (docs []model.Job, err error) {
+	runnable := goqu.From(goqu.T(pkg.TABLE_JOBS).Schema(pkg.SCHEMA_NAME)).
+		Select("id").
+		Where(goqu.Or(
+			goqu.And(
+				goqu.Ex{"status": pkg.JOB_STATUS_PENDING},
+				goqu.I("run_at").Lte(now),
+			),
+			goqu.And(
+				goqu.Ex{"status": pkg.JOB_STATUS_RUNNING},
+				goqu.I("locked_until").Lte(now),
+			),
+		)).
+		Order(goqu.I("priority").Desc(), goqu.I("run_at").Asc()).
+		Limit(limit).
+		ForUpdate(exp.SkipLocked)
+
+	query, _, err := goqu.Update(goqu.T(pkg.TABLE_JOBS).Schema(pkg.SCHEMA_NAME)).
+		Set(goqu.Record{
+			"status":       pkg.JOB_STATUS_RUNNING,
+			"attempts":     goqu.L("attempts + 1"),
+			"locked_until": leaseUntil,
+			"locked_by":    workerID,
+			"started_at":   now,
+		}).
+		Where(goqu.I("id").In(runnable)).
+		Returning("*").
+		ToSQL()
+	if err != nil {
+		return
+	}
+
+	if err = tx.SelectContext(ctx, &docs, query); err != nil {
+		err = pkg.NewDatabaseError(err)
+		return
+	}
+	return
+}
+
+// FinishJob saves the outcome of a run, unless the worker lost the job to another one after its visibility
+// timeout passed, which is reported as not found
+func (r *JobRepository) FinishJob(ctx context.Context, job model.Job, workerID string, tx DBTX) (doc model.Job, err error) {
+	query, _, err := r.updateJobQuery(job, goqu.Ex{"status": pkg.JOB_STATUS_RUNNING}, goqu.Ex{"locked_by": workerID}).ToSQL()
+	if err != nil {
+		return
+	}
+	return returningOne[model.Job](ctx, tx, query, notFoundError("JOB_NOT_FOUND", "job is no longer held by the worker"))
+}
+
+func (r *JobRepository) UpdateJob(ctx context.Context, job model.Job, tx DBTX) (doc model.Job, err error) {
+	query, _, err := r.updateJobQuery(job).ToSQL()
+	if err != nil {
+		return
+	}
+	return returningOne[model.Job](ctx, tx, query, notFoundError("JOB_NOT_FOUND", "job not found"))
+}
+
+func (r *JobRepository) updateJobQuery(job model.Job, where ...exp.Expression) *goqu.UpdateDataset {
+	return goqu.Update(goqu.T(pkg.TABLE_JOBS).Schema(pkg.SCHEMA_NAME)).
+		Set(goqu.Record{
+			"status":       job.Status,
+			"attempts":     job.Attempts,
+			"run_at":       job.RunAt,
+			"locked_until": job.LockedUntil,
+			"locked_by":    job.LockedBy,
+			"last_error":   job.LastError,
+			"finished_at":  job.FinishedAt,
+		}).
+		Where(append([]exp.Expression{goqu.Ex{"id": job.ID}}, where...)...).
+		Returning("*")
+}
+
+// DeleteSucceededJobs removes the jobs that succeeded before finishedBefore, failed jobs are kept to be retried
+func (r *JobRepository) DeleteSucceededJobs(ctx context.Context, finishedBefore time.Time, tx DBTX) (deleted int64, err error) {
+	query, _, err := goqu.Delete(goqu.T(pkg.TABLE_JOBS).Schema(pkg.SCHEMA_NAME)).
+		Where(
+			goqu.Ex{"status": pkg.JOB_STATUS_SUCCEEDED},
+			goqu.I("finished_at").Lt(finishedBefore),
+		).
+		ToSQL()
+	if err != nil {
+		return
+	}
+
+	result, err := tx.ExecContext(ctx, query)
+	if err != nil {
+		err = pkg.NewDatabaseError(err)
+		return
+	}
+	return result.RowsAffected()
+}
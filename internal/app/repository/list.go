@@ -0,0 +1,238 @@
+package repository
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"edukita-teaching-grading/internal/pkg"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx/reflectx"
+)
+
+type filterKind int
+
+const (
+	filterString filterKind = iota
+	filterUUID
+	filterBool
+	// filterPresence matches rows where the column is set for true and NULL for false
+	filterPresence
+)
+
+type (
+	listFilter struct {
+		column string
+		kind   filterKind
+	}
+
+	// listSpec declares what a list endpoint can be sorted and filtered on. Sort fields are named
+	// after the db tag of the scanned struct so cursors can be read back from the last row, and
+	// only columns that are always set may be sorted on for keyset pagination to hold.
+	listSpec struct {
+		sorts       map[string]string
+		filters     map[string]listFilter
+		defaultSort []string
+		// key is the unique column that breaks ties between equal sort values
+		key string
+	}
+
+	listSort struct {
+		field  string
+		column string
+		desc   bool
+	}
+
+	listCursor struct {
+		Sort   string `json:"s"`
+		Values []any  `json:"v"`
+	}
+)
+
+var listMapper = reflectx.NewMapperFunc("db", strings.ToLower)
+
+// selectPage applies the filters, sort and page or cursor of the query to the dataset and returns the rows of
+// the page together with the total number of matching rows
+func selectPage[T any](ctx context.Context, tx DBTX, ds *goqu.SelectDataset, q pkg.ListQuery, spec listSpec) (docs []T, page pkg.ListPage, err error) {
+	page.Page = q.Page
+	page.PerPage = q.PerPage
+
+	ds, err = spec.filter(ds, q.Filters)
+	if err != nil {
+		return
+	}
+
+	query, _, err := ds.ClearOrder().Select(goqu.COUNT("*")).ToSQL()
+	if err != nil {
+		return
+	}
+	if err = tx.GetContext(ctx, &page.TotalCount, query); err != nil {
+		err = pkg.NewDatabaseError(err)
+		return
+	}
+
+	sorts, err := spec.order(q.Sort)
+	if err != nil {
+		return
+	}
+	sortKey := strings.Join(q.Sort, ",")
+	order := make([]exp.OrderedExpression, len(sorts))
+	for i, sort := range sorts {
+		order[i] = goqu.I(sort.column).Asc()
+		if sort.desc {
+			order[i] = goqu.I(sort.column).Desc()
+		}
+	}
+	ds = ds.Order(order...)
+
+	if q.Cursor != "" {
+		values, err := decodeCursor(q.Cursor, sortKey, len(sorts))
+		if err != nil {
+			return nil, page, err
+		}
+		ds = ds.Where(keyset(sorts, values))
+		// pages continued from a cursor have no page number
+		page.Page = 0
+	} else {
+		ds = ds.Offset(uint((q.Page - 1) * q.PerPage))
+	}
+
+	// one extra row tells whether there is a next page
+	query, _, err = ds.Limit(uint(q.PerPage + 1)).ToSQL()
+	if err != nil {
+		return
+	}
+	if err = tx.SelectContext(ctx, &docs, query); err != nil {
+		err = pkg.NewDatabaseError(err)
+		return
+	}
+
+	if len(docs) > q.PerPage {
+		docs = docs[:q.PerPage]
+		page.NextCursor, err = encodeCursor(docs[len(docs)-1], sorts, sortKey)
+	}
+	return
+}
+
+func (s listSpec) filter(ds *goqu.SelectDataset, filters map[string]string) (*goqu.SelectDataset, error) {
+	for name, value := range filters {
+		filter, ok := s.filters[name]
+		if !ok {
+			return nil, pkg.NewBadRequestError(fmt.Sprintf("unknown filter %q", name), nil)
+		}
+
+		switch filter.kind {
+		case filterString:
+			ds = ds.Where(goqu.Ex{filter.column: value})
+		case filterUUID:
+			id, err := uuid.Parse(value)
+			if err != nil {
+				return nil, pkg.NewBadRequestError(fmt.Sprintf("filter %q must be a uuid", name), err)
+			}
+			ds = ds.Where(goqu.Ex{filter.column: id.String()})
+		case filterBool, filterPresence:
+			set, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, pkg.NewBadRequestError(fmt.Sprintf("filter %q must be true or false", name), err)
+			}
+			switch {
+			case filter.kind == filterBool:
+				ds = ds.Where(goqu.Ex{filter.column: set})
+			case set:
+				ds = ds.Where(goqu.I(filter.column).IsNotNull())
+			default:
+				ds = ds.Where(goqu.I(filter.column).IsNull())
+			}
+		}
+	}
+	return ds, nil
+}
+
+// order resolves the requested sort fields, falling back to the default sort, and appends the key as tiebreaker
+func (s listSpec) order(fields []string) ([]listSort, error) {
+	if len(fields) == 0 {
+		fields = s.defaultSort
+	}
+
+	sorts := make([]listSort, 0, len(fields)+1)
+	for _, field := range fields {
+		name, desc := strings.CutPrefix(field, "-")
+		column, ok := s.sorts[name]
+		if !ok {
+			return nil, pkg.NewBadRequestError(fmt.Sprintf("cannot sort by %q", name), nil)
+		}
+		sorts = append(sorts, listSort{field: name, column: column, desc: desc})
+	}
+	field := s.key[strings.LastIndex(s.key, ".")+1:]
+	return append(sorts, listSort{field: field, column: s.key}), nil
+}
+
+// keyset matches the rows that come after the cursor values in the sort order
+func keyset(sorts []listSort, values []any) exp.Expression {
+	after := make([]exp.Expression, len(sorts))
+	for i, sort := range sorts {
+		conditions := make([]exp.Expression, 0, i+1)
+		for j := range i {
+			conditions = append(conditions, goqu.I(sorts[j].column).Eq(values[j]))
+		}
+		if sort.desc {
+			conditions = append(conditions, goqu.I(sort.column).Lt(values[i]))
+		} else {
+			conditions = append(conditions, goqu.I(sort.column).Gt(values[i]))
+		}
+		after[i] = goqu.And(conditions...)
+	}
+	return goqu.Or(after...)
+}
+
+func encodeCursor[T any](last T, sorts []listSort, sortKey string) (string, error) {
+	row := reflect.Indirect(reflect.ValueOf(last))
+	cursor := listCursor{Sort: sortKey, Values: make([]any, len(sorts))}
+	// the fields are looked up by hand, the mapper returns the row for a missing field and allocates nil pointers
+	fields := listMapper.TypeMap(row.Type()).Names
+	for i, sort := range sorts {
+		field, ok := fields[sort.field]
+		if !ok {
+			return "", fmt.Errorf("cursor field %q not found on %T", sort.field, last)
+		}
+		value, err := row.FieldByIndexErr(field.Index)
+		if err != nil {
+			return "", err
+		}
+		if value.Kind() == reflect.Pointer {
+			if value.IsNil() {
+				continue
+			}
+			value = value.Elem()
+		}
+		cursor.Values[i] = value.Interface()
+	}
+
+	raw, err := json.Marshal(cursor)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+func decodeCursor(token string, sortKey string, size int) ([]any, error) {
+	var cursor listCursor
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err == nil {
+		err = json.Unmarshal(raw, &cursor)
+	}
+	if err != nil || len(cursor.Values) != size {
+		return nil, pkg.NewBadRequestError("invalid cursor", err)
+	}
+	if cursor.Sort != sortKey {
+		return nil, pkg.NewBadRequestError("cursor was issued for another sort", nil)
+	}
+	return cursor.Values, nil
+}
@@ -0,0 +1,230 @@
+package repository
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"edukita-teaching-grading/internal/pkg"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/google/uuid"
+)
+
+type listTestRow struct {
+	ID          uuid.UUID `db:"id"`
+	Title       string    `db:"title"`
+	TotalPoints float64   `db:"total_points"`
+	GradedAt    *string   `db:"graded_at"`
+}
+
+var listTestSpec = listSpec{
+	sorts: map[string]string{
+		"title":        "a.title",
+		"total_points": "a.total_points",
+	},
+	filters: map[string]listFilter{
+		"status":    {column: "a.status", kind: filterString},
+		"course_id": {column: "a.course_id", kind: filterUUID},
+		"published": {column: "a.is_published", kind: filterBool},
+		"graded":    {column: "a.graded_at", kind: filterPresence},
+	},
+	defaultSort: []string{"-total_points"},
+	key:         "a.id",
+}
+
+// fakeListTx answers the count with total and each select with the next of pages, recording the queries
+type fakeListTx struct {
+	DBTX
+	total   int
+	pages   [][]listTestRow
+	queries []string
+}
+
+func (tx *fakeListTx) GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	*dest.(*int) = tx.total
+	return nil
+}
+
+func (tx *fakeListTx) SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	tx.queries = append(tx.queries, query)
+	*dest.(*[]listTestRow) = tx.pages[0]
+	tx.pages = tx.pages[1:]
+	return nil
+}
+
+func isBadRequest(err error) bool {
+	var appErr *pkg.AppError
+	return errors.As(err, &appErr) && appErr.StatusCode == http.StatusBadRequest
+}
+
+func TestListSpecOrder(t *testing.T) {
+	tests := []struct {
+		name    string
+		fields  []string
+		want    []listSort
+		wantErr bool
+	}{
+		{"default sort", nil, []listSort{{field: "total_points", column: "a.total_points", desc: true}, {field: "id", column: "a.id"}}, false},
+		{"ascending and descending", []string{"title", "-total_points"}, []listSort{
+			{field: "title", column: "a.title"},
+			{field: "total_points", column: "a.total_points", desc: true},
+			{field: "id", column: "a.id"},
+		}, false},
+		{"unknown field", []string{"title", "-created_at"}, nil, true},
+		// the key breaks ties, it is not a sort field of its own
+		{"key", []string{"id"}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := listTestSpec.order(tt.fields)
+			if tt.wantErr {
+				if !isBadRequest(err) {
+					t.Errorf("order() error = %v, want a bad request", err)
+				}
+				return
+			}
+			if err != nil || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("order() = %+v, %v, want %+v", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestListSpecFilter(t *testing.T) {
+	courseID := uuid.New()
+	tests := []struct {
+		name    string
+		filters map[string]string
+		want    string
+		wantErr bool
+	}{
+		{"string", map[string]string{"status": "open"}, `("a"."status" = 'open')`, false},
+		{"uuid", map[string]string{"course_id": courseID.String()}, `("a"."course_id" = '` + courseID.String() + `')`, false},
+		{"bool", map[string]string{"published": "false"}, `("a"."is_published" IS FALSE)`, false},
+		{"present", map[string]string{"graded": "true"}, `("a"."graded_at" IS NOT NULL)`, false},
+		{"absent", map[string]string{"graded": "0"}, `("a"."graded_at" IS NULL)`, false},
+		{"unknown filter", map[string]string{"owner_id": courseID.String()}, "", true},
+		{"malformed uuid", map[string]string{"course_id": "c1"}, "", true},
+		{"malformed bool", map[string]string{"published": "yes"}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ds, err := listTestSpec.filter(goqu.From("a"), tt.filters)
+			if tt.wantErr {
+				if !isBadRequest(err) {
+					t.Errorf("filter() error = %v, want a bad request", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("filter() unexpected error: %v", err)
+			}
+			query, _, _ := ds.ToSQL()
+			if !strings.Contains(query, tt.want) {
+				t.Errorf("query %q does not contain %q", query, tt.want)
+			}
+		})
+	}
+}
+
+func TestListCursor(t *testing.T) {
+	sorts, _ := listTestSpec.order([]string{"-total_points", "title"})
+	row := listTestRow{ID: uuid.New(), Title: "Cells", TotalPoints: 42.5}
+
+	cursor, err := encodeCursor(row, sorts, "-total_points,title")
+	if err != nil {
+		t.Fatalf("encodeCursor() unexpected error: %v", err)
+	}
+	values, err := decodeCursor(cursor, "-total_points,title", len(sorts))
+	if want := []any{42.5, "Cells", row.ID.String()}; err != nil || !reflect.DeepEqual(values, want) {
+		t.Errorf("decodeCursor() = %v, %v, want %v", values, err, want)
+	}
+
+	// a nil pointer is carried as a null value
+	graded, _ := encodeCursor(&row, []listSort{{field: "graded_at"}}, "graded_at")
+	if values, err := decodeCursor(graded, "graded_at", 1); err != nil || values[0] != nil || row.GradedAt != nil {
+		t.Errorf("decodeCursor() = %v, %v, want a null value", values, err)
+	}
+	if _, err := encodeCursor(row, []listSort{{field: "created_at"}}, "created_at"); err == nil {
+		t.Error("encodeCursor() of a field the row does not have succeeded")
+	}
+
+	encode := func(raw string) string { return base64.RawURLEncoding.EncodeToString([]byte(raw)) }
+	tampered := map[string]string{
+		"not base64":      "!" + cursor,
+		"not json":        encode(`{"s":"-total_points,title","v":[42.5,"Cells"`),
+		"too few values":  encode(`{"s":"-total_points,title","v":[42.5,"Cells"]}`),
+		"too many values": encode(`{"s":"-total_points,title","v":[42.5,"Cells","x","y"]}`),
+		"no values":       encode(`{"s":"-total_points,title"}`),
+		"another sort":    encode(`{"s":"title","v":[42.5,"Cells","x"]}`),
+	}
+	for name, token := range tampered {
+		if _, err := decodeCursor(token, "-total_points,title", len(sorts)); !isBadRequest(err) {
+			t.Errorf("%s: decodeCursor() error = %v, want a bad request", name, err)
+		}
+	}
+}
+
+func TestListKeyset(t *testing.T) {
+	sorts, _ := listTestSpec.order([]string{"-total_points", "title"})
+	query, _, err := goqu.From("a").Where(keyset(sorts, []any{40.0, "Cells", "k"})).ToSQL()
+	if err != nil {
+		t.Fatalf("building query: %v", err)
+	}
+
+	// every row after the cursor in the sort order, rows tied on the leading fields are told apart by the next one
+	want := `WHERE (("a"."total_points" < 40) OR (("a"."total_points" = 40) AND ("a"."title" > 'Cells')) OR ` +
+		`(("a"."total_points" = 40) AND ("a"."title" = 'Cells') AND ("a"."id" > 'k')))`
+	if !strings.HasSuffix(query, want) {
+		t.Errorf("query %q does not end with %q", query, want)
+	}
+}
+
+func TestSelectPage(t *testing.T) {
+	// three of the rows are tied on the sort field, the key orders them
+	rows := make([]listTestRow, 5)
+	for i, points := range []float64{50, 40, 40, 40, 30} {
+		rows[i] = listTestRow{ID: uuid.MustParse("00000000-0000-0000-0000-00000000000" + string(rune('1'+i))), TotalPoints: points}
+	}
+	tx := &fakeListTx{total: len(rows), pages: [][]listTestRow{rows[0:3], rows[2:5], rows[4:5]}}
+
+	q := pkg.ListQuery{Page: 1, PerPage: 2}
+	var got []listTestRow
+	for i := 0; ; i++ {
+		docs, page, err := selectPage[listTestRow](context.Background(), tx, goqu.From(goqu.T("assignments").As("a")), q, listTestSpec)
+		if err != nil {
+			t.Fatalf("page %d: selectPage() unexpected error: %v", i, err)
+		}
+		if page.TotalCount != len(rows) || page.PerPage != 2 || (i == 0) != (page.Page == 1) {
+			t.Errorf("page %d: got %+v", i, page)
+		}
+		got = append(got, docs...)
+		if page.NextCursor == "" {
+			break
+		}
+		q.Cursor = page.NextCursor
+	}
+	if !reflect.DeepEqual(got, rows) {
+		t.Errorf("walked %v, want %v", got, rows)
+	}
+
+	wants := []string{
+		`FROM "assignments" AS "a" ORDER BY "a"."total_points" DESC, "a"."id" ASC LIMIT 3`,
+		// the second page starts after the second row, inside the run of tied rows
+		`(("a"."total_points" = 40) AND ("a"."id" > '` + rows[1].ID.String() + `'))) ORDER BY "a"."total_points" DESC, "a"."id" ASC LIMIT 3`,
+		`(("a"."total_points" = 40) AND ("a"."id" > '` + rows[3].ID.String() + `'))) ORDER BY "a"."total_points" DESC, "a"."id" ASC LIMIT 3`,
+	}
+	for i, want := range wants {
+		if !strings.Contains(tx.queries[i], want) {
+			t.Errorf("query %d %q does not contain %q", i, tx.queries[i], want)
+		}
+	}
+	if strings.Contains(tx.queries[1], "OFFSET") {
+		t.Errorf("query %q continued from a cursor has an offset", tx.queries[1])
+	}
+}
@@ -11,28 +11,74 @@ import (
 	"edukita-teaching-grading/internal/pkg"
 
 	"github.com/doug-martin/goqu/v9"
-	"github.com/jmoiron/sqlx"
+	"github.com/google/uuid"
 )
 
 type (
 	ILearningManagementRepository interface {
-		CreateCourse(ctx context.Context, course model.Course, tx *sqlx.Tx) (doc model.Course, err error)
-		GetCourseByID(ctx context.Context, id string, tx *sqlx.Tx) (doc model.Course, err error)
-		GetCourseByCode(ctx context.Context, code string, tx *sqlx.Tx) (doc model.Course, err error)
-		GetAllCourses(ctx context.Context, tx *sqlx.Tx) (docs []model.Course, err error)
-		UpdateCourseByID(ctx context.Context, course model.Course, tx *sqlx.Tx) (doc model.Course, err error)
-		DeleteCourseByID(ctx context.Context, id string, tx *sqlx.Tx) (doc model.Course, err error)
-
-		CreateAssignment(ctx context.Context, assignment model.Assignment, tx *sqlx.Tx) (doc model.Assignment, err error)
-		GetAssignmentByID(ctx context.Context, id string, tx *sqlx.Tx) (doc model.Assignment, err error)
-		GetAssignmentByTeacherID(ctx context.Context, id string, tx *sqlx.Tx) (doc model.Assignment, err error)
-		GetAllAssignmentsByCourseID(ctx context.Context, id string, tx *sqlx.Tx) (docs []model.Assignment, err error)
-		UpdateAssignmentByID(ctx context.Context, assignment model.Assignment, tx *sqlx.Tx) (doc model.Assignment, err error)
-
-		CreateSubmission(ctx context.Context, submission model.Submission, tx *sqlx.Tx) (doc model.Submission, err error)
-		GetSubmissionByID(ctx context.Context, id string, tx *sqlx.Tx) (doc model.Submission, err error)
-		GetAllSubmissionsByAssignmentID(ctx context.Context, id string, tx *sqlx.Tx) (docs []model.Submission, err error)
-		UpdateSubmissionByID(ctx context.Context, submission model.Submission, tx *sqlx.Tx) (doc model.Submission, err error)
+		CreateCourse(ctx context.Context, course model.Course, tx DBTX) (doc model.Course, err error)
+		GetCourseByID(ctx context.Context, id string, tx DBTX) (doc model.Course, err error)
+		GetCourseByCode(ctx context.Context, code string, tx DBTX) (doc model.Course, err error)
+		ListCourses(ctx context.Context, q pkg.ListQuery, tx DBTX) (docs []model.Course, page pkg.ListPage, err error)
+		UpdateCourseByID(ctx context.Context, course model.Course, tx DBTX) (doc model.Course, err error)
+		DeleteCourseByID(ctx context.Context, id string, deletedBy uuid.UUID, deletedAt time.Time, tx DBTX) (doc model.Course, err error)
+		GetDeletedCourseByID(ctx context.Context, id string, tx DBTX) (doc model.Course, err error)
+		RestoreCourseByID(ctx context.Context, id string, restoredBy uuid.UUID, tx DBTX) (doc model.Course, err error)
+		PurgeCourseByID(ctx context.Context, id string, tx DBTX) (doc model.Course, err error)
+
+		CreateCourseTeacher(ctx context.Context, courseTeacher model.CourseTeacher, tx DBTX) (doc model.CourseTeacher, err error)
+		GetAllCourseTeachersByCourseID(ctx context.Context, courseID string, tx DBTX) (docs []model.CourseTeacher, err error)
+		ListCourseTeachersByCourseID(ctx context.Context, courseID string, q pkg.ListQuery, tx DBTX) (docs []model.CourseTeacher, page pkg.ListPage, err error)
+		DeleteCourseTeacher(ctx context.Context, courseID string, teacherID string, tx DBTX) (doc model.CourseTeacher, err error)
+		GetAllCourseIDsByTeacherID(ctx context.Context, teacherID string, tx DBTX) (ids []uuid.UUID, err error)
+		GetAllEndedActiveCourses(ctx context.Context, endedBefore time.Time, tx DBTX) (docs []model.Course, err error)
+
+		CreateAssignment(ctx context.Context, assignment model.Assignment, tx DBTX) (doc model.Assignment, err error)
+		GetAssignmentByID(ctx context.Context, id string, tx DBTX) (doc model.Assignment, err error)
+		GetAssignmentByTeacherID(ctx context.Context, id string, tx DBTX) (doc model.Assignment, err error)
+		GetAllAssignmentsByCourseID(ctx context.Context, id string, tx DBTX) (docs []model.Assignment, err error)
+		ListAssignmentsByCourseID(ctx context.Context, courseID string, q pkg.ListQuery, tx DBTX) (docs []model.Assignment, page pkg.ListPage, err error)
+		UpdateAssignmentByID(ctx context.Context, assignment model.Assignment, tx DBTX) (doc model.Assignment, err error)
+		DeleteAssignmentByID(ctx context.Context, id string, deletedBy uuid.UUID, deletedAt time.Time, tx DBTX) (doc model.Assignment, err error)
+		DeleteAssignmentsByCourseID(ctx context.Context, courseID string, deletedBy uuid.UUID, deletedAt time.Time, tx DBTX) (deleted int64, err error)
+		GetDeletedAssignmentByID(ctx context.Context, id string, tx DBTX) (doc model.Assignment, err error)
+		RestoreAssignmentByID(ctx context.Context, id string, restoredBy uuid.UUID, tx DBTX) (doc model.Assignment, err error)
+		RestoreAssignmentsByCourseID(ctx context.Context, courseID string, deletedAt time.Time, restoredBy uuid.UUID, tx DBTX) (restored int64, err error)
+		PurgeAssignmentByID(ctx context.Context, id string, tx DBTX) (doc model.Assignment, err error)
+
+		CreateSubmission(ctx context.Context, submission model.Submission, tx DBTX) (doc model.Submission, err error)
+		GetSubmissionByID(ctx context.Context, id string, tx DBTX) (doc model.Submission, err error)
+		GetSubmissionByIDForUpdate(ctx context.Context, id string, tx DBTX) (doc model.Submission, err error)
+		GetAllSubmissionsByAssignmentID(ctx context.Context, id string, tx DBTX) (docs []model.Submission, err error)
+		GetAllSubmissionsByCourseID(ctx context.Context, courseID string, assignmentIDs []uuid.UUID, tx DBTX) (docs []model.Submission, err error)
+		ListSubmissionsByAssignmentID(ctx context.Context, assignmentID string, q pkg.ListQuery, tx DBTX) (docs []model.Submission, page pkg.ListPage, err error)
+		ListSubmissionsByStudentID(ctx context.Context, studentID string, q pkg.ListQuery, tx DBTX) (docs []model.Submission, page pkg.ListPage, err error)
+		ListSubmissionsByTeacherID(ctx context.Context, teacherID string, q pkg.ListQuery, tx DBTX) (docs []model.Submission, page pkg.ListPage, err error)
+		UpdateSubmissionByID(ctx context.Context, submission model.Submission, tx DBTX) (doc model.Submission, err error)
+		GetSubmissionByAssignmentAndStudentID(ctx context.Context, assignmentID string, studentID string, tx DBTX) (doc model.Submission, err error)
+		DeleteSubmissionByID(ctx context.Context, id string, deletedBy uuid.UUID, deletedAt time.Time, tx DBTX) (doc model.Submission, err error)
+		DeleteSubmissionsByAssignmentID(ctx context.Context, assignmentID string, deletedBy uuid.UUID, deletedAt time.Time, tx DBTX) (deleted int64, err error)
+		DeleteSubmissionsByCourseID(ctx context.Context, courseID string, deletedBy uuid.UUID, deletedAt time.Time, tx DBTX) (deleted int64, err error)
+		GetDeletedSubmissionByID(ctx context.Context, id string, tx DBTX) (doc model.Submission, err error)
+		RestoreSubmissionByID(ctx context.Context, id string, restoredBy uuid.UUID, tx DBTX) (doc model.Submission, err error)
+		RestoreSubmissionsByAssignmentID(ctx context.Context, assignmentID string, deletedAt time.Time, restoredBy uuid.UUID, tx DBTX) (restored int64, err error)
+		RestoreSubmissionsByCourseID(ctx context.Context, courseID string, deletedAt time.Time, restoredBy uuid.UUID, tx DBTX) (restored int64, err error)
+		PurgeSubmissionByID(ctx context.Context, id string, tx DBTX) (doc model.Submission, err error)
+
+		CreateSubmissionAttempt(ctx context.Context, attempt model.SubmissionAttempt, tx DBTX) (doc model.SubmissionAttempt, err error)
+		GetSubmissionAttemptByID(ctx context.Context, id string, tx DBTX) (doc model.SubmissionAttempt, err error)
+		GetSubmissionAttemptByNumber(ctx context.Context, submissionID string, attemptNumber int, tx DBTX) (doc model.SubmissionAttempt, err error)
+		ListSubmissionAttemptsBySubmissionID(ctx context.Context, submissionID string, q pkg.ListQuery, tx DBTX) (docs []model.SubmissionAttempt, page pkg.ListPage, err error)
+
+		CreateEnrollment(ctx context.Context, enrollment model.Enrollment, tx DBTX) (doc model.Enrollment, err error)
+		GetEnrollmentByCourseAndStudentID(ctx context.Context, courseID string, studentID string, tx DBTX) (doc model.Enrollment, err error)
+		GetAllEnrollmentsByCourseID(ctx context.Context, courseID string, tx DBTX) (docs []model.EnrollmentRoster, err error)
+		GetAllDueReminders(ctx context.Context, dueAfter time.Time, dueBy time.Time, tx DBTX) (docs []model.DueReminder, err error)
+		ListEnrollmentsByCourseID(ctx context.Context, courseID string, q pkg.ListQuery, tx DBTX) (docs []model.EnrollmentRoster, page pkg.ListPage, err error)
+		GetAllEnrollmentsByStudentID(ctx context.Context, studentID string, tx DBTX) (docs []model.Enrollment, err error)
+		UpdateEnrollmentByID(ctx context.Context, enrollment model.Enrollment, tx DBTX) (doc model.Enrollment, err error)
+		DeleteEnrollmentsByCourseID(ctx context.Context, courseID string, deletedBy uuid.UUID, deletedAt time.Time, tx DBTX) (deleted int64, err error)
+		RestoreEnrollmentsByCourseID(ctx context.Context, courseID string, deletedAt time.Time, restoredBy uuid.UUID, tx DBTX) (restored int64, err error)
 	}
 	LearningManagementRepository struct {
 		RepositoryOption
@@ -45,7 +91,7 @@ func InitiateLearningManagementRepository(opt RepositoryOption) ILearningManagem
 	}
 }
 
-func (r *LearningManagementRepository) CreateCourse(ctx context.Context, course model.Course, tx *sqlx.Tx) (doc model.Course, err error) {
+func (r *LearningManagementRepository) CreateCourse(ctx context.Context, course model.Course, tx DBTX) (doc model.Course, err error) {
 	query, _, err := goqu.Insert(fmt.Sprintf("%s.%s", pkg.SCHEMA_NAME, pkg.TABLE_COURSES)).
 		Rows(course).
 		Returning("*").
@@ -61,12 +107,13 @@ func (r *LearningManagementRepository) CreateCourse(ctx context.Context, course
 	return
 }
 
-func (r *LearningManagementRepository) GetCourseByID(ctx context.Context, id string, tx *sqlx.Tx) (doc model.Course, err error) {
+func (r *LearningManagementRepository) GetCourseByID(ctx context.Context, id string, tx DBTX) (doc model.Course, err error) {
 	query, _, err := goqu.Select("*").
 		From(fmt.Sprintf("%s.%s", pkg.SCHEMA_NAME, pkg.TABLE_COURSES)).
 		Where(
 			goqu.Ex{"id": id},
 			goqu.Ex{"is_active": true},
+			goqu.Ex{"deleted_at": nil},
 		).
 		ToSQL()
 	if err != nil {
@@ -91,12 +138,13 @@ func (r *LearningManagementRepository) GetCourseByID(ctx context.Context, id str
 	return
 }
 
-func (r *LearningManagementRepository) GetCourseByCode(ctx context.Context, code string, tx *sqlx.Tx) (doc model.Course, err error) {
+func (r *LearningManagementRepository) GetCourseByCode(ctx context.Context, code string, tx DBTX) (doc model.Course, err error) {
 	query, _, err := goqu.Select("*").
 		From(fmt.Sprintf("%s.%s", pkg.SCHEMA_NAME, pkg.TABLE_COURSES)).
 		Where(
 			goqu.Ex{"code": code},
 			goqu.Ex{"is_active": true},
+			goqu.Ex{"deleted_at": nil},
 		).
 		ToSQL()
 	if err != nil {
@@ -120,36 +168,89 @@ func (r *LearningManagementRepository) GetCourseByCode(ctx context.Context, code
 	return
 }
 
-func (r *LearningManagementRepository) GetAllCourses(ctx context.Context, tx *sqlx.Tx) (docs []model.Course, err error) {
-	query, _, err := goqu.Select("*").
-		From(fmt.Sprintf("%s.%s", pkg.SCHEMA_NAME, pkg.TABLE_COURSES)).
-		Order(goqu.I("name").Asc()).
+var courseListSpec = listSpec{
+	sorts: map[string]string{
+		"name":       "name",
+		"code":       "code",
+		"start_date": "start_date",
+		"end_date":   "end_date",
+		"created_at": "created_at",
+	},
+	filters: map[string]listFilter{
+		"is_active": {column: "is_active", kind: filterBool},
+		"code":      {column: "code", kind: filterString},
+	},
+	defaultSort: []string{"name"},
+	key:         "id",
+}
+
+func (r *LearningManagementRepository) ListCourses(ctx context.Context, q pkg.ListQuery, tx DBTX) (docs []model.Course, page pkg.ListPage, err error) {
+	ds := goqu.From(goqu.T(pkg.TABLE_COURSES).Schema(pkg.SCHEMA_NAME)).
+		Where(goqu.Ex{"deleted_at": nil})
+	return selectPage[model.Course](ctx, tx, ds, q, courseListSpec)
+}
+
+func (r *LearningManagementRepository) UpdateCourseByID(ctx context.Context, course model.Course, tx DBTX) (doc model.Course, err error) {
+	query, _, err := goqu.From(fmt.Sprintf("%s.%s", pkg.SCHEMA_NAME, pkg.TABLE_COURSES)).
+		Update().
+		Set(course).
+		Where(goqu.Ex{"id": course.ID}).
+		Returning("*").
 		ToSQL()
 	if err != nil {
 		return
 	}
 
-	rows, err := tx.QueryxContext(ctx, query)
+	if err = tx.QueryRowxContext(ctx, query).StructScan(&doc); err != nil {
+		err = pkg.NewDatabaseError(err)
+		return
+	}
+	return
+}
+
+func (r *LearningManagementRepository) DeleteCourseByID(ctx context.Context, id string, deletedBy uuid.UUID, deletedAt time.Time, tx DBTX) (doc model.Course, err error) {
+	query, _, err := softDeleteQuery(pkg.TABLE_COURSES, goqu.Ex{"id": id}, deletedBy, deletedAt).
+		Returning("*").
+		ToSQL()
+	if err != nil {
+		return
+	}
+	return returningOne[model.Course](ctx, tx, query, notFoundError("COURSE_NOT_FOUND", "course not found"))
+}
+
+func (r *LearningManagementRepository) GetDeletedCourseByID(ctx context.Context, id string, tx DBTX) (doc model.Course, err error) {
+	query, _, err := deletedQuery(pkg.TABLE_COURSES, id).ToSQL()
 	if err != nil {
 		return
 	}
+	return returningOne[model.Course](ctx, tx, query, notFoundError("DELETED_COURSE_NOT_FOUND", "deleted course not found"))
+}
 
-	for rows.Next() {
-		row := model.Course{}
-		if err = rows.StructScan(&row); err != nil {
-			return
-		}
+func (r *LearningManagementRepository) RestoreCourseByID(ctx context.Context, id string, restoredBy uuid.UUID, tx DBTX) (doc model.Course, err error) {
+	query, _, err := restoreQuery(pkg.TABLE_COURSES, goqu.Ex{"id": id}, restoredBy).
+		Returning("*").
+		ToSQL()
+	if err != nil {
+		return
+	}
+	return returningOne[model.Course](ctx, tx, query, notFoundError("DELETED_COURSE_NOT_FOUND", "deleted course not found"))
+}
 
-		docs = append(docs, row)
+// PurgeCourseByID permanently removes a soft-deleted course, the database cascades to everything in the course
+func (r *LearningManagementRepository) PurgeCourseByID(ctx context.Context, id string, tx DBTX) (doc model.Course, err error) {
+	query, _, err := purgeQuery(pkg.TABLE_COURSES, goqu.Ex{"id": id}).
+		Returning("*").
+		ToSQL()
+	if err != nil {
+		return
 	}
-	return
+	return returningOne[model.Course](ctx, tx, query, notFoundError("DELETED_COURSE_NOT_FOUND", "deleted course not found"))
 }
 
-func (r *LearningManagementRepository) UpdateCourseByID(ctx context.Context, course model.Course, tx *sqlx.Tx) (doc model.Course, err error) {
-	query, _, err := goqu.From(fmt.Sprintf("%s.%s", pkg.SCHEMA_NAME, pkg.TABLE_COURSES)).
-		Update().
-		Set(course).
-		Where(goqu.Ex{"id": course.ID}).
+func (r *LearningManagementRepository) CreateCourseTeacher(ctx context.Context, courseTeacher model.CourseTeacher, tx DBTX) (doc model.CourseTeacher, err error) {
+	query, _, err := goqu.Insert(fmt.Sprintf("%s.%s", pkg.SCHEMA_NAME, pkg.TABLE_COURSE_TEACHERS)).
+		Rows(courseTeacher).
+		OnConflict(goqu.DoNothing()).
 		Returning("*").
 		ToSQL()
 	if err != nil {
@@ -157,17 +258,90 @@ func (r *LearningManagementRepository) UpdateCourseByID(ctx context.Context, cou
 	}
 
 	if err = tx.QueryRowxContext(ctx, query).StructScan(&doc); err != nil {
+		if err == sql.ErrNoRows {
+			err = pkg.NewBadRequestError("teacher already assigned to this course", nil)
+		} else {
+			err = pkg.NewDatabaseError(err)
+		}
+		return
+	}
+	return
+}
+
+func (r *LearningManagementRepository) GetAllCourseTeachersByCourseID(ctx context.Context, courseID string, tx DBTX) (docs []model.CourseTeacher, err error) {
+	query, _, err := goqu.Select("*").
+		From(fmt.Sprintf("%s.%s", pkg.SCHEMA_NAME, pkg.TABLE_COURSE_TEACHERS)).
+		Where(goqu.Ex{"course_id": courseID}).
+		Order(goqu.I("created_at").Asc()).
+		ToSQL()
+	if err != nil {
+		return
+	}
+
+	if err = tx.SelectContext(ctx, &docs, query); err != nil {
 		err = pkg.NewDatabaseError(err)
 		return
 	}
 	return
 }
 
-func (r *LearningManagementRepository) DeleteCourseByID(ctx context.Context, id string, tx *sqlx.Tx) (doc model.Course, err error) {
-	query, _, err := goqu.From(fmt.Sprintf("%s.%s", pkg.SCHEMA_NAME, pkg.TABLE_COURSES)).
-		Update().
-		Set(goqu.Record{"deleted_at": time.Now()}).
-		Where(goqu.Ex{"id": id}).
+// GetAllCourseIDsByTeacherID returns the active courses the teacher created or co-teaches
+func (r *LearningManagementRepository) GetAllCourseIDsByTeacherID(ctx context.Context, teacherID string, tx DBTX) (ids []uuid.UUID, err error) {
+	coTaught := goqu.From(fmt.Sprintf("%s.%s", pkg.SCHEMA_NAME, pkg.TABLE_COURSE_TEACHERS)).
+		Select("course_id").
+		Where(goqu.Ex{"teacher_id": teacherID})
+
+	query, _, err := goqu.Select("id").
+		From(fmt.Sprintf("%s.%s", pkg.SCHEMA_NAME, pkg.TABLE_COURSES)).
+		Where(
+			goqu.Or(
+				goqu.Ex{"created_by": teacherID},
+				goqu.I("id").In(coTaught),
+			),
+			goqu.Ex{"is_active": true},
+			goqu.Ex{"deleted_at": nil},
+		).
+		Order(goqu.I("created_at").Asc()).
+		ToSQL()
+	if err != nil {
+		return
+	}
+
+	if err = tx.SelectContext(ctx, &ids, query); err != nil {
+		err = pkg.NewDatabaseError(err)
+		return
+	}
+	return
+}
+
+// GetAllEndedActiveCourses locks and returns the active courses that ended before endedBefore
+func (r *LearningManagementRepository) GetAllEndedActiveCourses(ctx context.Context, endedBefore time.Time, tx DBTX) (docs []model.Course, err error) {
+	query, _, err := goqu.From(goqu.T(pkg.TABLE_COURSES).Schema(pkg.SCHEMA_NAME)).
+		Where(
+			goqu.Ex{"is_active": true},
+			goqu.Ex{"deleted_at": nil},
+			goqu.I("end_date").Lt(endedBefore),
+		).
+		Order(goqu.I("end_date").Asc()).
+		ForUpdate(goqu.Wait).
+		ToSQL()
+	if err != nil {
+		return
+	}
+
+	if err = tx.SelectContext(ctx, &docs, query); err != nil {
+		err = pkg.NewDatabaseError(err)
+		return
+	}
+	return
+}
+
+func (r *LearningManagementRepository) DeleteCourseTeacher(ctx context.Context, courseID string, teacherID string, tx DBTX) (doc model.CourseTeacher, err error) {
+	query, _, err := goqu.Delete(fmt.Sprintf("%s.%s", pkg.SCHEMA_NAME, pkg.TABLE_COURSE_TEACHERS)).
+		Where(
+			goqu.Ex{"course_id": courseID},
+			goqu.Ex{"teacher_id": teacherID},
+		).
 		Returning("*").
 		ToSQL()
 	if err != nil {
@@ -175,13 +349,22 @@ func (r *LearningManagementRepository) DeleteCourseByID(ctx context.Context, id
 	}
 
 	if err = tx.QueryRowxContext(ctx, query).StructScan(&doc); err != nil {
-		err = pkg.NewDatabaseError(err)
+		if err == sql.ErrNoRows {
+			err = &pkg.AppError{
+				Code:       "COURSE_TEACHER_NOT_FOUND",
+				Message:    "course teacher not found",
+				StatusCode: http.StatusNotFound,
+				Err:        fmt.Errorf("course teacher not found"),
+			}
+		} else {
+			err = pkg.NewDatabaseError(err)
+		}
 		return
 	}
 	return
 }
 
-func (r *LearningManagementRepository) CreateAssignment(ctx context.Context, assignment model.Assignment, tx *sqlx.Tx) (doc model.Assignment, err error) {
+func (r *LearningManagementRepository) CreateAssignment(ctx context.Context, assignment model.Assignment, tx DBTX) (doc model.Assignment, err error) {
 	query, _, err := goqu.Insert(fmt.Sprintf("%s.%s", pkg.SCHEMA_NAME, pkg.TABLE_ASSIGNMENTS)).
 		Rows(assignment).
 		Returning("*").
@@ -197,11 +380,12 @@ func (r *LearningManagementRepository) CreateAssignment(ctx context.Context, ass
 	return
 }
 
-func (r *LearningManagementRepository) GetAssignmentByID(ctx context.Context, id string, tx *sqlx.Tx) (doc model.Assignment, err error) {
+func (r *LearningManagementRepository) GetAssignmentByID(ctx context.Context, id string, tx DBTX) (doc model.Assignment, err error) {
 	query, _, err := goqu.Select("*").
 		From(fmt.Sprintf("%s.%s", pkg.SCHEMA_NAME, pkg.TABLE_ASSIGNMENTS)).
 		Where(
 			goqu.Ex{"id": id},
+			goqu.Ex{"deleted_at": nil},
 		).
 		ToSQL()
 	if err != nil {
@@ -226,12 +410,13 @@ func (r *LearningManagementRepository) GetAssignmentByID(ctx context.Context, id
 	return
 }
 
-func (r *LearningManagementRepository) GetAssignmentByTeacherID(ctx context.Context, id string, tx *sqlx.Tx) (doc model.Assignment, err error) {
+func (r *LearningManagementRepository) GetAssignmentByTeacherID(ctx context.Context, id string, tx DBTX) (doc model.Assignment, err error) {
 	query, _, err := goqu.Select("*").
 		From(fmt.Sprintf("%s.%s", pkg.SCHEMA_NAME, pkg.TABLE_ASSIGNMENTS)).
 		Where(
 			goqu.Ex{"teacher_id": id},
 			goqu.Ex{"is_active": true},
+			goqu.Ex{"deleted_at": nil},
 		).
 		ToSQL()
 	if err != nil {
@@ -255,11 +440,12 @@ func (r *LearningManagementRepository) GetAssignmentByTeacherID(ctx context.Cont
 	return
 }
 
-func (r *LearningManagementRepository) GetAllAssignmentsByCourseID(ctx context.Context, id string, tx *sqlx.Tx) (docs []model.Assignment, err error) {
+func (r *LearningManagementRepository) GetAllAssignmentsByCourseID(ctx context.Context, id string, tx DBTX) (docs []model.Assignment, err error) {
 	query, _, err := goqu.Select("*").
 		From(fmt.Sprintf("%s.%s", pkg.SCHEMA_NAME, pkg.TABLE_ASSIGNMENTS)).
 		Where(
 			goqu.Ex{"course_id": id},
+			goqu.Ex{"deleted_at": nil},
 		).
 		ToSQL()
 	if err != nil {
@@ -282,7 +468,7 @@ func (r *LearningManagementRepository) GetAllAssignmentsByCourseID(ctx context.C
 	return
 }
 
-func (r *LearningManagementRepository) UpdateAssignmentByID(ctx context.Context, assignment model.Assignment, tx *sqlx.Tx) (doc model.Assignment, err error) {
+func (r *LearningManagementRepository) UpdateAssignmentByID(ctx context.Context, assignment model.Assignment, tx DBTX) (doc model.Assignment, err error) {
 	query, _, err := goqu.From(fmt.Sprintf("%s.%s", pkg.SCHEMA_NAME, pkg.TABLE_ASSIGNMENTS)).
 		Update().
 		Set(assignment).
@@ -300,7 +486,62 @@ func (r *LearningManagementRepository) UpdateAssignmentByID(ctx context.Context,
 	return
 }
 
-func (r *LearningManagementRepository) CreateSubmission(ctx context.Context, submission model.Submission, tx *sqlx.Tx) (doc model.Submission, err error) {
+func (r *LearningManagementRepository) DeleteAssignmentByID(ctx context.Context, id string, deletedBy uuid.UUID, deletedAt time.Time, tx DBTX) (doc model.Assignment, err error) {
+	query, _, err := softDeleteQuery(pkg.TABLE_ASSIGNMENTS, goqu.Ex{"id": id}, deletedBy, deletedAt).
+		Returning("*").
+		ToSQL()
+	if err != nil {
+		return
+	}
+	return returningOne[model.Assignment](ctx, tx, query, notFoundError("ASSIGNMENT_NOT_FOUND", "assignment not found"))
+}
+
+func (r *LearningManagementRepository) DeleteAssignmentsByCourseID(ctx context.Context, courseID string, deletedBy uuid.UUID, deletedAt time.Time, tx DBTX) (deleted int64, err error) {
+	query, _, err := softDeleteQuery(pkg.TABLE_ASSIGNMENTS, goqu.Ex{"course_id": courseID}, deletedBy, deletedAt).ToSQL()
+	if err != nil {
+		return
+	}
+	return execCount(ctx, tx, query)
+}
+
+func (r *LearningManagementRepository) GetDeletedAssignmentByID(ctx context.Context, id string, tx DBTX) (doc model.Assignment, err error) {
+	query, _, err := deletedQuery(pkg.TABLE_ASSIGNMENTS, id).ToSQL()
+	if err != nil {
+		return
+	}
+	return returningOne[model.Assignment](ctx, tx, query, notFoundError("DELETED_ASSIGNMENT_NOT_FOUND", "deleted assignment not found"))
+}
+
+func (r *LearningManagementRepository) RestoreAssignmentByID(ctx context.Context, id string, restoredBy uuid.UUID, tx DBTX) (doc model.Assignment, err error) {
+	query, _, err := restoreQuery(pkg.TABLE_ASSIGNMENTS, goqu.Ex{"id": id}, restoredBy).
+		Returning("*").
+		ToSQL()
+	if err != nil {
+		return
+	}
+	return returningOne[model.Assignment](ctx, tx, query, notFoundError("DELETED_ASSIGNMENT_NOT_FOUND", "deleted assignment not found"))
+}
+
+// RestoreAssignmentsByCourseID restores the assignments that were deleted together with the course at deletedAt
+func (r *LearningManagementRepository) RestoreAssignmentsByCourseID(ctx context.Context, courseID string, deletedAt time.Time, restoredBy uuid.UUID, tx DBTX) (restored int64, err error) {
+	query, _, err := restoreQuery(pkg.TABLE_ASSIGNMENTS, goqu.Ex{"course_id": courseID, "deleted_at": deletedAt}, restoredBy).ToSQL()
+	if err != nil {
+		return
+	}
+	return execCount(ctx, tx, query)
+}
+
+func (r *LearningManagementRepository) PurgeAssignmentByID(ctx context.Context, id string, tx DBTX) (doc model.Assignment, err error) {
+	query, _, err := purgeQuery(pkg.TABLE_ASSIGNMENTS, goqu.Ex{"id": id}).
+		Returning("*").
+		ToSQL()
+	if err != nil {
+		return
+	}
+	return returningOne[model.Assignment](ctx, tx, query, notFoundError("DELETED_ASSIGNMENT_NOT_FOUND", "deleted assignment not found"))
+}
+
+func (r *LearningManagementRepository) CreateSubmission(ctx context.Context, submission model.Submission, tx DBTX) (doc model.Submission, err error) {
 	query, _, err := goqu.Insert(fmt.Sprintf("%s.%s", pkg.SCHEMA_NAME, pkg.TABLE_SUBMISSIONS)).
 		Rows(submission).
 		Returning("*").
@@ -315,13 +556,27 @@ func (r *LearningManagementRepository) CreateSubmission(ctx context.Context, sub
 	}
 	return
 }
-func (r *LearningManagementRepository) GetSubmissionByID(ctx context.Context, id string, tx *sqlx.Tx) (doc model.Submission, err error) {
-	query, _, err := goqu.Select("*").
+func (r *LearningManagementRepository) GetSubmissionByID(ctx context.Context, id string, tx DBTX) (doc model.Submission, err error) {
+	return r.getSubmission(ctx, submissionByIDQuery(id), tx)
+}
+
+// GetSubmissionByIDForUpdate locks the submission until the transaction ends, so its attempts are numbered one
+// after the other
+func (r *LearningManagementRepository) GetSubmissionByIDForUpdate(ctx context.Context, id string, tx DBTX) (doc model.Submission, err error) {
+	return r.getSubmission(ctx, submissionByIDQuery(id).ForUpdate(goqu.Wait), tx)
+}
+
+func submissionByIDQuery(id string) *goqu.SelectDataset {
+	return goqu.Select("*").
 		From(fmt.Sprintf("%s.%s", pkg.SCHEMA_NAME, pkg.TABLE_SUBMISSIONS)).
 		Where(
 			goqu.Ex{"id": id},
-		).
-		ToSQL()
+			goqu.Ex{"deleted_at": nil},
+		)
+}
+
+func (r *LearningManagementRepository) getSubmission(ctx context.Context, ds *goqu.SelectDataset, tx DBTX) (doc model.Submission, err error) {
+	query, _, err := ds.ToSQL()
 	if err != nil {
 		return
 	}
@@ -343,11 +598,12 @@ func (r *LearningManagementRepository) GetSubmissionByID(ctx context.Context, id
 	return
 }
 
-func (r *LearningManagementRepository) GetAllSubmissionsByAssignmentID(ctx context.Context, id string, tx *sqlx.Tx) (docs []model.Submission, err error) {
+func (r *LearningManagementRepository) GetAllSubmissionsByAssignmentID(ctx context.Context, id string, tx DBTX) (docs []model.Submission, err error) {
 	query, _, err := goqu.Select("*").
 		From(fmt.Sprintf("%s.%s", pkg.SCHEMA_NAME, pkg.TABLE_SUBMISSIONS)).
 		Where(
 			goqu.Ex{"assignment_id": id},
+			goqu.Ex{"deleted_at": nil},
 		).
 		ToSQL()
 	if err != nil {
@@ -371,7 +627,7 @@ func (r *LearningManagementRepository) GetAllSubmissionsByAssignmentID(ctx conte
 	return
 }
 
-func (r *LearningManagementRepository) UpdateSubmissionByID(ctx context.Context, submission model.Submission, tx *sqlx.Tx) (doc model.Submission, err error) {
+func (r *LearningManagementRepository) UpdateSubmissionByID(ctx context.Context, submission model.Submission, tx DBTX) (doc model.Submission, err error) {
 	query, _, err := goqu.From(fmt.Sprintf("%s.%s", pkg.SCHEMA_NAME, pkg.TABLE_SUBMISSIONS)).
 		Update().
 		Set(submission).
@@ -388,3 +644,550 @@ func (r *LearningManagementRepository) UpdateSubmissionByID(ctx context.Context,
 	}
 	return
 }
+
+func (r *LearningManagementRepository) DeleteSubmissionByID(ctx context.Context, id string, deletedBy uuid.UUID, deletedAt time.Time, tx DBTX) (doc model.Submission, err error) {
+	query, _, err := softDeleteQuery(pkg.TABLE_SUBMISSIONS, goqu.Ex{"id": id}, deletedBy, deletedAt).
+		Returning("*").
+		ToSQL()
+	if err != nil {
+		return
+	}
+	return returningOne[model.Submission](ctx, tx, query, notFoundError("SUBMISSION_NOT_FOUND", "submission not found"))
+}
+
+func (r *LearningManagementRepository) DeleteSubmissionsByAssignmentID(ctx context.Context, assignmentID string, deletedBy uuid.UUID, deletedAt time.Time, tx DBTX) (deleted int64, err error) {
+	query, _, err := softDeleteQuery(pkg.TABLE_SUBMISSIONS, goqu.Ex{"assignment_id": assignmentID}, deletedBy, deletedAt).ToSQL()
+	if err != nil {
+		return
+	}
+	return execCount(ctx, tx, query)
+}
+
+func (r *LearningManagementRepository) DeleteSubmissionsByCourseID(ctx context.Context, courseID string, deletedBy uuid.UUID, deletedAt time.Time, tx DBTX) (deleted int64, err error) {
+	query, _, err := softDeleteQuery(pkg.TABLE_SUBMISSIONS, goqu.Ex{"assignment_id": assignmentsOfCourse(courseID)}, deletedBy, deletedAt).ToSQL()
+	if err != nil {
+		return
+	}
+	return execCount(ctx, tx, query)
+}
+
+func (r *LearningManagementRepository) GetDeletedSubmissionByID(ctx context.Context, id string, tx DBTX) (doc model.Submission, err error) {
+	query, _, err := deletedQuery(pkg.TABLE_SUBMISSIONS, id).ToSQL()
+	if err != nil {
+		return
+	}
+	return returningOne[model.Submission](ctx, tx, query, notFoundError("DELETED_SUBMISSION_NOT_FOUND", "deleted submission not found"))
+}
+
+func (r *LearningManagementRepository) RestoreSubmissionByID(ctx context.Context, id string, restoredBy uuid.UUID, tx DBTX) (doc model.Submission, err error) {
+	query, _, err := restoreQuery(pkg.TABLE_SUBMISSIONS, goqu.Ex{"id": id}, restoredBy).
+		Returning("*").
+		ToSQL()
+	if err != nil {
+		return
+	}
+	return returningOne[model.Submission](ctx, tx, query, notFoundError("DELETED_SUBMISSION_NOT_FOUND", "deleted submission not found"))
+}
+
+// RestoreSubmissionsByAssignmentID restores the submissions that were deleted together with the assignment at deletedAt
+func (r *LearningManagementRepository) RestoreSubmissionsByAssignmentID(ctx context.Context, assignmentID string, deletedAt time.Time, restoredBy uuid.UUID, tx DBTX) (restored int64, err error) {
+	query, _, err := restoreQuery(pkg.TABLE_SUBMISSIONS, goqu.Ex{"assignment_id": assignmentID, "deleted_at": deletedAt}, restoredBy).ToSQL()
+	if err != nil {
+		return
+	}
+	return execCount(ctx, tx, query)
+}
+
+// RestoreSubmissionsByCourseID restores the submissions that were deleted together with the course at deletedAt
+func (r *LearningManagementRepository) RestoreSubmissionsByCourseID(ctx context.Context, courseID string, deletedAt time.Time, restoredBy uuid.UUID, tx DBTX) (restored int64, err error) {
+	query, _, err := restoreQuery(pkg.TABLE_SUBMISSIONS, goqu.Ex{"assignment_id": assignmentsOfCourse(courseID), "deleted_at": deletedAt}, restoredBy).ToSQL()
+	if err != nil {
+		return
+	}
+	return execCount(ctx, tx, query)
+}
+
+func (r *LearningManagementRepository) PurgeSubmissionByID(ctx context.Context, id string, tx DBTX) (doc model.Submission, err error) {
+	query, _, err := purgeQuery(pkg.TABLE_SUBMISSIONS, goqu.Ex{"id": id}).
+		Returning("*").
+		ToSQL()
+	if err != nil {
+		return
+	}
+	return returningOne[model.Submission](ctx, tx, query, notFoundError("DELETED_SUBMISSION_NOT_FOUND", "deleted submission not found"))
+}
+
+// assignmentsOfCourse selects the ids of every assignment of the course, deleted or not
+func assignmentsOfCourse(courseID string) *goqu.SelectDataset {
+	return goqu.From(goqu.T(pkg.TABLE_ASSIGNMENTS).Schema(pkg.SCHEMA_NAME)).
+		Select("id").
+		Where(goqu.Ex{"course_id": courseID})
+}
+
+func (r *LearningManagementRepository) GetSubmissionByAssignmentAndStudentID(ctx context.Context, assignmentID string, studentID string, tx DBTX) (doc model.Submission, err error) {
+	query, _, err := goqu.Select("*").
+		From(fmt.Sprintf("%s.%s", pkg.SCHEMA_NAME, pkg.TABLE_SUBMISSIONS)).
+		Where(
+			goqu.Ex{"assignment_id": assignmentID},
+			goqu.Ex{"student_id": studentID},
+			goqu.Ex{"deleted_at": nil},
+		).
+		ToSQL()
+	if err != nil {
+		return
+	}
+
+	if err = tx.GetContext(ctx, &doc, query); err != nil {
+		if err == sql.ErrNoRows {
+			err = &pkg.AppError{
+				Code:       "SUBMISSION_NOT_FOUND",
+				Message:    "submission not found",
+				StatusCode: http.StatusNotFound,
+				Err:        fmt.Errorf("submission not found"),
+			}
+		} else {
+			err = pkg.NewDatabaseError(err)
+			return
+		}
+		return
+	}
+	return
+}
+
+func (r *LearningManagementRepository) CreateSubmissionAttempt(ctx context.Context, attempt model.SubmissionAttempt, tx DBTX) (doc model.SubmissionAttempt, err error) {
+	query, _, err := goqu.Insert(fmt.Sprintf("%s.%s", pkg.SCHEMA_NAME, pkg.TABLE_ATTEMPTS)).
+		Rows(attempt).
+		Returning("*").
+		ToSQL()
+	if err != nil {
+		return
+	}
+
+	if err = tx.QueryRowxContext(ctx, query).StructScan(&doc); err != nil {
+		err = pkg.NewDatabaseError(err)
+		return
+	}
+	return
+}
+
+func (r *LearningManagementRepository) GetSubmissionAttemptByID(ctx context.Context, id string, tx DBTX) (doc model.SubmissionAttempt, err error) {
+	query, _, err := goqu.Select("*").
+		From(fmt.Sprintf("%s.%s", pkg.SCHEMA_NAME, pkg.TABLE_ATTEMPTS)).
+		Where(goqu.Ex{"id": id}).
+		ToSQL()
+	if err != nil {
+		return
+	}
+
+	if err = tx.GetContext(ctx, &doc, query); err != nil {
+		if err == sql.ErrNoRows {
+			err = &pkg.AppError{
+				Code:       "SUBMISSION_ATTEMPT_NOT_FOUND",
+				Message:    "submission attempt not found",
+				StatusCode: http.StatusNotFound,
+				Err:        fmt.Errorf("submission attempt not found"),
+			}
+		} else {
+			err = pkg.NewDatabaseError(err)
+			return
+		}
+		return
+	}
+	return
+}
+
+func (r *LearningManagementRepository) GetSubmissionAttemptByNumber(ctx context.Context, submissionID string, attemptNumber int, tx DBTX) (doc model.SubmissionAttempt, err error) {
+	query, _, err := goqu.Select("*").
+		From(fmt.Sprintf("%s.%s", pkg.SCHEMA_NAME, pkg.TABLE_ATTEMPTS)).
+		Where(
+			goqu.Ex{"submission_id": submissionID},
+			goqu.Ex{"attempt_number": attemptNumber},
+		).
+		ToSQL()
+	if err != nil {
+		return
+	}
+
+	if err = tx.GetContext(ctx, &doc, query); err != nil {
+		if err == sql.ErrNoRows {
+			err = &pkg.AppError{
+				Code:       "SUBMISSION_ATTEMPT_NOT_FOUND",
+				Message:    "submission attempt not found",
+				StatusCode: http.StatusNotFound,
+				Err:        fmt.Errorf("submission attempt not found"),
+			}
+		} else {
+			err = pkg.NewDatabaseError(err)
+			return
+		}
+		return
+	}
+	return
+}
+
+var attemptListSpec = listSpec{
+	sorts: map[string]string{
+		"attempt_number": "attempt_number",
+		"submitted_at":   "submitted_at",
+	},
+	filters: map[string]listFilter{
+		"is_late": {column: "is_late", kind: filterBool},
+	},
+	defaultSort: []string{"attempt_number"},
+	key:         "id",
+}
+
+func (r *LearningManagementRepository) ListSubmissionAttemptsBySubmissionID(ctx context.Context, submissionID string, q pkg.ListQuery, tx DBTX) (docs []model.SubmissionAttempt, page pkg.ListPage, err error) {
+	ds := goqu.From(goqu.T(pkg.TABLE_ATTEMPTS).Schema(pkg.SCHEMA_NAME)).
+		Where(goqu.Ex{"submission_id": submissionID})
+	return selectPage[model.SubmissionAttempt](ctx, tx, ds, q, attemptListSpec)
+}
+
+func (r *LearningManagementRepository) CreateEnrollment(ctx context.Context, enrollment model.Enrollment, tx DBTX) (doc model.Enrollment, err error) {
+	query, _, err := goqu.Insert(fmt.Sprintf("%s.%s", pkg.SCHEMA_NAME, pkg.TABLE_ENROLLMENTS)).
+		Rows(enrollment).
+		Returning("*").
+		ToSQL()
+	if err != nil {
+		return
+	}
+
+	if err = tx.QueryRowxContext(ctx, query).StructScan(&doc); err != nil {
+		err = pkg.NewDatabaseError(err)
+		return
+	}
+	return
+}
+
+func (r *LearningManagementRepository) GetEnrollmentByCourseAndStudentID(ctx context.Context, courseID string, studentID string, tx DBTX) (doc model.Enrollment, err error) {
+	query, _, err := goqu.Select("*").
+		From(fmt.Sprintf("%s.%s", pkg.SCHEMA_NAME, pkg.TABLE_ENROLLMENTS)).
+		Where(
+			goqu.Ex{"course_id": courseID},
+			goqu.Ex{"student_id": studentID},
+			goqu.Ex{"deleted_at": nil},
+		).
+		ToSQL()
+	if err != nil {
+		return
+	}
+
+	if err = tx.GetContext(ctx, &doc, query); err != nil {
+		if err == sql.ErrNoRows {
+			err = &pkg.AppError{
+				Code:       "ENROLLMENT_NOT_FOUND",
+				Message:    "enrollment not found",
+				StatusCode: http.StatusNotFound,
+				Err:        fmt.Errorf("enrollment not found"),
+			}
+		} else {
+			err = pkg.NewDatabaseError(err)
+			return
+		}
+		return
+	}
+	return
+}
+
+func (r *LearningManagementRepository) GetAllEnrollmentsByCourseID(ctx context.Context, courseID string, tx DBTX) (docs []model.EnrollmentRoster, err error) {
+	query, _, err := goqu.Select(
+		goqu.I("e.id"),
+		goqu.I("e.course_id"),
+		goqu.I("e.student_id"),
+		goqu.I("s.student_id").As("student_number"),
+		goqu.I("u.first_name"),
+		goqu.I("u.last_name"),
+		goqu.I("u.email"),
+		goqu.I("e.status"),
+		goqu.I("e.enrolled_at"),
+		goqu.I("e.dropped_at"),
+	).
+		From(goqu.T(pkg.TABLE_ENROLLMENTS).Schema(pkg.SCHEMA_NAME).As("e")).
+		InnerJoin(goqu.T(pkg.TABLE_STUDENTS).Schema(pkg.SCHEMA_NAME).As("s"), goqu.On(goqu.Ex{"s.user_id": goqu.I("e.student_id")})).
+		InnerJoin(goqu.T(pkg.TABLE_USERS).Schema(pkg.SCHEMA_NAME).As("u"), goqu.On(goqu.Ex{"u.id": goqu.I("e.student_id")})).
+		Where(
+			goqu.Ex{"e.course_id": courseID},
+			goqu.Ex{"e.deleted_at": nil},
+			goqu.Ex{"u.deleted_at": nil},
+		).
+		Order(goqu.I("u.last_name").Asc(), goqu.I("u.first_name").Asc()).
+		ToSQL()
+	if err != nil {
+		return
+	}
+
+	if err = tx.SelectContext(ctx, &docs, query); err != nil {
+		err = pkg.NewDatabaseError(err)
+		return
+	}
+	return
+}
+
+// GetAllDueReminders returns every active student of an active course paired with each published assignment of
+// the course that is due after dueAfter and by dueBy and that the student has not submitted
+func (r *LearningManagementRepository) GetAllDueReminders(ctx context.Context, dueAfter time.Time, dueBy time.Time, tx DBTX) (docs []model.DueReminder, err error) {
+	query, _, err := goqu.Select(
+		goqu.I("a.id").As("assignment_id"),
+		goqu.I("a.title").As("assignment_title"),
+		goqu.I("a.due_date"),
+		goqu.I("c.id").As("course_id"),
+		goqu.I("c.name").As("course_name"),
+		goqu.I("e.student_id"),
+		goqu.I("u.first_name"),
+		goqu.I("u.email"),
+	).
+		From(goqu.T(pkg.TABLE_ASSIGNMENTS).Schema(pkg.SCHEMA_NAME).As("a")).
+		InnerJoin(goqu.T(pkg.TABLE_COURSES).Schema(pkg.SCHEMA_NAME).As("c"), goqu.On(goqu.Ex{"c.id": goqu.I("a.course_id")})).
+		InnerJoin(goqu.T(pkg.TABLE_ENROLLMENTS).Schema(pkg.SCHEMA_NAME).As("e"), goqu.On(goqu.Ex{"e.course_id": goqu.I("c.id")})).
+		InnerJoin(goqu.T(pkg.TABLE_USERS).Schema(pkg.SCHEMA_NAME).As("u"), goqu.On(goqu.Ex{"u.id": goqu.I("e.student_id")})).
+		LeftJoin(goqu.T(pkg.TABLE_SUBMISSIONS).Schema(pkg.SCHEMA_NAME).As("s"), goqu.On(
+			goqu.Ex{"s.assignment_id": goqu.I("a.id")},
+			goqu.Ex{"s.student_id": goqu.I("e.student_id")},
+			goqu.Ex{"s.deleted_at": nil},
+		)).
+		Where(
+			goqu.Ex{"a.is_published": true},
+			goqu.Ex{"a.deleted_at": nil},
+			goqu.I("a.due_date").Gt(dueAfter),
+			goqu.I("a.due_date").Lte(dueBy),
+			goqu.Ex{"c.is_active": true},
+			goqu.Ex{"c.deleted_at": nil},
+			goqu.Ex{"e.status": pkg.ENROLLMENT_STATUS_ACTIVE},
+			goqu.Ex{"e.deleted_at": nil},
+			goqu.Ex{"u.deleted_at": nil},
+			goqu.Ex{"s.id": nil},
+		).
+		Order(goqu.I("a.due_date").Asc(), goqu.I("a.id").Asc()).
+		ToSQL()
+	if err != nil {
+		return
+	}
+
+	if err = tx.SelectContext(ctx, &docs, query); err != nil {
+		err = pkg.NewDatabaseError(err)
+		return
+	}
+	return
+}
+
+func (r *LearningManagementRepository) GetAllEnrollmentsByStudentID(ctx context.Context, studentID string, tx DBTX) (docs []model.Enrollment, err error) {
+	query, _, err := goqu.Select("*").
+		From(fmt.Sprintf("%s.%s", pkg.SCHEMA_NAME, pkg.TABLE_ENROLLMENTS)).
+		Where(
+			goqu.Ex{"student_id": studentID},
+			goqu.Ex{"deleted_at": nil},
+		).
+		Order(goqu.I("enrolled_at").Asc()).
+		ToSQL()
+	if err != nil {
+		return
+	}
+
+	if err = tx.SelectContext(ctx, &docs, query); err != nil {
+		err = pkg.NewDatabaseError(err)
+		return
+	}
+	return
+}
+
+func (r *LearningManagementRepository) UpdateEnrollmentByID(ctx context.Context, enrollment model.Enrollment, tx DBTX) (doc model.Enrollment, err error) {
+	query, _, err := goqu.From(fmt.Sprintf("%s.%s", pkg.SCHEMA_NAME, pkg.TABLE_ENROLLMENTS)).
+		Update().
+		Set(enrollment).
+		Where(goqu.Ex{"id": enrollment.ID}).
+		Returning("*").
+		ToSQL()
+	if err != nil {
+		return
+	}
+
+	if err = tx.QueryRowxContext(ctx, query).StructScan(&doc); err != nil {
+		err = pkg.NewDatabaseError(err)
+		return
+	}
+	return
+}
+
+func (r *LearningManagementRepository) DeleteEnrollmentsByCourseID(ctx context.Context, courseID string, deletedBy uuid.UUID, deletedAt time.Time, tx DBTX) (deleted int64, err error) {
+	query, _, err := softDeleteQuery(pkg.TABLE_ENROLLMENTS, goqu.Ex{"course_id": courseID}, deletedBy, deletedAt).ToSQL()
+	if err != nil {
+		return
+	}
+	return execCount(ctx, tx, query)
+}
+
+// RestoreEnrollmentsByCourseID restores the enrollments that were deleted together with the course at deletedAt
+func (r *LearningManagementRepository) RestoreEnrollmentsByCourseID(ctx context.Context, courseID string, deletedAt time.Time, restoredBy uuid.UUID, tx DBTX) (restored int64, err error) {
+	query, _, err := restoreQuery(pkg.TABLE_ENROLLMENTS, goqu.Ex{"course_id": courseID, "deleted_at": deletedAt}, restoredBy).ToSQL()
+	if err != nil {
+		return
+	}
+	return execCount(ctx, tx, query)
+}
+
+var courseTeacherListSpec = listSpec{
+	sorts: map[string]string{
+		"created_at": "created_at",
+	},
+	defaultSort: []string{"created_at"},
+	key:         "teacher_id",
+}
+
+func (r *LearningManagementRepository) ListCourseTeachersByCourseID(ctx context.Context, courseID string, q pkg.ListQuery, tx DBTX) (docs []model.CourseTeacher, page pkg.ListPage, err error) {
+	ds := goqu.From(goqu.T(pkg.TABLE_COURSE_TEACHERS).Schema(pkg.SCHEMA_NAME)).
+		Where(goqu.Ex{"course_id": courseID})
+	return selectPage[model.CourseTeacher](ctx, tx, ds, q, courseTeacherListSpec)
+}
+
+var assignmentListSpec = listSpec{
+	sorts: map[string]string{
+		"title":      "title",
+		"due_date":   "due_date",
+		"created_at": "created_at",
+	},
+	filters: map[string]listFilter{
+		"is_published": {column: "is_published", kind: filterBool},
+		"category_id":  {column: "category_id", kind: filterUUID},
+	},
+	defaultSort: []string{"due_date"},
+	key:         "id",
+}
+
+func (r *LearningManagementRepository) ListAssignmentsByCourseID(ctx context.Context, courseID string, q pkg.ListQuery, tx DBTX) (docs []model.Assignment, page pkg.ListPage, err error) {
+	ds := goqu.From(goqu.T(pkg.TABLE_ASSIGNMENTS).Schema(pkg.SCHEMA_NAME)).
+		Where(
+			goqu.Ex{"course_id": courseID},
+			goqu.Ex{"deleted_at": nil},
+		)
+	return selectPage[model.Assignment](ctx, tx, ds, q, assignmentListSpec)
+}
+
+// GetAllSubmissionsByCourseID returns the submissions of the course in one joined query, ordered by assignment.
+// When assignmentIDs is not empty only the submissions of those assignments are returned.
+func (r *LearningManagementRepository) GetAllSubmissionsByCourseID(ctx context.Context, courseID string, assignmentIDs []uuid.UUID, tx DBTX) (docs []model.Submission, err error) {
+	query, err := submissionsByCourseQuery(courseID, assignmentIDs)
+	if err != nil {
+		return
+	}
+
+	if err = tx.SelectContext(ctx, &docs, query); err != nil {
+		err = pkg.NewDatabaseError(err)
+		return
+	}
+	return
+}
+
+func submissionsByCourseQuery(courseID string, assignmentIDs []uuid.UUID) (string, error) {
+	ds := goqu.Select(goqu.T("s").All()).
+		From(goqu.T(pkg.TABLE_SUBMISSIONS).Schema(pkg.SCHEMA_NAME).As("s")).
+		InnerJoin(goqu.T(pkg.TABLE_ASSIGNMENTS).Schema(pkg.SCHEMA_NAME).As("a"), goqu.On(goqu.Ex{"a.id": goqu.I("s.assignment_id")})).
+		Where(
+			goqu.Ex{"a.course_id": courseID},
+			goqu.Ex{"a.deleted_at": nil},
+			goqu.Ex{"s.deleted_at": nil},
+		).
+		Order(goqu.I("s.assignment_id").Asc(), goqu.I("s.submitted_at").Asc())
+	if len(assignmentIDs) > 0 {
+		ids := make([]string, len(assignmentIDs))
+		for i, id := range assignmentIDs {
+			ids[i] = id.String()
+		}
+		ds = ds.Where(goqu.Ex{"s.assignment_id": ids})
+	}
+
+	query, _, err := ds.ToSQL()
+	return query, err
+}
+
+var submissionListSpec = listSpec{
+	sorts: map[string]string{
+		"submitted_at":  "submitted_at",
+		"created_at":    "created_at",
+		"attempt_count": "attempt_count",
+	},
+	filters: map[string]listFilter{
+		"graded":        {column: "grade", kind: filterPresence},
+		"is_late":       {column: "is_late", kind: filterBool},
+		"student_id":    {column: "student_id", kind: filterUUID},
+		"assignment_id": {column: "assignment_id", kind: filterUUID},
+	},
+	defaultSort: []string{"-submitted_at"},
+	key:         "id",
+}
+
+func (r *LearningManagementRepository) ListSubmissionsByAssignmentID(ctx context.Context, assignmentID string, q pkg.ListQuery, tx DBTX) (docs []model.Submission, page pkg.ListPage, err error) {
+	ds := goqu.From(goqu.T(pkg.TABLE_SUBMISSIONS).Schema(pkg.SCHEMA_NAME)).
+		Where(
+			goqu.Ex{"assignment_id": assignmentID},
+			goqu.Ex{"deleted_at": nil},
+		)
+	return selectPage[model.Submission](ctx, tx, ds, q, submissionListSpec)
+}
+
+func (r *LearningManagementRepository) ListSubmissionsByStudentID(ctx context.Context, studentID string, q pkg.ListQuery, tx DBTX) (docs []model.Submission, page pkg.ListPage, err error) {
+	ds := goqu.From(goqu.T(pkg.TABLE_SUBMISSIONS).Schema(pkg.SCHEMA_NAME)).
+		Where(
+			goqu.Ex{"student_id": studentID},
+			goqu.Ex{"deleted_at": nil},
+		)
+	return selectPage[model.Submission](ctx, tx, ds, q, submissionListSpec)
+}
+
+// ListSubmissionsByTeacherID lists the submissions of the assignments the teacher set or of the courses they teach
+func (r *LearningManagementRepository) ListSubmissionsByTeacherID(ctx context.Context, teacherID string, q pkg.ListQuery, tx DBTX) (docs []model.Submission, page pkg.ListPage, err error) {
+	courses := goqu.From(goqu.T(pkg.TABLE_COURSE_TEACHERS).Schema(pkg.SCHEMA_NAME)).
+		Select("course_id").
+		Where(goqu.Ex{"teacher_id": teacherID})
+	assignments := goqu.From(goqu.T(pkg.TABLE_ASSIGNMENTS).Schema(pkg.SCHEMA_NAME)).
+		Select("id").
+		Where(
+			goqu.Or(
+				goqu.Ex{"teacher_id": teacherID},
+				goqu.Ex{"course_id": courses},
+			),
+			goqu.Ex{"deleted_at": nil},
+		)
+	ds := goqu.From(goqu.T(pkg.TABLE_SUBMISSIONS).Schema(pkg.SCHEMA_NAME)).
+		Where(
+			goqu.Ex{"assignment_id": assignments},
+			goqu.Ex{"deleted_at": nil},
+		)
+	return selectPage[model.Submission](ctx, tx, ds, q, submissionListSpec)
+}
+
+var enrollmentListSpec = listSpec{
+	sorts: map[string]string{
+		"last_name":   "u.last_name",
+		"first_name":  "u.first_name",
+		"enrolled_at": "e.enrolled_at",
+	},
+	filters: map[string]listFilter{
+		"status": {column: "e.status", kind: filterString},
+	},
+	defaultSort: []string{"last_name", "first_name"},
+	key:         "e.id",
+}
+
+func (r *LearningManagementRepository) ListEnrollmentsByCourseID(ctx context.Context, courseID string, q pkg.ListQuery, tx DBTX) (docs []model.EnrollmentRoster, page pkg.ListPage, err error) {
+	ds := goqu.Select(
+		goqu.I("e.id"),
+		goqu.I("e.course_id"),
+		goqu.I("e.student_id"),
+		goqu.I("s.student_id").As("student_number"),
+		goqu.I("u.first_name"),
+		goqu.I("u.last_name"),
+		goqu.I("u.email"),
+		goqu.I("e.status"),
+		goqu.I("e.enrolled_at"),
+		goqu.I("e.dropped_at"),
+	).
+		From(goqu.T(pkg.TABLE_ENROLLMENTS).Schema(pkg.SCHEMA_NAME).As("e")).
+		InnerJoin(goqu.T(pkg.TABLE_STUDENTS).Schema(pkg.SCHEMA_NAME).As("s"), goqu.On(goqu.Ex{"s.user_id": goqu.I("e.student_id")})).
+		InnerJoin(goqu.T(pkg.TABLE_USERS).Schema(pkg.SCHEMA_NAME).As("u"), goqu.On(goqu.Ex{"u.id": goqu.I("e.student_id")})).
+		Where(
+			goqu.Ex{"e.course_id": courseID},
+			goqu.Ex{"e.deleted_at": nil},
+			goqu.Ex{"u.deleted_at": nil},
+		)
+	return selectPage[model.EnrollmentRoster](ctx, tx, ds, q, enrollmentListSpec)
+}
@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestSubmissionsByCourseQuery(t *testing.T) {
+	courseID := uuid.New().String()
+	essay, quiz := uuid.New(), uuid.New()
+
+	query, err := submissionsByCourseQuery(courseID, []uuid.UUID{essay, quiz})
+	if err != nil {
+		t.Fatalf("building query: %v", err)
+	}
+
+	for _, part := range []string{
+		`INNER JOIN "public"."assignments" AS "a" ON ("a"."id" = "s"."assignment_id")`,
+		`("a"."course_id" = '` + courseID + `')`,
+		`("a"."deleted_at" IS NULL)`,
+		`("s"."deleted_at" IS NULL)`,
+		`("s"."assignment_id" IN ('` + essay.String() + `', '` + quiz.String() + `'))`,
+		`ORDER BY "s"."assignment_id" ASC`,
+	} {
+		if !strings.Contains(query, part) {
+			t.Errorf("query %q does not contain %q", query, part)
+		}
+	}
+	if strings.Count(query, "SELECT") != 1 {
+		t.Errorf("query %q should be a single select", query)
+	}
+
+	query, err = submissionsByCourseQuery(courseID, nil)
+	if err != nil {
+		t.Fatalf("building query: %v", err)
+	}
+	if strings.Contains(query, " IN ") {
+		t.Errorf("query %q should cover every assignment of the course", query)
+	}
+}
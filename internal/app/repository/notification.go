@@ -0,0 +1,201 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"edukita-teaching-grading/internal/app/model"
+	"edukita-teaching-grading/internal/pkg"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/google/uuid"
+)
+
+type (
+	INotificationRepository interface {
+		CreateNotifications(ctx context.Context, notifications []model.Notification, tx DBTX) (created int64, err error)
+		ListNotificationsByUserID(ctx context.Context, userID string, q pkg.ListQuery, tx DBTX) (docs []model.Notification, page pkg.ListPage, err error)
+		CountUnreadNotificationsByUserID(ctx context.Context, userID string, tx DBTX) (count int64, err error)
+		MarkNotificationRead(ctx context.Context, id string, userID string, readAt time.Time, tx DBTX) (doc model.Notification, err error)
+		MarkAllNotificationsRead(ctx context.Context, userID string, readAt time.Time, tx DBTX) (updated int64, err error)
+
+		GetAllNotificationPreferencesByUserID(ctx context.Context, userID string, tx DBTX) (docs []model.NotificationPreference, err error)
+		GetAllNotificationPreferencesByType(ctx context.Context, notificationType string, userIDs []uuid.UUID, tx DBTX) (docs []model.NotificationPreference, err error)
+		UpsertNotificationPreference(ctx context.Context, preference model.NotificationPreference, tx DBTX) (doc model.NotificationPreference, err error)
+	}
+	NotificationRepository struct {
+		RepositoryOption
+	}
+)
+
+func InitiateNotificationRepository(opt RepositoryOption) INotificationRepository {
+	return &NotificationRepository{
+		RepositoryOption: opt,
+	}
+}
+
+// CreateNotifications inserts the notifications in one statement, a user already notified of the same event
+// is skipped
+func (r *NotificationRepository) CreateNotifications(ctx context.Context, notifications []model.Notification, tx DBTX) (created int64, err error) {
+	if len(notifications) == 0 {
+		return
+	}
+
+	query, _, err := goqu.Insert(goqu.T(pkg.TABLE_NOTIFICATIONS).Schema(pkg.SCHEMA_NAME)).
+		Rows(notifications).
+		OnConflict(goqu.DoNothing()).
+		ToSQL()
+	if err != nil {
+		return
+	}
+
+	result, err := tx.ExecContext(ctx, query)
+	if err != nil {
+		err = pkg.NewDatabaseError(err)
+		return
+	}
+	return result.RowsAffected()
+}
+
+var notificationListSpec = listSpec{
+	sorts: map[string]string{
+		"created_at": "created_at",
+	},
+	filters: map[string]listFilter{
+		"type":    {column: "type", kind: filterString},
+		"is_read": {column: "read_at", kind: filterPresence},
+	},
+	defaultSort: []string{"-created_at"},
+	key:         "id",
+}
+
+func (r *NotificationRepository) ListNotificationsByUserID(ctx context.Context, userID string, q pkg.ListQuery, tx DBTX) (docs []model.Notification, page pkg.ListPage, err error) {
+	ds := goqu.From(goqu.T(pkg.TABLE_NOTIFICATIONS).Schema(pkg.SCHEMA_NAME)).
+		Where(goqu.Ex{"user_id": userID})
+	return selectPage[model.Notification](ctx, tx, ds, q, notificationListSpec)
+}
+
+func (r *NotificationRepository) CountUnreadNotificationsByUserID(ctx context.Context, userID string, tx DBTX) (count int64, err error) {
+	query, _, err := goqu.From(goqu.T(pkg.TABLE_NOTIFICATIONS).Schema(pkg.SCHEMA_NAME)).
+		Select(goqu.COUNT("*")).
+		Where(
+			goqu.Ex{"user_id": userID},
+			goqu.Ex{"read_at": nil},
+		).
+		ToSQL()
+	if err != nil {
+		return
+	}
+
+	if err = tx.GetContext(ctx, &count, query); err != nil {
+		err = pkg.NewDatabaseError(err)
+		return
+	}
+	return
+}
+
+// MarkNotificationRead marks a notification of the user as read, a notification read before keeps its time
+func (r *NotificationRepository) MarkNotificationRead(ctx context.Context, id string, userID string, readAt time.Time, tx DBTX) (doc model.Notification, err error) {
+	query, _, err := goqu.Update(goqu.T(pkg.TABLE_NOTIFICATIONS).Schema(pkg.SCHEMA_NAME)).
+		Set(goqu.Record{"read_at": goqu.COALESCE(goqu.I("read_at"), readAt)}).
+		Where(
+			goqu.Ex{"id": id},
+			goqu.Ex{"user_id": userID},
+		).
+		Returning("*").
+		ToSQL()
+	if err != nil {
+		return
+	}
+
+	if err = tx.QueryRowxContext(ctx, query).StructScan(&doc); err != nil {
+		if err == sql.ErrNoRows {
+			err = notFoundError("NOTIFICATION_NOT_FOUND", "notification not found")
+			return
+		}
+		err = pkg.NewDatabaseError(err)
+		return
+	}
+	return
+}
+
+func (r *NotificationRepository) MarkAllNotificationsRead(ctx context.Context, userID string, readAt time.Time, tx DBTX) (updated int64, err error) {
+	query, _, err := goqu.Update(goqu.T(pkg.TABLE_NOTIFICATIONS).Schema(pkg.SCHEMA_NAME)).
+		Set(goqu.Record{"read_at": readAt}).
+		Where(
+			goqu.Ex{"user_id": userID},
+			goqu.Ex{"read_at": nil},
+		).
+		ToSQL()
+	if err != nil {
+		return
+	}
+
+	result, err := tx.ExecContext(ctx, query)
+	if err != nil {
+		err = pkg.NewDatabaseError(err)
+		return
+	}
+	return result.RowsAffected()
+}
+
+func (r *NotificationRepository) GetAllNotificationPreferencesByUserID(ctx context.Context, userID string, tx DBTX) (docs []model.NotificationPreference, err error) {
+	query, _, err := goqu.From(goqu.T(pkg.TABLE_NOTIFICATION_PREFERENCES).Schema(pkg.SCHEMA_NAME)).
+		Where(goqu.Ex{"user_id": userID}).
+		ToSQL()
+	if err != nil {
+		return
+	}
+
+	if err = tx.SelectContext(ctx, &docs, query); err != nil {
+		err = pkg.NewDatabaseError(err)
+		return
+	}
+	return
+}
+
+// GetAllNotificationPreferencesByType returns the preferences of the users for one type, users that never
+// changed it have no row
+func (r *NotificationRepository) GetAllNotificationPreferencesByType(ctx context.Context, notificationType string, userIDs []uuid.UUID, tx DBTX) (docs []model.NotificationPreference, err error) {
+	if len(userIDs) == 0 {
+		return
+	}
+
+	query, _, err := goqu.From(goqu.T(pkg.TABLE_NOTIFICATION_PREFERENCES).Schema(pkg.SCHEMA_NAME)).
+		Where(
+			goqu.Ex{"type": notificationType},
+			goqu.Ex{"user_id": userIDs},
+		).
+		ToSQL()
+	if err != nil {
+		return
+	}
+
+	if err = tx.SelectContext(ctx, &docs, query); err != nil {
+		err = pkg.NewDatabaseError(err)
+		return
+	}
+	return
+}
+
+func (r *NotificationRepository) UpsertNotificationPreference(ctx context.Context, preference model.NotificationPreference, tx DBTX) (doc model.NotificationPreference, err error) {
+	query, _, err := goqu.Insert(goqu.T(pkg.TABLE_NOTIFICATION_PREFERENCES).Schema(pkg.SCHEMA_NAME)).
+		Rows(preference).
+		OnConflict(goqu.DoUpdate("user_id, type", goqu.Record{
+			"in_app":     goqu.I("excluded.in_app"),
+			"email":      goqu.I("excluded.email"),
+			"updated_at": goqu.I("excluded.updated_at"),
+		})).
+		Returning("*").
+		ToSQL()
+	if err != nil {
+		return
+	}
+
+	if err = tx.QueryRowxContext(ctx, query).StructScan(&doc); err != nil {
+		err = pkg.NewDatabaseError(err)
+		return
+	}
+	return
+}
@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"edukita-teaching-grading/internal/app/model"
+	"edukita-teaching-grading/internal/pkg"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+)
+
+type (
+	IOutboxRepository interface {
+		CreateOutboxEvent(ctx context.Context, event model.OutboxEvent, tx DBTX) (doc model.OutboxEvent, err error)
+		ClaimOutboxEvents(ctx context.Context, now time.Time, limit uint, tx DBTX) (docs []model.OutboxEvent, err error)
+		UpdateOutboxEvent(ctx context.Context, event model.OutboxEvent, tx DBTX) (err error)
+	}
+	OutboxRepository struct {
+		RepositoryOption
+	}
+)
+
+func InitiateOutboxRepository(opt RepositoryOption) IOutboxRepository {
+	return &OutboxRepository{
+		RepositoryOption: opt,
+	}
+}
+
+func (r *OutboxRepository) CreateOutboxEvent(ctx context.Context, event model.OutboxEvent, tx DBTX) (doc model.OutboxEvent, err error) {
+	query, _, err := goqu.Insert(goqu.T(pkg.TABLE_OUTBOX_EVENTS).Schema(pkg.SCHEMA_NAME)).
+		Rows(event).
+		Returning("*").
+		ToSQL()
+	if err != nil {
+		return
+	}
+
+	if err = tx.QueryRowxContext(ctx, query).StructScan(&doc); err != nil {
+		err = pkg.NewDatabaseError(err)
+		return
+	}
+	return
+}
+
+// ClaimOutboxEvents locks up to limit pending events that are due, oldest first. Rows locked by another
+// dispatcher are skipped, so several replicas can dispatch side by side without handing out an event twice.
+func (r *OutboxRepository) ClaimOutboxEvents(ctx context.Context, now time.Time, limit uint, tx DBTX) (docs []model.OutboxEvent, err error) {
+	query, _, err := goqu.From(goqu.T(pkg.TABLE_OUTBOX_EVENTS).Schema(pkg.SCHEMA_NAME)).
+		Where(
+			goqu.Ex{"dispatched_at": nil},
+			goqu.Ex{"failed_at": nil},
+			goqu.I("available_at").Lte(now),
+		).
+		Order(goqu.I("occurred_at").Asc(), goqu.I("id").Asc()).
+		Limit(limit).
+		ForUpdate(exp.SkipLocked).
+		ToSQL()
+	if err != nil {
+		return
+	}
+
+	if err = tx.SelectContext(ctx, &docs, query); err != nil {
+		err = pkg.NewDatabaseError(err)
+		return
+	}
+	return
+}
+
+// UpdateOutboxEvent saves the outcome of a dispatch attempt
+func (r *OutboxRepository) UpdateOutboxEvent(ctx context.Context, event model.OutboxEvent, tx DBTX) (err error) {
+	query, _, err := goqu.Update(goqu.T(pkg.TABLE_OUTBOX_EVENTS).Schema(pkg.SCHEMA_NAME)).
+		Set(goqu.Record{
+			"attempts":      event.Attempts,
+			"available_at":  event.AvailableAt,
+			"last_error":    event.LastError,
+			"dispatched_at": event.DispatchedAt,
+			"failed_at":     event.FailedAt,
+		}).
+		Where(goqu.Ex{"id": event.ID}).
+		ToSQL()
+	if err != nil {
+		return
+	}
+
+	if _, err = tx.ExecContext(ctx, query); err != nil {
+		err = pkg.NewDatabaseError(err)
+		return
+	}
+	return
+}
@@ -1,14 +1,7 @@
 package repository
 
 import (
-	"context"
-	"database/sql"
-	"fmt"
-	"net/http"
-
 	"edukita-teaching-grading/internal/pkg"
-
-	"github.com/jmoiron/sqlx"
 )
 
 type RepositoryOption struct {
@@ -18,42 +11,16 @@ type RepositoryOption struct {
 type Repository struct {
 	User               IUserRepository
 	LearningManagement ILearningManagementRepository
-}
-
-func TransactionWrapper(ctx context.Context, db *sqlx.DB, fn func(tx *sqlx.Tx) error) error {
-	if db == nil {
-		return &pkg.AppError{
-			Code:       "DB_NOT_FOUND",
-			Message:    "Database connection not found",
-			StatusCode: http.StatusInternalServerError,
-			Err:        fmt.Errorf("Database connection not found"),
-		}
-	}
-	opt := sql.TxOptions{
-		Isolation: 0,
-		ReadOnly:  false,
-	}
-
-	tx, err := db.BeginTxx(ctx, &opt)
-	if err != nil {
-		return err
-	}
-
-	defer func() {
-		if p := recover(); p != nil {
-			_ = tx.Rollback()
-			err = fmt.Errorf("panic: %v", p)
-		} else if err != nil {
-			_ = tx.Rollback()
-		} else {
-			_ = tx.Commit()
-		}
-	}()
-
-	if err := fn(tx); err != nil {
-		_ = tx.Rollback()
-		return err
-	}
-
-	return tx.Commit()
+	Auth               IAuthRepository
+	Attachment         IAttachmentRepository
+	Rubric             IRubricRepository
+	Gradebook          IGradebookRepository
+	Audit              IAuditRepository
+	Outbox             IOutboxRepository
+	Webhook            IWebhookRepository
+	Notification       INotificationRepository
+	Email              IEmailRepository
+	Scheduler          ISchedulerRepository
+	Job                IJobRepository
+	Tx                 *TxManager
 }
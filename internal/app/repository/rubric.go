@@ -0,0 +1,260 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+
+	"edukita-teaching-grading/internal/app/model"
+	"edukita-teaching-grading/internal/pkg"
+
+	"github.com/doug-martin/goqu/v9"
+)
+
+type (
+	IRubricRepository interface {
+		CreateRubric(ctx context.Context, rubric model.Rubric, tx DBTX) (doc model.Rubric, err error)
+		GetRubricByID(ctx context.Context, id string, tx DBTX) (doc model.Rubric, err error)
+		ListRubrics(ctx context.Context, q pkg.ListQuery, tx DBTX) (docs []model.Rubric, page pkg.ListPage, err error)
+
+		CreateRubricCriterion(ctx context.Context, criterion model.RubricCriterion, tx DBTX) (doc model.RubricCriterion, err error)
+		GetAllRubricCriteriaByRubricID(ctx context.Context, rubricID string, tx DBTX) (docs []model.RubricCriterion, err error)
+		CreateRubricLevel(ctx context.Context, level model.RubricLevel, tx DBTX) (doc model.RubricLevel, err error)
+		GetAllRubricLevelsByRubricID(ctx context.Context, rubricID string, tx DBTX) (docs []model.RubricLevel, err error)
+
+		CreateCriterionScore(ctx context.Context, score model.CriterionScore, tx DBTX) (doc model.CriterionScore, err error)
+		GetAllCriterionScoresBySubmissionID(ctx context.Context, submissionID string, tx DBTX) (docs []model.CriterionScoreDetail, err error)
+		DeleteCriterionScoresBySubmissionID(ctx context.Context, submissionID string, tx DBTX) (err error)
+		CountCriterionScoresByAssignmentID(ctx context.Context, assignmentID string, tx DBTX) (count int, err error)
+	}
+	RubricRepository struct {
+		RepositoryOption
+	}
+)
+
+func InitiateRubricRepository(opt RepositoryOption) IRubricRepository {
+	return &RubricRepository{
+		RepositoryOption: opt,
+	}
+}
+
+func (r *RubricRepository) CreateRubric(ctx context.Context, rubric model.Rubric, tx DBTX) (doc model.Rubric, err error) {
+	query, _, err := goqu.Insert(fmt.Sprintf("%s.%s", pkg.SCHEMA_NAME, pkg.TABLE_RUBRICS)).
+		Rows(rubric).
+		Returning("*").
+		ToSQL()
+	if err != nil {
+		return
+	}
+
+	if err = tx.QueryRowxContext(ctx, query).StructScan(&doc); err != nil {
+		err = pkg.NewDatabaseError(err)
+		return
+	}
+	return
+}
+
+func (r *RubricRepository) GetRubricByID(ctx context.Context, id string, tx DBTX) (doc model.Rubric, err error) {
+	query, _, err := goqu.Select("*").
+		From(fmt.Sprintf("%s.%s", pkg.SCHEMA_NAME, pkg.TABLE_RUBRICS)).
+		Where(
+			goqu.Ex{"id": id},
+			goqu.Ex{"deleted_at": nil},
+		).
+		ToSQL()
+	if err != nil {
+		return
+	}
+
+	if err = tx.GetContext(ctx, &doc, query); err != nil {
+		if err == sql.ErrNoRows {
+			err = &pkg.AppError{
+				Code:       "RUBRIC_NOT_FOUND",
+				Message:    "rubric not found",
+				StatusCode: http.StatusNotFound,
+				Err:        fmt.Errorf("rubric not found"),
+			}
+		} else {
+			err = pkg.NewDatabaseError(err)
+			return
+		}
+		return
+	}
+	return
+}
+
+var rubricListSpec = listSpec{
+	sorts: map[string]string{
+		"title":      "title",
+		"created_at": "created_at",
+	},
+	filters: map[string]listFilter{
+		"created_by": {column: "created_by", kind: filterUUID},
+	},
+	defaultSort: []string{"title"},
+	key:         "id",
+}
+
+func (r *RubricRepository) ListRubrics(ctx context.Context, q pkg.ListQuery, tx DBTX) (docs []model.Rubric, page pkg.ListPage, err error) {
+	ds := goqu.From(goqu.T(pkg.TABLE_RUBRICS).Schema(pkg.SCHEMA_NAME)).
+		Where(goqu.Ex{"deleted_at": nil})
+	return selectPage[model.Rubric](ctx, tx, ds, q, rubricListSpec)
+}
+
+func (r *RubricRepository) CreateRubricCriterion(ctx context.Context, criterion model.RubricCriterion, tx DBTX) (doc model.RubricCriterion, err error) {
+	query, _, err := goqu.Insert(fmt.Sprintf("%s.%s", pkg.SCHEMA_NAME, pkg.TABLE_RUBRIC_CRITERIA)).
+		Rows(criterion).
+		Returning("*").
+		ToSQL()
+	if err != nil {
+		return
+	}
+
+	if err = tx.QueryRowxContext(ctx, query).StructScan(&doc); err != nil {
+		err = pkg.NewDatabaseError(err)
+		return
+	}
+	return
+}
+
+func (r *RubricRepository) GetAllRubricCriteriaByRubricID(ctx context.Context, rubricID string, tx DBTX) (docs []model.RubricCriterion, err error) {
+	query, _, err := goqu.Select("*").
+		From(fmt.Sprintf("%s.%s", pkg.SCHEMA_NAME, pkg.TABLE_RUBRIC_CRITERIA)).
+		Where(goqu.Ex{"rubric_id": rubricID}).
+		Order(goqu.I("position").Asc()).
+		ToSQL()
+	if err != nil {
+		return
+	}
+
+	if err = tx.SelectContext(ctx, &docs, query); err != nil {
+		err = pkg.NewDatabaseError(err)
+		return
+	}
+	return
+}
+
+func (r *RubricRepository) CreateRubricLevel(ctx context.Context, level model.RubricLevel, tx DBTX) (doc model.RubricLevel, err error) {
+	query, _, err := goqu.Insert(fmt.Sprintf("%s.%s", pkg.SCHEMA_NAME, pkg.TABLE_RUBRIC_LEVELS)).
+		Rows(level).
+		Returning("*").
+		ToSQL()
+	if err != nil {
+		return
+	}
+
+	if err = tx.QueryRowxContext(ctx, query).StructScan(&doc); err != nil {
+		err = pkg.NewDatabaseError(err)
+		return
+	}
+	return
+}
+
+func (r *RubricRepository) GetAllRubricLevelsByRubricID(ctx context.Context, rubricID string, tx DBTX) (docs []model.RubricLevel, err error) {
+	query, _, err := goqu.Select(
+		goqu.I("l.id"),
+		goqu.I("l.criterion_id"),
+		goqu.I("l.title"),
+		goqu.I("l.description"),
+		goqu.I("l.points"),
+		goqu.I("l.position"),
+	).
+		From(goqu.T(pkg.TABLE_RUBRIC_LEVELS).Schema(pkg.SCHEMA_NAME).As("l")).
+		InnerJoin(goqu.T(pkg.TABLE_RUBRIC_CRITERIA).Schema(pkg.SCHEMA_NAME).As("c"), goqu.On(goqu.Ex{"c.id": goqu.I("l.criterion_id")})).
+		Where(
+			goqu.Ex{"c.rubric_id": rubricID},
+		).
+		Order(goqu.I("c.position").Asc(), goqu.I("l.position").Asc()).
+		ToSQL()
+	if err != nil {
+		return
+	}
+
+	if err = tx.SelectContext(ctx, &docs, query); err != nil {
+		err = pkg.NewDatabaseError(err)
+		return
+	}
+	return
+}
+
+func (r *RubricRepository) CreateCriterionScore(ctx context.Context, score model.CriterionScore, tx DBTX) (doc model.CriterionScore, err error) {
+	query, _, err := goqu.Insert(fmt.Sprintf("%s.%s", pkg.SCHEMA_NAME, pkg.TABLE_CRITERION_SCORES)).
+		Rows(score).
+		Returning("*").
+		ToSQL()
+	if err != nil {
+		return
+	}
+
+	if err = tx.QueryRowxContext(ctx, query).StructScan(&doc); err != nil {
+		err = pkg.NewDatabaseError(err)
+		return
+	}
+	return
+}
+
+func (r *RubricRepository) GetAllCriterionScoresBySubmissionID(ctx context.Context, submissionID string, tx DBTX) (docs []model.CriterionScoreDetail, err error) {
+	query, _, err := goqu.Select(
+		goqu.I("s.submission_id"),
+		goqu.I("s.criterion_id"),
+		goqu.I("s.level_id"),
+		goqu.I("s.points"),
+		goqu.I("s.feedback"),
+		goqu.I("s.graded_by"),
+		goqu.I("s.graded_at"),
+		goqu.I("c.title").As("criterion_title"),
+		goqu.I("l.title").As("level_title"),
+	).
+		From(goqu.T(pkg.TABLE_CRITERION_SCORES).Schema(pkg.SCHEMA_NAME).As("s")).
+		InnerJoin(goqu.T(pkg.TABLE_RUBRIC_CRITERIA).Schema(pkg.SCHEMA_NAME).As("c"), goqu.On(goqu.Ex{"c.id": goqu.I("s.criterion_id")})).
+		InnerJoin(goqu.T(pkg.TABLE_RUBRIC_LEVELS).Schema(pkg.SCHEMA_NAME).As("l"), goqu.On(goqu.Ex{"l.id": goqu.I("s.level_id")})).
+		Where(
+			goqu.Ex{"s.submission_id": submissionID},
+		).
+		Order(goqu.I("c.position").Asc()).
+		ToSQL()
+	if err != nil {
+		return
+	}
+
+	if err = tx.SelectContext(ctx, &docs, query); err != nil {
+		err = pkg.NewDatabaseError(err)
+		return
+	}
+	return
+}
+
+func (r *RubricRepository) DeleteCriterionScoresBySubmissionID(ctx context.Context, submissionID string, tx DBTX) (err error) {
+	query, _, err := goqu.Delete(fmt.Sprintf("%s.%s", pkg.SCHEMA_NAME, pkg.TABLE_CRITERION_SCORES)).
+		Where(goqu.Ex{"submission_id": submissionID}).
+		ToSQL()
+	if err != nil {
+		return
+	}
+
+	if _, err = tx.ExecContext(ctx, query); err != nil {
+		err = pkg.NewDatabaseError(err)
+		return
+	}
+	return
+}
+
+// CountCriterionScoresByAssignmentID counts the criterion scores given to the submissions of the assignment, deleted
+// submissions included as they can be restored
+func (r *RubricRepository) CountCriterionScoresByAssignmentID(ctx context.Context, assignmentID string, tx DBTX) (count int, err error) {
+	query, _, err := goqu.Select(goqu.COUNT("*")).
+		From(goqu.T(pkg.TABLE_CRITERION_SCORES).Schema(pkg.SCHEMA_NAME).As("cs")).
+		InnerJoin(goqu.T(pkg.TABLE_SUBMISSIONS).Schema(pkg.SCHEMA_NAME).As("s"), goqu.On(goqu.Ex{"s.id": goqu.I("cs.submission_id")})).
+		Where(goqu.Ex{"s.assignment_id": assignmentID}).
+		ToSQL()
+	if err != nil {
+		return
+	}
+
+	if err = tx.GetContext(ctx, &count, query); err != nil {
+		err = pkg.NewDatabaseError(err)
+		return
+	}
+	return
+}
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"edukita-teaching-grading/internal/app/model"
+	"edukita-teaching-grading/internal/pkg"
+
+	"github.com/doug-martin/goqu/v9"
+)
+
+type (
+	ISchedulerRepository interface {
+		TryLockScheduledJob(ctx context.Context, jobName string, tx DBTX) (locked bool, err error)
+		ScheduledJobRunExists(ctx context.Context, jobName string, scheduledAt time.Time, tx DBTX) (exists bool, err error)
+		CreateScheduledJobRun(ctx context.Context, run model.ScheduledJobRun, tx DBTX) (doc model.ScheduledJobRun, err error)
+		ListScheduledJobRuns(ctx context.Context, q pkg.ListQuery, tx DBTX) (docs []model.ScheduledJobRun, page pkg.ListPage, err error)
+	}
+	SchedulerRepository struct {
+		RepositoryOption
+	}
+)
+
+func InitiateSchedulerRepository(opt RepositoryOption) ISchedulerRepository {
+	return &SchedulerRepository{
+		RepositoryOption: opt,
+	}
+}
+
+// TryLockScheduledJob takes the advisory lock of the job for the rest of the transaction, locked is false when
+// another replica holds it. The lock is keyed on a hash of the name, so jobs only share a lock on a collision.
+func (r *SchedulerRepository) TryLockScheduledJob(ctx context.Context, jobName string, tx DBTX) (locked bool, err error) {
+	query, _, err := goqu.Select(goqu.Func("pg_try_advisory_xact_lock", goqu.Func("hashtext", "scheduled_job:"+jobName))).
+		ToSQL()
+	if err != nil {
+		return
+	}
+
+	if err = tx.GetContext(ctx, &locked, query); err != nil {
+		err = pkg.NewDatabaseError(err)
+		return
+	}
+	return
+}
+
+// ScheduledJobRunExists reports whether the firing of the job at scheduledAt already ran
+func (r *SchedulerRepository) ScheduledJobRunExists(ctx context.Context, jobName string, scheduledAt time.Time, tx DBTX) (exists bool, err error) {
+	runs := goqu.From(goqu.T(pkg.TABLE_SCHEDULED_JOB_RUNS).Schema(pkg.SCHEMA_NAME)).
+		Select(goqu.L("1")).
+		Where(
+			goqu.Ex{"job_name": jobName},
+			goqu.Ex{"scheduled_at": scheduledAt},
+		)
+	query, _, err := goqu.Select(goqu.Func("EXISTS", runs)).ToSQL()
+	if err != nil {
+		return
+	}
+
+	if err = tx.GetContext(ctx, &exists, query); err != nil {
+		err = pkg.NewDatabaseError(err)
+		return
+	}
+	return
+}
+
+func (r *SchedulerRepository) CreateScheduledJobRun(ctx context.Context, run model.ScheduledJobRun, tx DBTX) (doc model.ScheduledJobRun, err error) {
+	query, _, err := goqu.Insert(goqu.T(pkg.TABLE_SCHEDULED_JOB_RUNS).Schema(pkg.SCHEMA_NAME)).
+		Rows(run).
+		Returning("*").
+		ToSQL()
+	if err != nil {
+		return
+	}
+
+	if err = tx.QueryRowxContext(ctx, query).StructScan(&doc); err != nil {
+		err = pkg.NewDatabaseError(err)
+		return
+	}
+	return
+}
+
+var scheduledJobRunListSpec = listSpec{
+	sorts: map[string]string{
+		"started_at":   "started_at",
+		"scheduled_at": "scheduled_at",
+	},
+	filters: map[string]listFilter{
+		"job_name": {column: "job_name", kind: filterString},
+		"status":   {column: "status", kind: filterString},
+	},
+	defaultSort: []string{"-started_at"},
+	key:         "id",
+}
+
+func (r *SchedulerRepository) ListScheduledJobRuns(ctx context.Context, q pkg.ListQuery, tx DBTX) (docs []model.ScheduledJobRun, page pkg.ListPage, err error) {
+	ds := goqu.From(goqu.T(pkg.TABLE_SCHEDULED_JOB_RUNS).Schema(pkg.SCHEMA_NAME))
+	return selectPage[model.ScheduledJobRun](ctx, tx, ds, q, scheduledJobRunListSpec)
+}
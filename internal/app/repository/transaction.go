@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"edukita-teaching-grading/internal/pkg"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jmoiron/sqlx"
+)
+
+// DBTX is implemented by both *sqlx.DB and *sqlx.Tx, so repository calls can run inside a transaction
+// or straight on the pool
+type DBTX interface {
+	sqlx.ExtContext
+	GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+	SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+	Select(dest interface{}, query string, args ...interface{}) error
+}
+
+// TxOptions selects the mode of a transaction
+type TxOptions struct {
+	ReadOnly  bool
+	Isolation sql.IsolationLevel
+	// Retries is how many times the transaction is run again after a serialization failure or deadlock
+	Retries int
+}
+
+var (
+	TxReadOnly  = TxOptions{ReadOnly: true}
+	TxReadWrite = TxOptions{}
+	// TxSerializable is for read-check-write paths, like enforcing limits, that must not interleave
+	TxSerializable = TxOptions{Isolation: sql.LevelSerializable, Retries: 3}
+)
+
+type txContextKey struct{}
+
+// txState is the transaction carried by the context of the function running inside it
+type txState struct {
+	tx    *sqlx.Tx
+	opts  TxOptions
+	depth int
+}
+
+type TxManager struct {
+	RepositoryOption
+}
+
+func NewTxManager(opt RepositoryOption) *TxManager {
+	return &TxManager{
+		RepositoryOption: opt,
+	}
+}
+
+// Run calls fn inside a transaction and commits it when fn succeeds. When the context already carries a
+// transaction, fn runs in a savepoint of it instead, keeping the mode of the outer transaction.
+func (m *TxManager) Run(ctx context.Context, opts TxOptions, fn func(ctx context.Context, tx *sqlx.Tx) error) error {
+	if m.Postgres == nil {
+		return &pkg.AppError{
+			Code:       "DB_NOT_FOUND",
+			Message:    "Database connection not found",
+			StatusCode: http.StatusInternalServerError,
+			Err:        fmt.Errorf("Database connection not found"),
+		}
+	}
+
+	if state, ok := ctx.Value(txContextKey{}).(*txState); ok {
+		return m.savepoint(ctx, state, opts, fn)
+	}
+
+	for attempt := 0; ; attempt++ {
+		err := m.run(ctx, opts, fn)
+		if err == nil || attempt >= opts.Retries || !isSerializationFailure(err) {
+			return err
+		}
+		m.Logger.Warnf("retrying transaction after serialization failure (attempt %d): %s", attempt+1, err.Error())
+	}
+}
+
+// Conn returns the transaction carried by the context, or the pool for calls that need no transaction
+func (m *TxManager) Conn(ctx context.Context) DBTX {
+	if state, ok := ctx.Value(txContextKey{}).(*txState); ok {
+		return state.tx
+	}
+	return m.Postgres
+}
+
+func (m *TxManager) run(ctx context.Context, opts TxOptions, fn func(ctx context.Context, tx *sqlx.Tx) error) (err error) {
+	tx, err := m.Postgres.BeginTxx(ctx, &sql.TxOptions{Isolation: opts.Isolation, ReadOnly: opts.ReadOnly})
+	if err != nil {
+		return pkg.NewDatabaseError(err)
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback()
+			err = fmt.Errorf("panic in transaction: %v", p)
+		}
+	}()
+
+	if err = fn(context.WithValue(ctx, txContextKey{}, &txState{tx: tx, opts: opts}), tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return pkg.NewDatabaseError(err)
+	}
+	return nil
+}
+
+func (m *TxManager) savepoint(ctx context.Context, state *txState, opts TxOptions, fn func(ctx context.Context, tx *sqlx.Tx) error) (err error) {
+	if state.opts.ReadOnly && !opts.ReadOnly {
+		return pkg.NewError(http.StatusText(http.StatusInternalServerError), "cannot write inside a read-only transaction", http.StatusInternalServerError, nil)
+	}
+
+	nested := &txState{tx: state.tx, opts: state.opts, depth: state.depth + 1}
+	name := fmt.Sprintf("sp_%d", nested.depth)
+	if _, err = state.tx.ExecContext(ctx, "SAVEPOINT "+name); err != nil {
+		return pkg.NewDatabaseError(err)
+	}
+
+	if err = fn(context.WithValue(ctx, txContextKey{}, nested), state.tx); err != nil {
+		if _, rollbackErr := state.tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+name); rollbackErr != nil {
+			return errors.Join(err, pkg.NewDatabaseError(rollbackErr))
+		}
+		return err
+	}
+
+	if _, err = state.tx.ExecContext(ctx, "RELEASE SAVEPOINT "+name); err != nil {
+		return pkg.NewDatabaseError(err)
+	}
+	return nil
+}
+
+// isSerializationFailure reports errors after which the whole transaction can safely be run again
+func isSerializationFailure(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && (pgErr.Code == "40001" || pgErr.Code == "40P01")
+}
@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"edukita-teaching-grading/internal/pkg"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Rows are soft-deleted by stamping deleted_at and deleted_by, reads only see rows where deleted_at is NULL.
+// Children deleted together with their parent share its deleted_at, so restoring the parent brings back exactly
+// the children it took down and leaves the ones deleted on their own in the trash.
+
+// softDeleteQuery stamps the live rows of the table matching where as deleted
+func softDeleteQuery(table string, where exp.Expression, deletedBy uuid.UUID, deletedAt time.Time) *goqu.UpdateDataset {
+	return goqu.From(goqu.T(table).Schema(pkg.SCHEMA_NAME)).
+		Update().
+		Set(goqu.Record{"deleted_at": deletedAt, "deleted_by": deletedBy}).
+		Where(where, goqu.Ex{"deleted_at": nil})
+}
+
+// restoreQuery clears the deletion stamp of the soft-deleted rows of the table matching where
+func restoreQuery(table string, where exp.Expression, restoredBy uuid.UUID) *goqu.UpdateDataset {
+	return goqu.From(goqu.T(table).Schema(pkg.SCHEMA_NAME)).
+		Update().
+		Set(goqu.Record{"deleted_at": nil, "deleted_by": nil, "updated_by": restoredBy}).
+		Where(where, goqu.I("deleted_at").IsNotNull())
+}
+
+// purgeQuery permanently removes the soft-deleted rows of the table matching where, live rows are never purged
+func purgeQuery(table string, where exp.Expression) *goqu.DeleteDataset {
+	return goqu.Delete(goqu.T(table).Schema(pkg.SCHEMA_NAME)).
+		Where(where, goqu.I("deleted_at").IsNotNull())
+}
+
+// deletedQuery selects the soft-deleted row of the table with the id
+func deletedQuery(table string, id string) *goqu.SelectDataset {
+	return goqu.From(goqu.T(table).Schema(pkg.SCHEMA_NAME)).
+		Where(goqu.Ex{"id": id}, goqu.I("deleted_at").IsNotNull())
+}
+
+// returningOne scans the single row returned by the query, notFound is returned when no row matched
+func returningOne[T any](ctx context.Context, tx DBTX, query string, notFound error) (doc T, err error) {
+	if err = tx.QueryRowxContext(ctx, query).StructScan(&doc); err != nil {
+		switch {
+		case err == sql.ErrNoRows:
+			err = notFound
+		case isUniqueViolation(err):
+			err = conflictError(err)
+		default:
+			err = pkg.NewDatabaseError(err)
+		}
+	}
+	return
+}
+
+// execCount runs the query and returns the number of rows it touched
+func execCount(ctx context.Context, tx DBTX, query string) (int64, error) {
+	result, err := tx.ExecContext(ctx, query)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return 0, conflictError(err)
+		}
+		return 0, pkg.NewDatabaseError(err)
+	}
+	return result.RowsAffected()
+}
+
+func notFoundError(code string, message string) *pkg.AppError {
+	return &pkg.AppError{
+		Code:       code,
+		Message:    message,
+		StatusCode: http.StatusNotFound,
+		Err:        errors.New(message),
+	}
+}
+
+// conflictError is returned when restoring a row would clash with a live row holding the same unique values,
+// such as a user registered again with the email of a deleted one
+func conflictError(err error) *pkg.AppError {
+	return pkg.NewError(http.StatusText(http.StatusConflict), "a live record with the same unique values already exists", http.StatusConflict, err)
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
@@ -11,31 +11,32 @@ import (
 	"edukita-teaching-grading/internal/pkg"
 
 	"github.com/doug-martin/goqu/v9"
-	"github.com/jmoiron/sqlx"
+	"github.com/google/uuid"
 )
 
 type (
 	IUserRepository interface {
 		// Create User General
-		CreateUser(ctx context.Context, user model.User, tx *sqlx.Tx) (docs model.User, err error)
-		GetUserByEmail(ctx context.Context, email string, tx *sqlx.Tx) (docs model.User, err error)
-		GetUserByID(ctx context.Context, id string, tx *sqlx.Tx) (docs model.User, err error)
-		UpdateUserByID(ctx context.Context, user model.User, tx *sqlx.Tx) (docs model.User, err error)
-		DeleteUserByID(ctx context.Context, id string, tx *sqlx.Tx) (docs model.User, err error)
+		CreateUser(ctx context.Context, user model.User, tx DBTX) (docs model.User, err error)
+		GetUserByEmail(ctx context.Context, email string, tx DBTX) (docs model.User, err error)
+		GetUserByID(ctx context.Context, id string, tx DBTX) (docs model.User, err error)
+		UpdateUserByID(ctx context.Context, user model.User, tx DBTX) (docs model.User, err error)
+		DeleteUserByID(ctx context.Context, id string, deletedBy uuid.UUID, deletedAt time.Time, tx DBTX) (docs model.User, err error)
+		GetDeletedUserByID(ctx context.Context, id string, tx DBTX) (docs model.User, err error)
+		RestoreUserByID(ctx context.Context, id string, restoredBy uuid.UUID, tx DBTX) (docs model.User, err error)
+		PurgeUserByID(ctx context.Context, id string, tx DBTX) (docs model.User, err error)
 
 		// Create User Teacher
-		CreateTeacher(ctx context.Context, teacher model.Teacher, tx *sqlx.Tx) (docs model.Teacher, err error)
-		GetTeacherByEmail(ctx context.Context, email string, tx *sqlx.Tx) (docs model.Teacher, err error)
-		GetTeacherByID(ctx context.Context, id string, tx *sqlx.Tx) (docs model.Teacher, err error)
-		UpdateTeacherByID(ctx context.Context, teacher model.Teacher, tx *sqlx.Tx) (docs model.Teacher, err error)
-		DeleteTeacherByID(ctx context.Context, id string, tx *sqlx.Tx) (docs model.Teacher, err error)
+		CreateTeacher(ctx context.Context, teacher model.Teacher, tx DBTX) (docs model.Teacher, err error)
+		GetTeacherByEmail(ctx context.Context, email string, tx DBTX) (docs model.Teacher, err error)
+		GetTeacherByID(ctx context.Context, id string, tx DBTX) (docs model.Teacher, err error)
+		UpdateTeacherByID(ctx context.Context, teacher model.Teacher, tx DBTX) (docs model.Teacher, err error)
 
 		// Create User Student
-		CreateStudent(ctx context.Context, student model.Student, tx *sqlx.Tx) (docs model.Student, err error)
-		GetStudentByEmail(ctx context.Context, email string, tx *sqlx.Tx) (docs model.Student, err error)
-		GetStudentByID(ctx context.Context, id string, tx *sqlx.Tx) (docs model.Student, err error)
-		UpdateStudentByID(ctx context.Context, student model.Student, tx *sqlx.Tx) (docs model.Student, err error)
-		DeleteStudentByID(ctx context.Context, id string, tx *sqlx.Tx) (docs model.Student, err error)
+		CreateStudent(ctx context.Context, student model.Student, tx DBTX) (docs model.Student, err error)
+		GetStudentByEmail(ctx context.Context, email string, tx DBTX) (docs model.Student, err error)
+		GetStudentByID(ctx context.Context, id string, tx DBTX) (docs model.Student, err error)
+		UpdateStudentByID(ctx context.Context, student model.Student, tx DBTX) (docs model.Student, err error)
 	}
 	UserRepository struct {
 		RepositoryOption
@@ -48,7 +49,7 @@ func InitiateUserRepository(opt RepositoryOption) IUserRepository {
 	}
 }
 
-func (r *UserRepository) CreateUser(ctx context.Context, user model.User, tx *sqlx.Tx) (docs model.User, err error) {
+func (r *UserRepository) CreateUser(ctx context.Context, user model.User, tx DBTX) (docs model.User, err error) {
 	query, _, err := goqu.Insert(fmt.Sprintf("%s.%s", pkg.SCHEMA_NAME, pkg.TABLE_USERS)).
 		Rows(user).
 		Returning("*").
@@ -65,12 +66,13 @@ func (r *UserRepository) CreateUser(ctx context.Context, user model.User, tx *sq
 	return
 }
 
-func (r *UserRepository) GetUserByEmail(ctx context.Context, email string, tx *sqlx.Tx) (docs model.User, err error) {
+func (r *UserRepository) GetUserByEmail(ctx context.Context, email string, tx DBTX) (docs model.User, err error) {
 	query, _, err := goqu.Select("*").
 		From(fmt.Sprintf("%s.%s", pkg.SCHEMA_NAME, pkg.TABLE_USERS)).
 		Where(
 			goqu.Ex{"email": email},
 			goqu.Ex{"is_active": true},
+			goqu.Ex{"deleted_at": nil},
 		).
 		ToSQL()
 	if err != nil {
@@ -94,12 +96,13 @@ func (r *UserRepository) GetUserByEmail(ctx context.Context, email string, tx *s
 	return
 }
 
-func (r *UserRepository) GetUserByID(ctx context.Context, id string, tx *sqlx.Tx) (docs model.User, err error) {
+func (r *UserRepository) GetUserByID(ctx context.Context, id string, tx DBTX) (docs model.User, err error) {
 	query, _, err := goqu.Select("*").
 		From(fmt.Sprintf("%s.%s", pkg.SCHEMA_NAME, pkg.TABLE_USERS)).
 		Where(
 			goqu.Ex{"id": id},
 			goqu.Ex{"is_active": true},
+			goqu.Ex{"deleted_at": nil},
 		).
 		ToSQL()
 	if err != nil {
@@ -123,7 +126,7 @@ func (r *UserRepository) GetUserByID(ctx context.Context, id string, tx *sqlx.Tx
 	return
 }
 
-func (r *UserRepository) UpdateUserByID(ctx context.Context, user model.User, tx *sqlx.Tx) (docs model.User, err error) {
+func (r *UserRepository) UpdateUserByID(ctx context.Context, user model.User, tx DBTX) (docs model.User, err error) {
 	query, _, err := goqu.From(fmt.Sprintf("%s.%s", pkg.SCHEMA_NAME, pkg.TABLE_USERS)).
 		Update().
 		Set(user).
@@ -142,26 +145,55 @@ func (r *UserRepository) UpdateUserByID(ctx context.Context, user model.User, tx
 	return
 }
 
-func (r *UserRepository) DeleteUserByID(ctx context.Context, id string, tx *sqlx.Tx) (docs model.User, err error) {
-	query, _, err := goqu.From(fmt.Sprintf("%s.%s", pkg.SCHEMA_NAME, pkg.TABLE_USERS)).
-		Update().
-		Set(goqu.Record{"deleted_at": time.Now()}).
-		Where(goqu.Ex{"id": id}).
+func (r *UserRepository) DeleteUserByID(ctx context.Context, id string, deletedBy uuid.UUID, deletedAt time.Time, tx DBTX) (docs model.User, err error) {
+	query, _, err := softDeleteQuery(pkg.TABLE_USERS, goqu.Ex{"id": id}, deletedBy, deletedAt).
 		Returning("*").
 		ToSQL()
 	if err != nil {
 		return
 	}
+	return returningOne[model.User](ctx, tx, query, notFoundError("USER_NOT_FOUND", "user not found"))
+}
 
-	if err = tx.QueryRowxContext(ctx, query).Scan(&docs); err != nil {
-		err = pkg.NewDatabaseError(err)
+func (r *UserRepository) GetDeletedUserByID(ctx context.Context, id string, tx DBTX) (docs model.User, err error) {
+	query, _, err := deletedQuery(pkg.TABLE_USERS, id).ToSQL()
+	if err != nil {
 		return
 	}
+	return returningOne[model.User](ctx, tx, query, notFoundError("DELETED_USER_NOT_FOUND", "deleted user not found"))
+}
 
-	return
+func (r *UserRepository) RestoreUserByID(ctx context.Context, id string, restoredBy uuid.UUID, tx DBTX) (docs model.User, err error) {
+	query, _, err := restoreQuery(pkg.TABLE_USERS, goqu.Ex{"id": id}, restoredBy).
+		Returning("*").
+		ToSQL()
+	if err != nil {
+		return
+	}
+	return returningOne[model.User](ctx, tx, query, notFoundError("DELETED_USER_NOT_FOUND", "deleted user not found"))
 }
 
-func (r *UserRepository) CreateTeacher(ctx context.Context, teacher model.Teacher, tx *sqlx.Tx) (docs model.Teacher, err error) {
+// PurgeUserByID permanently removes a soft-deleted user, the database cascades to their teacher or student
+// profile and to the assignments, submissions and enrollments hanging off it
+func (r *UserRepository) PurgeUserByID(ctx context.Context, id string, tx DBTX) (docs model.User, err error) {
+	query, _, err := purgeQuery(pkg.TABLE_USERS, goqu.Ex{"id": id}).
+		Returning("*").
+		ToSQL()
+	if err != nil {
+		return
+	}
+	return returningOne[model.User](ctx, tx, query, notFoundError("DELETED_USER_NOT_FOUND", "deleted user not found"))
+}
+
+// liveUsers selects the ids of the users that are not soft-deleted, teacher and student profiles have no
+// deletion stamp of their own and follow their user
+func liveUsers() *goqu.SelectDataset {
+	return goqu.From(goqu.T(pkg.TABLE_USERS).Schema(pkg.SCHEMA_NAME)).
+		Select("id").
+		Where(goqu.Ex{"deleted_at": nil})
+}
+
+func (r *UserRepository) CreateTeacher(ctx context.Context, teacher model.Teacher, tx DBTX) (docs model.Teacher, err error) {
 	query, _, err := goqu.Insert(fmt.Sprintf("%s.%s", pkg.SCHEMA_NAME, pkg.TABLE_TEACHERS)).
 		Rows(teacher).
 		Returning("*").
@@ -178,7 +210,7 @@ func (r *UserRepository) CreateTeacher(ctx context.Context, teacher model.Teache
 	return
 }
 
-func (r *UserRepository) GetTeacherByEmail(ctx context.Context, email string, tx *sqlx.Tx) (docs model.Teacher, err error) {
+func (r *UserRepository) GetTeacherByEmail(ctx context.Context, email string, tx DBTX) (docs model.Teacher, err error) {
 	query, _, err := goqu.Select("*").
 		From(fmt.Sprintf("%s.%s", pkg.SCHEMA_NAME, pkg.TABLE_TEACHERS)).
 		Where(
@@ -207,11 +239,12 @@ func (r *UserRepository) GetTeacherByEmail(ctx context.Context, email string, tx
 	return
 }
 
-func (r *UserRepository) GetTeacherByID(ctx context.Context, id string, tx *sqlx.Tx) (docs model.Teacher, err error) {
+func (r *UserRepository) GetTeacherByID(ctx context.Context, id string, tx DBTX) (docs model.Teacher, err error) {
 	query, _, err := goqu.Select("*").
 		From(fmt.Sprintf("%s.%s", pkg.SCHEMA_NAME, pkg.TABLE_TEACHERS)).
 		Where(
 			goqu.Ex{"user_id": id},
+			goqu.Ex{"user_id": liveUsers()},
 		).
 		ToSQL()
 	if err != nil {
@@ -235,7 +268,7 @@ func (r *UserRepository) GetTeacherByID(ctx context.Context, id string, tx *sqlx
 	return
 }
 
-func (r *UserRepository) UpdateTeacherByID(ctx context.Context, teacher model.Teacher, tx *sqlx.Tx) (docs model.Teacher, err error) {
+func (r *UserRepository) UpdateTeacherByID(ctx context.Context, teacher model.Teacher, tx DBTX) (docs model.Teacher, err error) {
 	query, _, err := goqu.From(fmt.Sprintf("%s.%s", pkg.SCHEMA_NAME, pkg.TABLE_TEACHERS)).
 		Update().
 		Set(teacher).
@@ -254,26 +287,7 @@ func (r *UserRepository) UpdateTeacherByID(ctx context.Context, teacher model.Te
 	return
 }
 
-func (r *UserRepository) DeleteTeacherByID(ctx context.Context, id string, tx *sqlx.Tx) (docs model.Teacher, err error) {
-	query, _, err := goqu.From(fmt.Sprintf("%s.%s", pkg.SCHEMA_NAME, pkg.TABLE_TEACHERS)).
-		Update().
-		Set(goqu.Record{"deleted_at": time.Now()}).
-		Where(goqu.Ex{"id": id}).
-		Returning("*").
-		ToSQL()
-	if err != nil {
-		return
-	}
-
-	if err = tx.QueryRowxContext(ctx, query).StructScan(&docs); err != nil {
-		err = pkg.NewDatabaseError(err)
-		return
-	}
-
-	return
-}
-
-func (r *UserRepository) CreateStudent(ctx context.Context, student model.Student, tx *sqlx.Tx) (docs model.Student, err error) {
+func (r *UserRepository) CreateStudent(ctx context.Context, student model.Student, tx DBTX) (docs model.Student, err error) {
 	query, _, err := goqu.Insert(fmt.Sprintf("%s.%s", pkg.SCHEMA_NAME, pkg.TABLE_STUDENTS)).
 		Rows(student).
 		Returning("*").
@@ -290,7 +304,7 @@ func (r *UserRepository) CreateStudent(ctx context.Context, student model.Studen
 	return
 }
 
-func (r *UserRepository) GetStudentByEmail(ctx context.Context, email string, tx *sqlx.Tx) (docs model.Student, err error) {
+func (r *UserRepository) GetStudentByEmail(ctx context.Context, email string, tx DBTX) (docs model.Student, err error) {
 	query, _, err := goqu.Select("*").
 		From(fmt.Sprintf("%s.%s", pkg.SCHEMA_NAME, pkg.TABLE_STUDENTS)).
 		Where(
@@ -319,11 +333,12 @@ func (r *UserRepository) GetStudentByEmail(ctx context.Context, email string, tx
 	return
 }
 
-func (r *UserRepository) GetStudentByID(ctx context.Context, id string, tx *sqlx.Tx) (docs model.Student, err error) {
+func (r *UserRepository) GetStudentByID(ctx context.Context, id string, tx DBTX) (docs model.Student, err error) {
 	query, _, err := goqu.Select("*").
 		From(fmt.Sprintf("%s.%s", pkg.SCHEMA_NAME, pkg.TABLE_STUDENTS)).
 		Where(
 			goqu.Ex{"user_id": id},
+			goqu.Ex{"user_id": liveUsers()},
 		).
 		ToSQL()
 	if err != nil {
@@ -347,7 +362,7 @@ func (r *UserRepository) GetStudentByID(ctx context.Context, id string, tx *sqlx
 	return
 }
 
-func (r *UserRepository) UpdateStudentByID(ctx context.Context, student model.Student, tx *sqlx.Tx) (docs model.Student, err error) {
+func (r *UserRepository) UpdateStudentByID(ctx context.Context, student model.Student, tx DBTX) (docs model.Student, err error) {
 	query, _, err := goqu.From(fmt.Sprintf("%s.%s", pkg.SCHEMA_NAME, pkg.TABLE_STUDENTS)).
 		Update().
 		Set(student).
@@ -365,22 +380,3 @@ func (r *UserRepository) UpdateStudentByID(ctx context.Context, student model.St
 
 	return
 }
-
-func (r *UserRepository) DeleteStudentByID(ctx context.Context, id string, tx *sqlx.Tx) (docs model.Student, err error) {
-	query, _, err := goqu.From(fmt.Sprintf("%s.%s", pkg.SCHEMA_NAME, pkg.TABLE_STUDENTS)).
-		Update().
-		Set(goqu.Record{"deleted_at": time.Now()}).
-		Where(goqu.Ex{"id": id}).
-		Returning("*").
-		ToSQL()
-	if err != nil {
-		return
-	}
-
-	if err = tx.QueryRowxContext(ctx, query).Scan(&docs); err != nil {
-		err = pkg.NewDatabaseError(err)
-		return
-	}
-
-	return
-}
@@ -0,0 +1,253 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"edukita-teaching-grading/internal/app/model"
+	"edukita-teaching-grading/internal/pkg"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/google/uuid"
+)
+
+type (
+	IWebhookRepository interface {
+		CreateWebhookSubscription(ctx context.Context, subscription model.WebhookSubscription, tx DBTX) (doc model.WebhookSubscription, err error)
+		GetWebhookSubscriptionByID(ctx context.Context, id string, tx DBTX) (doc model.WebhookSubscription, err error)
+		GetAllActiveWebhookSubscriptions(ctx context.Context, tx DBTX) (docs []model.WebhookSubscription, err error)
+		ListWebhookSubscriptions(ctx context.Context, q pkg.ListQuery, tx DBTX) (docs []model.WebhookSubscription, page pkg.ListPage, err error)
+		UpdateWebhookSubscriptionByID(ctx context.Context, subscription model.WebhookSubscription, tx DBTX) (doc model.WebhookSubscription, err error)
+		DeleteWebhookSubscriptionByID(ctx context.Context, id string, deletedBy uuid.UUID, deletedAt time.Time, tx DBTX) (doc model.WebhookSubscription, err error)
+
+		CreateWebhookDelivery(ctx context.Context, delivery model.WebhookDelivery, tx DBTX) (created int64, err error)
+		GetWebhookDeliveryByID(ctx context.Context, id string, tx DBTX) (doc model.WebhookDelivery, err error)
+		ListWebhookDeliveriesBySubscriptionID(ctx context.Context, subscriptionID string, q pkg.ListQuery, tx DBTX) (docs []model.WebhookDelivery, page pkg.ListPage, err error)
+		UpdateWebhookDelivery(ctx context.Context, delivery model.WebhookDelivery, tx DBTX) (doc model.WebhookDelivery, err error)
+
+		CreateWebhookAttempt(ctx context.Context, attempt model.WebhookAttempt, tx DBTX) (doc model.WebhookAttempt, err error)
+		GetAllWebhookAttemptsByDeliveryID(ctx context.Context, deliveryID string, tx DBTX) (docs []model.WebhookAttempt, err error)
+	}
+	WebhookRepository struct {
+		RepositoryOption
+	}
+)
+
+func InitiateWebhookRepository(opt RepositoryOption) IWebhookRepository {
+	return &WebhookRepository{
+		RepositoryOption: opt,
+	}
+}
+
+func (r *WebhookRepository) CreateWebhookSubscription(ctx context.Context, subscription model.WebhookSubscription, tx DBTX) (doc model.WebhookSubscription, err error) {
+	query, _, err := goqu.Insert(goqu.T(pkg.TABLE_WEBHOOKS).Schema(pkg.SCHEMA_NAME)).
+		Rows(subscription).
+		Returning("*").
+		ToSQL()
+	if err != nil {
+		return
+	}
+
+	if err = tx.QueryRowxContext(ctx, query).StructScan(&doc); err != nil {
+		err = pkg.NewDatabaseError(err)
+		return
+	}
+	return
+}
+
+func (r *WebhookRepository) GetWebhookSubscriptionByID(ctx context.Context, id string, tx DBTX) (doc model.WebhookSubscription, err error) {
+	query, _, err := goqu.From(goqu.T(pkg.TABLE_WEBHOOKS).Schema(pkg.SCHEMA_NAME)).
+		Where(goqu.Ex{"id": id, "deleted_at": nil}).
+		ToSQL()
+	if err != nil {
+		return
+	}
+
+	if err = tx.GetContext(ctx, &doc, query); err != nil {
+		if err == sql.ErrNoRows {
+			err = notFoundError("WEBHOOK_NOT_FOUND", "webhook not found")
+			return
+		}
+		err = pkg.NewDatabaseError(err)
+		return
+	}
+	return
+}
+
+func (r *WebhookRepository) GetAllActiveWebhookSubscriptions(ctx context.Context, tx DBTX) (docs []model.WebhookSubscription, err error) {
+	query, _, err := goqu.From(goqu.T(pkg.TABLE_WEBHOOKS).Schema(pkg.SCHEMA_NAME)).
+		Where(goqu.Ex{"is_active": true, "deleted_at": nil}).
+		ToSQL()
+	if err != nil {
+		return
+	}
+
+	if err = tx.SelectContext(ctx, &docs, query); err != nil {
+		err = pkg.NewDatabaseError(err)
+		return
+	}
+	return
+}
+
+var webhookListSpec = listSpec{
+	sorts: map[string]string{
+		"url":        "url",
+		"created_at": "created_at",
+	},
+	filters: map[string]listFilter{
+		"is_active": {column: "is_active", kind: filterBool},
+	},
+	defaultSort: []string{"-created_at"},
+	key:         "id",
+}
+
+func (r *WebhookRepository) ListWebhookSubscriptions(ctx context.Context, q pkg.ListQuery, tx DBTX) (docs []model.WebhookSubscription, page pkg.ListPage, err error) {
+	ds := goqu.From(goqu.T(pkg.TABLE_WEBHOOKS).Schema(pkg.SCHEMA_NAME)).
+		Where(goqu.Ex{"deleted_at": nil})
+	return selectPage[model.WebhookSubscription](ctx, tx, ds, q, webhookListSpec)
+}
+
+func (r *WebhookRepository) UpdateWebhookSubscriptionByID(ctx context.Context, subscription model.WebhookSubscription, tx DBTX) (doc model.WebhookSubscription, err error) {
+	query, _, err := goqu.Update(goqu.T(pkg.TABLE_WEBHOOKS).Schema(pkg.SCHEMA_NAME)).
+		Set(subscription).
+		Where(goqu.Ex{"id": subscription.ID, "deleted_at": nil}).
+		Returning("*").
+		ToSQL()
+	if err != nil {
+		return
+	}
+
+	if err = tx.QueryRowxContext(ctx, query).StructScan(&doc); err != nil {
+		err = pkg.NewDatabaseError(err)
+		return
+	}
+	return
+}
+
+// DeleteWebhookSubscriptionByID soft-deletes the subscription, its deliveries and their attempts stay as the log
+// of what was sent to the receiver
+func (r *WebhookRepository) DeleteWebhookSubscriptionByID(ctx context.Context, id string, deletedBy uuid.UUID, deletedAt time.Time, tx DBTX) (doc model.WebhookSubscription, err error) {
+	query, _, err := softDeleteQuery(pkg.TABLE_WEBHOOKS, goqu.Ex{"id": id}, deletedBy, deletedAt).
+		Returning("*").
+		ToSQL()
+	if err != nil {
+		return
+	}
+	return returningOne[model.WebhookSubscription](ctx, tx, query, notFoundError("WEBHOOK_NOT_FOUND", "webhook not found"))
+}
+
+// CreateWebhookDelivery saves the delivery of the event to the subscription, an event the subscription already
+// has is left as it is and nothing is created
+func (r *WebhookRepository) CreateWebhookDelivery(ctx context.Context, delivery model.WebhookDelivery, tx DBTX) (created int64, err error) {
+	query, _, err := goqu.Insert(goqu.T(pkg.TABLE_WEBHOOK_DELIVERY).Schema(pkg.SCHEMA_NAME)).
+		Rows(delivery).
+		OnConflict(goqu.DoNothing()).
+		ToSQL()
+	if err != nil {
+		return
+	}
+
+	result, err := tx.ExecContext(ctx, query)
+	if err != nil {
+		err = pkg.NewDatabaseError(err)
+		return
+	}
+	return result.RowsAffected()
+}
+
+func (r *WebhookRepository) GetWebhookDeliveryByID(ctx context.Context, id string, tx DBTX) (doc model.WebhookDelivery, err error) {
+	query, _, err := goqu.From(goqu.T(pkg.TABLE_WEBHOOK_DELIVERY).Schema(pkg.SCHEMA_NAME)).
+		Where(goqu.Ex{"id": id}).
+		ToSQL()
+	if err != nil {
+		return
+	}
+
+	if err = tx.GetContext(ctx, &doc, query); err != nil {
+		if err == sql.ErrNoRows {
+			err = notFoundError("WEBHOOK_DELIVERY_NOT_FOUND", "webhook delivery not found")
+			return
+		}
+		err = pkg.NewDatabaseError(err)
+		return
+	}
+	return
+}
+
+var webhookDeliveryListSpec = listSpec{
+	sorts: map[string]string{
+		"created_at":      "created_at",
+		"next_attempt_at": "next_attempt_at",
+	},
+	filters: map[string]listFilter{
+		"status":     {column: "status", kind: filterString},
+		"event_type": {column: "event_type", kind: filterString},
+		"event_id":   {column: "event_id", kind: filterUUID},
+	},
+	defaultSort: []string{"-created_at"},
+	key:         "id",
+}
+
+func (r *WebhookRepository) ListWebhookDeliveriesBySubscriptionID(ctx context.Context, subscriptionID string, q pkg.ListQuery, tx DBTX) (docs []model.WebhookDelivery, page pkg.ListPage, err error) {
+	ds := goqu.From(goqu.T(pkg.TABLE_WEBHOOK_DELIVERY).Schema(pkg.SCHEMA_NAME)).
+		Where(goqu.Ex{"subscription_id": subscriptionID})
+	return selectPage[model.WebhookDelivery](ctx, tx, ds, q, webhookDeliveryListSpec)
+}
+
+// UpdateWebhookDelivery saves the outcome of an attempt or a redelivery
+func (r *WebhookRepository) UpdateWebhookDelivery(ctx context.Context, delivery model.WebhookDelivery, tx DBTX) (doc model.WebhookDelivery, err error) {
+	query, _, err := goqu.Update(goqu.T(pkg.TABLE_WEBHOOK_DELIVERY).Schema(pkg.SCHEMA_NAME)).
+		Set(goqu.Record{
+			"status":           delivery.Status,
+			"attempts":         delivery.Attempts,
+			"next_attempt_at":  delivery.NextAttemptAt,
+			"last_status_code": delivery.LastStatusCode,
+			"last_error":       delivery.LastError,
+			"delivered_at":     delivery.DeliveredAt,
+		}).
+		Where(goqu.Ex{"id": delivery.ID}).
+		Returning("*").
+		ToSQL()
+	if err != nil {
+		return
+	}
+
+	if err = tx.QueryRowxContext(ctx, query).StructScan(&doc); err != nil {
+		err = pkg.NewDatabaseError(err)
+		return
+	}
+	return
+}
+
+func (r *WebhookRepository) CreateWebhookAttempt(ctx context.Context, attempt model.WebhookAttempt, tx DBTX) (doc model.WebhookAttempt, err error) {
+	query, _, err := goqu.Insert(goqu.T(pkg.TABLE_WEBHOOK_ATTEMPTS).Schema(pkg.SCHEMA_NAME)).
+		Rows(attempt).
+		Returning("*").
+		ToSQL()
+	if err != nil {
+		return
+	}
+
+	if err = tx.QueryRowxContext(ctx, query).StructScan(&doc); err != nil {
+		err = pkg.NewDatabaseError(err)
+		return
+	}
+	return
+}
+
+func (r *WebhookRepository) GetAllWebhookAttemptsByDeliveryID(ctx context.Context, deliveryID string, tx DBTX) (docs []model.WebhookAttempt, err error) {
+	query, _, err := goqu.From(goqu.T(pkg.TABLE_WEBHOOK_ATTEMPTS).Schema(pkg.SCHEMA_NAME)).
+		Where(goqu.Ex{"delivery_id": deliveryID}).
+		Order(goqu.I("attempted_at").Asc()).
+		ToSQL()
+	if err != nil {
+		return
+	}
+
+	if err = tx.SelectContext(ctx, &docs, query); err != nil {
+		err = pkg.NewDatabaseError(err)
+		return
+	}
+	return
+}
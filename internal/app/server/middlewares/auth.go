@@ -1,8 +1,10 @@
 package middlewares
 
 import (
+	"context"
 	"edukita-teaching-grading/internal/app/model"
 	"edukita-teaching-grading/internal/app/payload"
+	"edukita-teaching-grading/internal/app/repository"
 	"edukita-teaching-grading/internal/pkg"
 	"fmt"
 	"strings"
@@ -16,12 +18,14 @@ import (
 type AuthMiddleware struct {
 	Secret string
 	pkg.OptionsApplication
+	Repository *repository.Repository
 }
 
-func NewAuthMiddleware(optionsApp pkg.OptionsApplication) AuthMiddleware {
+func NewAuthMiddleware(optionsApp pkg.OptionsApplication, repo *repository.Repository) AuthMiddleware {
 	return AuthMiddleware{
 		Secret:             optionsApp.Config.Application.Secret,
 		OptionsApplication: optionsApp,
+		Repository:         repo,
 	}
 }
 
@@ -63,7 +67,33 @@ func (m *AuthMiddleware) AuthenticateJWT() fiber.Handler {
 			})
 		}
 
+		revoked, err := m.isTokenRevoked(c.Context(), myClaims.ID)
+		if err != nil {
+			m.Logger.Errorf(fmt.Sprintf("failed to check token revocation: %s", err.Error()), zap.Error(err))
+			return c.Status(fiber.StatusInternalServerError).JSON(payload.BaseResponse{
+				Status:  fiber.StatusInternalServerError,
+				Message: "failed to validate token",
+			})
+		}
+		if revoked {
+			m.Logger.Warnf("revoked token used: %s", myClaims.ID)
+			return c.Status(fiber.StatusUnauthorized).JSON(payload.BaseResponse{
+				Status:  fiber.StatusUnauthorized,
+				Message: "token has been revoked",
+			})
+		}
+
+		actor := pkg.Actor{
+			ID:      myClaims.UUID,
+			Role:    myClaims.Role,
+			TokenID: myClaims.ID,
+		}
+		if myClaims.ExpiresAt != nil {
+			actor.ExpiresAt = myClaims.ExpiresAt.Time
+		}
+
 		c.Locals("mw.auth.claims", myClaims)
+		c.SetUserContext(pkg.WithActor(c.UserContext(), actor))
 		return c.Next()
 	}
 }
@@ -125,6 +155,16 @@ func (m *AuthMiddleware) extractClaims(tokenString string) (*jwt.Token, error) {
 	return cleanedClaims, nil
 }
 
+func (m *AuthMiddleware) isTokenRevoked(ctx context.Context, tokenID string) (revoked bool, err error) {
+	if tokenID == "" {
+		// every token we issue carries a jti, a token without one cannot be revoked and is rejected
+		return true, nil
+	}
+
+	// a single lookup needs no transaction, it runs straight on the pool
+	return m.Repository.Auth.IsTokenRevoked(ctx, tokenID, m.Repository.Tx.Conn(ctx))
+}
+
 func claimToModelJWTToken(claims jwt.MapClaims) (model.JWTToken, error) {
 	myClaim := model.JWTToken{}
 	errMarshall := mapstructure.Decode(claims, &myClaim)
@@ -132,5 +172,16 @@ func claimToModelJWTToken(claims jwt.MapClaims) (model.JWTToken, error) {
 		return myClaim, errMarshall
 	}
 
+	// registered claims are embedded, so mapstructure does not pick them up by their json names
+	if jti, ok := claims["jti"].(string); ok {
+		myClaim.ID = jti
+	}
+	if exp, err := claims.GetExpirationTime(); err == nil {
+		myClaim.ExpiresAt = exp
+	}
+	if iat, err := claims.GetIssuedAt(); err == nil {
+		myClaim.IssuedAt = iat
+	}
+
 	return myClaim, nil
 }
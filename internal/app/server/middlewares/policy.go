@@ -0,0 +1,46 @@
+package middlewares
+
+import (
+	"edukita-teaching-grading/internal/app/model"
+	"edukita-teaching-grading/internal/app/payload"
+	"edukita-teaching-grading/internal/app/policy"
+	"edukita-teaching-grading/internal/pkg"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type PolicyMiddleware struct {
+	Policy *policy.Policy
+	pkg.OptionsApplication
+}
+
+func NewPolicyMiddleware(optionsApp pkg.OptionsApplication, p *policy.Policy) PolicyMiddleware {
+	return PolicyMiddleware{
+		Policy:             p,
+		OptionsApplication: optionsApp,
+	}
+}
+
+// Require rejects the request unless the role of the authenticated user is granted the action.
+// Ownership of the target resource is enforced by the services once the resource is loaded.
+func (m *PolicyMiddleware) Require(action policy.Action) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		claim, ok := c.Locals("mw.auth.claims").(model.JWTToken)
+		if !ok || claim.UUID == "" {
+			return c.Status(fiber.StatusUnauthorized).JSON(payload.BaseResponse{
+				Status:  fiber.StatusUnauthorized,
+				Message: "unauthorized",
+			})
+		}
+
+		if !m.Policy.AllowsRole(claim.Role, action) {
+			m.Logger.Warnf("role %s is not allowed to %s", claim.Role, action)
+			return c.Status(fiber.StatusForbidden).JSON(payload.BaseResponse{
+				Status:  fiber.StatusForbidden,
+				Message: "forbidden",
+			})
+		}
+
+		return c.Next()
+	}
+}
@@ -0,0 +1,21 @@
+package middlewares
+
+import (
+	"edukita-teaching-grading/internal/pkg"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
+)
+
+// RequestContext copies the request id and client IP into the user context so services can record them.
+// It must run after the requestid middleware.
+func RequestContext() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id, _ := c.Locals(requestid.ConfigDefault.ContextKey).(string)
+		c.SetUserContext(pkg.WithRequestMeta(c.UserContext(), pkg.RequestMeta{
+			ID: id,
+			IP: c.IP(),
+		}))
+		return c.Next()
+	}
+}
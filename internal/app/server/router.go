@@ -2,6 +2,7 @@ package server
 
 import (
 	"edukita-teaching-grading/internal/app/handler"
+	"edukita-teaching-grading/internal/app/policy"
 	"edukita-teaching-grading/internal/app/server/middlewares"
 
 	"github.com/gofiber/fiber/v2"
@@ -10,33 +11,144 @@ import (
 func Router(option handler.HandlerOptions, f *fiber.App) {
 	user := handler.UserHandler{HandlerOptions: option}
 	lms := handler.LMSHandler{HandlerOptions: option}
+	attachment := handler.AttachmentHandler{HandlerOptions: option}
+	rubric := handler.RubricHandler{HandlerOptions: option}
+	gradebook := handler.GradebookHandler{HandlerOptions: option}
+	audit := handler.AuditHandler{HandlerOptions: option}
+	webhook := handler.WebhookHandler{HandlerOptions: option}
+	notification := handler.NotificationHandler{HandlerOptions: option}
+	realtime := handler.RealtimeHandler{HandlerOptions: option}
+	scheduler := handler.SchedulerHandler{HandlerOptions: option}
+	job := handler.JobHandler{HandlerOptions: option}
 
-	authMiddleware := middlewares.NewAuthMiddleware(option.OptionsApplication)
+	authMiddleware := middlewares.NewAuthMiddleware(option.OptionsApplication, option.Repository)
+	policyMiddleware := middlewares.NewPolicyMiddleware(option.OptionsApplication, option.Policy)
 	v1 := f.Group("/api/v1")
 
 	userGroup := v1.Group("/user")
 	userGroup.Post("/register", user.RegisterUser)
 	userGroup.Post("/login", user.LoginUser)
+	userGroup.Post("/login/mfa", user.LoginMFA)
+	userGroup.Post("/login/mfa/enroll", user.EnrollMFAChallenge)
 	userGroup.Post("/logout", authMiddleware.AuthenticateJWT(), user.LogoutUser)
+	userGroup.Post("/token/refresh", user.RefreshToken)
+	userGroup.Post("/password/forgot", user.ForgotPassword)
+	userGroup.Post("/password/reset", user.ResetPassword)
+	userGroup.Post("/password/change", authMiddleware.AuthenticateJWT(), user.ChangePassword)
+	userGroup.Post("/email/verify", user.VerifyEmail)
+	userGroup.Post("/email/verify/resend", user.ResendVerification)
+	userGroup.Get("/mfa", authMiddleware.AuthenticateJWT(), user.GetMFAStatus)
+	userGroup.Post("/mfa/enroll", authMiddleware.AuthenticateJWT(), user.EnrollMFA)
+	userGroup.Post("/mfa/verify", authMiddleware.AuthenticateJWT(), user.VerifyMFA)
+	userGroup.Post("/mfa/recovery-codes", authMiddleware.AuthenticateJWT(), user.RegenerateRecoveryCodes)
+	userGroup.Post("/mfa/disable", authMiddleware.AuthenticateJWT(), user.DisableMFA)
+	userGroup.Post("/:id/sessions/revoke", authMiddleware.AuthenticateJWT(), policyMiddleware.Require(policy.SessionRevoke), user.RevokeUserSessions)
 	userGroup.Get("/me", authMiddleware.AuthenticateJWT(), user.GetUserByID)
 	userGroup.Get("/:id", authMiddleware.AuthenticateJWT(), user.GetUserByID)
+	userGroup.Delete("/:id", authMiddleware.AuthenticateJWT(), policyMiddleware.Require(policy.UserDelete), user.DeleteUserByID)
+	userGroup.Post("/:id/restore", authMiddleware.AuthenticateJWT(), policyMiddleware.Require(policy.RecordRestore), user.RestoreUserByID)
+	userGroup.Delete("/:id/purge", authMiddleware.AuthenticateJWT(), policyMiddleware.Require(policy.RecordPurge), user.PurgeUserByID)
 
 	lmsGroup := v1.Group("/lms")
-	lmsGroup.Post("/courses", authMiddleware.AuthenticateJWT(), lms.CreateCourse)
-	lmsGroup.Get("/courses/:id", authMiddleware.AuthenticateJWT(), lms.GetCourseByID)
-	lmsGroup.Get("/courses/:code", authMiddleware.AuthenticateJWT(), lms.GetCourseByCode)
-	lmsGroup.Get("/courses", authMiddleware.AuthenticateJWT(), lms.GetAllCourses)
-	lmsGroup.Put("/courses/:id", authMiddleware.AuthenticateJWT(), lms.UpdateCourseByID)
+	lmsGroup.Post("/courses", authMiddleware.AuthenticateJWT(), policyMiddleware.Require(policy.CourseCreate), lms.CreateCourse)
+	lmsGroup.Get("/courses/:id", authMiddleware.AuthenticateJWT(), policyMiddleware.Require(policy.CourseRead), lms.GetCourseByID)
+	lmsGroup.Get("/courses/:code", authMiddleware.AuthenticateJWT(), policyMiddleware.Require(policy.CourseRead), lms.GetCourseByCode)
+	lmsGroup.Get("/courses", authMiddleware.AuthenticateJWT(), policyMiddleware.Require(policy.CourseRead), lms.GetAllCourses)
+	lmsGroup.Put("/courses/:id", authMiddleware.AuthenticateJWT(), policyMiddleware.Require(policy.CourseUpdate), lms.UpdateCourseByID)
+	lmsGroup.Delete("/courses/:id", authMiddleware.AuthenticateJWT(), policyMiddleware.Require(policy.CourseDelete), lms.DeleteCourseByID)
+	lmsGroup.Post("/courses/:id/restore", authMiddleware.AuthenticateJWT(), policyMiddleware.Require(policy.RecordRestore), lms.RestoreCourseByID)
+	lmsGroup.Delete("/courses/:id/purge", authMiddleware.AuthenticateJWT(), policyMiddleware.Require(policy.RecordPurge), lms.PurgeCourseByID)
 
-	lmsGroup.Post("/assignments", authMiddleware.AuthenticateJWT(), lms.CreateAssignment)
-	lmsGroup.Get("/assignments/:id", authMiddleware.AuthenticateJWT(), lms.GetAssignmentByID)
-	lmsGroup.Put("/assignments/:id", authMiddleware.AuthenticateJWT(), lms.UpdateAssignmentByID)
+	lmsGroup.Post("/courses/:id/teachers", authMiddleware.AuthenticateJWT(), policyMiddleware.Require(policy.CourseTeacherManage), lms.AddCourseTeacher)
+	lmsGroup.Get("/courses/:id/teachers", authMiddleware.AuthenticateJWT(), policyMiddleware.Require(policy.CourseRead), lms.GetAllCourseTeachersByCourseID)
+	lmsGroup.Delete("/courses/:id/teachers/:teacher_id", authMiddleware.AuthenticateJWT(), policyMiddleware.Require(policy.CourseTeacherManage), lms.RemoveCourseTeacher)
 
-	lmsGroup.Post("/submissions", authMiddleware.AuthenticateJWT(), lms.CreateSubmission)
+	lmsGroup.Post("/courses/:id/categories", authMiddleware.AuthenticateJWT(), policyMiddleware.Require(policy.CourseUpdate), gradebook.CreateCategory)
+	lmsGroup.Get("/courses/:id/categories", authMiddleware.AuthenticateJWT(), policyMiddleware.Require(policy.CourseRead), gradebook.GetAllCategoriesByCourseID)
+	lmsGroup.Put("/courses/:id/categories/:category_id", authMiddleware.AuthenticateJWT(), policyMiddleware.Require(policy.CourseUpdate), gradebook.UpdateCategoryByID)
+	lmsGroup.Delete("/courses/:id/categories/:category_id", authMiddleware.AuthenticateJWT(), policyMiddleware.Require(policy.CourseUpdate), gradebook.DeleteCategoryByID)
+
+	lmsGroup.Get("/courses/:id/gradebook", authMiddleware.AuthenticateJWT(), policyMiddleware.Require(policy.GradebookRead), gradebook.GetCourseGradebook)
+	lmsGroup.Get("/courses/:id/gradebook/me", authMiddleware.AuthenticateJWT(), policyMiddleware.Require(policy.GradebookRead), gradebook.GetStudentGradebook)
+	lmsGroup.Get("/courses/:id/gradebook/students/:student_id", authMiddleware.AuthenticateJWT(), policyMiddleware.Require(policy.GradebookRead), gradebook.GetStudentGradebook)
+
+	lmsGroup.Post("/courses/:id/enrollments", authMiddleware.AuthenticateJWT(), policyMiddleware.Require(policy.EnrollmentManage), lms.EnrollStudent)
+	lmsGroup.Get("/courses/:id/enrollments", authMiddleware.AuthenticateJWT(), policyMiddleware.Require(policy.EnrollmentRead), lms.GetAllEnrollmentsByCourseID)
+	lmsGroup.Delete("/courses/:id/enrollments/:student_id", authMiddleware.AuthenticateJWT(), policyMiddleware.Require(policy.EnrollmentManage), lms.DropEnrollment)
+
+	lmsGroup.Post("/assignments", authMiddleware.AuthenticateJWT(), policyMiddleware.Require(policy.AssignmentCreate), lms.CreateAssignment)
+	lmsGroup.Get("/assignments/:id", authMiddleware.AuthenticateJWT(), policyMiddleware.Require(policy.AssignmentRead), lms.GetAssignmentByID)
+	lmsGroup.Put("/assignments/:id", authMiddleware.AuthenticateJWT(), policyMiddleware.Require(policy.AssignmentUpdate), lms.UpdateAssignmentByID)
+	lmsGroup.Delete("/assignments/:id", authMiddleware.AuthenticateJWT(), policyMiddleware.Require(policy.AssignmentDelete), lms.DeleteAssignmentByID)
+	lmsGroup.Post("/assignments/:id/restore", authMiddleware.AuthenticateJWT(), policyMiddleware.Require(policy.RecordRestore), lms.RestoreAssignmentByID)
+	lmsGroup.Delete("/assignments/:id/purge", authMiddleware.AuthenticateJWT(), policyMiddleware.Require(policy.RecordPurge), lms.PurgeAssignmentByID)
+
+	lmsGroup.Post("/submissions", authMiddleware.AuthenticateJWT(), policyMiddleware.Require(policy.SubmissionCreate), lms.CreateSubmission)
 	lmsGroup.Get("/submissions/course/:id", authMiddleware.AuthenticateJWT(), lms.GetAllSubmissionsByCourseID)
-	lmsGroup.Get("/submissions/:id", authMiddleware.AuthenticateJWT(), lms.GetSubmissionByID)
+	lmsGroup.Get("/submissions/:id", authMiddleware.AuthenticateJWT(), policyMiddleware.Require(policy.SubmissionRead), lms.GetSubmissionByID)
 	lmsGroup.Put("/submissions/:id", authMiddleware.AuthenticateJWT(), lms.UpdateSubmissionByID)
+	lmsGroup.Delete("/submissions/:id", authMiddleware.AuthenticateJWT(), policyMiddleware.Require(policy.SubmissionDelete), lms.DeleteSubmissionByID)
+	lmsGroup.Post("/submissions/:id/restore", authMiddleware.AuthenticateJWT(), policyMiddleware.Require(policy.RecordRestore), lms.RestoreSubmissionByID)
+	lmsGroup.Delete("/submissions/:id/purge", authMiddleware.AuthenticateJWT(), policyMiddleware.Require(policy.RecordPurge), lms.PurgeSubmissionByID)
+	lmsGroup.Get("/submissions/:id/attempts", authMiddleware.AuthenticateJWT(), policyMiddleware.Require(policy.SubmissionRead), lms.GetAllSubmissionAttempts)
+	lmsGroup.Get("/submissions/:id/attempts/diff", authMiddleware.AuthenticateJWT(), policyMiddleware.Require(policy.SubmissionRead), lms.DiffSubmissionAttempts)
+
+	lmsGroup.Get("/submissions/assignments/:id", authMiddleware.AuthenticateJWT(), policyMiddleware.Require(policy.SubmissionReview), lms.GetAllSubmissionsByAssignmentID)
+	lmsGroup.Get("/submissions/users/:id", authMiddleware.AuthenticateJWT(), policyMiddleware.Require(policy.SubmissionRead), lms.GetAllSubmissionsByUserID)
+
+	lmsGroup.Post("/submissions/:id/attachments", authMiddleware.AuthenticateJWT(), policyMiddleware.Require(policy.SubmissionUpdate), attachment.UploadSubmissionAttachment)
+	lmsGroup.Get("/submissions/:id/attachments", authMiddleware.AuthenticateJWT(), policyMiddleware.Require(policy.SubmissionRead), attachment.GetAllAttachmentsBySubmissionID)
+	lmsGroup.Post("/assignments/:id/attachments", authMiddleware.AuthenticateJWT(), policyMiddleware.Require(policy.AssignmentUpdate), attachment.UploadAssignmentAttachment)
+	lmsGroup.Get("/assignments/:id/attachments", authMiddleware.AuthenticateJWT(), policyMiddleware.Require(policy.AssignmentRead), attachment.GetAllAttachmentsByAssignmentID)
+	lmsGroup.Get("/attachments/:id", authMiddleware.AuthenticateJWT(), attachment.GetAttachmentByID)
+	// signed link, the signature replaces the session
+	lmsGroup.Get("/attachments/:id/download", attachment.DownloadAttachment)
+
+	lmsGroup.Post("/rubrics", authMiddleware.AuthenticateJWT(), policyMiddleware.Require(policy.RubricCreate), rubric.CreateRubric)
+	lmsGroup.Get("/rubrics", authMiddleware.AuthenticateJWT(), policyMiddleware.Require(policy.RubricRead), rubric.GetAllRubrics)
+	lmsGroup.Get("/rubrics/:id", authMiddleware.AuthenticateJWT(), policyMiddleware.Require(policy.RubricRead), rubric.GetRubricByID)
+
+	lmsGroup.Post("/grading-schemes", authMiddleware.AuthenticateJWT(), policyMiddleware.Require(policy.GradingSchemeCreate), gradebook.CreateGradingScheme)
+	lmsGroup.Get("/grading-schemes", authMiddleware.AuthenticateJWT(), policyMiddleware.Require(policy.GradingSchemeRead), gradebook.GetAllGradingSchemes)
+	lmsGroup.Get("/grading-schemes/:id", authMiddleware.AuthenticateJWT(), policyMiddleware.Require(policy.GradingSchemeRead), gradebook.GetGradingSchemeByID)
+
+	lmsGroup.Get("/transcripts/me", authMiddleware.AuthenticateJWT(), policyMiddleware.Require(policy.TranscriptRead), gradebook.GetStudentTranscript)
+	lmsGroup.Get("/transcripts/students/:student_id", authMiddleware.AuthenticateJWT(), policyMiddleware.Require(policy.TranscriptRead), gradebook.GetStudentTranscript)
+
+	auditGroup := v1.Group("/audit")
+	auditGroup.Get("/events", authMiddleware.AuthenticateJWT(), policyMiddleware.Require(policy.AuditRead), audit.GetAllAuditEvents)
+	auditGroup.Get("/verify", authMiddleware.AuthenticateJWT(), policyMiddleware.Require(policy.AuditVerify), audit.VerifyAuditChain)
+
+	schedulerGroup := v1.Group("/scheduler")
+	schedulerGroup.Get("/runs", authMiddleware.AuthenticateJWT(), policyMiddleware.Require(policy.SchedulerRead), scheduler.GetAllScheduledJobRuns)
+
+	jobGroup := v1.Group("/jobs")
+	jobGroup.Get("/", authMiddleware.AuthenticateJWT(), policyMiddleware.Require(policy.JobManage), job.GetAllJobs)
+	jobGroup.Get("/stats", authMiddleware.AuthenticateJWT(), policyMiddleware.Require(policy.JobManage), job.GetJobStats)
+	jobGroup.Get("/:id", authMiddleware.AuthenticateJWT(), policyMiddleware.Require(policy.JobManage), job.GetJobByID)
+	jobGroup.Post("/:id/retry", authMiddleware.AuthenticateJWT(), policyMiddleware.Require(policy.JobManage), job.RetryJob)
+
+	webhookGroup := v1.Group("/webhooks")
+	webhookGroup.Post("/", authMiddleware.AuthenticateJWT(), policyMiddleware.Require(policy.WebhookManage), webhook.CreateWebhook)
+	webhookGroup.Get("/", authMiddleware.AuthenticateJWT(), policyMiddleware.Require(policy.WebhookManage), webhook.GetAllWebhooks)
+	webhookGroup.Get("/deliveries/:delivery_id", authMiddleware.AuthenticateJWT(), policyMiddleware.Require(policy.WebhookManage), webhook.GetWebhookDeliveryByID)
+	webhookGroup.Post("/deliveries/:delivery_id/redeliver", authMiddleware.AuthenticateJWT(), policyMiddleware.Require(policy.WebhookManage), webhook.RedeliverWebhookDelivery)
+	webhookGroup.Get("/:id", authMiddleware.AuthenticateJWT(), policyMiddleware.Require(policy.WebhookManage), webhook.GetWebhookByID)
+	webhookGroup.Put("/:id", authMiddleware.AuthenticateJWT(), policyMiddleware.Require(policy.WebhookManage), webhook.UpdateWebhookByID)
+	webhookGroup.Delete("/:id", authMiddleware.AuthenticateJWT(), policyMiddleware.Require(policy.WebhookManage), webhook.DeleteWebhookByID)
+	webhookGroup.Post("/:id/secret", authMiddleware.AuthenticateJWT(), policyMiddleware.Require(policy.WebhookManage), webhook.RotateWebhookSecret)
+	webhookGroup.Get("/:id/deliveries", authMiddleware.AuthenticateJWT(), policyMiddleware.Require(policy.WebhookManage), webhook.GetAllWebhookDeliveries)
+
+	// every user reads and manages only their own notifications
+	notificationGroup := v1.Group("/notifications")
+	notificationGroup.Get("/", authMiddleware.AuthenticateJWT(), notification.GetAllNotifications)
+	notificationGroup.Post("/read-all", authMiddleware.AuthenticateJWT(), notification.MarkAllNotificationsRead)
+	notificationGroup.Get("/preferences", authMiddleware.AuthenticateJWT(), notification.GetNotificationPreferences)
+	notificationGroup.Put("/preferences", authMiddleware.AuthenticateJWT(), notification.UpdateNotificationPreferences)
+	notificationGroup.Post("/:id/read", authMiddleware.AuthenticateJWT(), notification.MarkNotificationRead)
 
-	lmsGroup.Get("/submissions/assignments/:id", authMiddleware.AuthenticateJWT(), lms.GetAllSubmissionsByAssignmentID)
-	lmsGroup.Get("/submissions/users/:id", authMiddleware.AuthenticateJWT(), lms.GetAllSubmissionsByUserID)
+	// every user only receives the events of their own courses, see RealtimeService
+	realtimeGroup := v1.Group("/realtime")
+	realtimeGroup.Get("/events", authMiddleware.AuthenticateJWT(), realtime.Stream)
+	realtimeGroup.Get("/ws", authMiddleware.AuthenticateJWT(), realtime.UpgradeWebSocket, realtime.WebSocket())
 }
@@ -1,21 +1,25 @@
 package server
 
 import (
+	"context"
 	"fmt"
 
 	"edukita-teaching-grading/internal/app/handler"
+	"edukita-teaching-grading/internal/app/policy"
 	"edukita-teaching-grading/internal/app/repository"
+	"edukita-teaching-grading/internal/app/server/middlewares"
 	"edukita-teaching-grading/internal/app/service"
 	"edukita-teaching-grading/internal/pkg"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
 	"github.com/gofiber/fiber/v2/middleware/recover"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
 	"go.uber.org/zap"
 )
 
 type IServer interface {
-	ServerRun()
+	ServerRun(ctx context.Context)
 }
 
 type server struct {
@@ -23,24 +27,33 @@ type server struct {
 	Service    *service.Service
 	Logger     *zap.SugaredLogger
 	Repository *repository.Repository
+	Policy     *policy.Policy
 }
 
 // NewServer create object server
-func NewServer(opt pkg.OptionsApplication, svc *service.Service, repo *repository.Repository) IServer {
+func NewServer(opt pkg.OptionsApplication, svc *service.Service, repo *repository.Repository, rbac *policy.Policy) IServer {
 	return &server{
 		Option:     opt,
 		Service:    svc,
 		Logger:     opt.Logger,
 		Repository: repo,
+		Policy:     rbac,
 	}
 }
 
-func (s *server) ServerRun() {
+// ServerRun serves until ctx is cancelled, then stops accepting connections and gives the in-flight requests the
+// shutdown timeout to finish
+func (s *server) ServerRun(ctx context.Context) {
 	pkg.SwaggerInfo(s.Option.Config)
 
-	f := fiber.New()
+	f := fiber.New(fiber.Config{
+		// leave room for the multipart envelope around the largest allowed upload
+		BodyLimit: int(s.Option.Config.Storage.MaxUploadSize) + 1<<20,
+	})
 
 	f.Use(recover.New())
+	f.Use(requestid.New())
+	f.Use(middlewares.RequestContext())
 	// CORS
 	f.Use(cors.New(cors.Config{
 		AllowMethods: "GET,POST,HEAD,PUT,DELETE,PATCH",
@@ -50,19 +63,23 @@ func (s *server) ServerRun() {
 		OptionsApplication: s.Option,
 		Service:            s.Service,
 		Repository:         s.Repository,
+		Policy:             s.Policy,
 	}, f)
 
 	address := fmt.Sprintf(":%v", s.Option.Config.Application.Port)
 
-	// Start the server
+	// Gracefully shut down the server when the application is shutting down
+	go func() {
+		<-ctx.Done()
+		if err := f.ShutdownWithTimeout(s.Option.Config.Application.ShutdownTimeout); err != nil {
+			s.Option.Logger.Errorf("Failed to shut down server gracefully: %v", err)
+		} else {
+			s.Option.Logger.Info("Server shut down gracefully")
+		}
+	}()
+
+	// Start the server, Listen returns once it is shut down
 	if err := f.Listen(address); err != nil {
 		s.Option.Logger.Fatalf("failed to listen: %v", err)
 	}
-
-	// Gracefully shut down the server when the application is shutting down
-	if err := f.Shutdown(); err != nil {
-		s.Option.Logger.Errorf("Failed to shut down server gracefully: %v", err)
-	} else {
-		s.Option.Logger.Info("Server shut down gracefully")
-	}
 }
@@ -0,0 +1,404 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"slices"
+	"time"
+
+	"edukita-teaching-grading/internal/app/model"
+	"edukita-teaching-grading/internal/app/payload"
+	"edukita-teaching-grading/internal/app/policy"
+	"edukita-teaching-grading/internal/app/repository"
+	"edukita-teaching-grading/internal/pkg"
+	"edukita-teaching-grading/pkg/storage"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+type (
+	IAttachmentService interface {
+		UploadSubmissionAttachment(ctx context.Context, submissionID string, requestBody *payload.UploadAttachmentRequest) (response payload.AttachmentResponse, err error)
+		UploadAssignmentAttachment(ctx context.Context, assignmentID string, requestBody *payload.UploadAttachmentRequest) (response payload.AttachmentResponse, err error)
+		GetAttachmentByID(ctx context.Context, id string) (response payload.AttachmentResponse, err error)
+		GetAllAttachmentsBySubmissionID(ctx context.Context, submissionID string, query pkg.ListQuery) (response payload.GetAllAttachmentsResponse, meta payload.MetaResponse, err error)
+		GetAllAttachmentsByAssignmentID(ctx context.Context, assignmentID string, query pkg.ListQuery) (response payload.GetAllAttachmentsResponse, meta payload.MetaResponse, err error)
+		DownloadAttachment(ctx context.Context, id string, expires string, signature string) (response payload.DownloadAttachmentResponse, err error)
+	}
+	AttachmentService struct {
+		ServiceOption
+	}
+)
+
+func InitiateAttachmentService(opt ServiceOption) IAttachmentService {
+	return &AttachmentService{
+		ServiceOption: opt,
+	}
+}
+
+func (s *AttachmentService) UploadSubmissionAttachment(ctx context.Context, submissionID string, requestBody *payload.UploadAttachmentRequest) (response payload.AttachmentResponse, err error) {
+	var stored string
+	err = s.Repository.Tx.Run(ctx, repository.TxReadWrite, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+		user, err := s.currentUser(ctx, tx)
+		if err != nil {
+			return
+		}
+
+		submission, err := s.Repository.LearningManagement.GetSubmissionByID(ctx, submissionID, tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to get submission by id: %s", err.Error()), zap.Error(err))
+			return
+		}
+
+		owners, err := s.submissionOwners(ctx, submission, tx)
+		if err != nil {
+			return
+		}
+
+		if err = s.authorize(user, policy.SubmissionUpdate, owners); err != nil {
+			return
+		}
+
+		attachment := model.Attachment{
+			BaseModel: model.BaseModel{
+				ID:        uuid.New(),
+				CreatedBy: user.ID,
+				CreatedAt: time.Now(),
+			},
+			SubmissionID: &submission.ID,
+		}
+		attachment.StorageKey = fmt.Sprintf("submissions/%s/%s", submission.ID, attachment.ID)
+
+		if attachment, err = s.put(ctx, attachment, requestBody); err != nil {
+			return
+		}
+		stored = attachment.StorageKey
+
+		attachment, err = s.Repository.Attachment.CreateAttachment(ctx, attachment, tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to create attachment: %s", err.Error()), zap.Error(err))
+			return
+		}
+
+		courseID, err := s.submissionCourseID(ctx, submission, tx)
+		if err != nil {
+			return
+		}
+		if err = s.audit(ctx, tx, auditChange{
+			action:     pkg.AUDIT_ACTION_CREATE,
+			entityType: pkg.AUDIT_ENTITY_ATTACHMENT,
+			entityID:   attachment.ID,
+			courseID:   courseID,
+			after:      attachment,
+		}); err != nil {
+			return
+		}
+
+		response = s.attachmentToResponse(attachment)
+		return
+	})
+	if err != nil {
+		s.discard(ctx, stored)
+	}
+	return
+}
+
+func (s *AttachmentService) UploadAssignmentAttachment(ctx context.Context, assignmentID string, requestBody *payload.UploadAttachmentRequest) (response payload.AttachmentResponse, err error) {
+	var stored string
+	err = s.Repository.Tx.Run(ctx, repository.TxReadWrite, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+		user, err := s.currentUser(ctx, tx)
+		if err != nil {
+			return
+		}
+
+		assignment, err := s.Repository.LearningManagement.GetAssignmentByID(ctx, assignmentID, tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to get assignment by id: %s", err.Error()), zap.Error(err))
+			return
+		}
+
+		owners, err := s.assignmentOwners(ctx, assignment, tx)
+		if err != nil {
+			return
+		}
+
+		if err = s.authorize(user, policy.AssignmentUpdate, owners); err != nil {
+			return
+		}
+
+		attachment := model.Attachment{
+			BaseModel: model.BaseModel{
+				ID:        uuid.New(),
+				CreatedBy: user.ID,
+				CreatedAt: time.Now(),
+			},
+			AssignmentID: &assignment.ID,
+		}
+		attachment.StorageKey = fmt.Sprintf("assignments/%s/%s", assignment.ID, attachment.ID)
+
+		if attachment, err = s.put(ctx, attachment, requestBody); err != nil {
+			return
+		}
+		stored = attachment.StorageKey
+
+		attachment, err = s.Repository.Attachment.CreateAttachment(ctx, attachment, tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to create attachment: %s", err.Error()), zap.Error(err))
+			return
+		}
+
+		if err = s.audit(ctx, tx, auditChange{
+			action:     pkg.AUDIT_ACTION_CREATE,
+			entityType: pkg.AUDIT_ENTITY_ATTACHMENT,
+			entityID:   attachment.ID,
+			courseID:   &assignment.CourseID,
+			after:      attachment,
+		}); err != nil {
+			return
+		}
+
+		response = s.attachmentToResponse(attachment)
+		return
+	})
+	if err != nil {
+		s.discard(ctx, stored)
+	}
+	return
+}
+
+func (s *AttachmentService) GetAttachmentByID(ctx context.Context, id string) (response payload.AttachmentResponse, err error) {
+	return response, s.Repository.Tx.Run(ctx, repository.TxReadOnly, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+		user, err := s.currentUser(ctx, tx)
+		if err != nil {
+			return
+		}
+
+		attachment, err := s.Repository.Attachment.GetAttachmentByID(ctx, id, tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to get attachment by id: %s", err.Error()), zap.Error(err))
+			return
+		}
+
+		switch {
+		case attachment.SubmissionID != nil:
+			submission, err := s.Repository.LearningManagement.GetSubmissionByID(ctx, attachment.SubmissionID.String(), tx)
+			if err != nil {
+				s.Logger.Warnf(fmt.Sprintf("failed to get submission by id: %s", err.Error()), zap.Error(err))
+				return err
+			}
+			owners, err := s.submissionOwners(ctx, submission, tx)
+			if err != nil {
+				return err
+			}
+			if err = s.authorize(user, policy.SubmissionRead, owners); err != nil {
+				return err
+			}
+		default:
+			assignment, err := s.Repository.LearningManagement.GetAssignmentByID(ctx, attachment.AssignmentID.String(), tx)
+			if err != nil {
+				s.Logger.Warnf(fmt.Sprintf("failed to get assignment by id: %s", err.Error()), zap.Error(err))
+				return err
+			}
+			if err = s.authorizeAssignmentRead(ctx, user, assignment, tx); err != nil {
+				return err
+			}
+		}
+
+		response = s.attachmentToResponse(attachment)
+		return
+	})
+}
+
+func (s *AttachmentService) GetAllAttachmentsBySubmissionID(ctx context.Context, submissionID string, query pkg.ListQuery) (response payload.GetAllAttachmentsResponse, meta payload.MetaResponse, err error) {
+	return response, meta, s.Repository.Tx.Run(ctx, repository.TxReadOnly, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+		user, err := s.currentUser(ctx, tx)
+		if err != nil {
+			return
+		}
+
+		submission, err := s.Repository.LearningManagement.GetSubmissionByID(ctx, submissionID, tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to get submission by id: %s", err.Error()), zap.Error(err))
+			return
+		}
+
+		owners, err := s.submissionOwners(ctx, submission, tx)
+		if err != nil {
+			return
+		}
+
+		if err = s.authorize(user, policy.SubmissionRead, owners); err != nil {
+			return
+		}
+
+		attachments, page, err := s.Repository.Attachment.ListAttachmentsBySubmissionID(ctx, submission.ID.String(), query, tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to get attachments by submission id: %s", err.Error()), zap.Error(err))
+			return
+		}
+		meta = pageToMeta(page)
+
+		response.Attachments = make([]payload.AttachmentResponse, len(attachments))
+		for i, attachment := range attachments {
+			response.Attachments[i] = s.attachmentToResponse(attachment)
+		}
+		return
+	})
+}
+
+func (s *AttachmentService) GetAllAttachmentsByAssignmentID(ctx context.Context, assignmentID string, query pkg.ListQuery) (response payload.GetAllAttachmentsResponse, meta payload.MetaResponse, err error) {
+	return response, meta, s.Repository.Tx.Run(ctx, repository.TxReadOnly, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+		user, err := s.currentUser(ctx, tx)
+		if err != nil {
+			return
+		}
+
+		assignment, err := s.Repository.LearningManagement.GetAssignmentByID(ctx, assignmentID, tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to get assignment by id: %s", err.Error()), zap.Error(err))
+			return
+		}
+
+		if err = s.authorizeAssignmentRead(ctx, user, assignment, tx); err != nil {
+			return
+		}
+
+		attachments, page, err := s.Repository.Attachment.ListAttachmentsByAssignmentID(ctx, assignment.ID.String(), query, tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to get attachments by assignment id: %s", err.Error()), zap.Error(err))
+			return
+		}
+		meta = pageToMeta(page)
+
+		response.Attachments = make([]payload.AttachmentResponse, len(attachments))
+		for i, attachment := range attachments {
+			response.Attachments[i] = s.attachmentToResponse(attachment)
+		}
+		return
+	})
+}
+
+// DownloadAttachment opens the stored file behind a signed link, the link itself is the authorization
+func (s *AttachmentService) DownloadAttachment(ctx context.Context, id string, expires string, signature string) (response payload.DownloadAttachmentResponse, err error) {
+	if err = s.URLSigner.Verify(id, expires, signature, time.Now()); err != nil {
+		s.Logger.Warnf("invalid download link for attachment %s", id, zap.Error(err))
+		return response, pkg.NewForbiddenError("invalid or expired download link", err)
+	}
+
+	var attachment model.Attachment
+	err = s.Repository.Tx.Run(ctx, repository.TxReadOnly, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+		attachment, err = s.Repository.Attachment.GetAttachmentByID(ctx, id, tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to get attachment by id: %s", err.Error()), zap.Error(err))
+		}
+		return
+	})
+	if err != nil {
+		return
+	}
+
+	content, err := s.Storage.Get(ctx, attachment.StorageKey)
+	if errors.Is(err, storage.ErrObjectNotFound) {
+		err = pkg.NewNotFoundError("attachment content not found", err)
+		s.Logger.Warnf("attachment content missing: %s", attachment.StorageKey, zap.Error(err))
+		return
+	}
+	if err != nil {
+		s.Logger.Errorf(fmt.Sprintf("failed to read attachment: %s", err.Error()), zap.Error(err))
+		return
+	}
+
+	response.FileName = attachment.FileName
+	response.ContentType = attachment.ContentType
+	response.SizeBytes = attachment.SizeBytes
+	response.Checksum = attachment.Checksum
+	response.Content = content
+	return
+}
+
+// put validates the upload and streams it to the blob store while hashing it, the attachment is returned with the
+// details of the file
+func (s *AttachmentService) put(ctx context.Context, attachment model.Attachment, requestBody *payload.UploadAttachmentRequest) (doc model.Attachment, err error) {
+	if requestBody.Size <= 0 {
+		err = pkg.NewBadRequestError("file is empty", nil)
+		s.Logger.Warnf("empty upload: %s", requestBody.FileName, zap.Error(err))
+		return
+	}
+	if requestBody.Size > s.Config.Storage.MaxUploadSize {
+		err = pkg.NewError("FILE_TOO_LARGE", fmt.Sprintf("file exceeds the %d bytes limit", s.Config.Storage.MaxUploadSize), http.StatusRequestEntityTooLarge, nil)
+		s.Logger.Warnf("upload too large: %d bytes", requestBody.Size, zap.Error(err))
+		return
+	}
+
+	// the content type is sniffed from the file itself, the one declared by the client is not trusted
+	head := make([]byte, 512)
+	n, err := io.ReadFull(requestBody.Content, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		s.Logger.Warnf(fmt.Sprintf("failed to read upload: %s", err.Error()), zap.Error(err))
+		return
+	}
+	head = head[:n]
+
+	contentType, _, err := mime.ParseMediaType(http.DetectContentType(head))
+	if err != nil || !slices.Contains(s.Config.Storage.AllowedContentTypes, contentType) {
+		err = pkg.NewError("UNSUPPORTED_MEDIA_TYPE", fmt.Sprintf("content type %s is not allowed", contentType), http.StatusUnsupportedMediaType, err)
+		s.Logger.Warnf("rejected upload content type: %s", contentType, zap.Error(err))
+		return
+	}
+
+	hash := sha256.New()
+	body := io.TeeReader(io.MultiReader(bytes.NewReader(head), requestBody.Content), hash)
+	if err = s.Storage.Put(ctx, attachment.StorageKey, body, requestBody.Size, contentType); err != nil {
+		s.Logger.Errorf(fmt.Sprintf("failed to store attachment: %s", err.Error()), zap.Error(err))
+		return
+	}
+
+	attachment.FileName = requestBody.FileName
+	attachment.ContentType = contentType
+	attachment.SizeBytes = requestBody.Size
+	attachment.Checksum = hex.EncodeToString(hash.Sum(nil))
+	return attachment, nil
+}
+
+// discard deletes the blob of an upload whose transaction did not commit, so no object is left without its row.
+// The request may have been cancelled, so the delete does not depend on its context.
+func (s *AttachmentService) discard(ctx context.Context, key string) {
+	if key == "" {
+		return
+	}
+	if err := s.Storage.Delete(context.WithoutCancel(ctx), key); err != nil {
+		s.Logger.Errorf(fmt.Sprintf("failed to delete orphaned attachment %s: %s", key, err.Error()), zap.Error(err))
+	}
+}
+
+func (s *AttachmentService) attachmentToResponse(attachment model.Attachment) (response payload.AttachmentResponse) {
+	link, expiresAt := s.URLSigner.Sign(attachment.ID.String(), time.Now())
+
+	response.ID = attachment.ID.String()
+	if attachment.SubmissionID != nil {
+		submissionID := attachment.SubmissionID.String()
+		response.SubmissionID = &submissionID
+	}
+	if attachment.AssignmentID != nil {
+		assignmentID := attachment.AssignmentID.String()
+		response.AssignmentID = &assignmentID
+	}
+	response.FileName = attachment.FileName
+	response.ContentType = attachment.ContentType
+	response.SizeBytes = attachment.SizeBytes
+	response.Checksum = attachment.Checksum
+	response.DownloadURL = link
+	response.DownloadExpiresAt = expiresAt.Format(time.RFC3339)
+	response.CreatedAt = attachment.CreatedAt.Format(time.RFC3339)
+	response.CreatedBy = attachment.CreatedBy.String()
+	return
+}
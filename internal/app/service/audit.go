@@ -0,0 +1,362 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"edukita-teaching-grading/internal/app/model"
+	"edukita-teaching-grading/internal/app/payload"
+	"edukita-teaching-grading/internal/app/policy"
+	"edukita-teaching-grading/internal/app/repository"
+	"edukita-teaching-grading/internal/pkg"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+type (
+	IAuditService interface {
+		GetAllAuditEvents(ctx context.Context, query pkg.ListQuery) (response payload.GetAllAuditEventsResponse, meta payload.MetaResponse, err error)
+		VerifyAuditChain(ctx context.Context) (response payload.AuditVerificationResponse, err error)
+	}
+	AuditService struct {
+		ServiceOption
+	}
+)
+
+func InitiateAuditService(opt ServiceOption) IAuditService {
+	return &AuditService{
+		ServiceOption: opt,
+	}
+}
+
+// auditVerifyBatchSize is how many events are loaded at a time while walking the chain
+const auditVerifyBatchSize = 500
+
+// auditChange is a change to an entity to record in the audit log. Before and After are the entity around the
+// change and are stored as JSON, either may be nil.
+type auditChange struct {
+	action     string
+	entityType string
+	entityID   uuid.UUID
+	courseID   *uuid.UUID
+	before     any
+	after      any
+}
+
+// audit appends the change to the audit chain. It runs inside the transaction of the change, so the event is
+// kept exactly when the change commits, and holds the chain head until then.
+func (o ServiceOption) audit(ctx context.Context, tx *sqlx.Tx, change auditChange) (err error) {
+	event := model.AuditEvent{
+		ID:         uuid.New(),
+		Action:     change.action,
+		EntityType: change.entityType,
+		EntityID:   change.entityID,
+		CourseID:   change.courseID,
+		// the database keeps microseconds, the hash must see the same instant it will read back
+		CreatedAt: time.Now().UTC().Truncate(time.Microsecond),
+	}
+
+	// self-registration has no actor
+	if actor, ok := pkg.ActorFromContext(ctx); ok {
+		if actorID, err := uuid.Parse(actor.ID); err == nil {
+			event.ActorID = &actorID
+		}
+		event.ActorRole = &actor.Role
+	}
+	if meta := pkg.RequestMetaFromContext(ctx); meta.ID != "" || meta.IP != "" {
+		event.IP = &meta.IP
+		event.RequestID = &meta.ID
+	}
+
+	if event.Before, err = auditState(change.before); err != nil {
+		o.Logger.Errorf(fmt.Sprintf("failed to encode audit state: %s", err.Error()), zap.Error(err))
+		return
+	}
+	if event.After, err = auditState(change.after); err != nil {
+		o.Logger.Errorf(fmt.Sprintf("failed to encode audit state: %s", err.Error()), zap.Error(err))
+		return
+	}
+
+	head, err := o.Repository.Audit.LockAuditChainHead(ctx, tx)
+	if err != nil {
+		o.Logger.Errorf(fmt.Sprintf("failed to lock audit chain head: %s", err.Error()), zap.Error(err))
+		return
+	}
+
+	event.Seq = head.Seq + 1
+	event.PrevHash = head.Hash
+	if event.Hash, err = auditHash(event); err != nil {
+		o.Logger.Errorf(fmt.Sprintf("failed to hash audit event: %s", err.Error()), zap.Error(err))
+		return
+	}
+
+	if _, err = o.Repository.Audit.CreateAuditEvent(ctx, event, tx); err != nil {
+		o.Logger.Errorf(fmt.Sprintf("failed to create audit event: %s", err.Error()), zap.Error(err))
+		return
+	}
+
+	if err = o.Repository.Audit.UpdateAuditChainHead(ctx, model.AuditChainHead{Seq: event.Seq, Hash: event.Hash}, tx); err != nil {
+		o.Logger.Errorf(fmt.Sprintf("failed to update audit chain head: %s", err.Error()), zap.Error(err))
+		return
+	}
+	return
+}
+
+// submissionCourseID is the course of the submission, looked up through its assignment even when the
+// assignment is in the trash
+func (o ServiceOption) submissionCourseID(ctx context.Context, submission model.Submission, tx *sqlx.Tx) (*uuid.UUID, error) {
+	assignment, err := o.Repository.LearningManagement.GetAssignmentByID(ctx, submission.AssignmentID.String(), tx)
+	if pkg.IsNotFound(err) {
+		assignment, err = o.Repository.LearningManagement.GetDeletedAssignmentByID(ctx, submission.AssignmentID.String(), tx)
+	}
+	if err != nil {
+		o.Logger.Warnf(fmt.Sprintf("failed to get assignment by id: %s", err.Error()), zap.Error(err))
+		return nil, err
+	}
+	return &assignment.CourseID, nil
+}
+
+func auditState(state any) (*string, error) {
+	if state == nil {
+		return nil, nil
+	}
+	raw, err := json.Marshal(state)
+	if err != nil {
+		return nil, err
+	}
+	encoded := string(raw)
+	return &encoded, nil
+}
+
+// auditHash is the SHA-256 of the previous hash followed by the canonical JSON of the event, so changing any
+// field or link of the chain changes every hash after it
+func auditHash(event model.AuditEvent) (string, error) {
+	canonical, err := json.Marshal(struct {
+		Seq        int64      `json:"seq"`
+		ID         uuid.UUID  `json:"id"`
+		ActorID    *uuid.UUID `json:"actor_id"`
+		ActorRole  *string    `json:"actor_role"`
+		Action     string     `json:"action"`
+		EntityType string     `json:"entity_type"`
+		EntityID   uuid.UUID  `json:"entity_id"`
+		CourseID   *uuid.UUID `json:"course_id"`
+		Before     *string    `json:"before"`
+		After      *string    `json:"after"`
+		IP         *string    `json:"ip"`
+		RequestID  *string    `json:"request_id"`
+		CreatedAt  string     `json:"created_at"`
+	}{
+		Seq:        event.Seq,
+		ID:         event.ID,
+		ActorID:    event.ActorID,
+		ActorRole:  event.ActorRole,
+		Action:     event.Action,
+		EntityType: event.EntityType,
+		EntityID:   event.EntityID,
+		CourseID:   event.CourseID,
+		Before:     event.Before,
+		After:      event.After,
+		IP:         event.IP,
+		RequestID:  event.RequestID,
+		CreatedAt:  event.CreatedAt.UTC().Format(time.RFC3339Nano),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(append([]byte(event.PrevHash), canonical...))
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func (s *AuditService) GetAllAuditEvents(ctx context.Context, query pkg.ListQuery) (response payload.GetAllAuditEventsResponse, meta payload.MetaResponse, err error) {
+	return response, meta, s.Repository.Tx.Run(ctx, repository.TxReadOnly, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+		user, err := s.currentUser(ctx, tx)
+		if err != nil {
+			return
+		}
+
+		// teachers only see the events of a course they own, so they must name it
+		resource := policy.Resource{}
+		if s.Policy.Scope(user.Role, policy.AuditRead) == policy.ScopeOwn {
+			courseID, ok := query.Filters["course_id"]
+			if !ok {
+				err = pkg.NewBadRequestError("course_id filter is required", nil)
+				s.Logger.Warnf("audit events requested without course_id", zap.Error(err))
+				return
+			}
+
+			course, err := s.Repository.LearningManagement.GetCourseByID(ctx, courseID, tx)
+			if err != nil {
+				s.Logger.Warnf(fmt.Sprintf("failed to get course by id: %s", err.Error()), zap.Error(err))
+				return err
+			}
+
+			if resource, err = s.courseOwners(ctx, course, tx); err != nil {
+				return err
+			}
+		}
+
+		if err = s.authorize(user, policy.AuditRead, resource); err != nil {
+			return
+		}
+
+		events, page, err := s.Repository.Audit.ListAuditEvents(ctx, query, tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to get audit events: %s", err.Error()), zap.Error(err))
+			return
+		}
+		meta = pageToMeta(page)
+
+		response.Events = make([]payload.AuditEventResponse, len(events))
+		for i, event := range events {
+			response.Events[i] = auditEventToResponse(event)
+		}
+		return
+	})
+}
+
+func (s *AuditService) VerifyAuditChain(ctx context.Context) (response payload.AuditVerificationResponse, err error) {
+	return response, s.Repository.Tx.Run(ctx, repository.TxReadOnly, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+		user, err := s.currentUser(ctx, tx)
+		if err != nil {
+			return
+		}
+
+		if err = s.authorize(user, policy.AuditVerify, policy.Resource{}); err != nil {
+			return
+		}
+
+		// events appended after the head is read are left for the next verification
+		head, err := s.Repository.Audit.GetAuditChainHead(ctx, tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to get audit chain head: %s", err.Error()), zap.Error(err))
+			return
+		}
+
+		walk := newAuditChainWalk(head)
+		for !walk.done() {
+			events, err := s.Repository.Audit.GetAuditEventsAfterSeq(ctx, walk.last.Seq, auditVerifyBatchSize, tx)
+			if err != nil {
+				s.Logger.Warnf(fmt.Sprintf("failed to get audit events: %s", err.Error()), zap.Error(err))
+				return err
+			}
+			if err = walk.next(events); err != nil {
+				return err
+			}
+		}
+
+		response = walk.finish()
+		if !response.Valid {
+			s.Logger.Errorf("audit chain is broken at event %d: %s", *response.BrokenAtSeq, response.Reason)
+		}
+		return
+	})
+}
+
+// auditChainWalk follows the audit chain from the first event up to the head it was started with and stops at the
+// first broken link
+type auditChainWalk struct {
+	head   model.AuditChainHead
+	last   model.AuditChainHead
+	result payload.AuditVerificationResponse
+}
+
+func newAuditChainWalk(head model.AuditChainHead) *auditChainWalk {
+	return &auditChainWalk{
+		head:   head,
+		last:   model.AuditChainHead{Seq: 0, Hash: genesisAuditHash},
+		result: payload.AuditVerificationResponse{HeadSeq: head.Seq},
+	}
+}
+
+func (w *auditChainWalk) done() bool {
+	return w.last.Seq >= w.head.Seq || w.result.BrokenAtSeq != nil
+}
+
+func (w *auditChainWalk) broken(seq int64, reason string) {
+	w.result.BrokenAtSeq = &seq
+	w.result.Reason = reason
+}
+
+// next checks the batch of events that follows the last checked event, an empty batch means the chain ends
+// before its head
+func (w *auditChainWalk) next(events []model.AuditEvent) error {
+	if len(events) == 0 {
+		w.broken(w.last.Seq+1, "event is missing")
+		return nil
+	}
+
+	for _, event := range events {
+		if event.Seq > w.head.Seq {
+			break
+		}
+
+		hash, err := auditHash(event)
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case event.Seq != w.last.Seq+1:
+			w.broken(w.last.Seq+1, "event is missing")
+		case event.PrevHash != w.last.Hash:
+			w.broken(event.Seq, "previous hash does not match the event before")
+		case event.Hash != hash:
+			w.broken(event.Seq, "hash does not match the content of the event")
+		}
+		if w.result.BrokenAtSeq != nil {
+			return nil
+		}
+
+		w.last = model.AuditChainHead{Seq: event.Seq, Hash: event.Hash}
+		w.result.Checked++
+	}
+	return nil
+}
+
+// finish compares the last checked event with the head and returns the outcome of the walk
+func (w *auditChainWalk) finish() payload.AuditVerificationResponse {
+	if w.result.BrokenAtSeq == nil && w.last.Hash != w.head.Hash {
+		w.broken(w.head.Seq, "chain head does not match the last event")
+	}
+	w.result.Valid = w.result.BrokenAtSeq == nil
+	return w.result
+}
+
+// genesisAuditHash is the previous hash of the first event
+var genesisAuditHash = fmt.Sprintf("%064d", 0)
+
+func auditEventToResponse(event model.AuditEvent) (response payload.AuditEventResponse) {
+	response.Seq = event.Seq
+	response.ID = event.ID.String()
+	if event.ActorID != nil {
+		actorID := event.ActorID.String()
+		response.ActorID = &actorID
+	}
+	response.ActorRole = event.ActorRole
+	response.Action = event.Action
+	response.EntityType = event.EntityType
+	response.EntityID = event.EntityID.String()
+	if event.CourseID != nil {
+		courseID := event.CourseID.String()
+		response.CourseID = &courseID
+	}
+	if event.Before != nil {
+		response.Before = json.RawMessage(*event.Before)
+	}
+	if event.After != nil {
+		response.After = json.RawMessage(*event.After)
+	}
+	response.IP = event.IP
+	response.RequestID = event.RequestID
+	response.CreatedAt = event.CreatedAt.Format(time.RFC3339Nano)
+	response.PrevHash = event.PrevHash
+	response.Hash = event.Hash
+	return
+}
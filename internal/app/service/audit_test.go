@@ -0,0 +1,174 @@
+package service
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"edukita-teaching-grading/internal/app/model"
+	"edukita-teaching-grading/internal/pkg"
+
+	"github.com/google/uuid"
+)
+
+// newTestAuditChain links n events the way audit appends them and returns them with the head
+func newTestAuditChain(t *testing.T, n int) ([]model.AuditEvent, model.AuditChainHead) {
+	t.Helper()
+
+	head := model.AuditChainHead{Seq: 0, Hash: genesisAuditHash}
+	events := make([]model.AuditEvent, n)
+	for i := range events {
+		after := `{"grade":90}`
+		event := model.AuditEvent{
+			Seq:        head.Seq + 1,
+			ID:         uuid.New(),
+			Action:     pkg.AUDIT_ACTION_UPDATE,
+			EntityType: pkg.AUDIT_ENTITY_SUBMISSION,
+			EntityID:   uuid.New(),
+			After:      &after,
+			CreatedAt:  time.Now().UTC().Truncate(time.Microsecond),
+			PrevHash:   head.Hash,
+		}
+		hash, err := auditHash(event)
+		if err != nil {
+			t.Fatalf("auditHash() unexpected error: %v", err)
+		}
+		event.Hash = hash
+		events[i] = event
+		head = model.AuditChainHead{Seq: event.Seq, Hash: event.Hash}
+	}
+	return events, head
+}
+
+func TestAuditHash(t *testing.T) {
+	events, _ := newTestAuditChain(t, 1)
+	event := events[0]
+
+	again, err := auditHash(event)
+	if err != nil || again != event.Hash {
+		t.Fatalf("auditHash() = %s, %v, want the same hash %s", again, err, event.Hash)
+	}
+
+	// the hash reads the time in UTC, so an event read back in another zone keeps its hash
+	event.CreatedAt = event.CreatedAt.In(time.FixedZone("WIB", 7*60*60))
+	if hash, _ := auditHash(event); hash != events[0].Hash {
+		t.Error("hash changed with the time zone of the timestamp")
+	}
+
+	changes := map[string]func(*model.AuditEvent){
+		"action":    func(e *model.AuditEvent) { e.Action = pkg.AUDIT_ACTION_DELETE },
+		"after":     func(e *model.AuditEvent) { after := `{"grade":100}`; e.After = &after },
+		"actor":     func(e *model.AuditEvent) { actorID := uuid.New(); e.ActorID = &actorID },
+		"timestamp": func(e *model.AuditEvent) { e.CreatedAt = e.CreatedAt.Add(time.Microsecond) },
+		"prev hash": func(e *model.AuditEvent) { e.PrevHash = events[0].Hash },
+	}
+	for name, change := range changes {
+		changed := events[0]
+		change(&changed)
+		if hash, _ := auditHash(changed); hash == events[0].Hash {
+			t.Errorf("changing the %s kept the hash", name)
+		}
+	}
+}
+
+func TestAuditChainWalk(t *testing.T) {
+	tests := []struct {
+		name        string
+		tamper      func(events []model.AuditEvent, head model.AuditChainHead) ([]model.AuditEvent, model.AuditChainHead)
+		wantBroken  int64
+		wantReason  string
+		wantChecked int64
+	}{
+		{
+			name: "valid",
+			tamper: func(events []model.AuditEvent, head model.AuditChainHead) ([]model.AuditEvent, model.AuditChainHead) {
+				return events, head
+			},
+			wantChecked: 5,
+		},
+		{
+			name: "missing seq",
+			tamper: func(events []model.AuditEvent, head model.AuditChainHead) ([]model.AuditEvent, model.AuditChainHead) {
+				return slices.Delete(events, 2, 3), head
+			},
+			wantBroken:  3,
+			wantReason:  "event is missing",
+			wantChecked: 2,
+		},
+		{
+			name: "missing tail",
+			tamper: func(events []model.AuditEvent, head model.AuditChainHead) ([]model.AuditEvent, model.AuditChainHead) {
+				return events[:4], head
+			},
+			wantBroken:  5,
+			wantReason:  "event is missing",
+			wantChecked: 4,
+		},
+		{
+			name: "wrong prev hash",
+			tamper: func(events []model.AuditEvent, head model.AuditChainHead) ([]model.AuditEvent, model.AuditChainHead) {
+				// the event is hashed again so only its link is wrong
+				events[3].PrevHash = events[1].Hash
+				events[3].Hash, _ = auditHash(events[3])
+				return events, head
+			},
+			wantBroken:  4,
+			wantReason:  "previous hash does not match the event before",
+			wantChecked: 3,
+		},
+		{
+			name: "edited content",
+			tamper: func(events []model.AuditEvent, head model.AuditChainHead) ([]model.AuditEvent, model.AuditChainHead) {
+				after := `{"grade":100}`
+				events[1].After = &after
+				return events, head
+			},
+			wantBroken:  2,
+			wantReason:  "hash does not match the content of the event",
+			wantChecked: 1,
+		},
+		{
+			name: "head mismatch",
+			tamper: func(events []model.AuditEvent, head model.AuditChainHead) ([]model.AuditEvent, model.AuditChainHead) {
+				head.Hash = events[3].Hash
+				return events, head
+			},
+			wantBroken:  5,
+			wantReason:  "chain head does not match the last event",
+			wantChecked: 5,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events, head := tt.tamper(newTestAuditChain(t, 5))
+
+			// the events are read in batches of two like the repository pages through them
+			walk := newAuditChainWalk(head)
+			for !walk.done() {
+				var batch []model.AuditEvent
+				for _, event := range events {
+					if event.Seq > walk.last.Seq && len(batch) < 2 {
+						batch = append(batch, event)
+					}
+				}
+				if err := walk.next(batch); err != nil {
+					t.Fatalf("next() unexpected error: %v", err)
+				}
+			}
+			got := walk.finish()
+
+			if got.Checked != tt.wantChecked || got.HeadSeq != head.Seq {
+				t.Errorf("checked %d of %d events, want %d", got.Checked, got.HeadSeq, tt.wantChecked)
+			}
+			if tt.wantBroken == 0 {
+				if !got.Valid || got.BrokenAtSeq != nil {
+					t.Errorf("valid %v, broken at %v: %s", got.Valid, got.BrokenAtSeq, got.Reason)
+				}
+				return
+			}
+			if got.Valid || got.BrokenAtSeq == nil || *got.BrokenAtSeq != tt.wantBroken || got.Reason != tt.wantReason {
+				t.Errorf("valid %v, broken at %v: %q, want %d: %q", got.Valid, got.BrokenAtSeq, got.Reason, tt.wantBroken, tt.wantReason)
+			}
+		})
+	}
+}
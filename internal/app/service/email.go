@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"edukita-teaching-grading/internal/app/model"
+	"edukita-teaching-grading/internal/app/worker"
+	"edukita-teaching-grading/internal/pkg"
+	"edukita-teaching-grading/pkg/mailer"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// email queues a template with its data for one user, it is rendered when it is sent. Emails about a domain event
+// carry its id so the event emails an address once however often it is delivered.
+func (o ServiceOption) email(userID uuid.UUID, to string, template string, data any, eventID *uuid.UUID) (email model.EmailMessage, err error) {
+	if _, err = mailer.Data(template); err != nil {
+		o.Logger.Warnf(fmt.Sprintf("failed to queue email: %s", err.Error()), zap.Error(err))
+		return
+	}
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		o.Logger.Warnf(fmt.Sprintf("failed to encode email %s: %s", template, err.Error()), zap.Error(err))
+		return
+	}
+
+	now := time.Now()
+	return model.EmailMessage{
+		ID:            uuid.New(),
+		UserID:        &userID,
+		ToAddress:     to,
+		Template:      template,
+		Data:          string(encoded),
+		EventID:       eventID,
+		Status:        pkg.EMAIL_PENDING,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}, nil
+}
+
+// queueEmails saves the emails and queues the jobs sending them in the transaction of the change that caused them,
+// so they are only sent when it commits. It returns how many were queued, emails already sent about the same event
+// are skipped.
+func (o ServiceOption) queueEmails(ctx context.Context, tx *sqlx.Tx, emails ...model.EmailMessage) (queued int, err error) {
+	created, err := o.Repository.Email.CreateEmailMessages(ctx, emails, tx)
+	if err != nil {
+		o.Logger.Warnf(fmt.Sprintf("failed to queue emails: %s", err.Error()), zap.Error(err))
+		return
+	}
+	if err = worker.EnqueueEmails(ctx, o.Jobs, created); err != nil {
+		o.Logger.Warnf(fmt.Sprintf("failed to queue send_email jobs: %s", err.Error()), zap.Error(err))
+		return
+	}
+	return len(created), nil
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"edukita-teaching-grading/internal/app/event"
+	"edukita-teaching-grading/internal/app/model"
+	"edukita-teaching-grading/internal/pkg"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// publish writes the domain event to the outbox inside the transaction of the change that raised it, so it is
+// dispatched exactly when the change commits
+func (o ServiceOption) publish(ctx context.Context, tx *sqlx.Tx, eventType event.Type, aggregateID uuid.UUID, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		o.Logger.Errorf(fmt.Sprintf("failed to encode %s event: %s", eventType, err.Error()), zap.Error(err))
+		return err
+	}
+
+	now := time.Now()
+	row := model.OutboxEvent{
+		ID:            uuid.New(),
+		EventType:     string(eventType),
+		AggregateType: eventType.Aggregate(),
+		AggregateID:   aggregateID,
+		Payload:       string(body),
+		OccurredAt:    now,
+		AvailableAt:   now,
+	}
+	if actor, ok := pkg.ActorFromContext(ctx); ok {
+		if actorID, err := uuid.Parse(actor.ID); err == nil {
+			row.ActorID = &actorID
+		}
+	}
+
+	if _, err = o.Repository.Outbox.CreateOutboxEvent(ctx, row, tx); err != nil {
+		o.Logger.Errorf(fmt.Sprintf("failed to create outbox event: %s", err.Error()), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func submissionEventPayload(submission model.Submission, courseID uuid.UUID) event.SubmissionPayload {
+	return event.SubmissionPayload{
+		SubmissionID: submission.ID,
+		AssignmentID: submission.AssignmentID,
+		CourseID:     courseID,
+		StudentID:    submission.StudentID,
+		TeacherID:    submission.TeacherID,
+		AttemptCount: submission.AttemptCount,
+		IsLate:       submission.IsLate,
+		SubmittedAt:  submission.SubmittedAt,
+	}
+}
+
+func assignmentPublishedPayload(assignment model.Assignment) event.AssignmentPublishedPayload {
+	return event.AssignmentPublishedPayload{
+		AssignmentID: assignment.ID,
+		CourseID:     assignment.CourseID,
+		TeacherID:    assignment.TeacherID,
+		Title:        assignment.Title,
+		DueDate:      assignment.DueDate,
+	}
+}
+
+func enrollmentEventPayload(enrollment model.Enrollment) event.EnrollmentPayload {
+	return event.EnrollmentPayload{
+		EnrollmentID: enrollment.ID,
+		CourseID:     enrollment.CourseID,
+		StudentID:    enrollment.StudentID,
+	}
+}
@@ -0,0 +1,601 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"edukita-teaching-grading/internal/app/model"
+	"edukita-teaching-grading/internal/app/payload"
+	"edukita-teaching-grading/internal/app/policy"
+	"edukita-teaching-grading/internal/app/repository"
+	"edukita-teaching-grading/internal/pkg"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+type (
+	IGradebookService interface {
+		CreateCategory(ctx context.Context, courseID string, requestBody *payload.CreateCategoryRequest) (response payload.CategoryResponse, err error)
+		GetAllCategoriesByCourseID(ctx context.Context, courseID string, query pkg.ListQuery) (response payload.GetAllCategoriesResponse, meta payload.MetaResponse, err error)
+		UpdateCategoryByID(ctx context.Context, courseID string, id string, requestBody *payload.UpdateCategoryRequest) (response payload.CategoryResponse, err error)
+		DeleteCategoryByID(ctx context.Context, courseID string, id string) (response payload.CategoryResponse, err error)
+
+		GetCourseGradebook(ctx context.Context, courseID string) (response payload.GradebookResponse, err error)
+		GetStudentGradebook(ctx context.Context, courseID string, studentID string) (response payload.StudentGradeResponse, err error)
+
+		CreateGradingScheme(ctx context.Context, requestBody *payload.CreateGradingSchemeRequest) (response payload.GradingSchemeResponse, err error)
+		GetGradingSchemeByID(ctx context.Context, id string) (response payload.GradingSchemeResponse, err error)
+		GetAllGradingSchemes(ctx context.Context, query pkg.ListQuery) (response payload.GetAllGradingSchemesResponse, meta payload.MetaResponse, err error)
+		GetStudentTranscript(ctx context.Context, studentID string) (response payload.TranscriptResponse, err error)
+	}
+	GradebookService struct {
+		ServiceOption
+	}
+)
+
+func InitiateGradebookService(opt ServiceOption) IGradebookService {
+	return &GradebookService{
+		ServiceOption: opt,
+	}
+}
+
+func (s *GradebookService) CreateCategory(ctx context.Context, courseID string, requestBody *payload.CreateCategoryRequest) (response payload.CategoryResponse, err error) {
+	return response, s.Repository.Tx.Run(ctx, repository.TxSerializable, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+		user, err := s.currentUser(ctx, tx)
+		if err != nil {
+			return
+		}
+
+		course, err := s.Repository.LearningManagement.GetCourseByID(ctx, courseID, tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to get course by id: %s", err.Error()), zap.Error(err))
+			return
+		}
+
+		owners, err := s.courseOwners(ctx, course, tx)
+		if err != nil {
+			return
+		}
+
+		if err = s.authorize(user, policy.CourseUpdate, owners); err != nil {
+			return
+		}
+
+		category := model.AssignmentCategory{
+			BaseModel: model.BaseModel{
+				ID:        uuid.New(),
+				CreatedBy: user.ID,
+				CreatedAt: time.Now(),
+			},
+			CourseID:   course.ID,
+			Name:       requestBody.Name,
+			Weight:     requestBody.Weight,
+			DropLowest: requestBody.DropLowest,
+		}
+		if err = s.checkCategoryWeights(ctx, category, tx); err != nil {
+			return
+		}
+
+		category, err = s.Repository.Gradebook.CreateCategory(ctx, category, tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to create category: %s", err.Error()), zap.Error(err))
+			return
+		}
+
+		if err = s.audit(ctx, tx, auditChange{
+			action:     pkg.AUDIT_ACTION_CREATE,
+			entityType: pkg.AUDIT_ENTITY_CATEGORY,
+			entityID:   category.ID,
+			courseID:   &category.CourseID,
+			after:      category,
+		}); err != nil {
+			return
+		}
+
+		response = categoryToResponse(category)
+		return
+	})
+}
+
+func (s *GradebookService) GetAllCategoriesByCourseID(ctx context.Context, courseID string, query pkg.ListQuery) (response payload.GetAllCategoriesResponse, meta payload.MetaResponse, err error) {
+	return response, meta, s.Repository.Tx.Run(ctx, repository.TxReadOnly, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+		user, err := s.currentUser(ctx, tx)
+		if err != nil {
+			return
+		}
+
+		if err = s.authorize(user, policy.CourseRead, policy.Resource{}); err != nil {
+			return
+		}
+
+		course, err := s.Repository.LearningManagement.GetCourseByID(ctx, courseID, tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to get course by id: %s", err.Error()), zap.Error(err))
+			return
+		}
+
+		categories, page, err := s.Repository.Gradebook.ListCategoriesByCourseID(ctx, course.ID.String(), query, tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to get categories: %s", err.Error()), zap.Error(err))
+			return
+		}
+		meta = pageToMeta(page)
+
+		response.Categories = make([]payload.CategoryResponse, len(categories))
+		for i, category := range categories {
+			response.Categories[i] = categoryToResponse(category)
+		}
+		return
+	})
+}
+
+func (s *GradebookService) UpdateCategoryByID(ctx context.Context, courseID string, id string, requestBody *payload.UpdateCategoryRequest) (response payload.CategoryResponse, err error) {
+	return response, s.Repository.Tx.Run(ctx, repository.TxSerializable, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+		user, err := s.currentUser(ctx, tx)
+		if err != nil {
+			return
+		}
+
+		category, err := s.courseCategory(ctx, courseID, id, user, tx)
+		if err != nil {
+			return
+		}
+
+		before := category
+		now := time.Now()
+		category.Name = requestBody.Name
+		category.Weight = requestBody.Weight
+		category.DropLowest = requestBody.DropLowest
+		category.UpdatedBy = &user.ID
+		category.UpdatedAt = &now
+		if err = s.checkCategoryWeights(ctx, category, tx); err != nil {
+			return
+		}
+
+		category, err = s.Repository.Gradebook.UpdateCategoryByID(ctx, category, tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to update category: %s", err.Error()), zap.Error(err))
+			return
+		}
+
+		// weights and dropped scores change final grades, the log keeps the rules they were computed with
+		if err = s.audit(ctx, tx, auditChange{
+			action:     pkg.AUDIT_ACTION_UPDATE,
+			entityType: pkg.AUDIT_ENTITY_CATEGORY,
+			entityID:   category.ID,
+			courseID:   &category.CourseID,
+			before:     before,
+			after:      category,
+		}); err != nil {
+			return
+		}
+
+		response = categoryToResponse(category)
+		return
+	})
+}
+
+// DeleteCategoryByID removes the category, its assignments stay in the course without a category
+func (s *GradebookService) DeleteCategoryByID(ctx context.Context, courseID string, id string) (response payload.CategoryResponse, err error) {
+	return response, s.Repository.Tx.Run(ctx, repository.TxReadWrite, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+		user, err := s.currentUser(ctx, tx)
+		if err != nil {
+			return
+		}
+
+		category, err := s.courseCategory(ctx, courseID, id, user, tx)
+		if err != nil {
+			return
+		}
+
+		category, err = s.Repository.Gradebook.DeleteCategoryByID(ctx, category.ID.String(), tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to delete category: %s", err.Error()), zap.Error(err))
+			return
+		}
+
+		if err = s.audit(ctx, tx, auditChange{
+			action:     pkg.AUDIT_ACTION_DELETE,
+			entityType: pkg.AUDIT_ENTITY_CATEGORY,
+			entityID:   category.ID,
+			courseID:   &category.CourseID,
+			before:     category,
+		}); err != nil {
+			return
+		}
+
+		response = categoryToResponse(category)
+		return
+	})
+}
+
+func (s *GradebookService) GetCourseGradebook(ctx context.Context, courseID string) (response payload.GradebookResponse, err error) {
+	return response, s.Repository.Tx.Run(ctx, repository.TxReadOnly, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+		user, err := s.currentUser(ctx, tx)
+		if err != nil {
+			return
+		}
+
+		course, err := s.Repository.LearningManagement.GetCourseByID(ctx, courseID, tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to get course by id: %s", err.Error()), zap.Error(err))
+			return
+		}
+
+		owners, err := s.courseOwners(ctx, course, tx)
+		if err != nil {
+			return
+		}
+
+		if err = s.authorize(user, policy.GradebookRead, owners); err != nil {
+			return
+		}
+
+		book, err := s.loadGradebook(ctx, course, tx)
+		if err != nil {
+			return
+		}
+
+		response.CourseID = course.ID.String()
+		response.GradingSchemeID = gradingSchemeIDToResponse(course.GradingSchemeID)
+		response.Categories = make([]payload.CategoryResponse, len(book.categories))
+		for i, category := range book.categories {
+			response.Categories[i] = categoryToResponse(category)
+		}
+		response.Students = []payload.StudentGradeResponse{}
+		for _, student := range book.roster {
+			if student.Status != pkg.ENROLLMENT_STATUS_ACTIVE {
+				continue
+			}
+			response.Students = append(response.Students, book.studentGrade(student))
+		}
+		return
+	})
+}
+
+func (s *GradebookService) GetStudentGradebook(ctx context.Context, courseID string, studentID string) (response payload.StudentGradeResponse, err error) {
+	return response, s.Repository.Tx.Run(ctx, repository.TxReadOnly, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+		user, err := s.currentUser(ctx, tx)
+		if err != nil {
+			return
+		}
+
+		course, err := s.Repository.LearningManagement.GetCourseByID(ctx, courseID, tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to get course by id: %s", err.Error()), zap.Error(err))
+			return
+		}
+
+		owners, err := s.courseOwners(ctx, course, tx)
+		if err != nil {
+			return
+		}
+		if id, err := uuid.Parse(studentID); err == nil {
+			owners.Owners = append(owners.Owners, id)
+		}
+
+		if err = s.authorize(user, policy.GradebookRead, owners); err != nil {
+			return
+		}
+
+		book, err := s.loadGradebook(ctx, course, tx)
+		if err != nil {
+			return
+		}
+
+		// dropped students keep their grades, so the whole roster is searched
+		for _, student := range book.roster {
+			if student.StudentID.String() == studentID {
+				response = book.studentGrade(student)
+				return
+			}
+		}
+
+		err = pkg.NewNotFoundError("student is not enrolled in this course", nil)
+		s.Logger.Warnf("student %s is not enrolled in course %s", studentID, course.ID, zap.Error(err))
+		return
+	})
+}
+
+// courseCategory loads a category of the course after checking the user may manage the course
+func (s *GradebookService) courseCategory(ctx context.Context, courseID string, id string, user model.User, tx *sqlx.Tx) (category model.AssignmentCategory, err error) {
+	category, err = s.Repository.Gradebook.GetCategoryByID(ctx, id, tx)
+	if err != nil {
+		s.Logger.Warnf(fmt.Sprintf("failed to get category by id: %s", err.Error()), zap.Error(err))
+		return
+	}
+
+	if category.CourseID.String() != courseID {
+		err = pkg.NewNotFoundError("category not found", nil)
+		s.Logger.Warnf("category %s does not belong to course %s", id, courseID, zap.Error(err))
+		return
+	}
+
+	course, err := s.Repository.LearningManagement.GetCourseByID(ctx, courseID, tx)
+	if err != nil {
+		s.Logger.Warnf(fmt.Sprintf("failed to get course by id: %s", err.Error()), zap.Error(err))
+		return
+	}
+
+	owners, err := s.courseOwners(ctx, course, tx)
+	if err != nil {
+		return
+	}
+
+	err = s.authorize(user, policy.CourseUpdate, owners)
+	return
+}
+
+// checkCategoryWeights rejects a category that would take the weights of the course above 100 percent
+func (s *GradebookService) checkCategoryWeights(ctx context.Context, category model.AssignmentCategory, tx *sqlx.Tx) error {
+	categories, err := s.Repository.Gradebook.GetAllCategoriesByCourseID(ctx, category.CourseID.String(), tx)
+	if err != nil {
+		s.Logger.Warnf(fmt.Sprintf("failed to get categories: %s", err.Error()), zap.Error(err))
+		return err
+	}
+
+	total := category.Weight
+	for _, other := range categories {
+		if other.ID == category.ID {
+			continue
+		}
+		if other.Name == category.Name {
+			return pkg.NewBadRequestError(fmt.Sprintf("category %q already exists", category.Name), nil)
+		}
+		total += other.Weight
+	}
+	if total > 100 {
+		err = pkg.NewBadRequestError(fmt.Sprintf("category weights of the course add up to %.2f, they must not exceed 100", total), nil)
+		s.Logger.Warnf("category weights exceed 100 for course %s", category.CourseID, zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// assignmentCategory validates that the category belongs to the course of the assignment
+func (s ServiceOption) assignmentCategory(ctx context.Context, categoryID string, courseID uuid.UUID, tx *sqlx.Tx) (*uuid.UUID, error) {
+	if categoryID == "" {
+		return nil, nil
+	}
+
+	category, err := s.Repository.Gradebook.GetCategoryByID(ctx, categoryID, tx)
+	if err != nil {
+		s.Logger.Warnf(fmt.Sprintf("failed to get category by id: %s", err.Error()), zap.Error(err))
+		return nil, err
+	}
+
+	if category.CourseID != courseID {
+		err = pkg.NewBadRequestError("category belongs to another course", nil)
+		s.Logger.Warnf("category %s does not belong to course %s", categoryID, courseID, zap.Error(err))
+		return nil, err
+	}
+	return &category.ID, nil
+}
+
+// gradebook holds everything needed to grade the students of a course
+type gradebook struct {
+	categories  []model.AssignmentCategory
+	assignments []model.Assignment
+	roster      []model.EnrollmentRoster
+	// grades maps a student to the graded submissions of that student by assignment
+	grades map[uuid.UUID]map[uuid.UUID]float64
+	// scheme turns percentages into letter grades, nil when the course has no grading scheme
+	scheme *gradingScheme
+}
+
+func (s *GradebookService) loadGradebook(ctx context.Context, course model.Course, tx *sqlx.Tx) (book gradebook, err error) {
+	book.categories, err = s.Repository.Gradebook.GetAllCategoriesByCourseID(ctx, course.ID.String(), tx)
+	if err != nil {
+		s.Logger.Warnf(fmt.Sprintf("failed to get categories: %s", err.Error()), zap.Error(err))
+		return
+	}
+
+	assignments, err := s.Repository.LearningManagement.GetAllAssignmentsByCourseID(ctx, course.ID.String(), tx)
+	if err != nil {
+		s.Logger.Warnf(fmt.Sprintf("failed to get assignments: %s", err.Error()), zap.Error(err))
+		return
+	}
+	for _, assignment := range assignments {
+		if assignment.IsPublished {
+			book.assignments = append(book.assignments, assignment)
+		}
+	}
+	slices.SortStableFunc(book.assignments, func(a, b model.Assignment) int {
+		return a.DueDate.Compare(b.DueDate)
+	})
+
+	book.roster, err = s.Repository.LearningManagement.GetAllEnrollmentsByCourseID(ctx, course.ID.String(), tx)
+	if err != nil {
+		s.Logger.Warnf(fmt.Sprintf("failed to get enrollments: %s", err.Error()), zap.Error(err))
+		return
+	}
+
+	submissions, err := s.Repository.LearningManagement.GetAllSubmissionsByCourseID(ctx, course.ID.String(), nil, tx)
+	if err != nil {
+		s.Logger.Warnf(fmt.Sprintf("failed to get submissions: %s", err.Error()), zap.Error(err))
+		return
+	}
+
+	book.scheme, err = s.courseGradingScheme(ctx, course, tx)
+	if err != nil {
+		return
+	}
+
+	book.grades = make(map[uuid.UUID]map[uuid.UUID]float64)
+	for _, submission := range submissions {
+		if submission.Grade == nil {
+			continue
+		}
+		if book.grades[submission.StudentID] == nil {
+			book.grades[submission.StudentID] = make(map[uuid.UUID]float64)
+		}
+		book.grades[submission.StudentID][submission.AssignmentID] = *submission.Grade
+	}
+	return
+}
+
+// gradebookItem is the score of one student on one assignment
+type gradebookItem struct {
+	assignment model.Assignment
+	grade      *float64
+	dropped    bool
+}
+
+func (i gradebookItem) percentage() float64 {
+	if i.grade == nil {
+		return 0
+	}
+	return *i.grade / i.assignment.TotalPoints * 100
+}
+
+// studentGrade computes the category and course percentages of the student.
+// Without categories the course is graded on the total points of its assignments, otherwise every category
+// is graded on its own points after dropping its lowest scores and the categories are averaged by weight.
+// The running percentage only counts graded work, the final percentage counts missing work as zero.
+func (b gradebook) studentGrade(student model.EnrollmentRoster) (response payload.StudentGradeResponse) {
+	response.StudentID = student.StudentID.String()
+	response.StudentNumber = student.StudentNumber
+	response.FirstName = student.FirstName
+	response.LastName = student.LastName
+	response.EnrollmentStatus = student.Status
+
+	categories := b.categories
+	if len(categories) == 0 {
+		categories = []model.AssignmentCategory{{Name: "All assignments", Weight: 100}}
+	}
+
+	items := make([]*gradebookItem, len(b.assignments))
+	groups := make(map[uuid.UUID][]*gradebookItem, len(categories))
+	for i, assignment := range b.assignments {
+		items[i] = &gradebookItem{assignment: assignment}
+		if grade, ok := b.grades[student.StudentID][assignment.ID]; ok {
+			items[i].grade = &grade
+		}
+		if assignment.TotalPoints <= 0 {
+			continue
+		}
+		switch {
+		case len(b.categories) == 0:
+			groups[uuid.Nil] = append(groups[uuid.Nil], items[i])
+		case assignment.CategoryID != nil:
+			groups[*assignment.CategoryID] = append(groups[*assignment.CategoryID], items[i])
+		}
+	}
+
+	var runningWeighted, runningWeight, finalWeighted, finalWeight float64
+	for _, category := range categories {
+		group := groups[category.ID]
+		categoryGrade := payload.CategoryGradeResponse{
+			Name:       category.Name,
+			Weight:     category.Weight,
+			DropLowest: category.DropLowest,
+		}
+		if category.ID != uuid.Nil {
+			categoryID := category.ID.String()
+			categoryGrade.CategoryID = &categoryID
+		}
+
+		graded := slices.DeleteFunc(slices.Clone(group), func(item *gradebookItem) bool { return item.grade == nil })
+		if running, ok := categoryPercentage(graded, category.DropLowest, false); ok {
+			categoryGrade.RunningPercentage = &running
+			runningWeighted += running * category.Weight
+			runningWeight += category.Weight
+		}
+		// the final pass marks which scores end up dropped
+		if final, ok := categoryPercentage(group, category.DropLowest, true); ok {
+			categoryGrade.FinalPercentage = &final
+			finalWeighted += final * category.Weight
+			finalWeight += category.Weight
+		}
+		response.Categories = append(response.Categories, categoryGrade)
+	}
+
+	if runningWeight > 0 {
+		running := roundPercentage(runningWeighted / runningWeight)
+		response.RunningPercentage = &running
+	}
+	if finalWeight > 0 {
+		final := roundPercentage(finalWeighted / finalWeight)
+		response.FinalPercentage = &final
+	}
+	response.RunningLetterGrade = b.scheme.letter(response.RunningPercentage)
+	if band := b.scheme.band(response.FinalPercentage); band != nil {
+		response.LetterGrade = &band.Label
+		response.GradePoints = band.GradePoints
+		response.Passed = &band.IsPassing
+	}
+
+	response.Assignments = make([]payload.AssignmentGradeResponse, len(items))
+	for i, item := range items {
+		assignmentGrade := payload.AssignmentGradeResponse{
+			AssignmentID: item.assignment.ID.String(),
+			Title:        item.assignment.Title,
+			DueDate:      item.assignment.DueDate.Format(time.RFC3339),
+			TotalPoints:  item.assignment.TotalPoints,
+			Grade:        item.grade,
+			Dropped:      item.dropped,
+		}
+		if item.assignment.CategoryID != nil {
+			categoryID := item.assignment.CategoryID.String()
+			assignmentGrade.CategoryID = &categoryID
+		}
+		if item.grade != nil && item.assignment.TotalPoints > 0 {
+			percentage := roundPercentage(item.percentage())
+			assignmentGrade.Percentage = &percentage
+			assignmentGrade.LetterGrade = b.scheme.letter(&percentage)
+		}
+		assignmentGrade.Counted = item.assignment.TotalPoints > 0 && (len(b.categories) == 0 || item.assignment.CategoryID != nil)
+		response.Assignments[i] = assignmentGrade
+	}
+	return
+}
+
+// categoryPercentage leaves out the dropLowest lowest scores, always keeping at least one, and
+// returns the points earned over the points possible of the rest. It reports false for an empty category.
+func categoryPercentage(items []*gradebookItem, dropLowest int, markDropped bool) (float64, bool) {
+	if len(items) == 0 {
+		return 0, false
+	}
+
+	sorted := slices.Clone(items)
+	slices.SortStableFunc(sorted, func(a, b *gradebookItem) int {
+		return cmp.Compare(a.percentage(), b.percentage())
+	})
+	drop := min(dropLowest, len(sorted)-1)
+
+	var earned, possible float64
+	for i, item := range sorted {
+		if i < drop {
+			if markDropped {
+				item.dropped = true
+			}
+			continue
+		}
+		if item.grade != nil {
+			earned += *item.grade
+		}
+		possible += item.assignment.TotalPoints
+	}
+	return roundPercentage(earned / possible * 100), true
+}
+
+func roundPercentage(value float64) float64 {
+	return math.Round(value*100) / 100
+}
+
+func categoryToResponse(category model.AssignmentCategory) (response payload.CategoryResponse) {
+	response.ID = category.ID.String()
+	response.CourseID = category.CourseID.String()
+	response.Name = category.Name
+	response.Weight = category.Weight
+	response.DropLowest = category.DropLowest
+	response.CreatedAt = category.CreatedAt.Format(time.RFC3339)
+	response.CreatedBy = category.CreatedBy.String()
+	return
+}
@@ -0,0 +1,174 @@
+package service
+
+import (
+	"testing"
+
+	"edukita-teaching-grading/internal/app/model"
+
+	"github.com/google/uuid"
+)
+
+func newTestGradebookItem(totalPoints float64, grade *float64) *gradebookItem {
+	return &gradebookItem{assignment: model.Assignment{BaseModel: model.BaseModel{ID: uuid.New()}, TotalPoints: totalPoints}, grade: grade}
+}
+
+func newTestAssignment(title string, totalPoints float64, categoryID *uuid.UUID) model.Assignment {
+	return model.Assignment{BaseModel: model.BaseModel{ID: uuid.New()}, Title: title, TotalPoints: totalPoints, CategoryID: categoryID}
+}
+
+func TestCategoryPercentage(t *testing.T) {
+	grade := func(g float64) *float64 { return &g }
+
+	tests := []struct {
+		name        string
+		items       []*gradebookItem
+		dropLowest  int
+		want        float64
+		wantOK      bool
+		wantDropped []bool
+	}{
+		{"empty", nil, 0, 0, false, nil},
+		{"points over points", []*gradebookItem{newTestGradebookItem(10, grade(8)), newTestGradebookItem(10, grade(5))}, 0, 65, true, []bool{false, false}},
+		{"drop lowest", []*gradebookItem{newTestGradebookItem(10, grade(8)), newTestGradebookItem(10, grade(5))}, 1, 80, true, []bool{false, true}},
+		{"keeps one score", []*gradebookItem{newTestGradebookItem(10, grade(8)), newTestGradebookItem(10, grade(5))}, 5, 80, true, []bool{false, true}},
+		{"missing work is zero", []*gradebookItem{newTestGradebookItem(10, grade(8)), newTestGradebookItem(10, nil)}, 0, 40, true, []bool{false, false}},
+		{"drops missing work first", []*gradebookItem{newTestGradebookItem(10, grade(8)), newTestGradebookItem(10, nil)}, 1, 80, true, []bool{false, true}},
+		// the lowest percentage is dropped, not the fewest points
+		{"drops by percentage", []*gradebookItem{newTestGradebookItem(50, grade(45)), newTestGradebookItem(10, grade(5))}, 1, 90, true, []bool{false, true}},
+		{"weighs by points", []*gradebookItem{newTestGradebookItem(50, grade(45)), newTestGradebookItem(10, grade(5))}, 0, 83.33, true, []bool{false, false}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := categoryPercentage(tt.items, tt.dropLowest, true)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("categoryPercentage() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+			for i, want := range tt.wantDropped {
+				if tt.items[i].dropped != want {
+					t.Errorf("item %d dropped %v, want %v", i, tt.items[i].dropped, want)
+				}
+			}
+		})
+	}
+
+	items := []*gradebookItem{newTestGradebookItem(10, grade(8)), newTestGradebookItem(10, grade(5))}
+	categoryPercentage(items, 1, false)
+	if items[1].dropped {
+		t.Error("running pass marked a dropped score")
+	}
+}
+
+func TestStudentGrade(t *testing.T) {
+	homework := model.AssignmentCategory{BaseModel: model.BaseModel{ID: uuid.New()}, Name: "Homework", Weight: 40, DropLowest: 1}
+	exams := model.AssignmentCategory{BaseModel: model.BaseModel{ID: uuid.New()}, Name: "Exams", Weight: 60}
+	quizzes := model.AssignmentCategory{BaseModel: model.BaseModel{ID: uuid.New()}, Name: "Quizzes", Weight: 50}
+
+	hw1 := newTestAssignment("hw1", 10, &homework.ID)
+	hw2 := newTestAssignment("hw2", 10, &homework.ID)
+	hw3 := newTestAssignment("hw3", 10, &homework.ID)
+	midterm := newTestAssignment("midterm", 100, &exams.ID)
+	final := newTestAssignment("final", 100, &exams.ID)
+	extra := newTestAssignment("extra", 10, nil)
+	survey := newTestAssignment("survey", 0, &homework.ID)
+	// without categories the category of an assignment is ignored
+	essay := newTestAssignment("essay", 10, nil)
+	project := newTestAssignment("project", 30, &homework.ID)
+
+	student := model.EnrollmentRoster{StudentID: uuid.New()}
+
+	tests := []struct {
+		name        string
+		book        gradebook
+		grades      map[uuid.UUID]float64
+		wantRunning *float64
+		wantFinal   *float64
+		// wantDropped and wantCounted list the assignments in the order of the book
+		wantDropped []bool
+		wantCounted []bool
+		wantLetter  *string
+	}{
+		{
+			name: "weighted categories",
+			book: gradebook{
+				categories:  []model.AssignmentCategory{homework, exams},
+				assignments: []model.Assignment{hw1, hw2, hw3, midterm, final, extra, survey},
+			},
+			grades: map[uuid.UUID]float64{hw1.ID: 10, hw2.ID: 5, midterm.ID: 80, extra.ID: 10},
+			// running: homework drops hw2 and is 100, exams are 80, final: homework drops the missing hw3 and is 75,
+			// exams are 80 out of 200
+			wantRunning: ref(88.0),
+			wantFinal:   ref(54.0),
+			wantDropped: []bool{false, false, true, false, false, false, false},
+			wantCounted: []bool{true, true, true, true, true, false, false},
+		},
+		{
+			name: "without categories",
+			book: gradebook{
+				assignments: []model.Assignment{essay, project},
+			},
+			grades:      map[uuid.UUID]float64{essay.ID: 5},
+			wantRunning: ref(50.0),
+			wantFinal:   ref(12.5),
+			wantDropped: []bool{false, false},
+			wantCounted: []bool{true, true},
+		},
+		{
+			name: "nothing graded",
+			book: gradebook{
+				categories:  []model.AssignmentCategory{homework, exams},
+				assignments: []model.Assignment{hw1, midterm},
+			},
+			wantFinal:   ref(0.0),
+			wantDropped: []bool{false, false},
+			wantCounted: []bool{true, true},
+		},
+		{
+			name: "weights scaled over categories with work",
+			book: gradebook{
+				categories:  []model.AssignmentCategory{quizzes, exams},
+				assignments: []model.Assignment{midterm},
+				scheme:      newTestGradingScheme(),
+			},
+			grades:      map[uuid.UUID]float64{midterm.ID: 85},
+			wantRunning: ref(85.0),
+			wantFinal:   ref(85.0),
+			wantDropped: []bool{false},
+			wantCounted: []bool{true},
+			wantLetter:  ref("B"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.book.grades = map[uuid.UUID]map[uuid.UUID]float64{student.StudentID: tt.grades}
+
+			got := tt.book.studentGrade(student)
+			if !equalPercentage(got.RunningPercentage, tt.wantRunning) {
+				t.Errorf("running percentage %v, want %v", deref(got.RunningPercentage), deref(tt.wantRunning))
+			}
+			if !equalPercentage(got.FinalPercentage, tt.wantFinal) {
+				t.Errorf("final percentage %v, want %v", deref(got.FinalPercentage), deref(tt.wantFinal))
+			}
+			for i, assignment := range got.Assignments {
+				if assignment.Dropped != tt.wantDropped[i] || assignment.Counted != tt.wantCounted[i] {
+					t.Errorf("%s: dropped %v counted %v, want %v %v", assignment.Title, assignment.Dropped, assignment.Counted, tt.wantDropped[i], tt.wantCounted[i])
+				}
+			}
+			if (got.LetterGrade == nil) != (tt.wantLetter == nil) || (got.LetterGrade != nil && *got.LetterGrade != *tt.wantLetter) {
+				t.Errorf("letter grade %v, want %v", got.LetterGrade, tt.wantLetter)
+			}
+		})
+	}
+}
+
+func ref[T any](v T) *T { return &v }
+
+func equalPercentage(a, b *float64) bool {
+	return (a == nil && b == nil) || (a != nil && b != nil && *a == *b)
+}
+
+func deref(p *float64) any {
+	if p == nil {
+		return nil
+	}
+	return *p
+}
@@ -0,0 +1,395 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"edukita-teaching-grading/internal/app/model"
+	"edukita-teaching-grading/internal/app/payload"
+	"edukita-teaching-grading/internal/app/policy"
+	"edukita-teaching-grading/internal/app/repository"
+	"edukita-teaching-grading/internal/pkg"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// gradingScheme is a scheme with its bands ordered from the highest minimum percentage down
+type gradingScheme struct {
+	scheme model.GradingScheme
+	bands  []model.GradingSchemeBand
+}
+
+// band returns the band reached by the percentage, nil without a scheme or a percentage
+func (g *gradingScheme) band(percentage *float64) *model.GradingSchemeBand {
+	if g == nil || percentage == nil {
+		return nil
+	}
+	for i := range g.bands {
+		if *percentage >= g.bands[i].MinPercentage {
+			return &g.bands[i]
+		}
+	}
+	return nil
+}
+
+func (g *gradingScheme) letter(percentage *float64) *string {
+	band := g.band(percentage)
+	if band == nil {
+		return nil
+	}
+	return &band.Label
+}
+
+func (s *GradebookService) CreateGradingScheme(ctx context.Context, requestBody *payload.CreateGradingSchemeRequest) (response payload.GradingSchemeResponse, err error) {
+	return response, s.Repository.Tx.Run(ctx, repository.TxReadWrite, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+		user, err := s.currentUser(ctx, tx)
+		if err != nil {
+			return
+		}
+
+		if err = s.authorize(user, policy.GradingSchemeCreate, policy.OwnedBy(user.ID)); err != nil {
+			return
+		}
+
+		if err = validateGradingSchemeBands(requestBody); err != nil {
+			s.Logger.Warnf(fmt.Sprintf("invalid grading scheme: %s", err.Error()), zap.Error(err))
+			return
+		}
+
+		scheme := gradingScheme{
+			scheme: model.GradingScheme{
+				BaseModel: model.BaseModel{
+					ID:        uuid.New(),
+					CreatedBy: user.ID,
+					CreatedAt: time.Now(),
+				},
+				Name:        requestBody.Name,
+				Type:        requestBody.Type,
+				Description: requestBody.Description,
+			},
+		}
+		scheme.scheme, err = s.Repository.Gradebook.CreateGradingScheme(ctx, scheme.scheme, tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to create grading scheme: %s", err.Error()), zap.Error(err))
+			return
+		}
+
+		bands := slices.Clone(requestBody.Bands)
+		slices.SortFunc(bands, func(a, b payload.CreateGradingSchemeBandRequest) int {
+			return cmp.Compare(b.MinPercentage, a.MinPercentage)
+		})
+		for i, bandRequest := range bands {
+			band, err := s.Repository.Gradebook.CreateGradingSchemeBand(ctx, model.GradingSchemeBand{
+				ID:            uuid.New(),
+				SchemeID:      scheme.scheme.ID,
+				Label:         bandRequest.Label,
+				MinPercentage: bandRequest.MinPercentage,
+				GradePoints:   bandRequest.GradePoints,
+				IsPassing:     bandRequest.IsPassing,
+				Position:      i + 1,
+			}, tx)
+			if err != nil {
+				s.Logger.Warnf(fmt.Sprintf("failed to create grading scheme band: %s", err.Error()), zap.Error(err))
+				return err
+			}
+			scheme.bands = append(scheme.bands, band)
+		}
+
+		if err = s.audit(ctx, tx, auditChange{
+			action:     pkg.AUDIT_ACTION_CREATE,
+			entityType: pkg.AUDIT_ENTITY_GRADING_SCHEME,
+			entityID:   scheme.scheme.ID,
+			after:      gradingSchemeToResponse(scheme),
+		}); err != nil {
+			return
+		}
+
+		response = gradingSchemeToResponse(scheme)
+		return
+	})
+}
+
+func (s *GradebookService) GetGradingSchemeByID(ctx context.Context, id string) (response payload.GradingSchemeResponse, err error) {
+	return response, s.Repository.Tx.Run(ctx, repository.TxReadOnly, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+		user, err := s.currentUser(ctx, tx)
+		if err != nil {
+			return
+		}
+
+		if err = s.authorize(user, policy.GradingSchemeRead, policy.Resource{}); err != nil {
+			return
+		}
+
+		scheme, err := s.gradingScheme(ctx, id, tx)
+		if err != nil {
+			return
+		}
+
+		response = gradingSchemeToResponse(*scheme)
+		return
+	})
+}
+
+func (s *GradebookService) GetAllGradingSchemes(ctx context.Context, query pkg.ListQuery) (response payload.GetAllGradingSchemesResponse, meta payload.MetaResponse, err error) {
+	return response, meta, s.Repository.Tx.Run(ctx, repository.TxReadOnly, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+		user, err := s.currentUser(ctx, tx)
+		if err != nil {
+			return
+		}
+
+		if err = s.authorize(user, policy.GradingSchemeRead, policy.Resource{}); err != nil {
+			return
+		}
+
+		schemes, page, err := s.Repository.Gradebook.ListGradingSchemes(ctx, query, tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to get grading schemes: %s", err.Error()), zap.Error(err))
+			return
+		}
+		meta = pageToMeta(page)
+
+		response.GradingSchemes = make([]payload.GradingSchemeResponse, len(schemes))
+		for i, scheme := range schemes {
+			bands, err := s.Repository.Gradebook.GetAllGradingSchemeBandsBySchemeID(ctx, scheme.ID.String(), tx)
+			if err != nil {
+				s.Logger.Warnf(fmt.Sprintf("failed to get grading scheme bands: %s", err.Error()), zap.Error(err))
+				return err
+			}
+			response.GradingSchemes[i] = gradingSchemeToResponse(gradingScheme{scheme: scheme, bands: bands})
+		}
+		return
+	})
+}
+
+// GetStudentTranscript lists the final grade of the student in every course they enrolled in and
+// the cumulative GPA over the courses whose grading scheme gives grade points
+func (s *GradebookService) GetStudentTranscript(ctx context.Context, studentID string) (response payload.TranscriptResponse, err error) {
+	return response, s.Repository.Tx.Run(ctx, repository.TxReadOnly, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+		user, err := s.currentUser(ctx, tx)
+		if err != nil {
+			return
+		}
+
+		student, err := s.Repository.User.GetStudentByID(ctx, studentID, tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to get student by id: %s", err.Error()), zap.Error(err))
+			return
+		}
+
+		if err = s.authorize(user, policy.TranscriptRead, policy.OwnedBy(student.UserID)); err != nil {
+			return
+		}
+
+		enrollments, err := s.Repository.LearningManagement.GetAllEnrollmentsByStudentID(ctx, student.UserID.String(), tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to get enrollments: %s", err.Error()), zap.Error(err))
+			return
+		}
+
+		response.StudentID = student.UserID.String()
+		response.Courses = make([]payload.TranscriptCourseResponse, 0, len(enrollments))
+		for _, enrollment := range enrollments {
+			course, err := s.Repository.LearningManagement.GetCourseByID(ctx, enrollment.CourseID.String(), tx)
+			if err != nil {
+				s.Logger.Warnf(fmt.Sprintf("failed to get course by id: %s", err.Error()), zap.Error(err))
+				return err
+			}
+
+			book, err := s.loadGradebook(ctx, course, tx)
+			if err != nil {
+				return err
+			}
+
+			courseGrade := payload.TranscriptCourseResponse{
+				CourseID:         course.ID.String(),
+				Code:             course.Code,
+				Name:             course.Name,
+				EnrollmentStatus: enrollment.Status,
+			}
+			if book.scheme != nil {
+				courseGrade.GradingScheme = &book.scheme.scheme.Name
+			}
+			for _, row := range book.roster {
+				if row.StudentID != student.UserID {
+					continue
+				}
+				grade := book.studentGrade(row)
+				courseGrade.FinalPercentage = grade.FinalPercentage
+				courseGrade.LetterGrade = grade.LetterGrade
+				courseGrade.GradePoints = grade.GradePoints
+				courseGrade.Passed = grade.Passed
+			}
+
+			response.Courses = append(response.Courses, courseGrade)
+		}
+
+		response.GPA, response.CoursesCounted = transcriptGPA(response.Courses)
+		return
+	})
+}
+
+// transcriptGPA flags the courses that count towards the GPA and averages their grade points, rounded to two
+// decimals. The GPA is nil when no course counts.
+func transcriptGPA(courses []payload.TranscriptCourseResponse) (gpa *float64, counted int) {
+	var gradePoints float64
+	for i := range courses {
+		// dropped courses are listed but do not weigh on the GPA
+		courses[i].CountsTowardsGPA = courses[i].GradePoints != nil && courses[i].EnrollmentStatus != pkg.ENROLLMENT_STATUS_DROPPED
+		if courses[i].CountsTowardsGPA {
+			gradePoints += *courses[i].GradePoints
+			counted++
+		}
+	}
+
+	if counted > 0 {
+		average := math.Round(gradePoints/float64(counted)*100) / 100
+		gpa = &average
+	}
+	return
+}
+
+// gradingScheme loads the scheme together with its bands
+func (s ServiceOption) gradingScheme(ctx context.Context, id string, tx *sqlx.Tx) (*gradingScheme, error) {
+	scheme, err := s.Repository.Gradebook.GetGradingSchemeByID(ctx, id, tx)
+	if err != nil {
+		s.Logger.Warnf(fmt.Sprintf("failed to get grading scheme by id: %s", err.Error()), zap.Error(err))
+		return nil, err
+	}
+
+	bands, err := s.Repository.Gradebook.GetAllGradingSchemeBandsBySchemeID(ctx, id, tx)
+	if err != nil {
+		s.Logger.Warnf(fmt.Sprintf("failed to get grading scheme bands: %s", err.Error()), zap.Error(err))
+		return nil, err
+	}
+	return &gradingScheme{scheme: scheme, bands: bands}, nil
+}
+
+// courseGradingScheme returns the scheme of the course, nil when the course has none
+func (s ServiceOption) courseGradingScheme(ctx context.Context, course model.Course, tx *sqlx.Tx) (*gradingScheme, error) {
+	if course.GradingSchemeID == nil {
+		return nil, nil
+	}
+	return s.gradingScheme(ctx, course.GradingSchemeID.String(), tx)
+}
+
+// courseGradingSchemeID validates the scheme picked for a course
+func (s ServiceOption) courseGradingSchemeID(ctx context.Context, schemeID string, tx *sqlx.Tx) (*uuid.UUID, error) {
+	if schemeID == "" {
+		return nil, nil
+	}
+
+	scheme, err := s.Repository.Gradebook.GetGradingSchemeByID(ctx, schemeID, tx)
+	if err != nil {
+		s.Logger.Warnf(fmt.Sprintf("failed to get grading scheme by id: %s", err.Error()), zap.Error(err))
+		return nil, err
+	}
+	return &scheme.ID, nil
+}
+
+// letterGrader renders submission grades with the scheme of their course, loading every assignment and scheme once
+type letterGrader struct {
+	opt         ServiceOption
+	assignments map[uuid.UUID]model.Assignment
+	schemes     map[uuid.UUID]*gradingScheme
+}
+
+func (s ServiceOption) newLetterGrader() *letterGrader {
+	return &letterGrader{
+		opt:         s,
+		assignments: make(map[uuid.UUID]model.Assignment),
+		schemes:     make(map[uuid.UUID]*gradingScheme),
+	}
+}
+
+// addCourse caches the scheme and assignments of a course that were loaded already
+func (l *letterGrader) addCourse(courseID uuid.UUID, scheme *gradingScheme, assignments []model.Assignment) {
+	l.schemes[courseID] = scheme
+	for _, assignment := range assignments {
+		l.assignments[assignment.ID] = assignment
+	}
+}
+
+func (l *letterGrader) submissionLetter(ctx context.Context, submission model.Submission, tx *sqlx.Tx) (*string, error) {
+	if submission.Grade == nil {
+		return nil, nil
+	}
+
+	assignment, ok := l.assignments[submission.AssignmentID]
+	if !ok {
+		var err error
+		assignment, err = l.opt.Repository.LearningManagement.GetAssignmentByID(ctx, submission.AssignmentID.String(), tx)
+		if err != nil {
+			l.opt.Logger.Warnf(fmt.Sprintf("failed to get assignment by id: %s", err.Error()), zap.Error(err))
+			return nil, err
+		}
+		l.assignments[assignment.ID] = assignment
+	}
+	if assignment.TotalPoints <= 0 {
+		return nil, nil
+	}
+
+	scheme, ok := l.schemes[assignment.CourseID]
+	if !ok {
+		course, err := l.opt.Repository.LearningManagement.GetCourseByID(ctx, assignment.CourseID.String(), tx)
+		if err != nil {
+			l.opt.Logger.Warnf(fmt.Sprintf("failed to get course by id: %s", err.Error()), zap.Error(err))
+			return nil, err
+		}
+		scheme, err = l.opt.courseGradingScheme(ctx, course, tx)
+		if err != nil {
+			return nil, err
+		}
+		l.schemes[assignment.CourseID] = scheme
+	}
+
+	percentage := *submission.Grade / assignment.TotalPoints * 100
+	return scheme.letter(&percentage), nil
+}
+
+// validateGradingSchemeBands requires distinct thresholds, one of them at 0 so every percentage gets a band
+func validateGradingSchemeBands(requestBody *payload.CreateGradingSchemeRequest) error {
+	seen := make(map[float64]bool, len(requestBody.Bands))
+	for _, band := range requestBody.Bands {
+		if seen[band.MinPercentage] {
+			return pkg.NewBadRequestError(fmt.Sprintf("more than one band starts at %.2f percent", band.MinPercentage), nil)
+		}
+		seen[band.MinPercentage] = true
+		if requestBody.Type == pkg.GRADING_SCHEME_PASS_FAIL && band.GradePoints != nil {
+			return pkg.NewBadRequestError("pass/fail bands do not carry grade points", nil)
+		}
+	}
+	if !seen[0] {
+		return pkg.NewBadRequestError("the lowest band must start at 0 percent", nil)
+	}
+	return nil
+}
+
+func gradingSchemeIDToResponse(id *uuid.UUID) *string {
+	if id == nil {
+		return nil
+	}
+	schemeID := id.String()
+	return &schemeID
+}
+
+func gradingSchemeToResponse(scheme gradingScheme) (response payload.GradingSchemeResponse) {
+	response.ID = scheme.scheme.ID.String()
+	response.Name = scheme.scheme.Name
+	response.Type = scheme.scheme.Type
+	response.Description = scheme.scheme.Description
+	response.CreatedAt = scheme.scheme.CreatedAt.Format(time.RFC3339)
+	response.Bands = make([]payload.GradingSchemeBandResponse, len(scheme.bands))
+	for i, band := range scheme.bands {
+		response.Bands[i].Label = band.Label
+		response.Bands[i].MinPercentage = band.MinPercentage
+		response.Bands[i].GradePoints = band.GradePoints
+		response.Bands[i].IsPassing = band.IsPassing
+	}
+	return
+}
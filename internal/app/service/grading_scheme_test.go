@@ -0,0 +1,112 @@
+package service
+
+import (
+	"testing"
+
+	"edukita-teaching-grading/internal/app/model"
+	"edukita-teaching-grading/internal/app/payload"
+	"edukita-teaching-grading/internal/pkg"
+)
+
+func newTestGradingScheme() *gradingScheme {
+	points := func(p float64) *float64 { return &p }
+	return &gradingScheme{bands: []model.GradingSchemeBand{
+		{Label: "A", MinPercentage: 90, GradePoints: points(4), IsPassing: true},
+		{Label: "B", MinPercentage: 80, GradePoints: points(3), IsPassing: true},
+		{Label: "C", MinPercentage: 70, GradePoints: points(2), IsPassing: true},
+		{Label: "F", MinPercentage: 0, GradePoints: points(0), IsPassing: false},
+	}}
+}
+
+func TestGradingSchemeBand(t *testing.T) {
+	scheme := newTestGradingScheme()
+
+	tests := []struct {
+		percentage float64
+		want       string
+	}{
+		{100, "A"},
+		{90, "A"},
+		{89.99, "B"},
+		{80, "B"},
+		{70, "C"},
+		{69.5, "F"},
+		{0, "F"},
+	}
+	for _, tt := range tests {
+		percentage := tt.percentage
+		if got := scheme.letter(&percentage); got == nil || *got != tt.want {
+			t.Errorf("%.2f percent: got %v, want %s", tt.percentage, got, tt.want)
+		}
+	}
+
+	percentage := 95.0
+	var none *gradingScheme
+	if got := none.band(&percentage); got != nil {
+		t.Errorf("got band %s without a scheme", got.Label)
+	}
+	if got := scheme.band(nil); got != nil {
+		t.Errorf("got band %s without a percentage", got.Label)
+	}
+
+	// a scheme is only saved with a band at 0, without one low percentages have no band
+	percentage = -1
+	if got := scheme.band(&percentage); got != nil {
+		t.Errorf("got band %s below every threshold", got.Label)
+	}
+}
+
+func TestValidateGradingSchemeBands(t *testing.T) {
+	points := 4.0
+	tests := []struct {
+		name    string
+		request payload.CreateGradingSchemeRequest
+		wantErr bool
+	}{
+		{"valid", payload.CreateGradingSchemeRequest{Type: pkg.GRADING_SCHEME_LETTER, Bands: []payload.CreateGradingSchemeBandRequest{
+			{Label: "A", MinPercentage: 90, GradePoints: &points}, {Label: "F", MinPercentage: 0},
+		}}, false},
+		{"no band at 0", payload.CreateGradingSchemeRequest{Type: pkg.GRADING_SCHEME_LETTER, Bands: []payload.CreateGradingSchemeBandRequest{
+			{Label: "A", MinPercentage: 90}, {Label: "B", MinPercentage: 10},
+		}}, true},
+		{"duplicate threshold", payload.CreateGradingSchemeRequest{Type: pkg.GRADING_SCHEME_LETTER, Bands: []payload.CreateGradingSchemeBandRequest{
+			{Label: "A", MinPercentage: 0}, {Label: "B", MinPercentage: 0},
+		}}, true},
+		{"pass/fail with grade points", payload.CreateGradingSchemeRequest{Type: pkg.GRADING_SCHEME_PASS_FAIL, Bands: []payload.CreateGradingSchemeBandRequest{
+			{Label: "Pass", MinPercentage: 60, GradePoints: &points}, {Label: "Fail", MinPercentage: 0},
+		}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateGradingSchemeBands(&tt.request); (err != nil) != tt.wantErr {
+				t.Errorf("validateGradingSchemeBands() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTranscriptGPA(t *testing.T) {
+	points := func(p float64) *float64 { return &p }
+	courses := []payload.TranscriptCourseResponse{
+		{EnrollmentStatus: pkg.ENROLLMENT_STATUS_ACTIVE, GradePoints: points(4)},
+		{EnrollmentStatus: pkg.ENROLLMENT_STATUS_ACTIVE, GradePoints: points(3)},
+		{EnrollmentStatus: pkg.ENROLLMENT_STATUS_ACTIVE, GradePoints: points(3)},
+		// dropped and pass/fail courses are listed without counting
+		{EnrollmentStatus: pkg.ENROLLMENT_STATUS_DROPPED, GradePoints: points(0)},
+		{EnrollmentStatus: pkg.ENROLLMENT_STATUS_ACTIVE},
+	}
+
+	gpa, counted := transcriptGPA(courses)
+	if counted != 3 || gpa == nil || *gpa != 3.33 {
+		t.Errorf("got a GPA of %v over %d courses, want 3.33 over 3", gpa, counted)
+	}
+	for i, want := range []bool{true, true, true, false, false} {
+		if courses[i].CountsTowardsGPA != want {
+			t.Errorf("course %d counts towards the GPA: %v, want %v", i, courses[i].CountsTowardsGPA, want)
+		}
+	}
+
+	if gpa, counted := transcriptGPA(courses[3:]); gpa != nil || counted != 0 {
+		t.Errorf("got a GPA of %v over %d courses, want none", gpa, counted)
+	}
+}
@@ -0,0 +1,186 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"edukita-teaching-grading/internal/app/model"
+	"edukita-teaching-grading/internal/app/payload"
+	"edukita-teaching-grading/internal/app/policy"
+	"edukita-teaching-grading/internal/app/repository"
+	"edukita-teaching-grading/internal/pkg"
+
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+type (
+	IJobService interface {
+		GetAllJobs(ctx context.Context, query pkg.ListQuery) (response payload.GetAllJobsResponse, meta payload.MetaResponse, err error)
+		GetJobStats(ctx context.Context) (response payload.JobStatsResponse, err error)
+		GetJobByID(ctx context.Context, id string) (response payload.JobResponse, err error)
+		RetryJob(ctx context.Context, id string) (response payload.JobResponse, err error)
+	}
+	JobService struct {
+		ServiceOption
+	}
+)
+
+func InitiateJobService(opt ServiceOption) IJobService {
+	return &JobService{
+		ServiceOption: opt,
+	}
+}
+
+func (s *JobService) GetAllJobs(ctx context.Context, query pkg.ListQuery) (response payload.GetAllJobsResponse, meta payload.MetaResponse, err error) {
+	return response, meta, s.Repository.Tx.Run(ctx, repository.TxReadOnly, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+		if err = s.authorizeJobs(ctx, tx); err != nil {
+			return
+		}
+
+		jobs, page, err := s.Repository.Job.ListJobs(ctx, query, tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to get jobs: %s", err.Error()), zap.Error(err))
+			return
+		}
+		meta = pageToMeta(page)
+
+		response.Jobs = make([]payload.JobResponse, len(jobs))
+		for i, job := range jobs {
+			response.Jobs[i] = jobToResponse(job)
+		}
+		return
+	})
+}
+
+func (s *JobService) GetJobStats(ctx context.Context) (response payload.JobStatsResponse, err error) {
+	return response, s.Repository.Tx.Run(ctx, repository.TxReadOnly, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+		if err = s.authorizeJobs(ctx, tx); err != nil {
+			return
+		}
+
+		counts, err := s.Repository.Job.CountJobs(ctx, tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to count jobs: %s", err.Error()), zap.Error(err))
+			return
+		}
+
+		response = jobStatsToResponse(counts)
+		return
+	})
+}
+
+func (s *JobService) GetJobByID(ctx context.Context, id string) (response payload.JobResponse, err error) {
+	return response, s.Repository.Tx.Run(ctx, repository.TxReadOnly, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+		if err = s.authorizeJobs(ctx, tx); err != nil {
+			return
+		}
+
+		job, err := s.Repository.Job.GetJobByID(ctx, id, tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to get job by id: %s", err.Error()), zap.Error(err))
+			return
+		}
+
+		response = jobToResponse(job)
+		return
+	})
+}
+
+// RetryJob runs a failed job again with a fresh set of attempts, the last error stays until it runs
+func (s *JobService) RetryJob(ctx context.Context, id string) (response payload.JobResponse, err error) {
+	return response, s.Repository.Tx.Run(ctx, repository.TxReadWrite, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+		if err = s.authorizeJobs(ctx, tx); err != nil {
+			return
+		}
+
+		job, err := s.Repository.Job.GetJobByID(ctx, id, tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to get job by id: %s", err.Error()), zap.Error(err))
+			return
+		}
+
+		if job.Status != pkg.JOB_STATUS_FAILED {
+			err = pkg.NewBadRequestError(fmt.Sprintf("only failed jobs can be retried, the job is %s", job.Status), nil)
+			s.Logger.Warnf("retry of job %s in status %s", job.ID, job.Status, zap.Error(err))
+			return
+		}
+
+		job.Status = pkg.JOB_STATUS_PENDING
+		job.Attempts = 0
+		job.RunAt = time.Now()
+		job.FinishedAt = nil
+
+		job, err = s.Repository.Job.UpdateJob(ctx, job, tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to update job: %s", err.Error()), zap.Error(err))
+			return
+		}
+
+		response = jobToResponse(job)
+		return
+	})
+}
+
+func (s *JobService) authorizeJobs(ctx context.Context, tx *sqlx.Tx) error {
+	user, err := s.currentUser(ctx, tx)
+	if err != nil {
+		return err
+	}
+	return s.authorize(user, policy.JobManage, policy.Resource{})
+}
+
+func jobToResponse(job model.Job) (response payload.JobResponse) {
+	response.ID = job.ID.String()
+	response.Type = job.Type
+	response.Payload = json.RawMessage(job.Payload)
+	response.Priority = job.Priority
+	response.Status = job.Status
+	response.Attempts = job.Attempts
+	response.MaxAttempts = job.MaxAttempts
+	response.RunAt = job.RunAt.Format(time.RFC3339)
+	response.LockedUntil = optionalTimeToResponse(job.LockedUntil)
+	response.LockedBy = job.LockedBy
+	response.LastError = job.LastError
+	response.StartedAt = optionalTimeToResponse(job.StartedAt)
+	response.FinishedAt = optionalTimeToResponse(job.FinishedAt)
+	response.CreatedAt = job.CreatedAt.Format(time.RFC3339)
+	return
+}
+
+// jobStatsToResponse turns the counts into one row per type, in the order of the counts
+func jobStatsToResponse(counts []model.JobCount) (response payload.JobStatsResponse) {
+	response.Types = []payload.JobTypeStatsResponse{}
+	index := make(map[string]int)
+	for _, count := range counts {
+		i, ok := index[count.Type]
+		if !ok {
+			i = len(response.Types)
+			index[count.Type] = i
+			response.Types = append(response.Types, payload.JobTypeStatsResponse{Type: count.Type})
+		}
+
+		stats := &response.Types[i]
+		switch count.Status {
+		case pkg.JOB_STATUS_PENDING:
+			stats.Pending = count.Count
+		case pkg.JOB_STATUS_RUNNING:
+			stats.Running = count.Count
+		case pkg.JOB_STATUS_SUCCEEDED:
+			stats.Succeeded = count.Count
+		case pkg.JOB_STATUS_FAILED:
+			stats.Failed = count.Count
+		}
+	}
+	return
+}
+
+func optionalTimeToResponse(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	formatted := t.Format(time.RFC3339)
+	return &formatted
+}
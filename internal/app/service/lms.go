@@ -2,11 +2,15 @@ package service
 
 import (
 	"context"
+	"edukita-teaching-grading/internal/app/event"
 	"edukita-teaching-grading/internal/app/model"
 	"edukita-teaching-grading/internal/app/payload"
+	"edukita-teaching-grading/internal/app/policy"
 	"edukita-teaching-grading/internal/app/repository"
 	"edukita-teaching-grading/internal/pkg"
+	"errors"
 	"fmt"
+	"net/http"
 	"time"
 
 	"github.com/google/uuid"
@@ -19,19 +23,38 @@ type (
 		CreateCourse(ctx context.Context, requestBody *payload.CreateCourseRequest) (response payload.CreateCourseResponse, err error)
 		GetCourseByID(ctx context.Context, id string) (response payload.GetCourseResponse, err error)
 		GetCourseByCode(ctx context.Context, code string) (response payload.GetCourseResponse, err error)
-		GetAllCourses(ctx context.Context) (response payload.GetAllCoursesResponse, err error)
+		GetAllCourses(ctx context.Context, query pkg.ListQuery) (response payload.GetAllCoursesResponse, meta payload.MetaResponse, err error)
 		UpdateCourseByID(ctx context.Context, id string, requestBody *payload.UpdateCourseRequest) (response payload.UpdateCourseResponse, err error)
+		DeleteCourseByID(ctx context.Context, id string) (response payload.DeletionResponse, err error)
+		RestoreCourseByID(ctx context.Context, id string) (response payload.DeletionResponse, err error)
+		PurgeCourseByID(ctx context.Context, id string) (response payload.DeletionResponse, err error)
+
+		AddCourseTeacher(ctx context.Context, courseID string, requestBody *payload.AddCourseTeacherRequest) (response payload.CourseTeacherResponse, err error)
+		GetAllCourseTeachersByCourseID(ctx context.Context, courseID string, query pkg.ListQuery) (response payload.GetAllCourseTeachersResponse, meta payload.MetaResponse, err error)
+		RemoveCourseTeacher(ctx context.Context, courseID string, teacherID string) (response payload.CourseTeacherResponse, err error)
 
 		CreateAssignment(ctx context.Context, requestBody *payload.CreateAssignmentRequest) (response payload.CreateAssignmentResponse, err error)
 		GetAssignmentByID(ctx context.Context, id string) (response payload.GetAssignmentResponse, err error)
 		UpdateAssignmentByID(ctx context.Context, id string, requestBody *payload.UpdateAssignmentRequest) (response payload.UpdateAssignmentResponse, err error)
+		DeleteAssignmentByID(ctx context.Context, id string) (response payload.DeletionResponse, err error)
+		RestoreAssignmentByID(ctx context.Context, id string) (response payload.DeletionResponse, err error)
+		PurgeAssignmentByID(ctx context.Context, id string) (response payload.DeletionResponse, err error)
 
 		CreateSubmission(ctx context.Context, id string, requestBody *payload.CreateSubmissionRequest) (response payload.CreateSubmissionResponse, err error)
 		GetSubmissionByID(ctx context.Context, id string) (response payload.GetSubmissionResponse, err error)
 		UpdateSubmissionByID(ctx context.Context, id string, requestBody *payload.UpdateSubmissionRequest) (response payload.UpdateSubmissionResponse, err error)
-		GetAllSubmissionsByCourseID(ctx context.Context, courseID string, userID string) (response payload.GetAllSubmissionsByCourseID, err error)
-		GetAllSubmissionsByAssignmentID(ctx context.Context, assignmentID string, userID string) (response payload.GetAllSubmissionsResponse, err error)
-		GetAllSubmissionsByUserID(ctx context.Context, id string) (response payload.GetAllSubmissionsResponse, err error)
+		DeleteSubmissionByID(ctx context.Context, id string) (response payload.DeletionResponse, err error)
+		RestoreSubmissionByID(ctx context.Context, id string) (response payload.DeletionResponse, err error)
+		PurgeSubmissionByID(ctx context.Context, id string) (response payload.DeletionResponse, err error)
+		GetAllSubmissionsByCourseID(ctx context.Context, courseID string, query pkg.ListQuery) (response payload.GetAllSubmissionsByCourseID, meta payload.MetaResponse, err error)
+		GetAllSubmissionsByAssignmentID(ctx context.Context, assignmentID string, query pkg.ListQuery) (response payload.GetAllSubmissionsResponse, meta payload.MetaResponse, err error)
+		GetAllSubmissionsByUserID(ctx context.Context, id string, query pkg.ListQuery) (response payload.GetAllSubmissionsResponse, meta payload.MetaResponse, err error)
+		GetAllSubmissionAttempts(ctx context.Context, submissionID string, query pkg.ListQuery) (response payload.GetAllSubmissionAttemptsResponse, meta payload.MetaResponse, err error)
+		DiffSubmissionAttempts(ctx context.Context, submissionID string, from int, to int) (response payload.SubmissionAttemptDiffResponse, err error)
+
+		EnrollStudent(ctx context.Context, courseID string, requestBody *payload.EnrollStudentRequest) (response payload.EnrollmentResponse, err error)
+		DropEnrollment(ctx context.Context, courseID string, studentID string) (response payload.EnrollmentResponse, err error)
+		GetAllEnrollmentsByCourseID(ctx context.Context, courseID string, query pkg.ListQuery) (response payload.GetAllEnrollmentsResponse, meta payload.MetaResponse, err error)
 	}
 	LearningManagementService struct {
 		ServiceOption
@@ -45,24 +68,13 @@ func InitiateLearningManagementService(opt ServiceOption) ILearningManagementSer
 }
 
 func (s *LearningManagementService) CreateCourse(ctx context.Context, requestBody *payload.CreateCourseRequest) (response payload.CreateCourseResponse, err error) {
-	return response, repository.TransactionWrapper(ctx, s.Postgres, func(tx *sqlx.Tx) (err error) {
-		user, err := s.Repository.User.GetUserByID(ctx, requestBody.CreatedBy, tx)
+	return response, s.Repository.Tx.Run(ctx, repository.TxReadWrite, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+		user, err := s.currentUser(ctx, tx)
 		if err != nil {
-			s.Logger.Warnf(fmt.Sprintf("failed to get user by id: %s", err.Error()), zap.Error(err))
 			return
 		}
 
-		switch user.Role {
-		case pkg.ROLE_ADMIN:
-		case pkg.ROLE_TEACHER:
-			_, err := s.Repository.User.GetTeacherByID(ctx, user.ID.String(), tx)
-			if err != nil {
-				s.Logger.Warnf(fmt.Sprintf("failed to get teacher by id: %s", err.Error()), zap.Error(err))
-				return err
-			}
-		default:
-			err = pkg.NewBadRequestError("invalid role", nil)
-			s.Logger.Warnf("invalid role: %s", user.Role, zap.Error(err))
+		if err = s.authorize(user, policy.CourseCreate, policy.Resource{}); err != nil {
 			return
 		}
 
@@ -82,12 +94,39 @@ func (s *LearningManagementService) CreateCourse(ctx context.Context, requestBod
 			EndDate:     &endDate,
 			IsActive:    true,
 		}
+		course.GradingSchemeID, err = s.courseGradingSchemeID(ctx, requestBody.GradingSchemeID, tx)
+		if err != nil {
+			return
+		}
 		course, err = s.Repository.LearningManagement.CreateCourse(ctx, course, tx)
 		if err != nil {
 			s.Logger.Warnf(fmt.Sprintf("failed to create course: %s", err.Error()), zap.Error(err))
 			return
 		}
 
+		if user.Role == pkg.ROLE_TEACHER {
+			_, err = s.Repository.LearningManagement.CreateCourseTeacher(ctx, model.CourseTeacher{
+				CourseID:  course.ID,
+				TeacherID: user.ID,
+				CreatedBy: user.ID,
+				CreatedAt: now,
+			}, tx)
+			if err != nil {
+				s.Logger.Warnf(fmt.Sprintf("failed to create course teacher: %s", err.Error()), zap.Error(err))
+				return
+			}
+		}
+
+		if err = s.audit(ctx, tx, auditChange{
+			action:     pkg.AUDIT_ACTION_CREATE,
+			entityType: pkg.AUDIT_ENTITY_COURSE,
+			entityID:   course.ID,
+			courseID:   &course.ID,
+			after:      course,
+		}); err != nil {
+			return
+		}
+
 		response.ID = course.ID.String()
 
 		return
@@ -95,7 +134,7 @@ func (s *LearningManagementService) CreateCourse(ctx context.Context, requestBod
 }
 
 func (s *LearningManagementService) GetCourseByID(ctx context.Context, id string) (response payload.GetCourseResponse, err error) {
-	return response, repository.TransactionWrapper(ctx, s.Postgres, func(tx *sqlx.Tx) (err error) {
+	return response, s.Repository.Tx.Run(ctx, repository.TxReadOnly, func(ctx context.Context, tx *sqlx.Tx) (err error) {
 		course, err := s.Repository.LearningManagement.GetCourseByID(ctx, id, tx)
 		if err != nil {
 			s.Logger.Warnf(fmt.Sprintf("failed to get course by id: %s", err.Error()), zap.Error(err))
@@ -109,12 +148,13 @@ func (s *LearningManagementService) GetCourseByID(ctx context.Context, id string
 		response.StartDate = course.StartDate.Format(time.RFC3339)
 		response.EndDate = course.EndDate.Format(time.RFC3339)
 		response.IsActive = course.IsActive
+		response.GradingSchemeID = gradingSchemeIDToResponse(course.GradingSchemeID)
 		return
 	})
 }
 
 func (s *LearningManagementService) GetCourseByCode(ctx context.Context, code string) (response payload.GetCourseResponse, err error) {
-	return response, repository.TransactionWrapper(ctx, s.Postgres, func(tx *sqlx.Tx) (err error) {
+	return response, s.Repository.Tx.Run(ctx, repository.TxReadOnly, func(ctx context.Context, tx *sqlx.Tx) (err error) {
 		course, err := s.Repository.LearningManagement.GetCourseByCode(ctx, code, tx)
 		if err != nil {
 			s.Logger.Warnf(fmt.Sprintf("failed to get course by code: %s", err.Error()), zap.Error(err))
@@ -128,17 +168,19 @@ func (s *LearningManagementService) GetCourseByCode(ctx context.Context, code st
 		response.StartDate = course.StartDate.Format(time.RFC3339)
 		response.EndDate = course.EndDate.Format(time.RFC3339)
 		response.IsActive = course.IsActive
+		response.GradingSchemeID = gradingSchemeIDToResponse(course.GradingSchemeID)
 		return
 	})
 }
 
-func (s *LearningManagementService) GetAllCourses(ctx context.Context) (response payload.GetAllCoursesResponse, err error) {
-	return response, repository.TransactionWrapper(ctx, s.Postgres, func(tx *sqlx.Tx) (err error) {
-		courses, err := s.Repository.LearningManagement.GetAllCourses(ctx, tx)
+func (s *LearningManagementService) GetAllCourses(ctx context.Context, query pkg.ListQuery) (response payload.GetAllCoursesResponse, meta payload.MetaResponse, err error) {
+	return response, meta, s.Repository.Tx.Run(ctx, repository.TxReadOnly, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+		courses, page, err := s.Repository.LearningManagement.ListCourses(ctx, query, tx)
 		if err != nil {
 			s.Logger.Warnf(fmt.Sprintf("failed to get all courses: %s", err.Error()), zap.Error(err))
 			return
 		}
+		meta = pageToMeta(page)
 
 		response.Courses = make([]payload.GetCourseResponse, len(courses))
 		for i, course := range courses {
@@ -153,39 +195,35 @@ func (s *LearningManagementService) GetAllCourses(ctx context.Context) (response
 				response.Courses[i].EndDate = course.EndDate.Format(time.RFC3339)
 			}
 			response.Courses[i].IsActive = course.IsActive
+			response.Courses[i].GradingSchemeID = gradingSchemeIDToResponse(course.GradingSchemeID)
 		}
 		return
 	})
 }
 
 func (s *LearningManagementService) UpdateCourseByID(ctx context.Context, id string, requestBody *payload.UpdateCourseRequest) (response payload.UpdateCourseResponse, err error) {
-	return response, repository.TransactionWrapper(ctx, s.Postgres, func(tx *sqlx.Tx) (err error) {
-		user, err := s.Repository.User.GetUserByID(ctx, requestBody.UserID, tx)
+	return response, s.Repository.Tx.Run(ctx, repository.TxReadWrite, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+		user, err := s.currentUser(ctx, tx)
 		if err != nil {
-			s.Logger.Warnf(fmt.Sprintf("failed to get user by id: %s", err.Error()), zap.Error(err))
 			return
 		}
 
-		switch user.Role {
-		case pkg.ROLE_ADMIN:
-		case pkg.ROLE_TEACHER:
-			_, err := s.Repository.User.GetTeacherByID(ctx, user.ID.String(), tx)
-			if err != nil {
-				s.Logger.Warnf(fmt.Sprintf("failed to get teacher by id: %s", err.Error()), zap.Error(err))
-				return err
-			}
-		default:
-			err = pkg.NewBadRequestError("invalid role", nil)
-			s.Logger.Warnf("invalid role: %s", user.Role, zap.Error(err))
+		course, err := s.Repository.LearningManagement.GetCourseByID(ctx, id, tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to get course by id: %s", err.Error()), zap.Error(err))
 			return
 		}
 
-		course, err := s.Repository.LearningManagement.GetCourseByCode(ctx, requestBody.Code, tx)
+		owners, err := s.courseOwners(ctx, course, tx)
 		if err != nil {
-			s.Logger.Warnf(fmt.Sprintf("failed to get course by code: %s", err.Error()), zap.Error(err))
 			return
 		}
 
+		if err = s.authorize(user, policy.CourseUpdate, owners); err != nil {
+			return
+		}
+
+		before := course
 		now := time.Now()
 		startDate, _ := time.Parse(time.RFC3339, requestBody.StartDate)
 		endDate, _ := time.Parse(time.RFC3339, requestBody.EndDate)
@@ -195,6 +233,10 @@ func (s *LearningManagementService) UpdateCourseByID(ctx context.Context, id str
 		course.StartDate = &startDate
 		course.EndDate = &endDate
 		course.IsActive = true
+		course.GradingSchemeID, err = s.courseGradingSchemeID(ctx, requestBody.GradingSchemeID, tx)
+		if err != nil {
+			return
+		}
 		course.UpdatedBy = &user.ID
 		course.UpdatedAt = &now
 
@@ -204,6 +246,17 @@ func (s *LearningManagementService) UpdateCourseByID(ctx context.Context, id str
 			return
 		}
 
+		if err = s.audit(ctx, tx, auditChange{
+			action:     pkg.AUDIT_ACTION_UPDATE,
+			entityType: pkg.AUDIT_ENTITY_COURSE,
+			entityID:   course.ID,
+			courseID:   &course.ID,
+			before:     before,
+			after:      course,
+		}); err != nil {
+			return
+		}
+
 		response.ID = course.ID.String()
 		response.Code = course.Code
 		response.Name = course.Name
@@ -211,29 +264,126 @@ func (s *LearningManagementService) UpdateCourseByID(ctx context.Context, id str
 		response.StartDate = course.StartDate.Format(time.RFC3339)
 		response.EndDate = course.EndDate.Format(time.RFC3339)
 		response.IsActive = course.IsActive
+		response.GradingSchemeID = gradingSchemeIDToResponse(course.GradingSchemeID)
 		return
 	})
 }
 
-func (s *LearningManagementService) CreateAssignment(ctx context.Context, requestBody *payload.CreateAssignmentRequest) (response payload.CreateAssignmentResponse, err error) {
-	return response, repository.TransactionWrapper(ctx, s.Postgres, func(tx *sqlx.Tx) (err error) {
-		user, err := s.Repository.User.GetUserByID(ctx, requestBody.CreatedBy, tx)
+func (s *LearningManagementService) AddCourseTeacher(ctx context.Context, courseID string, requestBody *payload.AddCourseTeacherRequest) (response payload.CourseTeacherResponse, err error) {
+	return response, s.Repository.Tx.Run(ctx, repository.TxReadWrite, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+		user, err := s.currentUser(ctx, tx)
 		if err != nil {
-			s.Logger.Warnf(fmt.Sprintf("failed to get user by id: %s", err.Error()), zap.Error(err))
 			return
 		}
 
-		switch user.Role {
-		case pkg.ROLE_ADMIN:
-		case pkg.ROLE_TEACHER:
-			_, err := s.Repository.User.GetTeacherByID(ctx, user.ID.String(), tx)
-			if err != nil {
-				s.Logger.Warnf(fmt.Sprintf("failed to get teacher by id: %s", err.Error()), zap.Error(err))
-				return err
-			}
-		default:
-			err = pkg.NewBadRequestError("invalid role", nil)
-			s.Logger.Warnf("invalid role: %s", user.Role, zap.Error(err))
+		course, err := s.Repository.LearningManagement.GetCourseByID(ctx, courseID, tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to get course by id: %s", err.Error()), zap.Error(err))
+			return
+		}
+
+		if err = s.authorize(user, policy.CourseTeacherManage, policy.OwnedBy(course.CreatedBy)); err != nil {
+			return
+		}
+
+		teacher, err := s.Repository.User.GetTeacherByID(ctx, requestBody.TeacherID, tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to get teacher by id: %s", err.Error()), zap.Error(err))
+			return
+		}
+
+		courseTeacher, err := s.Repository.LearningManagement.CreateCourseTeacher(ctx, model.CourseTeacher{
+			CourseID:  course.ID,
+			TeacherID: teacher.UserID,
+			CreatedBy: user.ID,
+			CreatedAt: time.Now(),
+		}, tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to create course teacher: %s", err.Error()), zap.Error(err))
+			return
+		}
+
+		if err = s.audit(ctx, tx, auditChange{
+			action:     pkg.AUDIT_ACTION_CREATE,
+			entityType: pkg.AUDIT_ENTITY_COURSE_TEACHER,
+			entityID:   courseTeacher.TeacherID,
+			courseID:   &course.ID,
+			after:      courseTeacher,
+		}); err != nil {
+			return
+		}
+
+		response = courseTeacherToResponse(courseTeacher)
+		return
+	})
+}
+
+func (s *LearningManagementService) GetAllCourseTeachersByCourseID(ctx context.Context, courseID string, query pkg.ListQuery) (response payload.GetAllCourseTeachersResponse, meta payload.MetaResponse, err error) {
+	return response, meta, s.Repository.Tx.Run(ctx, repository.TxReadOnly, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+		course, err := s.Repository.LearningManagement.GetCourseByID(ctx, courseID, tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to get course by id: %s", err.Error()), zap.Error(err))
+			return
+		}
+
+		teachers, page, err := s.Repository.LearningManagement.ListCourseTeachersByCourseID(ctx, course.ID.String(), query, tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to get course teachers: %s", err.Error()), zap.Error(err))
+			return
+		}
+		meta = pageToMeta(page)
+
+		response.CourseID = course.ID.String()
+		response.Teachers = make([]payload.CourseTeacherResponse, len(teachers))
+		for i, teacher := range teachers {
+			response.Teachers[i] = courseTeacherToResponse(teacher)
+		}
+		return
+	})
+}
+
+func (s *LearningManagementService) RemoveCourseTeacher(ctx context.Context, courseID string, teacherID string) (response payload.CourseTeacherResponse, err error) {
+	return response, s.Repository.Tx.Run(ctx, repository.TxReadWrite, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+		user, err := s.currentUser(ctx, tx)
+		if err != nil {
+			return
+		}
+
+		course, err := s.Repository.LearningManagement.GetCourseByID(ctx, courseID, tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to get course by id: %s", err.Error()), zap.Error(err))
+			return
+		}
+
+		if err = s.authorize(user, policy.CourseTeacherManage, policy.OwnedBy(course.CreatedBy)); err != nil {
+			return
+		}
+
+		courseTeacher, err := s.Repository.LearningManagement.DeleteCourseTeacher(ctx, course.ID.String(), teacherID, tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to delete course teacher: %s", err.Error()), zap.Error(err))
+			return
+		}
+
+		if err = s.audit(ctx, tx, auditChange{
+			action:     pkg.AUDIT_ACTION_DELETE,
+			entityType: pkg.AUDIT_ENTITY_COURSE_TEACHER,
+			entityID:   courseTeacher.TeacherID,
+			courseID:   &course.ID,
+			before:     courseTeacher,
+		}); err != nil {
+			return
+		}
+
+		response = courseTeacherToResponse(courseTeacher)
+		return
+	})
+}
+
+func (s *LearningManagementService) CreateAssignment(ctx context.Context, requestBody *payload.CreateAssignmentRequest) (response payload.CreateAssignmentResponse, err error) {
+	return response, s.Repository.Tx.Run(ctx, repository.TxReadWrite, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+		user, err := s.currentUser(ctx, tx)
+		if err != nil {
 			return
 		}
 
@@ -243,6 +393,20 @@ func (s *LearningManagementService) CreateAssignment(ctx context.Context, reques
 			return
 		}
 
+		owners, err := s.courseOwners(ctx, course, tx)
+		if err != nil {
+			return
+		}
+
+		if err = s.authorize(user, policy.AssignmentCreate, owners); err != nil {
+			return
+		}
+
+		teacherID, err := s.assignmentTeacher(ctx, user, course, tx)
+		if err != nil {
+			return
+		}
+
 		now := time.Now()
 		assignment := model.Assignment{
 			BaseModel: model.BaseModel{
@@ -253,11 +417,30 @@ func (s *LearningManagementService) CreateAssignment(ctx context.Context, reques
 			Title:       requestBody.Title,
 			Description: requestBody.Description,
 			Content:     requestBody.Content,
-			DueDate:     now,
-			TeacherID:   user.ID,
+			TeacherID:   teacherID,
 			CourseID:    course.ID,
 			TotalPoints: requestBody.TotalPoints,
 			IsPublished: true,
+			MaxAttempts: requestBody.MaxAttempts,
+		}
+		err = applyAssignmentSchedule(&assignment, assignmentSchedule{
+			dueDate:            requestBody.DueDate,
+			availableFrom:      &requestBody.AvailableFrom,
+			availableUntil:     &requestBody.AvailableUntil,
+			latePolicy:         requestBody.LatePolicy,
+			latePenaltyPercent: &requestBody.LatePenaltyPercent,
+		})
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("invalid assignment schedule: %s", err.Error()), zap.Error(err))
+			return
+		}
+		assignment.RubricID, err = s.assignmentRubric(ctx, requestBody.RubricID, assignment.TotalPoints, tx)
+		if err != nil {
+			return
+		}
+		assignment.CategoryID, err = s.assignmentCategory(ctx, requestBody.CategoryID, assignment.CourseID, tx)
+		if err != nil {
+			return
 		}
 		assignment, err = s.Repository.LearningManagement.CreateAssignment(ctx, assignment, tx)
 		if err != nil {
@@ -265,34 +448,44 @@ func (s *LearningManagementService) CreateAssignment(ctx context.Context, reques
 			return
 		}
 
+		if err = s.audit(ctx, tx, auditChange{
+			action:     pkg.AUDIT_ACTION_CREATE,
+			entityType: pkg.AUDIT_ENTITY_ASSIGNMENT,
+			entityID:   assignment.ID,
+			courseID:   &assignment.CourseID,
+			after:      assignment,
+		}); err != nil {
+			return
+		}
+
+		if assignment.IsPublished {
+			if err = s.publish(ctx, tx, event.AssignmentPublished, assignment.ID, assignmentPublishedPayload(assignment)); err != nil {
+				return
+			}
+		}
+
 		response.ID = assignment.ID.String()
 		return
 	})
 }
 
 func (s *LearningManagementService) GetAssignmentByID(ctx context.Context, id string) (response payload.GetAssignmentResponse, err error) {
-	return response, repository.TransactionWrapper(ctx, s.Postgres, func(tx *sqlx.Tx) (err error) {
+	return response, s.Repository.Tx.Run(ctx, repository.TxReadOnly, func(ctx context.Context, tx *sqlx.Tx) (err error) {
 		assignment, err := s.Repository.LearningManagement.GetAssignmentByID(ctx, id, tx)
 		if err != nil {
 			s.Logger.Warnf(fmt.Sprintf("failed to get assignment by id: %s", err.Error()), zap.Error(err))
 			return
 		}
 
-		response.ID = assignment.ID.String()
-		response.Title = assignment.Title
-		response.Description = assignment.Description
-		response.DueDate = assignment.DueDate.Format(time.RFC3339)
-		response.TotalPoints = assignment.TotalPoints
-		response.IsPublished = assignment.IsPublished
+		response = assignmentToResponse(assignment)
 		return
 	})
 }
 
 func (s *LearningManagementService) UpdateAssignmentByID(ctx context.Context, id string, requestBody *payload.UpdateAssignmentRequest) (response payload.UpdateAssignmentResponse, err error) {
-	return response, repository.TransactionWrapper(ctx, s.Postgres, func(tx *sqlx.Tx) (err error) {
-		user, err := s.Repository.User.GetUserByID(ctx, requestBody.UserID, tx)
+	return response, s.Repository.Tx.Run(ctx, repository.TxReadWrite, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+		user, err := s.currentUser(ctx, tx)
 		if err != nil {
-			s.Logger.Warnf(fmt.Sprintf("failed to get user by id: %s", err.Error()), zap.Error(err))
 			return
 		}
 		assignment, err := s.Repository.LearningManagement.GetAssignmentByID(ctx, id, tx)
@@ -301,19 +494,43 @@ func (s *LearningManagementService) UpdateAssignmentByID(ctx context.Context, id
 			return
 		}
 
-		switch assignment.TeacherID {
-		case uuid.Nil:
-			err = pkg.NewBadRequestError("invalid teacher id", nil)
-			s.Logger.Warnf("invalid teacher id: %s", assignment.TeacherID, zap.Error(err))
+		owners, err := s.assignmentOwners(ctx, assignment, tx)
+		if err != nil {
+			return
+		}
+
+		if err = s.authorize(user, policy.AssignmentUpdate, owners); err != nil {
 			return
 		}
 
+		before := assignment
 		now := time.Now()
 		assignment.Title = requestBody.Title
 		assignment.Description = requestBody.Description
-		assignment.DueDate = now
+		err = applyAssignmentSchedule(&assignment, assignmentSchedule{
+			dueDate:            requestBody.DueDate,
+			availableFrom:      requestBody.AvailableFrom,
+			availableUntil:     requestBody.AvailableUntil,
+			latePolicy:         requestBody.LatePolicy,
+			latePenaltyPercent: requestBody.LatePenaltyPercent,
+		})
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("invalid assignment schedule: %s", err.Error()), zap.Error(err))
+			return
+		}
 		assignment.TotalPoints = requestBody.TotalPoints
 		assignment.IsPublished = requestBody.IsPublished
+		assignment.MaxAttempts = requestBody.MaxAttempts
+		assignment.RubricID, err = s.updatedAssignmentRubric(ctx, assignment, requestBody.RubricID, tx)
+		if err != nil {
+			return
+		}
+		if requestBody.CategoryID != nil {
+			assignment.CategoryID, err = s.assignmentCategory(ctx, *requestBody.CategoryID, assignment.CourseID, tx)
+			if err != nil {
+				return
+			}
+		}
 		assignment.UpdatedBy = &user.ID
 		assignment.UpdatedAt = &now
 		assignment, err = s.Repository.LearningManagement.UpdateAssignmentByID(ctx, assignment, tx)
@@ -322,21 +539,32 @@ func (s *LearningManagementService) UpdateAssignmentByID(ctx context.Context, id
 			return
 		}
 
-		response.ID = assignment.ID.String()
-		response.Title = assignment.Title
-		response.Description = assignment.Description
-		response.DueDate = assignment.DueDate.Format(time.RFC3339)
-		response.TotalPoints = assignment.TotalPoints
-		response.IsPublished = assignment.IsPublished
+		if err = s.audit(ctx, tx, auditChange{
+			action:     pkg.AUDIT_ACTION_UPDATE,
+			entityType: pkg.AUDIT_ENTITY_ASSIGNMENT,
+			entityID:   assignment.ID,
+			courseID:   &assignment.CourseID,
+			before:     before,
+			after:      assignment,
+		}); err != nil {
+			return
+		}
+
+		if assignment.IsPublished && !before.IsPublished {
+			if err = s.publish(ctx, tx, event.AssignmentPublished, assignment.ID, assignmentPublishedPayload(assignment)); err != nil {
+				return
+			}
+		}
+
+		response = payload.UpdateAssignmentResponse(assignmentToResponse(assignment))
 		return
 	})
 }
 
 func (s *LearningManagementService) CreateSubmission(ctx context.Context, id string, requestBody *payload.CreateSubmissionRequest) (response payload.CreateSubmissionResponse, err error) {
-	return response, repository.TransactionWrapper(ctx, s.Postgres, func(tx *sqlx.Tx) (err error) {
-		user, err := s.Repository.User.GetUserByID(ctx, requestBody.CreatedBy, tx)
+	return response, s.Repository.Tx.Run(ctx, repository.TxSerializable, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+		user, err := s.currentUser(ctx, tx)
 		if err != nil {
-			s.Logger.Warnf(fmt.Sprintf("failed to get user by id: %s", err.Error()), zap.Error(err))
 			return
 		}
 
@@ -346,108 +574,160 @@ func (s *LearningManagementService) CreateSubmission(ctx context.Context, id str
 			return
 		}
 
-		switch user.Role {
-		case pkg.ROLE_STUDENT:
-			student, err := s.Repository.User.GetStudentByID(ctx, user.ID.String(), tx)
-			if err != nil {
-				s.Logger.Warnf(fmt.Sprintf("failed to get student by id: %s", err.Error()), zap.Error(err))
-				return err
-			}
+		if err = s.authorize(user, policy.SubmissionCreate, policy.Resource{}); err != nil {
+			return
+		}
 
-			submission := model.Submission{
-				BaseModel: model.BaseModel{
-					ID:        uuid.New(),
-					CreatedBy: user.ID,
-					CreatedAt: time.Now(),
-				},
-				AssignmentID: assignment.ID,
-				StudentID:    student.UserID,
-				SubmittedAt:  time.Now(),
-				TeacherID:    assignment.TeacherID,
-				Content:      requestBody.Content,
-			}
-			if requestBody.FileURL != "" {
-				submission.FileURL = &requestBody.FileURL
-			}
+		student, err := s.Repository.User.GetStudentByID(ctx, user.ID.String(), tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to get student by id: %s", err.Error()), zap.Error(err))
+			return
+		}
 
-			submission, err = s.Repository.LearningManagement.CreateSubmission(ctx, submission, tx)
-			if err != nil {
-				s.Logger.Warnf(fmt.Sprintf("failed to create submission: %s", err.Error()), zap.Error(err))
-				return err
-			}
+		if err = s.checkActiveEnrollment(ctx, assignment.CourseID.String(), student.UserID.String(), tx); err != nil {
+			return
+		}
 
-			response.ID = submission.ID.String()
-		default:
-			err = pkg.NewBadRequestError("invalid role", nil)
-			s.Logger.Warnf("invalid role: %s", user.Role, zap.Error(err))
+		_, err = s.Repository.LearningManagement.GetSubmissionByAssignmentAndStudentID(ctx, assignment.ID.String(), student.UserID.String(), tx)
+		switch {
+		case err == nil:
+			err = pkg.NewBadRequestError("assignment already submitted, update the submission to hand in a new attempt", nil)
+			s.Logger.Warnf("assignment %s already submitted by %s", assignment.ID, student.UserID, zap.Error(err))
+			return
+		case !pkg.IsNotFound(err):
+			s.Logger.Warnf(fmt.Sprintf("failed to get submission: %s", err.Error()), zap.Error(err))
+			return
+		}
+
+		now := time.Now()
+		isLate, penaltyPercent, err := submissionLateness(assignment, now)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("submission rejected: %s", err.Error()), zap.Error(err))
+			return
+		}
+
+		submission := model.Submission{
+			BaseModel: model.BaseModel{
+				ID:        uuid.New(),
+				CreatedBy: user.ID,
+				CreatedAt: now,
+			},
+			AssignmentID:       assignment.ID,
+			StudentID:          student.UserID,
+			SubmittedAt:        now,
+			TeacherID:          assignment.TeacherID,
+			Content:            requestBody.Content,
+			IsLate:             isLate,
+			LatePenaltyPercent: penaltyPercent,
+			AttemptCount:       1,
+		}
+		if requestBody.FileURL != "" {
+			submission.FileURL = &requestBody.FileURL
+		}
+
+		submission, err = s.Repository.LearningManagement.CreateSubmission(ctx, submission, tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to create submission: %s", err.Error()), zap.Error(err))
+			return
+		}
+
+		if _, err = s.Repository.LearningManagement.CreateSubmissionAttempt(ctx, newSubmissionAttempt(submission, user.ID), tx); err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to create submission attempt: %s", err.Error()), zap.Error(err))
+			return
+		}
+
+		if err = s.audit(ctx, tx, auditChange{
+			action:     pkg.AUDIT_ACTION_CREATE,
+			entityType: pkg.AUDIT_ENTITY_SUBMISSION,
+			entityID:   submission.ID,
+			courseID:   &assignment.CourseID,
+			after:      submission,
+		}); err != nil {
 			return
+		}
 
+		if err = s.publish(ctx, tx, event.SubmissionCreated, submission.ID, submissionEventPayload(submission, assignment.CourseID)); err != nil {
+			return
 		}
 
+		response.ID = submission.ID.String()
+
 		return
 	})
 }
 
 func (s *LearningManagementService) GetSubmissionByID(ctx context.Context, id string) (response payload.GetSubmissionResponse, err error) {
-	return response, repository.TransactionWrapper(ctx, s.Postgres, func(tx *sqlx.Tx) (err error) {
+	return response, s.Repository.Tx.Run(ctx, repository.TxReadOnly, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+		user, err := s.currentUser(ctx, tx)
+		if err != nil {
+			return
+		}
+
 		submission, err := s.Repository.LearningManagement.GetSubmissionByID(ctx, id, tx)
 		if err != nil {
 			s.Logger.Warnf(fmt.Sprintf("failed to get submission by id: %s", err.Error()), zap.Error(err))
 			return
 		}
-		response.ID = submission.ID.String()
-		response.AssignmentID = submission.AssignmentID.String()
-		response.StudentID = submission.StudentID.String()
-		response.SubmittedAt = submission.SubmittedAt.Format(time.RFC3339)
-		response.Content = submission.Content
-		response.CreatedAt = submission.CreatedAt.Format(time.RFC3339)
-		response.CreatedBy = submission.CreatedBy.String()
-		if submission.FileURL != nil {
-			response.FileURL = *submission.FileURL
-		}
 
-		if submission.Grade != nil {
-			response.Grade = submission.Grade
-		}
-		if submission.Feedback != nil {
-			response.Feedback = submission.Feedback
+		owners, err := s.submissionOwners(ctx, submission, tx)
+		if err != nil {
+			return
 		}
-		if submission.GradedAt != nil {
-			gradedAt := submission.GradedAt.Format(time.RFC3339)
-			response.GradedAt = &gradedAt
+
+		if err = s.authorize(user, policy.SubmissionRead, owners); err != nil {
+			return
 		}
 
-		if submission.GradedBy != nil {
-			response.GradedBy = submission.GradedBy
+		response = submissionToResponse(submission)
+		response.LetterGrade, err = s.newLetterGrader().submissionLetter(ctx, submission, tx)
+		if err != nil {
+			return
 		}
+		response.CriterionScores, err = s.criterionScores(ctx, submission, tx)
 		return
 	})
 }
 
 func (s *LearningManagementService) UpdateSubmissionByID(ctx context.Context, id string, requestBody *payload.UpdateSubmissionRequest) (response payload.UpdateSubmissionResponse, err error) {
-	return response, repository.TransactionWrapper(ctx, s.Postgres, func(tx *sqlx.Tx) (err error) {
-		user, err := s.Repository.User.GetUserByID(ctx, requestBody.UserID, tx)
+	return response, s.Repository.Tx.Run(ctx, repository.TxSerializable, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+		user, err := s.currentUser(ctx, tx)
 		if err != nil {
-			s.Logger.Warnf(fmt.Sprintf("failed to get user by id: %s", err.Error()), zap.Error(err))
 			return
 		}
 
-		submission, err := s.Repository.LearningManagement.GetSubmissionByID(ctx, id, tx)
+		// locked so edits at the same time do not number their attempts alike
+		submission, err := s.Repository.LearningManagement.GetSubmissionByIDForUpdate(ctx, id, tx)
 		if err != nil {
 			s.Logger.Warnf(fmt.Sprintf("failed to get submission by id: %s", err.Error()), zap.Error(err))
 			return
 		}
 
+		before := submission
 		now := time.Now()
-		switch user.Role {
-		case pkg.ROLE_TEACHER:
-			_, err := s.Repository.User.GetTeacherByID(ctx, user.ID.String(), tx)
+		var raised event.Type
+		switch {
+		case s.Policy.AllowsRole(user.Role, policy.SubmissionGrade):
+			owners, err := s.submissionOwners(ctx, submission, tx)
 			if err != nil {
-				s.Logger.Warnf(fmt.Sprintf("failed to get teacher by id: %s", err.Error()), zap.Error(err))
 				return err
 			}
-			if requestBody.Grade != 0 {
-				submission.Grade = &requestBody.Grade
+			if err = s.authorize(user, policy.SubmissionGrade, owners); err != nil {
+				return err
+			}
+			rawGrade, err := s.gradeFromRequest(ctx, submission, requestBody, user.ID, now, tx)
+			if err != nil {
+				return err
+			}
+			if rawGrade != nil {
+				attempt, err := s.attemptToGrade(ctx, submission, requestBody.AttemptID, tx)
+				if err != nil {
+					return err
+				}
+				// the late penalty recorded when the attempt was handed in is applied on top of the teacher's grade
+				grade := applyLatePenalty(*rawGrade, attempt.LatePenaltyPercent)
+				submission.GradedAttemptID = &attempt.ID
+				submission.RawGrade = rawGrade
+				submission.Grade = &grade
 				submission.GradedAt = &now
 				userID := user.ID.String()
 				submission.GradedBy = &userID
@@ -455,25 +735,56 @@ func (s *LearningManagementService) UpdateSubmissionByID(ctx context.Context, id
 			if requestBody.Feedback != "" {
 				submission.Feedback = &requestBody.Feedback
 			}
+			if rawGrade != nil || requestBody.Feedback != "" {
+				raised = event.SubmissionGraded
+			}
 			submission.UpdatedBy = &user.ID
 			submission.UpdatedAt = &now
-		case pkg.ROLE_STUDENT:
+		default:
+			if err = s.authorize(user, policy.SubmissionUpdate, policy.OwnedBy(submission.StudentID)); err != nil {
+				return
+			}
 			student, err := s.Repository.User.GetStudentByID(ctx, user.ID.String(), tx)
 			if err != nil {
 				s.Logger.Warnf(fmt.Sprintf("failed to get student by id: %s", err.Error()), zap.Error(err))
 				return err
 			}
+			assignment, err := s.Repository.LearningManagement.GetAssignmentByID(ctx, submission.AssignmentID.String(), tx)
+			if err != nil {
+				s.Logger.Warnf(fmt.Sprintf("failed to get assignment by id: %s", err.Error()), zap.Error(err))
+				return err
+			}
+			// a student dropped from the course hands in nothing more, like CreateSubmission
+			if err = s.checkActiveEnrollment(ctx, assignment.CourseID.String(), student.UserID.String(), tx); err != nil {
+				return err
+			}
+			if assignment.MaxAttempts > 0 && submission.AttemptCount >= assignment.MaxAttempts {
+				err = pkg.NewBadRequestError(fmt.Sprintf("maximum of %d attempts reached", assignment.MaxAttempts), nil)
+				s.Logger.Warnf("attempt limit reached for submission %s", submission.ID, zap.Error(err))
+				return err
+			}
+			// every edit hands the work in again as a new attempt, checked against the new time
+			isLate, penaltyPercent, err := submissionLateness(assignment, now)
+			if err != nil {
+				s.Logger.Warnf(fmt.Sprintf("submission rejected: %s", err.Error()), zap.Error(err))
+				return err
+			}
 			submission.StudentID = student.UserID
+			submission.SubmittedAt = now
+			submission.IsLate = isLate
+			submission.LatePenaltyPercent = penaltyPercent
 			submission.Content = requestBody.Content
+			submission.AttemptCount++
 			submission.UpdatedBy = &user.ID
 			submission.UpdatedAt = &now
 			if requestBody.FileURL != "" {
 				submission.FileURL = &requestBody.FileURL
 			}
-		default:
-			err = pkg.NewBadRequestError("invalid role", nil)
-			s.Logger.Warnf("invalid role: %s", user.Role, zap.Error(err))
-			return
+			if _, err = s.Repository.LearningManagement.CreateSubmissionAttempt(ctx, newSubmissionAttempt(submission, user.ID), tx); err != nil {
+				s.Logger.Warnf(fmt.Sprintf("failed to create submission attempt: %s", err.Error()), zap.Error(err))
+				return err
+			}
+			raised = event.SubmissionResubmitted
 		}
 
 		submission, err = s.Repository.LearningManagement.UpdateSubmissionByID(ctx, submission, tx)
@@ -482,6 +793,41 @@ func (s *LearningManagementService) UpdateSubmissionByID(ctx context.Context, id
 			return
 		}
 
+		// grades are overwritten in place, the audit log keeps the grade, feedback and grader that came before
+		courseID, err := s.submissionCourseID(ctx, submission, tx)
+		if err != nil {
+			return
+		}
+		if err = s.audit(ctx, tx, auditChange{
+			action:     pkg.AUDIT_ACTION_UPDATE,
+			entityType: pkg.AUDIT_ENTITY_SUBMISSION,
+			entityID:   submission.ID,
+			courseID:   courseID,
+			before:     before,
+			after:      submission,
+		}); err != nil {
+			return
+		}
+
+		switch raised {
+		case event.SubmissionGraded:
+			err = s.publish(ctx, tx, raised, submission.ID, event.SubmissionGradedPayload{
+				SubmissionID: submission.ID,
+				AssignmentID: submission.AssignmentID,
+				CourseID:     *courseID,
+				StudentID:    submission.StudentID,
+				GradedBy:     user.ID,
+				Grade:        submission.Grade,
+				RawGrade:     submission.RawGrade,
+				Feedback:     submission.Feedback,
+			})
+		case event.SubmissionResubmitted:
+			err = s.publish(ctx, tx, raised, submission.ID, submissionEventPayload(submission, *courseID))
+		}
+		if err != nil {
+			return
+		}
+
 		response.ID = submission.ID.String()
 		response.AssignmentID = submission.AssignmentID.String()
 		response.StudentID = submission.StudentID.String()
@@ -505,155 +851,153 @@ func (s *LearningManagementService) UpdateSubmissionByID(ctx context.Context, id
 		if submission.GradedBy != nil {
 			response.GradedBy = submission.GradedBy
 		}
+		response.IsLate = submission.IsLate
+		response.LatePenaltyPercent = submission.LatePenaltyPercent
+		response.RawGrade = submission.RawGrade
+		response.AttemptCount = submission.AttemptCount
+		if submission.GradedAttemptID != nil {
+			gradedAttemptID := submission.GradedAttemptID.String()
+			response.GradedAttemptID = &gradedAttemptID
+		}
+		response.LetterGrade, err = s.newLetterGrader().submissionLetter(ctx, submission, tx)
+		if err != nil {
+			return
+		}
+		response.CriterionScores, err = s.criterionScores(ctx, submission, tx)
 		return
 	})
 }
 
-func (s *LearningManagementService) GetAllSubmissionsByCourseID(ctx context.Context, courseID string, userID string) (response payload.GetAllSubmissionsByCourseID, err error) {
-	return response, repository.TransactionWrapper(ctx, s.Postgres, func(tx *sqlx.Tx) (err error) {
-		user, err := s.Repository.User.GetUserByID(ctx, userID, tx)
+func (s *LearningManagementService) GetAllSubmissionsByCourseID(ctx context.Context, courseID string, query pkg.ListQuery) (response payload.GetAllSubmissionsByCourseID, meta payload.MetaResponse, err error) {
+	return response, meta, s.Repository.Tx.Run(ctx, repository.TxReadOnly, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+		user, err := s.currentUser(ctx, tx)
 		if err != nil {
-			s.Logger.Warnf(fmt.Sprintf("failed to get user by id: %s", err.Error()), zap.Error(err))
 			return
 		}
 
-		switch user.Role {
-		case pkg.ROLE_TEACHER:
-			_, err := s.Repository.User.GetTeacherByID(ctx, user.ID.String(), tx)
+		course, err := s.Repository.LearningManagement.GetCourseByID(ctx, courseID, tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to get course by id: %s", err.Error()), zap.Error(err))
+			return
+		}
+
+		// reviewers see every submission, everyone else only their own
+		reviewer := s.Policy.AllowsRole(user.Role, policy.SubmissionReview)
+		switch {
+		case reviewer:
+			owners, err := s.courseOwners(ctx, course, tx)
 			if err != nil {
-				s.Logger.Warnf(fmt.Sprintf("failed to get teacher by id: %s", err.Error()), zap.Error(err))
 				return err
 			}
-		case pkg.ROLE_STUDENT:
-			_, err := s.Repository.User.GetStudentByID(ctx, user.ID.String(), tx)
-			if err != nil {
-				s.Logger.Warnf(fmt.Sprintf("failed to get student by id: %s", err.Error()), zap.Error(err))
+			if err = s.authorize(user, policy.SubmissionReview, owners); err != nil {
 				return err
 			}
 		default:
-			err = pkg.NewBadRequestError("invalid role", nil)
-			s.Logger.Warnf("invalid role: %s", user.Role, zap.Error(err))
-			return
+			// everyone else may only look at a course they are enrolled in
+			if err = s.checkActiveEnrollment(ctx, course.ID.String(), user.ID.String(), tx); err != nil {
+				return
+			}
 		}
 
-		course, err := s.Repository.LearningManagement.GetCourseByID(ctx, courseID, tx)
+		// the page runs over the assignments of the course, each listed with all of its submissions
+		assignments, page, err := s.Repository.LearningManagement.ListAssignmentsByCourseID(ctx, course.ID.String(), query, tx)
 		if err != nil {
-			s.Logger.Warnf(fmt.Sprintf("failed to get course by id: %s", err.Error()), zap.Error(err))
+			s.Logger.Warnf(fmt.Sprintf("failed to get assignments by course id: %s", err.Error()), zap.Error(err))
 			return
 		}
+		meta = pageToMeta(page)
 
-		assignments, err := s.Repository.LearningManagement.GetAllAssignmentsByCourseID(ctx, course.ID.String(), tx)
-		if err != nil {
-			s.Logger.Warnf(fmt.Sprintf("failed to get assignment by id: %s", err.Error()), zap.Error(err))
-			return
+		response.CourseID = course.ID.String()
+		response.Title = course.Name
+		response.Description = course.Description
+		response.DueDate = course.EndDate.Format(time.RFC3339)
+		response.CreatedAt = course.CreatedAt.Format(time.RFC3339)
+		response.CreatedBy = course.CreatedBy.String()
+		assignmentIDs := make([]uuid.UUID, len(assignments))
+		for i, assignment := range assignments {
+			assignmentIDs[i] = assignment.ID
 		}
-
-		for _, assignment := range assignments {
-			submissions, err := s.Repository.LearningManagement.GetAllSubmissionsByAssignmentID(ctx, assignment.ID.String(), tx)
+		var submissions []model.Submission
+		if len(assignments) > 0 {
+			submissions, err = s.Repository.LearningManagement.GetAllSubmissionsByCourseID(ctx, course.ID.String(), assignmentIDs, tx)
 			if err != nil {
-				s.Logger.Warnf(fmt.Sprintf("failed to get submission by id: %s", err.Error()), zap.Error(err))
-				return err
+				s.Logger.Warnf(fmt.Sprintf("failed to get submissions by course id: %s", err.Error()), zap.Error(err))
+				return
 			}
-			response.CourseID = course.ID.String()
-			response.Title = course.Name
-			response.Description = course.Description
-			response.DueDate = course.EndDate.Format(time.RFC3339)
-			response.CreatedAt = course.CreatedAt.Format(time.RFC3339)
-			response.CreatedBy = course.CreatedBy.String()
-			response.Assignments = make([]payload.AssignmentAndSubmissions, len(assignments))
-			for i, assignment := range assignments {
-				response.Assignments[i].AssignmentID = assignment.ID.String()
-				response.Assignments[i].Title = assignment.Title
-				response.Assignments[i].Description = assignment.Description
-				response.Assignments[i].DueDate = assignment.DueDate.Format(time.RFC3339)
-				response.Assignments[i].TotalPoints = assignment.TotalPoints
-				response.Assignments[i].IsPublished = assignment.IsPublished
-				response.Assignments[i].CreatedAt = assignment.CreatedAt.Format(time.RFC3339)
-				response.Assignments[i].CreatedBy = assignment.CreatedBy.String()
-				response.Assignments[i].Submissions = make([]payload.GetSubmissionResponse, len(submissions))
-				for j, submission := range submissions {
-					response.Assignments[i].Submissions[j].ID = submission.ID.String()
-					response.Assignments[i].Submissions[j].AssignmentID = submission.AssignmentID.String()
-					response.Assignments[i].Submissions[j].StudentID = submission.StudentID.String()
-					response.Assignments[i].Submissions[j].SubmittedAt = submission.SubmittedAt.Format(time.RFC3339)
-					response.Assignments[i].Submissions[j].Content = submission.Content
-					response.Assignments[i].Submissions[j].CreatedAt = submission.CreatedAt.Format(time.RFC3339)
-					response.Assignments[i].Submissions[j].CreatedBy = submission.CreatedBy.String()
-					if submission.FileURL != nil {
-						response.Assignments[i].Submissions[j].FileURL = *submission.FileURL
-					}
-					if submission.Grade != nil {
-						response.Assignments[i].Submissions[j].Grade = submission.Grade
-					}
-					if submission.Feedback != nil {
-						response.Assignments[i].Submissions[j].Feedback = submission.Feedback
-					}
-					if submission.GradedAt != nil {
-						gradedAt := submission.GradedAt.Format(time.RFC3339)
-						response.Assignments[i].Submissions[j].GradedAt = &gradedAt
-					}
-					if submission.GradedBy != nil {
-						response.Assignments[i].Submissions[j].GradedBy = submission.GradedBy
-					}
+		}
+		submissionsByAssignment := groupSubmissionsByAssignment(submissions)
+
+		scheme, err := s.courseGradingScheme(ctx, course, tx)
+		if err != nil {
+			return
+		}
+		grader := s.newLetterGrader()
+		grader.addCourse(course.ID, scheme, assignments)
+
+		response.Assignments = make([]payload.AssignmentAndSubmissions, len(assignments))
+		for i, assignment := range assignments {
+			assignmentSubmissions := submissionsByAssignment[assignment.ID]
+			response.Assignments[i].AssignmentID = assignment.ID.String()
+			response.Assignments[i].Title = assignment.Title
+			response.Assignments[i].Description = assignment.Description
+			response.Assignments[i].DueDate = assignment.DueDate.Format(time.RFC3339)
+			response.Assignments[i].TotalPoints = assignment.TotalPoints
+			response.Assignments[i].IsPublished = assignment.IsPublished
+			response.Assignments[i].CreatedAt = assignment.CreatedAt.Format(time.RFC3339)
+			response.Assignments[i].CreatedBy = assignment.CreatedBy.String()
+			response.Assignments[i].Submissions = make([]payload.GetSubmissionResponse, 0, len(assignmentSubmissions))
+			for _, submission := range assignmentSubmissions {
+				if !reviewer && submission.StudentID != user.ID {
+					continue
+				}
+				submissionResponse := submissionToResponse(submission)
+				submissionResponse.LetterGrade, err = grader.submissionLetter(ctx, submission, tx)
+				if err != nil {
+					return err
 				}
+				response.Assignments[i].Submissions = append(response.Assignments[i].Submissions, submissionResponse)
 			}
 		}
 		return
 	})
 }
 
-func (s *LearningManagementService) GetAllSubmissionsByAssignmentID(ctx context.Context, assignmentID string, userID string) (response payload.GetAllSubmissionsResponse, err error) {
-	return response, repository.TransactionWrapper(ctx, s.Postgres, func(tx *sqlx.Tx) (err error) {
-		user, err := s.Repository.User.GetUserByID(ctx, userID, tx)
+func (s *LearningManagementService) GetAllSubmissionsByAssignmentID(ctx context.Context, assignmentID string, query pkg.ListQuery) (response payload.GetAllSubmissionsResponse, meta payload.MetaResponse, err error) {
+	return response, meta, s.Repository.Tx.Run(ctx, repository.TxReadOnly, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+		user, err := s.currentUser(ctx, tx)
 		if err != nil {
-			s.Logger.Warnf(fmt.Sprintf("failed to get user by id: %s", err.Error()), zap.Error(err))
 			return
 		}
 
-		switch user.Role {
-		case pkg.ROLE_TEACHER:
-			_, err := s.Repository.User.GetTeacherByID(ctx, user.ID.String(), tx)
-			if err != nil {
-				s.Logger.Warnf(fmt.Sprintf("failed to get teacher by id: %s", err.Error()), zap.Error(err))
-				return err
-			}
-		default:
-			err = pkg.NewBadRequestError("invalid role", nil)
-			s.Logger.Warnf("invalid role: %s", user.Role, zap.Error(err))
+		assignment, err := s.Repository.LearningManagement.GetAssignmentByID(ctx, assignmentID, tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to get assignment by id: %s", err.Error()), zap.Error(err))
 			return
 		}
 
-		submissions, err := s.Repository.LearningManagement.GetAllSubmissionsByAssignmentID(ctx, assignmentID, tx)
+		owners, err := s.assignmentOwners(ctx, assignment, tx)
 		if err != nil {
-			s.Logger.Warnf(fmt.Sprintf("failed to get submission by id: %s", err.Error()), zap.Error(err))
 			return
 		}
 
+		if err = s.authorize(user, policy.SubmissionReview, owners); err != nil {
+			return
+		}
+
+		submissions, page, err := s.Repository.LearningManagement.ListSubmissionsByAssignmentID(ctx, assignment.ID.String(), query, tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to get submissions by assignment id: %s", err.Error()), zap.Error(err))
+			return
+		}
+		meta = pageToMeta(page)
+
+		grader := s.newLetterGrader()
 		response.Submissions = make([]payload.GetSubmissionResponse, len(submissions))
 		for i, submission := range submissions {
-			response.Submissions[i].ID = submission.ID.String()
-			response.Submissions[i].AssignmentID = submission.AssignmentID.String()
-			response.Submissions[i].StudentID = submission.StudentID.String()
-			response.Submissions[i].SubmittedAt = submission.SubmittedAt.Format(time.RFC3339)
-			response.Submissions[i].Content = submission.Content
-			response.Submissions[i].CreatedAt = submission.CreatedAt.Format(time.RFC3339)
-			response.Submissions[i].CreatedBy = submission.CreatedBy.String()
-			if submission.FileURL != nil {
-				response.Submissions[i].FileURL = *submission.FileURL
-			}
-
-			if submission.Grade != nil {
-				response.Submissions[i].Grade = submission.Grade
-			}
-			if submission.Feedback != nil {
-				response.Submissions[i].Feedback = submission.Feedback
-			}
-			if submission.GradedAt != nil {
-				gradedAt := submission.GradedAt.Format(time.RFC3339)
-				response.Submissions[i].GradedAt = &gradedAt
-			}
-			if submission.GradedBy != nil {
-				response.Submissions[i].GradedBy = submission.GradedBy
+			response.Submissions[i] = submissionToResponse(submission)
+			response.Submissions[i].LetterGrade, err = grader.submissionLetter(ctx, submission, tx)
+			if err != nil {
+				return
 			}
 		}
 
@@ -661,38 +1005,44 @@ func (s *LearningManagementService) GetAllSubmissionsByAssignmentID(ctx context.
 	})
 }
 
-func (s *LearningManagementService) GetAllSubmissionsByUserID(ctx context.Context, userID string) (response payload.GetAllSubmissionsResponse, err error) {
-	return response, repository.TransactionWrapper(ctx, s.Postgres, func(tx *sqlx.Tx) (err error) {
-		user, err := s.Repository.User.GetUserByID(ctx, userID, tx)
+func (s *LearningManagementService) GetAllSubmissionsByUserID(ctx context.Context, id string, query pkg.ListQuery) (response payload.GetAllSubmissionsResponse, meta payload.MetaResponse, err error) {
+	return response, meta, s.Repository.Tx.Run(ctx, repository.TxReadOnly, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+		actor, err := s.currentUser(ctx, tx)
+		if err != nil {
+			return
+		}
+
+		user, err := s.Repository.User.GetUserByID(ctx, id, tx)
 		if err != nil {
 			s.Logger.Warnf(fmt.Sprintf("failed to get user by id: %s", err.Error()), zap.Error(err))
 			return
 		}
 
-		var submissions []model.Submission
+		if err = s.authorize(actor, policy.SubmissionRead, policy.OwnedBy(user.ID)); err != nil {
+			return
+		}
+
+		var (
+			submissions []model.Submission
+			page        pkg.ListPage
+		)
 		switch user.Role {
 		case pkg.ROLE_TEACHER:
-			assignment, err := s.Repository.LearningManagement.GetAssignmentByTeacherID(ctx, user.ID.String(), tx)
-			if err != nil {
-				s.Logger.Warnf(fmt.Sprintf("failed to get assignment by id: %s", err.Error()), zap.Error(err))
-				return err
-			}
-
-			submissions, err = s.Repository.LearningManagement.GetAllSubmissionsByAssignmentID(ctx, assignment.ID.String(), tx)
+			// a teacher sees the submissions to grade in every assignment and course they teach
+			submissions, page, err = s.Repository.LearningManagement.ListSubmissionsByTeacherID(ctx, user.ID.String(), query, tx)
 			if err != nil {
-				s.Logger.Warnf(fmt.Sprintf("failed to get submission by id: %s", err.Error()), zap.Error(err))
-				return err
+				s.Logger.Warnf(fmt.Sprintf("failed to get submissions by teacher id: %s", err.Error()), zap.Error(err))
+				return
 			}
-
 		case pkg.ROLE_STUDENT:
 			student, err := s.Repository.User.GetStudentByID(ctx, user.ID.String(), tx)
 			if err != nil {
 				s.Logger.Warnf(fmt.Sprintf("failed to get student by id: %s", err.Error()), zap.Error(err))
 				return err
 			}
-			submissions, err = s.Repository.LearningManagement.GetAllSubmissionsByAssignmentID(ctx, student.StudentID, tx)
+			submissions, page, err = s.Repository.LearningManagement.ListSubmissionsByStudentID(ctx, student.UserID.String(), query, tx)
 			if err != nil {
-				s.Logger.Warnf(fmt.Sprintf("failed to get submission by id: %s", err.Error()), zap.Error(err))
+				s.Logger.Warnf(fmt.Sprintf("failed to get submissions by student id: %s", err.Error()), zap.Error(err))
 				return err
 			}
 		default:
@@ -701,32 +1051,340 @@ func (s *LearningManagementService) GetAllSubmissionsByUserID(ctx context.Contex
 			return
 		}
 
+		meta = pageToMeta(page)
+		grader := s.newLetterGrader()
 		response.Submissions = make([]payload.GetSubmissionResponse, len(submissions))
 		for i, submission := range submissions {
-			response.Submissions[i].ID = submission.ID.String()
-			response.Submissions[i].AssignmentID = submission.AssignmentID.String()
-			response.Submissions[i].StudentID = submission.StudentID.String()
-			response.Submissions[i].SubmittedAt = submission.SubmittedAt.Format(time.RFC3339)
-			response.Submissions[i].Content = submission.Content
-			if submission.FileURL != nil {
-				response.Submissions[i].FileURL = *submission.FileURL
+			response.Submissions[i] = submissionToResponse(submission)
+			response.Submissions[i].LetterGrade, err = grader.submissionLetter(ctx, submission, tx)
+			if err != nil {
+				return
 			}
+		}
 
-			if submission.Grade != nil {
-				response.Submissions[i].Grade = submission.Grade
-			}
-			if submission.Feedback != nil {
-				response.Submissions[i].Feedback = submission.Feedback
+		return
+	})
+}
+
+func (s *LearningManagementService) GetAllSubmissionAttempts(ctx context.Context, submissionID string, query pkg.ListQuery) (response payload.GetAllSubmissionAttemptsResponse, meta payload.MetaResponse, err error) {
+	return response, meta, s.Repository.Tx.Run(ctx, repository.TxReadOnly, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+		user, err := s.currentUser(ctx, tx)
+		if err != nil {
+			return
+		}
+
+		submission, err := s.Repository.LearningManagement.GetSubmissionByID(ctx, submissionID, tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to get submission by id: %s", err.Error()), zap.Error(err))
+			return
+		}
+
+		owners, err := s.submissionOwners(ctx, submission, tx)
+		if err != nil {
+			return
+		}
+
+		if err = s.authorize(user, policy.SubmissionRead, owners); err != nil {
+			return
+		}
+
+		assignment, err := s.Repository.LearningManagement.GetAssignmentByID(ctx, submission.AssignmentID.String(), tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to get assignment by id: %s", err.Error()), zap.Error(err))
+			return
+		}
+
+		attempts, page, err := s.Repository.LearningManagement.ListSubmissionAttemptsBySubmissionID(ctx, submission.ID.String(), query, tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to get submission attempts: %s", err.Error()), zap.Error(err))
+			return
+		}
+		meta = pageToMeta(page)
+
+		response.SubmissionID = submission.ID.String()
+		response.MaxAttempts = assignment.MaxAttempts
+		if submission.GradedAttemptID != nil {
+			gradedAttemptID := submission.GradedAttemptID.String()
+			response.GradedAttemptID = &gradedAttemptID
+		}
+		response.Attempts = make([]payload.SubmissionAttemptResponse, len(attempts))
+		for i, attempt := range attempts {
+			response.Attempts[i].ID = attempt.ID.String()
+			response.Attempts[i].AttemptNumber = attempt.AttemptNumber
+			response.Attempts[i].Content = attempt.Content
+			response.Attempts[i].FileURL = attempt.FileURL
+			response.Attempts[i].SubmittedAt = attempt.SubmittedAt.Format(time.RFC3339)
+			response.Attempts[i].IsLate = attempt.IsLate
+			response.Attempts[i].LatePenaltyPercent = attempt.LatePenaltyPercent
+			response.Attempts[i].IsGraded = submission.GradedAttemptID != nil && *submission.GradedAttemptID == attempt.ID
+		}
+		return
+	})
+}
+
+// DiffSubmissionAttempts compares the content of two attempts, by default the latest one against its predecessor
+func (s *LearningManagementService) DiffSubmissionAttempts(ctx context.Context, submissionID string, from int, to int) (response payload.SubmissionAttemptDiffResponse, err error) {
+	return response, s.Repository.Tx.Run(ctx, repository.TxReadOnly, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+		user, err := s.currentUser(ctx, tx)
+		if err != nil {
+			return
+		}
+
+		submission, err := s.Repository.LearningManagement.GetSubmissionByID(ctx, submissionID, tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to get submission by id: %s", err.Error()), zap.Error(err))
+			return
+		}
+
+		owners, err := s.submissionOwners(ctx, submission, tx)
+		if err != nil {
+			return
+		}
+
+		if err = s.authorize(user, policy.SubmissionRead, owners); err != nil {
+			return
+		}
+
+		if to == 0 {
+			to = submission.AttemptCount
+		}
+		if from == 0 {
+			from = to - 1
+		}
+		if from < 1 || to > submission.AttemptCount || from >= to {
+			err = pkg.NewBadRequestError(fmt.Sprintf("attempts to compare must satisfy 1 <= from < to <= %d", submission.AttemptCount), nil)
+			s.Logger.Warnf("invalid attempt range %d..%d", from, to, zap.Error(err))
+			return
+		}
+
+		older, err := s.Repository.LearningManagement.GetSubmissionAttemptByNumber(ctx, submission.ID.String(), from, tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to get submission attempt: %s", err.Error()), zap.Error(err))
+			return
+		}
+		newer, err := s.Repository.LearningManagement.GetSubmissionAttemptByNumber(ctx, submission.ID.String(), to, tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to get submission attempt: %s", err.Error()), zap.Error(err))
+			return
+		}
+
+		lines, err := pkg.DiffLines(older.Content, newer.Content)
+		if errors.Is(err, pkg.ErrDiffTooLarge) {
+			err = pkg.NewError("DIFF_TOO_LARGE", fmt.Sprintf("attempts differ in more than %d lines", pkg.DIFF_MAX_LINES), http.StatusUnprocessableEntity, err)
+		}
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to diff submission attempts: %s", err.Error()), zap.Error(err))
+			return
+		}
+		response.SubmissionID = submission.ID.String()
+		response.From = from
+		response.To = to
+		response.Lines = make([]payload.DiffLineResponse, len(lines))
+		for i, line := range lines {
+			response.Lines[i].Op = line.Op
+			response.Lines[i].Text = line.Text
+		}
+		return
+	})
+}
+
+func (s *LearningManagementService) EnrollStudent(ctx context.Context, courseID string, requestBody *payload.EnrollStudentRequest) (response payload.EnrollmentResponse, err error) {
+	return response, s.Repository.Tx.Run(ctx, repository.TxSerializable, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+		user, err := s.currentUser(ctx, tx)
+		if err != nil {
+			return
+		}
+
+		course, err := s.Repository.LearningManagement.GetCourseByID(ctx, courseID, tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to get course by id: %s", err.Error()), zap.Error(err))
+			return
+		}
+
+		owners, err := s.courseOwners(ctx, course, tx)
+		if err != nil {
+			return
+		}
+
+		if err = s.authorize(user, policy.EnrollmentManage, owners); err != nil {
+			return
+		}
+
+		student, err := s.Repository.User.GetStudentByID(ctx, requestBody.StudentID, tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to get student by id: %s", err.Error()), zap.Error(err))
+			return
+		}
+
+		now := time.Now()
+		change := auditChange{entityType: pkg.AUDIT_ENTITY_ENROLLMENT, courseID: &course.ID}
+		enrollment, err := s.Repository.LearningManagement.GetEnrollmentByCourseAndStudentID(ctx, course.ID.String(), student.UserID.String(), tx)
+		switch {
+		case err == nil && enrollment.Status == pkg.ENROLLMENT_STATUS_ACTIVE:
+			err = pkg.NewBadRequestError("student already enrolled", nil)
+			s.Logger.Warnf("student already enrolled: %s", student.UserID, zap.Error(err))
+			return
+		case err == nil:
+			// re-enrolling a student that dropped keeps the same roster row
+			change.action, change.before = pkg.AUDIT_ACTION_UPDATE, enrollment
+			enrollment.Status = pkg.ENROLLMENT_STATUS_ACTIVE
+			enrollment.EnrolledAt = now
+			enrollment.DroppedAt = nil
+			enrollment.UpdatedBy = &user.ID
+			enrollment.UpdatedAt = &now
+			enrollment, err = s.Repository.LearningManagement.UpdateEnrollmentByID(ctx, enrollment, tx)
+			if err != nil {
+				s.Logger.Warnf(fmt.Sprintf("failed to update enrollment: %s", err.Error()), zap.Error(err))
+				return
 			}
-			if submission.GradedAt != nil {
-				gradedAt := submission.GradedAt.Format(time.RFC3339)
-				response.Submissions[i].GradedAt = &gradedAt
+		case pkg.IsNotFound(err):
+			change.action = pkg.AUDIT_ACTION_CREATE
+			enrollment = model.Enrollment{
+				BaseModel: model.BaseModel{
+					ID:        uuid.New(),
+					CreatedBy: user.ID,
+					CreatedAt: now,
+				},
+				CourseID:   course.ID,
+				StudentID:  student.UserID,
+				Status:     pkg.ENROLLMENT_STATUS_ACTIVE,
+				EnrolledAt: now,
 			}
-			if submission.GradedBy != nil {
-				response.Submissions[i].GradedBy = submission.GradedBy
+			enrollment, err = s.Repository.LearningManagement.CreateEnrollment(ctx, enrollment, tx)
+			if err != nil {
+				s.Logger.Warnf(fmt.Sprintf("failed to create enrollment: %s", err.Error()), zap.Error(err))
+				return
 			}
+		default:
+			s.Logger.Warnf(fmt.Sprintf("failed to get enrollment: %s", err.Error()), zap.Error(err))
+			return
+		}
+
+		change.entityID, change.after = enrollment.ID, enrollment
+		if err = s.audit(ctx, tx, change); err != nil {
+			return
+		}
+
+		if err = s.publish(ctx, tx, event.StudentEnrolled, enrollment.ID, enrollmentEventPayload(enrollment)); err != nil {
+			return
+		}
+
+		response = enrollmentToResponse(enrollment)
+		return
+	})
+}
+
+func (s *LearningManagementService) DropEnrollment(ctx context.Context, courseID string, studentID string) (response payload.EnrollmentResponse, err error) {
+	return response, s.Repository.Tx.Run(ctx, repository.TxReadWrite, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+		user, err := s.currentUser(ctx, tx)
+		if err != nil {
+			return
+		}
+
+		course, err := s.Repository.LearningManagement.GetCourseByID(ctx, courseID, tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to get course by id: %s", err.Error()), zap.Error(err))
+			return
+		}
+
+		owners, err := s.courseOwners(ctx, course, tx)
+		if err != nil {
+			return
+		}
+
+		if err = s.authorize(user, policy.EnrollmentManage, owners); err != nil {
+			return
+		}
+
+		enrollment, err := s.Repository.LearningManagement.GetEnrollmentByCourseAndStudentID(ctx, course.ID.String(), studentID, tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to get enrollment: %s", err.Error()), zap.Error(err))
+			return
+		}
+
+		if enrollment.Status == pkg.ENROLLMENT_STATUS_DROPPED {
+			err = pkg.NewBadRequestError("student already dropped", nil)
+			s.Logger.Warnf("student already dropped: %s", studentID, zap.Error(err))
+			return
+		}
+
+		before := enrollment
+		now := time.Now()
+		enrollment.Status = pkg.ENROLLMENT_STATUS_DROPPED
+		enrollment.DroppedAt = &now
+		enrollment.UpdatedBy = &user.ID
+		enrollment.UpdatedAt = &now
+		enrollment, err = s.Repository.LearningManagement.UpdateEnrollmentByID(ctx, enrollment, tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to update enrollment: %s", err.Error()), zap.Error(err))
+			return
+		}
+
+		if err = s.audit(ctx, tx, auditChange{
+			action:     pkg.AUDIT_ACTION_UPDATE,
+			entityType: pkg.AUDIT_ENTITY_ENROLLMENT,
+			entityID:   enrollment.ID,
+			courseID:   &course.ID,
+			before:     before,
+			after:      enrollment,
+		}); err != nil {
+			return
+		}
+
+		if err = s.publish(ctx, tx, event.StudentDropped, enrollment.ID, enrollmentEventPayload(enrollment)); err != nil {
+			return
+		}
+
+		response = enrollmentToResponse(enrollment)
+		return
+	})
+}
+
+func (s *LearningManagementService) GetAllEnrollmentsByCourseID(ctx context.Context, courseID string, query pkg.ListQuery) (response payload.GetAllEnrollmentsResponse, meta payload.MetaResponse, err error) {
+	return response, meta, s.Repository.Tx.Run(ctx, repository.TxReadOnly, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+		user, err := s.currentUser(ctx, tx)
+		if err != nil {
+			return
 		}
 
+		course, err := s.Repository.LearningManagement.GetCourseByID(ctx, courseID, tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to get course by id: %s", err.Error()), zap.Error(err))
+			return
+		}
+
+		owners, err := s.courseOwners(ctx, course, tx)
+		if err != nil {
+			return
+		}
+
+		if err = s.authorize(user, policy.EnrollmentRead, owners); err != nil {
+			return
+		}
+
+		roster, page, err := s.Repository.LearningManagement.ListEnrollmentsByCourseID(ctx, course.ID.String(), query, tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to get enrollments by course id: %s", err.Error()), zap.Error(err))
+			return
+		}
+		meta = pageToMeta(page)
+
+		response.CourseID = course.ID.String()
+		response.Enrollments = make([]payload.EnrollmentRosterResponse, len(roster))
+		for i, entry := range roster {
+			response.Enrollments[i].ID = entry.ID.String()
+			response.Enrollments[i].StudentID = entry.StudentID.String()
+			response.Enrollments[i].StudentNumber = entry.StudentNumber
+			response.Enrollments[i].FirstName = entry.FirstName
+			response.Enrollments[i].LastName = entry.LastName
+			response.Enrollments[i].Email = entry.Email
+			response.Enrollments[i].Status = entry.Status
+			response.Enrollments[i].EnrolledAt = entry.EnrolledAt.Format(time.RFC3339)
+			if entry.DroppedAt != nil {
+				droppedAt := entry.DroppedAt.Format(time.RFC3339)
+				response.Enrollments[i].DroppedAt = &droppedAt
+			}
+		}
 		return
 	})
 }
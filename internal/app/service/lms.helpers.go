@@ -0,0 +1,403 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"edukita-teaching-grading/internal/app/model"
+	"edukita-teaching-grading/internal/app/payload"
+	"edukita-teaching-grading/internal/app/policy"
+	"edukita-teaching-grading/internal/pkg"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// checkActiveEnrollment returns a forbidden error unless the student is on the active roster of the course
+func (s ServiceOption) checkActiveEnrollment(ctx context.Context, courseID string, studentID string, tx *sqlx.Tx) error {
+	enrollment, err := s.Repository.LearningManagement.GetEnrollmentByCourseAndStudentID(ctx, courseID, studentID, tx)
+	if err != nil && !pkg.IsNotFound(err) {
+		s.Logger.Warnf(fmt.Sprintf("failed to get enrollment: %s", err.Error()), zap.Error(err))
+		return err
+	}
+
+	if err != nil || enrollment.Status != pkg.ENROLLMENT_STATUS_ACTIVE {
+		err = pkg.NewForbiddenError("student is not enrolled in this course", nil)
+		s.Logger.Warnf("student is not enrolled in course %s: %s", courseID, studentID, zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// courseTeacherIDs returns the teachers co-owning the course
+func (s ServiceOption) courseTeacherIDs(ctx context.Context, courseID uuid.UUID, tx *sqlx.Tx) ([]uuid.UUID, error) {
+	teachers, err := s.Repository.LearningManagement.GetAllCourseTeachersByCourseID(ctx, courseID.String(), tx)
+	if err != nil {
+		s.Logger.Warnf(fmt.Sprintf("failed to get course teachers: %s", err.Error()), zap.Error(err))
+		return nil, err
+	}
+
+	ids := make([]uuid.UUID, len(teachers))
+	for i, teacher := range teachers {
+		ids[i] = teacher.TeacherID
+	}
+	return ids, nil
+}
+
+// courseOwners is the creator of the course and its co-teachers
+func (s ServiceOption) courseOwners(ctx context.Context, course model.Course, tx *sqlx.Tx) (policy.Resource, error) {
+	teachers, err := s.courseTeacherIDs(ctx, course.ID, tx)
+	if err != nil {
+		return policy.Resource{}, err
+	}
+	return policy.OwnedBy(append(teachers, course.CreatedBy)...), nil
+}
+
+// assignmentTeacher is the teacher an assignment created by the user belongs to, assignments created by an admin
+// go to the first teacher of the course
+func (s ServiceOption) assignmentTeacher(ctx context.Context, user model.User, course model.Course, tx *sqlx.Tx) (uuid.UUID, error) {
+	if user.Role != pkg.ROLE_ADMIN {
+		return user.ID, nil
+	}
+
+	teachers, err := s.courseTeacherIDs(ctx, course.ID, tx)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if len(teachers) == 0 {
+		err = pkg.NewBadRequestError("course has no teacher to assign the assignment to", nil)
+		s.Logger.Warnf("course %s has no teacher", course.ID, zap.Error(err))
+		return uuid.Nil, err
+	}
+	return teachers[0], nil
+}
+
+// assignmentOwners is the teacher of the assignment and the co-teachers of its course
+func (s ServiceOption) assignmentOwners(ctx context.Context, assignment model.Assignment, tx *sqlx.Tx) (policy.Resource, error) {
+	teachers, err := s.courseTeacherIDs(ctx, assignment.CourseID, tx)
+	if err != nil {
+		return policy.Resource{}, err
+	}
+	return policy.OwnedBy(append(teachers, assignment.TeacherID)...), nil
+}
+
+// authorizeAssignmentRead lets the teachers of the assignment and admins read it with everything attached to it.
+// Students only read published assignments of a course they are on the active roster of.
+func (s ServiceOption) authorizeAssignmentRead(ctx context.Context, user model.User, assignment model.Assignment, tx *sqlx.Tx) error {
+	if s.Policy.AllowsRole(user.Role, policy.AssignmentUpdate) {
+		owners, err := s.assignmentOwners(ctx, assignment, tx)
+		if err != nil {
+			return err
+		}
+		return s.authorize(user, policy.AssignmentUpdate, owners)
+	}
+
+	if err := s.checkActiveEnrollment(ctx, assignment.CourseID.String(), user.ID.String(), tx); err != nil {
+		return err
+	}
+	if !assignment.IsPublished {
+		err := pkg.NewNotFoundError("assignment not found", nil)
+		s.Logger.Warnf("assignment %s is not published", assignment.ID, zap.Error(err))
+		return err
+	}
+	return s.authorize(user, policy.AssignmentRead, policy.Resource{})
+}
+
+// submissionOwners is the student that submitted the work and the teachers owning the assignment
+func (s ServiceOption) submissionOwners(ctx context.Context, submission model.Submission, tx *sqlx.Tx) (policy.Resource, error) {
+	assignment, err := s.Repository.LearningManagement.GetAssignmentByID(ctx, submission.AssignmentID.String(), tx)
+	if err != nil {
+		s.Logger.Warnf(fmt.Sprintf("failed to get assignment by id: %s", err.Error()), zap.Error(err))
+		return policy.Resource{}, err
+	}
+
+	owners, err := s.assignmentOwners(ctx, assignment, tx)
+	if err != nil {
+		return policy.Resource{}, err
+	}
+	owners.Owners = append(owners.Owners, submission.StudentID)
+	return owners, nil
+}
+
+func submissionToResponse(submission model.Submission) (response payload.GetSubmissionResponse) {
+	response.ID = submission.ID.String()
+	response.AssignmentID = submission.AssignmentID.String()
+	response.StudentID = submission.StudentID.String()
+	response.SubmittedAt = submission.SubmittedAt.Format(time.RFC3339)
+	response.Content = submission.Content
+	response.CreatedAt = submission.CreatedAt.Format(time.RFC3339)
+	response.CreatedBy = submission.CreatedBy.String()
+	if submission.FileURL != nil {
+		response.FileURL = *submission.FileURL
+	}
+	if submission.Grade != nil {
+		response.Grade = submission.Grade
+	}
+	if submission.Feedback != nil {
+		response.Feedback = submission.Feedback
+	}
+	if submission.GradedAt != nil {
+		gradedAt := submission.GradedAt.Format(time.RFC3339)
+		response.GradedAt = &gradedAt
+	}
+	if submission.GradedBy != nil {
+		response.GradedBy = submission.GradedBy
+	}
+	response.IsLate = submission.IsLate
+	response.LatePenaltyPercent = submission.LatePenaltyPercent
+	response.RawGrade = submission.RawGrade
+	response.AttemptCount = submission.AttemptCount
+	if submission.GradedAttemptID != nil {
+		gradedAttemptID := submission.GradedAttemptID.String()
+		response.GradedAttemptID = &gradedAttemptID
+	}
+	return
+}
+
+// groupSubmissionsByAssignment splits the submissions of a course by assignment, keeping their order
+func groupSubmissionsByAssignment(submissions []model.Submission) map[uuid.UUID][]model.Submission {
+	grouped := make(map[uuid.UUID][]model.Submission)
+	for _, submission := range submissions {
+		grouped[submission.AssignmentID] = append(grouped[submission.AssignmentID], submission)
+	}
+	return grouped
+}
+
+// newSubmissionAttempt snapshots the current work of the submission as its latest attempt
+func newSubmissionAttempt(submission model.Submission, createdBy uuid.UUID) model.SubmissionAttempt {
+	return model.SubmissionAttempt{
+		ID:                 uuid.New(),
+		SubmissionID:       submission.ID,
+		AttemptNumber:      submission.AttemptCount,
+		Content:            submission.Content,
+		FileURL:            submission.FileURL,
+		SubmittedAt:        submission.SubmittedAt,
+		IsLate:             submission.IsLate,
+		LatePenaltyPercent: submission.LatePenaltyPercent,
+		CreatedBy:          createdBy,
+		CreatedAt:          submission.SubmittedAt,
+	}
+}
+
+// gradeFromRequest returns the raw grade of a grading request, computed from the criterion scores when the
+// assignment has a rubric. It is nil when the request only carries feedback.
+func (s *LearningManagementService) gradeFromRequest(ctx context.Context, submission model.Submission, requestBody *payload.UpdateSubmissionRequest, gradedBy uuid.UUID, gradedAt time.Time, tx *sqlx.Tx) (*float64, error) {
+	assignment, err := s.Repository.LearningManagement.GetAssignmentByID(ctx, submission.AssignmentID.String(), tx)
+	if err != nil {
+		s.Logger.Warnf(fmt.Sprintf("failed to get assignment by id: %s", err.Error()), zap.Error(err))
+		return nil, err
+	}
+
+	grade, err := directGrade(assignment, requestBody)
+	if err == nil && assignment.RubricID != nil && len(requestBody.Scores) > 0 {
+		var total float64
+		if total, err = s.scoreRubric(ctx, *assignment.RubricID, submission, requestBody.Scores, assignment.TotalPoints, gradedBy, gradedAt, tx); err == nil {
+			grade = &total
+		}
+	}
+	if err != nil {
+		s.Logger.Warnf(fmt.Sprintf("invalid grade: %s", err.Error()), zap.Error(err))
+		return nil, err
+	}
+	return grade, nil
+}
+
+// directGrade returns the grade sent with a grading request, nil when the request carries none. An assignment with
+// a rubric is graded by scoring its criteria and refuses a grade sent directly.
+func directGrade(assignment model.Assignment, requestBody *payload.UpdateSubmissionRequest) (*float64, error) {
+	switch {
+	case assignment.RubricID == nil && len(requestBody.Scores) > 0:
+		return nil, pkg.NewBadRequestError("assignment has no rubric to score", nil)
+	case assignment.RubricID != nil && requestBody.Grade != nil:
+		return nil, pkg.NewBadRequestError("assignment is graded with a rubric, score its criteria instead of sending a grade", nil)
+	case requestBody.Grade == nil:
+		return nil, nil
+	}
+	if err := checkGrade(*requestBody.Grade, assignment.TotalPoints); err != nil {
+		return nil, err
+	}
+	return requestBody.Grade, nil
+}
+
+// checkGrade keeps a grade between 0 and the points of the assignment
+func checkGrade(grade float64, totalPoints float64) error {
+	switch {
+	case grade < 0:
+		return pkg.NewBadRequestError(fmt.Sprintf("grade %.2f must not be negative", grade), nil)
+	case grade > totalPoints:
+		return pkg.NewBadRequestError(fmt.Sprintf("grade %.2f exceeds the %.2f points of the assignment", grade, totalPoints), nil)
+	}
+	return nil
+}
+
+// attemptToGrade returns the attempt picked by the teacher, or the latest attempt when none was picked
+func (s *LearningManagementService) attemptToGrade(ctx context.Context, submission model.Submission, attemptID string, tx *sqlx.Tx) (attempt model.SubmissionAttempt, err error) {
+	if attemptID == "" {
+		attempt, err = s.Repository.LearningManagement.GetSubmissionAttemptByNumber(ctx, submission.ID.String(), submission.AttemptCount, tx)
+	} else {
+		attempt, err = s.Repository.LearningManagement.GetSubmissionAttemptByID(ctx, attemptID, tx)
+	}
+	if err != nil {
+		s.Logger.Warnf(fmt.Sprintf("failed to get submission attempt: %s", err.Error()), zap.Error(err))
+		return
+	}
+
+	if attempt.SubmissionID != submission.ID {
+		err = pkg.NewBadRequestError("attempt does not belong to this submission", nil)
+		s.Logger.Warnf("attempt %s does not belong to submission %s", attempt.ID, submission.ID, zap.Error(err))
+		return
+	}
+	return
+}
+
+func courseTeacherToResponse(courseTeacher model.CourseTeacher) (response payload.CourseTeacherResponse) {
+	response.CourseID = courseTeacher.CourseID.String()
+	response.TeacherID = courseTeacher.TeacherID.String()
+	response.CreatedBy = courseTeacher.CreatedBy.String()
+	response.CreatedAt = courseTeacher.CreatedAt.Format(time.RFC3339)
+	return
+}
+
+// assignmentSchedule is the schedule of a create or update request. A nil field, an empty due date or an empty late
+// policy keeps what the assignment has, an empty available_from or available_until removes that end of the window.
+type assignmentSchedule struct {
+	dueDate            string
+	availableFrom      *string
+	availableUntil     *string
+	latePolicy         string
+	latePenaltyPercent *float64
+}
+
+// applyAssignmentSchedule parses the due date, availability window and late policy of a request into the assignment
+func applyAssignmentSchedule(assignment *model.Assignment, schedule assignmentSchedule) (err error) {
+	due := assignment.DueDate
+	if schedule.dueDate != "" {
+		if due, err = time.Parse(time.RFC3339, schedule.dueDate); err != nil {
+			return pkg.NewBadRequestError("due_date must be an RFC3339 timestamp", err)
+		}
+	}
+	from, until := assignment.AvailableFrom, assignment.AvailableUntil
+	if schedule.availableFrom != nil {
+		if from, err = parseOptionalTime("available_from", *schedule.availableFrom); err != nil {
+			return
+		}
+	}
+	if schedule.availableUntil != nil {
+		if until, err = parseOptionalTime("available_until", *schedule.availableUntil); err != nil {
+			return
+		}
+	}
+
+	switch {
+	case from != nil && until != nil && !from.Before(*until):
+		return pkg.NewBadRequestError("available_from must be before available_until", nil)
+	case from != nil && due.Before(*from):
+		return pkg.NewBadRequestError("due_date must not be before available_from", nil)
+	case until != nil && due.After(*until):
+		return pkg.NewBadRequestError("due_date must not be after available_until", nil)
+	}
+
+	latePolicy := cmp.Or(schedule.latePolicy, assignment.LatePolicy, pkg.LATE_POLICY_ACCEPT)
+	latePenaltyPercent := assignment.LatePenaltyPercent
+	if schedule.latePenaltyPercent != nil {
+		latePenaltyPercent = *schedule.latePenaltyPercent
+	}
+	if latePolicy != pkg.LATE_POLICY_PENALTY {
+		latePenaltyPercent = 0
+	}
+
+	assignment.DueDate = due
+	assignment.AvailableFrom = from
+	assignment.AvailableUntil = until
+	assignment.LatePolicy = latePolicy
+	assignment.LatePenaltyPercent = latePenaltyPercent
+	return nil
+}
+
+func parseOptionalTime(field string, value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, pkg.NewBadRequestError(fmt.Sprintf("%s must be an RFC3339 timestamp", field), err)
+	}
+	return &parsed, nil
+}
+
+// submissionLateness enforces the availability window and the late policy of the assignment,
+// it reports whether work handed in at submittedAt is late and the penalty grading will apply
+func submissionLateness(assignment model.Assignment, submittedAt time.Time) (isLate bool, penaltyPercent float64, err error) {
+	if assignment.AvailableFrom != nil && submittedAt.Before(*assignment.AvailableFrom) {
+		return false, 0, pkg.NewBadRequestError("assignment is not open for submissions yet", nil)
+	}
+	if assignment.AvailableUntil != nil && submittedAt.After(*assignment.AvailableUntil) {
+		return false, 0, pkg.NewBadRequestError("assignment is closed for submissions", nil)
+	}
+	if !submittedAt.After(assignment.DueDate) {
+		return false, 0, nil
+	}
+
+	switch assignment.LatePolicy {
+	case pkg.LATE_POLICY_REJECT:
+		return true, 0, pkg.NewBadRequestError("the due date of this assignment has passed", nil)
+	case pkg.LATE_POLICY_PENALTY:
+		// every started day past the due date costs the configured percentage
+		days := math.Ceil(submittedAt.Sub(assignment.DueDate).Hours() / 24)
+		return true, math.Min(100, days*assignment.LatePenaltyPercent), nil
+	default:
+		return true, 0, nil
+	}
+}
+
+// applyLatePenalty reduces the grade by the penalty percentage, rounded to two decimals
+func applyLatePenalty(grade float64, penaltyPercent float64) float64 {
+	return math.Round(grade*(100-penaltyPercent)) / 100
+}
+
+func assignmentToResponse(assignment model.Assignment) (response payload.GetAssignmentResponse) {
+	response.ID = assignment.ID.String()
+	response.Title = assignment.Title
+	response.Description = assignment.Description
+	response.DueDate = assignment.DueDate.Format(time.RFC3339)
+	response.TotalPoints = assignment.TotalPoints
+	response.IsPublished = assignment.IsPublished
+	if assignment.AvailableFrom != nil {
+		availableFrom := assignment.AvailableFrom.Format(time.RFC3339)
+		response.AvailableFrom = &availableFrom
+	}
+	if assignment.AvailableUntil != nil {
+		availableUntil := assignment.AvailableUntil.Format(time.RFC3339)
+		response.AvailableUntil = &availableUntil
+	}
+	response.LatePolicy = assignment.LatePolicy
+	response.LatePenaltyPercent = assignment.LatePenaltyPercent
+	response.MaxAttempts = assignment.MaxAttempts
+	if assignment.RubricID != nil {
+		rubricID := assignment.RubricID.String()
+		response.RubricID = &rubricID
+	}
+	if assignment.CategoryID != nil {
+		categoryID := assignment.CategoryID.String()
+		response.CategoryID = &categoryID
+	}
+	return
+}
+
+func enrollmentToResponse(enrollment model.Enrollment) (response payload.EnrollmentResponse) {
+	response.ID = enrollment.ID.String()
+	response.CourseID = enrollment.CourseID.String()
+	response.StudentID = enrollment.StudentID.String()
+	response.Status = enrollment.Status
+	response.EnrolledAt = enrollment.EnrolledAt.Format(time.RFC3339)
+	if enrollment.DroppedAt != nil {
+		droppedAt := enrollment.DroppedAt.Format(time.RFC3339)
+		response.DroppedAt = &droppedAt
+	}
+	return
+}
@@ -0,0 +1,436 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"edukita-teaching-grading/internal/app/payload"
+	"edukita-teaching-grading/internal/app/policy"
+	"edukita-teaching-grading/internal/app/repository"
+	"edukita-teaching-grading/internal/pkg"
+
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// DeleteCourseByID soft-deletes the course together with its assignments, their submissions and the enrollments
+func (s *LearningManagementService) DeleteCourseByID(ctx context.Context, id string) (response payload.DeletionResponse, err error) {
+	return response, s.Repository.Tx.Run(ctx, repository.TxReadWrite, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+		user, err := s.currentUser(ctx, tx)
+		if err != nil {
+			return
+		}
+
+		course, err := s.Repository.LearningManagement.GetCourseByID(ctx, id, tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to get course by id: %s", err.Error()), zap.Error(err))
+			return
+		}
+
+		if err = s.authorize(user, policy.CourseDelete, policy.OwnedBy(course.CreatedBy)); err != nil {
+			return
+		}
+
+		before := course
+		now := time.Now()
+		course, err = s.Repository.LearningManagement.DeleteCourseByID(ctx, course.ID.String(), user.ID, now, tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to delete course: %s", err.Error()), zap.Error(err))
+			return
+		}
+		if _, err = s.Repository.LearningManagement.DeleteAssignmentsByCourseID(ctx, course.ID.String(), user.ID, now, tx); err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to delete course assignments: %s", err.Error()), zap.Error(err))
+			return
+		}
+		if _, err = s.Repository.LearningManagement.DeleteSubmissionsByCourseID(ctx, course.ID.String(), user.ID, now, tx); err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to delete course submissions: %s", err.Error()), zap.Error(err))
+			return
+		}
+		if _, err = s.Repository.LearningManagement.DeleteEnrollmentsByCourseID(ctx, course.ID.String(), user.ID, now, tx); err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to delete course enrollments: %s", err.Error()), zap.Error(err))
+			return
+		}
+
+		if err = s.audit(ctx, tx, auditChange{
+			action:     pkg.AUDIT_ACTION_DELETE,
+			entityType: pkg.AUDIT_ENTITY_COURSE,
+			entityID:   course.ID,
+			courseID:   &course.ID,
+			before:     before,
+			after:      course,
+		}); err != nil {
+			return
+		}
+
+		response = deletionToResponse(course.BaseModel, false)
+		return
+	})
+}
+
+// RestoreCourseByID brings back the course and everything that was deleted together with it
+func (s *LearningManagementService) RestoreCourseByID(ctx context.Context, id string) (response payload.DeletionResponse, err error) {
+	return response, s.Repository.Tx.Run(ctx, repository.TxReadWrite, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+		admin, err := s.currentUser(ctx, tx)
+		if err != nil {
+			return
+		}
+
+		if err = s.authorize(admin, policy.RecordRestore, policy.Resource{}); err != nil {
+			return
+		}
+
+		course, err := s.Repository.LearningManagement.GetDeletedCourseByID(ctx, id, tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to get deleted course by id: %s", err.Error()), zap.Error(err))
+			return
+		}
+
+		before := course
+		deletedAt := *course.DeletedAt
+		course, err = s.Repository.LearningManagement.RestoreCourseByID(ctx, course.ID.String(), admin.ID, tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to restore course: %s", err.Error()), zap.Error(err))
+			return
+		}
+		if _, err = s.Repository.LearningManagement.RestoreAssignmentsByCourseID(ctx, course.ID.String(), deletedAt, admin.ID, tx); err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to restore course assignments: %s", err.Error()), zap.Error(err))
+			return
+		}
+		if _, err = s.Repository.LearningManagement.RestoreSubmissionsByCourseID(ctx, course.ID.String(), deletedAt, admin.ID, tx); err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to restore course submissions: %s", err.Error()), zap.Error(err))
+			return
+		}
+		if _, err = s.Repository.LearningManagement.RestoreEnrollmentsByCourseID(ctx, course.ID.String(), deletedAt, admin.ID, tx); err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to restore course enrollments: %s", err.Error()), zap.Error(err))
+			return
+		}
+
+		if err = s.audit(ctx, tx, auditChange{
+			action:     pkg.AUDIT_ACTION_RESTORE,
+			entityType: pkg.AUDIT_ENTITY_COURSE,
+			entityID:   course.ID,
+			courseID:   &course.ID,
+			before:     before,
+			after:      course,
+		}); err != nil {
+			return
+		}
+
+		response = deletionToResponse(course.BaseModel, false)
+		return
+	})
+}
+
+// PurgeCourseByID permanently removes a soft-deleted course and everything in it
+func (s *LearningManagementService) PurgeCourseByID(ctx context.Context, id string) (response payload.DeletionResponse, err error) {
+	return response, s.Repository.Tx.Run(ctx, repository.TxReadWrite, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+		admin, err := s.currentUser(ctx, tx)
+		if err != nil {
+			return
+		}
+
+		if err = s.authorize(admin, policy.RecordPurge, policy.Resource{}); err != nil {
+			return
+		}
+
+		course, err := s.Repository.LearningManagement.PurgeCourseByID(ctx, id, tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to purge course: %s", err.Error()), zap.Error(err))
+			return
+		}
+
+		if err = s.audit(ctx, tx, auditChange{
+			action:     pkg.AUDIT_ACTION_PURGE,
+			entityType: pkg.AUDIT_ENTITY_COURSE,
+			entityID:   course.ID,
+			courseID:   &course.ID,
+			before:     course,
+		}); err != nil {
+			return
+		}
+
+		response = deletionToResponse(course.BaseModel, true)
+		return
+	})
+}
+
+// DeleteAssignmentByID soft-deletes the assignment together with its submissions
+func (s *LearningManagementService) DeleteAssignmentByID(ctx context.Context, id string) (response payload.DeletionResponse, err error) {
+	return response, s.Repository.Tx.Run(ctx, repository.TxReadWrite, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+		user, err := s.currentUser(ctx, tx)
+		if err != nil {
+			return
+		}
+
+		assignment, err := s.Repository.LearningManagement.GetAssignmentByID(ctx, id, tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to get assignment by id: %s", err.Error()), zap.Error(err))
+			return
+		}
+
+		owners, err := s.assignmentOwners(ctx, assignment, tx)
+		if err != nil {
+			return
+		}
+
+		if err = s.authorize(user, policy.AssignmentDelete, owners); err != nil {
+			return
+		}
+
+		before := assignment
+		now := time.Now()
+		assignment, err = s.Repository.LearningManagement.DeleteAssignmentByID(ctx, assignment.ID.String(), user.ID, now, tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to delete assignment: %s", err.Error()), zap.Error(err))
+			return
+		}
+		if _, err = s.Repository.LearningManagement.DeleteSubmissionsByAssignmentID(ctx, assignment.ID.String(), user.ID, now, tx); err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to delete assignment submissions: %s", err.Error()), zap.Error(err))
+			return
+		}
+
+		if err = s.audit(ctx, tx, auditChange{
+			action:     pkg.AUDIT_ACTION_DELETE,
+			entityType: pkg.AUDIT_ENTITY_ASSIGNMENT,
+			entityID:   assignment.ID,
+			courseID:   &assignment.CourseID,
+			before:     before,
+			after:      assignment,
+		}); err != nil {
+			return
+		}
+
+		response = deletionToResponse(assignment.BaseModel, false)
+		return
+	})
+}
+
+// RestoreAssignmentByID brings back the assignment and the submissions deleted with it, its course must be live
+func (s *LearningManagementService) RestoreAssignmentByID(ctx context.Context, id string) (response payload.DeletionResponse, err error) {
+	return response, s.Repository.Tx.Run(ctx, repository.TxReadWrite, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+		admin, err := s.currentUser(ctx, tx)
+		if err != nil {
+			return
+		}
+
+		if err = s.authorize(admin, policy.RecordRestore, policy.Resource{}); err != nil {
+			return
+		}
+
+		assignment, err := s.Repository.LearningManagement.GetDeletedAssignmentByID(ctx, id, tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to get deleted assignment by id: %s", err.Error()), zap.Error(err))
+			return
+		}
+
+		if _, err = s.Repository.LearningManagement.GetCourseByID(ctx, assignment.CourseID.String(), tx); err != nil {
+			if pkg.IsNotFound(err) {
+				err = pkg.NewBadRequestError("the course of the assignment is deleted, restore the course instead", err)
+			}
+			s.Logger.Warnf(fmt.Sprintf("failed to get course by id: %s", err.Error()), zap.Error(err))
+			return
+		}
+
+		before := assignment
+		deletedAt := *assignment.DeletedAt
+		assignment, err = s.Repository.LearningManagement.RestoreAssignmentByID(ctx, assignment.ID.String(), admin.ID, tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to restore assignment: %s", err.Error()), zap.Error(err))
+			return
+		}
+		if _, err = s.Repository.LearningManagement.RestoreSubmissionsByAssignmentID(ctx, assignment.ID.String(), deletedAt, admin.ID, tx); err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to restore assignment submissions: %s", err.Error()), zap.Error(err))
+			return
+		}
+
+		if err = s.audit(ctx, tx, auditChange{
+			action:     pkg.AUDIT_ACTION_RESTORE,
+			entityType: pkg.AUDIT_ENTITY_ASSIGNMENT,
+			entityID:   assignment.ID,
+			courseID:   &assignment.CourseID,
+			before:     before,
+			after:      assignment,
+		}); err != nil {
+			return
+		}
+
+		response = deletionToResponse(assignment.BaseModel, false)
+		return
+	})
+}
+
+// PurgeAssignmentByID permanently removes a soft-deleted assignment and its submissions
+func (s *LearningManagementService) PurgeAssignmentByID(ctx context.Context, id string) (response payload.DeletionResponse, err error) {
+	return response, s.Repository.Tx.Run(ctx, repository.TxReadWrite, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+		admin, err := s.currentUser(ctx, tx)
+		if err != nil {
+			return
+		}
+
+		if err = s.authorize(admin, policy.RecordPurge, policy.Resource{}); err != nil {
+			return
+		}
+
+		assignment, err := s.Repository.LearningManagement.PurgeAssignmentByID(ctx, id, tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to purge assignment: %s", err.Error()), zap.Error(err))
+			return
+		}
+
+		if err = s.audit(ctx, tx, auditChange{
+			action:     pkg.AUDIT_ACTION_PURGE,
+			entityType: pkg.AUDIT_ENTITY_ASSIGNMENT,
+			entityID:   assignment.ID,
+			courseID:   &assignment.CourseID,
+			before:     assignment,
+		}); err != nil {
+			return
+		}
+
+		response = deletionToResponse(assignment.BaseModel, true)
+		return
+	})
+}
+
+// DeleteSubmissionByID soft-deletes the submission, students may only withdraw work that is not graded yet
+func (s *LearningManagementService) DeleteSubmissionByID(ctx context.Context, id string) (response payload.DeletionResponse, err error) {
+	return response, s.Repository.Tx.Run(ctx, repository.TxReadWrite, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+		user, err := s.currentUser(ctx, tx)
+		if err != nil {
+			return
+		}
+
+		submission, err := s.Repository.LearningManagement.GetSubmissionByID(ctx, id, tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to get submission by id: %s", err.Error()), zap.Error(err))
+			return
+		}
+
+		if err = s.authorize(user, policy.SubmissionDelete, policy.OwnedBy(submission.StudentID)); err != nil {
+			return
+		}
+
+		if user.Role == pkg.ROLE_STUDENT && submission.Grade != nil {
+			err = pkg.NewForbiddenError("graded submissions cannot be withdrawn", nil)
+			s.Logger.Warnf("submission %s is already graded", submission.ID, zap.Error(err))
+			return
+		}
+
+		before := submission
+		submission, err = s.Repository.LearningManagement.DeleteSubmissionByID(ctx, submission.ID.String(), user.ID, time.Now(), tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to delete submission: %s", err.Error()), zap.Error(err))
+			return
+		}
+
+		courseID, err := s.submissionCourseID(ctx, submission, tx)
+		if err != nil {
+			return
+		}
+		if err = s.audit(ctx, tx, auditChange{
+			action:     pkg.AUDIT_ACTION_DELETE,
+			entityType: pkg.AUDIT_ENTITY_SUBMISSION,
+			entityID:   submission.ID,
+			courseID:   courseID,
+			before:     before,
+			after:      submission,
+		}); err != nil {
+			return
+		}
+
+		response = deletionToResponse(submission.BaseModel, false)
+		return
+	})
+}
+
+// RestoreSubmissionByID brings back the submission, its assignment must be live
+func (s *LearningManagementService) RestoreSubmissionByID(ctx context.Context, id string) (response payload.DeletionResponse, err error) {
+	return response, s.Repository.Tx.Run(ctx, repository.TxReadWrite, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+		admin, err := s.currentUser(ctx, tx)
+		if err != nil {
+			return
+		}
+
+		if err = s.authorize(admin, policy.RecordRestore, policy.Resource{}); err != nil {
+			return
+		}
+
+		submission, err := s.Repository.LearningManagement.GetDeletedSubmissionByID(ctx, id, tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to get deleted submission by id: %s", err.Error()), zap.Error(err))
+			return
+		}
+
+		if _, err = s.Repository.LearningManagement.GetAssignmentByID(ctx, submission.AssignmentID.String(), tx); err != nil {
+			if pkg.IsNotFound(err) {
+				err = pkg.NewBadRequestError("the assignment of the submission is deleted, restore the assignment instead", err)
+			}
+			s.Logger.Warnf(fmt.Sprintf("failed to get assignment by id: %s", err.Error()), zap.Error(err))
+			return
+		}
+
+		before := submission
+		// a student that submitted again after the deletion holds the live submission, restoring fails with a conflict
+		submission, err = s.Repository.LearningManagement.RestoreSubmissionByID(ctx, submission.ID.String(), admin.ID, tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to restore submission: %s", err.Error()), zap.Error(err))
+			return
+		}
+
+		courseID, err := s.submissionCourseID(ctx, submission, tx)
+		if err != nil {
+			return
+		}
+		if err = s.audit(ctx, tx, auditChange{
+			action:     pkg.AUDIT_ACTION_RESTORE,
+			entityType: pkg.AUDIT_ENTITY_SUBMISSION,
+			entityID:   submission.ID,
+			courseID:   courseID,
+			before:     before,
+			after:      submission,
+		}); err != nil {
+			return
+		}
+
+		response = deletionToResponse(submission.BaseModel, false)
+		return
+	})
+}
+
+// PurgeSubmissionByID permanently removes a soft-deleted submission with its attempts, attachments and scores
+func (s *LearningManagementService) PurgeSubmissionByID(ctx context.Context, id string) (response payload.DeletionResponse, err error) {
+	return response, s.Repository.Tx.Run(ctx, repository.TxReadWrite, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+		admin, err := s.currentUser(ctx, tx)
+		if err != nil {
+			return
+		}
+
+		if err = s.authorize(admin, policy.RecordPurge, policy.Resource{}); err != nil {
+			return
+		}
+
+		submission, err := s.Repository.LearningManagement.PurgeSubmissionByID(ctx, id, tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to purge submission: %s", err.Error()), zap.Error(err))
+			return
+		}
+
+		courseID, err := s.submissionCourseID(ctx, submission, tx)
+		if err != nil {
+			return
+		}
+		if err = s.audit(ctx, tx, auditChange{
+			action:     pkg.AUDIT_ACTION_PURGE,
+			entityType: pkg.AUDIT_ENTITY_SUBMISSION,
+			entityID:   submission.ID,
+			courseID:   courseID,
+			before:     submission,
+		}); err != nil {
+			return
+		}
+
+		response = deletionToResponse(submission.BaseModel, true)
+		return
+	})
+}
@@ -0,0 +1,183 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"edukita-teaching-grading/internal/app/model"
+	"edukita-teaching-grading/internal/app/payload"
+	"edukita-teaching-grading/internal/pkg"
+
+	"github.com/google/uuid"
+)
+
+func newTestSubmission(assignmentID uuid.UUID, submittedAt time.Time) model.Submission {
+	return model.Submission{
+		BaseModel:    model.BaseModel{ID: uuid.New()},
+		AssignmentID: assignmentID,
+		StudentID:    uuid.New(),
+		SubmittedAt:  submittedAt,
+	}
+}
+
+func TestGroupSubmissionsByAssignment(t *testing.T) {
+	essay, quiz, project := uuid.New(), uuid.New(), uuid.New()
+	start := time.Now()
+
+	// the rows of several assignments come back from one query, interleaved as the database returns them
+	submissions := []model.Submission{
+		newTestSubmission(essay, start),
+		newTestSubmission(quiz, start.Add(time.Minute)),
+		newTestSubmission(essay, start.Add(2*time.Minute)),
+		newTestSubmission(quiz, start.Add(3*time.Minute)),
+		newTestSubmission(essay, start.Add(4*time.Minute)),
+	}
+
+	grouped := groupSubmissionsByAssignment(submissions)
+
+	want := map[uuid.UUID][]model.Submission{
+		essay:   {submissions[0], submissions[2], submissions[4]},
+		quiz:    {submissions[1], submissions[3]},
+		project: nil,
+	}
+	for assignmentID, expected := range want {
+		got := grouped[assignmentID]
+		if len(got) != len(expected) {
+			t.Fatalf("assignment %s: got %d submissions, want %d", assignmentID, len(got), len(expected))
+		}
+		for i := range expected {
+			if got[i].ID != expected[i].ID {
+				t.Errorf("assignment %s: submission %d is %s, want %s", assignmentID, i, got[i].ID, expected[i].ID)
+			}
+			if got[i].AssignmentID != assignmentID {
+				t.Errorf("assignment %s: got a submission of assignment %s", assignmentID, got[i].AssignmentID)
+			}
+		}
+	}
+	if len(grouped) != 2 {
+		t.Errorf("got %d groups, want 2", len(grouped))
+	}
+}
+
+func TestApplyAssignmentScheduleKeepsOmittedFields(t *testing.T) {
+	due := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	assignment := model.Assignment{DueDate: due}
+
+	if err := applyAssignmentSchedule(&assignment, assignmentSchedule{}); err != nil {
+		t.Fatalf("applyAssignmentSchedule() unexpected error: %v", err)
+	}
+	if !assignment.DueDate.Equal(due) || assignment.LatePolicy != pkg.LATE_POLICY_ACCEPT {
+		t.Errorf("due date %v with policy %s, want %v to be kept with the accept policy", assignment.DueDate, assignment.LatePolicy, due)
+	}
+
+	if err := applyAssignmentSchedule(&assignment, assignmentSchedule{availableFrom: ref("2025-05-02T00:00:00Z")}); err == nil {
+		t.Error("want the kept due date checked against the new availability window")
+	}
+
+	if err := applyAssignmentSchedule(&assignment, assignmentSchedule{dueDate: "2025-06-01T12:00:00Z"}); err != nil {
+		t.Fatalf("applyAssignmentSchedule() unexpected error: %v", err)
+	}
+	if want := due.AddDate(0, 1, 0); !assignment.DueDate.Equal(want) {
+		t.Errorf("due date %v, want %v", assignment.DueDate, want)
+	}
+
+	from, until := due.AddDate(0, 0, -7), due.AddDate(0, 0, 7)
+	newAssignment := func() model.Assignment {
+		return model.Assignment{DueDate: due, AvailableFrom: &from, AvailableUntil: &until, LatePolicy: pkg.LATE_POLICY_PENALTY, LatePenaltyPercent: 10}
+	}
+
+	tests := []struct {
+		name        string
+		schedule    assignmentSchedule
+		wantFrom    *time.Time
+		wantUntil   *time.Time
+		wantPolicy  string
+		wantPenalty float64
+	}{
+		{"nothing sent", assignmentSchedule{}, &from, &until, pkg.LATE_POLICY_PENALTY, 10},
+		{"only the due date", assignmentSchedule{dueDate: "2025-05-02T12:00:00Z"}, &from, &until, pkg.LATE_POLICY_PENALTY, 10},
+		{"window cleared", assignmentSchedule{availableFrom: ref(""), availableUntil: ref("")}, nil, nil, pkg.LATE_POLICY_PENALTY, 10},
+		{"penalty changed", assignmentSchedule{latePenaltyPercent: ref(25.0)}, &from, &until, pkg.LATE_POLICY_PENALTY, 25},
+		{"policy changed", assignmentSchedule{latePolicy: pkg.LATE_POLICY_REJECT}, &from, &until, pkg.LATE_POLICY_REJECT, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assignment := newAssignment()
+			if err := applyAssignmentSchedule(&assignment, tt.schedule); err != nil {
+				t.Fatalf("applyAssignmentSchedule() unexpected error: %v", err)
+			}
+			if !equalTime(assignment.AvailableFrom, tt.wantFrom) || !equalTime(assignment.AvailableUntil, tt.wantUntil) {
+				t.Errorf("window %v - %v, want %v - %v", assignment.AvailableFrom, assignment.AvailableUntil, tt.wantFrom, tt.wantUntil)
+			}
+			if assignment.LatePolicy != tt.wantPolicy || assignment.LatePenaltyPercent != tt.wantPenalty {
+				t.Errorf("policy %s with %v%% penalty, want %s with %v%%", assignment.LatePolicy, assignment.LatePenaltyPercent, tt.wantPolicy, tt.wantPenalty)
+			}
+		})
+	}
+}
+
+func equalTime(a, b *time.Time) bool {
+	return (a == nil && b == nil) || (a != nil && b != nil && a.Equal(*b))
+}
+
+func TestDirectGrade(t *testing.T) {
+	rubricID := uuid.New()
+	essay := model.Assignment{TotalPoints: 50}
+	rubricEssay := model.Assignment{TotalPoints: 50, RubricID: &rubricID}
+	scores := []payload.CriterionScoreRequest{{CriterionID: uuid.NewString(), LevelID: uuid.NewString()}}
+
+	tests := []struct {
+		name       string
+		assignment model.Assignment
+		request    payload.UpdateSubmissionRequest
+		want       *float64
+		wantErr    bool
+	}{
+		{"grade", essay, payload.UpdateSubmissionRequest{Grade: ref(42.5)}, ref(42.5), false},
+		{"zero is a grade", essay, payload.UpdateSubmissionRequest{Grade: ref(0.0)}, ref(0.0), false},
+		{"full points", essay, payload.UpdateSubmissionRequest{Grade: ref(50.0)}, ref(50.0), false},
+		{"feedback only", essay, payload.UpdateSubmissionRequest{Feedback: "Good work"}, nil, false},
+		{"negative", essay, payload.UpdateSubmissionRequest{Grade: ref(-50.0)}, nil, true},
+		{"above the points", essay, payload.UpdateSubmissionRequest{Grade: ref(50.5)}, nil, true},
+		{"scores without a rubric", essay, payload.UpdateSubmissionRequest{Scores: scores}, nil, true},
+		{"grade with a rubric", rubricEssay, payload.UpdateSubmissionRequest{Grade: ref(40.0)}, nil, true},
+		{"zero with a rubric", rubricEssay, payload.UpdateSubmissionRequest{Grade: ref(0.0), Scores: scores}, nil, true},
+		// the grade of a rubric is the total of its scores, worked out by scoreRubric
+		{"scores with a rubric", rubricEssay, payload.UpdateSubmissionRequest{Scores: scores}, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := directGrade(tt.assignment, &tt.request)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("directGrade() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !equalPercentage(got, tt.want) {
+				t.Errorf("directGrade() = %v, want %v", deref(got), deref(tt.want))
+			}
+		})
+	}
+}
+
+func BenchmarkGroupSubmissionsByAssignment(b *testing.B) {
+	const (
+		assignments = 50
+		students    = 200
+	)
+
+	ids := make([]uuid.UUID, assignments)
+	for i := range ids {
+		ids[i] = uuid.New()
+	}
+	start := time.Now()
+	submissions := make([]model.Submission, 0, assignments*students)
+	for _, id := range ids {
+		for j := range students {
+			submissions = append(submissions, newTestSubmission(id, start.Add(time.Duration(j)*time.Second)))
+		}
+	}
+
+	b.ReportAllocs()
+	for b.Loop() {
+		groupSubmissionsByAssignment(submissions)
+	}
+}
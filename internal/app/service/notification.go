@@ -0,0 +1,416 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"edukita-teaching-grading/internal/app/event"
+	"edukita-teaching-grading/internal/app/model"
+	"edukita-teaching-grading/internal/app/payload"
+	"edukita-teaching-grading/internal/app/repository"
+	"edukita-teaching-grading/internal/pkg"
+	"edukita-teaching-grading/pkg/mailer"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+type (
+	INotificationService interface {
+		GetAllNotifications(ctx context.Context, query pkg.ListQuery) (response payload.GetAllNotificationsResponse, meta payload.MetaResponse, err error)
+		MarkNotificationRead(ctx context.Context, id string) (response payload.NotificationResponse, err error)
+		MarkAllNotificationsRead(ctx context.Context) (response payload.MarkAllNotificationsReadResponse, err error)
+		GetNotificationPreferences(ctx context.Context) (response payload.NotificationPreferencesResponse, err error)
+		UpdateNotificationPreferences(ctx context.Context, requestBody *payload.UpdateNotificationPreferencesRequest) (response payload.NotificationPreferencesResponse, err error)
+		NotifyEvent(ctx context.Context, e event.Event) error
+	}
+	NotificationService struct {
+		ServiceOption
+	}
+)
+
+func InitiateNotificationService(opt ServiceOption) INotificationService {
+	return &NotificationService{
+		ServiceOption: opt,
+	}
+}
+
+// notificationTypes lists the types shown in the preferences of a user
+var notificationTypes = []string{
+	pkg.NOTIFICATION_ASSIGNMENT_PUBLISHED,
+	pkg.NOTIFICATION_GRADE_POSTED,
+	pkg.NOTIFICATION_FEEDBACK_POSTED,
+	pkg.NOTIFICATION_ASSIGNMENT_DUE_SOON,
+}
+
+func (s *NotificationService) GetAllNotifications(ctx context.Context, query pkg.ListQuery) (response payload.GetAllNotificationsResponse, meta payload.MetaResponse, err error) {
+	return response, meta, s.Repository.Tx.Run(ctx, repository.TxReadOnly, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+		user, err := s.currentUser(ctx, tx)
+		if err != nil {
+			return
+		}
+
+		notifications, page, err := s.Repository.Notification.ListNotificationsByUserID(ctx, user.ID.String(), query, tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to get notifications: %s", err.Error()), zap.Error(err))
+			return
+		}
+		meta = pageToMeta(page)
+
+		response.UnreadCount, err = s.Repository.Notification.CountUnreadNotificationsByUserID(ctx, user.ID.String(), tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to count unread notifications: %s", err.Error()), zap.Error(err))
+			return
+		}
+
+		response.Notifications = make([]payload.NotificationResponse, len(notifications))
+		for i, notification := range notifications {
+			response.Notifications[i] = notificationToResponse(notification)
+		}
+		return
+	})
+}
+
+func (s *NotificationService) MarkNotificationRead(ctx context.Context, id string) (response payload.NotificationResponse, err error) {
+	return response, s.Repository.Tx.Run(ctx, repository.TxReadWrite, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+		user, err := s.currentUser(ctx, tx)
+		if err != nil {
+			return
+		}
+
+		// notifications of other users are reported as missing
+		notification, err := s.Repository.Notification.MarkNotificationRead(ctx, id, user.ID.String(), time.Now(), tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to mark notification read: %s", err.Error()), zap.Error(err))
+			return
+		}
+
+		response = notificationToResponse(notification)
+		return
+	})
+}
+
+func (s *NotificationService) MarkAllNotificationsRead(ctx context.Context) (response payload.MarkAllNotificationsReadResponse, err error) {
+	return response, s.Repository.Tx.Run(ctx, repository.TxReadWrite, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+		user, err := s.currentUser(ctx, tx)
+		if err != nil {
+			return
+		}
+
+		response.Updated, err = s.Repository.Notification.MarkAllNotificationsRead(ctx, user.ID.String(), time.Now(), tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to mark notifications read: %s", err.Error()), zap.Error(err))
+			return
+		}
+		return
+	})
+}
+
+func (s *NotificationService) GetNotificationPreferences(ctx context.Context) (response payload.NotificationPreferencesResponse, err error) {
+	return response, s.Repository.Tx.Run(ctx, repository.TxReadOnly, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+		user, err := s.currentUser(ctx, tx)
+		if err != nil {
+			return
+		}
+
+		response, err = s.notificationPreferences(ctx, user, tx)
+		return
+	})
+}
+
+func (s *NotificationService) UpdateNotificationPreferences(ctx context.Context, requestBody *payload.UpdateNotificationPreferencesRequest) (response payload.NotificationPreferencesResponse, err error) {
+	return response, s.Repository.Tx.Run(ctx, repository.TxReadWrite, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+		user, err := s.currentUser(ctx, tx)
+		if err != nil {
+			return
+		}
+
+		current, err := s.Repository.Notification.GetAllNotificationPreferencesByUserID(ctx, user.ID.String(), tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to get notification preferences: %s", err.Error()), zap.Error(err))
+			return
+		}
+
+		now := time.Now()
+		for _, preference := range requestBody.Preferences {
+			// a channel left out of the request keeps its setting
+			updated := notificationPreference(current, user.ID, preference.Type)
+			if preference.InApp != nil {
+				updated.InApp = *preference.InApp
+			}
+			if preference.Email != nil {
+				updated.Email = *preference.Email
+			}
+			updated.UpdatedAt = now
+
+			_, err = s.Repository.Notification.UpsertNotificationPreference(ctx, updated, tx)
+			if err != nil {
+				s.Logger.Warnf(fmt.Sprintf("failed to update notification preference: %s", err.Error()), zap.Error(err))
+				return
+			}
+		}
+
+		response, err = s.notificationPreferences(ctx, user, tx)
+		return
+	})
+}
+
+func (s *NotificationService) notificationPreferences(ctx context.Context, user model.User, tx *sqlx.Tx) (response payload.NotificationPreferencesResponse, err error) {
+	preferences, err := s.Repository.Notification.GetAllNotificationPreferencesByUserID(ctx, user.ID.String(), tx)
+	if err != nil {
+		s.Logger.Warnf(fmt.Sprintf("failed to get notification preferences: %s", err.Error()), zap.Error(err))
+		return
+	}
+
+	response.Preferences = make([]payload.NotificationPreferenceResponse, len(notificationTypes))
+	for i, notificationType := range notificationTypes {
+		preference := notificationPreference(preferences, user.ID, notificationType)
+		response.Preferences[i] = payload.NotificationPreferenceResponse{
+			Type:  notificationType,
+			InApp: preference.InApp,
+			Email: preference.Email,
+		}
+	}
+	return
+}
+
+// notificationPreference picks the preference of a type, a type the user never changed is on in every channel
+func notificationPreference(preferences []model.NotificationPreference, userID uuid.UUID, notificationType string) model.NotificationPreference {
+	for _, preference := range preferences {
+		if preference.Type == notificationType {
+			return preference
+		}
+	}
+	return model.NotificationPreference{UserID: userID, Type: notificationType, InApp: true, Email: true}
+}
+
+// NotifyEvent is the bus subscriber that turns domain events into notifications and emails. It runs in the
+// savepoint of the dispatcher, and a user is notified of an event once however often the event is delivered.
+func (s *NotificationService) NotifyEvent(ctx context.Context, e event.Event) error {
+	return s.Repository.Tx.Run(ctx, repository.TxReadWrite, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+		var notifications []model.Notification
+		var emails []model.EmailMessage
+		switch e.Type {
+		case event.AssignmentPublished:
+			notifications, emails, err = s.assignmentPublishedNotifications(ctx, e, tx)
+		case event.SubmissionGraded:
+			notifications, emails, err = s.submissionGradedNotifications(ctx, e, tx)
+		}
+		if err != nil || len(notifications) == 0 {
+			return
+		}
+
+		// the notifications of one event share their type
+		recipients := make([]uuid.UUID, len(notifications))
+		for i, notification := range notifications {
+			recipients[i] = notification.UserID
+		}
+		preferences, err := s.Repository.Notification.GetAllNotificationPreferencesByType(ctx, notifications[0].Type, recipients, tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to get notification preferences: %s", err.Error()), zap.Error(err))
+			return
+		}
+
+		if _, err = s.Repository.Notification.CreateNotifications(ctx, withoutMutedNotifications(notifications, preferences), tx); err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to create notifications: %s", err.Error()), zap.Error(err))
+			return
+		}
+		_, err = s.queueEmails(ctx, tx, withoutMutedEmails(emails, preferences)...)
+		return
+	})
+}
+
+// assignmentPublishedNotifications notifies and emails every active student of the course, nobody is notified
+// when the course was deleted since
+func (s *NotificationService) assignmentPublishedNotifications(ctx context.Context, e event.Event, tx *sqlx.Tx) (notifications []model.Notification, emails []model.EmailMessage, err error) {
+	var published event.AssignmentPublishedPayload
+	if err = e.Decode(&published); err != nil {
+		return
+	}
+
+	course, err := s.Repository.LearningManagement.GetCourseByID(ctx, published.CourseID.String(), tx)
+	if pkg.IsNotFound(err) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		s.Logger.Warnf(fmt.Sprintf("failed to get course by id: %s", err.Error()), zap.Error(err))
+		return
+	}
+
+	enrollments, err := s.Repository.LearningManagement.GetAllEnrollmentsByCourseID(ctx, course.ID.String(), tx)
+	if err != nil {
+		s.Logger.Warnf(fmt.Sprintf("failed to get enrollments by course id: %s", err.Error()), zap.Error(err))
+		return
+	}
+
+	for _, enrollment := range enrollments {
+		if enrollment.Status != pkg.ENROLLMENT_STATUS_ACTIVE {
+			continue
+		}
+		notifications = append(notifications, assignmentPublishedNotification(e, published, course, enrollment.StudentID))
+
+		var email model.EmailMessage
+		email, err = s.email(enrollment.StudentID, enrollment.Email, mailer.TemplateAssignmentPublished, mailer.AssignmentPublishedData{
+			FirstName:       enrollment.FirstName,
+			CourseID:        course.ID.String(),
+			CourseName:      course.Name,
+			AssignmentID:    published.AssignmentID.String(),
+			AssignmentTitle: published.Title,
+			DueDate:         published.DueDate,
+		}, &e.ID)
+		if err != nil {
+			return
+		}
+		emails = append(emails, email)
+	}
+	return
+}
+
+// submissionGradedNotifications notifies and emails the student of the submission, nobody is notified when the
+// assignment was deleted since and a deleted student gets no email
+func (s *NotificationService) submissionGradedNotifications(ctx context.Context, e event.Event, tx *sqlx.Tx) (notifications []model.Notification, emails []model.EmailMessage, err error) {
+	var graded event.SubmissionGradedPayload
+	if err = e.Decode(&graded); err != nil {
+		return
+	}
+
+	assignment, err := s.Repository.LearningManagement.GetAssignmentByID(ctx, graded.AssignmentID.String(), tx)
+	if pkg.IsNotFound(err) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		s.Logger.Warnf(fmt.Sprintf("failed to get assignment by id: %s", err.Error()), zap.Error(err))
+		return
+	}
+	notifications = []model.Notification{submissionGradedNotification(e, graded, assignment)}
+
+	student, err := s.Repository.User.GetUserByID(ctx, graded.StudentID.String(), tx)
+	if pkg.IsNotFound(err) {
+		return notifications, nil, nil
+	}
+	if err != nil {
+		s.Logger.Warnf(fmt.Sprintf("failed to get user by id: %s", err.Error()), zap.Error(err))
+		return
+	}
+
+	email, err := s.email(student.ID, student.Email, mailer.TemplateGradePosted, mailer.GradePostedData{
+		FirstName:       student.FirstName,
+		CourseID:        graded.CourseID.String(),
+		AssignmentID:    graded.AssignmentID.String(),
+		AssignmentTitle: assignment.Title,
+		Grade:           graded.Grade,
+		TotalPoints:     assignment.TotalPoints,
+		HasFeedback:     graded.Feedback != nil && *graded.Feedback != "",
+	}, &e.ID)
+	if err != nil {
+		return
+	}
+	return notifications, []model.EmailMessage{email}, nil
+}
+
+func assignmentPublishedNotification(e event.Event, published event.AssignmentPublishedPayload, course model.Course, studentID uuid.UUID) model.Notification {
+	return model.Notification{
+		ID:     uuid.New(),
+		UserID: studentID,
+		Type:   pkg.NOTIFICATION_ASSIGNMENT_PUBLISHED,
+		Title:  fmt.Sprintf("New assignment: %s", published.Title),
+		Body:   fmt.Sprintf("%s was published in %s and is due %s.", published.Title, course.Name, published.DueDate.Format("2 Jan 2006 15:04 MST")),
+		Data: notificationData(map[string]uuid.UUID{
+			"course_id":     published.CourseID,
+			"assignment_id": published.AssignmentID,
+		}),
+		EventID:   &e.ID,
+		CreatedAt: e.OccurredAt,
+	}
+}
+
+// submissionGradedNotification tells the student about a grade, or about feedback when the submission has no
+// grade yet
+func submissionGradedNotification(e event.Event, graded event.SubmissionGradedPayload, assignment model.Assignment) model.Notification {
+	notification := model.Notification{
+		ID:     uuid.New(),
+		UserID: graded.StudentID,
+		Data: notificationData(map[string]uuid.UUID{
+			"course_id":     graded.CourseID,
+			"assignment_id": graded.AssignmentID,
+			"submission_id": graded.SubmissionID,
+		}),
+		EventID:   &e.ID,
+		CreatedAt: e.OccurredAt,
+	}
+
+	if graded.Grade == nil {
+		notification.Type = pkg.NOTIFICATION_FEEDBACK_POSTED
+		notification.Title = fmt.Sprintf("Feedback on %s", assignment.Title)
+		notification.Body = fmt.Sprintf("Your teacher left feedback on your submission for %s.", assignment.Title)
+		return notification
+	}
+
+	notification.Type = pkg.NOTIFICATION_GRADE_POSTED
+	notification.Title = fmt.Sprintf("Grade posted: %s", assignment.Title)
+	notification.Body = fmt.Sprintf("You scored %.2f out of %.2f on %s.", *graded.Grade, assignment.TotalPoints, assignment.Title)
+	if graded.Feedback != nil && *graded.Feedback != "" {
+		notification.Body += " Your teacher also left feedback."
+	}
+	return notification
+}
+
+// withoutMutedNotifications drops the notifications of users that turned their type off
+func withoutMutedNotifications(notifications []model.Notification, preferences []model.NotificationPreference) []model.Notification {
+	muted := make(map[uuid.UUID]bool)
+	for _, preference := range preferences {
+		if !preference.InApp {
+			muted[preference.UserID] = true
+		}
+	}
+
+	kept := notifications[:0]
+	for _, notification := range notifications {
+		if !muted[notification.UserID] {
+			kept = append(kept, notification)
+		}
+	}
+	return kept
+}
+
+// withoutMutedEmails drops the emails of users that turned emails of the type off
+func withoutMutedEmails(emails []model.EmailMessage, preferences []model.NotificationPreference) []model.EmailMessage {
+	muted := make(map[uuid.UUID]bool)
+	for _, preference := range preferences {
+		if !preference.Email {
+			muted[preference.UserID] = true
+		}
+	}
+
+	kept := emails[:0]
+	for _, email := range emails {
+		if email.UserID == nil || !muted[*email.UserID] {
+			kept = append(kept, email)
+		}
+	}
+	return kept
+}
+
+func notificationData(ids map[string]uuid.UUID) string {
+	// a map of uuids always encodes
+	data, _ := json.Marshal(ids)
+	return string(data)
+}
+
+func notificationToResponse(notification model.Notification) (response payload.NotificationResponse) {
+	response.ID = notification.ID.String()
+	response.Type = notification.Type
+	response.Title = notification.Title
+	response.Body = notification.Body
+	response.Data = json.RawMessage(notification.Data)
+	response.IsRead = notification.ReadAt != nil
+	if notification.ReadAt != nil {
+		readAt := notification.ReadAt.Format(time.RFC3339)
+		response.ReadAt = &readAt
+	}
+	response.CreatedAt = notification.CreatedAt.Format(time.RFC3339)
+	return
+}
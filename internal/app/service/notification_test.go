@@ -0,0 +1,93 @@
+package service
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"edukita-teaching-grading/internal/app/event"
+	"edukita-teaching-grading/internal/app/model"
+	"edukita-teaching-grading/internal/pkg"
+
+	"github.com/google/uuid"
+)
+
+func TestSubmissionGradedNotification(t *testing.T) {
+	e := event.Event{ID: uuid.New(), Type: event.SubmissionGraded, OccurredAt: time.Now()}
+	assignment := model.Assignment{Title: "Essay", TotalPoints: 50}
+	grade, feedback := 42.5, "well argued"
+	graded := event.SubmissionGradedPayload{
+		SubmissionID: uuid.New(),
+		AssignmentID: uuid.New(),
+		CourseID:     uuid.New(),
+		StudentID:    uuid.New(),
+		Grade:        &grade,
+		Feedback:     &feedback,
+	}
+
+	notification := submissionGradedNotification(e, graded, assignment)
+	if notification.Type != pkg.NOTIFICATION_GRADE_POSTED || notification.UserID != graded.StudentID {
+		t.Errorf("got %s for %s, want a grade for the student", notification.Type, notification.UserID)
+	}
+	if !strings.Contains(notification.Body, "42.50 out of 50.00") || !strings.Contains(notification.Body, "feedback") {
+		t.Errorf("unexpected body %q", notification.Body)
+	}
+	if notification.EventID == nil || *notification.EventID != e.ID {
+		t.Errorf("event id %v, want %s", notification.EventID, e.ID)
+	}
+
+	var data map[string]uuid.UUID
+	if err := json.Unmarshal([]byte(notification.Data), &data); err != nil {
+		t.Fatalf("decoding data: %v", err)
+	}
+	if data["submission_id"] != graded.SubmissionID || data["course_id"] != graded.CourseID {
+		t.Errorf("data %v does not link the submission and course", data)
+	}
+
+	graded.Grade = nil
+	notification = submissionGradedNotification(e, graded, assignment)
+	if notification.Type != pkg.NOTIFICATION_FEEDBACK_POSTED {
+		t.Errorf("got %s, want feedback without a grade", notification.Type)
+	}
+}
+
+func TestWithoutMutedNotifications(t *testing.T) {
+	muted, enabled, unset := uuid.New(), uuid.New(), uuid.New()
+	notifications := []model.Notification{{UserID: muted}, {UserID: enabled}, {UserID: unset}}
+	preferences := []model.NotificationPreference{
+		{UserID: muted, InApp: false},
+		{UserID: enabled, InApp: true},
+	}
+
+	kept := withoutMutedNotifications(notifications, preferences)
+	if len(kept) != 2 || kept[0].UserID != enabled || kept[1].UserID != unset {
+		t.Errorf("kept %v, want the users that did not turn the type off", kept)
+	}
+}
+
+func TestWithoutMutedEmails(t *testing.T) {
+	muted, inAppOnly, unset := uuid.New(), uuid.New(), uuid.New()
+	emails := []model.EmailMessage{{UserID: &muted}, {UserID: &inAppOnly}, {UserID: &unset}}
+	preferences := []model.NotificationPreference{
+		{UserID: muted, InApp: true, Email: false},
+		{UserID: inAppOnly, InApp: false, Email: true},
+	}
+
+	kept := withoutMutedEmails(emails, preferences)
+	if len(kept) != 2 || *kept[0].UserID != inAppOnly || *kept[1].UserID != unset {
+		t.Errorf("kept %v, want the users that did not turn emails of the type off", kept)
+	}
+}
+
+func TestNotificationPreferenceDefaultsOn(t *testing.T) {
+	userID := uuid.New()
+	preferences := []model.NotificationPreference{{UserID: userID, Type: pkg.NOTIFICATION_GRADE_POSTED, InApp: true, Email: false}}
+
+	if preference := notificationPreference(preferences, userID, pkg.NOTIFICATION_GRADE_POSTED); preference.Email || !preference.InApp {
+		t.Errorf("got %+v, want the stored preference", preference)
+	}
+	if preference := notificationPreference(preferences, userID, pkg.NOTIFICATION_FEEDBACK_POSTED); !preference.Email || !preference.InApp {
+		t.Errorf("got %+v, want every channel on", preference)
+	}
+}
@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"edukita-teaching-grading/internal/app/event"
+	"edukita-teaching-grading/internal/app/model"
+	"edukita-teaching-grading/internal/app/repository"
+	"edukita-teaching-grading/internal/pkg"
+	"edukita-teaching-grading/pkg/pubsub"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+type (
+	IRealtimeService interface {
+		Subscribe(ctx context.Context, courseID string) (sub *pubsub.Subscription, err error)
+		Check(ctx context.Context, courseID string, sub *pubsub.Subscription) error
+	}
+	RealtimeService struct {
+		ServiceOption
+	}
+)
+
+func InitiateRealtimeService(opt ServiceOption) IRealtimeService {
+	return &RealtimeService{
+		ServiceOption: opt,
+	}
+}
+
+// Subscribe subscribes the current user to their own messages and to those of their courses, or of the one
+// course given. The caller closes the subscription.
+func (s *RealtimeService) Subscribe(ctx context.Context, courseID string) (sub *pubsub.Subscription, err error) {
+	var course *uuid.UUID
+	if courseID != "" {
+		id, err := uuid.Parse(courseID)
+		if err != nil {
+			err = pkg.NewBadRequestError("invalid course_id", err)
+			s.Logger.Warnf("invalid course_id: %s", courseID, zap.Error(err))
+			return nil, err
+		}
+		course = &id
+	}
+
+	var topics []string
+	err = s.Repository.Tx.Run(ctx, repository.TxReadOnly, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+		user, err := s.currentUser(ctx, tx)
+		if err != nil {
+			return
+		}
+
+		topics, err = s.realtimeTopics(ctx, user, course, tx)
+		return
+	})
+	if err != nil {
+		return
+	}
+	return s.Hub.Subscribe(topics...), nil
+}
+
+// Check tells whether a subscription made by Subscribe may go on: the access token it was made with is not revoked
+// and the user still belongs to every course it listens to. The stream is ended when it may not, a client that
+// reconnects only gets the topics it may have now.
+func (s *RealtimeService) Check(ctx context.Context, courseID string, sub *pubsub.Subscription) error {
+	var course *uuid.UUID
+	if courseID != "" {
+		id, err := uuid.Parse(courseID)
+		if err != nil {
+			return pkg.NewBadRequestError("invalid course_id", err)
+		}
+		course = &id
+	}
+
+	return s.Repository.Tx.Run(ctx, repository.TxReadOnly, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+		user, err := s.currentUser(ctx, tx)
+		if err != nil {
+			return
+		}
+
+		actor, _ := pkg.ActorFromContext(ctx)
+		revoked, err := s.Repository.Auth.IsTokenRevoked(ctx, actor.TokenID, tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to check token revocation: %s", err.Error()), zap.Error(err))
+			return
+		}
+		if revoked {
+			err = pkg.NewUnauthorizedError("token has been revoked", nil)
+			s.Logger.Warnf("revoked token %s still streaming", actor.TokenID, zap.Error(err))
+			return
+		}
+
+		topics, err := s.realtimeTopics(ctx, user, course, tx)
+		if err != nil {
+			return
+		}
+		for _, topic := range sub.Topics() {
+			if !slices.Contains(topics, topic) {
+				err = pkg.NewForbiddenError("no longer a member of a course of the stream", nil)
+				s.Logger.Warnf("user %s no longer listens to %s", user.ID, topic, zap.Error(err))
+				return
+			}
+		}
+		return nil
+	})
+}
+
+// realtimeTopics lists what the user may listen to besides their own messages: teachers hear the staff topics
+// of the courses they teach, students the topics of the courses they are active in and admins every course
+func (s *RealtimeService) realtimeTopics(ctx context.Context, user model.User, course *uuid.UUID, tx *sqlx.Tx) (topics []string, err error) {
+	topics = []string{event.UserTopic(user.ID)}
+
+	var courses []uuid.UUID
+	var courseTopic func(uuid.UUID) string
+	switch user.Role {
+	case pkg.ROLE_ADMIN:
+		if course == nil {
+			return append(topics, event.StaffTopic), nil
+		}
+		if _, err = s.Repository.LearningManagement.GetCourseByID(ctx, course.String(), tx); err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to get course by id: %s", err.Error()), zap.Error(err))
+			return
+		}
+		return append(topics, event.CourseStaffTopic(*course)), nil
+	case pkg.ROLE_TEACHER:
+		courses, err = s.Repository.LearningManagement.GetAllCourseIDsByTeacherID(ctx, user.ID.String(), tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to get courses by teacher id: %s", err.Error()), zap.Error(err))
+			return
+		}
+		courseTopic = event.CourseStaffTopic
+	default:
+		var enrollments []model.Enrollment
+		enrollments, err = s.Repository.LearningManagement.GetAllEnrollmentsByStudentID(ctx, user.ID.String(), tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to get enrollments by student id: %s", err.Error()), zap.Error(err))
+			return
+		}
+		for _, enrollment := range enrollments {
+			if enrollment.Status == pkg.ENROLLMENT_STATUS_ACTIVE {
+				courses = append(courses, enrollment.CourseID)
+			}
+		}
+		courseTopic = event.CourseTopic
+	}
+
+	if course != nil {
+		if !slices.Contains(courses, *course) {
+			err = pkg.NewForbiddenError("not a member of this course", nil)
+			s.Logger.Warnf("user %s is not a member of course %s", user.ID, course, zap.Error(err))
+			return
+		}
+		courses = []uuid.UUID{*course}
+	}
+	for _, id := range courses {
+		topics = append(topics, courseTopic(id))
+	}
+	return
+}
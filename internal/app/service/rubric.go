@@ -0,0 +1,383 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"edukita-teaching-grading/internal/app/model"
+	"edukita-teaching-grading/internal/app/payload"
+	"edukita-teaching-grading/internal/app/policy"
+	"edukita-teaching-grading/internal/app/repository"
+	"edukita-teaching-grading/internal/pkg"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+type (
+	IRubricService interface {
+		CreateRubric(ctx context.Context, requestBody *payload.CreateRubricRequest) (response payload.RubricResponse, err error)
+		GetRubricByID(ctx context.Context, id string) (response payload.RubricResponse, err error)
+		GetAllRubrics(ctx context.Context, query pkg.ListQuery) (response payload.GetAllRubricsResponse, meta payload.MetaResponse, err error)
+	}
+	RubricService struct {
+		ServiceOption
+	}
+)
+
+func InitiateRubricService(opt ServiceOption) IRubricService {
+	return &RubricService{
+		ServiceOption: opt,
+	}
+}
+
+// rubricSheet is a rubric with its criteria and the levels of every criterion
+type rubricSheet struct {
+	rubric   model.Rubric
+	criteria []model.RubricCriterion
+	levels   map[uuid.UUID][]model.RubricLevel
+}
+
+// criterionMaxPoints is the points of the best level of the criterion
+func (r rubricSheet) criterionMaxPoints(criterionID uuid.UUID) (points float64) {
+	for _, level := range r.levels[criterionID] {
+		points = max(points, level.Points)
+	}
+	return
+}
+
+// maxPoints is the grade of a submission reaching the best level of every criterion
+func (r rubricSheet) maxPoints() (points float64) {
+	for _, criterion := range r.criteria {
+		points += r.criterionMaxPoints(criterion.ID)
+	}
+	return
+}
+
+func (s *RubricService) CreateRubric(ctx context.Context, requestBody *payload.CreateRubricRequest) (response payload.RubricResponse, err error) {
+	return response, s.Repository.Tx.Run(ctx, repository.TxReadWrite, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+		user, err := s.currentUser(ctx, tx)
+		if err != nil {
+			return
+		}
+
+		if err = s.authorize(user, policy.RubricCreate, policy.OwnedBy(user.ID)); err != nil {
+			return
+		}
+
+		sheet := rubricSheet{
+			rubric: model.Rubric{
+				BaseModel: model.BaseModel{
+					ID:        uuid.New(),
+					CreatedBy: user.ID,
+					CreatedAt: time.Now(),
+				},
+				Title:       requestBody.Title,
+				Description: requestBody.Description,
+			},
+			levels: make(map[uuid.UUID][]model.RubricLevel),
+		}
+		sheet.rubric, err = s.Repository.Rubric.CreateRubric(ctx, sheet.rubric, tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to create rubric: %s", err.Error()), zap.Error(err))
+			return
+		}
+
+		for i, criterionRequest := range requestBody.Criteria {
+			criterion, err := s.Repository.Rubric.CreateRubricCriterion(ctx, model.RubricCriterion{
+				ID:          uuid.New(),
+				RubricID:    sheet.rubric.ID,
+				Title:       criterionRequest.Title,
+				Description: criterionRequest.Description,
+				Position:    i + 1,
+			}, tx)
+			if err != nil {
+				s.Logger.Warnf(fmt.Sprintf("failed to create rubric criterion: %s", err.Error()), zap.Error(err))
+				return err
+			}
+			sheet.criteria = append(sheet.criteria, criterion)
+
+			for j, levelRequest := range criterionRequest.Levels {
+				level, err := s.Repository.Rubric.CreateRubricLevel(ctx, model.RubricLevel{
+					ID:          uuid.New(),
+					CriterionID: criterion.ID,
+					Title:       levelRequest.Title,
+					Description: levelRequest.Description,
+					Points:      levelRequest.Points,
+					Position:    j + 1,
+				}, tx)
+				if err != nil {
+					s.Logger.Warnf(fmt.Sprintf("failed to create rubric level: %s", err.Error()), zap.Error(err))
+					return err
+				}
+				sheet.levels[criterion.ID] = append(sheet.levels[criterion.ID], level)
+			}
+		}
+
+		response = rubricToResponse(sheet)
+		if err = s.audit(ctx, tx, auditChange{
+			action:     pkg.AUDIT_ACTION_CREATE,
+			entityType: pkg.AUDIT_ENTITY_RUBRIC,
+			entityID:   sheet.rubric.ID,
+			after:      response,
+		}); err != nil {
+			return
+		}
+		return
+	})
+}
+
+func (s *RubricService) GetRubricByID(ctx context.Context, id string) (response payload.RubricResponse, err error) {
+	return response, s.Repository.Tx.Run(ctx, repository.TxReadOnly, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+		user, err := s.currentUser(ctx, tx)
+		if err != nil {
+			return
+		}
+
+		if err = s.authorize(user, policy.RubricRead, policy.Resource{}); err != nil {
+			return
+		}
+
+		sheet, err := s.rubricSheet(ctx, id, tx)
+		if err != nil {
+			return
+		}
+
+		response = rubricToResponse(sheet)
+		return
+	})
+}
+
+func (s *RubricService) GetAllRubrics(ctx context.Context, query pkg.ListQuery) (response payload.GetAllRubricsResponse, meta payload.MetaResponse, err error) {
+	return response, meta, s.Repository.Tx.Run(ctx, repository.TxReadOnly, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+		user, err := s.currentUser(ctx, tx)
+		if err != nil {
+			return
+		}
+
+		if err = s.authorize(user, policy.RubricRead, policy.Resource{}); err != nil {
+			return
+		}
+
+		rubrics, page, err := s.Repository.Rubric.ListRubrics(ctx, query, tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to get rubrics: %s", err.Error()), zap.Error(err))
+			return
+		}
+		meta = pageToMeta(page)
+
+		response.Rubrics = make([]payload.RubricSummaryResponse, len(rubrics))
+		for i, rubric := range rubrics {
+			response.Rubrics[i].ID = rubric.ID.String()
+			response.Rubrics[i].Title = rubric.Title
+			response.Rubrics[i].Description = rubric.Description
+			response.Rubrics[i].CreatedAt = rubric.CreatedAt.Format(time.RFC3339)
+			response.Rubrics[i].CreatedBy = rubric.CreatedBy.String()
+		}
+		return
+	})
+}
+
+// rubricSheet loads the rubric together with its criteria and levels
+func (s ServiceOption) rubricSheet(ctx context.Context, rubricID string, tx *sqlx.Tx) (sheet rubricSheet, err error) {
+	sheet.rubric, err = s.Repository.Rubric.GetRubricByID(ctx, rubricID, tx)
+	if err != nil {
+		s.Logger.Warnf(fmt.Sprintf("failed to get rubric by id: %s", err.Error()), zap.Error(err))
+		return
+	}
+
+	sheet.criteria, err = s.Repository.Rubric.GetAllRubricCriteriaByRubricID(ctx, rubricID, tx)
+	if err != nil {
+		s.Logger.Warnf(fmt.Sprintf("failed to get rubric criteria: %s", err.Error()), zap.Error(err))
+		return
+	}
+
+	levels, err := s.Repository.Rubric.GetAllRubricLevelsByRubricID(ctx, rubricID, tx)
+	if err != nil {
+		s.Logger.Warnf(fmt.Sprintf("failed to get rubric levels: %s", err.Error()), zap.Error(err))
+		return
+	}
+
+	sheet.levels = make(map[uuid.UUID][]model.RubricLevel, len(sheet.criteria))
+	for _, level := range levels {
+		sheet.levels[level.CriterionID] = append(sheet.levels[level.CriterionID], level)
+	}
+	return
+}
+
+// assignmentRubric validates that the rubric exists and that its best score fits in the points of the assignment
+func (s ServiceOption) assignmentRubric(ctx context.Context, rubricID string, totalPoints float64, tx *sqlx.Tx) (*uuid.UUID, error) {
+	if rubricID == "" {
+		return nil, nil
+	}
+
+	sheet, err := s.rubricSheet(ctx, rubricID, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	if sheet.maxPoints() > totalPoints {
+		err = pkg.NewBadRequestError(fmt.Sprintf("rubric is worth %.2f points, more than the %.2f points of the assignment", sheet.maxPoints(), totalPoints), nil)
+		s.Logger.Warnf("rubric %s does not fit the assignment", rubricID, zap.Error(err))
+		return nil, err
+	}
+	return &sheet.rubric.ID, nil
+}
+
+// updatedAssignmentRubric returns the rubric of an assignment after an update: a nil rubricID keeps the rubric it
+// has and an empty one detaches it. The rubric of an assignment cannot change once submissions were scored with it.
+func (s ServiceOption) updatedAssignmentRubric(ctx context.Context, assignment model.Assignment, rubricID *string, tx *sqlx.Tx) (*uuid.UUID, error) {
+	current := ""
+	if assignment.RubricID != nil {
+		current = assignment.RubricID.String()
+	}
+	if rubricID == nil || strings.EqualFold(*rubricID, current) {
+		// the rubric is checked again as the points of the assignment may have changed
+		return s.assignmentRubric(ctx, current, assignment.TotalPoints, tx)
+	}
+
+	if assignment.RubricID != nil {
+		scored, err := s.Repository.Rubric.CountCriterionScoresByAssignmentID(ctx, assignment.ID.String(), tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to count criterion scores: %s", err.Error()), zap.Error(err))
+			return nil, err
+		}
+		if scored > 0 {
+			err = pkg.NewBadRequestError("submissions were already scored with the rubric of the assignment, it cannot be changed", nil)
+			s.Logger.Warnf("rubric of assignment %s is in use", assignment.ID, zap.Error(err))
+			return nil, err
+		}
+	}
+	return s.assignmentRubric(ctx, *rubricID, assignment.TotalPoints, tx)
+}
+
+// scoreRubric replaces the criterion scores of the submission and returns their total
+func (s ServiceOption) scoreRubric(ctx context.Context, rubricID uuid.UUID, submission model.Submission, scores []payload.CriterionScoreRequest, totalPoints float64, gradedBy uuid.UUID, gradedAt time.Time, tx *sqlx.Tx) (total float64, err error) {
+	sheet, err := s.rubricSheet(ctx, rubricID.String(), tx)
+	if err != nil {
+		return
+	}
+	criterionScores, total, err := sheet.score(submission.ID, scores, totalPoints, gradedBy, gradedAt)
+	if err != nil {
+		return
+	}
+
+	if err = s.Repository.Rubric.DeleteCriterionScoresBySubmissionID(ctx, submission.ID.String(), tx); err != nil {
+		s.Logger.Warnf(fmt.Sprintf("failed to delete criterion scores: %s", err.Error()), zap.Error(err))
+		return
+	}
+	for _, criterionScore := range criterionScores {
+		if _, err = s.Repository.Rubric.CreateCriterionScore(ctx, criterionScore, tx); err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to create criterion score: %s", err.Error()), zap.Error(err))
+			return
+		}
+	}
+	return
+}
+
+// score checks the scores of a grading request against the rubric and returns the criterion scores with their
+// total. Every criterion has to be scored exactly once with one of its own levels, and the total has to fit the
+// points of the assignment.
+func (r rubricSheet) score(submissionID uuid.UUID, scores []payload.CriterionScoreRequest, totalPoints float64, gradedBy uuid.UUID, gradedAt time.Time) (criterionScores []model.CriterionScore, total float64, err error) {
+	picked := make(map[uuid.UUID]payload.CriterionScoreRequest, len(scores))
+	for _, score := range scores {
+		criterionID, err := uuid.Parse(score.CriterionID)
+		if err != nil {
+			return nil, 0, pkg.NewBadRequestError(fmt.Sprintf("invalid criterion_id %s", score.CriterionID), err)
+		}
+		if _, ok := picked[criterionID]; ok {
+			return nil, 0, pkg.NewBadRequestError(fmt.Sprintf("criterion %s is scored more than once", criterionID), nil)
+		}
+		picked[criterionID] = score
+	}
+
+	for _, criterion := range r.criteria {
+		score, ok := picked[criterion.ID]
+		if !ok {
+			return nil, 0, pkg.NewBadRequestError(fmt.Sprintf("criterion %q is not scored", criterion.Title), nil)
+		}
+		delete(picked, criterion.ID)
+
+		var level *model.RubricLevel
+		for i := range r.levels[criterion.ID] {
+			if r.levels[criterion.ID][i].ID.String() == score.LevelID {
+				level = &r.levels[criterion.ID][i]
+			}
+		}
+		if level == nil {
+			return nil, 0, pkg.NewBadRequestError(fmt.Sprintf("level %s is not a level of criterion %q", score.LevelID, criterion.Title), nil)
+		}
+
+		criterionScore := model.CriterionScore{
+			SubmissionID: submissionID,
+			CriterionID:  criterion.ID,
+			LevelID:      level.ID,
+			Points:       level.Points,
+			GradedBy:     gradedBy,
+			GradedAt:     gradedAt,
+		}
+		if score.Feedback != "" {
+			criterionScore.Feedback = &score.Feedback
+		}
+		criterionScores = append(criterionScores, criterionScore)
+		total += level.Points
+	}
+
+	for criterionID := range picked {
+		return nil, 0, pkg.NewBadRequestError(fmt.Sprintf("criterion %s is not part of the rubric", criterionID), nil)
+	}
+	if err = checkGrade(total, totalPoints); err != nil {
+		return nil, 0, err
+	}
+	return criterionScores, total, nil
+}
+
+// criterionScores returns the per-criterion grading of the submission
+func (s ServiceOption) criterionScores(ctx context.Context, submission model.Submission, tx *sqlx.Tx) ([]payload.CriterionScoreResponse, error) {
+	scores, err := s.Repository.Rubric.GetAllCriterionScoresBySubmissionID(ctx, submission.ID.String(), tx)
+	if err != nil {
+		s.Logger.Warnf(fmt.Sprintf("failed to get criterion scores: %s", err.Error()), zap.Error(err))
+		return nil, err
+	}
+
+	response := make([]payload.CriterionScoreResponse, len(scores))
+	for i, score := range scores {
+		response[i].CriterionID = score.CriterionID.String()
+		response[i].CriterionTitle = score.CriterionTitle
+		response[i].LevelID = score.LevelID.String()
+		response[i].LevelTitle = score.LevelTitle
+		response[i].Points = score.Points
+		response[i].Feedback = score.Feedback
+		response[i].GradedAt = score.GradedAt.Format(time.RFC3339)
+		response[i].GradedBy = score.GradedBy.String()
+	}
+	return response, nil
+}
+
+func rubricToResponse(sheet rubricSheet) (response payload.RubricResponse) {
+	response.ID = sheet.rubric.ID.String()
+	response.Title = sheet.rubric.Title
+	response.Description = sheet.rubric.Description
+	response.MaxPoints = sheet.maxPoints()
+	response.CreatedAt = sheet.rubric.CreatedAt.Format(time.RFC3339)
+	response.CreatedBy = sheet.rubric.CreatedBy.String()
+	response.Criteria = make([]payload.RubricCriterionResponse, len(sheet.criteria))
+	for i, criterion := range sheet.criteria {
+		response.Criteria[i].ID = criterion.ID.String()
+		response.Criteria[i].Title = criterion.Title
+		response.Criteria[i].Description = criterion.Description
+		response.Criteria[i].MaxPoints = sheet.criterionMaxPoints(criterion.ID)
+		levels := sheet.levels[criterion.ID]
+		response.Criteria[i].Levels = make([]payload.RubricLevelResponse, len(levels))
+		for j, level := range levels {
+			response.Criteria[i].Levels[j].ID = level.ID.String()
+			response.Criteria[i].Levels[j].Title = level.Title
+			response.Criteria[i].Levels[j].Description = level.Description
+			response.Criteria[i].Levels[j].Points = level.Points
+		}
+	}
+	return
+}
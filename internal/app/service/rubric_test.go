@@ -0,0 +1,74 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"edukita-teaching-grading/internal/app/model"
+	"edukita-teaching-grading/internal/app/payload"
+
+	"github.com/google/uuid"
+)
+
+// newTestRubricSheet builds a rubric of two criteria, each with a level worth 10 points and one worth 5
+func newTestRubricSheet() rubricSheet {
+	sheet := rubricSheet{levels: make(map[uuid.UUID][]model.RubricLevel)}
+	for _, title := range []string{"Content", "Style"} {
+		criterion := model.RubricCriterion{ID: uuid.New(), Title: title}
+		sheet.criteria = append(sheet.criteria, criterion)
+		sheet.levels[criterion.ID] = []model.RubricLevel{
+			{ID: uuid.New(), CriterionID: criterion.ID, Title: "Excellent", Points: 10},
+			{ID: uuid.New(), CriterionID: criterion.ID, Title: "Fair", Points: 5},
+		}
+	}
+	return sheet
+}
+
+func TestRubricSheetScore(t *testing.T) {
+	sheet := newTestRubricSheet()
+	content, style := sheet.criteria[0].ID, sheet.criteria[1].ID
+	score := func(criterionID uuid.UUID, level int) payload.CriterionScoreRequest {
+		return payload.CriterionScoreRequest{CriterionID: criterionID.String(), LevelID: sheet.levels[criterionID][level].ID.String()}
+	}
+
+	tests := []struct {
+		name        string
+		scores      []payload.CriterionScoreRequest
+		totalPoints float64
+		want        float64
+		wantErr     bool
+	}{
+		{"every criterion", []payload.CriterionScoreRequest{score(content, 0), score(style, 1)}, 20, 15, false},
+		{"in any order", []payload.CriterionScoreRequest{score(style, 0), score(content, 0)}, 20, 20, false},
+		{"missing criterion", []payload.CriterionScoreRequest{score(content, 0)}, 20, 0, true},
+		{"duplicate criterion", []payload.CriterionScoreRequest{score(content, 0), score(content, 1), score(style, 0)}, 20, 0, true},
+		{"level of another criterion", []payload.CriterionScoreRequest{score(content, 0), {CriterionID: style.String(), LevelID: sheet.levels[content][1].ID.String()}}, 20, 0, true},
+		{"foreign criterion", []payload.CriterionScoreRequest{score(content, 0), score(style, 0), {CriterionID: uuid.NewString(), LevelID: uuid.NewString()}}, 20, 0, true},
+		{"malformed criterion", []payload.CriterionScoreRequest{score(content, 0), score(style, 0), {CriterionID: "c1", LevelID: uuid.NewString()}}, 20, 0, true},
+		{"total above the points", []payload.CriterionScoreRequest{score(content, 0), score(style, 0)}, 15, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			submissionID, gradedBy, gradedAt := uuid.New(), uuid.New(), time.Now()
+			criterionScores, total, err := sheet.score(submissionID, tt.scores, tt.totalPoints, gradedBy, gradedAt)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("score() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if criterionScores != nil || total != 0 {
+					t.Errorf("score() = %v, %v, want nothing to save", criterionScores, total)
+				}
+				return
+			}
+			if total != tt.want || len(criterionScores) != len(sheet.criteria) {
+				t.Fatalf("score() = %d scores worth %v, want %d worth %v", len(criterionScores), total, len(sheet.criteria), tt.want)
+			}
+			// the scores follow the order of the criteria whatever order they were sent in
+			for i, criterionScore := range criterionScores {
+				if criterionScore.CriterionID != sheet.criteria[i].ID || criterionScore.SubmissionID != submissionID || criterionScore.GradedBy != gradedBy {
+					t.Errorf("score %d = %+v", i, criterionScore)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,275 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"edukita-teaching-grading/internal/app/model"
+	"edukita-teaching-grading/internal/app/payload"
+	"edukita-teaching-grading/internal/app/policy"
+	"edukita-teaching-grading/internal/app/repository"
+	"edukita-teaching-grading/internal/pkg"
+	"edukita-teaching-grading/pkg/mailer"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+type (
+	ISchedulerService interface {
+		GetAllScheduledJobRuns(ctx context.Context, query pkg.ListQuery) (response payload.GetAllScheduledJobRunsResponse, meta payload.MetaResponse, err error)
+
+		// the scheduled jobs, each runs inside the transaction of its run and sums up what it did
+		SendDueReminders(ctx context.Context, now time.Time) (summary string, err error)
+		DeactivateEndedCourses(ctx context.Context, now time.Time) (summary string, err error)
+		PurgeExpiredTokens(ctx context.Context, now time.Time) (summary string, err error)
+		PurgeSucceededJobs(ctx context.Context, now time.Time) (summary string, err error)
+	}
+	SchedulerService struct {
+		ServiceOption
+	}
+)
+
+func InitiateSchedulerService(opt ServiceOption) ISchedulerService {
+	return &SchedulerService{
+		ServiceOption: opt,
+	}
+}
+
+// dueReminderWindows are how long before the due date students are reminded, longest first
+var dueReminderWindows = []time.Duration{24 * time.Hour, time.Hour}
+
+// dueReminderNamespace derives the ids of reminders, a reminder keeps its id on every run that finds it
+var dueReminderNamespace = uuid.MustParse("7fdb0ffc-ac6c-4657-81f9-045c1e8d8a3a")
+
+func (s *SchedulerService) GetAllScheduledJobRuns(ctx context.Context, query pkg.ListQuery) (response payload.GetAllScheduledJobRunsResponse, meta payload.MetaResponse, err error) {
+	return response, meta, s.Repository.Tx.Run(ctx, repository.TxReadOnly, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+		user, err := s.currentUser(ctx, tx)
+		if err != nil {
+			return
+		}
+
+		if err = s.authorize(user, policy.SchedulerRead, policy.Resource{}); err != nil {
+			return
+		}
+
+		runs, page, err := s.Repository.Scheduler.ListScheduledJobRuns(ctx, query, tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to get scheduled job runs: %s", err.Error()), zap.Error(err))
+			return
+		}
+		meta = pageToMeta(page)
+
+		response.Runs = make([]payload.ScheduledJobRunResponse, len(runs))
+		for i, run := range runs {
+			response.Runs[i] = scheduledJobRunToResponse(run)
+		}
+		return
+	})
+}
+
+// SendDueReminders notifies and emails the students that have not submitted an assignment due within one of the
+// reminder windows. Every run finds the same reminders again until the window passes, and a reminder reaches a
+// student once since its id only changes with the window and the due date.
+func (s *SchedulerService) SendDueReminders(ctx context.Context, now time.Time) (summary string, err error) {
+	return summary, s.Repository.Tx.Run(ctx, repository.TxReadWrite, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+		var notifications []model.Notification
+		var emails []model.EmailMessage
+		for i, window := range dueReminderWindows {
+			// an assignment due within a shorter window is reminded of by that window
+			dueAfter := now
+			if i+1 < len(dueReminderWindows) {
+				dueAfter = now.Add(dueReminderWindows[i+1])
+			}
+
+			var reminders []model.DueReminder
+			reminders, err = s.Repository.LearningManagement.GetAllDueReminders(ctx, dueAfter, now.Add(window), tx)
+			if err != nil {
+				s.Logger.Warnf(fmt.Sprintf("failed to get due reminders: %s", err.Error()), zap.Error(err))
+				return
+			}
+
+			for _, reminder := range reminders {
+				id := dueReminderID(reminder, window)
+				notifications = append(notifications, dueSoonNotification(reminder, id, now))
+
+				var email model.EmailMessage
+				email, err = s.email(reminder.StudentID, reminder.Email, mailer.TemplateDueSoon, mailer.DueSoonData{
+					FirstName:       reminder.FirstName,
+					CourseID:        reminder.CourseID.String(),
+					CourseName:      reminder.CourseName,
+					AssignmentID:    reminder.AssignmentID.String(),
+					AssignmentTitle: reminder.AssignmentTitle,
+					DueDate:         reminder.DueDate,
+					TimeLeft:        timeLeft(reminder.DueDate.Sub(now)),
+				}, &id)
+				if err != nil {
+					return
+				}
+				emails = append(emails, email)
+			}
+		}
+		if len(notifications) == 0 {
+			summary = "no reminders due"
+			return
+		}
+
+		recipients := make([]uuid.UUID, len(notifications))
+		for i, notification := range notifications {
+			recipients[i] = notification.UserID
+		}
+		preferences, err := s.Repository.Notification.GetAllNotificationPreferencesByType(ctx, pkg.NOTIFICATION_ASSIGNMENT_DUE_SOON, recipients, tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to get notification preferences: %s", err.Error()), zap.Error(err))
+			return
+		}
+
+		notified, err := s.Repository.Notification.CreateNotifications(ctx, withoutMutedNotifications(notifications, preferences), tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to create notifications: %s", err.Error()), zap.Error(err))
+			return
+		}
+		emailed, err := s.queueEmails(ctx, tx, withoutMutedEmails(emails, preferences)...)
+		if err != nil {
+			return
+		}
+
+		summary = fmt.Sprintf("%d reminders due, %d notifications created, %d emails queued", len(notifications), notified, emailed)
+		return
+	})
+}
+
+// dueReminderID is the id of the reminder of an assignment for a student in a window, a new due date is
+// reminded of again
+func dueReminderID(reminder model.DueReminder, window time.Duration) uuid.UUID {
+	name := fmt.Sprintf("%s:%s:%d:%s", reminder.AssignmentID, reminder.StudentID, reminder.DueDate.Unix(), window)
+	return uuid.NewSHA1(dueReminderNamespace, []byte(name))
+}
+
+func dueSoonNotification(reminder model.DueReminder, id uuid.UUID, now time.Time) model.Notification {
+	return model.Notification{
+		ID:     uuid.New(),
+		UserID: reminder.StudentID,
+		Type:   pkg.NOTIFICATION_ASSIGNMENT_DUE_SOON,
+		Title:  fmt.Sprintf("Due in %s: %s", timeLeft(reminder.DueDate.Sub(now)), reminder.AssignmentTitle),
+		Body:   fmt.Sprintf("%s in %s is due %s and you have not submitted it yet.", reminder.AssignmentTitle, reminder.CourseName, reminder.DueDate.Format("2 Jan 2006 15:04 MST")),
+		Data: notificationData(map[string]uuid.UUID{
+			"course_id":     reminder.CourseID,
+			"assignment_id": reminder.AssignmentID,
+		}),
+		EventID:   &id,
+		CreatedAt: now,
+	}
+}
+
+// timeLeft rounds the time until a due date to whole hours, or to whole minutes in the last hour and a half
+func timeLeft(d time.Duration) string {
+	if d >= 90*time.Minute {
+		return plural(int(math.Round(d.Hours())), "hour")
+	}
+	return plural(max(int(math.Round(d.Minutes())), 1), "minute")
+}
+
+func plural(n int, unit string) string {
+	if n == 1 {
+		return fmt.Sprintf("1 %s", unit)
+	}
+	return fmt.Sprintf("%d %ss", n, unit)
+}
+
+// DeactivateEndedCourses turns off the courses past their end date, each change is audited without an actor
+func (s *SchedulerService) DeactivateEndedCourses(ctx context.Context, now time.Time) (summary string, err error) {
+	return summary, s.Repository.Tx.Run(ctx, repository.TxReadWrite, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+		courses, err := s.Repository.LearningManagement.GetAllEndedActiveCourses(ctx, now, tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to get ended courses: %s", err.Error()), zap.Error(err))
+			return
+		}
+
+		for _, course := range courses {
+			before := course
+			course.IsActive = false
+			course.UpdatedBy = nil
+			course.UpdatedAt = &now
+
+			course, err = s.Repository.LearningManagement.UpdateCourseByID(ctx, course, tx)
+			if err != nil {
+				s.Logger.Warnf(fmt.Sprintf("failed to update course: %s", err.Error()), zap.Error(err))
+				return
+			}
+
+			if err = s.audit(ctx, tx, auditChange{
+				action:     pkg.AUDIT_ACTION_UPDATE,
+				entityType: pkg.AUDIT_ENTITY_COURSE,
+				entityID:   course.ID,
+				courseID:   &course.ID,
+				before:     before,
+				after:      course,
+			}); err != nil {
+				return
+			}
+		}
+
+		summary = fmt.Sprintf("%d courses deactivated", len(courses))
+		return
+	})
+}
+
+// PurgeExpiredTokens deletes the refresh tokens, the deny list entries of access tokens and the password reset and
+// email verification tokens that expired, the database removes the emails with their links
+func (s *SchedulerService) PurgeExpiredTokens(ctx context.Context, now time.Time) (summary string, err error) {
+	return summary, s.Repository.Tx.Run(ctx, repository.TxReadWrite, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+		refreshTokens, err := s.Repository.Auth.DeleteExpiredRefreshTokens(ctx, now, tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to delete expired refresh tokens: %s", err.Error()), zap.Error(err))
+			return
+		}
+
+		revokedTokens, err := s.Repository.Auth.DeleteExpiredRevokedTokens(ctx, now, tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to delete expired revoked tokens: %s", err.Error()), zap.Error(err))
+			return
+		}
+
+		userTokens, err := s.Repository.Auth.DeleteExpiredUserTokens(ctx, now, tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to delete expired user tokens: %s", err.Error()), zap.Error(err))
+			return
+		}
+
+		summary = fmt.Sprintf("%d refresh tokens, %d revoked access tokens and %d reset and verification tokens deleted", refreshTokens, revokedTokens, userTokens)
+		return
+	})
+}
+
+// PurgeSucceededJobs deletes the queued jobs that succeeded longer than the retention ago
+func (s *SchedulerService) PurgeSucceededJobs(ctx context.Context, now time.Time) (summary string, err error) {
+	return summary, s.Repository.Tx.Run(ctx, repository.TxReadWrite, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+		deleted, err := s.Repository.Job.DeleteSucceededJobs(ctx, now.Add(-s.Config.Jobs.Retention), tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to delete succeeded jobs: %s", err.Error()), zap.Error(err))
+			return
+		}
+
+		summary = fmt.Sprintf("%d succeeded jobs deleted", deleted)
+		return
+	})
+}
+
+func scheduledJobRunToResponse(run model.ScheduledJobRun) payload.ScheduledJobRunResponse {
+	return payload.ScheduledJobRunResponse{
+		ID:          run.ID.String(),
+		JobName:     run.JobName,
+		ScheduledAt: run.ScheduledAt.Format(time.RFC3339),
+		Status:      run.Status,
+		Summary:     run.Summary,
+		Error:       run.Error,
+		Instance:    run.Instance,
+		StartedAt:   run.StartedAt.Format(time.RFC3339),
+		FinishedAt:  run.FinishedAt.Format(time.RFC3339),
+		DurationMS:  run.FinishedAt.Sub(run.StartedAt).Milliseconds(),
+	}
+}
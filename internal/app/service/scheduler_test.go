@@ -0,0 +1,76 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"edukita-teaching-grading/internal/app/model"
+	"edukita-teaching-grading/internal/pkg"
+
+	"github.com/google/uuid"
+)
+
+func TestDueReminderID(t *testing.T) {
+	reminder := model.DueReminder{
+		AssignmentID: uuid.New(),
+		StudentID:    uuid.New(),
+		DueDate:      time.Date(2025, 5, 20, 17, 0, 0, 0, time.UTC),
+	}
+
+	id := dueReminderID(reminder, 24*time.Hour)
+	if again := dueReminderID(reminder, 24*time.Hour); again != id {
+		t.Errorf("id changed between runs: %s, %s", id, again)
+	}
+	if other := dueReminderID(reminder, time.Hour); other == id {
+		t.Error("the windows of an assignment share a reminder id")
+	}
+
+	reminder.DueDate = reminder.DueDate.Add(24 * time.Hour)
+	if extended := dueReminderID(reminder, 24*time.Hour); extended == id {
+		t.Error("a new due date keeps the reminder id, the student would not be reminded again")
+	}
+}
+
+func TestDueSoonNotification(t *testing.T) {
+	now := time.Date(2025, 5, 20, 16, 5, 0, 0, time.UTC)
+	reminder := model.DueReminder{
+		AssignmentID:    uuid.New(),
+		AssignmentTitle: "Essay",
+		DueDate:         time.Date(2025, 5, 20, 17, 0, 0, 0, time.UTC),
+		CourseID:        uuid.New(),
+		CourseName:      "Writing 101",
+		StudentID:       uuid.New(),
+	}
+	id := uuid.New()
+
+	notification := dueSoonNotification(reminder, id, now)
+	if notification.Type != pkg.NOTIFICATION_ASSIGNMENT_DUE_SOON || notification.UserID != reminder.StudentID {
+		t.Errorf("got %s for %s, want a due soon notification for the student", notification.Type, notification.UserID)
+	}
+	if notification.Title != "Due in 55 minutes: Essay" {
+		t.Errorf("unexpected title %q", notification.Title)
+	}
+	if notification.EventID == nil || *notification.EventID != id {
+		t.Errorf("event id %v, want the reminder id %s", notification.EventID, id)
+	}
+}
+
+func TestTimeLeft(t *testing.T) {
+	tests := []struct {
+		left time.Duration
+		want string
+	}{
+		{24 * time.Hour, "24 hours"},
+		{23*time.Hour + 56*time.Minute, "24 hours"},
+		{2 * time.Hour, "2 hours"},
+		{90 * time.Minute, "2 hours"},
+		{89 * time.Minute, "89 minutes"},
+		{time.Minute, "1 minute"},
+		{10 * time.Second, "1 minute"},
+	}
+	for _, tt := range tests {
+		if got := timeLeft(tt.left); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.left, got, tt.want)
+		}
+	}
+}
@@ -1,16 +1,99 @@
 package service
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"edukita-teaching-grading/internal/app/model"
+	"edukita-teaching-grading/internal/app/payload"
+	"edukita-teaching-grading/internal/app/policy"
 	"edukita-teaching-grading/internal/app/repository"
+	"edukita-teaching-grading/internal/app/worker"
 	"edukita-teaching-grading/internal/pkg"
+	"edukita-teaching-grading/pkg/pubsub"
+	"edukita-teaching-grading/pkg/storage"
+
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
 )
 
 type ServiceOption struct {
 	pkg.OptionsApplication
 	Repository *repository.Repository
+	Policy     *policy.Policy
+	Storage    storage.BlobStore
+	URLSigner  *storage.URLSigner
+	Hub        *pubsub.Hub
+	Jobs       *worker.JobQueue
 }
 
 type Service struct {
 	User               IUserService
 	LearningManagement ILearningManagementService
+	Attachment         IAttachmentService
+	Rubric             IRubricService
+	Gradebook          IGradebookService
+	Audit              IAuditService
+	Webhook            IWebhookService
+	Notification       INotificationService
+	Realtime           IRealtimeService
+	Scheduler          ISchedulerService
+	Job                IJobService
+}
+
+// currentUser loads the authenticated user of the request from the actor carried by the context
+func (o ServiceOption) currentUser(ctx context.Context, tx *sqlx.Tx) (user model.User, err error) {
+	actor, ok := pkg.ActorFromContext(ctx)
+	if !ok {
+		err = pkg.NewUnauthorizedError("unauthorized", nil)
+		o.Logger.Warnf("missing actor in request context", zap.Error(err))
+		return
+	}
+
+	user, err = o.Repository.User.GetUserByID(ctx, actor.ID, tx)
+	if err != nil {
+		o.Logger.Warnf(fmt.Sprintf("failed to get user by id: %s", err.Error()), zap.Error(err))
+		return
+	}
+	return
+}
+
+// authorize checks the action of the user against the policy, denials are returned as forbidden errors
+func (o ServiceOption) authorize(user model.User, action policy.Action, resource policy.Resource) error {
+	err := o.Policy.Authorize(policy.Subject{ID: user.ID, Role: user.Role}, action, resource)
+	if err != nil {
+		o.Logger.Warnf("user %s is not allowed to %s", user.ID, action, zap.Error(err))
+	}
+	return err
+}
+
+func isNotAuthorizedError(err error) bool {
+	var e *pkg.AppError
+	return errors.As(err, &e) && e.StatusCode == http.StatusUnauthorized
+}
+
+func pageToMeta(page pkg.ListPage) payload.MetaResponse {
+	return payload.MetaResponse{
+		TotalCount: page.TotalCount,
+		Page:       page.Page,
+		PerPage:    page.PerPage,
+		NextCursor: page.NextCursor,
+	}
+}
+
+func deletionToResponse(record model.BaseModel, purged bool) (response payload.DeletionResponse) {
+	response.ID = record.ID.String()
+	if record.DeletedAt != nil {
+		deletedAt := record.DeletedAt.Format(time.RFC3339)
+		response.DeletedAt = &deletedAt
+	}
+	if record.DeletedBy != nil {
+		deletedBy := record.DeletedBy.String()
+		response.DeletedBy = &deletedBy
+	}
+	response.Purged = purged
+	return
 }
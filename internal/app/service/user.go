@@ -6,10 +6,13 @@ import (
 	"net/http"
 	"time"
 
+	"edukita-teaching-grading/internal/app/event"
 	"edukita-teaching-grading/internal/app/model"
 	"edukita-teaching-grading/internal/app/payload"
+	"edukita-teaching-grading/internal/app/policy"
 	"edukita-teaching-grading/internal/app/repository"
 	"edukita-teaching-grading/internal/pkg"
+	"edukita-teaching-grading/pkg/mailer"
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
@@ -21,7 +24,28 @@ type (
 		RegisterUser(ctx context.Context, requestBody payload.RegisterUserRequest) (response payload.RegisterUserResponse, err error)
 		LoginUser(ctx context.Context, requestBody *payload.LoginUserRequest) (response payload.LoginUserResponse, err error)
 		GetUserByID(ctx context.Context, id string) (response payload.GetUserResponse, err error)
-		LogoutUser(ctx context.Context, id string) (response payload.LogoutUserResponse, err error)
+		LogoutUser(ctx context.Context, refreshToken string) (response payload.LogoutUserResponse, err error)
+		RefreshToken(ctx context.Context, requestBody *payload.RefreshTokenRequest) (response payload.LoginUserResponse, err error)
+		RevokeUserSessions(ctx context.Context, id string) (response payload.RevokeUserSessionsResponse, err error)
+		DeleteUserByID(ctx context.Context, id string) (response payload.DeletionResponse, err error)
+		RestoreUserByID(ctx context.Context, id string) (response payload.DeletionResponse, err error)
+		PurgeUserByID(ctx context.Context, id string) (response payload.DeletionResponse, err error)
+
+		// password and email verification flows
+		ForgotPassword(ctx context.Context, requestBody payload.ForgotPasswordRequest) (err error)
+		ResetPassword(ctx context.Context, requestBody payload.ResetPasswordRequest) (response payload.ResetPasswordResponse, err error)
+		ChangePassword(ctx context.Context, requestBody payload.ChangePasswordRequest) (response payload.LoginUserResponse, err error)
+		VerifyEmail(ctx context.Context, requestBody payload.VerifyEmailRequest) (response payload.VerifyEmailResponse, err error)
+		ResendVerification(ctx context.Context, requestBody payload.ResendVerificationRequest) (err error)
+
+		// two-factor authentication
+		LoginMFA(ctx context.Context, requestBody payload.LoginMFARequest) (response payload.LoginUserResponse, err error)
+		EnrollMFAChallenge(ctx context.Context, requestBody payload.MFAChallengeRequest) (response payload.MFAEnrollmentResponse, err error)
+		EnrollMFA(ctx context.Context) (response payload.MFAEnrollmentResponse, err error)
+		VerifyMFA(ctx context.Context, requestBody payload.MFACodeRequest) (response payload.MFARecoveryCodesResponse, err error)
+		RegenerateRecoveryCodes(ctx context.Context, requestBody payload.MFACodeRequest) (response payload.MFARecoveryCodesResponse, err error)
+		DisableMFA(ctx context.Context, requestBody payload.DisableMFARequest) (err error)
+		GetMFAStatus(ctx context.Context) (response payload.MFAStatusResponse, err error)
 	}
 	UserService struct {
 		ServiceOption
@@ -35,7 +59,7 @@ func InitiateUserService(opt ServiceOption) IUserService {
 }
 
 func (s *UserService) RegisterUser(ctx context.Context, requestBody payload.RegisterUserRequest) (response payload.RegisterUserResponse, err error) {
-	return response, repository.TransactionWrapper(ctx, s.Postgres, func(tx *sqlx.Tx) (err error) {
+	return response, s.Repository.Tx.Run(ctx, repository.TxReadWrite, func(ctx context.Context, tx *sqlx.Tx) (err error) {
 		now := time.Now()
 		user, err := s.Repository.User.GetUserByEmail(ctx, requestBody.Email, tx)
 		if err != nil {
@@ -101,6 +125,40 @@ func (s *UserService) RegisterUser(ctx context.Context, requestBody payload.Regi
 			return
 		}
 
+		if err = s.audit(ctx, tx, auditChange{
+			action:     pkg.AUDIT_ACTION_CREATE,
+			entityType: pkg.AUDIT_ENTITY_USER,
+			entityID:   user.ID,
+			after:      user,
+		}); err != nil {
+			return
+		}
+
+		if err = s.publish(ctx, tx, event.UserRegistered, user.ID, event.UserRegisteredPayload{
+			UserID:    user.ID,
+			Email:     user.Email,
+			FirstName: user.FirstName,
+			LastName:  user.LastName,
+			Role:      user.Role,
+		}); err != nil {
+			return
+		}
+
+		welcome, err := s.email(user.ID, user.Email, mailer.TemplateWelcome, mailer.WelcomeData{
+			FirstName: user.FirstName,
+			Role:      user.Role,
+		}, nil)
+		if err != nil {
+			return
+		}
+		verification, err := s.verificationEmail(ctx, user, tx)
+		if err != nil {
+			return
+		}
+		if _, err = s.queueEmails(ctx, tx, welcome, verification); err != nil {
+			return
+		}
+
 		response = payload.RegisterUserResponse{
 			ID:        user.ID.String(),
 			FirstName: user.FirstName,
@@ -114,7 +172,7 @@ func (s *UserService) RegisterUser(ctx context.Context, requestBody payload.Regi
 }
 
 func (s *UserService) LoginUser(ctx context.Context, requestBody *payload.LoginUserRequest) (response payload.LoginUserResponse, err error) {
-	return response, repository.TransactionWrapper(ctx, s.Postgres, func(tx *sqlx.Tx) (err error) {
+	return response, s.Repository.Tx.Run(ctx, repository.TxReadWrite, func(ctx context.Context, tx *sqlx.Tx) (err error) {
 		user, err := s.Repository.User.GetUserByEmail(ctx, requestBody.Email, tx)
 		if err != nil {
 			s.Logger.Warnf(fmt.Sprintf("failed to get user by email: %s", err.Error()), zap.Error(err))
@@ -127,29 +185,25 @@ func (s *UserService) LoginUser(ctx context.Context, requestBody *payload.LoginU
 			return
 		}
 
-		token, err := GenerateJWTToken(user, s.Config.Application.Secret, s.Config.Cookies.SSOExpired)
-		if err != nil {
-			s.Logger.Warnf(fmt.Sprintf("failed to generate token: %s", err.Error()), zap.Error(err))
-			return err
+		if s.Config.Auth.RequireEmailVerification && user.EmailVerifiedAt == nil {
+			err = pkg.NewError("EMAIL_NOT_VERIFIED", "email address is not verified", http.StatusForbidden, nil)
+			s.Logger.Warnf("login of unverified user: %s", user.ID, zap.Error(err))
+			return
 		}
 
-		now := time.Now()
-		user.LastLogin = &now
-		user.UpdatedBy = &user.ID
-		user, err = s.Repository.User.UpdateUserByID(ctx, user, tx)
-		if err != nil {
-			s.Logger.Warnf(fmt.Sprintf("failed to update user: %s", err.Error()), zap.Error(err))
+		var challenged bool
+		response, challenged, err = s.loginChallenge(ctx, user, tx)
+		if err != nil || challenged {
 			return
 		}
 
-		response.Token = token
-
+		response, err = s.completeLogin(ctx, user, tx)
 		return
 	})
 }
 
 func (s *UserService) GetUserByID(ctx context.Context, id string) (response payload.GetUserResponse, err error) {
-	return response, repository.TransactionWrapper(ctx, s.Postgres, func(tx *sqlx.Tx) (err error) {
+	return response, s.Repository.Tx.Run(ctx, repository.TxReadOnly, func(ctx context.Context, tx *sqlx.Tx) (err error) {
 		user, err := s.Repository.User.GetUserByID(ctx, id, tx)
 		if err != nil {
 			s.Logger.Warnf(fmt.Sprintf("failed to get user by id: %s", err.Error()), zap.Error(err))
@@ -188,6 +242,9 @@ func (s *UserService) GetUserByID(ctx context.Context, id string) (response payl
 		response.LastName = user.LastName
 		response.Email = user.Email
 		response.IsActive = user.IsActive
+		if user.EmailVerifiedAt != nil {
+			response.EmailVerifiedAt = user.EmailVerifiedAt.Format(time.RFC3339)
+		}
 		response.LastLogin = user.LastLogin.Format(time.RFC3339)
 		response.CreatedAt = user.CreatedAt.Format(time.RFC3339)
 		if user.UpdatedAt != nil {
@@ -198,16 +255,331 @@ func (s *UserService) GetUserByID(ctx context.Context, id string) (response payl
 	})
 }
 
-func (s *UserService) LogoutUser(ctx context.Context, id string) (response payload.LogoutUserResponse, err error) {
-	return response, repository.TransactionWrapper(ctx, s.Postgres, func(tx *sqlx.Tx) (err error) {
+func (s *UserService) LogoutUser(ctx context.Context, refreshToken string) (response payload.LogoutUserResponse, err error) {
+	return response, s.Repository.Tx.Run(ctx, repository.TxReadWrite, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+		user, err := s.currentUser(ctx, tx)
+		if err != nil {
+			return
+		}
+
+		actor, _ := pkg.ActorFromContext(ctx)
+		now := time.Now()
+		expiresAt := now.Add(s.Config.Cookies.AccessExpired)
+		if !actor.ExpiresAt.IsZero() {
+			expiresAt = actor.ExpiresAt
+		}
+		err = s.Repository.Auth.CreateRevokedToken(ctx, model.RevokedToken{
+			TokenID:   actor.TokenID,
+			UserID:    user.ID,
+			Reason:    pkg.REVOKE_REASON_LOGOUT,
+			ExpiresAt: expiresAt,
+			RevokedBy: &user.ID,
+			RevokedAt: now,
+		}, tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to revoke access token: %s", err.Error()), zap.Error(err))
+			return
+		}
+
+		if refreshToken != "" {
+			token, err := s.Repository.Auth.GetRefreshTokenByHash(ctx, HashOpaqueToken(refreshToken), tx)
+			if err != nil && !isNotAuthorizedError(err) {
+				s.Logger.Warnf(fmt.Sprintf("failed to get refresh token: %s", err.Error()), zap.Error(err))
+				return err
+			}
+			if err == nil && token.UserID == user.ID && token.RevokedAt == nil {
+				token.RevokedAt = &now
+				if _, err = s.Repository.Auth.UpdateRefreshTokenByID(ctx, token, tx); err != nil {
+					s.Logger.Warnf(fmt.Sprintf("failed to revoke refresh token: %s", err.Error()), zap.Error(err))
+					return err
+				}
+			}
+		}
+
+		user.LastLogin = &now
+		user.UpdatedBy = &user.ID
+		user, err = s.Repository.User.UpdateUserByID(ctx, user, tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to update user: %s", err.Error()), zap.Error(err))
+			return
+		}
+
+		response.ID = user.ID.String()
+		return
+	})
+}
+
+func (s *UserService) RefreshToken(ctx context.Context, requestBody *payload.RefreshTokenRequest) (response payload.LoginUserResponse, err error) {
+	var reused bool
+	err = s.Repository.Tx.Run(ctx, repository.TxReadWrite, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+		token, err := s.Repository.Auth.GetRefreshTokenByHash(ctx, HashOpaqueToken(requestBody.RefreshToken), tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to get refresh token: %s", err.Error()), zap.Error(err))
+			return
+		}
+
+		now := time.Now()
+		if token.RevokedAt != nil && token.ReplacedBy == nil {
+			// signed out by a logout or a revocation, nothing was handed out in exchange for the token
+			err = pkg.NewError("REFRESH_TOKEN_REVOKED", "invalid refresh token", http.StatusUnauthorized, nil)
+			s.Logger.Warnf("revoked refresh token presented for user: %s", token.UserID, zap.Error(err))
+			return
+		}
+		if token.RevokedAt != nil {
+			// a rotated token being presented again means it leaked, so sign the user out everywhere
+			s.Logger.Warnf("refresh token reused for user: %s", token.UserID)
+			if _, err = s.revokeSessions(ctx, token.UserID, nil, pkg.REVOKE_REASON_TOKEN_REUSED, tx); err != nil {
+				return
+			}
+			reused = true
+			return
+		}
+
+		if now.After(token.ExpiresAt) {
+			err = pkg.NewError("REFRESH_TOKEN_EXPIRED", "refresh token expired", http.StatusUnauthorized, nil)
+			s.Logger.Warnf("refresh token expired for user: %s", token.UserID, zap.Error(err))
+			return
+		}
+
+		user, err := s.Repository.User.GetUserByID(ctx, token.UserID.String(), tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to get user by id: %s", err.Error()), zap.Error(err))
+			return
+		}
+
+		response, err = s.issueTokens(ctx, user, tx)
+		if err != nil {
+			return
+		}
+
+		replacement, err := s.Repository.Auth.GetRefreshTokenByHash(ctx, HashOpaqueToken(response.RefreshToken), tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to get refresh token: %s", err.Error()), zap.Error(err))
+			return
+		}
+
+		token.RevokedAt = &now
+		token.ReplacedBy = &replacement.ID
+		if _, err = s.Repository.Auth.UpdateRefreshTokenByID(ctx, token, tx); err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to rotate refresh token: %s", err.Error()), zap.Error(err))
+			return
+		}
+		return
+	})
+	if err == nil && reused {
+		err = pkg.NewError("REFRESH_TOKEN_REUSED", "invalid refresh token", http.StatusUnauthorized, nil)
+	}
+	return
+}
+
+func (s *UserService) RevokeUserSessions(ctx context.Context, id string) (response payload.RevokeUserSessionsResponse, err error) {
+	return response, s.Repository.Tx.Run(ctx, repository.TxReadWrite, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+		admin, err := s.currentUser(ctx, tx)
+		if err != nil {
+			return
+		}
+
+		if err = s.authorize(admin, policy.SessionRevoke, policy.Resource{}); err != nil {
+			return
+		}
+
+		user, err := s.Repository.User.GetUserByID(ctx, id, tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to get user by id: %s", err.Error()), zap.Error(err))
+			return
+		}
+
+		revoked, err := s.revokeSessions(ctx, user.ID, &admin.ID, pkg.REVOKE_REASON_FORCED, tx)
+		if err != nil {
+			return
+		}
+
+		response.UserID = user.ID.String()
+		response.RevokedSessions = revoked
+
+		if err = s.audit(ctx, tx, auditChange{
+			action:     pkg.AUDIT_ACTION_DELETE,
+			entityType: pkg.AUDIT_ENTITY_SESSION,
+			entityID:   user.ID,
+			before:     response,
+		}); err != nil {
+			return
+		}
+		return
+	})
+}
+
+// DeleteUserByID soft-deletes the account and signs it out everywhere, the user can no longer log in
+func (s *UserService) DeleteUserByID(ctx context.Context, id string) (response payload.DeletionResponse, err error) {
+	return response, s.Repository.Tx.Run(ctx, repository.TxReadWrite, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+		admin, err := s.currentUser(ctx, tx)
+		if err != nil {
+			return
+		}
+
+		if err = s.authorize(admin, policy.UserDelete, policy.Resource{}); err != nil {
+			return
+		}
+
 		user, err := s.Repository.User.GetUserByID(ctx, id, tx)
 		if err != nil {
 			s.Logger.Warnf(fmt.Sprintf("failed to get user by id: %s", err.Error()), zap.Error(err))
 			return
 		}
 
+		if user.ID == admin.ID {
+			err = pkg.NewBadRequestError("admins cannot delete their own account", nil)
+			s.Logger.Warnf("admin %s tried to delete their own account", admin.ID, zap.Error(err))
+			return
+		}
+
+		before := user
+		user, err = s.Repository.User.DeleteUserByID(ctx, user.ID.String(), admin.ID, time.Now(), tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to delete user: %s", err.Error()), zap.Error(err))
+			return
+		}
+
+		if _, err = s.revokeSessions(ctx, user.ID, &admin.ID, pkg.REVOKE_REASON_USER_DELETED, tx); err != nil {
+			return
+		}
+
+		if err = s.audit(ctx, tx, auditChange{
+			action:     pkg.AUDIT_ACTION_DELETE,
+			entityType: pkg.AUDIT_ENTITY_USER,
+			entityID:   user.ID,
+			before:     before,
+			after:      user,
+		}); err != nil {
+			return
+		}
+
+		response = deletionToResponse(user.BaseModel, false)
+		return
+	})
+}
+
+// RestoreUserByID brings back a soft-deleted account, it fails with a conflict when its email was registered again
+func (s *UserService) RestoreUserByID(ctx context.Context, id string) (response payload.DeletionResponse, err error) {
+	return response, s.Repository.Tx.Run(ctx, repository.TxReadWrite, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+		admin, err := s.currentUser(ctx, tx)
+		if err != nil {
+			return
+		}
+
+		if err = s.authorize(admin, policy.RecordRestore, policy.Resource{}); err != nil {
+			return
+		}
+
+		before, err := s.Repository.User.GetDeletedUserByID(ctx, id, tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to get deleted user by id: %s", err.Error()), zap.Error(err))
+			return
+		}
+
+		user, err := s.Repository.User.RestoreUserByID(ctx, before.ID.String(), admin.ID, tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to restore user: %s", err.Error()), zap.Error(err))
+			return
+		}
+
+		if err = s.audit(ctx, tx, auditChange{
+			action:     pkg.AUDIT_ACTION_RESTORE,
+			entityType: pkg.AUDIT_ENTITY_USER,
+			entityID:   user.ID,
+			before:     before,
+			after:      user,
+		}); err != nil {
+			return
+		}
+
+		response = deletionToResponse(user.BaseModel, false)
+		return
+	})
+}
+
+// PurgeUserByID permanently removes a soft-deleted account with its profile and everything it owns
+func (s *UserService) PurgeUserByID(ctx context.Context, id string) (response payload.DeletionResponse, err error) {
+	return response, s.Repository.Tx.Run(ctx, repository.TxReadWrite, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+		admin, err := s.currentUser(ctx, tx)
+		if err != nil {
+			return
+		}
+
+		if err = s.authorize(admin, policy.RecordPurge, policy.Resource{}); err != nil {
+			return
+		}
+
+		user, err := s.Repository.User.PurgeUserByID(ctx, id, tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to purge user: %s", err.Error()), zap.Error(err))
+			return
+		}
+
+		if err = s.audit(ctx, tx, auditChange{
+			action:     pkg.AUDIT_ACTION_PURGE,
+			entityType: pkg.AUDIT_ENTITY_USER,
+			entityID:   user.ID,
+			before:     user,
+		}); err != nil {
+			return
+		}
+
+		response = deletionToResponse(user.BaseModel, true)
+		return
+	})
+}
+
+// ForgotPassword mails a password reset link when the email belongs to an active account. It succeeds either way,
+// so the endpoint does not tell which addresses have an account.
+func (s *UserService) ForgotPassword(ctx context.Context, requestBody payload.ForgotPasswordRequest) (err error) {
+	return s.Repository.Tx.Run(ctx, repository.TxReadWrite, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+		user, err := s.Repository.User.GetUserByEmail(ctx, requestBody.Email, tx)
+		if err != nil {
+			if pkg.IsNotFound(err) {
+				s.Logger.Infof("password reset asked for unknown email")
+				return nil
+			}
+			s.Logger.Warnf(fmt.Sprintf("failed to get user by email: %s", err.Error()), zap.Error(err))
+			return
+		}
+
+		token, err := s.issueUserToken(ctx, user, pkg.USER_TOKEN_PASSWORD_RESET, s.Config.Auth.PasswordResetExpired, tx)
+		if err != nil {
+			return
+		}
+
+		reset, err := s.email(user.ID, user.Email, mailer.TemplatePasswordReset, mailer.PasswordResetData{
+			FirstName:        user.FirstName,
+			ExpiresInMinutes: int(s.Config.Auth.PasswordResetExpired.Minutes()),
+		}, nil)
+		if err != nil {
+			return
+		}
+		reset.UserTokenID = &token.ID
+		_, err = s.queueEmails(ctx, tx, reset)
+		return
+	})
+}
+
+// ResetPassword sets a new password with a reset token and signs the user out everywhere. Following the link
+// proves the user reads the address, so it verifies the email as well.
+func (s *UserService) ResetPassword(ctx context.Context, requestBody payload.ResetPasswordRequest) (response payload.ResetPasswordResponse, err error) {
+	return response, s.Repository.Tx.Run(ctx, repository.TxReadWrite, func(ctx context.Context, tx *sqlx.Tx) (err error) {
 		now := time.Now()
-		user.LastLogin = &now
+		user, err := s.useUserToken(ctx, requestBody.Token, pkg.USER_TOKEN_PASSWORD_RESET, now, tx)
+		if err != nil {
+			return
+		}
+
+		before := user
+		if err = user.SetPassword(requestBody.Password, s.Config.Application.CostBcrypt); err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to hash password: %s", err.Error()), zap.Error(err))
+			return
+		}
+		if user.EmailVerifiedAt == nil {
+			user.EmailVerifiedAt = &now
+		}
 		user.UpdatedBy = &user.ID
 		user, err = s.Repository.User.UpdateUserByID(ctx, user, tx)
 		if err != nil {
@@ -215,7 +587,300 @@ func (s *UserService) LogoutUser(ctx context.Context, id string) (response paylo
 			return
 		}
 
+		revoked, err := s.revokeSessions(ctx, user.ID, &user.ID, pkg.REVOKE_REASON_PASSWORD, tx)
+		if err != nil {
+			return
+		}
+
+		if err = s.audit(ctx, tx, auditChange{
+			action:     pkg.AUDIT_ACTION_UPDATE,
+			entityType: pkg.AUDIT_ENTITY_USER,
+			entityID:   user.ID,
+			before:     before,
+			after:      user,
+		}); err != nil {
+			return
+		}
+
 		response.ID = user.ID.String()
+		response.RevokedSessions = revoked
+		return
+	})
+}
+
+// ChangePassword sets a new password after checking the current one. Every session is signed out, the one making
+// the change gets fresh tokens.
+func (s *UserService) ChangePassword(ctx context.Context, requestBody payload.ChangePasswordRequest) (response payload.LoginUserResponse, err error) {
+	return response, s.Repository.Tx.Run(ctx, repository.TxReadWrite, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+		user, err := s.currentUser(ctx, tx)
+		if err != nil {
+			return
+		}
+
+		if !user.CheckPassword(requestBody.CurrentPassword) {
+			err = pkg.NewBadRequestError("invalid password", nil)
+			s.Logger.Warnf("invalid current password for user: %s", user.ID, zap.Error(err))
+			return
+		}
+
+		before := user
+		if err = user.SetPassword(requestBody.NewPassword, s.Config.Application.CostBcrypt); err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to hash password: %s", err.Error()), zap.Error(err))
+			return
+		}
+		user.UpdatedBy = &user.ID
+		user, err = s.Repository.User.UpdateUserByID(ctx, user, tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to update user: %s", err.Error()), zap.Error(err))
+			return
+		}
+
+		// the access token of the request may predate the refresh tokens that are left, deny it on its own
+		actor, _ := pkg.ActorFromContext(ctx)
+		now := time.Now()
+		expiresAt := now.Add(s.Config.Cookies.AccessExpired)
+		if !actor.ExpiresAt.IsZero() {
+			expiresAt = actor.ExpiresAt
+		}
+		err = s.Repository.Auth.CreateRevokedToken(ctx, model.RevokedToken{
+			TokenID:   actor.TokenID,
+			UserID:    user.ID,
+			Reason:    pkg.REVOKE_REASON_PASSWORD,
+			ExpiresAt: expiresAt,
+			RevokedBy: &user.ID,
+			RevokedAt: now,
+		}, tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to revoke access token: %s", err.Error()), zap.Error(err))
+			return
+		}
+		if _, err = s.revokeSessions(ctx, user.ID, &user.ID, pkg.REVOKE_REASON_PASSWORD, tx); err != nil {
+			return
+		}
+
+		if err = s.audit(ctx, tx, auditChange{
+			action:     pkg.AUDIT_ACTION_UPDATE,
+			entityType: pkg.AUDIT_ENTITY_USER,
+			entityID:   user.ID,
+			before:     before,
+			after:      user,
+		}); err != nil {
+			return
+		}
+
+		response, err = s.issueTokens(ctx, user, tx)
 		return
 	})
 }
+
+// VerifyEmail marks the email address of the user that the verification token was mailed to as verified
+func (s *UserService) VerifyEmail(ctx context.Context, requestBody payload.VerifyEmailRequest) (response payload.VerifyEmailResponse, err error) {
+	return response, s.Repository.Tx.Run(ctx, repository.TxReadWrite, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+		now := time.Now()
+		user, err := s.useUserToken(ctx, requestBody.Token, pkg.USER_TOKEN_EMAIL_VERIFICATION, now, tx)
+		if err != nil {
+			return
+		}
+
+		if user.EmailVerifiedAt == nil {
+			before := user
+			user.EmailVerifiedAt = &now
+			user.UpdatedBy = &user.ID
+			user, err = s.Repository.User.UpdateUserByID(ctx, user, tx)
+			if err != nil {
+				s.Logger.Warnf(fmt.Sprintf("failed to update user: %s", err.Error()), zap.Error(err))
+				return
+			}
+
+			if err = s.audit(ctx, tx, auditChange{
+				action:     pkg.AUDIT_ACTION_UPDATE,
+				entityType: pkg.AUDIT_ENTITY_USER,
+				entityID:   user.ID,
+				before:     before,
+				after:      user,
+			}); err != nil {
+				return
+			}
+		}
+
+		response.ID = user.ID.String()
+		response.Email = user.Email
+		response.EmailVerifiedAt = user.EmailVerifiedAt.Format(time.RFC3339)
+		return
+	})
+}
+
+// ResendVerification mails a new verification link when the email belongs to an unverified account, the earlier
+// links stop working. Like ForgotPassword it succeeds either way.
+func (s *UserService) ResendVerification(ctx context.Context, requestBody payload.ResendVerificationRequest) (err error) {
+	return s.Repository.Tx.Run(ctx, repository.TxReadWrite, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+		user, err := s.Repository.User.GetUserByEmail(ctx, requestBody.Email, tx)
+		if err != nil {
+			if pkg.IsNotFound(err) {
+				s.Logger.Infof("email verification asked for unknown email")
+				return nil
+			}
+			s.Logger.Warnf(fmt.Sprintf("failed to get user by email: %s", err.Error()), zap.Error(err))
+			return
+		}
+		if user.EmailVerifiedAt != nil {
+			return nil
+		}
+
+		verification, err := s.verificationEmail(ctx, user, tx)
+		if err != nil {
+			return
+		}
+		_, err = s.queueEmails(ctx, tx, verification)
+		return
+	})
+}
+
+// verificationEmail issues an email verification token for the user and queues the email with its link
+func (s *UserService) verificationEmail(ctx context.Context, user model.User, tx *sqlx.Tx) (email model.EmailMessage, err error) {
+	token, err := s.issueUserToken(ctx, user, pkg.USER_TOKEN_EMAIL_VERIFICATION, s.Config.Auth.EmailVerificationExpired, tx)
+	if err != nil {
+		return
+	}
+
+	email, err = s.email(user.ID, user.Email, mailer.TemplateEmailVerification, mailer.EmailVerificationData{
+		FirstName:      user.FirstName,
+		ExpiresInHours: int(s.Config.Auth.EmailVerificationExpired.Hours()),
+	}, nil)
+	if err != nil {
+		return
+	}
+	email.UserTokenID = &token.ID
+	return
+}
+
+// issueUserToken persists a new single-use token with the purpose for the user, the tokens mailed before stop
+// working. The token in the link is derived from it when the email is sent, see model.UserToken.Mailed.
+func (s *UserService) issueUserToken(ctx context.Context, user model.User, purpose string, expired time.Duration, tx *sqlx.Tx) (token model.UserToken, err error) {
+	now := time.Now()
+	if _, err = s.Repository.Auth.UseUserTokensByUserID(ctx, user.ID.String(), purpose, now, tx); err != nil {
+		s.Logger.Warnf(fmt.Sprintf("failed to invalidate %s tokens: %s", purpose, err.Error()), zap.Error(err))
+		return
+	}
+
+	token = model.UserToken{
+		ID:        uuid.New(),
+		UserID:    user.ID,
+		Purpose:   purpose,
+		ExpiresAt: now.Add(expired),
+		CreatedAt: now,
+	}
+	token.TokenHash = HashOpaqueToken(token.Mailed(s.Config.Application.Secret))
+	if token, err = s.Repository.Auth.CreateUserToken(ctx, token, tx); err != nil {
+		s.Logger.Warnf(fmt.Sprintf("failed to create %s token: %s", purpose, err.Error()), zap.Error(err))
+		return
+	}
+	return
+}
+
+// useUserToken spends a single-use token with the purpose and returns the user it was mailed to. Used, expired
+// and unknown tokens are all refused the same way.
+func (s *UserService) useUserToken(ctx context.Context, token string, purpose string, now time.Time, tx *sqlx.Tx) (user model.User, err error) {
+	userToken, err := s.Repository.Auth.GetUserTokenByHash(ctx, HashOpaqueToken(token), purpose, tx)
+	if err != nil {
+		s.Logger.Warnf(fmt.Sprintf("failed to get %s token: %s", purpose, err.Error()), zap.Error(err))
+		return
+	}
+
+	if userToken.UsedAt != nil || !now.Before(userToken.ExpiresAt) {
+		err = pkg.NewError("USER_TOKEN_EXPIRED", "invalid or expired token", http.StatusBadRequest, nil)
+		s.Logger.Warnf("%s token of user %s is used or expired", purpose, userToken.UserID, zap.Error(err))
+		return
+	}
+
+	user, err = s.Repository.User.GetUserByID(ctx, userToken.UserID.String(), tx)
+	if err != nil {
+		s.Logger.Warnf(fmt.Sprintf("failed to get user by id: %s", err.Error()), zap.Error(err))
+		return
+	}
+
+	if _, err = s.Repository.Auth.UseUserTokensByUserID(ctx, user.ID.String(), purpose, now, tx); err != nil {
+		s.Logger.Warnf(fmt.Sprintf("failed to use %s token: %s", purpose, err.Error()), zap.Error(err))
+		return
+	}
+	return
+}
+
+// completeLogin issues the tokens of a new session for a user that passed every factor
+func (s *UserService) completeLogin(ctx context.Context, user model.User, tx *sqlx.Tx) (response payload.LoginUserResponse, err error) {
+	response, err = s.issueTokens(ctx, user, tx)
+	if err != nil {
+		return
+	}
+
+	now := time.Now()
+	user.LastLogin = &now
+	user.UpdatedBy = &user.ID
+	if _, err = s.Repository.User.UpdateUserByID(ctx, user, tx); err != nil {
+		s.Logger.Warnf(fmt.Sprintf("failed to update user: %s", err.Error()), zap.Error(err))
+		return
+	}
+	return
+}
+
+// issueTokens signs a new access token and persists the refresh token bound to it
+func (s *UserService) issueTokens(ctx context.Context, user model.User, tx *sqlx.Tx) (response payload.LoginUserResponse, err error) {
+	now := time.Now()
+	tokenID := uuid.NewString()
+	accessToken, err := GenerateJWTToken(user, tokenID, s.Config.Application.Secret, s.Config.Cookies.AccessExpired)
+	if err != nil {
+		s.Logger.Warnf(fmt.Sprintf("failed to generate token: %s", err.Error()), zap.Error(err))
+		return
+	}
+
+	refreshToken, refreshHash, err := GenerateOpaqueToken()
+	if err != nil {
+		s.Logger.Warnf(fmt.Sprintf("failed to generate refresh token: %s", err.Error()), zap.Error(err))
+		return
+	}
+
+	refresh, err := s.Repository.Auth.CreateRefreshToken(ctx, model.RefreshToken{
+		ID:            uuid.New(),
+		UserID:        user.ID,
+		TokenHash:     refreshHash,
+		AccessTokenID: tokenID,
+		ExpiresAt:     now.Add(s.Config.Cookies.SSOExpired),
+		CreatedAt:     now,
+	}, tx)
+	if err != nil {
+		s.Logger.Warnf(fmt.Sprintf("failed to create refresh token: %s", err.Error()), zap.Error(err))
+		return
+	}
+
+	response.Token = accessToken
+	response.ExpiresAt = now.Add(s.Config.Cookies.AccessExpired).Format(time.RFC3339)
+	response.RefreshToken = refreshToken
+	response.RefreshExpiresAt = refresh.ExpiresAt.Format(time.RFC3339)
+	return
+}
+
+// revokeSessions revokes every active refresh token of the user and deny-lists the access tokens issued with them
+func (s *UserService) revokeSessions(ctx context.Context, userID uuid.UUID, revokedBy *uuid.UUID, reason string, tx *sqlx.Tx) (revoked int, err error) {
+	now := time.Now()
+	tokens, err := s.Repository.Auth.RevokeRefreshTokensByUserID(ctx, userID.String(), now, tx)
+	if err != nil {
+		s.Logger.Warnf(fmt.Sprintf("failed to revoke refresh tokens: %s", err.Error()), zap.Error(err))
+		return
+	}
+
+	for _, token := range tokens {
+		err = s.Repository.Auth.CreateRevokedToken(ctx, model.RevokedToken{
+			TokenID:   token.AccessTokenID,
+			UserID:    userID,
+			Reason:    reason,
+			ExpiresAt: now.Add(s.Config.Cookies.AccessExpired),
+			RevokedBy: revokedBy,
+			RevokedAt: now,
+		}, tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to revoke access token: %s", err.Error()), zap.Error(err))
+			return
+		}
+	}
+	return len(tokens), nil
+}
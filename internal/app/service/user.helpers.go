@@ -1,14 +1,17 @@
 package service
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
 	"edukita-teaching-grading/internal/app/model"
+	"encoding/base64"
+	"encoding/hex"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
-	"github.com/google/uuid"
 )
 
-func GenerateJWTToken(user model.User, secret string, expireTime time.Duration) (string, error) {
+func GenerateJWTToken(user model.User, tokenID string, secret string, expireTime time.Duration) (string, error) {
 	createdAt := user.CreatedAt.Format(time.RFC3339)
 	var updatedAt string
 	if user.UpdatedAt != nil {
@@ -17,7 +20,7 @@ func GenerateJWTToken(user model.User, secret string, expireTime time.Duration)
 	now := time.Now()
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, model.JWTToken{
 		RegisteredClaims: jwt.RegisteredClaims{
-			ID:        uuid.New().String(),
+			ID:        tokenID,
 			Issuer:    "edukita-teaching-grading",
 			ExpiresAt: jwt.NewNumericDate(now.Add(expireTime)),
 			IssuedAt:  jwt.NewNumericDate(now),
@@ -32,3 +35,18 @@ func GenerateJWTToken(user model.User, secret string, expireTime time.Duration)
 	})
 	return token.SignedString([]byte(secret))
 }
+
+// GenerateOpaqueToken returns a random URL-safe token and the hash that should be persisted instead of it
+func GenerateOpaqueToken() (token string, hash string, err error) {
+	buf := make([]byte, 32)
+	if _, err = rand.Read(buf); err != nil {
+		return
+	}
+	token = base64.RawURLEncoding.EncodeToString(buf)
+	return token, HashOpaqueToken(token), nil
+}
+
+func HashOpaqueToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,538 @@
+package service
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"edukita-teaching-grading/internal/app/model"
+	"edukita-teaching-grading/internal/app/payload"
+	"edukita-teaching-grading/internal/app/repository"
+	"edukita-teaching-grading/internal/pkg"
+	"edukita-teaching-grading/pkg/totp"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+const (
+	// recoveryCodeCount is how many recovery codes a user gets at a time
+	recoveryCodeCount = 10
+	// mfaSkew accepts the codes of the steps next to the current one, for clocks that drift
+	mfaSkew = 1
+)
+
+// LoginMFA completes a login that was answered with an MFA challenge. A login that confirms the enrollment of a
+// user of a role that must use two-factor authentication turns it on and returns the recovery codes.
+func (s *UserService) LoginMFA(ctx context.Context, requestBody payload.LoginMFARequest) (response payload.LoginUserResponse, err error) {
+	var failed bool
+	err = s.Repository.Tx.Run(ctx, repository.TxReadWrite, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+		now := time.Now()
+		challenge, user, err := s.mfaChallenge(ctx, requestBody.MFAToken, now, tx)
+		if err != nil {
+			return
+		}
+
+		mfa, err := s.Repository.Auth.GetUserMFAByUserIDForUpdate(ctx, user.ID.String(), tx)
+		if err != nil {
+			if pkg.IsNotFound(err) {
+				err = pkg.NewBadRequestError("enroll an authenticator app before logging in", nil)
+			}
+			s.Logger.Warnf(fmt.Sprintf("failed to get mfa of user: %s", err.Error()), zap.Error(err))
+			return
+		}
+
+		ok, err := s.checkSecondFactor(ctx, &mfa, requestBody.Code, requestBody.RecoveryCode, now, tx)
+		if err != nil {
+			return
+		}
+		if !ok {
+			// the attempt is counted even though the login fails, so the transaction has to commit
+			challenge.Attempts++
+			if _, err = s.Repository.Auth.UpdateUserTokenByID(ctx, challenge, tx); err != nil {
+				s.Logger.Warnf(fmt.Sprintf("failed to count mfa attempt: %s", err.Error()), zap.Error(err))
+				return
+			}
+			s.Logger.Warnf("invalid mfa code for user: %s", user.ID)
+			failed = true
+			return
+		}
+
+		if _, err = s.Repository.Auth.UseUserTokensByUserID(ctx, user.ID.String(), pkg.USER_TOKEN_MFA_CHALLENGE, now, tx); err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to use mfa challenge: %s", err.Error()), zap.Error(err))
+			return
+		}
+
+		var recoveryCodes []string
+		if mfa.EnabledAt == nil {
+			if recoveryCodes, err = s.enableMFA(ctx, &mfa, now, tx); err != nil {
+				return
+			}
+		} else if mfa, err = s.Repository.Auth.SaveUserMFA(ctx, mfa, tx); err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to save mfa: %s", err.Error()), zap.Error(err))
+			return
+		}
+
+		if response, err = s.completeLogin(ctx, user, tx); err != nil {
+			return
+		}
+		response.RecoveryCodes = recoveryCodes
+		return
+	})
+	if err == nil && failed {
+		err = pkg.NewError("MFA_CODE_INVALID", "invalid code", http.StatusUnauthorized, nil)
+	}
+	return
+}
+
+// EnrollMFAChallenge starts the enrollment of a user of a role that must use two-factor authentication, with the
+// challenge of their login as they cannot log in without it
+func (s *UserService) EnrollMFAChallenge(ctx context.Context, requestBody payload.MFAChallengeRequest) (response payload.MFAEnrollmentResponse, err error) {
+	return response, s.Repository.Tx.Run(ctx, repository.TxReadWrite, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+		_, user, err := s.mfaChallenge(ctx, requestBody.MFAToken, time.Now(), tx)
+		if err != nil {
+			return
+		}
+
+		response, err = s.enrollMFA(ctx, user, tx)
+		return
+	})
+}
+
+// EnrollMFA creates a new TOTP secret for the current user, it is enforced once a code of it is verified
+func (s *UserService) EnrollMFA(ctx context.Context) (response payload.MFAEnrollmentResponse, err error) {
+	return response, s.Repository.Tx.Run(ctx, repository.TxReadWrite, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+		user, err := s.currentUser(ctx, tx)
+		if err != nil {
+			return
+		}
+
+		response, err = s.enrollMFA(ctx, user, tx)
+		return
+	})
+}
+
+// VerifyMFA confirms the enrollment of the current user with a code of the authenticator app, turns two-factor
+// authentication on and returns the recovery codes
+func (s *UserService) VerifyMFA(ctx context.Context, requestBody payload.MFACodeRequest) (response payload.MFARecoveryCodesResponse, err error) {
+	return response, s.Repository.Tx.Run(ctx, repository.TxReadWrite, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+		user, err := s.currentUser(ctx, tx)
+		if err != nil {
+			return
+		}
+
+		mfa, err := s.Repository.Auth.GetUserMFAByUserIDForUpdate(ctx, user.ID.String(), tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to get mfa of user: %s", err.Error()), zap.Error(err))
+			return
+		}
+		if mfa.EnabledAt != nil {
+			err = pkg.NewBadRequestError("two-factor authentication is already enabled", nil)
+			s.Logger.Warnf("mfa of user %s is already enabled", user.ID, zap.Error(err))
+			return
+		}
+
+		if err = s.requireTOTP(&mfa, requestBody.Code, time.Now()); err != nil {
+			return
+		}
+
+		response.RecoveryCodes, err = s.enableMFA(ctx, &mfa, time.Now(), tx)
+		return
+	})
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes of the current user, the earlier ones stop working
+func (s *UserService) RegenerateRecoveryCodes(ctx context.Context, requestBody payload.MFACodeRequest) (response payload.MFARecoveryCodesResponse, err error) {
+	return response, s.Repository.Tx.Run(ctx, repository.TxReadWrite, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+		user, mfa, err := s.enabledMFA(ctx, tx)
+		if err != nil {
+			return
+		}
+
+		if err = s.requireTOTP(&mfa, requestBody.Code, time.Now()); err != nil {
+			return
+		}
+		if _, err = s.Repository.Auth.SaveUserMFA(ctx, mfa, tx); err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to save mfa: %s", err.Error()), zap.Error(err))
+			return
+		}
+
+		response.RecoveryCodes, err = s.replaceRecoveryCodes(ctx, user.ID, tx)
+		return
+	})
+}
+
+// DisableMFA turns two-factor authentication off for the current user after checking their password and a code,
+// users of a role that must use it cannot
+func (s *UserService) DisableMFA(ctx context.Context, requestBody payload.DisableMFARequest) (err error) {
+	return s.Repository.Tx.Run(ctx, repository.TxReadWrite, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+		user, mfa, err := s.enabledMFA(ctx, tx)
+		if err != nil {
+			return
+		}
+
+		if s.mfaRequired(user) {
+			err = pkg.NewForbiddenError(fmt.Sprintf("two-factor authentication is required for %s accounts", user.Role), nil)
+			s.Logger.Warnf("user %s tried to disable required mfa", user.ID, zap.Error(err))
+			return
+		}
+
+		if !user.CheckPassword(requestBody.Password) {
+			err = pkg.NewBadRequestError("invalid password", nil)
+			s.Logger.Warnf("invalid password to disable mfa for user: %s", user.ID, zap.Error(err))
+			return
+		}
+
+		// the code may be either kind, a user that lost the authenticator still has the recovery codes
+		ok, err := s.checkSecondFactor(ctx, &mfa, requestBody.Code, requestBody.Code, time.Now(), tx)
+		if err != nil {
+			return
+		}
+		if !ok {
+			err = pkg.NewBadRequestError("invalid code", nil)
+			s.Logger.Warnf("invalid code to disable mfa for user: %s", user.ID, zap.Error(err))
+			return
+		}
+
+		if err = s.Repository.Auth.DeleteUserMFA(ctx, user.ID.String(), tx); err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to delete mfa: %s", err.Error()), zap.Error(err))
+			return
+		}
+
+		return s.audit(ctx, tx, auditChange{
+			action:     pkg.AUDIT_ACTION_DELETE,
+			entityType: pkg.AUDIT_ENTITY_MFA,
+			entityID:   user.ID,
+			before:     mfa,
+		})
+	})
+}
+
+func (s *UserService) GetMFAStatus(ctx context.Context) (response payload.MFAStatusResponse, err error) {
+	return response, s.Repository.Tx.Run(ctx, repository.TxReadOnly, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+		user, err := s.currentUser(ctx, tx)
+		if err != nil {
+			return
+		}
+		response.Required = s.mfaRequired(user)
+
+		mfa, err := s.Repository.Auth.GetUserMFAByUserID(ctx, user.ID.String(), tx)
+		if err != nil {
+			if pkg.IsNotFound(err) {
+				return nil
+			}
+			s.Logger.Warnf(fmt.Sprintf("failed to get mfa of user: %s", err.Error()), zap.Error(err))
+			return
+		}
+		if mfa.EnabledAt == nil {
+			return
+		}
+
+		response.Enabled = true
+		response.EnabledAt = mfa.EnabledAt.Format(time.RFC3339)
+		response.RecoveryCodesLeft, err = s.Repository.Auth.CountUnusedRecoveryCodes(ctx, user.ID.String(), tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to count recovery codes: %s", err.Error()), zap.Error(err))
+			return
+		}
+		return
+	})
+}
+
+// loginChallenge answers a login whose password checked out with an MFA challenge instead of tokens when the user
+// turned two-factor authentication on or their role must use it. challenged is false when tokens may be issued.
+func (s *UserService) loginChallenge(ctx context.Context, user model.User, tx *sqlx.Tx) (response payload.LoginUserResponse, challenged bool, err error) {
+	mfa, err := s.Repository.Auth.GetUserMFAByUserIDForUpdate(ctx, user.ID.String(), tx)
+	if err != nil && !pkg.IsNotFound(err) {
+		s.Logger.Warnf(fmt.Sprintf("failed to get mfa of user: %s", err.Error()), zap.Error(err))
+		return
+	}
+	enabled := err == nil && mfa.EnabledAt != nil
+	if !enabled && !s.mfaRequired(user) {
+		return response, false, nil
+	}
+
+	now := time.Now()
+	token, hash, err := GenerateOpaqueToken()
+	if err != nil {
+		s.Logger.Warnf(fmt.Sprintf("failed to generate mfa challenge: %s", err.Error()), zap.Error(err))
+		return
+	}
+
+	challenge, err := s.Repository.Auth.CreateUserToken(ctx, model.UserToken{
+		ID:        uuid.New(),
+		UserID:    user.ID,
+		Purpose:   pkg.USER_TOKEN_MFA_CHALLENGE,
+		TokenHash: hash,
+		ExpiresAt: now.Add(s.Config.Auth.MFAChallengeExpired),
+		CreatedAt: now,
+	}, tx)
+	if err != nil {
+		s.Logger.Warnf(fmt.Sprintf("failed to create mfa challenge: %s", err.Error()), zap.Error(err))
+		return
+	}
+
+	response.MFARequired = true
+	response.MFAEnrollmentRequired = !enabled
+	response.MFAToken = token
+	response.MFAExpiresAt = challenge.ExpiresAt.Format(time.RFC3339)
+	return response, true, nil
+}
+
+// mfaChallenge looks up a login challenge that can still be answered and the user it was issued to
+func (s *UserService) mfaChallenge(ctx context.Context, token string, now time.Time, tx *sqlx.Tx) (challenge model.UserToken, user model.User, err error) {
+	challenge, err = s.Repository.Auth.GetUserTokenByHash(ctx, HashOpaqueToken(token), pkg.USER_TOKEN_MFA_CHALLENGE, tx)
+	if err != nil {
+		s.Logger.Warnf(fmt.Sprintf("failed to get mfa challenge: %s", err.Error()), zap.Error(err))
+		return
+	}
+
+	if challenge.UsedAt != nil || !now.Before(challenge.ExpiresAt) || challenge.Attempts >= s.Config.Auth.MFAMaxAttempts {
+		err = pkg.NewError("MFA_CHALLENGE_EXPIRED", "the login expired, log in again", http.StatusUnauthorized, nil)
+		s.Logger.Warnf("mfa challenge of user %s is used, expired or out of attempts", challenge.UserID, zap.Error(err))
+		return
+	}
+
+	user, err = s.Repository.User.GetUserByID(ctx, challenge.UserID.String(), tx)
+	if err != nil {
+		s.Logger.Warnf(fmt.Sprintf("failed to get user by id: %s", err.Error()), zap.Error(err))
+		return
+	}
+	return
+}
+
+// enrollMFA replaces any enrollment of the user that was not confirmed with a new secret
+func (s *UserService) enrollMFA(ctx context.Context, user model.User, tx *sqlx.Tx) (response payload.MFAEnrollmentResponse, err error) {
+	mfa, err := s.Repository.Auth.GetUserMFAByUserIDForUpdate(ctx, user.ID.String(), tx)
+	if err != nil && !pkg.IsNotFound(err) {
+		s.Logger.Warnf(fmt.Sprintf("failed to get mfa of user: %s", err.Error()), zap.Error(err))
+		return
+	}
+	if err == nil && mfa.EnabledAt != nil {
+		err = pkg.NewBadRequestError("two-factor authentication is already enabled", nil)
+		s.Logger.Warnf("mfa of user %s is already enabled", user.ID, zap.Error(err))
+		return
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		s.Logger.Warnf(fmt.Sprintf("failed to generate totp secret: %s", err.Error()), zap.Error(err))
+		return
+	}
+	sealed, err := sealSecret(secret, s.Config.Application.Secret)
+	if err != nil {
+		s.Logger.Warnf(fmt.Sprintf("failed to seal totp secret: %s", err.Error()), zap.Error(err))
+		return
+	}
+
+	now := time.Now()
+	_, err = s.Repository.Auth.SaveUserMFA(ctx, model.UserMFA{
+		UserID:    user.ID,
+		Secret:    sealed,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}, tx)
+	if err != nil {
+		s.Logger.Warnf(fmt.Sprintf("failed to save mfa: %s", err.Error()), zap.Error(err))
+		return
+	}
+
+	response.Secret = secret
+	response.ProvisioningURI = totp.ProvisioningURI(s.Config.Auth.MFAIssuer, user.Email, secret)
+	return
+}
+
+// enableMFA turns the confirmed enrollment on and gives the user their recovery codes
+func (s *UserService) enableMFA(ctx context.Context, mfa *model.UserMFA, now time.Time, tx *sqlx.Tx) (recoveryCodes []string, err error) {
+	before := *mfa
+	mfa.EnabledAt = &now
+	saved, err := s.Repository.Auth.SaveUserMFA(ctx, *mfa, tx)
+	if err != nil {
+		s.Logger.Warnf(fmt.Sprintf("failed to save mfa: %s", err.Error()), zap.Error(err))
+		return
+	}
+	*mfa = saved
+
+	if recoveryCodes, err = s.replaceRecoveryCodes(ctx, mfa.UserID, tx); err != nil {
+		return
+	}
+
+	err = s.audit(ctx, tx, auditChange{
+		action:     pkg.AUDIT_ACTION_UPDATE,
+		entityType: pkg.AUDIT_ENTITY_MFA,
+		entityID:   mfa.UserID,
+		before:     before,
+		after:      *mfa,
+	})
+	return
+}
+
+// enabledMFA returns the current user with their enrollment, which must be turned on
+func (s *UserService) enabledMFA(ctx context.Context, tx *sqlx.Tx) (user model.User, mfa model.UserMFA, err error) {
+	if user, err = s.currentUser(ctx, tx); err != nil {
+		return
+	}
+
+	mfa, err = s.Repository.Auth.GetUserMFAByUserIDForUpdate(ctx, user.ID.String(), tx)
+	if err == nil && mfa.EnabledAt == nil {
+		err = pkg.NewNotFoundError("two-factor authentication is not set up", nil)
+	}
+	if err != nil {
+		s.Logger.Warnf(fmt.Sprintf("failed to get mfa of user: %s", err.Error()), zap.Error(err))
+		return
+	}
+	return
+}
+
+// checkSecondFactor reports whether the TOTP code or, once two-factor authentication is on, the recovery code is
+// valid. An accepted TOTP code moves the last used step of mfa, which the caller saves.
+func (s *UserService) checkSecondFactor(ctx context.Context, mfa *model.UserMFA, code string, recoveryCode string, now time.Time, tx *sqlx.Tx) (ok bool, err error) {
+	if code != "" {
+		if ok, err = s.checkTOTP(mfa, code, now); ok || err != nil {
+			return
+		}
+	}
+
+	if recoveryCode == "" || mfa.EnabledAt == nil {
+		return false, nil
+	}
+	ok, err = s.Repository.Auth.UseRecoveryCode(ctx, mfa.UserID.String(), HashOpaqueToken(normalizeRecoveryCode(recoveryCode)), now, tx)
+	if err != nil {
+		s.Logger.Warnf(fmt.Sprintf("failed to use recovery code: %s", err.Error()), zap.Error(err))
+		return
+	}
+	return
+}
+
+// checkTOTP reports whether the code is valid for the secret of mfa and newer than the last accepted one, so a
+// code that was seen once cannot be replayed
+func (s *UserService) checkTOTP(mfa *model.UserMFA, code string, now time.Time) (ok bool, err error) {
+	secret, err := openSecret(mfa.Secret, s.Config.Application.Secret)
+	if err != nil {
+		s.Logger.Errorf(fmt.Sprintf("failed to open totp secret of user %s: %s", mfa.UserID, err.Error()), zap.Error(err))
+		return
+	}
+
+	step, ok, err := totp.Validate(secret, code, now, mfaSkew)
+	if err != nil || !ok || step <= mfa.LastUsedStep {
+		return false, err
+	}
+	mfa.LastUsedStep = step
+	return true, nil
+}
+
+// requireTOTP fails with a bad request unless the code is valid
+func (s *UserService) requireTOTP(mfa *model.UserMFA, code string, now time.Time) error {
+	ok, err := s.checkTOTP(mfa, code, now)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		err = pkg.NewBadRequestError("invalid code", nil)
+		s.Logger.Warnf("invalid totp code for user: %s", mfa.UserID, zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// replaceRecoveryCodes stores new recovery codes for the user and returns them in the clear, the only time they
+// are shown
+func (s *UserService) replaceRecoveryCodes(ctx context.Context, userID uuid.UUID, tx *sqlx.Tx) (codes []string, err error) {
+	now := time.Now()
+	codes = make([]string, recoveryCodeCount)
+	rows := make([]model.RecoveryCode, recoveryCodeCount)
+	for i := range codes {
+		if codes[i], err = generateRecoveryCode(); err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to generate recovery code: %s", err.Error()), zap.Error(err))
+			return
+		}
+		rows[i] = model.RecoveryCode{
+			ID:        uuid.New(),
+			UserID:    userID,
+			CodeHash:  HashOpaqueToken(normalizeRecoveryCode(codes[i])),
+			CreatedAt: now,
+		}
+	}
+
+	if err = s.Repository.Auth.ReplaceRecoveryCodes(ctx, userID.String(), rows, tx); err != nil {
+		s.Logger.Warnf(fmt.Sprintf("failed to replace recovery codes: %s", err.Error()), zap.Error(err))
+		return
+	}
+	return
+}
+
+func (s *UserService) mfaRequired(user model.User) bool {
+	return slices.Contains(s.Config.Auth.MFARequiredRoles, user.Role)
+}
+
+// generateRecoveryCode returns 50 random bits as ten base32 characters in two groups, such as "k3j9d-x7q2m"
+func generateRecoveryCode() (string, error) {
+	buf := make([]byte, 7)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	code := strings.ToLower(base32.StdEncoding.EncodeToString(buf))[:10]
+	return code[:5] + "-" + code[5:], nil
+}
+
+// normalizeRecoveryCode lets users type a recovery code without the dash and in any case
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
+
+// sealSecret encrypts a TOTP secret with AES-GCM under a key derived from the application secret, a leaked
+// database alone does not give the codes away
+func sealSecret(secret string, appSecret string) (string, error) {
+	aead, err := secretCipher(appSecret)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(aead.Seal(nonce, nonce, []byte(secret), nil)), nil
+}
+
+func openSecret(sealed string, appSecret string) (string, error) {
+	aead, err := secretCipher(appSecret)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil {
+		return "", err
+	}
+	if len(data) < aead.NonceSize() {
+		return "", errors.New("sealed secret is too short")
+	}
+
+	secret, err := aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], nil)
+	if err != nil {
+		return "", err
+	}
+	return string(secret), nil
+}
+
+func secretCipher(appSecret string) (cipher.AEAD, error) {
+	key := sha256.Sum256([]byte("totp:" + appSecret))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
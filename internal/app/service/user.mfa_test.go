@@ -0,0 +1,90 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"edukita-teaching-grading/configs"
+	"edukita-teaching-grading/internal/app/model"
+	"edukita-teaching-grading/internal/pkg"
+	"edukita-teaching-grading/pkg/totp"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+func TestSealSecret(t *testing.T) {
+	sealed, err := sealSecret("JBSWY3DPEHPK3PXP", "app-secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(sealed, "JBSWY3DPEHPK3PXP") {
+		t.Fatal("the sealed secret holds the secret in the clear")
+	}
+
+	if secret, err := openSecret(sealed, "app-secret"); err != nil || secret != "JBSWY3DPEHPK3PXP" {
+		t.Errorf("opened %q, err %v", secret, err)
+	}
+	if _, err := openSecret(sealed, "another-secret"); err == nil {
+		t.Error("opened the secret with another application secret")
+	}
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	code, err := generateRecoveryCode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(code) != 11 || code[5] != '-' {
+		t.Errorf("unexpected code %q", code)
+	}
+
+	typed := strings.ToUpper(strings.ReplaceAll(code, "-", " "))
+	if normalizeRecoveryCode(typed) != normalizeRecoveryCode(code) {
+		t.Errorf("%q and %q are the same code", typed, code)
+	}
+}
+
+func TestCheckTOTPRefusesReplay(t *testing.T) {
+	s := &UserService{ServiceOption: ServiceOption{OptionsApplication: pkg.OptionsApplication{
+		Config: &configs.Config{Application: configs.Application{Secret: "app-secret"}},
+		Logger: zap.NewNop().Sugar(),
+	}}}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sealed, err := sealSecret(secret, "app-secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	mfa := model.UserMFA{UserID: uuid.New(), Secret: sealed}
+
+	now := time.Now()
+	code, err := totp.Code(secret, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ok, err := s.checkTOTP(&mfa, code, now); !ok || err != nil {
+		t.Fatalf("refused a valid code: %v", err)
+	}
+	if mfa.LastUsedStep != totp.Step(now) {
+		t.Errorf("last used step %d, want %d", mfa.LastUsedStep, totp.Step(now))
+	}
+	if ok, _ := s.checkTOTP(&mfa, code, now); ok {
+		t.Error("accepted the same code twice")
+	}
+}
+
+func TestMFARequired(t *testing.T) {
+	s := &UserService{ServiceOption: ServiceOption{OptionsApplication: pkg.OptionsApplication{
+		Config: &configs.Config{Auth: configs.Auth{MFARequiredRoles: []string{pkg.ROLE_ADMIN, pkg.ROLE_TEACHER}}},
+	}}}
+
+	if !s.mfaRequired(model.User{Role: pkg.ROLE_TEACHER}) || s.mfaRequired(model.User{Role: pkg.ROLE_STUDENT}) {
+		t.Error("only admins and teachers must use two-factor authentication")
+	}
+}
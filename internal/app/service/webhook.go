@@ -0,0 +1,471 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+
+	"edukita-teaching-grading/internal/app/event"
+	"edukita-teaching-grading/internal/app/model"
+	"edukita-teaching-grading/internal/app/payload"
+	"edukita-teaching-grading/internal/app/policy"
+	"edukita-teaching-grading/internal/app/repository"
+	"edukita-teaching-grading/internal/pkg"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+type (
+	IWebhookService interface {
+		CreateWebhook(ctx context.Context, requestBody *payload.CreateWebhookRequest) (response payload.WebhookResponse, err error)
+		GetAllWebhooks(ctx context.Context, query pkg.ListQuery) (response payload.GetAllWebhooksResponse, meta payload.MetaResponse, err error)
+		GetWebhookByID(ctx context.Context, id string) (response payload.WebhookResponse, err error)
+		UpdateWebhookByID(ctx context.Context, id string, requestBody *payload.UpdateWebhookRequest) (response payload.WebhookResponse, err error)
+		DeleteWebhookByID(ctx context.Context, id string) (response payload.WebhookResponse, err error)
+		RotateWebhookSecret(ctx context.Context, id string) (response payload.WebhookResponse, err error)
+		GetAllWebhookDeliveries(ctx context.Context, id string, query pkg.ListQuery) (response payload.GetAllWebhookDeliveriesResponse, meta payload.MetaResponse, err error)
+		GetWebhookDeliveryByID(ctx context.Context, deliveryID string) (response payload.WebhookDeliveryResponse, err error)
+		RedeliverWebhookDelivery(ctx context.Context, deliveryID string) (response payload.WebhookDeliveryResponse, err error)
+	}
+	WebhookService struct {
+		ServiceOption
+	}
+)
+
+func InitiateWebhookService(opt ServiceOption) IWebhookService {
+	return &WebhookService{
+		ServiceOption: opt,
+	}
+}
+
+// webhookSecretPrefix marks webhook secrets, so they are recognised when they leak into logs or code
+const webhookSecretPrefix = "whsec_"
+
+func (s *WebhookService) CreateWebhook(ctx context.Context, requestBody *payload.CreateWebhookRequest) (response payload.WebhookResponse, err error) {
+	return response, s.Repository.Tx.Run(ctx, repository.TxReadWrite, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+		user, err := s.currentUser(ctx, tx)
+		if err != nil {
+			return
+		}
+
+		if err = s.authorize(user, policy.WebhookManage, policy.Resource{}); err != nil {
+			return
+		}
+
+		eventTypes, err := s.checkWebhook(requestBody.URL, requestBody.EventTypes)
+		if err != nil {
+			return
+		}
+
+		secret, _, err := GenerateOpaqueToken()
+		if err != nil {
+			s.Logger.Errorf(fmt.Sprintf("failed to generate webhook secret: %s", err.Error()), zap.Error(err))
+			return
+		}
+
+		webhook := model.WebhookSubscription{
+			BaseModel: model.BaseModel{
+				ID:        uuid.New(),
+				CreatedBy: user.ID,
+				CreatedAt: time.Now(),
+			},
+			URL:         requestBody.URL,
+			Description: requestBody.Description,
+			Secret:      webhookSecretPrefix + secret,
+			EventTypes:  eventTypes,
+			IsActive:    true,
+		}
+
+		webhook, err = s.Repository.Webhook.CreateWebhookSubscription(ctx, webhook, tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to create webhook: %s", err.Error()), zap.Error(err))
+			return
+		}
+
+		if err = s.audit(ctx, tx, auditChange{
+			action:     pkg.AUDIT_ACTION_CREATE,
+			entityType: pkg.AUDIT_ENTITY_WEBHOOK,
+			entityID:   webhook.ID,
+			after:      webhook,
+		}); err != nil {
+			return
+		}
+
+		response = webhookToResponse(webhook)
+		response.Secret = webhook.Secret
+		return
+	})
+}
+
+func (s *WebhookService) GetAllWebhooks(ctx context.Context, query pkg.ListQuery) (response payload.GetAllWebhooksResponse, meta payload.MetaResponse, err error) {
+	return response, meta, s.Repository.Tx.Run(ctx, repository.TxReadOnly, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+		user, err := s.currentUser(ctx, tx)
+		if err != nil {
+			return
+		}
+
+		if err = s.authorize(user, policy.WebhookManage, policy.Resource{}); err != nil {
+			return
+		}
+
+		webhooks, page, err := s.Repository.Webhook.ListWebhookSubscriptions(ctx, query, tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to get webhooks: %s", err.Error()), zap.Error(err))
+			return
+		}
+		meta = pageToMeta(page)
+
+		response.Webhooks = make([]payload.WebhookResponse, len(webhooks))
+		for i, webhook := range webhooks {
+			response.Webhooks[i] = webhookToResponse(webhook)
+		}
+		return
+	})
+}
+
+func (s *WebhookService) GetWebhookByID(ctx context.Context, id string) (response payload.WebhookResponse, err error) {
+	return response, s.Repository.Tx.Run(ctx, repository.TxReadOnly, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+		user, err := s.currentUser(ctx, tx)
+		if err != nil {
+			return
+		}
+
+		webhook, err := s.managedWebhook(ctx, id, user, tx)
+		if err != nil {
+			return
+		}
+
+		response = webhookToResponse(webhook)
+		return
+	})
+}
+
+func (s *WebhookService) UpdateWebhookByID(ctx context.Context, id string, requestBody *payload.UpdateWebhookRequest) (response payload.WebhookResponse, err error) {
+	return response, s.Repository.Tx.Run(ctx, repository.TxReadWrite, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+		user, err := s.currentUser(ctx, tx)
+		if err != nil {
+			return
+		}
+
+		webhook, err := s.managedWebhook(ctx, id, user, tx)
+		if err != nil {
+			return
+		}
+		before := webhook
+
+		eventTypes, err := s.checkWebhook(requestBody.URL, requestBody.EventTypes)
+		if err != nil {
+			return
+		}
+
+		now := time.Now()
+		webhook.URL = requestBody.URL
+		webhook.Description = requestBody.Description
+		webhook.EventTypes = eventTypes
+		webhook.IsActive = *requestBody.IsActive
+		webhook.UpdatedBy = &user.ID
+		webhook.UpdatedAt = &now
+
+		webhook, err = s.Repository.Webhook.UpdateWebhookSubscriptionByID(ctx, webhook, tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to update webhook: %s", err.Error()), zap.Error(err))
+			return
+		}
+
+		if err = s.audit(ctx, tx, auditChange{
+			action:     pkg.AUDIT_ACTION_UPDATE,
+			entityType: pkg.AUDIT_ENTITY_WEBHOOK,
+			entityID:   webhook.ID,
+			before:     before,
+			after:      webhook,
+		}); err != nil {
+			return
+		}
+
+		response = webhookToResponse(webhook)
+		return
+	})
+}
+
+func (s *WebhookService) DeleteWebhookByID(ctx context.Context, id string) (response payload.WebhookResponse, err error) {
+	return response, s.Repository.Tx.Run(ctx, repository.TxReadWrite, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+		user, err := s.currentUser(ctx, tx)
+		if err != nil {
+			return
+		}
+
+		webhook, err := s.managedWebhook(ctx, id, user, tx)
+		if err != nil {
+			return
+		}
+
+		before := webhook
+		webhook, err = s.Repository.Webhook.DeleteWebhookSubscriptionByID(ctx, webhook.ID.String(), user.ID, time.Now(), tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to delete webhook: %s", err.Error()), zap.Error(err))
+			return
+		}
+
+		if err = s.audit(ctx, tx, auditChange{
+			action:     pkg.AUDIT_ACTION_DELETE,
+			entityType: pkg.AUDIT_ENTITY_WEBHOOK,
+			entityID:   webhook.ID,
+			before:     before,
+			after:      webhook,
+		}); err != nil {
+			return
+		}
+
+		response = webhookToResponse(webhook)
+		return
+	})
+}
+
+// RotateWebhookSecret replaces the secret of the webhook, requests are signed with the new secret from then on,
+// including the retries of deliveries that already failed
+func (s *WebhookService) RotateWebhookSecret(ctx context.Context, id string) (response payload.WebhookResponse, err error) {
+	return response, s.Repository.Tx.Run(ctx, repository.TxReadWrite, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+		user, err := s.currentUser(ctx, tx)
+		if err != nil {
+			return
+		}
+
+		webhook, err := s.managedWebhook(ctx, id, user, tx)
+		if err != nil {
+			return
+		}
+		before := webhook
+
+		secret, _, err := GenerateOpaqueToken()
+		if err != nil {
+			s.Logger.Errorf(fmt.Sprintf("failed to generate webhook secret: %s", err.Error()), zap.Error(err))
+			return
+		}
+
+		now := time.Now()
+		webhook.Secret = webhookSecretPrefix + secret
+		webhook.UpdatedBy = &user.ID
+		webhook.UpdatedAt = &now
+
+		webhook, err = s.Repository.Webhook.UpdateWebhookSubscriptionByID(ctx, webhook, tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to update webhook: %s", err.Error()), zap.Error(err))
+			return
+		}
+
+		// the secret itself is never written to the audit log, the update still records who rotated it
+		if err = s.audit(ctx, tx, auditChange{
+			action:     pkg.AUDIT_ACTION_UPDATE,
+			entityType: pkg.AUDIT_ENTITY_WEBHOOK,
+			entityID:   webhook.ID,
+			before:     before,
+			after:      webhook,
+		}); err != nil {
+			return
+		}
+
+		response = webhookToResponse(webhook)
+		response.Secret = webhook.Secret
+		return
+	})
+}
+
+func (s *WebhookService) GetAllWebhookDeliveries(ctx context.Context, id string, query pkg.ListQuery) (response payload.GetAllWebhookDeliveriesResponse, meta payload.MetaResponse, err error) {
+	return response, meta, s.Repository.Tx.Run(ctx, repository.TxReadOnly, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+		user, err := s.currentUser(ctx, tx)
+		if err != nil {
+			return
+		}
+
+		webhook, err := s.managedWebhook(ctx, id, user, tx)
+		if err != nil {
+			return
+		}
+
+		deliveries, page, err := s.Repository.Webhook.ListWebhookDeliveriesBySubscriptionID(ctx, webhook.ID.String(), query, tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to get webhook deliveries: %s", err.Error()), zap.Error(err))
+			return
+		}
+		meta = pageToMeta(page)
+
+		response.Deliveries = make([]payload.WebhookDeliveryResponse, len(deliveries))
+		for i, delivery := range deliveries {
+			response.Deliveries[i] = webhookDeliveryToResponse(delivery)
+		}
+		return
+	})
+}
+
+func (s *WebhookService) GetWebhookDeliveryByID(ctx context.Context, deliveryID string) (response payload.WebhookDeliveryResponse, err error) {
+	return response, s.Repository.Tx.Run(ctx, repository.TxReadOnly, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+		user, err := s.currentUser(ctx, tx)
+		if err != nil {
+			return
+		}
+
+		delivery, err := s.managedWebhookDelivery(ctx, deliveryID, user, tx)
+		if err != nil {
+			return
+		}
+
+		attempts, err := s.Repository.Webhook.GetAllWebhookAttemptsByDeliveryID(ctx, delivery.ID.String(), tx)
+		if err != nil {
+			s.Logger.Warnf(fmt.Sprintf("failed to get webhook attempts: %s", err.Error()), zap.Error(err))
+			return
+		}
+
+		response = webhookDeliveryToResponse(delivery)
+		response.History = make([]payload.WebhookAttemptResponse, len(attempts))
+		for i, attempt := range attempts {
+			response.History[i] = webhookAttemptToResponse(attempt)
+		}
+		return
+	})
+}
+
+// RedeliverWebhookDelivery queues the delivery to be sent again right away with a fresh set of attempts, a
+// delivery that is still pending is left to its job. Receivers recognise the repeat by the unchanged delivery and
+// event ids.
+func (s *WebhookService) RedeliverWebhookDelivery(ctx context.Context, deliveryID string) (response payload.WebhookDeliveryResponse, err error) {
+	return response, s.Repository.Tx.Run(ctx, repository.TxReadWrite, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+		user, err := s.currentUser(ctx, tx)
+		if err != nil {
+			return
+		}
+
+		delivery, err := s.managedWebhookDelivery(ctx, deliveryID, user, tx)
+		if err != nil {
+			return
+		}
+
+		// a pending delivery is already retried by its job
+		if delivery.Status != pkg.WEBHOOK_DELIVERY_PENDING {
+			delivery.Status = pkg.WEBHOOK_DELIVERY_PENDING
+			delivery.Attempts = 0
+			delivery.NextAttemptAt = time.Now()
+
+			delivery, err = s.Repository.Webhook.UpdateWebhookDelivery(ctx, delivery, tx)
+			if err != nil {
+				s.Logger.Warnf(fmt.Sprintf("failed to update webhook delivery: %s", err.Error()), zap.Error(err))
+				return
+			}
+			if err = event.EnqueueDelivery(ctx, s.Jobs, delivery); err != nil {
+				s.Logger.Warnf(fmt.Sprintf("failed to queue webhook delivery: %s", err.Error()), zap.Error(err))
+				return
+			}
+		}
+
+		response = webhookDeliveryToResponse(delivery)
+		return
+	})
+}
+
+// managedWebhook loads the webhook after checking that the user manages webhooks
+func (s *WebhookService) managedWebhook(ctx context.Context, id string, user model.User, tx *sqlx.Tx) (webhook model.WebhookSubscription, err error) {
+	if err = s.authorize(user, policy.WebhookManage, policy.Resource{}); err != nil {
+		return
+	}
+
+	webhook, err = s.Repository.Webhook.GetWebhookSubscriptionByID(ctx, id, tx)
+	if err != nil {
+		s.Logger.Warnf(fmt.Sprintf("failed to get webhook by id: %s", err.Error()), zap.Error(err))
+		return
+	}
+	return
+}
+
+func (s *WebhookService) managedWebhookDelivery(ctx context.Context, deliveryID string, user model.User, tx *sqlx.Tx) (delivery model.WebhookDelivery, err error) {
+	if err = s.authorize(user, policy.WebhookManage, policy.Resource{}); err != nil {
+		return
+	}
+
+	delivery, err = s.Repository.Webhook.GetWebhookDeliveryByID(ctx, deliveryID, tx)
+	if err != nil {
+		s.Logger.Warnf(fmt.Sprintf("failed to get webhook delivery by id: %s", err.Error()), zap.Error(err))
+		return
+	}
+
+	// the deliveries of a deleted webhook are kept but no longer served or sent
+	if _, err = s.Repository.Webhook.GetWebhookSubscriptionByID(ctx, delivery.SubscriptionID.String(), tx); err != nil {
+		s.Logger.Warnf(fmt.Sprintf("failed to get webhook by id: %s", err.Error()), zap.Error(err))
+		return
+	}
+	return
+}
+
+// checkWebhook validates the url and event types of a webhook and returns the event types encoded for storage
+func (s *WebhookService) checkWebhook(rawURL string, eventTypes []string) (string, error) {
+	target, err := url.Parse(rawURL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		err = pkg.NewBadRequestError("url must be an absolute http or https url", err)
+		s.Logger.Warnf(fmt.Sprintf("invalid webhook url: %s", rawURL), zap.Error(err))
+		return "", err
+	}
+
+	if eventTypes == nil {
+		eventTypes = []string{}
+	}
+	for _, eventType := range eventTypes {
+		if !event.Type(eventType).Known() {
+			err = pkg.NewBadRequestError(fmt.Sprintf("unknown event type %s", eventType), nil)
+			s.Logger.Warnf(fmt.Sprintf("invalid webhook event type: %s", eventType), zap.Error(err))
+			return "", err
+		}
+	}
+
+	encoded, err := json.Marshal(eventTypes)
+	if err != nil {
+		return "", err
+	}
+	return string(encoded), nil
+}
+
+func webhookToResponse(webhook model.WebhookSubscription) (response payload.WebhookResponse) {
+	response.ID = webhook.ID.String()
+	response.URL = webhook.URL
+	response.Description = webhook.Description
+	response.EventTypes = []string{}
+	_ = json.Unmarshal([]byte(webhook.EventTypes), &response.EventTypes)
+	response.IsActive = webhook.IsActive
+	response.CreatedAt = webhook.CreatedAt.Format(time.RFC3339)
+	response.CreatedBy = webhook.CreatedBy.String()
+	if webhook.UpdatedAt != nil {
+		updatedAt := webhook.UpdatedAt.Format(time.RFC3339)
+		response.UpdatedAt = &updatedAt
+	}
+	return
+}
+
+func webhookDeliveryToResponse(delivery model.WebhookDelivery) (response payload.WebhookDeliveryResponse) {
+	response.ID = delivery.ID.String()
+	response.WebhookID = delivery.SubscriptionID.String()
+	response.EventID = delivery.EventID.String()
+	response.EventType = delivery.EventType
+	response.Payload = json.RawMessage(delivery.Payload)
+	response.Status = delivery.Status
+	response.Attempts = delivery.Attempts
+	response.NextAttemptAt = delivery.NextAttemptAt.Format(time.RFC3339)
+	response.LastStatusCode = delivery.LastStatusCode
+	response.LastError = delivery.LastError
+	if delivery.DeliveredAt != nil {
+		deliveredAt := delivery.DeliveredAt.Format(time.RFC3339)
+		response.DeliveredAt = &deliveredAt
+	}
+	response.CreatedAt = delivery.CreatedAt.Format(time.RFC3339)
+	return
+}
+
+func webhookAttemptToResponse(attempt model.WebhookAttempt) (response payload.WebhookAttemptResponse) {
+	response.Attempt = attempt.Attempt
+	response.StatusCode = attempt.StatusCode
+	response.ResponseBody = attempt.ResponseBody
+	response.Error = attempt.Error
+	response.DurationMS = attempt.DurationMS
+	response.AttemptedAt = attempt.AttemptedAt.Format(time.RFC3339)
+	return
+}
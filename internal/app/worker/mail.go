@@ -0,0 +1,172 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"edukita-teaching-grading/internal/app/model"
+	"edukita-teaching-grading/internal/app/repository"
+	"edukita-teaching-grading/internal/pkg"
+	"edukita-teaching-grading/pkg/mailer"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// SendEmailPayload is the payload of a send_email job
+type SendEmailPayload struct {
+	EmailID uuid.UUID `json:"email_id"`
+}
+
+type MailerOption struct {
+	pkg.OptionsApplication
+	Repository *repository.Repository
+	Templates  *mailer.Templates
+	Sender     mailer.Sender
+}
+
+// Mailer sends the emails queued as send_email jobs, no faster than the configured rate
+type Mailer struct {
+	MailerOption
+	limiter *mailer.Limiter
+}
+
+func NewMailer(opt MailerOption) *Mailer {
+	return &Mailer{
+		MailerOption: opt,
+		limiter:      mailer.NewLimiter(opt.Config.Mail.RatePerMinute, opt.Config.Mail.RateBurst),
+	}
+}
+
+// Register adds the handler of send_email jobs to the queue
+func (m *Mailer) Register(q *JobQueue) {
+	Handle(q, pkg.JOB_TYPE_SEND_EMAIL, m.Send)
+	Finish(q, pkg.JOB_TYPE_SEND_EMAIL, m.Finish)
+}
+
+// EnqueueEmails queues a send_email job for each email, in the transaction carried by ctx
+func EnqueueEmails(ctx context.Context, q *JobQueue, emails []model.EmailMessage) error {
+	for _, email := range emails {
+		if _, err := q.Enqueue(ctx, pkg.JOB_TYPE_SEND_EMAIL, SendEmailPayload{EmailID: email.ID}, EnqueueOptions{
+			MaxAttempts: q.Config.Mail.MaxAttempts,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Send is the handler of send_email jobs, an email that is no longer pending was sent by an earlier run
+func (m *Mailer) Send(ctx context.Context, job model.Job, payload SendEmailPayload) error {
+	var (
+		email     model.EmailMessage
+		userToken *model.UserToken
+	)
+	err := m.Repository.Tx.Run(ctx, repository.TxReadOnly, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+		email, err = m.Repository.Email.GetEmailMessageByID(ctx, payload.EmailID.String(), tx)
+		if err != nil || email.UserTokenID == nil {
+			return
+		}
+		token, err := m.Repository.Auth.GetUserTokenByID(ctx, email.UserTokenID.String(), tx)
+		userToken = &token
+		return
+	})
+	if err != nil {
+		if pkg.IsNotFound(err) {
+			return PermanentJobError(err)
+		}
+		return err
+	}
+	if email.Status != pkg.EMAIL_PENDING {
+		return nil
+	}
+
+	msg, err := m.message(email, userToken, time.Now())
+	if err != nil {
+		return PermanentJobError(err)
+	}
+	return m.send(ctx, msg)
+}
+
+// message renders the email, the link of a reset or verification email gets its token back. A link whose token was
+// used, replaced by a newer one or expired before the email went out is not sent.
+func (m *Mailer) message(email model.EmailMessage, userToken *model.UserToken, now time.Time) (msg mailer.Message, err error) {
+	data, err := mailer.Data(email.Template)
+	if err != nil {
+		return
+	}
+	if err = json.Unmarshal([]byte(email.Data), data); err != nil {
+		return msg, fmt.Errorf("decoding the data of the email: %w", err)
+	}
+	if tokenData, ok := data.(mailer.TokenData); ok {
+		if userToken == nil {
+			return msg, errors.New("the email has no token for its link")
+		}
+		if userToken.UsedAt != nil || !now.Before(userToken.ExpiresAt) {
+			return msg, errors.New("the token of the link was used or expired before the email was sent")
+		}
+		tokenData.SetToken(userToken.Mailed(m.Config.Application.Secret))
+	}
+
+	if msg, err = m.Templates.Render(email.Template, data); err != nil {
+		return
+	}
+	msg.ID = email.ID.String()
+	msg.To = email.ToAddress
+	return
+}
+
+// send hands the message to the sender at the rate limit, a message the server rejected for good fails the job at once
+func (m *Mailer) send(ctx context.Context, msg mailer.Message) error {
+	if err := m.limiter.Wait(ctx); err != nil {
+		return err
+	}
+
+	err := m.Sender.Send(ctx, msg)
+	if mailer.IsPermanent(err) {
+		return PermanentJobError(err)
+	}
+	return err
+}
+
+// Finish saves the outcome of the run on the email
+func (m *Mailer) Finish(ctx context.Context, job model.Job, payload SendEmailPayload, tx *sqlx.Tx) error {
+	email, err := m.Repository.Email.GetEmailMessageByID(ctx, payload.EmailID.String(), tx)
+	if pkg.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if email.Status != pkg.EMAIL_PENDING {
+		return nil
+	}
+
+	if _, err = m.Repository.Email.UpdateEmailMessage(ctx, finishedEmail(email, job), tx); err != nil {
+		m.Logger.Errorf(fmt.Sprintf("failed to save email %s: %s", email.ID, err.Error()), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// finishedEmail returns the email with the state of the job that sends it: sent when the job succeeded, dead when
+// it failed for good and pending while it is retried
+func finishedEmail(email model.EmailMessage, job model.Job) model.EmailMessage {
+	email.Attempts = job.Attempts
+	email.LastError = job.LastError
+	email.NextAttemptAt = job.RunAt
+	switch job.Status {
+	case pkg.JOB_STATUS_SUCCEEDED:
+		email.Status = pkg.EMAIL_SENT
+		email.SentAt = job.FinishedAt
+	case pkg.JOB_STATUS_FAILED:
+		email.Status = pkg.EMAIL_DEAD
+	default:
+		email.Status = pkg.EMAIL_PENDING
+	}
+	return email
+}
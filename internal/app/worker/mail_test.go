@@ -0,0 +1,130 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+
+	"edukita-teaching-grading/configs"
+	"edukita-teaching-grading/internal/app/model"
+	"edukita-teaching-grading/internal/pkg"
+	"edukita-teaching-grading/pkg/mailer"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// fakeSender records the messages it is given and fails with err
+type fakeSender struct {
+	sent []mailer.Message
+	err  error
+}
+
+func (s *fakeSender) Send(ctx context.Context, msg mailer.Message) error {
+	s.sent = append(s.sent, msg)
+	return s.err
+}
+
+func newTestMailer(t *testing.T, sender mailer.Sender) *Mailer {
+	t.Helper()
+
+	templates, err := mailer.NewTemplates("Edukita LMS", "https://lms.edukita.test")
+	if err != nil {
+		t.Fatalf("parsing templates: %v", err)
+	}
+	return NewMailer(MailerOption{
+		OptionsApplication: pkg.OptionsApplication{
+			Config: &configs.Config{
+				Application: configs.Application{Secret: "secret"},
+				Mail:        configs.Mail{RatePerMinute: 60, RateBurst: 1},
+			},
+			Logger: zap.NewNop().Sugar(),
+		},
+		Templates: templates,
+		Sender:    sender,
+	})
+}
+
+func TestMailerMessage(t *testing.T) {
+	now := time.Now()
+	usedAt := now.Add(-time.Minute)
+	token := model.UserToken{ID: uuid.New(), Purpose: pkg.USER_TOKEN_PASSWORD_RESET, ExpiresAt: now.Add(time.Hour)}
+	used := token
+	used.UsedAt = &usedAt
+	expired := token
+	expired.ExpiresAt = now
+
+	reset := model.EmailMessage{
+		ID:          uuid.New(),
+		ToAddress:   "siti@example.com",
+		Template:    mailer.TemplatePasswordReset,
+		Data:        `{"FirstName":"Siti","ExpiresInMinutes":30}`,
+		UserTokenID: &token.ID,
+	}
+	welcome := model.EmailMessage{ID: uuid.New(), ToAddress: "siti@example.com", Template: mailer.TemplateWelcome, Data: `{"FirstName":"Siti"}`}
+	unknown := welcome
+	unknown.Template = "unknown"
+	malformed := welcome
+	malformed.Data = `{"FirstName":`
+
+	tests := []struct {
+		name      string
+		email     model.EmailMessage
+		userToken *model.UserToken
+		wantText  string
+		wantErr   bool
+	}{
+		{"without a link", welcome, nil, "Hi Siti", false},
+		{"with a link", reset, &token, "reset-password?token=" + token.Mailed("secret"), false},
+		{"token missing", reset, nil, "", true},
+		{"token used", reset, &used, "", true},
+		{"token expired", reset, &expired, "", true},
+		{"unknown template", unknown, nil, "", true},
+		{"malformed data", malformed, nil, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, err := newTestMailer(t, &fakeSender{}).message(tt.email, tt.userToken, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("message() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if msg.ID != tt.email.ID.String() || msg.To != tt.email.ToAddress || !strings.Contains(msg.Text, tt.wantText) {
+				t.Errorf("message() = %+v, want a text with %q", msg, tt.wantText)
+			}
+		})
+	}
+}
+
+func TestMailerSend(t *testing.T) {
+	msg := mailer.Message{ID: uuid.NewString(), To: "siti@example.com", Subject: "Welcome", HTML: "<p>Hi</p>", Text: "Hi"}
+
+	tests := []struct {
+		name          string
+		err           error
+		wantErr       bool
+		wantPermanent bool
+	}{
+		{"sent", nil, false, false},
+		{"temporary failure", errors.New("connection refused"), true, false},
+		{"rejected", &mailer.PermanentError{Err: &textproto.Error{Code: 550, Msg: "no such user"}}, true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sender := &fakeSender{err: tt.err}
+			err := newTestMailer(t, sender).send(context.Background(), msg)
+			var permanent *permanentJobError
+			if (err != nil) != tt.wantErr || errors.As(err, &permanent) != tt.wantPermanent {
+				t.Errorf("send() error = %v, wantErr %v, permanent %v", err, tt.wantErr, tt.wantPermanent)
+			}
+			if len(sender.sent) != 1 || sender.sent[0] != msg {
+				t.Errorf("sent %+v", sender.sent)
+			}
+		})
+	}
+}
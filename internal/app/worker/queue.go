@@ -0,0 +1,266 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"edukita-teaching-grading/internal/app/model"
+	"edukita-teaching-grading/internal/app/repository"
+	"edukita-teaching-grading/internal/pkg"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// JobHandler runs one job, a returned error fails the attempt
+type JobHandler func(ctx context.Context, job model.Job) error
+
+// JobFinisher saves the outcome of a run of the job on the record it works for
+type JobFinisher func(ctx context.Context, job model.Job, tx *sqlx.Tx) error
+
+// permanentJobError is a failure that retrying cannot fix, such as a payload that does not decode
+type permanentJobError struct {
+	err error
+}
+
+func (e *permanentJobError) Error() string { return e.err.Error() }
+func (e *permanentJobError) Unwrap() error { return e.err }
+
+// PermanentJobError wraps an error returned by a handler to fail the job at once instead of retrying it
+func PermanentJobError(err error) error {
+	return &permanentJobError{err: err}
+}
+
+type JobQueueOption struct {
+	pkg.OptionsApplication
+	Repository *repository.Repository
+}
+
+// JobQueue runs the jobs of the jobs table on a pool of workers, with the handler registered for their type
+type JobQueue struct {
+	JobQueueOption
+	handlers  map[string]JobHandler
+	finishers map[string]JobFinisher
+	instance  string
+}
+
+func NewJobQueue(opt JobQueueOption) *JobQueue {
+	instance, err := os.Hostname()
+	if err != nil {
+		instance = "unknown"
+	}
+	return &JobQueue{
+		JobQueueOption: opt,
+		handlers:       make(map[string]JobHandler),
+		finishers:      make(map[string]JobFinisher),
+		instance:       fmt.Sprintf("%s/%d", instance, os.Getpid()),
+	}
+}
+
+// Handle registers the handler of a job type, the payload of each job is decoded into T before fn runs. The job
+// tells fn which attempt it is. It must be called before Run.
+func Handle[T any](q *JobQueue, jobType string, fn func(ctx context.Context, job model.Job, payload T) error) {
+	q.handlers[jobType] = func(ctx context.Context, job model.Job) error {
+		var payload T
+		if err := json.Unmarshal([]byte(job.Payload), &payload); err != nil {
+			return PermanentJobError(fmt.Errorf("decoding payload of %s job: %w", jobType, err))
+		}
+		return fn(ctx, job, payload)
+	}
+}
+
+// Finish registers fn to save the outcome of every run of a job type on the record the job works for, such as an
+// email or a webhook delivery. fn gets the job as it is saved, retried or finished, and runs in the transaction
+// that saves it, so the record always follows the job. It must be called before Run.
+func Finish[T any](q *JobQueue, jobType string, fn func(ctx context.Context, job model.Job, payload T, tx *sqlx.Tx) error) {
+	q.finishers[jobType] = func(ctx context.Context, job model.Job, tx *sqlx.Tx) error {
+		var payload T
+		if err := json.Unmarshal([]byte(job.Payload), &payload); err != nil {
+			// the run already failed for good on the payload, there is no record to save it on
+			return nil
+		}
+		return fn(ctx, job, payload, tx)
+	}
+}
+
+// EnqueueOptions tune a job, the zero value runs it now at priority 0 with the configured attempts
+type EnqueueOptions struct {
+	// Priority orders runnable jobs, higher first
+	Priority int
+	// RunAt delays the job until then
+	RunAt       time.Time
+	MaxAttempts int
+}
+
+// Enqueue adds a job of a registered type. It joins the transaction carried by ctx, so a job enqueued by a change
+// only runs once the change commits.
+func (q *JobQueue) Enqueue(ctx context.Context, jobType string, payload any, opts EnqueueOptions) (job model.Job, err error) {
+	if _, ok := q.handlers[jobType]; !ok {
+		return job, fmt.Errorf("no handler registered for job type %s", jobType)
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return job, fmt.Errorf("encoding payload of %s job: %w", jobType, err)
+	}
+
+	now := time.Now()
+	job = model.Job{
+		ID:          uuid.New(),
+		Type:        jobType,
+		Payload:     string(data),
+		Priority:    opts.Priority,
+		Status:      pkg.JOB_STATUS_PENDING,
+		MaxAttempts: opts.MaxAttempts,
+		RunAt:       opts.RunAt,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if job.MaxAttempts <= 0 {
+		job.MaxAttempts = q.Config.Jobs.MaxAttempts
+	}
+	if job.RunAt.IsZero() {
+		job.RunAt = now
+	}
+
+	err = q.Repository.Tx.Run(ctx, repository.TxReadWrite, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+		job, err = q.Repository.Job.CreateJob(ctx, job, tx)
+		return
+	})
+	return
+}
+
+// Run starts the workers and blocks until ctx is cancelled and the pool drained. Once ctx is cancelled no job is
+// claimed anymore, and the running ones get the shutdown timeout to finish before their context is cancelled too.
+func (q *JobQueue) Run(ctx context.Context) {
+	drain, cancelDrain := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancelDrain()
+	stop := context.AfterFunc(ctx, func() {
+		time.AfterFunc(q.Config.Jobs.ShutdownTimeout, cancelDrain)
+	})
+	defer stop()
+
+	var wg sync.WaitGroup
+	for i := 0; i < q.Config.Jobs.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			q.work(ctx, drain, fmt.Sprintf("%s/%d", q.instance, i))
+		}()
+	}
+	wg.Wait()
+}
+
+// work runs jobs one after another, waiting for the poll interval whenever the queue is empty
+func (q *JobQueue) work(ctx context.Context, drain context.Context, workerID string) {
+	ticker := time.NewTicker(q.Config.Jobs.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		for ctx.Err() == nil {
+			ran, err := q.RunNext(drain, workerID)
+			if err != nil {
+				q.Logger.Errorf(fmt.Sprintf("failed to run job: %s", err.Error()), zap.Error(err))
+				break
+			}
+			if !ran {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunNext claims the next runnable job for the worker and runs it, ran is false when there was none
+func (q *JobQueue) RunNext(ctx context.Context, workerID string) (ran bool, err error) {
+	var jobs []model.Job
+	err = q.Repository.Tx.Run(ctx, repository.TxReadWrite, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+		now := time.Now()
+		jobs, err = q.Repository.Job.ClaimJobs(ctx, now, now.Add(q.Config.Jobs.VisibilityTimeout), workerID, 1, tx)
+		return
+	})
+	if err != nil || len(jobs) == 0 {
+		return false, err
+	}
+
+	job := jobs[0]
+	runErr := q.run(ctx, job)
+	job = q.attempt(job, runErr, time.Now())
+
+	// the outcome is saved even when the shutdown timeout cut the run off
+	err = q.Repository.Tx.Run(context.WithoutCancel(ctx), repository.TxReadWrite, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+		if _, err = q.Repository.Job.FinishJob(ctx, job, workerID, tx); err != nil {
+			return
+		}
+		if finish, ok := q.finishers[job.Type]; ok {
+			err = finish(ctx, job, tx)
+		}
+		return
+	})
+	if pkg.IsNotFound(err) {
+		q.Logger.Warnf("job %s (%s) outlived its visibility timeout and was claimed by another worker", job.ID, job.Type)
+		return true, nil
+	}
+	return true, err
+}
+
+// run calls the handler of the job within the visibility timeout, a panic fails the attempt
+func (q *JobQueue) run(ctx context.Context, job model.Job) (err error) {
+	// a job claimed again after its visibility timeout passed has used its attempts up by crashing or stalling
+	if job.Attempts > job.MaxAttempts {
+		return PermanentJobError(errors.New("the visibility timeout passed on the last attempt"))
+	}
+
+	handler, ok := q.handlers[job.Type]
+	if !ok {
+		return PermanentJobError(fmt.Errorf("no handler registered for job type %s", job.Type))
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, q.Config.Jobs.VisibilityTimeout)
+	defer cancel()
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("panic in job handler: %v", p)
+		}
+	}()
+	return handler(ctx, job)
+}
+
+// attempt returns the job updated with the outcome of a run. Failed jobs are retried with a growing backoff and
+// fail for good once they run out of attempts or the error is permanent.
+func (q *JobQueue) attempt(job model.Job, runErr error, now time.Time) model.Job {
+	job.LockedUntil = nil
+	job.LockedBy = nil
+
+	var permanent *permanentJobError
+	switch {
+	case runErr == nil:
+		job.Status = pkg.JOB_STATUS_SUCCEEDED
+		job.FinishedAt = &now
+		job.LastError = nil
+	case errors.As(runErr, &permanent) || job.Attempts >= job.MaxAttempts:
+		lastError := runErr.Error()
+		job.Status = pkg.JOB_STATUS_FAILED
+		job.FinishedAt = &now
+		job.LastError = &lastError
+		q.Logger.Errorf("job %s (%s) failed after %d attempts: %s", job.ID, job.Type, job.Attempts, lastError)
+	default:
+		lastError := runErr.Error()
+		job.Status = pkg.JOB_STATUS_PENDING
+		job.LastError = &lastError
+		job.RunAt = now.Add(pkg.Backoff(job.Attempts, q.Config.Jobs.RetryBackoff, q.Config.Jobs.MaxBackoff))
+		q.Logger.Warnf("job %s (%s) failed, retrying at %s: %s", job.ID, job.Type, job.RunAt.Format(time.RFC3339), lastError)
+	}
+	return job
+}
@@ -0,0 +1,163 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"edukita-teaching-grading/configs"
+	"edukita-teaching-grading/internal/app/model"
+	"edukita-teaching-grading/internal/pkg"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+func newTestJobQueue() *JobQueue {
+	return NewJobQueue(JobQueueOption{
+		OptionsApplication: pkg.OptionsApplication{
+			Config: &configs.Config{
+				Jobs: configs.Jobs{
+					VisibilityTimeout: time.Minute,
+					MaxAttempts:       3,
+					RetryBackoff:      time.Minute,
+					MaxBackoff:        time.Hour,
+				},
+			},
+			Logger: zap.NewNop().Sugar(),
+		},
+	})
+}
+
+func newTestJob(jobType string, payload string) model.Job {
+	workerID := "test/1"
+	lockedUntil := time.Now().Add(time.Minute)
+	return model.Job{
+		ID:          uuid.New(),
+		Type:        jobType,
+		Payload:     payload,
+		Status:      pkg.JOB_STATUS_RUNNING,
+		Attempts:    1,
+		MaxAttempts: 3,
+		LockedUntil: &lockedUntil,
+		LockedBy:    &workerID,
+	}
+}
+
+func TestJobAttemptSucceeded(t *testing.T) {
+	now := time.Now()
+	job := newTestJobQueue().attempt(newTestJob("export", `{}`), nil, now)
+	if job.Status != pkg.JOB_STATUS_SUCCEEDED || job.FinishedAt == nil || !job.FinishedAt.Equal(now) {
+		t.Errorf("status %s, finished at %v", job.Status, job.FinishedAt)
+	}
+	if job.LockedUntil != nil || job.LockedBy != nil {
+		t.Error("lock not released")
+	}
+}
+
+func TestJobAttemptRetriesThenFails(t *testing.T) {
+	q := newTestJobQueue()
+	job := newTestJob("export", `{}`)
+	now := time.Now()
+
+	for i, wait := range []time.Duration{time.Minute, 2 * time.Minute} {
+		job = q.attempt(job, errors.New("timeout"), now)
+		if job.Status != pkg.JOB_STATUS_PENDING || job.FinishedAt != nil {
+			t.Fatalf("attempt %d: status %s, want pending", i+1, job.Status)
+		}
+		if !job.RunAt.Equal(now.Add(wait)) {
+			t.Errorf("attempt %d: run at %v, want %v", i+1, job.RunAt, now.Add(wait))
+		}
+		if job.LastError == nil || *job.LastError != "timeout" || job.LockedBy != nil {
+			t.Errorf("attempt %d: failure not recorded %+v", i+1, job)
+		}
+		// the next claim counts the attempt
+		job.Attempts++
+	}
+
+	job = q.attempt(job, errors.New("timeout"), now)
+	if job.Status != pkg.JOB_STATUS_FAILED || job.FinishedAt == nil {
+		t.Errorf("status %s after %d attempts, want failed after 3", job.Status, job.Attempts)
+	}
+}
+
+func TestJobAttemptPermanentError(t *testing.T) {
+	job := newTestJobQueue().attempt(newTestJob("export", `{}`), PermanentJobError(errors.New("course deleted")), time.Now())
+	if job.Status != pkg.JOB_STATUS_FAILED || job.LastError == nil || *job.LastError != "course deleted" {
+		t.Errorf("status %s, last error %v, want failed on the first attempt", job.Status, job.LastError)
+	}
+}
+
+func TestJobHandleDecodesPayload(t *testing.T) {
+	q := newTestJobQueue()
+	var got string
+	var attempts int
+	Handle(q, "export", func(ctx context.Context, job model.Job, payload struct {
+		CourseID string `json:"course_id"`
+	}) error {
+		got, attempts = payload.CourseID, job.Attempts
+		return nil
+	})
+
+	if err := q.run(context.Background(), newTestJob("export", `{"course_id":"c-1"}`)); err != nil || got != "c-1" || attempts != 1 {
+		t.Errorf("run returned %v with course id %q on attempt %d", err, got, attempts)
+	}
+
+	var permanent *permanentJobError
+	err := q.run(context.Background(), newTestJob("export", `not json`))
+	if !errors.As(err, &permanent) {
+		t.Errorf("got %v, want a permanent error for a payload that does not decode", err)
+	}
+}
+
+func TestJobRunFailures(t *testing.T) {
+	q := newTestJobQueue()
+	Handle(q, "scan", func(ctx context.Context, job model.Job, payload map[string]any) error {
+		panic("index out of range")
+	})
+
+	if err := q.run(context.Background(), newTestJob("scan", `{}`)); err == nil || !strings.Contains(err.Error(), "index out of range") {
+		t.Errorf("got %v, want the panic as an error", err)
+	}
+
+	var permanent *permanentJobError
+	if err := q.run(context.Background(), newTestJob("unknown", `{}`)); !errors.As(err, &permanent) {
+		t.Errorf("got %v, want a permanent error for a type without a handler", err)
+	}
+
+	exhausted := newTestJob("scan", `{}`)
+	exhausted.Attempts = exhausted.MaxAttempts + 1
+	if err := q.run(context.Background(), exhausted); !errors.As(err, &permanent) {
+		t.Errorf("got %v, want a permanent error for a job claimed past its attempts", err)
+	}
+}
+
+func TestJobFinishDecodesPayload(t *testing.T) {
+	q := newTestJobQueue()
+	var got []string
+	Finish(q, "export", func(ctx context.Context, job model.Job, payload struct {
+		CourseID string `json:"course_id"`
+	}, tx *sqlx.Tx) error {
+		got = append(got, payload.CourseID+" "+job.Status)
+		return nil
+	})
+
+	job := q.attempt(newTestJob("export", `{"course_id":"c-1"}`), nil, time.Now())
+	if err := q.finishers["export"](context.Background(), job, nil); err != nil || len(got) != 1 || got[0] != "c-1 "+pkg.JOB_STATUS_SUCCEEDED {
+		t.Errorf("finish returned %v and saved %v", err, got)
+	}
+
+	// the run of a payload that does not decode failed for good, there is nothing to save
+	if err := q.finishers["export"](context.Background(), newTestJob("export", `not json`), nil); err != nil || len(got) != 1 {
+		t.Errorf("finish returned %v and saved %v for a payload that does not decode", err, got)
+	}
+}
+
+func TestJobEnqueueRejectsUnknownType(t *testing.T) {
+	if _, err := newTestJobQueue().Enqueue(context.Background(), "unknown", nil, EnqueueOptions{}); err == nil {
+		t.Error("enqueued a job without a handler")
+	}
+}
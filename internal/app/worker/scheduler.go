@@ -0,0 +1,160 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"edukita-teaching-grading/internal/app/model"
+	"edukita-teaching-grading/internal/app/repository"
+	"edukita-teaching-grading/internal/pkg"
+	"edukita-teaching-grading/pkg/scheduler"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// JobFunc does the work of one run of a scheduled job at now and sums up what it did
+type JobFunc func(ctx context.Context, now time.Time) (summary string, err error)
+
+// Job is a task run whenever its cron schedule fires
+type Job struct {
+	Name     string
+	Schedule scheduler.Schedule
+	Run      JobFunc
+}
+
+type SchedulerOption struct {
+	pkg.OptionsApplication
+	Repository *repository.Repository
+}
+
+// Scheduler runs the registered jobs on their schedules. Every replica runs a scheduler, and a Postgres advisory
+// lock together with the run history lets each firing of a job run on one of them.
+type Scheduler struct {
+	SchedulerOption
+	jobs     []Job
+	location *time.Location
+	instance string
+}
+
+func NewScheduler(opt SchedulerOption) (*Scheduler, error) {
+	location, err := time.LoadLocation(opt.Config.Scheduler.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid scheduler time zone %q: %w", opt.Config.Scheduler.Timezone, err)
+	}
+
+	instance, err := os.Hostname()
+	if err != nil {
+		instance = "unknown"
+	}
+	return &Scheduler{
+		SchedulerOption: opt,
+		location:        location,
+		instance:        fmt.Sprintf("%s/%d", instance, os.Getpid()),
+	}, nil
+}
+
+// Register adds a job run on the cron expression spec, it must be called before Run
+func (s *Scheduler) Register(name string, spec string, run JobFunc) error {
+	schedule, err := scheduler.Parse(spec)
+	if err != nil {
+		return fmt.Errorf("job %s: %w", name, err)
+	}
+	s.jobs = append(s.jobs, Job{Name: name, Schedule: schedule, Run: run})
+	return nil
+}
+
+// Run runs the jobs until ctx is cancelled and waits for the runs in progress to finish
+func (s *Scheduler) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, job := range s.jobs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.loop(ctx, job)
+		}()
+	}
+	wg.Wait()
+}
+
+// loop waits for every firing of the job and runs it. A firing missed while the previous run was still going,
+// or while no replica was up, is skipped rather than caught up.
+func (s *Scheduler) loop(ctx context.Context, job Job) {
+	for {
+		next := job.Schedule.Next(time.Now().In(s.location))
+		if next.IsZero() {
+			s.Logger.Warnf("scheduled job %s never fires on %q", job.Name, job.Schedule.String())
+			return
+		}
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		if err := s.RunJob(ctx, job, next); err != nil {
+			s.Logger.Errorf(fmt.Sprintf("failed to run scheduled job %s: %s", job.Name, err.Error()), zap.Error(err))
+		}
+	}
+}
+
+// RunJob runs the firing of the job at scheduledAt unless another replica is running the job or already ran
+// this firing. The run and its record share one transaction, which holds the lock of the job until it commits,
+// and a failed job leaves only the record of its failure behind.
+func (s *Scheduler) RunJob(ctx context.Context, job Job, scheduledAt time.Time) error {
+	return s.Repository.Tx.Run(ctx, repository.TxReadWrite, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+		locked, err := s.Repository.Scheduler.TryLockScheduledJob(ctx, job.Name, tx)
+		if err != nil || !locked {
+			return
+		}
+
+		// the lock is only released once the run before was committed, so its record is visible here
+		ran, err := s.Repository.Scheduler.ScheduledJobRunExists(ctx, job.Name, scheduledAt, tx)
+		if err != nil || ran {
+			return
+		}
+
+		run := model.ScheduledJobRun{
+			ID:          uuid.New(),
+			JobName:     job.Name,
+			ScheduledAt: scheduledAt,
+			Instance:    s.instance,
+			StartedAt:   time.Now(),
+		}
+		var summary string
+		runErr := s.Repository.Tx.Run(ctx, repository.TxReadWrite, func(ctx context.Context, tx *sqlx.Tx) (err error) {
+			summary, err = job.Run(ctx, scheduledAt)
+			return
+		})
+		run.FinishedAt = time.Now()
+		run = finishedRun(run, summary, runErr)
+
+		if runErr != nil {
+			s.Logger.Errorf("scheduled job %s failed after %s: %s", job.Name, run.FinishedAt.Sub(run.StartedAt), runErr.Error())
+		} else {
+			s.Logger.Infof("scheduled job %s finished in %s: %s", job.Name, run.FinishedAt.Sub(run.StartedAt), summary)
+		}
+
+		_, err = s.Repository.Scheduler.CreateScheduledJobRun(ctx, run, tx)
+		return
+	})
+}
+
+// finishedRun records the outcome of a run
+func finishedRun(run model.ScheduledJobRun, summary string, runErr error) model.ScheduledJobRun {
+	run.Status = pkg.JOB_RUN_SUCCEEDED
+	run.Summary = summary
+	if runErr != nil {
+		lastError := runErr.Error()
+		run.Status = pkg.JOB_RUN_FAILED
+		run.Error = &lastError
+	}
+	return run
+}
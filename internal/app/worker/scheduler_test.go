@@ -0,0 +1,60 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"edukita-teaching-grading/configs"
+	"edukita-teaching-grading/internal/app/model"
+	"edukita-teaching-grading/internal/pkg"
+
+	"go.uber.org/zap"
+)
+
+func newTestScheduler(t *testing.T, timezone string) (*Scheduler, error) {
+	t.Helper()
+	return NewScheduler(SchedulerOption{
+		OptionsApplication: pkg.OptionsApplication{
+			Config: &configs.Config{Scheduler: configs.Scheduler{Timezone: timezone}},
+			Logger: zap.NewNop().Sugar(),
+		},
+	})
+}
+
+func TestNewSchedulerRejectsUnknownTimezone(t *testing.T) {
+	if _, err := newTestScheduler(t, "Mars/Olympus_Mons"); err == nil {
+		t.Error("created a scheduler in an unknown time zone")
+	}
+}
+
+func TestSchedulerRegister(t *testing.T) {
+	s, err := newTestScheduler(t, "UTC")
+	if err != nil {
+		t.Fatal(err)
+	}
+	noop := func(ctx context.Context, now time.Time) (string, error) { return "", nil }
+
+	if err := s.Register("reminders", "*/5 * * * *", noop); err != nil {
+		t.Fatalf("registering a valid job: %v", err)
+	}
+	if err := s.Register("broken", "every five minutes", noop); err == nil {
+		t.Error("registered a job with an invalid schedule")
+	}
+	if len(s.jobs) != 1 || s.jobs[0].Name != "reminders" {
+		t.Errorf("jobs %v, want only the valid one", s.jobs)
+	}
+}
+
+func TestFinishedRun(t *testing.T) {
+	run := finishedRun(model.ScheduledJobRun{JobName: "reminders"}, "3 reminders due", nil)
+	if run.Status != pkg.JOB_RUN_SUCCEEDED || run.Summary != "3 reminders due" || run.Error != nil {
+		t.Errorf("unexpected run %+v", run)
+	}
+
+	run = finishedRun(model.ScheduledJobRun{JobName: "reminders"}, "", errors.New("connection reset"))
+	if run.Status != pkg.JOB_RUN_FAILED || run.Error == nil || *run.Error != "connection reset" {
+		t.Errorf("unexpected run %+v", run)
+	}
+}
@@ -0,0 +1,27 @@
+package pkg
+
+import (
+	"context"
+	"time"
+)
+
+type actorContextKey struct{}
+
+// Actor is the authenticated user performing the request, taken from the verified JWT
+type Actor struct {
+	ID        string
+	Role      string
+	TokenID   string
+	ExpiresAt time.Time
+}
+
+// WithActor returns a copy of the context carrying the actor
+func WithActor(ctx context.Context, actor Actor) context.Context {
+	return context.WithValue(ctx, actorContextKey{}, actor)
+}
+
+// ActorFromContext returns the actor of the request, ok is false for unauthenticated requests
+func ActorFromContext(ctx context.Context) (actor Actor, ok bool) {
+	actor, ok = ctx.Value(actorContextKey{}).(Actor)
+	return actor, ok && actor.ID != ""
+}
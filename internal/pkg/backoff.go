@@ -0,0 +1,13 @@
+package pkg
+
+import "time"
+
+// Backoff is the wait before retrying after the given number of failed attempts, doubling from base with
+// every attempt up to limit
+func Backoff(attempts int, base time.Duration, limit time.Duration) time.Duration {
+	delay := base
+	for i := 1; i < attempts && delay < limit; i++ {
+		delay *= 2
+	}
+	return min(delay, limit)
+}
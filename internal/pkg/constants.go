@@ -14,9 +14,46 @@ var (
 	TABLE_STUDENTS = "students"
 	TABLE_TEACHERS = "teachers"
 
+	TABLE_REFRESH_TOKENS = "refresh_tokens"
+	TABLE_REVOKED_TOKENS = "revoked_tokens"
+	TABLE_USER_TOKENS    = "user_tokens"
+	TABLE_USER_MFA       = "user_mfa"
+	TABLE_RECOVERY_CODES = "mfa_recovery_codes"
+
 	TABLE_COURSES     = "courses"
 	TABLE_ASSIGNMENTS = "assignments"
 	TABLE_SUBMISSIONS = "submissions"
+	TABLE_ATTEMPTS    = "submission_attempts"
+	TABLE_ENROLLMENTS = "enrollments"
+
+	TABLE_COURSE_TEACHERS = "course_teachers"
+	TABLE_ATTACHMENTS     = "attachments"
+
+	TABLE_RUBRICS          = "rubrics"
+	TABLE_RUBRIC_CRITERIA  = "rubric_criteria"
+	TABLE_RUBRIC_LEVELS    = "rubric_levels"
+	TABLE_CRITERION_SCORES = "submission_criterion_scores"
+
+	TABLE_CATEGORIES = "assignment_categories"
+	TABLE_SCHEMES    = "grading_schemes"
+	TABLE_BANDS      = "grading_scheme_bands"
+
+	TABLE_AUDIT_EVENTS = "audit_events"
+	TABLE_AUDIT_HEAD   = "audit_chain_head"
+
+	TABLE_OUTBOX_EVENTS = "outbox_events"
+
+	TABLE_WEBHOOKS         = "webhook_subscriptions"
+	TABLE_WEBHOOK_DELIVERY = "webhook_deliveries"
+	TABLE_WEBHOOK_ATTEMPTS = "webhook_delivery_attempts"
+
+	TABLE_NOTIFICATIONS            = "notifications"
+	TABLE_NOTIFICATION_PREFERENCES = "notification_preferences"
+
+	TABLE_EMAIL_MESSAGES = "email_messages"
+
+	TABLE_SCHEDULED_JOB_RUNS = "scheduled_job_runs"
+	TABLE_JOBS               = "jobs"
 )
 
 // Roles
@@ -25,3 +62,115 @@ var (
 	ROLE_TEACHER = "teacher"
 	ROLE_STUDENT = "student"
 )
+
+// Enrollment status
+var (
+	ENROLLMENT_STATUS_ACTIVE  = "active"
+	ENROLLMENT_STATUS_DROPPED = "dropped"
+
+	// late policies of an assignment, applied once the due date has passed
+	LATE_POLICY_REJECT  = "reject"
+	LATE_POLICY_ACCEPT  = "accept"
+	LATE_POLICY_PENALTY = "penalty"
+)
+
+// Grading scheme types
+var (
+	GRADING_SCHEME_LETTER    = "letter"
+	GRADING_SCHEME_PASS_FAIL = "pass_fail"
+)
+
+// Token revocation reasons
+var (
+	REVOKE_REASON_LOGOUT       = "logout"
+	REVOKE_REASON_FORCED       = "forced_sign_out"
+	REVOKE_REASON_TOKEN_REUSED = "refresh_token_reused"
+	REVOKE_REASON_USER_DELETED = "user_deleted"
+	REVOKE_REASON_PASSWORD     = "password_changed"
+)
+
+// Purposes of the single-use tokens mailed to users
+var (
+	USER_TOKEN_PASSWORD_RESET     = "password_reset"
+	USER_TOKEN_EMAIL_VERIFICATION = "email_verification"
+	USER_TOKEN_MFA_CHALLENGE      = "mfa_challenge"
+)
+
+// Audit event actions
+var (
+	AUDIT_ACTION_CREATE  = "create"
+	AUDIT_ACTION_UPDATE  = "update"
+	AUDIT_ACTION_DELETE  = "delete"
+	AUDIT_ACTION_RESTORE = "restore"
+	AUDIT_ACTION_PURGE   = "purge"
+)
+
+// Audited entity types
+var (
+	AUDIT_ENTITY_USER           = "user"
+	AUDIT_ENTITY_COURSE         = "course"
+	AUDIT_ENTITY_COURSE_TEACHER = "course_teacher"
+	AUDIT_ENTITY_ENROLLMENT     = "enrollment"
+	AUDIT_ENTITY_ASSIGNMENT     = "assignment"
+	AUDIT_ENTITY_CATEGORY       = "category"
+	AUDIT_ENTITY_GRADING_SCHEME = "grading_scheme"
+	AUDIT_ENTITY_RUBRIC         = "rubric"
+	AUDIT_ENTITY_SUBMISSION     = "submission"
+	AUDIT_ENTITY_ATTACHMENT     = "attachment"
+	AUDIT_ENTITY_SESSION        = "session"
+	AUDIT_ENTITY_WEBHOOK        = "webhook"
+	AUDIT_ENTITY_MFA            = "mfa"
+)
+
+// Webhook delivery status
+var (
+	WEBHOOK_DELIVERY_PENDING   = "pending"
+	WEBHOOK_DELIVERY_SUCCEEDED = "succeeded"
+	// dead deliveries ran out of attempts and are only sent again when redelivered by hand
+	WEBHOOK_DELIVERY_DEAD = "dead"
+)
+
+// Notification types, every type can be turned off in the notification preferences of a user
+var (
+	NOTIFICATION_ASSIGNMENT_PUBLISHED = "assignment_published"
+	NOTIFICATION_GRADE_POSTED         = "grade_posted"
+	NOTIFICATION_FEEDBACK_POSTED      = "feedback_posted"
+	NOTIFICATION_ASSIGNMENT_DUE_SOON  = "assignment_due_soon"
+)
+
+// Email message status
+var (
+	EMAIL_PENDING = "pending"
+	EMAIL_SENT    = "sent"
+	// dead emails ran out of attempts and are not sent again
+	EMAIL_DEAD = "dead"
+)
+
+// Scheduled jobs
+var (
+	JOB_DUE_REMINDERS            = "due_reminders"
+	JOB_DEACTIVATE_ENDED_COURSES = "deactivate_ended_courses"
+	JOB_PURGE_EXPIRED_TOKENS     = "purge_expired_tokens"
+	JOB_PURGE_FINISHED_JOBS      = "purge_finished_jobs"
+)
+
+// Scheduled job run status
+var (
+	JOB_RUN_SUCCEEDED = "succeeded"
+	JOB_RUN_FAILED    = "failed"
+)
+
+// Job types of the job queue, each has a handler registered in Go
+var (
+	JOB_TYPE_SEND_EMAIL      = "send_email"
+	JOB_TYPE_DELIVER_WEBHOOK = "deliver_webhook"
+)
+
+// Job status of the job queue
+var (
+	JOB_STATUS_PENDING   = "pending"
+	JOB_STATUS_RUNNING   = "running"
+	JOB_STATUS_SUCCEEDED = "succeeded"
+	// failed jobs ran out of attempts or cannot succeed, they only run again when retried by hand
+	JOB_STATUS_FAILED = "failed"
+)
@@ -0,0 +1,99 @@
+package pkg
+
+import (
+	"errors"
+	"strings"
+)
+
+const (
+	DIFF_OP_EQUAL  = "equal"
+	DIFF_OP_INSERT = "insert"
+	DIFF_OP_DELETE = "delete"
+
+	// DIFF_MAX_LINES bounds the lines of each text between their common head and tail, which keeps the table of
+	// the longest common subsequence under about 16MB
+	DIFF_MAX_LINES = 2000
+)
+
+// ErrDiffTooLarge is returned when the texts differ in more than DIFF_MAX_LINES lines
+var ErrDiffTooLarge = errors.New("diff: texts differ in too many lines")
+
+// DiffLine is one line of a line based diff between two texts
+type DiffLine struct {
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
+
+// DiffLines compares two texts line by line using their longest common subsequence. The lines both texts start
+// and end with are matched up front, so only the part that changed is compared.
+func DiffLines(from string, to string) ([]DiffLine, error) {
+	a := splitLines(from)
+	b := splitLines(to)
+
+	head := 0
+	for head < len(a) && head < len(b) && a[head] == b[head] {
+		head++
+	}
+	tail := 0
+	for tail < len(a)-head && tail < len(b)-head && a[len(a)-1-tail] == b[len(b)-1-tail] {
+		tail++
+	}
+	if len(a)-head-tail > DIFF_MAX_LINES || len(b)-head-tail > DIFF_MAX_LINES {
+		return nil, ErrDiffTooLarge
+	}
+
+	lines := make([]DiffLine, 0, max(len(a), len(b)))
+	for _, line := range a[:head] {
+		lines = append(lines, DiffLine{Op: DIFF_OP_EQUAL, Text: line})
+	}
+	lines = appendChangedLines(lines, a[head:len(a)-tail], b[head:len(b)-tail])
+	for _, line := range a[len(a)-tail:] {
+		lines = append(lines, DiffLine{Op: DIFF_OP_EQUAL, Text: line})
+	}
+	return lines, nil
+}
+
+func appendChangedLines(lines []DiffLine, a []string, b []string) []DiffLine {
+	// lcs[i*width+j] is the length of the longest common subsequence of a[i:] and b[j:]
+	width := len(b) + 1
+	lcs := make([]int32, (len(a)+1)*width)
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i*width+j] = lcs[(i+1)*width+j+1] + 1
+			} else {
+				lcs[i*width+j] = max(lcs[(i+1)*width+j], lcs[i*width+j+1])
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			lines = append(lines, DiffLine{Op: DIFF_OP_EQUAL, Text: a[i]})
+			i++
+			j++
+		case lcs[(i+1)*width+j] >= lcs[i*width+j+1]:
+			lines = append(lines, DiffLine{Op: DIFF_OP_DELETE, Text: a[i]})
+			i++
+		default:
+			lines = append(lines, DiffLine{Op: DIFF_OP_INSERT, Text: b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		lines = append(lines, DiffLine{Op: DIFF_OP_DELETE, Text: a[i]})
+	}
+	for ; j < len(b); j++ {
+		lines = append(lines, DiffLine{Op: DIFF_OP_INSERT, Text: b[j]})
+	}
+	return lines
+}
+
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+}
@@ -0,0 +1,67 @@
+package pkg
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiffLines(t *testing.T) {
+	equal := func(text string) DiffLine { return DiffLine{Op: DIFF_OP_EQUAL, Text: text} }
+	insert := func(text string) DiffLine { return DiffLine{Op: DIFF_OP_INSERT, Text: text} }
+	remove := func(text string) DiffLine { return DiffLine{Op: DIFF_OP_DELETE, Text: text} }
+
+	tests := []struct {
+		name string
+		from string
+		to   string
+		want []DiffLine
+	}{
+		{"both empty", "", "", []DiffLine{}},
+		{"from empty", "", "a\nb", []DiffLine{insert("a"), insert("b")}},
+		{"to empty", "a\nb", "", []DiffLine{remove("a"), remove("b")}},
+		{"unchanged", "a\nb", "a\nb", []DiffLine{equal("a"), equal("b")}},
+		{"crlf is a newline", "a\r\nb", "a\nb", []DiffLine{equal("a"), equal("b")}},
+		{"line changed", "a\nb\nc", "a\nx\nc", []DiffLine{equal("a"), remove("b"), insert("x"), equal("c")}},
+		{"line added", "a\nc", "a\nb\nc", []DiffLine{equal("a"), insert("b"), equal("c")}},
+		{"line removed", "a\nb\nc", "a\nc", []DiffLine{equal("a"), remove("b"), equal("c")}},
+		{"lines moved", "a\nb\nc\nd", "c\nd\na\nb", []DiffLine{remove("a"), remove("b"), equal("c"), equal("d"), insert("a"), insert("b")}},
+		{"repeated lines", "x\na\nx", "x\nx\nx", []DiffLine{equal("x"), remove("a"), insert("x"), equal("x")}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DiffLines(tt.from, tt.to)
+			if err != nil {
+				t.Fatalf("DiffLines() unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DiffLines() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffLinesTooLarge(t *testing.T) {
+	changed := func(prefix string, n int) string {
+		lines := make([]string, n)
+		for i := range lines {
+			lines[i] = prefix + strings.Repeat("-", i%7)
+		}
+		return strings.Join(lines, "\n")
+	}
+	same := strings.Repeat("same\n", 10*DIFF_MAX_LINES)
+
+	// long texts are compared as long as the lines in between their common head and tail fit
+	lines, err := DiffLines(same+changed("a", DIFF_MAX_LINES)+"\n"+same, same+changed("b", DIFF_MAX_LINES)+"\n"+same)
+	if err != nil {
+		t.Fatalf("DiffLines() unexpected error: %v", err)
+	}
+	if len(lines) != 2*10*DIFF_MAX_LINES+2*DIFF_MAX_LINES+1 {
+		t.Errorf("got %d lines", len(lines))
+	}
+
+	if _, err := DiffLines(changed("a", DIFF_MAX_LINES+1), changed("b", 1)); !errors.Is(err, ErrDiffTooLarge) {
+		t.Errorf("DiffLines() error = %v, want ErrDiffTooLarge", err)
+	}
+}
@@ -1,6 +1,9 @@
 package pkg
 
-import "net/http"
+import (
+	"errors"
+	"net/http"
+)
 
 type AppError struct {
 	Code       string   `json:"code"`
@@ -18,6 +21,12 @@ func (e *AppError) Unwrap() error {
 	return e.Err
 }
 
+// IsNotFound tells whether err is, or wraps, an AppError for a missing resource
+func IsNotFound(err error) bool {
+	var e *AppError
+	return errors.As(err, &e) && e.StatusCode == http.StatusNotFound
+}
+
 // Helper constructors
 func NewError(code string, msg string, statusCode int, err error) *AppError {
 	return &AppError{
@@ -32,6 +41,14 @@ func NewBadRequestError(msg string, err error) *AppError {
 	return NewError(http.StatusText(http.StatusBadRequest), msg, http.StatusBadRequest, err)
 }
 
+func NewUnauthorizedError(msg string, err error) *AppError {
+	return NewError(http.StatusText(http.StatusUnauthorized), msg, http.StatusUnauthorized, err)
+}
+
+func NewForbiddenError(msg string, err error) *AppError {
+	return NewError(http.StatusText(http.StatusForbidden), msg, http.StatusForbidden, err)
+}
+
 func NewNotFoundError(msg string, err error) *AppError {
 	return NewError(http.StatusText(http.StatusNotFound), msg, http.StatusNotFound, err)
 }
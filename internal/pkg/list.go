@@ -0,0 +1,61 @@
+package pkg
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	LIST_DEFAULT_PER_PAGE = 20
+	LIST_MAX_PER_PAGE     = 100
+)
+
+// ListQuery holds the paging, sorting and filtering parameters shared by the list endpoints.
+// Which sort fields and filters an endpoint accepts is decided by its repository.
+type ListQuery struct {
+	Page    int
+	PerPage int
+	// Cursor continues after the last row of a previous page and takes precedence over Page
+	Cursor string
+	// Sort lists field names in order of precedence, a leading "-" sorts descending
+	Sort    []string
+	Filters map[string]string
+}
+
+// ListPage describes the page a repository returned
+type ListPage struct {
+	TotalCount int
+	Page       int
+	PerPage    int
+	// NextCursor is empty on the last page
+	NextCursor string
+}
+
+// ParseListQuery reads page, per_page, cursor and sort from the query parameters, every other parameter is a filter
+func ParseListQuery(params map[string]string) (q ListQuery, err error) {
+	q = ListQuery{Page: 1, PerPage: LIST_DEFAULT_PER_PAGE, Filters: make(map[string]string)}
+	for key, value := range params {
+		switch key {
+		case "page":
+			if q.Page, err = strconv.Atoi(value); err != nil || q.Page < 1 {
+				return q, NewBadRequestError("page must be a positive number", err)
+			}
+		case "per_page":
+			if q.PerPage, err = strconv.Atoi(value); err != nil || q.PerPage < 1 || q.PerPage > LIST_MAX_PER_PAGE {
+				return q, NewBadRequestError(fmt.Sprintf("per_page must be between 1 and %d", LIST_MAX_PER_PAGE), err)
+			}
+		case "cursor":
+			q.Cursor = value
+		case "sort":
+			for _, field := range strings.Split(value, ",") {
+				if field = strings.TrimSpace(field); field != "" {
+					q.Sort = append(q.Sort, field)
+				}
+			}
+		default:
+			q.Filters[key] = value
+		}
+	}
+	return q, nil
+}
@@ -0,0 +1,53 @@
+package pkg
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestParseListQuery(t *testing.T) {
+	tests := []struct {
+		name    string
+		params  map[string]string
+		want    ListQuery
+		wantErr bool
+	}{
+		{"defaults", nil, ListQuery{Page: 1, PerPage: LIST_DEFAULT_PER_PAGE, Filters: map[string]string{}}, false},
+		{
+			name:   "every parameter",
+			params: map[string]string{"page": "3", "per_page": "50", "cursor": "abc", "sort": "title, -due_date,,", "course_id": "c1", "published": "true"},
+			want: ListQuery{
+				Page:    3,
+				PerPage: 50,
+				Cursor:  "abc",
+				Sort:    []string{"title", "-due_date"},
+				Filters: map[string]string{"course_id": "c1", "published": "true"},
+			},
+		},
+		{"page zero", map[string]string{"page": "0"}, ListQuery{}, true},
+		{"page not a number", map[string]string{"page": "two"}, ListQuery{}, true},
+		{"per page zero", map[string]string{"per_page": "0"}, ListQuery{}, true},
+		{"per page over the maximum", map[string]string{"per_page": "101"}, ListQuery{}, true},
+		{"per page at the maximum", map[string]string{"per_page": "100"}, ListQuery{Page: 1, PerPage: LIST_MAX_PER_PAGE, Filters: map[string]string{}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseListQuery(tt.params)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseListQuery() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				var appErr *AppError
+				if !errors.As(err, &appErr) || appErr.StatusCode != http.StatusBadRequest {
+					t.Errorf("ParseListQuery() error = %v, want a bad request", err)
+				}
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseListQuery() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
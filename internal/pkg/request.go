@@ -0,0 +1,22 @@
+package pkg
+
+import "context"
+
+type requestContextKey struct{}
+
+// RequestMeta identifies the HTTP request a service call is made for
+type RequestMeta struct {
+	ID string
+	IP string
+}
+
+// WithRequestMeta returns a copy of the context carrying the request metadata
+func WithRequestMeta(ctx context.Context, meta RequestMeta) context.Context {
+	return context.WithValue(ctx, requestContextKey{}, meta)
+}
+
+// RequestMetaFromContext returns the metadata of the request, it is empty outside of HTTP requests
+func RequestMetaFromContext(ctx context.Context) RequestMeta {
+	meta, _ := ctx.Value(requestContextKey{}).(RequestMeta)
+	return meta
+}
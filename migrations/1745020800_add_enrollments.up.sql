@@ -0,0 +1,28 @@
+CREATE TABLE enrollments (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    course_id UUID NOT NULL REFERENCES courses(id) ON DELETE CASCADE,
+    student_id UUID NOT NULL REFERENCES students(user_id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL DEFAULT 'active',
+    enrolled_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    dropped_at TIMESTAMP WITH TIME ZONE,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    deleted_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    deleted_at TIMESTAMP WITH TIME ZONE,
+    UNIQUE(course_id, student_id)
+);
+
+CREATE INDEX idx_enrollments_course_id ON enrollments(course_id);
+CREATE INDEX idx_enrollments_student_id ON enrollments(student_id);
+
+CREATE TRIGGER update_enrollments_modtime BEFORE UPDATE ON enrollments FOR EACH ROW EXECUTE FUNCTION update_modified_column();
+
+-- Backfill enrollments for students that already submitted work in a course
+INSERT INTO enrollments (course_id, student_id, status, enrolled_at, created_by, created_at)
+SELECT DISTINCT a.course_id, s.student_id, 'active', MIN(s.submitted_at), s.student_id, NOW()
+FROM submissions s
+INNER JOIN assignments a ON a.id = s.assignment_id
+GROUP BY a.course_id, s.student_id
+ON CONFLICT (course_id, student_id) DO NOTHING;
@@ -0,0 +1,23 @@
+CREATE TABLE course_teachers (
+    course_id UUID NOT NULL REFERENCES courses(id) ON DELETE CASCADE,
+    teacher_id UUID NOT NULL REFERENCES teachers(user_id) ON DELETE CASCADE,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (course_id, teacher_id)
+);
+
+CREATE INDEX idx_course_teachers_teacher_id ON course_teachers(teacher_id);
+
+-- Backfill the teachers that already own assignments in a course
+INSERT INTO course_teachers (course_id, teacher_id, created_by, created_at)
+SELECT DISTINCT a.course_id, a.teacher_id, a.teacher_id, NOW()
+FROM assignments a
+INNER JOIN teachers t ON t.user_id = a.teacher_id
+ON CONFLICT (course_id, teacher_id) DO NOTHING;
+
+-- Course creators that are teachers co-own their own courses
+INSERT INTO course_teachers (course_id, teacher_id, created_by, created_at)
+SELECT c.id, c.created_by, c.created_by, NOW()
+FROM courses c
+INNER JOIN teachers t ON t.user_id = c.created_by
+ON CONFLICT (course_id, teacher_id) DO NOTHING;
@@ -0,0 +1,23 @@
+CREATE TABLE attachments (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    submission_id UUID REFERENCES submissions(id) ON DELETE CASCADE,
+    assignment_id UUID REFERENCES assignments(id) ON DELETE CASCADE,
+    file_name VARCHAR(255) NOT NULL,
+    content_type VARCHAR(100) NOT NULL,
+    size_bytes BIGINT NOT NULL,
+    checksum_sha256 CHAR(64) NOT NULL,
+    storage_key VARCHAR(255) UNIQUE NOT NULL,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    deleted_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    deleted_at TIMESTAMP WITH TIME ZONE,
+    -- an attachment belongs to exactly one submission or assignment
+    CHECK ((submission_id IS NULL) <> (assignment_id IS NULL))
+);
+
+CREATE INDEX idx_attachments_submission_id ON attachments(submission_id);
+CREATE INDEX idx_attachments_assignment_id ON attachments(assignment_id);
+
+CREATE TRIGGER update_attachments_modtime BEFORE UPDATE ON attachments FOR EACH ROW EXECUTE FUNCTION update_modified_column();
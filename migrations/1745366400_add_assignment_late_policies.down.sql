@@ -0,0 +1,13 @@
+ALTER TABLE submissions
+    DROP COLUMN IF EXISTS raw_grade,
+    DROP COLUMN IF EXISTS late_penalty_percent,
+    DROP COLUMN IF EXISTS is_late;
+
+ALTER TABLE assignments
+    DROP CONSTRAINT IF EXISTS assignments_availability_check,
+    DROP CONSTRAINT IF EXISTS assignments_late_penalty_percent_check,
+    DROP CONSTRAINT IF EXISTS assignments_late_policy_check,
+    DROP COLUMN IF EXISTS late_penalty_percent,
+    DROP COLUMN IF EXISTS late_policy,
+    DROP COLUMN IF EXISTS available_until,
+    DROP COLUMN IF EXISTS available_from;
@@ -0,0 +1,22 @@
+ALTER TABLE assignments
+    ADD COLUMN available_from TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN available_until TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN late_policy VARCHAR(20) NOT NULL DEFAULT 'accept',
+    ADD COLUMN late_penalty_percent DECIMAL(5,2) NOT NULL DEFAULT 0,
+    ADD CONSTRAINT assignments_late_policy_check CHECK (late_policy IN ('reject', 'accept', 'penalty')),
+    ADD CONSTRAINT assignments_late_penalty_percent_check CHECK (late_penalty_percent BETWEEN 0 AND 100),
+    ADD CONSTRAINT assignments_availability_check CHECK (available_from IS NULL OR available_until IS NULL OR available_from < available_until);
+
+ALTER TABLE submissions
+    ADD COLUMN is_late BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN late_penalty_percent DECIMAL(5,2) NOT NULL DEFAULT 0,
+    ADD COLUMN raw_grade DECIMAL(5,2);
+
+-- Existing grades were entered without any penalty
+UPDATE submissions SET raw_grade = grade WHERE grade IS NOT NULL;
+
+-- Flag work that was already handed in after the due date
+UPDATE submissions s
+SET is_late = TRUE
+FROM assignments a
+WHERE a.id = s.assignment_id AND s.submitted_at > a.due_date;
@@ -0,0 +1,9 @@
+ALTER TABLE submissions
+    DROP COLUMN IF EXISTS graded_attempt_id,
+    DROP COLUMN IF EXISTS attempt_count;
+
+DROP TABLE IF EXISTS submission_attempts;
+
+ALTER TABLE assignments
+    DROP CONSTRAINT IF EXISTS assignments_max_attempts_check,
+    DROP COLUMN IF EXISTS max_attempts;
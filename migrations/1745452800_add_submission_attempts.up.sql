@@ -0,0 +1,31 @@
+ALTER TABLE assignments
+    ADD COLUMN max_attempts INTEGER NOT NULL DEFAULT 0,
+    ADD CONSTRAINT assignments_max_attempts_check CHECK (max_attempts >= 0);
+
+CREATE TABLE submission_attempts (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    submission_id UUID NOT NULL REFERENCES submissions(id) ON DELETE CASCADE,
+    attempt_number INTEGER NOT NULL,
+    content TEXT,
+    file_url VARCHAR(512),
+    submitted_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    is_late BOOLEAN NOT NULL DEFAULT FALSE,
+    late_penalty_percent DECIMAL(5,2) NOT NULL DEFAULT 0,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE(submission_id, attempt_number)
+);
+
+ALTER TABLE submissions
+    ADD COLUMN attempt_count INTEGER NOT NULL DEFAULT 1,
+    ADD COLUMN graded_attempt_id UUID REFERENCES submission_attempts(id) ON DELETE SET NULL;
+
+-- The current content of every submission becomes its first attempt
+INSERT INTO submission_attempts (submission_id, attempt_number, content, file_url, submitted_at, is_late, late_penalty_percent, created_by, created_at)
+SELECT id, 1, content, file_url, submitted_at, is_late, late_penalty_percent, student_id, submitted_at
+FROM submissions;
+
+UPDATE submissions s
+SET graded_attempt_id = sa.id
+FROM submission_attempts sa
+WHERE sa.submission_id = s.id AND s.grade IS NOT NULL;
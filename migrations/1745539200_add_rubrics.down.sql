@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS submission_criterion_scores;
+
+ALTER TABLE assignments DROP COLUMN IF EXISTS rubric_id;
+
+DROP TABLE IF EXISTS rubric_levels;
+DROP TABLE IF EXISTS rubric_criteria;
+DROP TABLE IF EXISTS rubrics;
@@ -0,0 +1,51 @@
+CREATE TABLE rubrics (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    title VARCHAR(255) NOT NULL,
+    description TEXT,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    deleted_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE TABLE rubric_criteria (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    rubric_id UUID NOT NULL REFERENCES rubrics(id) ON DELETE CASCADE,
+    title VARCHAR(255) NOT NULL,
+    description TEXT,
+    position INTEGER NOT NULL,
+    UNIQUE(rubric_id, position)
+);
+
+CREATE TABLE rubric_levels (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    criterion_id UUID NOT NULL REFERENCES rubric_criteria(id) ON DELETE CASCADE,
+    title VARCHAR(255) NOT NULL,
+    description TEXT,
+    points DECIMAL(5,2) NOT NULL CHECK (points >= 0),
+    position INTEGER NOT NULL,
+    UNIQUE(criterion_id, position)
+);
+
+ALTER TABLE assignments
+    ADD COLUMN rubric_id UUID REFERENCES rubrics(id) ON DELETE SET NULL;
+
+-- The level picked and the feedback given for every criterion of a graded submission
+CREATE TABLE submission_criterion_scores (
+    submission_id UUID NOT NULL REFERENCES submissions(id) ON DELETE CASCADE,
+    criterion_id UUID NOT NULL REFERENCES rubric_criteria(id) ON DELETE CASCADE,
+    level_id UUID NOT NULL REFERENCES rubric_levels(id) ON DELETE CASCADE,
+    points DECIMAL(5,2) NOT NULL,
+    feedback TEXT,
+    graded_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    graded_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (submission_id, criterion_id)
+);
+
+CREATE INDEX idx_rubric_criteria_rubric_id ON rubric_criteria(rubric_id);
+CREATE INDEX idx_rubric_levels_criterion_id ON rubric_levels(criterion_id);
+CREATE INDEX idx_assignments_rubric_id ON assignments(rubric_id);
+
+CREATE TRIGGER update_rubrics_modtime BEFORE UPDATE ON rubrics FOR EACH ROW EXECUTE FUNCTION update_modified_column();
@@ -0,0 +1,3 @@
+ALTER TABLE assignments DROP COLUMN IF EXISTS category_id;
+
+DROP TABLE IF EXISTS assignment_categories;
@@ -0,0 +1,23 @@
+CREATE TABLE assignment_categories (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    course_id UUID NOT NULL REFERENCES courses(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    -- share of the course grade in percent, the weights of a course add up to at most 100
+    weight DECIMAL(5,2) NOT NULL CHECK (weight > 0 AND weight <= 100),
+    drop_lowest INTEGER NOT NULL DEFAULT 0 CHECK (drop_lowest >= 0),
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    deleted_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    deleted_at TIMESTAMP WITH TIME ZONE,
+    UNIQUE(course_id, name)
+);
+
+ALTER TABLE assignments
+    ADD COLUMN category_id UUID REFERENCES assignment_categories(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_assignment_categories_course_id ON assignment_categories(course_id);
+CREATE INDEX idx_assignments_category_id ON assignments(category_id);
+
+CREATE TRIGGER update_assignment_categories_modtime BEFORE UPDATE ON assignment_categories FOR EACH ROW EXECUTE FUNCTION update_modified_column();
@@ -0,0 +1,4 @@
+ALTER TABLE courses DROP COLUMN IF EXISTS grading_scheme_id;
+
+DROP TABLE IF EXISTS grading_scheme_bands;
+DROP TABLE IF EXISTS grading_schemes;
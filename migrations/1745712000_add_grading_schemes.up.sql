@@ -0,0 +1,76 @@
+CREATE TABLE grading_schemes (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(100) NOT NULL UNIQUE,
+    type VARCHAR(20) NOT NULL CHECK (type IN ('letter', 'pass_fail')),
+    description TEXT,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    deleted_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+-- A percentage gets the band with the highest min_percentage it reaches
+CREATE TABLE grading_scheme_bands (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    scheme_id UUID NOT NULL REFERENCES grading_schemes(id) ON DELETE CASCADE,
+    label VARCHAR(20) NOT NULL,
+    min_percentage DECIMAL(5,2) NOT NULL CHECK (min_percentage >= 0 AND min_percentage <= 100),
+    -- NULL for bands that do not count towards the GPA, such as pass and fail
+    grade_points DECIMAL(3,2),
+    is_passing BOOLEAN NOT NULL DEFAULT TRUE,
+    position INTEGER NOT NULL,
+    UNIQUE(scheme_id, min_percentage),
+    UNIQUE(scheme_id, position)
+);
+
+ALTER TABLE courses
+    ADD COLUMN grading_scheme_id UUID REFERENCES grading_schemes(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_grading_scheme_bands_scheme_id ON grading_scheme_bands(scheme_id);
+
+CREATE TRIGGER update_grading_schemes_modtime BEFORE UPDATE ON grading_schemes FOR EACH ROW EXECUTE FUNCTION update_modified_column();
+
+-- Built-in schemes
+WITH scheme AS (
+    INSERT INTO grading_schemes (name, type, description)
+    VALUES ('Letter (A-F)', 'letter', 'US style letter grades on a 4.0 scale')
+    RETURNING id
+)
+INSERT INTO grading_scheme_bands (scheme_id, label, min_percentage, grade_points, is_passing, position)
+SELECT scheme.id, band.label, band.min_percentage, band.grade_points, band.is_passing, band.position
+FROM scheme, (VALUES
+    ('A', 90, 4.0, TRUE, 1),
+    ('B', 80, 3.0, TRUE, 2),
+    ('C', 70, 2.0, TRUE, 3),
+    ('D', 60, 1.0, TRUE, 4),
+    ('F', 0, 0.0, FALSE, 5)
+) AS band(label, min_percentage, grade_points, is_passing, position);
+
+WITH scheme AS (
+    INSERT INTO grading_schemes (name, type, description)
+    VALUES ('Pass/Fail', 'pass_fail', 'Pass from 60 percent, not counted in the GPA')
+    RETURNING id
+)
+INSERT INTO grading_scheme_bands (scheme_id, label, min_percentage, grade_points, is_passing, position)
+SELECT scheme.id, band.label, band.min_percentage, NULL, band.is_passing, band.position
+FROM scheme, (VALUES
+    ('Pass', 60, TRUE, 1),
+    ('Fail', 0, FALSE, 2)
+) AS band(label, min_percentage, is_passing, position);
+
+WITH scheme AS (
+    INSERT INTO grading_schemes (name, type, description)
+    VALUES ('Indonesia (A-E)', 'letter', 'Indonesian 0-100 scores mapped to A-E on a 4.0 scale')
+    RETURNING id
+)
+INSERT INTO grading_scheme_bands (scheme_id, label, min_percentage, grade_points, is_passing, position)
+SELECT scheme.id, band.label, band.min_percentage, band.grade_points, band.is_passing, band.position
+FROM scheme, (VALUES
+    ('A', 85, 4.0, TRUE, 1),
+    ('B', 70, 3.0, TRUE, 2),
+    ('C', 55, 2.0, TRUE, 3),
+    ('D', 40, 1.0, FALSE, 4),
+    ('E', 0, 0.0, FALSE, 5)
+) AS band(label, min_percentage, grade_points, is_passing, position);
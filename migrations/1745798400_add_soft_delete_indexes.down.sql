@@ -0,0 +1,13 @@
+DROP INDEX IF EXISTS idx_enrollments_course_id_deleted_at;
+DROP INDEX IF EXISTS idx_submissions_assignment_id_deleted_at;
+DROP INDEX IF EXISTS idx_assignments_course_id_deleted_at;
+
+-- Fails while a deleted row shares its unique values with a live one, purge those first
+DROP INDEX IF EXISTS idx_submissions_assignment_student_live;
+ALTER TABLE submissions ADD CONSTRAINT submissions_assignment_id_student_id_key UNIQUE (assignment_id, student_id);
+
+DROP INDEX IF EXISTS idx_courses_code_live;
+ALTER TABLE courses ADD CONSTRAINT courses_code_key UNIQUE (code);
+
+DROP INDEX IF EXISTS idx_users_email_live;
+ALTER TABLE users ADD CONSTRAINT users_email_key UNIQUE (email);
@@ -0,0 +1,15 @@
+-- Soft-deleted rows keep their unique values, so uniqueness only applies to live rows. This lets a deleted
+-- email, course code or submission be created again, restoring the old row then fails with a conflict.
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_email_key;
+CREATE UNIQUE INDEX idx_users_email_live ON users(email) WHERE deleted_at IS NULL;
+
+ALTER TABLE courses DROP CONSTRAINT IF EXISTS courses_code_key;
+CREATE UNIQUE INDEX idx_courses_code_live ON courses(code) WHERE deleted_at IS NULL;
+
+ALTER TABLE submissions DROP CONSTRAINT IF EXISTS submissions_assignment_id_student_id_key;
+CREATE UNIQUE INDEX idx_submissions_assignment_student_live ON submissions(assignment_id, student_id) WHERE deleted_at IS NULL;
+
+-- Children deleted with their parent are restored by matching the parent's deleted_at
+CREATE INDEX idx_assignments_course_id_deleted_at ON assignments(course_id, deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX idx_submissions_assignment_id_deleted_at ON submissions(assignment_id, deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX idx_enrollments_course_id_deleted_at ON enrollments(course_id, deleted_at) WHERE deleted_at IS NOT NULL;
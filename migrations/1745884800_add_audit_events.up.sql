@@ -0,0 +1,45 @@
+-- Append-only history of changes. Every event stores the hash of the event before it, so editing or removing
+-- a row breaks the chain from that point on. Actors and entities are not foreign keys because the events
+-- must outlive purged records.
+CREATE TABLE audit_events (
+    seq BIGINT PRIMARY KEY,
+    id UUID NOT NULL UNIQUE DEFAULT uuid_generate_v4(),
+    actor_id UUID,
+    actor_role VARCHAR(20),
+    action VARCHAR(50) NOT NULL,
+    entity_type VARCHAR(50) NOT NULL,
+    entity_id UUID NOT NULL,
+    -- course the entity belongs to, teachers read the events of their courses
+    course_id UUID,
+    -- JSON rather than JSONB keeps the text exactly as it was hashed
+    before JSON,
+    after JSON,
+    ip VARCHAR(45),
+    request_id TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    prev_hash CHAR(64) NOT NULL,
+    hash CHAR(64) NOT NULL UNIQUE
+);
+
+-- Single row holding the tip of the chain. Appending locks it, which orders concurrent writers.
+CREATE TABLE audit_chain_head (
+    id BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (id),
+    seq BIGINT NOT NULL,
+    hash CHAR(64) NOT NULL
+);
+
+INSERT INTO audit_chain_head (seq, hash) VALUES (0, repeat('0', 64));
+
+CREATE INDEX idx_audit_events_entity ON audit_events(entity_type, entity_id, seq);
+CREATE INDEX idx_audit_events_actor_id ON audit_events(actor_id, seq);
+CREATE INDEX idx_audit_events_course_id ON audit_events(course_id, seq);
+
+CREATE OR REPLACE FUNCTION reject_audit_event_change()
+RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_events_append_only BEFORE UPDATE OR DELETE ON audit_events FOR EACH ROW EXECUTE FUNCTION reject_audit_event_change();
+CREATE TRIGGER audit_events_no_truncate BEFORE TRUNCATE ON audit_events FOR EACH STATEMENT EXECUTE FUNCTION reject_audit_event_change();
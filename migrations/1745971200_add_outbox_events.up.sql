@@ -0,0 +1,21 @@
+-- Domain events are written here in the transaction of the change that raised them and handed to the
+-- in-process subscribers by the dispatcher once committed
+CREATE TABLE outbox_events (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    event_type VARCHAR(100) NOT NULL,
+    aggregate_type VARCHAR(50) NOT NULL,
+    aggregate_id UUID NOT NULL,
+    payload JSONB NOT NULL,
+    actor_id UUID,
+    occurred_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    -- failed deliveries are retried from this time on
+    available_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    dispatched_at TIMESTAMP WITH TIME ZONE,
+    -- set once the event ran out of attempts, it is no longer picked up
+    failed_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_outbox_events_pending ON outbox_events(available_at, occurred_at) WHERE dispatched_at IS NULL AND failed_at IS NULL;
+CREATE INDEX idx_outbox_events_aggregate ON outbox_events(aggregate_type, aggregate_id);
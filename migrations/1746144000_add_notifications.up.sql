@@ -0,0 +1,27 @@
+-- In-app notifications, created by the subscribers of the domain events
+CREATE TABLE notifications (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type VARCHAR(50) NOT NULL,
+    title TEXT NOT NULL,
+    body TEXT NOT NULL DEFAULT '',
+    -- ids of the records the notification links to, such as the course and assignment
+    data JSONB NOT NULL DEFAULT '{}',
+    -- the domain event that raised the notification, an event notifies a user once however often it is delivered
+    event_id UUID,
+    read_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE(user_id, event_id)
+);
+
+-- Types a user turned on or off, types without a row are on
+CREATE TABLE notification_preferences (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type VARCHAR(50) NOT NULL,
+    in_app BOOLEAN NOT NULL DEFAULT TRUE,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY(user_id, type)
+);
+
+CREATE INDEX idx_notifications_user_id ON notifications(user_id, created_at);
+CREATE INDEX idx_notifications_unread ON notifications(user_id) WHERE read_at IS NULL;
@@ -0,0 +1,28 @@
+-- Outgoing emails, rendered when they are queued and sent by the mail queue
+CREATE TABLE email_messages (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    to_address TEXT NOT NULL,
+    template VARCHAR(50) NOT NULL,
+    subject TEXT NOT NULL,
+    html_body TEXT NOT NULL,
+    text_body TEXT NOT NULL,
+    -- the domain event that queued the email, an event emails an address once however often it is delivered
+    event_id UUID,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'sent', 'dead')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    -- pending emails are sent from this time on, a claimed email is leased by moving it forward
+    next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    last_error TEXT,
+    sent_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE(to_address, event_id)
+);
+
+-- Email is a second channel of the notification types, on unless the user turned it off
+ALTER TABLE notification_preferences ADD COLUMN email BOOLEAN NOT NULL DEFAULT TRUE;
+
+CREATE INDEX idx_email_messages_pending ON email_messages(next_attempt_at) WHERE status = 'pending';
+
+CREATE TRIGGER update_email_messages_modtime BEFORE UPDATE ON email_messages FOR EACH ROW EXECUTE FUNCTION update_modified_column();
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_assignments_due_date_published;
+DROP TABLE IF EXISTS scheduled_job_runs;
@@ -0,0 +1,21 @@
+-- History of the scheduled jobs, a run is recorded when it finishes. Every replica computes the same scheduled_at
+-- for a firing of a job, so each firing runs on one replica only.
+CREATE TABLE scheduled_job_runs (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    job_name VARCHAR(100) NOT NULL,
+    scheduled_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('succeeded', 'failed')),
+    -- what the run did, such as the number of reminders it queued
+    summary TEXT NOT NULL DEFAULT '',
+    error TEXT,
+    -- the replica that ran the job
+    instance VARCHAR(255) NOT NULL DEFAULT '',
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    finished_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    UNIQUE(job_name, scheduled_at)
+);
+
+CREATE INDEX idx_scheduled_job_runs_started_at ON scheduled_job_runs(started_at);
+
+-- due date reminders look up the published assignments due in the next day
+CREATE INDEX idx_assignments_due_date_published ON assignments(due_date) WHERE is_published AND deleted_at IS NULL;
@@ -0,0 +1,28 @@
+-- TOTP two-factor authentication, a row exists from enrollment and enabled_at is set once the user confirmed a
+-- code. The secret is sealed with AES-GCM under a key derived from APP_SECRET.
+CREATE TABLE user_mfa (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    secret TEXT NOT NULL,
+    enabled_at TIMESTAMP WITH TIME ZONE,
+    -- the time step of the last accepted code, a code is accepted once
+    last_used_step BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- One-time recovery codes for a lost authenticator, only their SHA-256 hash is stored
+CREATE TABLE mfa_recovery_codes (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    code_hash VARCHAR(64) NOT NULL,
+    used_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE(user_id, code_hash)
+);
+
+-- the login challenge between the password and the second factor is a user token, attempts caps the codes tried
+ALTER TABLE user_tokens ADD COLUMN attempts INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE user_tokens DROP CONSTRAINT user_tokens_purpose_check;
+ALTER TABLE user_tokens ADD CONSTRAINT user_tokens_purpose_check CHECK (purpose IN ('password_reset', 'email_verification', 'mfa_challenge'));
+
+CREATE TRIGGER update_user_mfa_modtime BEFORE UPDATE ON user_mfa FOR EACH ROW EXECUTE FUNCTION update_modified_column();
@@ -0,0 +1,4 @@
+DELETE FROM jobs WHERE type IN ('send_email', 'deliver_webhook');
+
+CREATE INDEX idx_email_messages_pending ON email_messages(next_attempt_at) WHERE status = 'pending';
+CREATE INDEX idx_webhook_deliveries_pending ON webhook_deliveries(next_attempt_at) WHERE status = 'pending';
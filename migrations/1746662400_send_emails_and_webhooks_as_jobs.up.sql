@@ -0,0 +1,15 @@
+-- Emails and webhook deliveries are sent by jobs of the job queue, which schedules their attempts. The jobs copy
+-- their status, attempts and next attempt back to the rows.
+DROP INDEX IF EXISTS idx_email_messages_pending;
+DROP INDEX IF EXISTS idx_webhook_deliveries_pending;
+
+-- the emails and deliveries still pending get the job that sends them, with the default attempts of their kind
+INSERT INTO jobs (type, payload, max_attempts, run_at)
+SELECT 'send_email', jsonb_build_object('email_id', id), 5, next_attempt_at
+FROM email_messages
+WHERE status = 'pending';
+
+INSERT INTO jobs (type, payload, max_attempts, run_at)
+SELECT 'deliver_webhook', jsonb_build_object('delivery_id', id), 8, next_attempt_at
+FROM webhook_deliveries
+WHERE status = 'pending';
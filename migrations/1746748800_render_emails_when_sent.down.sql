@@ -0,0 +1,7 @@
+ALTER TABLE email_messages ADD COLUMN subject TEXT NOT NULL DEFAULT '';
+ALTER TABLE email_messages ADD COLUMN html_body TEXT NOT NULL DEFAULT '';
+ALTER TABLE email_messages ADD COLUMN text_body TEXT NOT NULL DEFAULT '';
+
+DROP INDEX IF EXISTS idx_email_messages_user_token_id;
+ALTER TABLE email_messages DROP COLUMN IF EXISTS user_token_id;
+ALTER TABLE email_messages DROP COLUMN IF EXISTS data;
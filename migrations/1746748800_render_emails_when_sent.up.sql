@@ -0,0 +1,16 @@
+-- Emails keep the data of their template and are rendered when they are sent, so the single-use token in a reset
+-- or verification link is never stored. The link is derived from the token the email points to, purging the token
+-- purges the email.
+ALTER TABLE email_messages ADD COLUMN data JSONB NOT NULL DEFAULT '{}';
+ALTER TABLE email_messages ADD COLUMN user_token_id UUID REFERENCES user_tokens(id) ON DELETE CASCADE;
+
+CREATE INDEX idx_email_messages_user_token_id ON email_messages(user_token_id) WHERE user_token_id IS NOT NULL;
+
+-- the emails still pending were queued with their bodies only and cannot be rendered again
+UPDATE email_messages
+SET status = 'dead', last_error = 'queued before emails were rendered when they are sent'
+WHERE status = 'pending';
+
+ALTER TABLE email_messages DROP COLUMN subject;
+ALTER TABLE email_messages DROP COLUMN html_body;
+ALTER TABLE email_messages DROP COLUMN text_body;
@@ -0,0 +1,72 @@
+package mailer
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Limiter is a token bucket that keeps sends under a rate per minute, letting up to burst sends through back to
+// back after a quiet period
+type Limiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	burst    float64
+	tokens   float64
+	last     time.Time
+}
+
+// NewLimiter returns a limiter of rate sends per minute, a rate of zero or less does not limit
+func NewLimiter(rate int, burst int) *Limiter {
+	if rate <= 0 {
+		return &Limiter{}
+	}
+	burst = max(burst, 1)
+	return &Limiter{
+		interval: time.Minute / time.Duration(rate),
+		burst:    float64(burst),
+		tokens:   float64(burst),
+	}
+}
+
+// Interval is the time between two sends at the full rate
+func (l *Limiter) Interval() time.Duration {
+	return l.interval
+}
+
+// Wait blocks until the next send is allowed or ctx is done
+func (l *Limiter) Wait(ctx context.Context) error {
+	delay := l.reserve(time.Now())
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// reserve takes a token and returns how long to wait for it, waiting callers hold negative tokens so they are
+// served in turn
+func (l *Limiter) reserve(now time.Time) time.Duration {
+	if l.interval == 0 {
+		return 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.last.IsZero() {
+		l.tokens = min(l.burst, l.tokens+float64(now.Sub(l.last))/float64(l.interval))
+	}
+	l.last = now
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens * float64(l.interval))
+}
@@ -0,0 +1,55 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// LogSender is the development backend, it logs every message and keeps a copy as an .eml file when a directory
+// is set, which any mail client opens
+type LogSender struct {
+	from   string
+	dir    string
+	logger *zap.SugaredLogger
+}
+
+func NewLogSender(from string, dir string, logger *zap.SugaredLogger) (*LogSender, error) {
+	if logger == nil {
+		logger = zap.NewNop().Sugar()
+	}
+	if dir != "" {
+		var err error
+		if dir, err = filepath.Abs(dir); err != nil {
+			return nil, err
+		}
+		if err = os.MkdirAll(dir, 0o750); err != nil {
+			return nil, err
+		}
+	}
+	return &LogSender{from: from, dir: dir, logger: logger}, nil
+}
+
+func (s *LogSender) Send(ctx context.Context, msg Message) error {
+	now := time.Now()
+	body, err := Encode(s.from, msg, now)
+	if err != nil {
+		return &PermanentError{Err: err}
+	}
+
+	if s.dir == "" {
+		s.logger.Infof("email %s to %s: %s\n%s", msg.ID, msg.To, msg.Subject, msg.Text)
+		return nil
+	}
+
+	path := filepath.Join(s.dir, fmt.Sprintf("%s-%s.eml", now.Format("20060102T150405"), msg.ID))
+	if err = os.WriteFile(path, body, 0o640); err != nil {
+		return err
+	}
+	s.logger.Infof("email %s to %s: %s, saved to %s", msg.ID, msg.To, msg.Subject, path)
+	return nil
+}
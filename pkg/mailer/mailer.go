@@ -0,0 +1,136 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	DriverLog  = "log"
+	DriverSMTP = "smtp"
+)
+
+// Message is one rendered email to a single recipient, it is sent as a multipart message with a plain text and
+// an HTML alternative
+type Message struct {
+	ID      string
+	To      string
+	Subject string
+	HTML    string
+	Text    string
+}
+
+// Sender hands messages over to the mail transport, errors that retrying cannot fix are wrapped in
+// PermanentError
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// PermanentError is a rejection of the message itself, such as an unknown recipient
+type PermanentError struct {
+	Err error
+}
+
+func (e *PermanentError) Error() string { return e.Err.Error() }
+func (e *PermanentError) Unwrap() error { return e.Err }
+
+// IsPermanent reports whether sending the message again is pointless
+func IsPermanent(err error) bool {
+	var e *PermanentError
+	return errors.As(err, &e)
+}
+
+type Option struct {
+	Driver string
+	// From is the sender of every message, such as "Edukita LMS <no-reply@edukita.id>"
+	From   string
+	LogDir string
+	SMTP   SMTPOption
+	Logger *zap.SugaredLogger
+}
+
+// NewSender builds the backend selected by the driver option
+func NewSender(opt Option) (Sender, error) {
+	if _, err := mail.ParseAddress(opt.From); err != nil {
+		return nil, fmt.Errorf("mailer: invalid from address %q: %w", opt.From, err)
+	}
+
+	switch opt.Driver {
+	case DriverLog, "":
+		return NewLogSender(opt.From, opt.LogDir, opt.Logger)
+	case DriverSMTP:
+		return NewSMTPSender(opt.From, opt.SMTP)
+	default:
+		return nil, fmt.Errorf("unknown mail driver: %s", opt.Driver)
+	}
+}
+
+// Encode renders the message in the MIME format sent over SMTP
+func Encode(from string, msg Message, now time.Time) ([]byte, error) {
+	sender, err := mail.ParseAddress(from)
+	if err != nil {
+		return nil, err
+	}
+	recipient, err := mail.ParseAddress(msg.To)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	parts := multipart.NewWriter(&buf)
+
+	// the subject is user content, Q-encoding also keeps line breaks out of the header
+	header := []string{
+		"From: " + sender.String(),
+		"To: " + recipient.String(),
+		"Subject: " + mime.QEncoding.Encode("utf-8", msg.Subject),
+		"Date: " + now.Format(time.RFC1123Z),
+		"Message-ID: <" + msg.ID + "@" + domain(sender.Address) + ">",
+		"MIME-Version: 1.0",
+		"Content-Type: multipart/alternative; boundary=" + parts.Boundary(),
+	}
+	buf.WriteString(strings.Join(header, "\r\n") + "\r\n\r\n")
+
+	// clients show the last alternative they support, so HTML comes after the plain text
+	for _, part := range []struct{ contentType, body string }{
+		{"text/plain; charset=utf-8", msg.Text},
+		{"text/html; charset=utf-8", msg.HTML},
+	} {
+		w, err := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		qp := quotedprintable.NewWriter(w)
+		if _, err = qp.Write([]byte(part.body)); err != nil {
+			return nil, err
+		}
+		if err = qp.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err = parts.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func domain(address string) string {
+	if i := strings.LastIndexByte(address, '@'); i >= 0 {
+		return address[i+1:]
+	}
+	return "localhost"
+}
@@ -0,0 +1,120 @@
+package mailer
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"time"
+)
+
+type SMTPOption struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	// StartTLS requires the server to upgrade the connection, without it TLS is still used when offered
+	StartTLS bool
+	Timeout  time.Duration
+}
+
+// SMTPSender delivers every message over its own connection to an SMTP relay, such as the mail provider or a
+// local MailHog
+type SMTPSender struct {
+	from     string
+	envelope string
+	opt      SMTPOption
+}
+
+func NewSMTPSender(from string, opt SMTPOption) (*SMTPSender, error) {
+	sender, err := mail.ParseAddress(from)
+	if err != nil {
+		return nil, err
+	}
+	if opt.Host == "" {
+		return nil, fmt.Errorf("mailer: smtp host is required")
+	}
+	if opt.Timeout <= 0 {
+		opt.Timeout = 10 * time.Second
+	}
+	return &SMTPSender{from: from, envelope: sender.Address, opt: opt}, nil
+}
+
+func (s *SMTPSender) Send(ctx context.Context, msg Message) (err error) {
+	recipient, err := mail.ParseAddress(msg.To)
+	if err != nil {
+		return &PermanentError{Err: err}
+	}
+	body, err := Encode(s.from, msg, time.Now())
+	if err != nil {
+		return &PermanentError{Err: err}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.opt.Timeout)
+	defer cancel()
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(s.opt.Host, strconv.Itoa(s.opt.Port)))
+	if err != nil {
+		return
+	}
+	// the timeout covers the whole conversation, not only the dial
+	deadline, _ := ctx.Deadline()
+	if err = conn.SetDeadline(deadline); err != nil {
+		conn.Close()
+		return
+	}
+
+	client, err := smtp.NewClient(conn, s.opt.Host)
+	if err != nil {
+		conn.Close()
+		return
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err = client.StartTLS(&tls.Config{ServerName: s.opt.Host}); err != nil {
+			return
+		}
+	} else if s.opt.StartTLS {
+		return fmt.Errorf("mailer: %s does not support STARTTLS", s.opt.Host)
+	}
+
+	if s.opt.Username != "" {
+		if err = client.Auth(smtp.PlainAuth("", s.opt.Username, s.opt.Password, s.opt.Host)); err != nil {
+			return
+		}
+	}
+
+	if err = client.Mail(s.envelope); err != nil {
+		return permanent(err)
+	}
+	if err = client.Rcpt(recipient.Address); err != nil {
+		return permanent(err)
+	}
+	w, err := client.Data()
+	if err != nil {
+		return
+	}
+	if _, err = w.Write(body); err != nil {
+		return
+	}
+	if err = w.Close(); err != nil {
+		return permanent(err)
+	}
+	return client.Quit()
+}
+
+// permanent marks 5xx replies of the server as permanent, 4xx replies are worth retrying
+func permanent(err error) error {
+	var reply *textproto.Error
+	if errors.As(err, &reply) && reply.Code >= 500 {
+		return &PermanentError{Err: err}
+	}
+	return err
+}
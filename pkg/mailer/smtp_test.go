@@ -0,0 +1,248 @@
+package mailer
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeSMTP is a MailHog style stand-in that accepts every message, or rejects recipients with reject
+type fakeSMTP struct {
+	listener net.Listener
+	reject   string
+	received chan receivedMail
+}
+
+type receivedMail struct {
+	from string
+	to   []string
+	data string
+}
+
+func newFakeSMTP(t *testing.T) *fakeSMTP {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listening: %v", err)
+	}
+	server := &fakeSMTP{listener: listener, received: make(chan receivedMail, 10)}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go server.serve(conn)
+		}
+	}()
+	return server
+}
+
+func (f *fakeSMTP) option() SMTPOption {
+	addr := f.listener.Addr().(*net.TCPAddr)
+	return SMTPOption{Host: addr.IP.String(), Port: addr.Port, Timeout: 5 * time.Second}
+}
+
+func (f *fakeSMTP) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { io.WriteString(conn, line+"\r\n") }
+
+	var msg receivedMail
+	reply("220 fake ESMTP")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		verb := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+
+		switch verb {
+		case "EHLO", "HELO":
+			reply("250-fake")
+			reply("250 8BITMIME")
+		case "MAIL":
+			msg = receivedMail{from: between(line, "<", ">")}
+			reply("250 ok")
+		case "RCPT":
+			to := between(line, "<", ">")
+			if to == f.reject {
+				reply("550 no such user")
+				continue
+			}
+			msg.to = append(msg.to, to)
+			reply("250 ok")
+		case "DATA":
+			reply("354 end with .")
+			var data strings.Builder
+			for {
+				line, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if line == ".\r\n" {
+					break
+				}
+				data.WriteString(strings.TrimPrefix(line, "."))
+			}
+			msg.data = data.String()
+			f.received <- msg
+			reply("250 queued")
+		case "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("250 ok")
+		}
+	}
+}
+
+func between(s string, open string, close string) string {
+	start := strings.Index(s, open)
+	end := strings.LastIndex(s, close)
+	if start < 0 || end <= start {
+		return ""
+	}
+	return s[start+1 : end]
+}
+
+func TestSMTPSenderSend(t *testing.T) {
+	server := newFakeSMTP(t)
+	sender, err := NewSMTPSender("Edukita LMS <no-reply@edukita.test>", server.option())
+	if err != nil {
+		t.Fatalf("creating sender: %v", err)
+	}
+
+	msg := Message{
+		ID:      "3f1c",
+		To:      "siti@example.com",
+		Subject: "Nilai: Esai \r\nBcc: someone@example.com",
+		HTML:    "<p>You scored <strong>42</strong></p>",
+		Text:    "You scored 42, a line long enough to be wrapped by the quoted printable encoding of the text body part",
+	}
+	if err = sender.Send(context.Background(), msg); err != nil {
+		t.Fatalf("sending: %v", err)
+	}
+
+	var received receivedMail
+	select {
+	case received = <-server.received:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no message received")
+	}
+	if received.from != "no-reply@edukita.test" || len(received.to) != 1 || received.to[0] != msg.To {
+		t.Errorf("envelope from %s to %v", received.from, received.to)
+	}
+
+	parsed, err := mail.ReadMessage(strings.NewReader(received.data))
+	if err != nil {
+		t.Fatalf("parsing message: %v", err)
+	}
+	if parsed.Header.Get("Bcc") != "" {
+		t.Error("subject injected a header")
+	}
+	subject, _ := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+	if subject != msg.Subject {
+		t.Errorf("subject %q, want %q", subject, msg.Subject)
+	}
+	if parsed.Header.Get("Message-ID") != "<3f1c@edukita.test>" {
+		t.Errorf("message id %s", parsed.Header.Get("Message-ID"))
+	}
+
+	mediaType, params, err := mime.ParseMediaType(parsed.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("content type %s: %v", mediaType, err)
+	}
+	parts := multipart.NewReader(parsed.Body, params["boundary"])
+	for _, want := range []struct{ contentType, body string }{
+		{"text/plain; charset=utf-8", msg.Text},
+		{"text/html; charset=utf-8", msg.HTML},
+	} {
+		part, err := parts.NextRawPart()
+		if err != nil {
+			t.Fatalf("reading %s part: %v", want.contentType, err)
+		}
+		body, _ := io.ReadAll(quotedprintable.NewReader(part))
+		if part.Header.Get("Content-Type") != want.contentType || string(body) != want.body {
+			t.Errorf("part %s: %q", part.Header.Get("Content-Type"), body)
+		}
+	}
+}
+
+func TestSMTPSenderRejectedRecipient(t *testing.T) {
+	server := newFakeSMTP(t)
+	server.reject = "gone@example.com"
+	sender, _ := NewSMTPSender("no-reply@edukita.test", server.option())
+
+	err := sender.Send(context.Background(), Message{ID: "1", To: "gone@example.com", Subject: "Hi"})
+	if err == nil || !IsPermanent(err) {
+		t.Errorf("got %v, want a permanent error", err)
+	}
+}
+
+func TestSMTPSenderUnreachable(t *testing.T) {
+	server := newFakeSMTP(t)
+	opt := server.option()
+	server.listener.Close()
+
+	sender, _ := NewSMTPSender("no-reply@edukita.test", opt)
+	err := sender.Send(context.Background(), Message{ID: "1", To: "siti@example.com", Subject: "Hi"})
+	if err == nil || IsPermanent(err) {
+		t.Errorf("got %v, want a temporary error", err)
+	}
+}
+
+func TestSMTPSenderRequiresStartTLS(t *testing.T) {
+	server := newFakeSMTP(t)
+	opt := server.option()
+	opt.StartTLS = true
+
+	sender, _ := NewSMTPSender("no-reply@edukita.test", opt)
+	err := sender.Send(context.Background(), Message{ID: "1", To: "siti@example.com", Subject: "Hi"})
+	if err == nil || !strings.Contains(err.Error(), "STARTTLS") {
+		t.Errorf("got %v, want the missing STARTTLS reported", err)
+	}
+}
+
+func TestNewSender(t *testing.T) {
+	if _, err := NewSender(Option{Driver: DriverSMTP, From: "not an address", SMTP: SMTPOption{Host: "localhost"}}); err == nil {
+		t.Error("invalid from address accepted")
+	}
+	if _, err := NewSender(Option{Driver: "carrier-pigeon", From: "no-reply@edukita.test"}); err == nil {
+		t.Error("unknown driver accepted")
+	}
+	sender, err := NewSender(Option{Driver: DriverSMTP, From: "no-reply@edukita.test", SMTP: SMTPOption{Host: "localhost", Port: 1025}})
+	if _, ok := sender.(*SMTPSender); err != nil || !ok {
+		t.Errorf("got %T, %v", sender, err)
+	}
+}
+
+func TestLogSenderSavesMessages(t *testing.T) {
+	dir := t.TempDir()
+	sender, err := NewLogSender("no-reply@edukita.test", dir, nil)
+	if err != nil {
+		t.Fatalf("creating sender: %v", err)
+	}
+	if err = sender.Send(context.Background(), Message{ID: "42", To: "siti@example.com", Subject: "Hi", Text: "hello"}); err != nil {
+		t.Fatalf("sending: %v", err)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*-42.eml"))
+	if len(files) != 1 {
+		t.Fatalf("saved %v, want one message", files)
+	}
+	if err = sender.Send(context.Background(), Message{ID: "43", To: "not an address"}); !IsPermanent(err) {
+		t.Errorf("got %v, want a permanent error", err)
+	}
+}
@@ -0,0 +1,185 @@
+package mailer
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+	"time"
+)
+
+const (
+	TemplateWelcome             = "welcome"
+	TemplatePasswordReset       = "password_reset"
+	TemplateEmailVerification   = "email_verification"
+	TemplateAssignmentPublished = "assignment_published"
+	TemplateGradePosted         = "grade_posted"
+	TemplateDueSoon             = "due_soon"
+)
+
+// Names lists every template, each has a <name>.txt with the subject and text body and a <name>.html body
+var Names = []string{
+	TemplateWelcome,
+	TemplatePasswordReset,
+	TemplateEmailVerification,
+	TemplateAssignmentPublished,
+	TemplateGradePosted,
+	TemplateDueSoon,
+}
+
+//go:embed templates
+var templateFiles embed.FS
+
+type (
+	WelcomeData struct {
+		FirstName string
+		Role      string
+	}
+	// PasswordResetData and EmailVerificationData are queued without their token, it is set when they are sent
+	PasswordResetData struct {
+		FirstName        string
+		Token            string `json:"-"`
+		ExpiresInMinutes int
+	}
+	EmailVerificationData struct {
+		FirstName      string
+		Token          string `json:"-"`
+		ExpiresInHours int
+	}
+	AssignmentPublishedData struct {
+		FirstName       string
+		CourseID        string
+		CourseName      string
+		AssignmentID    string
+		AssignmentTitle string
+		DueDate         time.Time
+	}
+	// GradePostedData covers feedback without a grade as well, Grade is nil then
+	GradePostedData struct {
+		FirstName       string
+		CourseID        string
+		AssignmentID    string
+		AssignmentTitle string
+		Grade           *float64
+		TotalPoints     float64
+		HasFeedback     bool
+	}
+	DueSoonData struct {
+		FirstName       string
+		CourseID        string
+		CourseName      string
+		AssignmentID    string
+		AssignmentTitle string
+		DueDate         time.Time
+		// TimeLeft reads naturally after "due in", such as "24 hours"
+		TimeLeft string
+	}
+)
+
+// TokenData is the data of a template whose link carries a single-use token
+type TokenData interface {
+	SetToken(token string)
+}
+
+func (d *PasswordResetData) SetToken(token string)     { d.Token = token }
+func (d *EmailVerificationData) SetToken(token string) { d.Token = token }
+
+// Data returns a pointer to empty data of the named template, to decode the data an email was queued with into
+func Data(name string) (any, error) {
+	switch name {
+	case TemplateWelcome:
+		return &WelcomeData{}, nil
+	case TemplatePasswordReset:
+		return &PasswordResetData{}, nil
+	case TemplateEmailVerification:
+		return &EmailVerificationData{}, nil
+	case TemplateAssignmentPublished:
+		return &AssignmentPublishedData{}, nil
+	case TemplateGradePosted:
+		return &GradePostedData{}, nil
+	case TemplateDueSoon:
+		return &DueSoonData{}, nil
+	}
+	return nil, fmt.Errorf("mailer: unknown template %s", name)
+}
+
+// view is what the templates execute on, Data is the data of the template
+type view struct {
+	AppName string
+	AppURL  string
+	Data    any
+}
+
+// Templates renders the embedded email templates, every message shares the layout of its format
+type Templates struct {
+	appName string
+	appURL  string
+	html    map[string]*htmltemplate.Template
+	text    map[string]*texttemplate.Template
+}
+
+var templateFuncs = map[string]any{
+	"date": func(t time.Time) string {
+		return t.Format("Monday, 2 Jan 2006 15:04 MST")
+	},
+	// points formats a score, grades are pointers as they may be missing
+	"points": func(points any) string {
+		if p, ok := points.(*float64); ok {
+			points = *p
+		}
+		return fmt.Sprintf("%.2f", points)
+	},
+}
+
+// NewTemplates parses the templates once, appURL is the base of the links in the messages
+func NewTemplates(appName string, appURL string) (*Templates, error) {
+	t := &Templates{
+		appName: appName,
+		appURL:  strings.TrimRight(appURL, "/"),
+		html:    make(map[string]*htmltemplate.Template, len(Names)),
+		text:    make(map[string]*texttemplate.Template, len(Names)),
+	}
+
+	for _, name := range Names {
+		html, err := htmltemplate.New(name).Funcs(templateFuncs).ParseFS(templateFiles, "templates/layout.html", "templates/"+name+".html")
+		if err != nil {
+			return nil, err
+		}
+		text, err := texttemplate.New(name).Funcs(templateFuncs).ParseFS(templateFiles, "templates/layout.txt", "templates/"+name+".txt")
+		if err != nil {
+			return nil, err
+		}
+		t.html[name], t.text[name] = html, text
+	}
+	return t, nil
+}
+
+// Render fills the subject and bodies of a message from the named template, the caller sets the recipient
+func (t *Templates) Render(name string, data any) (msg Message, err error) {
+	html, ok := t.html[name]
+	if !ok {
+		return msg, fmt.Errorf("mailer: unknown template %s", name)
+	}
+	v := view{AppName: t.appName, AppURL: t.appURL, Data: data}
+
+	var buf bytes.Buffer
+	if err = t.text[name].ExecuteTemplate(&buf, "subject", v); err != nil {
+		return
+	}
+	msg.Subject = strings.Join(strings.Fields(buf.String()), " ")
+
+	buf.Reset()
+	if err = t.text[name].ExecuteTemplate(&buf, "layout", v); err != nil {
+		return
+	}
+	msg.Text = buf.String()
+
+	buf.Reset()
+	if err = html.ExecuteTemplate(&buf, "layout", v); err != nil {
+		return
+	}
+	msg.HTML = buf.String()
+	return
+}
@@ -0,0 +1,110 @@
+package mailer
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTemplatesRender(t *testing.T) {
+	templates, err := NewTemplates("Edukita LMS", "https://lms.edukita.test/")
+	if err != nil {
+		t.Fatalf("parsing templates: %v", err)
+	}
+
+	grade := 42.5
+	due := time.Date(2025, 5, 2, 17, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		data    any
+		subject string
+		text    []string
+	}{
+		{TemplateWelcome, WelcomeData{FirstName: "Siti", Role: "student"}, "Welcome to Edukita LMS", []string{"Hi Siti", "https://lms.edukita.test/login"}},
+		{TemplatePasswordReset, PasswordResetData{FirstName: "Siti", Token: "abc", ExpiresInMinutes: 30}, "Reset your Edukita LMS password", []string{"reset-password?token=abc", "30 minutes"}},
+		{TemplateEmailVerification, EmailVerificationData{FirstName: "Siti", Token: "abc", ExpiresInHours: 48}, "Verify your email address for Edukita LMS", []string{"verify-email?token=abc", "48 hours"}},
+		{TemplateAssignmentPublished, AssignmentPublishedData{FirstName: "Siti", CourseID: "c1", CourseName: "Biology", AssignmentID: "a1", AssignmentTitle: "Cells", DueDate: due}, "New assignment in Biology: Cells", []string{"Friday, 2 May 2025 17:00 UTC", "/courses/c1/assignments/a1"}},
+		{TemplateGradePosted, GradePostedData{FirstName: "Siti", AssignmentTitle: "Cells", Grade: &grade, TotalPoints: 50, HasFeedback: true}, "Grade posted: Cells", []string{"42.50 out of 50.00", "also left feedback"}},
+		{TemplateGradePosted, GradePostedData{FirstName: "Siti", AssignmentTitle: "Cells"}, "Feedback: Cells", []string{"left feedback on your submission"}},
+		{TemplateDueSoon, DueSoonData{FirstName: "Siti", CourseName: "Biology", AssignmentTitle: "Cells", DueDate: due, TimeLeft: "1 hour"}, "Cells is due in 1 hour", []string{"have not submitted"}},
+	}
+	for _, tt := range tests {
+		msg, err := templates.Render(tt.name, tt.data)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if msg.Subject != tt.subject {
+			t.Errorf("%s: subject %q, want %q", tt.name, msg.Subject, tt.subject)
+		}
+		for _, want := range tt.text {
+			if !strings.Contains(msg.Text, want) {
+				t.Errorf("%s: text body misses %q:\n%s", tt.name, want, msg.Text)
+			}
+		}
+		if !strings.Contains(msg.HTML, "<title>"+tt.subject+"</title>") {
+			t.Errorf("%s: html body misses the subject:\n%s", tt.name, msg.HTML)
+		}
+	}
+}
+
+func TestTemplatesEscapeHTML(t *testing.T) {
+	templates, _ := NewTemplates("Edukita LMS", "https://lms.edukita.test")
+	msg, err := templates.Render(TemplateWelcome, WelcomeData{FirstName: "<script>alert(1)</script>"})
+	if err != nil {
+		t.Fatalf("rendering: %v", err)
+	}
+	if strings.Contains(msg.HTML, "<script>") {
+		t.Errorf("html body is not escaped:\n%s", msg.HTML)
+	}
+
+	if _, err = templates.Render("unknown", nil); err == nil {
+		t.Error("unknown template rendered")
+	}
+}
+
+func TestLimiterReserve(t *testing.T) {
+	limiter := NewLimiter(60, 2)
+	now := time.Now()
+
+	for i, want := range []time.Duration{0, 0, time.Second, 2 * time.Second} {
+		if got := limiter.reserve(now); got != want {
+			t.Errorf("send %d: wait %v, want %v", i+1, got, want)
+		}
+	}
+	// after the reserved sends went out the bucket fills up again
+	if got := limiter.reserve(now.Add(5 * time.Second)); got != 0 {
+		t.Errorf("wait %v after a quiet period, want none", got)
+	}
+	if got := NewLimiter(0, 0).reserve(now); got != 0 {
+		t.Errorf("unlimited limiter waits %v", got)
+	}
+}
+
+func TestDataQueuedWithoutToken(t *testing.T) {
+	templates, _ := NewTemplates("Edukita LMS", "https://lms.edukita.test")
+	for _, name := range Names {
+		if _, err := Data(name); err != nil {
+			t.Errorf("Data(%s) unexpected error: %v", name, err)
+		}
+	}
+	if _, err := Data("unknown"); err == nil {
+		t.Error("Data() of an unknown template succeeded")
+	}
+
+	queued, err := json.Marshal(PasswordResetData{FirstName: "Siti", Token: "abc", ExpiresInMinutes: 30})
+	if err != nil || strings.Contains(string(queued), "abc") {
+		t.Fatalf("queued data %s, %v, want it without the token", queued, err)
+	}
+
+	data, _ := Data(TemplatePasswordReset)
+	if err = json.Unmarshal(queued, data); err != nil {
+		t.Fatalf("decoding: %v", err)
+	}
+	data.(TokenData).SetToken("xyz")
+	msg, err := templates.Render(TemplatePasswordReset, data)
+	if err != nil || !strings.Contains(msg.Text, "reset-password?token=xyz") || !strings.Contains(msg.Text, "30 minutes") {
+		t.Errorf("Render() = %q, %v", msg.Text, err)
+	}
+}
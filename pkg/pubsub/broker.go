@@ -0,0 +1,62 @@
+package pubsub
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"go.uber.org/zap"
+)
+
+const (
+	DriverMemory   = "memory"
+	DriverPostgres = "postgres"
+)
+
+// Execer runs a statement, such as the transaction of the change a message is about
+type Execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// Broker publishes messages to the hubs of every replica, Run carries messages from the other replicas into the
+// local hub until ctx is done
+type Broker interface {
+	Publish(ctx context.Context, exec Execer, msg Message) error
+	Run(ctx context.Context)
+}
+
+type Option struct {
+	Driver string
+	// URL and Channel are the database and the LISTEN/NOTIFY channel of the postgres driver
+	URL     string
+	Channel string
+	Logger  *zap.SugaredLogger
+}
+
+// NewBroker builds the broker selected by the driver option on top of the local hub
+func NewBroker(opt Option, hub *Hub) (Broker, error) {
+	switch opt.Driver {
+	case DriverMemory, "":
+		return NewLocalBroker(hub), nil
+	case DriverPostgres:
+		return NewPostgresBroker(hub, opt.URL, opt.Channel, opt.Logger)
+	default:
+		return nil, fmt.Errorf("unknown pubsub driver: %s", opt.Driver)
+	}
+}
+
+// LocalBroker publishes straight to the hub, it is enough when a single replica serves every client
+type LocalBroker struct {
+	hub *Hub
+}
+
+func NewLocalBroker(hub *Hub) *LocalBroker {
+	return &LocalBroker{hub: hub}
+}
+
+func (b *LocalBroker) Publish(ctx context.Context, exec Execer, msg Message) error {
+	b.hub.Publish(msg)
+	return nil
+}
+
+func (b *LocalBroker) Run(ctx context.Context) {}
@@ -0,0 +1,134 @@
+package pubsub
+
+import (
+	"encoding/json"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Message is pushed to the subscribers of any of its topics, ID is stable across replicas and redeliveries so
+// clients can drop repeats
+type Message struct {
+	ID         string          `json:"id"`
+	Type       string          `json:"type"`
+	Topics     []string        `json:"topics,omitempty"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Data       json.RawMessage `json:"data"`
+}
+
+// Subscription receives the messages of its topics on C until it is closed
+type Subscription struct {
+	C <-chan Message
+
+	ch      chan Message
+	topics  []string
+	hub     *Hub
+	once    sync.Once
+	dropped atomic.Int64
+}
+
+// Topics lists the topics the subscription receives
+func (s *Subscription) Topics() []string {
+	return slices.Clone(s.topics)
+}
+
+// Dropped counts the messages lost because the subscriber did not keep up
+func (s *Subscription) Dropped() int64 {
+	return s.dropped.Load()
+}
+
+// Close unsubscribes and closes C, it is safe to call more than once
+func (s *Subscription) Close() {
+	s.hub.remove(s)
+}
+
+// Hub fans messages out to the subscriptions of this process
+type Hub struct {
+	mu     sync.RWMutex
+	buffer int
+	topics map[string]map[*Subscription]struct{}
+	closed bool
+}
+
+// NewHub returns a hub whose subscriptions buffer up to buffer messages
+func NewHub(buffer int) *Hub {
+	return &Hub{
+		buffer: max(buffer, 1),
+		topics: make(map[string]map[*Subscription]struct{}),
+	}
+}
+
+// Subscribe returns a subscription to the topics, a closed hub returns one that is closed already
+func (h *Hub) Subscribe(topics ...string) *Subscription {
+	ch := make(chan Message, h.buffer)
+	sub := &Subscription{C: ch, ch: ch, topics: topics, hub: h}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		sub.once.Do(func() { close(ch) })
+		return sub
+	}
+	for _, topic := range topics {
+		if h.topics[topic] == nil {
+			h.topics[topic] = make(map[*Subscription]struct{})
+		}
+		h.topics[topic][sub] = struct{}{}
+	}
+	return sub
+}
+
+// Publish hands the message to every subscription of its topics once, and returns how many got it. It never
+// blocks: a subscription with a full buffer misses the message.
+func (h *Hub) Publish(msg Message) (delivered int) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	seen := make(map[*Subscription]bool)
+	for _, topic := range msg.Topics {
+		for sub := range h.topics[topic] {
+			if seen[sub] {
+				continue
+			}
+			seen[sub] = true
+
+			select {
+			case sub.ch <- msg:
+				delivered++
+			default:
+				sub.dropped.Add(1)
+			}
+		}
+	}
+	return
+}
+
+// Close ends every subscription, subscribers see their channel closed
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.closed = true
+	for _, subs := range h.topics {
+		for sub := range subs {
+			sub.once.Do(func() { close(sub.ch) })
+		}
+	}
+	h.topics = make(map[string]map[*Subscription]struct{})
+}
+
+func (h *Hub) remove(sub *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, topic := range sub.topics {
+		delete(h.topics[topic], sub)
+		if len(h.topics[topic]) == 0 {
+			delete(h.topics, topic)
+		}
+	}
+	// Publish holds the read lock while sending, so the channel is never closed under it
+	sub.once.Do(func() { close(sub.ch) })
+}
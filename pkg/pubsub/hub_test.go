@@ -0,0 +1,104 @@
+package pubsub
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestHubPublishesToTopics(t *testing.T) {
+	hub := NewHub(4)
+	course := hub.Subscribe("course:1", "course:1:staff")
+	student := hub.Subscribe("user:7")
+	other := hub.Subscribe("course:2")
+	defer course.Close()
+	defer student.Close()
+	defer other.Close()
+
+	delivered := hub.Publish(Message{ID: "e1", Type: "submission.created", Topics: []string{"course:1:staff", "course:1", "user:7"}})
+	if delivered != 2 {
+		t.Errorf("delivered to %d subscriptions, want 2", delivered)
+	}
+	if msg := <-course.C; msg.ID != "e1" {
+		t.Errorf("course got %s", msg.ID)
+	}
+	if len(course.C) != 0 {
+		t.Error("a subscription of two matching topics got the message twice")
+	}
+	if msg := <-student.C; msg.ID != "e1" {
+		t.Errorf("student got %s", msg.ID)
+	}
+	if len(other.C) != 0 {
+		t.Error("another course got the message")
+	}
+}
+
+func TestHubDropsForSlowSubscribers(t *testing.T) {
+	hub := NewHub(1)
+	sub := hub.Subscribe("user:1")
+	defer sub.Close()
+
+	hub.Publish(Message{ID: "1", Topics: []string{"user:1"}})
+	hub.Publish(Message{ID: "2", Topics: []string{"user:1"}})
+	if sub.Dropped() != 1 {
+		t.Errorf("dropped %d, want 1", sub.Dropped())
+	}
+	if msg := <-sub.C; msg.ID != "1" {
+		t.Errorf("got %s, want the first message", msg.ID)
+	}
+}
+
+func TestHubClose(t *testing.T) {
+	hub := NewHub(1)
+	sub := hub.Subscribe("user:1")
+	sub.Close()
+	sub.Close()
+	if _, ok := <-sub.C; ok {
+		t.Error("closed subscription still open")
+	}
+	if hub.Publish(Message{Topics: []string{"user:1"}}) != 0 {
+		t.Error("closed subscription still subscribed")
+	}
+
+	open := hub.Subscribe("user:2")
+	hub.Close()
+	if _, ok := <-open.C; ok {
+		t.Error("subscription open after the hub closed")
+	}
+	open.Close()
+	if _, ok := <-hub.Subscribe("user:3").C; ok {
+		t.Error("closed hub handed out an open subscription")
+	}
+}
+
+func TestLocalBrokerPublishes(t *testing.T) {
+	hub := NewHub(1)
+	sub := hub.Subscribe("user:1")
+	defer sub.Close()
+
+	if err := NewLocalBroker(hub).Publish(context.Background(), nil, Message{ID: "1", Topics: []string{"user:1"}}); err != nil {
+		t.Fatalf("publishing: %v", err)
+	}
+	if msg := <-sub.C; msg.ID != "1" {
+		t.Errorf("got %s", msg.ID)
+	}
+}
+
+func TestEncodeNotificationLeavesOutLargeData(t *testing.T) {
+	msg := Message{ID: "1", Type: "submission.graded", Topics: []string{"user:1"}, Data: json.RawMessage(`{"feedback":"ok"}`)}
+	payload, err := encodeNotification(msg)
+	if err != nil || !strings.Contains(payload, `"feedback"`) {
+		t.Fatalf("got %s, %v", payload, err)
+	}
+
+	msg.Data = json.RawMessage(`{"feedback":"` + strings.Repeat("x", maxNotifyPayload) + `"}`)
+	payload, err = encodeNotification(msg)
+	if err != nil {
+		t.Fatalf("encoding: %v", err)
+	}
+	var decoded Message
+	if err = json.Unmarshal([]byte(payload), &decoded); err != nil || decoded.ID != "1" || string(decoded.Data) != "null" {
+		t.Errorf("got %s, want the message without data", payload)
+	}
+}
@@ -0,0 +1,111 @@
+package pubsub
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+// maxNotifyPayload is the largest payload Postgres accepts in a notification
+const maxNotifyPayload = 8000 - 1
+
+// PostgresBroker fans messages out to every replica with LISTEN/NOTIFY. A message is published with
+// pg_notify in the transaction given to Publish, so Postgres only sends it when that transaction commits, and
+// every replica, this one included, hands it to its hub when it arrives on the channel.
+type PostgresBroker struct {
+	hub     *Hub
+	url     string
+	channel string
+	logger  *zap.SugaredLogger
+}
+
+func NewPostgresBroker(hub *Hub, url string, channel string, logger *zap.SugaredLogger) (*PostgresBroker, error) {
+	if channel == "" {
+		return nil, fmt.Errorf("pubsub: postgres channel is required")
+	}
+	if logger == nil {
+		logger = zap.NewNop().Sugar()
+	}
+	return &PostgresBroker{hub: hub, url: url, channel: channel, logger: logger}, nil
+}
+
+func (b *PostgresBroker) Publish(ctx context.Context, exec Execer, msg Message) error {
+	payload, err := encodeNotification(msg)
+	if err != nil {
+		return err
+	}
+	_, err = exec.ExecContext(ctx, "SELECT pg_notify($1, $2)", b.channel, payload)
+	return err
+}
+
+// Run listens on the channel until ctx is done, reconnecting with a growing delay when the connection drops.
+// Messages sent while it reconnects are missed.
+func (b *PostgresBroker) Run(ctx context.Context) {
+	delay := time.Second
+	for ctx.Err() == nil {
+		err := b.listen(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		b.logger.Warnf("pubsub listener on %s stopped, reconnecting in %s: %v", b.channel, delay, err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		delay = min(2*delay, 30*time.Second)
+	}
+}
+
+func (b *PostgresBroker) listen(ctx context.Context) error {
+	conn, err := pgx.Connect(ctx, b.url)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(context.Background())
+
+	if _, err = conn.Exec(ctx, "LISTEN "+pgx.Identifier{b.channel}.Sanitize()); err != nil {
+		return err
+	}
+	b.logger.Infof("pubsub listening on %s", b.channel)
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+
+		var msg Message
+		if err = json.Unmarshal([]byte(notification.Payload), &msg); err != nil {
+			b.logger.Warnf("dropping malformed pubsub message on %s: %v", b.channel, err)
+			continue
+		}
+		b.hub.Publish(msg)
+	}
+}
+
+// encodeNotification encodes the message for pg_notify, data that does not fit in a notification is left out
+// and clients load it themselves
+func encodeNotification(msg Message) (string, error) {
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return "", err
+	}
+	if len(payload) <= maxNotifyPayload {
+		return string(payload), nil
+	}
+
+	msg.Data = nil
+	if payload, err = json.Marshal(msg); err != nil {
+		return "", err
+	}
+	if len(payload) > maxNotifyPayload {
+		return "", fmt.Errorf("pubsub: message %s is too large to notify", msg.ID)
+	}
+	return string(payload), nil
+}
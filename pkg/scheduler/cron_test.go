@@ -0,0 +1,83 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseRejectsInvalidExpressions(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"10-5 * * * *",
+		"a * * * *",
+	} {
+		if _, err := Parse(spec); err == nil {
+			t.Errorf("%q: parsed, want an error", spec)
+		}
+	}
+}
+
+func TestNext(t *testing.T) {
+	// a Wednesday
+	from := time.Date(2025, 5, 14, 10, 7, 30, 0, time.UTC)
+	tests := []struct {
+		spec string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2025, 5, 14, 10, 8, 0, 0, time.UTC)},
+		{"*/5 * * * *", time.Date(2025, 5, 14, 10, 10, 0, 0, time.UTC)},
+		{"5/15 * * * *", time.Date(2025, 5, 14, 10, 20, 0, 0, time.UTC)},
+		{"0 * * * *", time.Date(2025, 5, 14, 11, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2025, 5, 14, 11, 0, 0, 0, time.UTC)},
+		{"30 3 * * *", time.Date(2025, 5, 15, 3, 30, 0, 0, time.UTC)},
+		{"@daily", time.Date(2025, 5, 15, 0, 0, 0, 0, time.UTC)},
+		{"0 9 * * mon-fri", time.Date(2025, 5, 15, 9, 0, 0, 0, time.UTC)},
+		{"0 9 * * sun", time.Date(2025, 5, 18, 9, 0, 0, 0, time.UTC)},
+		{"0 9 * * 7", time.Date(2025, 5, 18, 9, 0, 0, 0, time.UTC)},
+		{"0 0 1 * *", time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 jan *", time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"15,45 8-9 * * *", time.Date(2025, 5, 15, 8, 15, 0, 0, time.UTC)},
+		// either restricted day field matches: the 20th or any Friday
+		{"0 0 20 * fri", time.Date(2025, 5, 16, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		s, err := Parse(tt.spec)
+		if err != nil {
+			t.Fatalf("%q: %v", tt.spec, err)
+		}
+		if got := s.Next(from); !got.Equal(tt.want) {
+			t.Errorf("%q: next %s, want %s", tt.spec, got, tt.want)
+		}
+	}
+}
+
+func TestNextNeverFires(t *testing.T) {
+	if got := MustParse("0 0 30 2 *").Next(time.Now()); !got.IsZero() {
+		t.Errorf("February 30th fires at %s", got)
+	}
+}
+
+func TestNextInLocation(t *testing.T) {
+	jakarta := time.FixedZone("WIB", 7*60*60)
+	from := time.Date(2025, 5, 14, 16, 30, 0, 0, time.UTC)
+
+	got := MustParse("0 2 * * *").Next(from.In(jakarta))
+	if want := time.Date(2025, 5, 15, 2, 0, 0, 0, jakarta); !got.Equal(want) {
+		t.Errorf("next %s, want %s", got, want)
+	}
+
+	kolkata := time.FixedZone("IST", 5*60*60+30*60)
+	got = MustParse("0 * * * *").Next(time.Date(2025, 5, 14, 10, 40, 0, 0, kolkata))
+	if want := time.Date(2025, 5, 14, 11, 0, 0, 0, kolkata); !got.Equal(want) {
+		t.Errorf("next %s, want %s on the hour of a half-hour zone", got, want)
+	}
+}
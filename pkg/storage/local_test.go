@@ -0,0 +1,90 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLocalStore(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocalStore() unexpected error: %v", err)
+	}
+
+	if err = store.Put(ctx, "submissions/1/2", strings.NewReader("hello"), 5, "text/plain"); err != nil {
+		t.Fatalf("Put() unexpected error: %v", err)
+	}
+	body, err := store.Get(ctx, "submissions/1/2")
+	if err != nil {
+		t.Fatalf("Get() unexpected error: %v", err)
+	}
+	got, _ := io.ReadAll(body)
+	_ = body.Close()
+	if string(got) != "hello" {
+		t.Errorf("Get() = %q, want %q", got, "hello")
+	}
+
+	// only the object itself is left, the temporary file was renamed
+	entries, _ := os.ReadDir(filepath.Join(store.root, "submissions", "1"))
+	if len(entries) != 1 {
+		t.Errorf("got %d files next to the object, want 1", len(entries))
+	}
+
+	if err = store.Delete(ctx, "submissions/1/2"); err != nil {
+		t.Fatalf("Delete() unexpected error: %v", err)
+	}
+	if _, err = store.Get(ctx, "submissions/1/2"); !errors.Is(err, ErrObjectNotFound) {
+		t.Errorf("Get() after Delete() error = %v, want ErrObjectNotFound", err)
+	}
+	if err = store.Delete(ctx, "submissions/1/2"); err != nil {
+		t.Errorf("Delete() of a missing object error = %v, want nil", err)
+	}
+}
+
+func TestLocalStorePath(t *testing.T) {
+	parent := t.TempDir()
+	store, err := NewLocalStore(filepath.Join(parent, "blobs"))
+	if err != nil {
+		t.Fatalf("NewLocalStore() unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		key     string
+		wantErr bool
+	}{
+		{"a/b", false},
+		{"/a/b", false},
+		{"a/../b", false},
+		{"", true},
+		{".", true},
+		{"..", true},
+		{"../x", true},
+		{"a/../../x", true},
+		// a sibling directory sharing the prefix of the root is outside of it
+		{"../blobs-other/x", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			path, err := store.path(tt.key)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("path() = %s, error = %v, wantErr %v", path, err, tt.wantErr)
+			}
+			if err == nil && !strings.HasPrefix(path, store.root+string(filepath.Separator)) {
+				t.Errorf("path() = %s, outside of %s", path, store.root)
+			}
+		})
+	}
+
+	if err = store.Put(context.Background(), "../x", strings.NewReader("x"), 1, ""); err == nil {
+		t.Error("Put() outside of the root succeeded")
+	}
+	if _, err = os.Stat(filepath.Join(parent, "x")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("file written outside of the root: %v", err)
+	}
+}
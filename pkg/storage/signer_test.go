@@ -0,0 +1,58 @@
+package storage
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestURLSigner(t *testing.T) {
+	signer := NewURLSigner("secret", "https://lms.test/api/attachments/", 15*time.Minute)
+	now := time.Date(2025, 5, 1, 10, 0, 0, 500, time.UTC)
+
+	link, expiresAt := signer.Sign("a/b", now)
+	if !expiresAt.Equal(now.Add(15 * time.Minute).Truncate(time.Second)) {
+		t.Errorf("Sign() expires at %v, want %v", expiresAt, now.Add(15*time.Minute))
+	}
+	if !strings.HasPrefix(link, "https://lms.test/api/attachments/a%2Fb/download?") {
+		t.Fatalf("Sign() = %s", link)
+	}
+
+	u, err := url.Parse(link)
+	if err != nil {
+		t.Fatalf("url.Parse() unexpected error: %v", err)
+	}
+	expires, signature := u.Query().Get("expires"), u.Query().Get("signature")
+
+	tests := []struct {
+		name      string
+		id        string
+		expires   string
+		signature string
+		now       time.Time
+		wantErr   error
+	}{
+		{"valid", "a/b", expires, signature, now, nil},
+		{"valid until it expires", "a/b", expires, signature, expiresAt, nil},
+		{"expired", "a/b", expires, signature, expiresAt.Add(time.Second), ErrSignatureExpired},
+		{"other object", "a/c", expires, signature, now, ErrSignatureInvalid},
+		{"extended expiry", "a/b", "9999999999", signature, now, ErrSignatureInvalid},
+		{"malformed expiry", "a/b", "soon", signature, now, ErrSignatureInvalid},
+		{"tampered signature", "a/b", expires, strings.Repeat("0", len(signature)), now, ErrSignatureInvalid},
+		{"missing signature", "a/b", expires, "", now, ErrSignatureInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := signer.Verify(tt.id, tt.expires, tt.signature, tt.now); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Verify() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	other := NewURLSigner("other secret", "https://lms.test/api/attachments", 15*time.Minute)
+	if err := other.Verify("a/b", expires, signature, now); !errors.Is(err, ErrSignatureInvalid) {
+		t.Errorf("Verify() with another secret error = %v, want ErrSignatureInvalid", err)
+	}
+}
@@ -0,0 +1,41 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+)
+
+const (
+	DriverLocal = "local"
+	DriverS3    = "s3"
+)
+
+// ErrObjectNotFound is returned when the requested key does not exist in the store
+var ErrObjectNotFound = errors.New("storage: object not found")
+
+// BlobStore keeps uploaded files, keys are slash separated paths chosen by the caller
+type BlobStore interface {
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+type Option struct {
+	Driver    string
+	LocalPath string
+	S3        S3Option
+}
+
+// NewBlobStore builds the backend selected by the driver option
+func NewBlobStore(opt Option) (BlobStore, error) {
+	switch opt.Driver {
+	case DriverLocal, "":
+		return NewLocalStore(opt.LocalPath)
+	case DriverS3:
+		return NewS3Store(opt.S3)
+	default:
+		return nil, fmt.Errorf("unknown storage driver: %s", opt.Driver)
+	}
+}
@@ -0,0 +1,93 @@
+package totp
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfcSecret is the SHA1 key of the test vectors of RFC 4226 and RFC 6238
+const rfcSecret = "12345678901234567890"
+
+func TestHOTPVectors(t *testing.T) {
+	want := []string{"755224", "287082", "359152", "969429", "338314", "254676", "287922", "162583", "399871", "520489"}
+	for counter, code := range want {
+		if got := hotp([]byte(rfcSecret), uint64(counter), 6); got != code {
+			t.Errorf("counter %d: got %s, want %s", counter, got, code)
+		}
+	}
+}
+
+func TestTOTPVectors(t *testing.T) {
+	tests := []struct {
+		unix int64
+		code string
+	}{
+		{59, "94287082"},
+		{1111111109, "07081804"},
+		{1111111111, "14050471"},
+		{1234567890, "89005924"},
+		{2000000000, "69279037"},
+		{20000000000, "65353130"},
+	}
+	for _, tt := range tests {
+		if got := hotp([]byte(rfcSecret), uint64(Step(time.Unix(tt.unix, 0))), 8); got != tt.code {
+			t.Errorf("%d: got %s, want %s", tt.unix, got, tt.code)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	secret := encoding.EncodeToString([]byte(rfcSecret))
+	now := time.Unix(1111111111, 0)
+
+	code, err := Code(secret, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code != "050471" {
+		t.Fatalf("code %s, want the last 6 digits of the RFC vector", code)
+	}
+
+	step, ok, err := Validate(secret, code, now.Add(Period), 1)
+	if err != nil || !ok || step != Step(now) {
+		t.Errorf("a code from the previous step: step %d, ok %v, err %v", step, ok, err)
+	}
+	if _, ok, _ := Validate(secret, code, now.Add(2*Period), 1); ok {
+		t.Error("accepted a code two steps old")
+	}
+	if _, ok, _ := Validate(secret, "123", now, 1); ok {
+		t.Error("accepted a code of the wrong length")
+	}
+	if _, _, err := Validate("not base32!", code, now, 1); err != ErrInvalidSecret {
+		t.Errorf("got %v, want an invalid secret", err)
+	}
+}
+
+func TestGenerateSecret(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := decodeSecret(secret)
+	if err != nil || len(key) != secretSize {
+		t.Errorf("secret %s decodes to %d bytes, err %v", secret, len(key), err)
+	}
+}
+
+func TestProvisioningURI(t *testing.T) {
+	uri := ProvisioningURI("Edukita LMS", "siti@edukita.test", "JBSWY3DPEHPK3PXP")
+	if !strings.HasPrefix(uri, "otpauth://totp/Edukita%20LMS:siti@edukita.test?") {
+		t.Errorf("unexpected label in %s", uri)
+	}
+
+	parsed, err := url.Parse(uri)
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := parsed.Query()
+	if query.Get("secret") != "JBSWY3DPEHPK3PXP" || query.Get("issuer") != "Edukita LMS" || query.Get("digits") != "6" || query.Get("period") != "30" {
+		t.Errorf("unexpected parameters %v", query)
+	}
+}
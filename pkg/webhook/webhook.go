@@ -0,0 +1,128 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderDelivery  = "X-Webhook-Delivery"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+
+	// signaturePrefix names the algorithm of the signature header value
+	signaturePrefix = "sha256="
+	// maxResponseBody is how much of the answer of the receiver is kept
+	maxResponseBody = 1 << 10
+)
+
+var (
+	ErrSignatureInvalid = errors.New("webhook: invalid signature")
+	ErrSignatureExpired = errors.New("webhook: signature expired")
+)
+
+// Sign returns the signature header value of a request body sent at the given unix time. The timestamp is
+// part of the signed content, so a captured request cannot be replayed later with a new timestamp.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks the signature headers of a received request, requests signed more than tolerance away from now
+// are rejected
+func Verify(secret string, header http.Header, body []byte, now time.Time, tolerance time.Duration) error {
+	timestamp, err := strconv.ParseInt(header.Get(HeaderTimestamp), 10, 64)
+	if err != nil {
+		return ErrSignatureInvalid
+	}
+
+	signature := header.Get(HeaderSignature)
+	if !strings.HasPrefix(signature, signaturePrefix) || !hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature)) {
+		return ErrSignatureInvalid
+	}
+	if age := now.Sub(time.Unix(timestamp, 0)); age > tolerance || age < -tolerance {
+		return ErrSignatureExpired
+	}
+	return nil
+}
+
+// Message is a signed request to a receiver
+type Message struct {
+	URL        string
+	Secret     string
+	Event      string
+	DeliveryID string
+	Body       []byte
+}
+
+// Response is what the receiver answered, StatusCode is 0 when no answer arrived
+type Response struct {
+	StatusCode int
+	Body       string
+	Duration   time.Duration
+}
+
+// Sender posts signed messages to receivers
+type Sender struct {
+	client *http.Client
+}
+
+// NewSender builds a sender that gives up on a receiver after timeout
+func NewSender(timeout time.Duration) *Sender {
+	return &Sender{
+		client: &http.Client{
+			Timeout: timeout,
+			// a redirect would resend the signed body to an address the subscription did not name
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+// Send posts the message, any answer outside 2xx is returned as an error along with the response
+func (s *Sender) Send(ctx context.Context, msg Message, now time.Time) (res Response, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, msg.URL, bytes.NewReader(msg.Body))
+	if err != nil {
+		return
+	}
+
+	timestamp := now.Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "edukita-webhooks/1.0")
+	req.Header.Set(HeaderEvent, msg.Event)
+	req.Header.Set(HeaderDelivery, msg.DeliveryID)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(msg.Secret, timestamp, msg.Body))
+
+	start := time.Now()
+	resp, err := s.client.Do(req)
+	res.Duration = time.Since(start)
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	res.StatusCode = resp.StatusCode
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+	res.Body = string(body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		err = fmt.Errorf("webhook: receiver answered %d", resp.StatusCode)
+	}
+	return
+}
@@ -0,0 +1,125 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+const testSecret = "whsec_test"
+
+func TestSendSignsRequest(t *testing.T) {
+	var received http.Header
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = r.Header.Clone()
+		if err := Verify(testSecret, r.Header, body, time.Now(), 5*time.Minute); err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	res, err := NewSender(time.Second).Send(context.Background(), Message{
+		URL:        receiver.URL,
+		Secret:     testSecret,
+		Event:      "submission.graded",
+		DeliveryID: "delivery-1",
+		Body:       []byte(`{"type":"submission.graded"}`),
+	}, time.Now())
+	if err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	if res.StatusCode != http.StatusNoContent {
+		t.Errorf("status %d, want %d", res.StatusCode, http.StatusNoContent)
+	}
+	if got := received.Get(HeaderEvent); got != "submission.graded" {
+		t.Errorf("event header %q", got)
+	}
+	if got := received.Get(HeaderDelivery); got != "delivery-1" {
+		t.Errorf("delivery header %q", got)
+	}
+}
+
+func TestSendRejectedByReceiver(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "maintenance", http.StatusServiceUnavailable)
+	}))
+	defer receiver.Close()
+
+	res, err := NewSender(time.Second).Send(context.Background(), Message{URL: receiver.URL, Secret: testSecret, Body: []byte(`{}`)}, time.Now())
+	if err == nil {
+		t.Fatal("expected an error for a 503 answer")
+	}
+	if res.StatusCode != http.StatusServiceUnavailable || res.Body != "maintenance\n" {
+		t.Errorf("got %d %q", res.StatusCode, res.Body)
+	}
+}
+
+func TestSendDoesNotFollowRedirects(t *testing.T) {
+	followed := false
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		followed = true
+	}))
+	defer target.Close()
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, target.URL, http.StatusTemporaryRedirect)
+	}))
+	defer receiver.Close()
+
+	res, err := NewSender(time.Second).Send(context.Background(), Message{URL: receiver.URL, Secret: testSecret, Body: []byte(`{}`)}, time.Now())
+	if err == nil || res.StatusCode != http.StatusTemporaryRedirect {
+		t.Errorf("got %d %v, want the redirect reported as a failure", res.StatusCode, err)
+	}
+	if followed {
+		t.Error("redirect was followed")
+	}
+}
+
+func TestSendTimeout(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer receiver.Close()
+
+	res, err := NewSender(50*time.Millisecond).Send(context.Background(), Message{URL: receiver.URL, Secret: testSecret, Body: []byte(`{}`)}, time.Now())
+	if err == nil {
+		t.Fatal("expected a timeout")
+	}
+	if res.StatusCode != 0 {
+		t.Errorf("status %d, want none", res.StatusCode)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	now := time.Unix(1746057600, 0)
+	body := []byte(`{"id":"1"}`)
+
+	header := http.Header{}
+	header.Set(HeaderTimestamp, "1746057600")
+	header.Set(HeaderSignature, Sign(testSecret, now.Unix(), body))
+
+	if err := Verify(testSecret, header, body, now.Add(time.Minute), 5*time.Minute); err != nil {
+		t.Errorf("valid signature: %v", err)
+	}
+	if err := Verify("other", header, body, now, 5*time.Minute); !errors.Is(err, ErrSignatureInvalid) {
+		t.Errorf("wrong secret: got %v", err)
+	}
+	if err := Verify(testSecret, header, []byte(`{"id":"2"}`), now, 5*time.Minute); !errors.Is(err, ErrSignatureInvalid) {
+		t.Errorf("tampered body: got %v", err)
+	}
+	if err := Verify(testSecret, header, body, now.Add(time.Hour), 5*time.Minute); !errors.Is(err, ErrSignatureExpired) {
+		t.Errorf("old request: got %v", err)
+	}
+
+	header.Set(HeaderTimestamp, "1746057601")
+	if err := Verify(testSecret, header, body, now, 5*time.Minute); !errors.Is(err, ErrSignatureInvalid) {
+		t.Errorf("changed timestamp: got %v", err)
+	}
+}